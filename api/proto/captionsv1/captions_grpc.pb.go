@@ -0,0 +1,372 @@
+// gRPC contract for programmatic clients. It mirrors the HTTP and WebSocket
+// API: one-shot transcription, translation and speech synthesis, upload jobs,
+// and a bidirectional stream for live captions.
+//
+// The server listens on GRPC_PORT next to the HTTP server. With Keycloak
+// configured every call needs "authorization: Bearer <access token>"
+// metadata. Regenerate captionsv1 after editing:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	  --go-grpc_out=. --go-grpc_opt=paths=source_relative captions.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: captions.proto
+
+package captionsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Captions_Transcribe_FullMethodName     = "/captions.v1.Captions/Transcribe"
+	Captions_Translate_FullMethodName      = "/captions.v1.Captions/Translate"
+	Captions_Synthesize_FullMethodName     = "/captions.v1.Captions/Synthesize"
+	Captions_CreateJob_FullMethodName      = "/captions.v1.Captions/CreateJob"
+	Captions_GetJob_FullMethodName         = "/captions.v1.Captions/GetJob"
+	Captions_WatchJob_FullMethodName       = "/captions.v1.Captions/WatchJob"
+	Captions_StreamCaptions_FullMethodName = "/captions.v1.Captions/StreamCaptions"
+)
+
+// CaptionsClient is the client API for Captions service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CaptionsClient interface {
+	// Transcribe one clip (like POST /upload/audio without translation)
+	Transcribe(ctx context.Context, in *TranscribeRequest, opts ...grpc.CallOption) (*TranscribeResponse, error)
+	// Translate text between languages
+	Translate(ctx context.Context, in *TranslateRequest, opts ...grpc.CallOption) (*TranslateResponse, error)
+	// Synthesize speech; audio arrives in chunks
+	Synthesize(ctx context.Context, in *SynthesizeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AudioChunk], error)
+	// Upload a video or audio file for processing (like POST /upload and
+	// /upload/audio): a config message, then the file in chunks
+	CreateJob(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[CreateJobRequest, Job], error)
+	// Current state of a job (like GET /api/jobs/{id})
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// Progress updates until the job finishes (like /progress/{sessionId})
+	WatchJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[JobProgress], error)
+	// Live captions: the client streams PCM16 audio after a config message,
+	// the server streams partial and final captions (like /ws)
+	StreamCaptions(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamCaptionsRequest, Caption], error)
+}
+
+type captionsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCaptionsClient(cc grpc.ClientConnInterface) CaptionsClient {
+	return &captionsClient{cc}
+}
+
+func (c *captionsClient) Transcribe(ctx context.Context, in *TranscribeRequest, opts ...grpc.CallOption) (*TranscribeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TranscribeResponse)
+	err := c.cc.Invoke(ctx, Captions_Transcribe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *captionsClient) Translate(ctx context.Context, in *TranslateRequest, opts ...grpc.CallOption) (*TranslateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TranslateResponse)
+	err := c.cc.Invoke(ctx, Captions_Translate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *captionsClient) Synthesize(ctx context.Context, in *SynthesizeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AudioChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Captions_ServiceDesc.Streams[0], Captions_Synthesize_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SynthesizeRequest, AudioChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Captions_SynthesizeClient = grpc.ServerStreamingClient[AudioChunk]
+
+func (c *captionsClient) CreateJob(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[CreateJobRequest, Job], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Captions_ServiceDesc.Streams[1], Captions_CreateJob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CreateJobRequest, Job]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Captions_CreateJobClient = grpc.ClientStreamingClient[CreateJobRequest, Job]
+
+func (c *captionsClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Captions_GetJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *captionsClient) WatchJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[JobProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Captions_ServiceDesc.Streams[2], Captions_WatchJob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetJobRequest, JobProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Captions_WatchJobClient = grpc.ServerStreamingClient[JobProgress]
+
+func (c *captionsClient) StreamCaptions(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamCaptionsRequest, Caption], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Captions_ServiceDesc.Streams[3], Captions_StreamCaptions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamCaptionsRequest, Caption]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Captions_StreamCaptionsClient = grpc.BidiStreamingClient[StreamCaptionsRequest, Caption]
+
+// CaptionsServer is the server API for Captions service.
+// All implementations must embed UnimplementedCaptionsServer
+// for forward compatibility.
+type CaptionsServer interface {
+	// Transcribe one clip (like POST /upload/audio without translation)
+	Transcribe(context.Context, *TranscribeRequest) (*TranscribeResponse, error)
+	// Translate text between languages
+	Translate(context.Context, *TranslateRequest) (*TranslateResponse, error)
+	// Synthesize speech; audio arrives in chunks
+	Synthesize(*SynthesizeRequest, grpc.ServerStreamingServer[AudioChunk]) error
+	// Upload a video or audio file for processing (like POST /upload and
+	// /upload/audio): a config message, then the file in chunks
+	CreateJob(grpc.ClientStreamingServer[CreateJobRequest, Job]) error
+	// Current state of a job (like GET /api/jobs/{id})
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	// Progress updates until the job finishes (like /progress/{sessionId})
+	WatchJob(*GetJobRequest, grpc.ServerStreamingServer[JobProgress]) error
+	// Live captions: the client streams PCM16 audio after a config message,
+	// the server streams partial and final captions (like /ws)
+	StreamCaptions(grpc.BidiStreamingServer[StreamCaptionsRequest, Caption]) error
+	mustEmbedUnimplementedCaptionsServer()
+}
+
+// UnimplementedCaptionsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCaptionsServer struct{}
+
+func (UnimplementedCaptionsServer) Transcribe(context.Context, *TranscribeRequest) (*TranscribeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transcribe not implemented")
+}
+func (UnimplementedCaptionsServer) Translate(context.Context, *TranslateRequest) (*TranslateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Translate not implemented")
+}
+func (UnimplementedCaptionsServer) Synthesize(*SynthesizeRequest, grpc.ServerStreamingServer[AudioChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method Synthesize not implemented")
+}
+func (UnimplementedCaptionsServer) CreateJob(grpc.ClientStreamingServer[CreateJobRequest, Job]) error {
+	return status.Errorf(codes.Unimplemented, "method CreateJob not implemented")
+}
+func (UnimplementedCaptionsServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedCaptionsServer) WatchJob(*GetJobRequest, grpc.ServerStreamingServer[JobProgress]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchJob not implemented")
+}
+func (UnimplementedCaptionsServer) StreamCaptions(grpc.BidiStreamingServer[StreamCaptionsRequest, Caption]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamCaptions not implemented")
+}
+func (UnimplementedCaptionsServer) mustEmbedUnimplementedCaptionsServer() {}
+func (UnimplementedCaptionsServer) testEmbeddedByValue()                  {}
+
+// UnsafeCaptionsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CaptionsServer will
+// result in compilation errors.
+type UnsafeCaptionsServer interface {
+	mustEmbedUnimplementedCaptionsServer()
+}
+
+func RegisterCaptionsServer(s grpc.ServiceRegistrar, srv CaptionsServer) {
+	// If the following call pancis, it indicates UnimplementedCaptionsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Captions_ServiceDesc, srv)
+}
+
+func _Captions_Transcribe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TranscribeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CaptionsServer).Transcribe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Captions_Transcribe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CaptionsServer).Transcribe(ctx, req.(*TranscribeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Captions_Translate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TranslateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CaptionsServer).Translate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Captions_Translate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CaptionsServer).Translate(ctx, req.(*TranslateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Captions_Synthesize_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SynthesizeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CaptionsServer).Synthesize(m, &grpc.GenericServerStream[SynthesizeRequest, AudioChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Captions_SynthesizeServer = grpc.ServerStreamingServer[AudioChunk]
+
+func _Captions_CreateJob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CaptionsServer).CreateJob(&grpc.GenericServerStream[CreateJobRequest, Job]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Captions_CreateJobServer = grpc.ClientStreamingServer[CreateJobRequest, Job]
+
+func _Captions_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CaptionsServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Captions_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CaptionsServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Captions_WatchJob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetJobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CaptionsServer).WatchJob(m, &grpc.GenericServerStream[GetJobRequest, JobProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Captions_WatchJobServer = grpc.ServerStreamingServer[JobProgress]
+
+func _Captions_StreamCaptions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CaptionsServer).StreamCaptions(&grpc.GenericServerStream[StreamCaptionsRequest, Caption]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Captions_StreamCaptionsServer = grpc.BidiStreamingServer[StreamCaptionsRequest, Caption]
+
+// Captions_ServiceDesc is the grpc.ServiceDesc for Captions service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Captions_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "captions.v1.Captions",
+	HandlerType: (*CaptionsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Transcribe",
+			Handler:    _Captions_Transcribe_Handler,
+		},
+		{
+			MethodName: "Translate",
+			Handler:    _Captions_Translate_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _Captions_GetJob_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Synthesize",
+			Handler:       _Captions_Synthesize_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "CreateJob",
+			Handler:       _Captions_CreateJob_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchJob",
+			Handler:       _Captions_WatchJob_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamCaptions",
+			Handler:       _Captions_StreamCaptions_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "captions.proto",
+}
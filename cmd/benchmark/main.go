@@ -0,0 +1,280 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"realtime-caption-translator/internal/asr"
+	"realtime-caption-translator/internal/benchmark"
+	"realtime-caption-translator/internal/database"
+)
+
+// backend is a named ASR endpoint under test
+type backend struct {
+	name string
+	url  string
+}
+
+// languageTotals accumulates scores for one language of a run
+type languageTotals struct {
+	samples int
+	wer     benchmark.ErrorRate
+	cer     benchmark.ErrorRate
+	latency time.Duration
+}
+
+func main() {
+	corpusPath := flag.String("corpus", "", "Path to the corpus manifest (JSONL: {\"id\",\"audio\",\"text\",\"language\"})")
+	backendsFlag := flag.String("asr", "", "Comma-separated ASR backends as name=url (default default=$ASR_BASE_URL)")
+	windowsFlag := flag.String("window", "0", "Comma-separated window sizes in seconds; 0 sends each file whole")
+	label := flag.String("label", "", "Free-form label stored with the run (e.g. model name)")
+	store := flag.Bool("store", true, "Store results in the database")
+	history := flag.Int("history", 0, "Print the N most recent stored runs and exit")
+	verbose := flag.Bool("v", false, "Print per-sample scores")
+	flag.Parse()
+
+	if *history > 0 {
+		printHistory(*history)
+		return
+	}
+
+	if *corpusPath == "" {
+		log.Fatal("-corpus is required")
+	}
+	samples, err := benchmark.LoadCorpus(*corpusPath)
+	if err != nil {
+		log.Fatalf("Failed to load corpus: %v", err)
+	}
+	if len(samples) == 0 {
+		log.Fatal("Corpus is empty")
+	}
+
+	backends, err := parseBackends(*backendsFlag)
+	if err != nil {
+		log.Fatalf("Invalid -asr: %v", err)
+	}
+	windows, err := parseWindows(*windowsFlag)
+	if err != nil {
+		log.Fatalf("Invalid -window: %v", err)
+	}
+
+	if *store {
+		if err := database.Init(); err != nil {
+			log.Fatalf("Database init failed: %v", err)
+		}
+		defer database.Close()
+	}
+
+	log.Printf("Benchmarking %d samples against %d backend(s), window sizes %v", len(samples), len(backends), windows)
+
+	for _, b := range backends {
+		for _, window := range windows {
+			run := runBenchmark(b, window, samples, *verbose)
+			run.Label = *label
+			run.Corpus = *corpusPath
+			printRun(run)
+
+			if *store {
+				if err := database.SaveBenchmarkRun(run); err != nil {
+					log.Printf("Failed to store run: %v", err)
+					continue
+				}
+				log.Printf("Stored run %d", run.ID)
+			}
+		}
+	}
+}
+
+func runBenchmark(b backend, windowSeconds int, samples []benchmark.Sample, verbose bool) *database.BenchmarkRun {
+	client := asr.New(b.url)
+	totals := make(map[string]*languageTotals)
+	run := &database.BenchmarkRun{
+		Backend:       b.name,
+		ASRURL:        b.url,
+		WindowSeconds: windowSeconds,
+		SampleCount:   len(samples),
+	}
+
+	for _, sample := range samples {
+		pcm, sampleRate, err := benchmark.ReadWAV(sample.AudioPath)
+		if err != nil {
+			log.Printf("[%s] %s: %v", b.name, sample.ID, err)
+			run.FailedCount++
+			continue
+		}
+
+		start := time.Now()
+		hypothesis, err := transcribe(client, pcm, sampleRate, sample.Language, windowSeconds)
+		elapsed := time.Since(start)
+		if err != nil {
+			log.Printf("[%s] %s: transcription failed: %v", b.name, sample.ID, err)
+			run.FailedCount++
+			continue
+		}
+
+		wer := benchmark.WER(sample.Text, hypothesis)
+		cer := benchmark.CER(sample.Text, hypothesis)
+		if verbose {
+			log.Printf("[%s] %s: WER %.3f CER %.3f (%s)\n  ref: %s\n  hyp: %s",
+				b.name, sample.ID, wer.Rate, cer.Rate, elapsed.Round(time.Millisecond), sample.Text, hypothesis)
+		}
+
+		language := sample.Language
+		if language == "" {
+			language = "auto"
+		}
+		t := totals[language]
+		if t == nil {
+			t = &languageTotals{}
+			totals[language] = t
+		}
+		t.samples++
+		t.wer.Add(wer)
+		t.cer.Add(cer)
+		t.latency += elapsed
+	}
+
+	languages := make([]string, 0, len(totals))
+	for language := range totals {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	for _, language := range languages {
+		t := totals[language]
+		run.Results = append(run.Results, database.BenchmarkResult{
+			Language:       language,
+			SampleCount:    t.samples,
+			WordErrors:     t.wer.Errors,
+			ReferenceWords: t.wer.Reference,
+			WER:            t.wer.Rate,
+			CharErrors:     t.cer.Errors,
+			ReferenceChars: t.cer.Reference,
+			CER:            t.cer.Rate,
+			AvgLatencyMs:   float64(t.latency.Milliseconds()) / float64(t.samples),
+		})
+	}
+
+	return run
+}
+
+// transcribe sends the whole file, or consecutive windows of it the way the
+// streaming path does, and joins the window transcripts
+func transcribe(client *asr.Client, pcm []int16, sampleRate int, language string, windowSeconds int) (string, error) {
+	if windowSeconds <= 0 {
+		return client.TranscribePCM16WithLang(pcm, sampleRate, language)
+	}
+
+	windowSize := sampleRate * windowSeconds
+	var parts []string
+	for start := 0; start < len(pcm); start += windowSize {
+		end := min(start+windowSize, len(pcm))
+		if end-start < sampleRate/4 { // skip trailing slivers the ASR can't use
+			break
+		}
+		text, err := client.TranscribePCM16WithLang(pcm[start:end], sampleRate, language)
+		if err != nil {
+			return "", err
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
+
+func parseBackends(value string) ([]backend, error) {
+	if value == "" {
+		return []backend{{name: "default", url: getEnv("ASR_BASE_URL", "http://127.0.0.1:8003")}}, nil
+	}
+
+	var backends []backend
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, url, found := strings.Cut(entry, "=")
+		if !found {
+			name, url = entry, entry
+		}
+		if url == "" {
+			return nil, fmt.Errorf("backend %q has no URL", name)
+		}
+		backends = append(backends, backend{name: name, url: url})
+	}
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("no backends given")
+	}
+	return backends, nil
+}
+
+func parseWindows(value string) ([]int, error) {
+	var windows []int
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		seconds, err := strconv.Atoi(entry)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("invalid window size %q", entry)
+		}
+		windows = append(windows, seconds)
+	}
+	if len(windows) == 0 {
+		windows = []int{0}
+	}
+	return windows, nil
+}
+
+func printRun(run *database.BenchmarkRun) {
+	window := "whole file"
+	if run.WindowSeconds > 0 {
+		window = fmt.Sprintf("%ds windows", run.WindowSeconds)
+	}
+	fmt.Printf("\n%s (%s) - %s, %d samples, %d failed\n", run.Backend, run.ASRURL, window, run.SampleCount, run.FailedCount)
+	fmt.Printf("  %-8s %7s %8s %8s %12s\n", "language", "samples", "WER", "CER", "avg latency")
+	for _, result := range run.Results {
+		fmt.Printf("  %-8s %7d %7.2f%% %7.2f%% %10.0fms\n",
+			result.Language, result.SampleCount, result.WER*100, result.CER*100, result.AvgLatencyMs)
+	}
+}
+
+func printHistory(limit int) {
+	if err := database.Init(); err != nil {
+		log.Fatalf("Database init failed: %v", err)
+	}
+	defer database.Close()
+
+	runs, err := database.ListBenchmarkRuns(limit)
+	if err != nil {
+		log.Fatalf("Failed to list runs: %v", err)
+	}
+	if len(runs) == 0 {
+		fmt.Println("No benchmark runs stored yet.")
+		return
+	}
+
+	for i := range runs {
+		run := &runs[i]
+		fmt.Printf("\n#%d %s", run.ID, run.CreatedAt.Format("2006-01-02 15:04"))
+		if run.Label != "" {
+			fmt.Printf(" [%s]", run.Label)
+		}
+		printRun(run)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
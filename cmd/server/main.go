@@ -1,19 +1,26 @@
 package main
 
 import (
+	"archive/zip"
 	"bufio"
+	"bytes"
 	"context"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"mime"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -24,21 +31,52 @@ import (
 	"github.com/gorilla/websocket"
 
 	"realtime-caption-translator/internal/asr"
+	"realtime-caption-translator/internal/audio/vad"
+	"realtime-caption-translator/internal/audioarchive"
 	"realtime-caption-translator/internal/auth"
+	"realtime-caption-translator/internal/cache"
 	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/document"
 	"realtime-caption-translator/internal/embedding"
+	"realtime-caption-translator/internal/estimate"
+	"realtime-caption-translator/internal/experiment"
+	"realtime-caption-translator/internal/export"
+	"realtime-caption-translator/internal/failures"
+	"realtime-caption-translator/internal/flags"
+	"realtime-caption-translator/internal/grpcapi"
+	"realtime-caption-translator/internal/hooks"
+	"realtime-caption-translator/internal/httpx"
+	"realtime-caption-translator/internal/jobs"
+	"realtime-caption-translator/internal/keepalive"
+	"realtime-caption-translator/internal/langcode"
+	"realtime-caption-translator/internal/lexicon"
 	"realtime-caption-translator/internal/llm"
+	"realtime-caption-translator/internal/logring"
 	"realtime-caption-translator/internal/meeting"
+	"realtime-caption-translator/internal/metrics"
+	"realtime-caption-translator/internal/notify"
+	"realtime-caption-translator/internal/profanity"
 	"realtime-caption-translator/internal/progress"
+	"realtime-caption-translator/internal/provenance"
+	"realtime-caption-translator/internal/quota"
 	"realtime-caption-translator/internal/rag"
+	"realtime-caption-translator/internal/ratelimit"
+	"realtime-caption-translator/internal/replica"
+	"realtime-caption-translator/internal/rerank"
 	"realtime-caption-translator/internal/session"
 	"realtime-caption-translator/internal/storage"
+	"realtime-caption-translator/internal/textfilter"
 	"realtime-caption-translator/internal/translate"
 	"realtime-caption-translator/internal/tts"
 	"realtime-caption-translator/internal/video"
+	"realtime-caption-translator/internal/voicepolicy"
+	"realtime-caption-translator/internal/webhook"
+	"realtime-caption-translator/internal/wsticket"
 )
 
 var upgrader = websocket.Upgrader{
+	// Echoed to clients that send a ticket as a subprotocol
+	Subprotocols: []string{wsticket.Protocol},
 	CheckOrigin: func(r *http.Request) bool {
 		// Get allowed origins from environment variable (comma-separated)
 		// Example: ALLOWED_ORIGINS=http://localhost:3000,https://yourdomain.com
@@ -316,6 +354,29 @@ func isLocalRequest(r *http.Request) bool {
 	return host == "127.0.0.1" || host == "::1" || host == "localhost"
 }
 
+// requireMetricsAccess allows scrapes from localhost, or from anywhere with
+// the bearer token when one is configured
+func requireMetricsAccess(token string, next http.Handler) http.Handler {
+	return requireLocalOrToken(token, "Metrics are only available from localhost or with METRICS_TOKEN", next)
+}
+
+// requireLocalOrToken allows requests from localhost, or from anywhere with
+// the bearer token when one is configured
+func requireLocalOrToken(token, denied string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorized := isLocalRequest(r)
+		if !authorized && token != "" {
+			provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			authorized = subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+		}
+		if !authorized {
+			sendJSONError(w, http.StatusForbidden, denied)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func buildDiagnosticsRecommendations(mem memoryInfo, containers []containerDiagnostics) []diagnosticsRecommendation {
 	const minAvailableBytes = int64(2 * 1024 * 1024 * 1024)
 
@@ -499,2513 +560,10631 @@ func handleDiagnosticsService(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func resolveMeetingID(meetingID string) (string, error) {
-	meeting, err := database.GetMeetingByID(meetingID)
+// handleAdminFailedChunks lists failed RAG chunks and status counts for a meeting
+func handleAdminFailedChunks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if !isLocalRequest(r) {
+		sendJSONError(w, http.StatusForbidden, "Admin API available only on localhost")
+		return
+	}
+
+	// Extract meeting ID from URL path: /api/admin/rag/failed-chunks/{meetingId}
+	path := strings.TrimPrefix(r.URL.Path, "/api/admin/rag/failed-chunks/")
+	meetingID := strings.TrimSuffix(path, "/")
+	if meetingID == "" {
+		sendJSONError(w, http.StatusBadRequest, "Meeting ID required")
+		return
+	}
+
+	resolvedID, err := resolveMeetingID(meetingID)
 	if err != nil {
-		return "", err
+		log.Printf("Failed to resolve meeting: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to resolve meeting")
+		return
 	}
-	if meeting != nil {
-		return meeting.ID, nil
+	if resolvedID == "" {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
 	}
 
-	meeting, err = database.GetMeetingByRoomCode(meetingID)
+	chunks, err := database.GetFailedChunks(resolvedID, languageParam(r, "language"))
 	if err != nil {
-		return "", err
+		log.Printf("Failed to get failed chunks: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to get failed chunks")
+		return
 	}
-	if meeting != nil {
-		return meeting.ID, nil
+	if chunks == nil {
+		chunks = []database.MeetingChunk{}
 	}
 
-	return "", nil
-}
+	counts, err := database.GetChunkStatusCounts(resolvedID)
+	if err != nil {
+		log.Printf("Failed to count chunk statuses: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to get chunk status")
+		return
+	}
 
-type videoUploadResponse struct {
-	Success       bool    `json:"success"`
-	SessionID     string  `json:"sessionId,omitempty"`
-	Transcription string  `json:"transcription,omitempty"`
-	Translation   string  `json:"translation,omitempty"`
-	Duration      float64 `json:"duration,omitempty"`
-	VideoPath     string  `json:"videoPath,omitempty"`
-	DetectedLang  string  `json:"detectedLang,omitempty"`
-	Error         string  `json:"error,omitempty"`
+	writeJSON(w, map[string]interface{}{
+		"success":      true,
+		"meetingId":    resolvedID,
+		"statusCounts": counts,
+		"maxAttempts":  rag.MaxChunkAttempts,
+		"failedChunks": chunks,
+	})
 }
 
-type authResponse struct {
-	Success bool           `json:"success"`
-	User    *database.User `json:"user,omitempty"`
-	Error   string         `json:"error,omitempty"`
+// adminOverviewDeps carries the live components summarized by /api/admin/overview
+type adminOverviewDeps struct {
+	roomManager *meeting.RoomManager
+	limiters    []*ratelimit.Limiter
+	services    map[string]string // name -> base URL
+	tempDir     string
+	errorLog    *logring.Ring
 }
 
-func handleKeycloakLogin(verifier *auth.KeycloakVerifier) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
-			return
-		}
+type serviceHealth struct {
+	Name       string  `json:"name"`
+	URL        string  `json:"url"`
+	Healthy    bool    `json:"healthy"`
+	StatusCode int     `json:"statusCode,omitempty"`
+	LatencyMs  float64 `json:"latencyMs"`
+	Error      string  `json:"error,omitempty"`
+}
 
-		if verifier == nil {
-			sendJSONError(w, http.StatusServiceUnavailable, "Keycloak auth not configured")
-			return
-		}
+// checkServiceHealth calls a downstream service's /health endpoint
+func checkServiceHealth(ctx context.Context, name, baseURL string) serviceHealth {
+	health := serviceHealth{Name: name, URL: baseURL}
 
-		tokenStr, err := extractBearerToken(r)
-		if err != nil {
-			sendJSONError(w, http.StatusUnauthorized, err.Error())
-			return
-		}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+"/health", nil)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
 
-		claims, err := verifier.VerifyToken(r.Context(), tokenStr)
-		if err != nil {
-			sendJSONError(w, http.StatusUnauthorized, "Invalid token")
-			return
-		}
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	health.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	resp.Body.Close()
 
-		user, err := upsertUserFromClaims(claims)
-		if err != nil {
-			log.Printf("Keycloak upsert failed: %v", err)
-			sendJSONError(w, http.StatusInternalServerError, "Failed to persist user")
-			return
-		}
+	health.StatusCode = resp.StatusCode
+	health.Healthy = resp.StatusCode >= 200 && resp.StatusCode < 300
+	return health
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(authResponse{
-			Success: true,
-			User:    user,
-		})
-	}
+// checkServicesHealth checks every service concurrently, sorted by name
+func checkServicesHealth(ctx context.Context, services map[string]string) []serviceHealth {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]serviceHealth, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = checkServiceHealth(ctx, name, services[name])
+		}(i, name)
+	}
+	wg.Wait()
+	return results
 }
 
-type historyVideoRequest struct {
-	SessionID       string     `json:"sessionId"`
-	Filename        string     `json:"filename"`
-	Transcription   string     `json:"transcription,omitempty"`
-	Translation     string     `json:"translation,omitempty"`
-	VideoPath       string     `json:"videoPath,omitempty"`
-	AudioPath       string     `json:"audioPath,omitempty"`
-	TTSPath         string     `json:"ttsPath,omitempty"`
-	SourceLang      string     `json:"sourceLang,omitempty"`
-	TargetLang      string     `json:"targetLang,omitempty"`
-	DurationSeconds int        `json:"durationSeconds,omitempty"`
-	ExpiresAt       *time.Time `json:"expiresAt,omitempty"`
+// dependencyCheck is the result of probing a local dependency
+type dependencyCheck struct {
+	Healthy   bool    `json:"healthy"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
 }
 
-type historyAudioRequest struct {
-	SessionID      string          `json:"sessionId"`
-	Filename       string          `json:"filename"`
-	Transcription  string          `json:"transcription,omitempty"`
-	Translation    string          `json:"translation,omitempty"`
-	AudioPath      string          `json:"audioPath,omitempty"`
-	SourceLang     string          `json:"sourceLang,omitempty"`
-	TargetLang     string          `json:"targetLang,omitempty"`
-	HasDiarization bool            `json:"hasDiarization,omitempty"`
-	NumSpeakers    int             `json:"numSpeakers,omitempty"`
-	Segments       json.RawMessage `json:"segments,omitempty"`
+// checkDependency times check and records its error
+func checkDependency(check func() error) dependencyCheck {
+	start := time.Now()
+	err := check()
+	result := dependencyCheck{
+		Healthy:   err == nil,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
 }
 
-type historyStreamingRequest struct {
-	SessionID            string `json:"sessionId"`
-	SourceLang           string `json:"sourceLang,omitempty"`
-	TargetLang           string `json:"targetLang,omitempty"`
-	TotalChunks          int    `json:"totalChunks,omitempty"`
-	TotalDurationSeconds int    `json:"totalDurationSeconds,omitempty"`
-	FinalTranscript      string `json:"finalTranscript,omitempty"`
-	FinalTranslation     string `json:"finalTranslation,omitempty"`
+// handleHealth probes the server for orchestrators. /healthz checks what
+// the process itself needs (database, ffmpeg); /readyz also requires the
+// model services to be reachable. Both answer 503 when a check fails.
+//
+//	GET /healthz
+//	GET /readyz
+func handleHealth(w http.ResponseWriter, r *http.Request, services map[string]string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	checks := map[string]dependencyCheck{
+		"database": checkDependency(database.HealthCheck),
+		"ffmpeg":   checkDependency(video.CheckFFmpegInstalled),
+	}
+	healthy := true
+	for _, check := range checks {
+		healthy = healthy && check.Healthy
+	}
+
+	response := map[string]interface{}{
+		"timestamp": time.Now().UTC(),
+		"checks":    checks,
+	}
+	if r.URL.Path == "/readyz" {
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+		serviceResults := checkServicesHealth(ctx, services)
+		for _, service := range serviceResults {
+			healthy = healthy && service.Healthy
+		}
+		response["services"] = serviceResults
+	}
+
+	status := http.StatusOK
+	response["status"] = "ok"
+	if !healthy {
+		status = http.StatusServiceUnavailable
+		response["status"] = "unavailable"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
 }
 
-type userFileRequest struct {
-	SessionType   string     `json:"sessionType"`
-	SessionID     string     `json:"sessionId"`
-	BucketName    string     `json:"bucketName"`
-	FileKey       string     `json:"fileKey"`
-	ContentHash   string     `json:"contentHash,omitempty"`
-	Etag          string     `json:"etag,omitempty"`
-	MimeType      string     `json:"mimeType,omitempty"`
-	FileSizeBytes int64      `json:"fileSizeBytes,omitempty"`
-	AccessedAt    *time.Time `json:"accessedAt,omitempty"`
+// dirSize returns the total size of regular files under dir
+func dirSize(dir string) int64 {
+	var total int64
+	filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
 }
 
-type historyResponse struct {
-	Success bool   `json:"success"`
-	ID      int    `json:"id,omitempty"`
-	Error   string `json:"error,omitempty"`
+// handleAdminOverview aggregates live operational metrics (localhost only):
+// meetings, job queues, downstream service health, storage and recent errors
+func handleAdminOverview(w http.ResponseWriter, r *http.Request, deps adminOverviewDeps) {
+	if r.Method != http.MethodGet {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if !isLocalRequest(r) {
+		sendJSONError(w, http.StatusForbidden, "Admin API available only on localhost")
+		return
+	}
+
+	// Service health checks run concurrently with a shared deadline
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	var services []serviceHealth
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		services = checkServicesHealth(ctx, deps.services)
+	}()
+
+	chunkCounts, err := database.GetGlobalChunkStatusCounts()
+	if err != nil {
+		log.Printf("Admin overview chunk counts failed: %v", err)
+	}
+	jobCounts, err := database.GetJobStatusCounts()
+	if err != nil {
+		log.Printf("Admin overview job counts failed: %v", err)
+	}
+	storageUsage, err := database.GetStorageUsage()
+	if err != nil {
+		log.Printf("Admin overview storage usage failed: %v", err)
+	}
+
+	limiterStats := make([]ratelimit.Stats, 0, len(deps.limiters))
+	for _, limiter := range deps.limiters {
+		limiterStats = append(limiterStats, limiter.Stats())
+	}
+
+	wg.Wait()
+
+	writeJSON(w, map[string]interface{}{
+		"success":   true,
+		"timestamp": time.Now().UTC(),
+		"meetings": map[string]int{
+			"activeRooms":        deps.roomManager.GetActiveRoomCount(),
+			"activeParticipants": deps.roomManager.GetActiveParticipantCount(),
+		},
+		"queues": map[string]interface{}{
+			"jobs":      jobCounts,
+			"ragChunks": chunkCounts,
+			"limiters":  limiterStats,
+		},
+		"services": services,
+		"storage": map[string]interface{}{
+			"usage":        storageUsage,
+			"tempDirBytes": dirSize(deps.tempDir),
+		},
+		"recentErrors": deps.errorLog.Recent(50),
+	})
 }
 
-func handleCreateVideoHistory(verifier *auth.KeycloakVerifier) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+// handleAdminFlags manages feature flags (localhost only):
+//
+//	GET    /api/admin/flags                   - definitions, global state and overrides
+//	PUT    /api/admin/flags/{key}             - {"enabled": bool, "description": "..."}
+//	PUT    /api/admin/flags/{key}/overrides   - {"scopeType": "org|user", "scopeId": "...", "enabled": bool}
+//	DELETE /api/admin/flags/{key}/overrides?scopeType=...&scopeId=...
+func handleAdminFlags(w http.ResponseWriter, r *http.Request) {
+	if !isLocalRequest(r) {
+		sendJSONError(w, http.StatusForbidden, "Admin API available only on localhost")
+		return
+	}
+
+	pathParts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/flags"), "/"), "/")
+	key := pathParts[0]
+
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		stored, err := database.ListFeatureFlags()
+		if err != nil {
+			log.Printf("Failed to list feature flags: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to list flags")
 			return
 		}
-
-		user, ok := authenticateUserFromRequest(verifier, w, r)
-		if !ok {
+		overrides, err := database.ListFeatureFlagOverrides()
+		if err != nil {
+			log.Printf("Failed to list feature flag overrides: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to list flags")
 			return
 		}
+		writeJSON(w, map[string]interface{}{
+			"success":     true,
+			"definitions": flags.Definitions,
+			"flags":       stored,
+			"overrides":   overrides,
+		})
 
-		var req historyVideoRequest
+	case key != "" && len(pathParts) == 1 && r.Method == http.MethodPut:
+		var req struct {
+			Enabled     bool   `json:"enabled"`
+			Description string `json:"description,omitempty"`
+		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			sendJSONError(w, http.StatusBadRequest, "Invalid request")
 			return
 		}
+		if err := database.SetFeatureFlag(key, req.Enabled, req.Description); err != nil {
+			log.Printf("Failed to set feature flag: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to set flag")
+			return
+		}
+		flags.Invalidate()
+		writeJSON(w, map[string]interface{}{"success": true, "key": key, "enabled": req.Enabled})
 
-		id, err := database.CreateUserVideoSession(user.ID, database.UserVideoSessionInput{
-			SessionID:       req.SessionID,
-			Filename:        req.Filename,
-			Transcription:   req.Transcription,
-			Translation:     req.Translation,
-			VideoPath:       req.VideoPath,
-			AudioPath:       req.AudioPath,
-			TTSPath:         req.TTSPath,
-			SourceLang:      req.SourceLang,
-			TargetLang:      req.TargetLang,
-			DurationSeconds: req.DurationSeconds,
-			ExpiresAt:       req.ExpiresAt,
-		})
-		if err != nil {
-			log.Printf("Create video history failed: %v", err)
-			sendJSONError(w, http.StatusInternalServerError, "Failed to store history")
+	case key != "" && len(pathParts) == 2 && pathParts[1] == "overrides" && r.Method == http.MethodPut:
+		var req struct {
+			ScopeType string `json:"scopeType"`
+			ScopeID   string `json:"scopeId"`
+			Enabled   bool   `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ScopeID == "" {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+		if req.ScopeType != database.FlagScopeOrg && req.ScopeType != database.FlagScopeUser {
+			sendJSONError(w, http.StatusBadRequest, "scopeType must be org or user")
+			return
+		}
+		if err := database.SetFeatureFlagOverride(key, req.ScopeType, req.ScopeID, req.Enabled); err != nil {
+			log.Printf("Failed to set feature flag override: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to set override")
 			return
 		}
+		flags.Invalidate()
+		writeJSON(w, map[string]interface{}{"success": true})
+
+	case key != "" && len(pathParts) == 2 && pathParts[1] == "overrides" && r.Method == http.MethodDelete:
+		scopeType := r.URL.Query().Get("scopeType")
+		scopeID := r.URL.Query().Get("scopeId")
+		if scopeType == "" || scopeID == "" {
+			sendJSONError(w, http.StatusBadRequest, "scopeType and scopeId are required")
+			return
+		}
+		if err := database.DeleteFeatureFlagOverride(key, scopeType, scopeID); err != nil {
+			log.Printf("Failed to delete feature flag override: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to delete override")
+			return
+		}
+		flags.Invalidate()
+		writeJSON(w, map[string]interface{}{"success": true})
 
-		writeJSON(w, historyResponse{Success: true, ID: id})
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 	}
 }
 
-func handleCreateAudioHistory(verifier *auth.KeycloakVerifier) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+// handleAdminMeetingLimits manages per-org meeting limits (localhost only).
+// Orgs are email domains; limits apply to meetings created afterwards.
+//
+//	GET    /api/admin/meeting-limits         - all org limits
+//	PUT    /api/admin/meeting-limits/{org}   - {"maxDurationMinutes": 120}
+//	DELETE /api/admin/meeting-limits/{org}
+func handleAdminMeetingLimits(w http.ResponseWriter, r *http.Request) {
+	if !isLocalRequest(r) {
+		sendJSONError(w, http.StatusForbidden, "Admin API available only on localhost")
+		return
+	}
+
+	orgID := strings.ToLower(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/meeting-limits"), "/"))
+
+	switch {
+	case orgID == "" && r.Method == http.MethodGet:
+		settings, err := database.ListOrgMeetingSettings()
+		if err != nil {
+			log.Printf("Failed to list org meeting settings: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to list meeting limits")
 			return
 		}
+		writeJSON(w, map[string]interface{}{"success": true, "orgs": settings})
 
-		user, ok := authenticateUserFromRequest(verifier, w, r)
-		if !ok {
+	case orgID != "" && r.Method == http.MethodPut:
+		var req struct {
+			MaxDurationMinutes int `json:"maxDurationMinutes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MaxDurationMinutes <= 0 {
+			sendJSONError(w, http.StatusBadRequest, "maxDurationMinutes must be a positive number of minutes")
+			return
+		}
+		if err := database.SetOrgMeetingMaxDuration(orgID, req.MaxDurationMinutes); err != nil {
+			log.Printf("Failed to set org meeting settings: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to set meeting limits")
 			return
 		}
+		log.Printf("[Admin] Meeting duration limit for org %s set to %d minutes", orgID, req.MaxDurationMinutes)
+		writeJSON(w, map[string]interface{}{"success": true, "orgId": orgID, "maxDurationMinutes": req.MaxDurationMinutes})
 
-		var req historyAudioRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			sendJSONError(w, http.StatusBadRequest, "Invalid request")
-			return
-		}
-
-		hasDiarization := req.HasDiarization || len(req.Segments) > 0
-
-		id, err := database.CreateUserAudioSession(user.ID, database.UserAudioSessionInput{
-			SessionID:      req.SessionID,
-			Filename:       req.Filename,
-			Transcription:  req.Transcription,
-			Translation:    req.Translation,
-			AudioPath:      req.AudioPath,
-			SourceLang:     req.SourceLang,
-			TargetLang:     req.TargetLang,
-			HasDiarization: hasDiarization,
-			NumSpeakers:    req.NumSpeakers,
-			Segments:       req.Segments,
-		})
-		if err != nil {
-			log.Printf("Create audio history failed: %v", err)
-			sendJSONError(w, http.StatusInternalServerError, "Failed to store history")
+	case orgID != "" && r.Method == http.MethodDelete:
+		if err := database.DeleteOrgMeetingSettings(orgID); err != nil {
+			log.Printf("Failed to delete org meeting settings: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to delete meeting limits")
 			return
 		}
+		writeJSON(w, map[string]interface{}{"success": true})
 
-		writeJSON(w, historyResponse{Success: true, ID: id})
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 	}
 }
 
-func handleCreateStreamingHistory(verifier *auth.KeycloakVerifier) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
+// handleAdminUserQuotas manages per-user quota overrides (localhost only).
+// Omitted or null fields keep the server default; 0 means unlimited.
+//
+//	GET    /api/admin/user-quotas            - defaults and all overrides
+//	GET    /api/admin/user-quotas/{userId}   - limits and usage of one user
+//	PUT    /api/admin/user-quotas/{userId}   - {"audioMinutesPerDay": 60, "concurrentJobs": 2, "storageBytes": 1073741824}
+//	DELETE /api/admin/user-quotas/{userId}
+func handleAdminUserQuotas(w http.ResponseWriter, r *http.Request) {
+	if !isLocalRequest(r) {
+		sendJSONError(w, http.StatusForbidden, "Admin API available only on localhost")
+		return
+	}
+
+	idStr := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/user-quotas"), "/")
+	if idStr == "" {
+		if r.Method != http.MethodGet {
 			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 			return
 		}
+		quotas, err := database.ListUserQuotas()
+		if err != nil {
+			log.Printf("Failed to list user quotas: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to list user quotas")
+			return
+		}
+		writeJSON(w, map[string]interface{}{"success": true, "defaults": quota.Defaults(), "users": quotas})
+		return
+	}
+	userID, err := strconv.Atoi(idStr)
+	if err != nil || userID <= 0 {
+		sendJSONError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
 
-		user, ok := authenticateUserFromRequest(verifier, w, r)
-		if !ok {
+	switch r.Method {
+	case http.MethodGet:
+		status, err := quota.Load(userID)
+		if err != nil {
+			log.Printf("Failed to load quota of user %d: %v", userID, err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load user quota")
 			return
 		}
+		writeJSON(w, map[string]interface{}{"success": true, "userId": userID, "quota": status, "remaining": status.Remaining()})
 
-		var req historyStreamingRequest
+	case http.MethodPut:
+		var req database.UserQuota
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			sendJSONError(w, http.StatusBadRequest, "Invalid request")
 			return
 		}
+		if (req.AudioMinutesPerDay != nil && *req.AudioMinutesPerDay < 0) ||
+			(req.ConcurrentJobs != nil && *req.ConcurrentJobs < 0) ||
+			(req.StorageBytes != nil && *req.StorageBytes < 0) {
+			sendJSONError(w, http.StatusBadRequest, "Quotas must not be negative")
+			return
+		}
+		req.UserID = userID
+		if err := database.SetUserQuota(&req); err != nil {
+			log.Printf("Failed to set quota of user %d: %v", userID, err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to set user quota")
+			return
+		}
+		log.Printf("[Admin] Quota overrides of user %d updated", userID)
+		writeJSON(w, map[string]interface{}{"success": true, "quota": req})
 
-		id, err := database.CreateUserStreamingSession(user.ID, database.UserStreamingSessionInput{
-			SessionID:            req.SessionID,
-			SourceLang:           req.SourceLang,
-			TargetLang:           req.TargetLang,
-			TotalChunks:          req.TotalChunks,
-			TotalDurationSeconds: req.TotalDurationSeconds,
-			FinalTranscript:      req.FinalTranscript,
-			FinalTranslation:     req.FinalTranslation,
-		})
-		if err != nil {
-			log.Printf("Create streaming history failed: %v", err)
-			sendJSONError(w, http.StatusInternalServerError, "Failed to store history")
+	case http.MethodDelete:
+		if err := database.DeleteUserQuota(userID); err != nil {
+			log.Printf("Failed to delete quota of user %d: %v", userID, err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to delete user quota")
 			return
 		}
+		writeJSON(w, map[string]interface{}{"success": true})
 
-		writeJSON(w, historyResponse{Success: true, ID: id})
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 	}
 }
 
-func handleCreateUserFile(verifier *auth.KeycloakVerifier) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
-			return
-		}
+// handleAdminTTSPolicies manages per-org TTS policies (localhost only). The
+// org "global" is the default for orgs without their own policy.
+//
+//	GET    /api/admin/tts-policies
+//	PUT    /api/admin/tts-policies/{org}   - {"allowedVoices": [...], "defaultVoice", "allowVoiceCloning", "profanityFilter": "off|standard|kid_safe"}
+//	DELETE /api/admin/tts-policies/{org}
+func handleAdminTTSPolicies(w http.ResponseWriter, r *http.Request) {
+	if !isLocalRequest(r) {
+		sendJSONError(w, http.StatusForbidden, "Admin API available only on localhost")
+		return
+	}
 
-		user, ok := authenticateUserFromRequest(verifier, w, r)
-		if !ok {
+	orgID := strings.ToLower(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/tts-policies"), "/"))
+	listing := orgID == ""
+	if orgID == "global" {
+		orgID = ""
+	}
+
+	switch {
+	case listing && r.Method == http.MethodGet:
+		policies, err := database.ListOrgTTSPolicies()
+		if err != nil {
+			log.Printf("Failed to list TTS policies: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to list TTS policies")
 			return
 		}
+		writeJSON(w, map[string]interface{}{"success": true, "policies": policies})
 
-		var req userFileRequest
+	case !listing && r.Method == http.MethodPut:
+		var req struct {
+			AllowedVoices     []string `json:"allowedVoices"`
+			DefaultVoice      string   `json:"defaultVoice"`
+			AllowVoiceCloning *bool    `json:"allowVoiceCloning"`
+			ProfanityFilter   string   `json:"profanityFilter"`
+		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			sendJSONError(w, http.StatusBadRequest, "Invalid request")
 			return
 		}
+		policy := &database.OrgTTSPolicy{
+			OrgID:             orgID,
+			DefaultVoice:      strings.TrimSpace(req.DefaultVoice),
+			AllowVoiceCloning: true,
+			ProfanityFilter:   req.ProfanityFilter,
+		}
+		for _, voice := range req.AllowedVoices {
+			if voice = strings.TrimSpace(voice); voice != "" {
+				policy.AllowedVoices = append(policy.AllowedVoices, voice)
+			}
+		}
+		if req.AllowVoiceCloning != nil {
+			policy.AllowVoiceCloning = *req.AllowVoiceCloning
+		}
+		if policy.ProfanityFilter == "" {
+			policy.ProfanityFilter = profanity.LevelOff
+		}
+		if !profanity.Valid(policy.ProfanityFilter) {
+			sendJSONError(w, http.StatusBadRequest, "profanityFilter must be off, standard or kid_safe")
+			return
+		}
+		// Kid-safe output never uses a cloned voice
+		if policy.ProfanityFilter == profanity.LevelKidSafe {
+			policy.AllowVoiceCloning = false
+		}
+		if policy.DefaultVoice != "" && !voicepolicy.FromRecord(*policy).AllowsVoice(policy.DefaultVoice) {
+			sendJSONError(w, http.StatusBadRequest, "defaultVoice must be one of allowedVoices")
+			return
+		}
 
-		id, err := database.CreateUserFile(&user.ID, database.UserFileInput{
-			SessionType:   req.SessionType,
-			SessionID:     req.SessionID,
-			BucketName:    req.BucketName,
-			FileKey:       req.FileKey,
-			ContentHash:   req.ContentHash,
-			Etag:          req.Etag,
-			MimeType:      req.MimeType,
-			FileSizeBytes: req.FileSizeBytes,
-			AccessedAt:    req.AccessedAt,
+		if err := database.SetOrgTTSPolicy(policy); err != nil {
+			log.Printf("Failed to set TTS policy: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to set TTS policy")
+			return
+		}
+		voicepolicy.Invalidate()
+		log.Printf("[Admin] TTS policy for org %q set: voices=%v cloning=%t profanity=%s",
+			orgID, policy.AllowedVoices, policy.AllowVoiceCloning, policy.ProfanityFilter)
+		writeJSON(w, map[string]interface{}{"success": true, "policy": policy})
+
+	case !listing && r.Method == http.MethodDelete:
+		if err := database.DeleteOrgTTSPolicy(orgID); err != nil {
+			log.Printf("Failed to delete TTS policy: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to delete TTS policy")
+			return
+		}
+		voicepolicy.Invalidate()
+		writeJSON(w, map[string]interface{}{"success": true})
+
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleNotifications serves the signed-in user's notification center:
+//
+//	GET    /api/notifications?unread=true&limit=50&offset=0
+//	POST   /api/notifications/read-all
+//	POST   /api/notifications/{id}/read
+//	DELETE /api/notifications/{id}
+//	GET    /api/notifications/preferences
+//	PUT    /api/notifications/preferences   - {"preferences": {"minutes_ready": false}}
+func handleNotifications(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	pathParts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/notifications"), "/"), "/")
+
+	switch {
+	case pathParts[0] == "" && r.Method == http.MethodGet:
+		unreadOnly := r.URL.Query().Get("unread") == "true"
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+		notifications, err := database.ListNotifications(user.ID, unreadOnly, limit, offset)
+		if err != nil {
+			log.Printf("Failed to list notifications: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to list notifications")
+			return
+		}
+		unread, err := database.CountUnreadNotifications(user.ID)
+		if err != nil {
+			log.Printf("Failed to count notifications: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to list notifications")
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"success":       true,
+			"notifications": notifications,
+			"unreadCount":   unread,
 		})
+
+	case pathParts[0] == "read-all" && r.Method == http.MethodPost:
+		updated, err := database.MarkAllNotificationsRead(user.ID)
 		if err != nil {
-			log.Printf("Create user file failed: %v", err)
-			sendJSONError(w, http.StatusInternalServerError, "Failed to store file metadata")
+			log.Printf("Failed to mark notifications read: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to update notifications")
 			return
 		}
+		writeJSON(w, map[string]interface{}{"success": true, "updated": updated})
 
-		writeJSON(w, historyResponse{Success: true, ID: id})
+	case pathParts[0] == "preferences":
+		handleNotificationPreferences(w, r, user)
+
+	default:
+		notificationID, err := strconv.Atoi(pathParts[0])
+		if err != nil {
+			sendJSONError(w, http.StatusNotFound, "Not found")
+			return
+		}
+
+		var found bool
+		switch {
+		case len(pathParts) == 2 && pathParts[1] == "read" && r.Method == http.MethodPost:
+			found, err = database.MarkNotificationRead(user.ID, notificationID)
+		case len(pathParts) == 1 && r.Method == http.MethodDelete:
+			found, err = database.DeleteNotification(user.ID, notificationID)
+		default:
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to update notification %d: %v", notificationID, err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to update notification")
+			return
+		}
+		if !found {
+			sendJSONError(w, http.StatusNotFound, "Notification not found")
+			return
+		}
+		writeJSON(w, map[string]interface{}{"success": true})
 	}
 }
 
-func extractBearerToken(r *http.Request) (string, error) {
-	authHeader := strings.TrimSpace(r.Header.Get("Authorization"))
-	if authHeader == "" {
-		return "", fmt.Errorf("Authorization header missing")
+// handleNotificationPreferences reads or updates per-type notification settings
+func handleNotificationPreferences(w http.ResponseWriter, r *http.Request, user *database.User) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req struct {
+			Preferences map[string]bool `json:"preferences"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+		for notificationType := range req.Preferences {
+			if !notify.IsKnownType(notificationType) {
+				sendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Unknown notification type: %s", notificationType))
+				return
+			}
+		}
+		for notificationType, enabled := range req.Preferences {
+			if err := database.SetNotificationPreference(user.ID, notificationType, enabled); err != nil {
+				log.Printf("Failed to save notification preference: %v", err)
+				sendJSONError(w, http.StatusInternalServerError, "Failed to save preferences")
+				return
+			}
+		}
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
 	}
 
-	parts := strings.SplitN(authHeader, " ", 2)
-	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-		return "", fmt.Errorf("Authorization header must be Bearer token")
+	stored, err := database.GetNotificationPreferences(user.ID)
+	if err != nil {
+		log.Printf("Failed to load notification preferences: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load preferences")
+		return
 	}
-
-	token := strings.TrimSpace(parts[1])
-	if token == "" {
-		return "", fmt.Errorf("Bearer token is empty")
+	preferences := make(map[string]bool, len(notify.Types))
+	for _, notificationType := range notify.Types {
+		enabled, ok := stored[notificationType]
+		preferences[notificationType] = !ok || enabled
 	}
 
-	return token, nil
+	writeJSON(w, map[string]interface{}{"success": true, "preferences": preferences})
 }
 
-func authenticateUserFromRequest(verifier *auth.KeycloakVerifier, w http.ResponseWriter, r *http.Request) (*database.User, bool) {
-	if verifier == nil {
-		sendJSONError(w, http.StatusServiceUnavailable, "Keycloak auth not configured")
-		return nil, false
+// handleNotificationsWebSocket pushes new notifications to the signed-in user.
+// Browsers cannot set headers on WebSocket requests, so the access token may
+// also be passed as ?token=.
+func handleNotificationsWebSocket(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
 	}
 
-	tokenStr, err := extractBearerToken(r)
+	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		sendJSONError(w, http.StatusUnauthorized, err.Error())
-		return nil, false
+		log.Println("Notifications WebSocket upgrade error:", err)
+		return
 	}
+	defer conn.Close()
 
-	claims, err := verifier.VerifyToken(r.Context(), tokenStr)
-	if err != nil {
-		sendJSONError(w, http.StatusUnauthorized, "Invalid token")
-		return nil, false
+	unsubscribe := notify.Subscribe(user.ID, conn)
+	defer unsubscribe()
+
+	alive := keepalive.Start(conn)
+	defer alive.Stop()
+
+	// Keep connection alive until the client goes away
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+		alive.Touch()
 	}
+}
 
-	user, err := upsertUserFromClaims(claims)
-	if err != nil {
-		log.Printf("Keycloak upsert failed: %v", err)
-		sendJSONError(w, http.StatusInternalServerError, "Failed to persist user")
-		return nil, false
+// handleUserFlags returns the feature flags evaluated for the authenticated user
+func handleUserFlags(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
+	if r.Method != http.MethodGet {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
 	}
 
-	return user, true
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"success": true,
+		"flags":   flags.Evaluate(flags.SubjectForUser(user)),
+	})
 }
 
-func maybeAuthenticateUserFromRequest(verifier *auth.KeycloakVerifier, r *http.Request) (*database.User, error) {
-	if verifier == nil {
-		return nil, nil
+// handleUserQuota returns the authenticated user's quotas, usage and what
+// remains, with the remaining allowance also in headers
+func handleUserQuota(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
+	if r.Method != http.MethodGet {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
 	}
 
-	authHeader := strings.TrimSpace(r.Header.Get("Authorization"))
-	if authHeader == "" {
-		return nil, nil
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
 	}
 
-	tokenStr, err := extractBearerToken(r)
+	status, err := quota.Load(user.ID)
 	if err != nil {
-		return nil, err
+		log.Printf("Failed to load quota of user %d: %v", user.ID, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load quota")
+		return
 	}
+	status.SetHeaders(w.Header())
+	writeJSON(w, map[string]interface{}{
+		"success":   true,
+		"quota":     status,
+		"remaining": status.Remaining(),
+	})
+}
 
+// isOrgAdmin reports whether the request's token carries the org admin realm
+// role (ORG_ADMIN_ROLE, default "org-admin"), which allows changing settings
+// shared by everyone in the caller's org
+func isOrgAdmin(verifier *auth.KeycloakVerifier, r *http.Request) bool {
+	if verifier == nil {
+		return false
+	}
+	tokenStr, err := extractBearerToken(r)
+	if err != nil {
+		return false
+	}
 	claims, err := verifier.VerifyToken(r.Context(), tokenStr)
 	if err != nil {
-		return nil, err
+		return false
 	}
-
-	return upsertUserFromClaims(claims)
+	return auth.HasRealmRole(claims, getEnv("ORG_ADMIN_ROLE", "org-admin"))
 }
 
-func upsertUserFromClaims(claims map[string]interface{}) (*database.User, error) {
-	sub, _ := claims["sub"].(string)
-	preferredUsername, _ := claims["preferred_username"].(string)
-	displayName, _ := claims["name"].(string)
-	email, _ := claims["email"].(string)
-	emailVerified := parseEmailVerified(claims["email_verified"])
-
-	return database.UpsertKeycloakUser(sub, preferredUsername, email, emailVerified, displayName)
-}
+// handleLexicon manages the pronunciation lexicon of the signed-in user's org
+// (domain of a verified email). Entries rewrite text before TTS, e.g.
+// "Nguyen" -> "Win". Anyone in the org may read and preview them; changing
+// org entries needs the org admin role. Global entries ("global": true) can
+// only be managed from localhost.
+//
+//	GET    /api/lexicon?language=es
+//	POST   /api/lexicon           - {"language", "pattern", "replacement", "matchType": "word|exact|regex", "global"}
+//	PUT    /api/lexicon/{id}      - same fields as POST
+//	DELETE /api/lexicon/{id}
+//	POST   /api/lexicon/preview   - {"language", "text"} returns the text as it will be synthesized
+func handleLexicon(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+	orgID := flags.SubjectForUser(user).OrgID
+	orgAdmin := orgID != "" && isOrgAdmin(keycloakVerifier, r)
+
+	type lexiconRequest struct {
+		Language    string `json:"language"`
+		Pattern     string `json:"pattern"`
+		Replacement string `json:"replacement"`
+		MatchType   string `json:"matchType"`
+		Global      bool   `json:"global"`
+	}
+	decodeEntry := func() (*database.LexiconEntry, bool) {
+		var req lexiconRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return nil, false
+		}
+		entry := &database.LexiconEntry{
+			OrgID:       orgID,
+			Language:    langcode.NormalizeTag(req.Language),
+			Pattern:     strings.TrimSpace(req.Pattern),
+			Replacement: req.Replacement,
+			MatchType:   req.MatchType,
+		}
+		if entry.MatchType == "" {
+			entry.MatchType = database.LexiconMatchWord
+		}
+		if req.Global {
+			if !isLocalRequest(r) {
+				sendJSONError(w, http.StatusForbidden, "Global entries can only be managed from localhost")
+				return nil, false
+			}
+			entry.OrgID = ""
+		} else if orgID == "" {
+			sendJSONError(w, http.StatusBadRequest, "Account has no organization (a verified email is required)")
+			return nil, false
+		} else if !orgAdmin {
+			sendJSONError(w, http.StatusForbidden, "Only org admins can manage lexicon entries")
+			return nil, false
+		}
+		if entry.Language == "" {
+			sendJSONError(w, http.StatusBadRequest, "language is required")
+			return nil, false
+		}
+		if _, err := lexicon.Compile(*entry); err != nil {
+			sendJSONError(w, http.StatusBadRequest, err.Error())
+			return nil, false
+		}
+		return entry, true
+	}
+	// canManage reports whether the user may change an existing entry
+	canManage := func(entry *database.LexiconEntry) bool {
+		if entry.OrgID == "" {
+			return isLocalRequest(r)
+		}
+		return entry.OrgID == orgID && orgAdmin
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/lexicon"), "/")
+
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		all, err := database.ListLexiconEntries()
+		if err != nil {
+			log.Printf("Failed to list lexicon entries: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to list lexicon")
+			return
+		}
+		language := langcode.NormalizeTag(r.URL.Query().Get("language"))
+		entries := []database.LexiconEntry{}
+		for _, entry := range all {
+			if entry.OrgID != "" && entry.OrgID != orgID {
+				continue
+			}
+			if language != "" && entry.Language != language {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		writeJSON(w, map[string]interface{}{
+			"success": true,
+			"orgId":   orgID,
+			"entries": entries,
+		})
+
+	case path == "" && r.Method == http.MethodPost:
+		entry, ok := decodeEntry()
+		if !ok {
+			return
+		}
+		entry.CreatedBy = &user.ID
+		if err := database.UpsertLexiconEntry(entry); err != nil {
+			log.Printf("Failed to save lexicon entry: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to save entry")
+			return
+		}
+		lexicon.Invalidate()
+		writeJSON(w, map[string]interface{}{"success": true, "entry": entry})
+
+	case path == "preview" && r.Method == http.MethodPost:
+		var req struct {
+			Language string `json:"language"`
+			Text     string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Language == "" {
+			sendJSONError(w, http.StatusBadRequest, "language and text are required")
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"success": true,
+			"text":    lexicon.Apply(orgID, langcode.NormalizeTag(req.Language), req.Text),
+		})
+
+	case path != "" && (r.Method == http.MethodPut || r.Method == http.MethodDelete):
+		id, err := strconv.Atoi(path)
+		if err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid entry ID")
+			return
+		}
+		existing, err := database.GetLexiconEntry(id)
+		if err != nil {
+			log.Printf("Failed to load lexicon entry: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load entry")
+			return
+		}
+		if existing == nil || (existing.OrgID != "" && existing.OrgID != orgID) {
+			sendJSONError(w, http.StatusNotFound, "Entry not found")
+			return
+		}
+		if !canManage(existing) {
+			if existing.OrgID == "" {
+				sendJSONError(w, http.StatusForbidden, "Global entries can only be managed from localhost")
+			} else {
+				sendJSONError(w, http.StatusForbidden, "Only org admins can manage lexicon entries")
+			}
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			if err := database.DeleteLexiconEntry(id); err != nil {
+				log.Printf("Failed to delete lexicon entry: %v", err)
+				sendJSONError(w, http.StatusInternalServerError, "Failed to delete entry")
+				return
+			}
+			lexicon.Invalidate()
+			writeJSON(w, map[string]interface{}{"success": true})
+			return
+		}
+
+		entry, ok := decodeEntry()
+		if !ok {
+			return
+		}
+		entry.ID = id
+		entry.OrgID = existing.OrgID
+		if err := database.UpdateLexiconEntry(entry); err != nil {
+			log.Printf("Failed to update lexicon entry: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to update entry")
+			return
+		}
+		entry.CreatedBy = existing.CreatedBy
+		entry.CreatedAt = existing.CreatedAt
+		lexicon.Invalidate()
+		writeJSON(w, map[string]interface{}{"success": true, "entry": entry})
 
-func parseEmailVerified(value interface{}) bool {
-	switch v := value.(type) {
-	case bool:
-		return v
-	case string:
-		return strings.EqualFold(v, "true")
 	default:
-		return false
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 	}
 }
 
-func writeJSON(w http.ResponseWriter, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(payload)
+// handleAdminCache reports embedding/LLM cache stats (GET) or clears both caches (DELETE)
+func handleAdminCache(w http.ResponseWriter, r *http.Request, embeddingCache *cache.TTL[string, []float32], llmCache *cache.TTL[string, string]) {
+	if !isLocalRequest(r) {
+		sendJSONError(w, http.StatusForbidden, "Admin API available only on localhost")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodDelete:
+		embeddingCache.Clear()
+		llmCache.Clear()
+		log.Println("[Admin] Cleared embedding and LLM caches")
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"success": true,
+		"caches":  []cache.Stats{embeddingCache.Stats(), llmCache.Stats()},
+	})
 }
 
-func storageDetectContentType(name string) string {
-	ext := strings.ToLower(filepath.Ext(name))
-	if ext == "" {
-		return "application/octet-stream"
+// handleAdminExperiments compares live pipeline variants (localhost only).
+// Defaults to the running experiment; ?name= reports a past one.
+func handleAdminExperiments(w http.ResponseWriter, r *http.Request, running *experiment.Experiment) {
+	if !isLocalRequest(r) {
+		sendJSONError(w, http.StatusForbidden, "Admin API available only on localhost")
+		return
 	}
-	if contentType := mime.TypeByExtension(ext); contentType != "" {
-		return contentType
+	if r.Method != http.MethodGet {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
 	}
-	return "application/octet-stream"
-}
 
-func computeFileHash(path string) (string, error) {
-	file, err := os.Open(path)
+	name := r.URL.Query().Get("name")
+	if name == "" && running != nil {
+		name = running.Name
+	}
+	if name == "" {
+		sendJSONError(w, http.StatusBadRequest, "No experiment running; pass ?name=")
+		return
+	}
+
+	variants, err := database.GetExperimentReport(name)
 	if err != nil {
-		return "", err
+		log.Printf("Failed to build experiment report: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to build report")
+		return
 	}
-	defer file.Close()
 
-	hasher := sha256.New()
-	if _, err := io.Copy(hasher, file); err != nil {
-		return "", err
+	writeJSON(w, map[string]interface{}{
+		"success":    true,
+		"experiment": name,
+		"running":    running != nil && running.Name == name,
+		"variants":   variants,
+	})
+}
+
+// handleAdminLimits reports queueing and throughput for the LLM and embedding limiters
+func handleAdminLimits(w http.ResponseWriter, r *http.Request, limiters ...*ratelimit.Limiter) {
+	if r.Method != http.MethodGet {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
 	}
 
-	return hex.EncodeToString(hasher.Sum(nil)), nil
+	if !isLocalRequest(r) {
+		sendJSONError(w, http.StatusForbidden, "Admin API available only on localhost")
+		return
+	}
+
+	stats := make([]ratelimit.Stats, 0, len(limiters))
+	for _, limiter := range limiters {
+		stats = append(stats, limiter.Stats())
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"success":  true,
+		"limiters": stats,
+	})
 }
 
-func handleVideoUpload(w http.ResponseWriter, r *http.Request, processor *video.Processor, asrClient *asr.Client, translator translate.Translator, ttsClient *tts.Client, progressMgr *progress.Manager, minioClient *storage.MinioClient, verifier *auth.KeycloakVerifier) {
-	if r.Method != "POST" {
+// handleServiceHealth reports the circuit breaker of each model service.
+// Open breakers mean calls to that service currently fail fast.
+//
+//	GET /health/services
+func handleServiceHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
 		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	// Parse multipart form first (max 500MB)
-	if err := r.ParseMultipartForm(500 << 20); err != nil {
-		log.Printf("Error parsing form: %v", err)
-		json.NewEncoder(w).Encode(videoUploadResponse{
-			Success: false,
-			Error:   "Failed to parse upload",
-		})
+	services := httpx.Snapshot()
+	healthy := true
+	for _, service := range services {
+		if service.State != httpx.StateClosed {
+			healthy = false
+		}
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"healthy":  healthy,
+		"services": services,
+	})
+}
+
+// handleAdminDocuments manages the global knowledge base (localhost only):
+// GET /api/admin/documents[?org=], POST /api/admin/documents, DELETE /api/admin/documents/{id}.
+// Global documents belong to an org (the "org" form field, an email domain)
+// and are blended only into meetings created by its members.
+func handleAdminDocuments(w http.ResponseWriter, r *http.Request, ragProcessor *rag.Processor) {
+	if !isLocalRequest(r) {
+		sendJSONError(w, http.StatusForbidden, "Admin API available only on localhost")
 		return
 	}
 
-	file, header, err := r.FormFile("video")
+	documentID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/documents"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && documentID == "":
+		listKnowledgeDocuments(w, "", strings.ToLower(strings.TrimSpace(r.URL.Query().Get("org"))))
+	case r.Method == http.MethodPost && documentID == "":
+		uploadKnowledgeDocument(w, r, ragProcessor, nil, nil)
+	case r.Method == http.MethodDelete && documentID != "":
+		deleteKnowledgeDocument(w, documentID, "")
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleMeetingDocuments manages reference documents pinned to a meeting.
+// Viewers can list documents; editors can upload and delete them.
+func handleMeetingDocuments(w http.ResponseWriter, r *http.Request, ragProcessor *rag.Processor, keycloakVerifier *auth.KeycloakVerifier, roomCode, documentID string) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	meetingID, err := resolveMeetingID(roomCode)
 	if err != nil {
-		log.Printf("Error getting file: %v", err)
-		json.NewEncoder(w).Encode(videoUploadResponse{
-			Success: false,
-			Error:   "No video file provided",
-		})
+		log.Printf("Failed to resolve meeting: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to resolve meeting")
+		return
+	}
+	if meetingID == "" {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
 		return
 	}
 
-	// Generate session ID for progress tracking
-	sessionID := fmt.Sprintf("upload_%d", time.Now().UnixNano())
+	requiredRole := database.RoleEditor
+	if r.Method == http.MethodGet {
+		requiredRole = database.RoleViewer
+	}
+	allowed, err := database.UserHasMinimumRole(user.ID, meetingID, requiredRole)
+	if err != nil {
+		log.Printf("Failed to check meeting role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+	if !allowed {
+		sendJSONError(w, http.StatusForbidden, "Insufficient permissions for meeting documents")
+		return
+	}
 
-	// Read form values before starting goroutine
-	targetLang := r.FormValue("targetLang")
-	if targetLang == "" {
-		targetLang = "ar" // Default to Arabic
+	switch {
+	case r.Method == http.MethodGet && documentID == "":
+		listKnowledgeDocuments(w, meetingID, "")
+	case r.Method == http.MethodPost && documentID == "":
+		uploadKnowledgeDocument(w, r, ragProcessor, &meetingID, &user.ID)
+	case r.Method == http.MethodDelete && documentID != "":
+		deleteKnowledgeDocument(w, documentID, meetingID)
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 	}
+}
 
-	sourceLang := r.FormValue("sourceLang")
-	if sourceLang == "" {
-		sourceLang = "en" // Default to English
+// captionDelayConfig bounds the per-consumer caption delay
+type captionDelayConfig struct {
+	Default time.Duration
+	Max     time.Duration
+}
+
+// parse reads a delay in seconds (fractions allowed), falling back to the default
+func (c captionDelayConfig) parse(value string) (time.Duration, error) {
+	if value == "" {
+		return c.Default, nil
 	}
-	autoDetect := sourceLang == "auto" || sourceLang == "detect"
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 {
+		return 0, fmt.Errorf("delay must be a non-negative number of seconds")
+	}
+	delay := time.Duration(seconds * float64(time.Second))
+	if delay > c.Max {
+		return 0, fmt.Errorf("delay cannot exceed %.0f seconds", c.Max.Seconds())
+	}
+	return delay, nil
+}
 
-	// Check if user wants translated audio
-	generateTTS := r.FormValue("generateTTS") == "true"
+// authorizeCaptionFeed checks viewer access to the meeting and parses the
+// feed options (?lang=, ?delay=). Writes the error response on failure.
+func authorizeCaptionFeed(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, delays captionDelayConfig, roomCode string) (string, time.Duration, bool) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return "", 0, false
+	}
 
-	// Check if user wants voice cloning
-	cloneVoice := r.FormValue("cloneVoice") == "true"
-	forceProcessing := r.FormValue("force") == "true"
+	meetingID, err := resolveMeetingID(roomCode)
+	if err != nil {
+		log.Printf("Failed to resolve meeting: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to resolve meeting")
+		return "", 0, false
+	}
+	if meetingID == "" {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return "", 0, false
+	}
 
-	user, err := maybeAuthenticateUserFromRequest(verifier, r)
+	allowed, err := database.UserHasMinimumRole(user.ID, meetingID, database.RoleViewer)
 	if err != nil {
-		sendJSONError(w, http.StatusUnauthorized, "Invalid token")
-		return
+		log.Printf("Failed to check meeting role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return "", 0, false
 	}
-	var userID *int
-	if user != nil {
-		userID = &user.ID
+	if !allowed {
+		sendJSONError(w, http.StatusForbidden, "Insufficient permissions for meeting captions")
+		return "", 0, false
 	}
 
-	// Send initial response with session ID immediately
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(videoUploadResponse{
-		Success:   true,
-		SessionID: sessionID,
-	})
+	delay, err := delays.parse(r.URL.Query().Get("delay"))
+	if err != nil {
+		sendJSONError(w, http.StatusBadRequest, err.Error())
+		return "", 0, false
+	}
 
-	// Process asynchronously
-	go func() {
-		defer file.Close()
-		tracker := progressMgr.NewTracker(sessionID)
+	return meetingID, delay, true
+}
 
-		tracker.Update("upload", 10, fmt.Sprintf("Received %s (%.2f MB)", header.Filename, float64(header.Size)/(1024*1024)))
+// handleCaptionStream serves a meeting's captions as server-sent events, each
+// released at its timestamp plus the requested delay:
+//
+//	GET /api/meetings/{roomCode}/captions/stream?lang=es&delay=10&ticket=...
+//
+// Browsers authenticate the EventSource with a "captions" socket ticket for
+// the room.
+func handleCaptionStream(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, keycloakVerifier *auth.KeycloakVerifier, delays captionDelayConfig, roomCode string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		sendJSONError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
 
-		log.Printf("Processing video: %s (%.2f MB), target language: %s", header.Filename, float64(header.Size)/(1024*1024), targetLang)
+	meetingID, delay, ok := authorizeCaptionFeed(w, r, keycloakVerifier, delays, roomCode)
+	if !ok {
+		return
+	}
 
-		tracker.Update("saving", 15, "Saving video file...")
+	consumer, unsubscribe := roomManager.SubscribeCaptions(meetingID, languageParam(r, "lang"), delay)
+	defer unsubscribe()
 
-		// Save uploaded file temporarily
-		tempDir := processor.TempDir
-		tempVideoPath := filepath.Join(tempDir, fmt.Sprintf("upload_%d_%s", time.Now().Unix(), header.Filename))
-		defer os.Remove(tempVideoPath)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
 
-		outFile, err := os.Create(tempVideoPath)
-		if err != nil {
-			log.Printf("Error creating temp file: %v", err)
-			tracker.Error("saving", "Failed to save video", err)
-			return
-		}
+	fmt.Fprintf(w, "event: ready\ndata: {\"delaySeconds\":%g}\n\n", delay.Seconds())
+	flusher.Flush()
 
-		if _, err := io.Copy(outFile, file); err != nil {
-			outFile.Close()
-			log.Printf("Error copying file: %v", err)
-			tracker.Error("saving", "Failed to save video", err)
-			return
-		}
-		outFile.Close()
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
 
-		var contentHash string
-		if userID != nil {
-			hashValue, err := computeFileHash(tempVideoPath)
+	eventID := 0
+	for {
+		select {
+		case caption, open := <-consumer.Captions():
+			if !open {
+				fmt.Fprint(w, "event: end\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+			data, err := json.Marshal(caption)
 			if err != nil {
-				log.Printf("Failed to hash video: %v", err)
-			} else {
-				contentHash = hashValue
+				continue
 			}
+			eventID++
+			fmt.Fprintf(w, "id: %d\nevent: caption\ndata: %s\n\n", eventID, data)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
 		}
+	}
+}
 
-		if userID != nil && contentHash != "" && !forceProcessing {
-			match, err := database.FindUserFileByHash(*userID, "video", contentHash)
-			if err != nil {
-				log.Printf("Failed to lookup video hash: %v", err)
-			} else if match != nil {
-				results := map[string]interface{}{
-					"existing":          true,
-					"existingSessionId": match.SessionID,
-					"existingFileKey":   match.FileKey,
-				}
-				if sessionData, err := database.GetUserVideoSessionBySessionID(*userID, match.SessionID); err != nil {
-					log.Printf("Failed to load existing video session: %v", err)
-				} else if sessionData != nil {
-					results["transcription"] = sessionData.Transcription
-					results["translation"] = sessionData.Translation
-					results["duration"] = float64(sessionData.DurationSeconds)
-					results["minioVideoKey"] = sessionData.VideoPath
-					results["minioAudioKey"] = sessionData.AudioPath
-					results["minioTtsKey"] = sessionData.TTSPath
-				}
+// handleProgressEvents streams an upload's progress updates as server-sent
+// events, for clients that cannot open the /ws/progress WebSocket:
+//
+//	GET /progress/{sessionId}/events
+func handleProgressEvents(w http.ResponseWriter, r *http.Request, progressMgr *progress.Manager) {
+	pathParts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/progress/"), "/"), "/")
+	if len(pathParts) != 2 || pathParts[0] == "" || pathParts[1] != "events" {
+		sendJSONError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	if r.Method != http.MethodGet {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	sessionID := pathParts[0]
 
-				tracker.CompleteWithResults("Existing upload found", results)
-				return
-			}
-		}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		sendJSONError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
 
-		tracker.Update("extraction", 25, "Extracting audio from video...")
+	subscriber, unsubscribe := progressMgr.SubscribeChannel(sessionID, 32)
+	defer unsubscribe()
 
-		// Extract audio
-		log.Println("Extracting audio from video...")
-		audioResult, err := processor.ExtractAudio(tempVideoPath)
-		if err != nil {
-			log.Printf("Error extracting audio: %v", err)
-			tracker.Error("extraction", "Failed to extract audio", err)
-			return
-		}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
 
-		log.Printf("Audio extracted: %.2f seconds, %d bytes", audioResult.Duration, len(audioResult.AudioData))
-		tracker.Update("extraction", 35, fmt.Sprintf("Audio extracted: %.2f seconds", audioResult.Duration))
+	fmt.Fprint(w, "event: ready\ndata: {}\n\n")
+	flusher.Flush()
+	log.Printf("Progress event stream connected for session: %s", sessionID)
 
-		// Auto-detect language if requested
-		var detectedLang string
-		if autoDetect {
-			tracker.Update("detection", 40, "Detecting language...")
-			log.Println("Auto-detecting language...")
-			detectedLang, err = asrClient.DetectLanguage(audioResult.AudioData)
-			if err != nil {
-				log.Printf("Error detecting language: %v, defaulting to 'en'", err)
-				detectedLang = "en"
-				sourceLang = "en" // Update sourceLang for transcription
-				tracker.Update("detection", 45, "Language detection failed, using English")
-			} else {
-				log.Printf("Detected language: %s", detectedLang)
-				sourceLang = detectedLang
-				tracker.Update("detection", 45, fmt.Sprintf("Detected language: %s", detectedLang))
-			}
-		}
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
 
-		// Transcribe audio
-		tracker.Update("transcription", 50, "Transcribing audio...")
-		log.Println("Transcribing audio...")
-		transcription, err := asrClient.TranscribeWAV(audioResult.AudioData, sourceLang)
-		if err != nil {
-			log.Printf("Error transcribing: %v", err)
-			tracker.Error("transcription", "Failed to transcribe audio", err)
+	for {
+		select {
+		case data, open := <-subscriber.Updates():
+			if !open {
+				// Dropped for falling behind; EventSource reconnects on its own
+				return
+			}
+			fmt.Fprintf(w, "event: progress\ndata: %s\n\n", data)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
 			return
 		}
+	}
+}
 
-		log.Printf("Transcription: %s", transcription)
-		tracker.Update("transcription", 60, "Transcription complete")
-
-		// Translate transcription
-		tracker.Update("translation", 65, fmt.Sprintf("Translating from %s to %s...", sourceLang, targetLang))
-		log.Printf("Translating from %s to %s...", sourceLang, targetLang)
-		translation, err := translateWithChunking(translator, transcription, sourceLang, targetLang)
-		if err != nil {
-			log.Printf("Error translating: %v", err)
-			tracker.Error("translation", "Failed to translate", err)
-			return
-		}
+// handleCaptionWebSocket serves delayed captions over a WebSocket. Clients may
+// retune the delay at runtime with {"type": "set_delay", "delaySeconds": 8}.
+//
+//	/ws/captions/{roomCode}?lang=es&delay=10&token=...
+func handleCaptionWebSocket(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, keycloakVerifier *auth.KeycloakVerifier, delays captionDelayConfig, roomCode string) {
+	meetingID, delay, ok := authorizeCaptionFeed(w, r, keycloakVerifier, delays, roomCode)
+	if !ok {
+		return
+	}
 
-		log.Printf("Translation: %s", translation)
-		tracker.Update("translation", 70, "Translation complete")
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println("Caption WebSocket upgrade error:", err)
+		return
+	}
+	defer conn.Close()
 
-		// Generate TTS and replace audio if requested
-		var videoPath string
-		if generateTTS && translation != "" {
-			var ttsAudio []byte
-			var err error
+	consumer, unsubscribe := roomManager.SubscribeCaptions(meetingID, languageParam(r, "lang"), delay)
+	defer unsubscribe()
 
-			if cloneVoice {
-				// Use voice cloning with original audio as reference
-				tracker.Update("tts", 75, "Generating TTS with voice cloning...")
-				log.Printf("Generating TTS with voice cloning...")
-				ttsAudio, err = ttsClient.SynthesizeWithVoice(translation, targetLang, audioResult.AudioData)
-				if err != nil {
-					log.Printf("Error with voice cloning, falling back to standard TTS: %v", err)
-					tracker.Update("tts", 75, "Voice cloning failed, using standard TTS...")
-					// Fallback to standard TTS if voice cloning fails
-					ttsAudio, err = ttsClient.Synthesize(translation, targetLang)
-					if err != nil {
-						log.Printf("Error generating TTS: %v", err)
-						tracker.Error("tts", "Failed to generate TTS", err)
-						return
-					}
-				}
-			} else {
-				// Standard TTS without voice cloning
-				tracker.Update("tts", 75, "Generating TTS audio...")
-				log.Printf("Generating TTS audio for translation...")
-				ttsAudio, err = ttsClient.Synthesize(translation, targetLang)
-				if err != nil {
-					log.Printf("Error generating TTS: %v", err)
-					tracker.Error("tts", "Failed to generate TTS", err)
-					return
-				}
-			}
+	var writeMu sync.Mutex
+	send := func(v interface{}) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return conn.WriteJSON(v)
+	}
 
-			log.Printf("Generated TTS audio: %d bytes", len(ttsAudio))
-			tracker.Update("tts", 85, "TTS generation complete")
+	alive := keepalive.Start(conn)
+	defer alive.Stop()
 
-			// Replace audio in video
-			tracker.Update("processing", 90, "Replacing audio in video...")
-			log.Println("Replacing audio in video...")
-			outputVideoPath, err := processor.ReplaceAudio(tempVideoPath, ttsAudio)
-			if err != nil {
-				log.Printf("Error replacing audio: %v", err)
-				tracker.Error("processing", "Failed to replace audio", err)
+	// Read loop: delay changes from the client; exits when the client disconnects
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			var msg struct {
+				Type         string  `json:"type"`
+				DelaySeconds float64 `json:"delaySeconds"`
+			}
+			if err := conn.ReadJSON(&msg); err != nil {
 				return
 			}
-
-			// Store the path for download (relative to temp dir)
-			videoPath = filepath.Base(outputVideoPath)
-			log.Printf("Video with translated audio ready: %s", videoPath)
-			tracker.Update("processing", 95, "Video processing complete")
-		}
-
-		var minioOriginalKey string
-		var minioAudioKey string
-		var minioTTSKey string
-
-		if minioClient != nil && minioClient.Enabled() {
-			ctx := context.Background()
-
-			originalKey := storage.SafeObjectKey("videos", sessionID, fmt.Sprintf("original_%s", header.Filename))
-			etag, size, err := minioClient.UploadFile(ctx, originalKey, tempVideoPath, "")
-			if err != nil {
-				log.Printf("MinIO upload failed (original video): %v", err)
-			} else {
-				minioOriginalKey = originalKey
-				if userID != nil {
-					_, _ = database.CreateUserFile(userID, database.UserFileInput{
-						SessionType:   "video",
-						SessionID:     sessionID,
-						BucketName:    minioClient.Bucket(),
-						FileKey:       originalKey,
-						ContentHash:   contentHash,
-						Etag:          etag,
-						MimeType:      storageDetectContentType(header.Filename),
-						FileSizeBytes: size,
-					})
-				}
+			alive.Touch()
+			if msg.Type != "set_delay" {
+				continue
 			}
-
-			audioKey := storage.SafeObjectKey("videos", sessionID, "extracted_audio.wav")
-			etag, size, err = minioClient.UploadBytes(ctx, audioKey, audioResult.AudioData, "audio/wav")
+			newDelay, err := delays.parse(strconv.FormatFloat(msg.DelaySeconds, 'f', -1, 64))
 			if err != nil {
-				log.Printf("MinIO upload failed (extracted audio): %v", err)
-			} else {
-				minioAudioKey = audioKey
-				if userID != nil {
-					_, _ = database.CreateUserFile(userID, database.UserFileInput{
-						SessionType:   "video",
-						SessionID:     sessionID,
-						BucketName:    minioClient.Bucket(),
-						FileKey:       audioKey,
-						Etag:          etag,
-						MimeType:      "audio/wav",
-						FileSizeBytes: size,
-					})
-				}
-			}
-
-			if generateTTS && videoPath != "" {
-				translatedKey := storage.SafeObjectKey("videos", sessionID, fmt.Sprintf("translated_%s", filepath.Base(videoPath)))
-				etag, size, err = minioClient.UploadFile(ctx, translatedKey, filepath.Join(tempDir, videoPath), "")
-				if err != nil {
-					log.Printf("MinIO upload failed (translated video): %v", err)
-				} else {
-					minioTTSKey = translatedKey
-					if userID != nil {
-						_, _ = database.CreateUserFile(userID, database.UserFileInput{
-							SessionType:   "video",
-							SessionID:     sessionID,
-							BucketName:    minioClient.Bucket(),
-							FileKey:       translatedKey,
-							Etag:          etag,
-							MimeType:      storageDetectContentType(videoPath),
-							FileSizeBytes: size,
-						})
-					}
-				}
+				_ = send(map[string]interface{}{"type": "error", "error": err.Error()})
+				continue
 			}
+			consumer.SetDelay(newDelay)
+			_ = send(map[string]interface{}{"type": "delay", "delaySeconds": newDelay.Seconds()})
 		}
+	}()
 
-		// Send completion with results
-		results := map[string]interface{}{
-			"transcription": transcription,
-			"translation":   translation,
-			"duration":      audioResult.Duration,
-			"videoPath":     videoPath,
-			"minioBucket":   "",
-			"minioVideoKey": minioOriginalKey,
-			"minioAudioKey": minioAudioKey,
-			"minioTtsKey":   minioTTSKey,
-		}
-		if minioClient != nil && minioClient.Enabled() {
-			results["minioBucket"] = minioClient.Bucket()
-		}
-		if detectedLang != "" {
-			results["detectedLang"] = detectedLang
+	if err := send(map[string]interface{}{"type": "delay", "delaySeconds": delay.Seconds()}); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case caption, open := <-consumer.Captions():
+			if !open {
+				_ = send(map[string]interface{}{"type": "meeting_ended"})
+				return
+			}
+			if err := send(struct {
+				Type string `json:"type"`
+				meeting.Caption
+			}{"caption", caption}); err != nil {
+				return
+			}
+		case <-closed:
+			return
 		}
-		tracker.CompleteWithResults("Video processing completed successfully", results)
-		log.Printf("Video processing completed for session %s", sessionID)
-	}() // End of goroutine
+	}
 }
 
-func handleAudioUpload(w http.ResponseWriter, r *http.Request, processor *video.Processor, asrClient *asr.Client, translator translate.Translator, progressMgr *progress.Manager, minioClient *storage.MinioClient, verifier *auth.KeycloakVerifier) {
-	if r.Method != "POST" {
+// handleMeetingRAGSettings reads (viewer) or updates (editor) the retrieval
+// defaults used by RAG chat for a meeting
+func handleMeetingRAGSettings(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, roomCode string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
 		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	// Parse multipart form first (max 100MB)
-	if err := r.ParseMultipartForm(100 << 20); err != nil {
-		log.Printf("Error parsing form: %v", err)
-		json.NewEncoder(w).Encode(videoUploadResponse{
-			Success: false,
-			Error:   "Failed to parse upload",
-		})
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
 		return
 	}
 
-	file, header, err := r.FormFile("audio")
+	meetingID, err := resolveMeetingID(roomCode)
 	if err != nil {
-		log.Printf("Error getting file: %v", err)
-		json.NewEncoder(w).Encode(videoUploadResponse{
-			Success: false,
-			Error:   "No audio file provided",
-		})
+		log.Printf("Failed to resolve meeting: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to resolve meeting")
 		return
 	}
-
-	// Generate session ID for progress tracking
-	sessionID := fmt.Sprintf("audio_%d", time.Now().UnixNano())
-
-	// Read form values before starting goroutine
-	targetLang := r.FormValue("targetLang")
-	if targetLang == "" {
-		targetLang = "en" // Default to English
+	if meetingID == "" {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
 	}
 
-	sourceLang := r.FormValue("sourceLang")
-	if sourceLang == "" {
-		sourceLang = "auto" // Default to auto-detect
+	requiredRole := database.RoleViewer
+	if r.Method == http.MethodPut {
+		requiredRole = database.RoleEditor
 	}
-	autoDetect := sourceLang == "auto" || sourceLang == "detect"
-
-	// Check if user wants speaker diarization
-	enableDiarization := r.FormValue("enableDiarization") == "true"
-	enhanceAudio := r.FormValue("enhanceAudio") == "true"
-	forceProcessing := r.FormValue("force") == "true"
-
-	user, err := maybeAuthenticateUserFromRequest(verifier, r)
+	allowed, err := database.UserHasMinimumRole(user.ID, meetingID, requiredRole)
 	if err != nil {
-		sendJSONError(w, http.StatusUnauthorized, "Invalid token")
+		log.Printf("Failed to check meeting role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
 		return
 	}
-	var userID *int
-	if user != nil {
-		userID = &user.ID
+	if !allowed {
+		sendJSONError(w, http.StatusForbidden, "Insufficient permissions for meeting settings")
+		return
 	}
 
-	// Send initial response with session ID immediately
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(videoUploadResponse{
-		Success:   true,
-		SessionID: sessionID,
-	})
-
-	// Process asynchronously
-	go func() {
-		defer file.Close()
-		tracker := progressMgr.NewTracker(sessionID)
-
-		tracker.Update("upload", 10, fmt.Sprintf("Received %s (%.2f MB)", header.Filename, float64(header.Size)/(1024*1024)))
-
-		log.Printf("Processing audio: %s (%.2f MB), source: %s, target: %s", header.Filename, float64(header.Size)/(1024*1024), sourceLang, targetLang)
-
-		tracker.Update("saving", 20, "Saving audio file...")
-
-		// Save uploaded file temporarily
-		tempDir := processor.TempDir
-		tempAudioPath := filepath.Join(tempDir, fmt.Sprintf("upload_audio_%d_%s", time.Now().Unix(), header.Filename))
-		defer os.Remove(tempAudioPath)
-
-		outFile, err := os.Create(tempAudioPath)
-		if err != nil {
-			log.Printf("Error creating temp file: %v", err)
-			tracker.Error("saving", "Failed to save audio", err)
+	if r.Method == http.MethodPut {
+		var settings database.MeetingRAGSettings
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
 			return
 		}
-
-		if _, err := io.Copy(outFile, file); err != nil {
-			outFile.Close()
-			log.Printf("Error copying file: %v", err)
-			tracker.Error("saving", "Failed to save audio", err)
+		if settings.TopK != nil && (*settings.TopK < 1 || *settings.TopK > 50) {
+			sendJSONError(w, http.StatusBadRequest, "topK must be between 1 and 50")
 			return
 		}
-		outFile.Close()
+		if settings.MinSimilarity != nil && (*settings.MinSimilarity < 0 || *settings.MinSimilarity > 1) {
+			sendJSONError(w, http.StatusBadRequest, "minSimilarity must be between 0 and 1")
+			return
+		}
+		if err := database.UpdateMeetingRAGSettings(meetingID, settings); err != nil {
+			log.Printf("Failed to update meeting RAG settings: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to update settings")
+			return
+		}
+	}
 
-		var contentHash string
-		if userID != nil {
-			hashValue, err := computeFileHash(tempAudioPath)
-			if err != nil {
-				log.Printf("Failed to hash audio: %v", err)
-			} else {
-				contentHash = hashValue
-			}
-		}
+	settings, err := database.GetMeetingRAGSettings(meetingID)
+	if err != nil {
+		log.Printf("Failed to get meeting RAG settings: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to get settings")
+		return
+	}
 
-		if userID != nil && contentHash != "" && !forceProcessing {
-			match, err := database.FindUserFileByHash(*userID, "audio", contentHash)
-			if err != nil {
-				log.Printf("Failed to lookup audio hash: %v", err)
-			} else if match != nil {
-				results := map[string]interface{}{
-					"existing":          true,
-					"existingSessionId": match.SessionID,
-					"existingFileKey":   match.FileKey,
-				}
-				if sessionData, err := database.GetUserAudioSessionBySessionID(*userID, match.SessionID); err != nil {
-					log.Printf("Failed to load existing audio session: %v", err)
-				} else if sessionData != nil {
-					results["transcription"] = sessionData.Transcription
-					results["translation"] = sessionData.Translation
-					results["minioAudioKey"] = sessionData.AudioPath
-					results["num_speakers"] = sessionData.NumSpeakers
-					results["segments"] = sessionData.Segments
-				}
+	writeJSON(w, map[string]interface{}{
+		"success":   true,
+		"meetingId": meetingID,
+		"settings":  settings,
+	})
+}
 
-				tracker.CompleteWithResults("Existing upload found", results)
-				return
-			}
-		}
+// handleMeetingTextFilter manages a meeting's caption redaction policy,
+// which masks or removes profanity and sensitive terms from transcriptions
+// and translations before they are broadcast, stored or embedded:
+//
+//	GET    /api/meetings/{roomCode}/text-filter - the policy, or null
+//	PUT    /api/meetings/{roomCode}/text-filter - {"mode": "mask", "profanity": "standard", "redactEmails": true, "redactCardNumbers": true, "terms": ["Jane Doe"]}
+//	DELETE /api/meetings/{roomCode}/text-filter - stop redacting
+//
+// Reading or changing the policy takes the owner or a co-host. It applies to
+// captions from then on; captions already sent are not redacted again.
+func handleMeetingTextFilter(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, roomCode string) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
 
-		if enhanceAudio {
-			tracker.Update("processing", 30, "Cleaning up audio and converting to WAV...")
-		} else {
-			tracker.Update("processing", 30, "Converting audio to WAV format...")
-		}
+	meetingID, err := resolveMeetingID(roomCode)
+	if err != nil {
+		log.Printf("Failed to resolve meeting: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to resolve meeting")
+		return
+	}
+	if meetingID == "" {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
 
-		// Convert audio to WAV format
-		log.Println("Converting audio to WAV...")
-		audioResult, err := processor.ConvertAudioToWAVWithEnhancement(tempAudioPath, enhanceAudio)
-		if err != nil && enhanceAudio {
-			log.Printf("Audio enhancement failed, retrying without enhancement: %v", err)
-			audioResult, err = processor.ConvertAudioToWAVWithEnhancement(tempAudioPath, false)
+	userRole, err := database.GetUserMeetingRole(user.ID, meetingID)
+	if err != nil {
+		log.Printf("Failed to get user role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+	if userRole == "" {
+		sendJSONError(w, http.StatusForbidden, "Access denied to this meeting")
+		return
+	}
+	// The term list names what is being redacted, so only moderators see it
+	if !database.IsModeratorRole(userRole) {
+		sendJSONError(w, http.StatusForbidden, "Only meeting owners or co-hosts can manage caption redaction")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req struct {
+			Mode              string   `json:"mode"`
+			Profanity         string   `json:"profanity"`
+			RedactEmails      bool     `json:"redactEmails"`
+			RedactCardNumbers bool     `json:"redactCardNumbers"`
+			Terms             []string `json:"terms"`
 		}
-		if err != nil {
-			log.Printf("Error converting audio: %v", err)
-			tracker.Error("processing", "Failed to convert audio", err)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
 			return
 		}
-
-		log.Printf("Audio converted: %.2f seconds, %d bytes", audioResult.Duration, len(audioResult.AudioData))
-		tracker.Update("processing", 40, fmt.Sprintf("Audio converted: %.2f seconds", audioResult.Duration))
-
-		// Auto-detect language if requested
-		var detectedLang string
-		if autoDetect {
-			tracker.Update("detection", 45, "Detecting language...")
-			log.Println("Auto-detecting language...")
-			detectedLang, err = asrClient.DetectLanguage(audioResult.AudioData)
-			if err != nil {
-				log.Printf("Error detecting language: %v, defaulting to 'en'", err)
-				detectedLang = "en"
-				sourceLang = "en" // Update sourceLang for transcription
-				tracker.Update("detection", 50, "Language detection failed, using English")
-			} else {
-				log.Printf("Detected language: %s", detectedLang)
-				sourceLang = detectedLang
-				tracker.Update("detection", 50, fmt.Sprintf("Detected language: %s", detectedLang))
-			}
+		filter := &database.MeetingTextFilter{
+			MeetingID:         meetingID,
+			Mode:              req.Mode,
+			Profanity:         req.Profanity,
+			RedactEmails:      req.RedactEmails,
+			RedactCardNumbers: req.RedactCardNumbers,
+			Terms:             []string{},
+			UpdatedBy:         &user.ID,
 		}
-
-		// Transcribe audio (with or without diarization)
-		tracker.Update("transcription", 60, "Transcribing audio...")
-		log.Println("Transcribing audio...")
-
-		var transcription string
-		var segments []map[string]interface{}
-		var numSpeakers int
-
-		if enableDiarization {
-			// Use diarization endpoint
-			tracker.Update("transcription", 60, "Transcribing with speaker identification...")
-			log.Println("Using speaker diarization...")
-
-			diarizationResult, err := asrClient.TranscribeWithDiarization(audioResult.AudioData, sourceLang)
-			if err != nil {
-				log.Printf("Error with diarization, falling back to normal transcription: %v", err)
-				// Fallback to normal transcription
-				transcription, err = asrClient.TranscribeWAV(audioResult.AudioData, sourceLang)
-				if err != nil {
-					log.Printf("Error transcribing: %v", err)
-					tracker.Error("transcription", "Failed to transcribe audio", err)
-					return
-				}
-			} else {
-				transcription = diarizationResult.Text
-				segments = diarizationResult.Segments
-				numSpeakers = diarizationResult.NumSpeakers
-				log.Printf("Diarization complete: %d speakers, %d segments", numSpeakers, len(segments))
-			}
-		} else {
-			// Normal transcription
-			transcription, err = asrClient.TranscribeWAV(audioResult.AudioData, sourceLang)
-			if err != nil {
-				log.Printf("Error transcribing: %v", err)
-				tracker.Error("transcription", "Failed to transcribe audio", err)
-				return
-			}
+		if filter.Mode == "" {
+			filter.Mode = textfilter.ModeMask
 		}
-
-		log.Printf("Transcription: %s", transcription[:min(len(transcription), 100)])
-		tracker.Update("transcription", 75, "Transcription complete")
-
-		// Translate transcription
-		var translation string
-
-		if len(segments) > 0 {
-			// Translate each segment
-			tracker.Update("translation", 80, fmt.Sprintf("Translating %d segments...", len(segments)))
-			log.Printf("Translating %d segments from %s to %s...", len(segments), sourceLang, targetLang)
-
-			for i, seg := range segments {
-				segText := seg["text"].(string)
-				translatedText, err := translateWithChunking(translator, segText, sourceLang, targetLang)
-				if err != nil {
-					log.Printf("Error translating segment %d: %v", i, err)
-					translatedText = segText // Fallback to original
-				}
-				seg["translation"] = translatedText
-				segments[i] = seg
-			}
-
-			// Also create full translation
-			translation, _ = translateWithChunking(translator, transcription, sourceLang, targetLang)
-		} else {
-			// Single translation
-			tracker.Update("translation", 80, fmt.Sprintf("Translating from %s to %s...", sourceLang, targetLang))
-			log.Printf("Translating from %s to %s...", sourceLang, targetLang)
-			translation, err = translateWithChunking(translator, transcription, sourceLang, targetLang)
-			if err != nil {
-				log.Printf("Error translating: %v", err)
-				tracker.Error("translation", "Failed to translate", err)
-				return
-			}
+		if !textfilter.ValidMode(filter.Mode) {
+			sendJSONError(w, http.StatusBadRequest, "mode must be mask or remove")
+			return
 		}
-
-		log.Printf("Translation complete")
-		tracker.Update("translation", 90, "Translation complete")
-
-		var minioAudioKey string
-		if minioClient != nil && minioClient.Enabled() {
-			ctx := context.Background()
-			audioKey := storage.SafeObjectKey("audio", sessionID, fmt.Sprintf("original_%s", header.Filename))
-			etag, size, err := minioClient.UploadFile(ctx, audioKey, tempAudioPath, "")
-			if err != nil {
-				log.Printf("MinIO upload failed (audio): %v", err)
-			} else {
-				minioAudioKey = audioKey
-				if userID != nil {
-					_, _ = database.CreateUserFile(userID, database.UserFileInput{
-						SessionType:   "audio",
-						SessionID:     sessionID,
-						BucketName:    minioClient.Bucket(),
-						FileKey:       audioKey,
-						ContentHash:   contentHash,
-						Etag:          etag,
-						MimeType:      storageDetectContentType(header.Filename),
-						FileSizeBytes: size,
-					})
-				}
-			}
+		if filter.Profanity == "" {
+			filter.Profanity = profanity.LevelOff
 		}
-
-		// Send completion with results
-		results := map[string]interface{}{
-			"transcription": transcription,
-			"translation":   translation,
-			"minioBucket":   "",
-			"minioAudioKey": minioAudioKey,
+		if !profanity.Valid(filter.Profanity) {
+			sendJSONError(w, http.StatusBadRequest, "profanity must be off, standard or kid_safe")
+			return
 		}
-		if minioClient != nil && minioClient.Enabled() {
-			results["minioBucket"] = minioClient.Bucket()
+		for _, term := range req.Terms {
+			if term = strings.TrimSpace(term); term != "" {
+				filter.Terms = append(filter.Terms, term)
+			}
 		}
-		if detectedLang != "" {
-			results["detectedLang"] = detectedLang
+		if len(filter.Terms) > 500 {
+			sendJSONError(w, http.StatusBadRequest, "At most 500 terms can be redacted")
+			return
 		}
-		if len(segments) > 0 {
-			results["segments"] = segments
-			results["num_speakers"] = numSpeakers
+		if err := database.SetMeetingTextFilter(filter); err != nil {
+			log.Printf("Failed to set text filter: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to set caption redaction")
+			return
 		}
-		tracker.CompleteWithResults("Audio processing completed successfully", results)
-		log.Printf("Audio processing completed for session %s", sessionID)
-	}() // End of goroutine
-}
+		textfilter.Invalidate(meetingID)
+		log.Printf("Caption redaction for meeting %s set by user %d: mode=%s profanity=%s emails=%t cards=%t terms=%d",
+			meetingID, user.ID, filter.Mode, filter.Profanity, filter.RedactEmails, filter.RedactCardNumbers, len(filter.Terms))
+	case http.MethodDelete:
+		if err := database.DeleteMeetingTextFilter(meetingID); err != nil {
+			log.Printf("Failed to delete text filter: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to remove caption redaction")
+			return
+		}
+		textfilter.Invalidate(meetingID)
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
 
-func min(a, b int) int {
-	if a < b {
-		return a
+	filter, err := database.GetMeetingTextFilter(meetingID)
+	if err != nil {
+		log.Printf("Failed to get text filter: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to get caption redaction")
+		return
 	}
-	return b
+	writeJSON(w, map[string]interface{}{
+		"success":    true,
+		"meetingId":  meetingID,
+		"textFilter": filter,
+	})
 }
 
-// Meeting API Handlers
+// Invite expiry bounds
+const (
+	defaultInviteTTL = 7 * 24 * time.Hour
+	maxInviteTTL     = 90 * 24 * time.Hour
+)
 
-func handleCreateMeeting(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
-	if r.Method != "POST" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Method not allowed",
-		})
+// handleMeetingInvites manages a meeting's join links. Each invite carries a
+// token scoped to the meeting, the role it grants and an expiry; signed-in
+// users who join with it get that role in the meeting's ACL.
+//
+//	GET    /api/meetings/{roomCode}/invites - invites without their tokens, and whether the meeting is invite-only
+//	POST   /api/meetings/{roomCode}/invites - {"role": "viewer", "expiresInHours": 168, "maxUses": 10}; returns the token and join URL once
+//	DELETE /api/meetings/{roomCode}/invites/{id} - revoke an invite
+//	PUT    /api/meetings/{roomCode}/invites/settings - {"inviteOnly": true} to require an invite to join
+//
+// All of it takes the owner or a co-host.
+func handleMeetingInvites(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, roomCode string, rest []string) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
 		return
 	}
 
-	// Parse request body
-	var req struct {
-		Mode string `json:"mode"` // "individual" or "shared"
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
 	}
-
-	// Try to parse JSON, but don't fail if empty (default to individual)
-	json.NewDecoder(r.Body).Decode(&req)
-
-	// Default to individual mode
-	if req.Mode == "" {
-		req.Mode = "individual"
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
 	}
 
-	// Validate mode
-	if req.Mode != "individual" && req.Mode != "shared" {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Invalid mode. Must be 'individual' or 'shared'",
-		})
+	userRole, err := database.GetUserMeetingRole(user.ID, mtg.ID)
+	if err != nil {
+		log.Printf("Failed to get user role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+	if !database.IsModeratorRole(userRole) {
+		sendJSONError(w, http.StatusForbidden, "Only meeting owners or co-hosts can manage invites")
 		return
 	}
 
-	user, err := maybeAuthenticateUserFromRequest(keycloakVerifier, r)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Invalid or expired authentication token",
+	if len(rest) > 0 && rest[0] == "settings" {
+		if r.Method != http.MethodPut {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		var req struct {
+			InviteOnly bool `json:"inviteOnly"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+		if err := database.SetMeetingInviteOnly(mtg.ID, req.InviteOnly); err != nil {
+			log.Printf("Failed to set invite-only: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to update invite settings")
+			return
+		}
+		log.Printf("Meeting %s invite-only set to %t by user %d", mtg.ID, req.InviteOnly, user.ID)
+		writeJSON(w, map[string]interface{}{
+			"success":    true,
+			"inviteOnly": req.InviteOnly,
 		})
 		return
 	}
-	var userID *int
-	if user != nil {
-		userID = &user.ID
-	}
 
-	// Create meeting in database
-	meeting, err := database.CreateMeeting(userID, req.Mode)
-	if err != nil {
-		log.Printf("Error creating meeting: %v", err)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Failed to create meeting",
+	if len(rest) > 0 && rest[0] != "" {
+		if r.Method != http.MethodDelete {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		inviteID, err := strconv.Atoi(rest[0])
+		if err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid invite ID")
+			return
+		}
+		revoked, err := database.RevokeMeetingInvite(mtg.ID, inviteID)
+		if err != nil {
+			log.Printf("Failed to revoke invite: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to revoke invite")
+			return
+		}
+		if !revoked {
+			sendJSONError(w, http.StatusNotFound, "Invite not found")
+			return
+		}
+		log.Printf("Invite %d of meeting %s revoked by user %d", inviteID, mtg.ID, user.ID)
+		writeJSON(w, map[string]interface{}{
+			"success": true,
 		})
 		return
 	}
 
-	log.Printf("Created meeting: %s (room code: %s, mode: %s)", meeting.ID, meeting.RoomCode, meeting.Mode)
+	switch r.Method {
+	case http.MethodGet:
+		invites, err := database.ListMeetingInvites(mtg.ID)
+		if err != nil {
+			log.Printf("Failed to list invites: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load invites")
+			return
+		}
+		inviteOnly, err := database.IsMeetingInviteOnly(mtg.ID)
+		if err != nil {
+			log.Printf("Failed to check invite-only: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load invites")
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"success":    true,
+			"inviteOnly": inviteOnly,
+			"invites":    invites,
+		})
+	case http.MethodPost:
+		var req struct {
+			Role           string  `json:"role"`
+			ExpiresInHours float64 `json:"expiresInHours"`
+			MaxUses        int     `json:"maxUses"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+		if req.Role == "" {
+			req.Role = database.RoleViewer
+		}
+		if req.Role != database.RoleEditor && req.Role != database.RoleViewer {
+			sendJSONError(w, http.StatusBadRequest, "role must be editor or viewer")
+			return
+		}
+		ttl := defaultInviteTTL
+		if req.ExpiresInHours != 0 {
+			ttl = time.Duration(req.ExpiresInHours * float64(time.Hour))
+		}
+		if ttl <= 0 || ttl > maxInviteTTL {
+			sendJSONError(w, http.StatusBadRequest, "expiresInHours must be between 0 and 2160")
+			return
+		}
+		if req.MaxUses < 0 {
+			sendJSONError(w, http.StatusBadRequest, "maxUses must not be negative")
+			return
+		}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":   true,
-		"meetingId": meeting.ID,
-		"roomCode":  meeting.RoomCode,
-		"mode":      meeting.Mode,
-		"hostToken": meeting.HostToken,
-	})
-}
+		invite, err := database.CreateMeetingInvite(mtg.ID, req.Role, time.Now().Add(ttl), req.MaxUses, user.ID)
+		if err != nil {
+			log.Printf("Failed to create invite: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to create invite")
+			return
+		}
+		log.Printf("Invite %d (%s) for meeting %s created by user %d", invite.ID, invite.Role, mtg.ID, user.ID)
 
-func handleJoinMeeting(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, keycloakVerifier *auth.KeycloakVerifier) {
-	if r.Method != "POST" {
+		joinURL := fmt.Sprintf("/meeting-join.html?roomCode=%s&invite=%s", url.QueryEscape(mtg.RoomCode), url.QueryEscape(invite.Token))
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Method not allowed",
+			"success": true,
+			"invite":  invite,
+			"joinUrl": joinURL,
 		})
-		return
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 	}
+}
 
-	// Extract room code from URL path
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 4 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Invalid room code",
-		})
+// handleMeetingWaitingRoom turns a meeting's waiting room on or off. With it
+// on, new participants wait until a host admits or rejects them with an
+// "admission" control message on the meeting WebSocket.
+//
+//	GET /api/meetings/{roomCode}/waiting-room - whether it is on, and who is waiting
+//	PUT /api/meetings/{roomCode}/waiting-room - {"enabled": true}
+//
+// Both take the owner or a co-host.
+func handleMeetingWaitingRoom(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, keycloakVerifier *auth.KeycloakVerifier, roomCode string) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
 		return
 	}
-	roomCode := pathParts[3]
 
-	// Parse request body
-	var req struct {
-		ParticipantName string `json:"participantName"`
-		TargetLanguage  string `json:"targetLanguage"`
+	meetingID, err := resolveMeetingID(roomCode)
+	if err != nil {
+		log.Printf("Failed to resolve meeting: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to resolve meeting")
+		return
 	}
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Invalid request body",
-		})
+	if meetingID == "" {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
 		return
 	}
 
-	// Validate inputs
-	if req.ParticipantName == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Participant name is required",
-		})
+	userRole, err := database.GetUserMeetingRole(user.ID, meetingID)
+	if err != nil {
+		log.Printf("Failed to get user role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
 		return
 	}
-	if req.TargetLanguage == "" {
-		req.TargetLanguage = "en" // Default to English
+	if !database.IsModeratorRole(userRole) {
+		sendJSONError(w, http.StatusForbidden, "Only meeting owners or co-hosts can manage the waiting room")
+		return
 	}
 
-	// Get meeting by room code
-	mtg, err := database.GetMeetingByRoomCode(roomCode)
-	if err != nil {
-		log.Printf("Error getting meeting: %v", err)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Failed to find meeting",
-		})
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req struct {
+			Enabled bool `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+		if err := database.SetMeetingWaitingRoom(meetingID, req.Enabled); err != nil {
+			log.Printf("Failed to set waiting room: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to update the waiting room")
+			return
+		}
+		log.Printf("Waiting room of meeting %s set to %t by user %d", meetingID, req.Enabled, user.ID)
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	if mtg == nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Meeting not found",
-		})
+	enabled, err := database.IsMeetingWaitingRoom(meetingID)
+	if err != nil {
+		log.Printf("Failed to check waiting room: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load the waiting room")
 		return
 	}
+	writeJSON(w, map[string]interface{}{
+		"success": true,
+		"enabled": enabled,
+		"waiting": roomManager.WaitingParticipants(meetingID),
+	})
+}
 
-	if !mtg.IsActive {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Meeting has ended",
-		})
+// handleMeetingRAGIngestion reports and restarts the chunking and embedding
+// of a meeting's transcripts, which starts by itself when the meeting ends
+//
+//	GET  /api/meetings/{roomCode}/rag/status - ingestion status per language and chunk counts (viewer)
+//	POST /api/meetings/{roomCode}/rag/ingest - ingest the transcript snapshots again (owner or co-host)
+func handleMeetingRAGIngestion(w http.ResponseWriter, r *http.Request, ragProcessor *rag.Processor, keycloakVerifier *auth.KeycloakVerifier, roomCode, action string) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
 		return
 	}
 
-	user, err := maybeAuthenticateUserFromRequest(keycloakVerifier, r)
+	meetingID, err := resolveMeetingID(roomCode)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Invalid or expired authentication token",
-		})
+		log.Printf("Failed to resolve meeting: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to resolve meeting")
 		return
 	}
-	var userID *int
-	if user != nil {
-		userID = &user.ID
+	if meetingID == "" {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
 	}
 
-	// Add participant to database
-	participant, err := database.AddParticipant(mtg.ID, userID, req.ParticipantName, req.TargetLanguage)
+	userRole, err := database.GetUserMeetingRole(user.ID, meetingID)
 	if err != nil {
-		log.Printf("Error adding participant: %v", err)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Failed to join meeting",
-		})
+		log.Printf("Failed to get user role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+	if userRole == "" {
+		sendJSONError(w, http.StatusForbidden, "Access denied to this meeting")
 		return
 	}
 
-	// Automatically grant viewer access if user is authenticated
-	if userID != nil {
-		err = database.AutoGrantViewerAccess(mtg.ID, *userID)
+	switch {
+	case action == "status" && r.Method == http.MethodGet:
+	case action == "ingest" && r.Method == http.MethodPost:
+		if !database.IsModeratorRole(userRole) {
+			sendJSONError(w, http.StatusForbidden, "Only meeting owners or co-hosts can re-ingest a meeting")
+			return
+		}
+		languages, err := meeting.ReingestMeeting(meetingID, ragProcessor)
+		if errors.Is(err, meeting.ErrIngestionRunning) {
+			sendJSONError(w, http.StatusConflict, "Meeting is already being ingested")
+			return
+		}
 		if err != nil {
-			// Log error but don't fail the join - they can still participate
-			log.Printf("Warning: Failed to auto-grant viewer access for user %d in meeting %s: %v", *userID, mtg.ID, err)
+			log.Printf("Failed to re-ingest meeting %s: %v", meetingID, err)
+			sendJSONError(w, http.StatusBadRequest, err.Error())
+			return
 		}
+		log.Printf("Re-ingesting meeting %s (%s) for user %d", meetingID, strings.Join(languages, ", "), user.ID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":   true,
+			"meetingId": meetingID,
+			"languages": languages,
+		})
+		return
+	default:
+		sendJSONError(w, http.StatusNotFound, "Not found")
+		return
 	}
 
-	log.Printf("Participant %d (%s) joined meeting %s", participant.ID, participant.ParticipantName, mtg.ID)
+	ingestions, err := database.ListRAGIngestions(meetingID)
+	if err != nil {
+		log.Printf("Failed to list RAG ingestions: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to get ingestion status")
+		return
+	}
+	chunkStatus, err := database.GetChunkStatusCounts(meetingID)
+	if err != nil {
+		log.Printf("Failed to count chunk statuses: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to get ingestion status")
+		return
+	}
+	chunkVersions, err := database.GetChunkVersionCounts(meetingID)
+	if err != nil {
+		log.Printf("Failed to count chunk versions: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to get ingestion status")
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	writeJSON(w, map[string]interface{}{
 		"success":       true,
-		"participantId": participant.ID,
-		"meetingId":     mtg.ID,
+		"meetingId":     meetingID,
+		"ingestions":    ingestions,
+		"chunkStatus":   chunkStatus,
+		"chunkVersions": chunkVersions,
+		"chunkVersion":  rag.ChunkerVersion,
 	})
 }
 
-func handleGetMeeting(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager) {
-	if r.Method != "GET" {
-		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+// maxChunkImportBytes bounds an uploaded chunk import
+const maxChunkImportBytes = 256 << 20
+
+// handleMeetingChunks exports a meeting's RAG chunks with their embeddings
+// (GET .../chunks/export?format=jsonl|parquet&lang=) or replaces them from a
+// JSONL export (POST .../chunks/import, raw body or multipart field "file").
+// Imported chunks without an embedding are embedded in the background.
+func handleMeetingChunks(w http.ResponseWriter, r *http.Request, ragProcessor *rag.Processor, keycloakVerifier *auth.KeycloakVerifier, roomCode, action string) {
+	var requiredRole string
+	switch {
+	case action == "export" && r.Method == http.MethodGet:
+		requiredRole = database.RoleViewer
+	case action == "import" && r.Method == http.MethodPost:
+		requiredRole = database.RoleEditor
+	default:
+		sendJSONError(w, http.StatusNotFound, "Unknown chunks endpoint")
 		return
 	}
 
-	// Extract room code from URL path: /api/meetings/K1N-G-A
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 4 {
-		sendJSONError(w, http.StatusBadRequest, "Invalid room code")
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
 		return
 	}
-	roomCode := pathParts[3]
 
-	// Get meeting by room code or ID
 	mtg, err := getMeetingByCodeOrID(roomCode)
 	if err != nil {
 		log.Printf("Error getting meeting: %v", err)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Failed to find meeting",
-		})
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
 		return
 	}
-
 	if mtg == nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Meeting not found",
-		})
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
 		return
 	}
 
-	// Get active participants from database
-	participants, err := database.GetActiveParticipants(mtg.ID)
+	allowed, err := database.UserHasMinimumRole(user.ID, mtg.ID, requiredRole)
 	if err != nil {
-		log.Printf("Error getting participants: %v", err)
-		participants = []database.MeetingParticipant{} // Return empty array on error
+		log.Printf("Failed to check meeting role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
 	}
-
-	// Convert to response format
-	participantList := make([]map[string]interface{}, len(participants))
-	for i, p := range participants {
-		participantList[i] = map[string]interface{}{
-			"id":             p.ID,
-			"name":           p.ParticipantName,
-			"targetLanguage": p.TargetLanguage,
-			"joinedAt":       p.JoinedAt,
-		}
+	if !allowed {
+		sendJSONError(w, http.StatusForbidden, "Insufficient permissions for meeting knowledge base")
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":      true,
-		"meetingId":    mtg.ID,
-		"roomCode":     mtg.RoomCode,
-		"mode":         mtg.Mode,
-		"isActive":     mtg.IsActive,
-		"participants": participantList,
-	})
-}
+	if action == "export" {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "jsonl"
+		}
+		if format != "jsonl" && format != "parquet" {
+			sendJSONError(w, http.StatusBadRequest, "format must be jsonl or parquet")
+			return
+		}
 
-func handleUpdateSpeakerName(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, roomCode, speakerID string) {
-	// Parse request body
-	var req struct {
-		SpeakerName string `json:"speakerName"`
-	}
+		records, err := rag.ExportChunks(mtg.ID, languageParam(r, "lang"))
+		if err != nil {
+			log.Printf("[RAG] Failed to export chunks for meeting %s: %v", mtg.ID, err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to export chunks")
+			return
+		}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+		if format == "parquet" {
+			w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"chunks-%s.parquet\"", mtg.RoomCode))
+			err = rag.WriteChunksParquet(w, records)
+		} else {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"chunks-%s.jsonl\"", mtg.RoomCode))
+			err = rag.WriteChunksJSONL(w, records)
+		}
+		if err != nil {
+			log.Printf("[RAG] Failed to write chunk export for meeting %s: %v", mtg.ID, err)
+		}
 		return
 	}
 
-	// Validate inputs
-	if req.SpeakerName == "" {
-		sendJSONError(w, http.StatusBadRequest, "Speaker name is required")
-		return
+	r.Body = http.MaxBytesReader(w, r.Body, maxChunkImportBytes)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			sendJSONError(w, http.StatusBadRequest, "No import file provided")
+			return
+		}
+		defer file.Close()
+		body = file
 	}
 
-	// Get meeting by room code or ID
-	mtg, err := getMeetingByCodeOrID(roomCode)
+	records, err := rag.ReadChunksJSONL(body)
 	if err != nil {
-		log.Printf("Error getting meeting: %v", err)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Failed to find meeting",
-		})
-		return
-	}
-
-	if mtg == nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Meeting not found",
-		})
+		sendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid chunk import: %v", err))
 		return
 	}
-
-	if !mtg.IsActive {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Meeting has ended",
-		})
+	if len(records) == 0 {
+		sendJSONError(w, http.StatusBadRequest, "Import contains no chunks")
 		return
 	}
 
-	// Save speaker name mapping to database
-	if err := database.SetSpeakerName(mtg.ID, speakerID, req.SpeakerName); err != nil {
-		log.Printf("Error saving speaker name: %v", err)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Failed to save speaker name",
-		})
+	result, err := ragProcessor.ImportChunks(mtg.ID, records)
+	if err != nil {
+		log.Printf("[RAG] Failed to import chunks for meeting %s: %v", mtg.ID, err)
+		sendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Failed to import chunks: %v", err))
 		return
 	}
 
-	log.Printf("Updated speaker %s in meeting %s to name: %s", speakerID, mtg.ID, req.SpeakerName)
-
-	// Broadcast update to all participants in the room
-	roomManager.Broadcast(mtg.ID, meeting.Message{
-		Type:        "speaker_name_updated",
-		SpeakerID:   speakerID,
-		SpeakerName: req.SpeakerName,
-	})
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":     true,
-		"speakerId":   speakerID,
-		"speakerName": req.SpeakerName,
+	writeJSON(w, map[string]interface{}{
+		"success":   true,
+		"meetingId": mtg.ID,
+		"result":    result,
 	})
 }
 
-func handleDownloadTranscript(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, roomCode string) {
-	if r.Method != "GET" {
-		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	lang := r.URL.Query().Get("lang")
-	if lang == "" {
-		sendJSONError(w, http.StatusBadRequest, "lang is required")
-		return
-	}
-
-	mtg, err := getMeetingByCodeOrID(roomCode)
+func listKnowledgeDocuments(w http.ResponseWriter, meetingID, orgID string) {
+	docs, err := database.ListKnowledgeDocuments(meetingID, orgID)
 	if err != nil {
-		log.Printf("Error getting meeting: %v", err)
-		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
-		return
-	}
-	if mtg == nil {
-		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		log.Printf("Failed to list knowledge documents: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to list documents")
 		return
 	}
-
-	entries := roomManager.GetTranscript(mtg.ID, lang)
-	content := formatTranscript(entries)
-
-	filename := fmt.Sprintf("meeting_%s_%s.txt", mtg.RoomCode, lang)
-	if mtg.RoomCode == "" {
-		filename = fmt.Sprintf("meeting_%s_%s.txt", mtg.ID, lang)
+	if docs == nil {
+		docs = []database.KnowledgeDocument{}
 	}
 
-	w.Header().Set("Content-Type", "text/plain")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
-	w.WriteHeader(http.StatusOK)
-	if _, err := w.Write([]byte(content)); err != nil {
-		log.Printf("Failed to write transcript response: %v", err)
-	}
+	writeJSON(w, map[string]interface{}{
+		"success":   true,
+		"documents": docs,
+	})
 }
 
-func handleDownloadTranscriptSnapshot(w http.ResponseWriter, r *http.Request, roomCode string) {
-	if r.Method != "GET" {
-		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+// uploadKnowledgeDocument stores an uploaded document (multipart field "file",
+// optional "title" and "language"; global documents also need "org") and
+// chunks/embeds it in the background
+func uploadKnowledgeDocument(w http.ResponseWriter, r *http.Request, ragProcessor *rag.Processor, meetingID *string, uploadedBy *int) {
+	if err := r.ParseMultipartForm(20 << 20); err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Failed to parse upload")
 		return
 	}
 
-	lang := r.URL.Query().Get("lang")
-	if lang == "" {
-		sendJSONError(w, http.StatusBadRequest, "lang is required")
-		return
+	orgID := ""
+	if meetingID == nil {
+		orgID = strings.ToLower(strings.TrimSpace(r.FormValue("org")))
+		if orgID == "" {
+			sendJSONError(w, http.StatusBadRequest, "org is required for global documents")
+			return
+		}
 	}
 
-	mtg, err := getMeetingByCodeOrID(roomCode)
+	file, header, err := r.FormFile("file")
 	if err != nil {
-		log.Printf("Error getting meeting: %v", err)
-		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
-		return
-	}
-	if mtg == nil {
-		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		sendJSONError(w, http.StatusBadRequest, "No document file provided")
 		return
 	}
+	defer file.Close()
 
-	snapshot, err := database.GetMeetingTranscriptSnapshot(mtg.ID, lang)
+	data, err := io.ReadAll(file)
 	if err != nil {
-		log.Printf("Failed to get transcript snapshot: %v", err)
-		sendJSONError(w, http.StatusInternalServerError, "Failed to load transcript snapshot")
+		sendJSONError(w, http.StatusBadRequest, "Failed to read document")
 		return
 	}
-	if snapshot == nil || snapshot.Transcript == "" {
-		sendJSONError(w, http.StatusNotFound, "Transcript snapshot not found")
+
+	text, err := document.ExtractText(header.Filename, data)
+	if err != nil {
+		log.Printf("Document extraction failed for %s: %v", header.Filename, err)
+		sendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Failed to extract text: %v", err))
 		return
 	}
 
-	filename := fmt.Sprintf("meeting_%s_%s_snapshot.txt", mtg.RoomCode, lang)
-	if mtg.RoomCode == "" {
-		filename = fmt.Sprintf("meeting_%s_%s_snapshot.txt", mtg.ID, lang)
+	title := strings.TrimSpace(r.FormValue("title"))
+	if title == "" {
+		title = strings.TrimSuffix(header.Filename, filepath.Ext(header.Filename))
 	}
 
-	w.Header().Set("Content-Type", "text/plain")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
-	w.WriteHeader(http.StatusOK)
-	if _, err := w.Write([]byte(snapshot.Transcript)); err != nil {
-		log.Printf("Failed to write transcript snapshot response: %v", err)
+	doc := &database.KnowledgeDocument{
+		MeetingID:  meetingID,
+		OrgID:      orgID,
+		UploadedBy: uploadedBy,
+		Title:      title,
+		Filename:   header.Filename,
+		MimeType:   header.Header.Get("Content-Type"),
+		Language:   r.FormValue("language"),
+		Content:    text,
 	}
-}
-
-func handleListTranscriptSnapshots(w http.ResponseWriter, r *http.Request, roomCode string) {
-	if r.Method != "GET" {
-		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	if err := database.CreateKnowledgeDocument(doc); err != nil {
+		log.Printf("Failed to store knowledge document: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to store document")
 		return
 	}
 
-	mtg, err := getMeetingByCodeOrID(roomCode)
+	go func() {
+		if err := ragProcessor.ProcessTranscript(database.DocumentSourceID(doc.ID), doc.Language, doc.Content); err != nil {
+			log.Printf("[RAG] Failed to process document %d: %v", doc.ID, err)
+		}
+		// The document may have been deleted while it was being embedded
+		if err := database.DeleteOrphanedDocumentChunks(doc.ID); err != nil {
+			log.Printf("[RAG] %v", err)
+		}
+	}()
+
+	writeJSON(w, map[string]interface{}{
+		"success":  true,
+		"document": doc,
+	})
+}
+
+// deleteKnowledgeDocument removes a document; meetingID scopes the delete to
+// documents pinned to that meeting (empty for the global knowledge base)
+func deleteKnowledgeDocument(w http.ResponseWriter, rawID, meetingID string) {
+	documentID, err := strconv.Atoi(rawID)
 	if err != nil {
-		log.Printf("Error getting meeting: %v", err)
-		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
-		return
-	}
-	if mtg == nil {
-		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		sendJSONError(w, http.StatusBadRequest, "Invalid document ID")
 		return
 	}
 
-	snapshots, err := database.ListMeetingTranscriptSnapshots(mtg.ID)
+	doc, err := database.GetKnowledgeDocument(documentID)
 	if err != nil {
-		log.Printf("Failed to list transcript snapshots: %v", err)
-		sendJSONError(w, http.StatusInternalServerError, "Failed to list transcript snapshots")
+		log.Printf("Failed to get knowledge document: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to get document")
 		return
 	}
-
-	type snapshotInfo struct {
-		Language  string `json:"language"`
-		CreatedAt string `json:"createdAt"`
-	}
-
-	items := make([]snapshotInfo, 0, len(snapshots))
-	for _, snapshot := range snapshots {
-		items = append(items, snapshotInfo{
-			Language:  snapshot.Language,
-			CreatedAt: snapshot.CreatedAt.Format(time.RFC3339),
-		})
+	docMeetingID := ""
+	if doc != nil && doc.MeetingID != nil {
+		docMeetingID = *doc.MeetingID
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":   true,
-		"snapshots": items,
-	})
-}
-
-func handleEndMeeting(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, llmClient *llm.Client, roomCode string) {
-	if r.Method != http.MethodPost {
-		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	if doc == nil || docMeetingID != meetingID {
+		sendJSONError(w, http.StatusNotFound, "Document not found")
 		return
 	}
 
-	var req struct {
-		HostToken string `json:"hostToken"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
-		return
-	}
-	if req.HostToken == "" {
-		sendJSONError(w, http.StatusBadRequest, "Host token required")
+	if err := database.DeleteKnowledgeDocument(documentID); err != nil {
+		log.Printf("Failed to delete knowledge document: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to delete document")
 		return
 	}
 
-	mtg, err := getMeetingByCodeOrID(roomCode)
+	writeJSON(w, map[string]interface{}{"success": true})
+}
+
+func resolveMeetingID(meetingID string) (string, error) {
+	meeting, err := database.GetMeetingByID(meetingID)
 	if err != nil {
-		log.Printf("Error getting meeting: %v", err)
-		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
-		return
+		return "", err
 	}
-	if mtg == nil {
-		sendJSONError(w, http.StatusNotFound, "Meeting not found")
-		return
+	if meeting != nil {
+		return meeting.ID, nil
 	}
 
-	valid, err := database.ValidateMeetingHostToken(mtg.ID, req.HostToken)
+	meeting, err = database.GetMeetingByRoomCode(meetingID)
 	if err != nil {
-		log.Printf("Failed to validate host token: %v", err)
-		sendJSONError(w, http.StatusInternalServerError, "Failed to validate host token")
-		return
-	}
-	if !valid {
-		sendJSONError(w, http.StatusUnauthorized, "Unauthorized")
-		return
+		return "", err
 	}
-
-	if err := roomManager.EndMeeting(mtg.ID); err != nil {
-		log.Printf("Failed to end meeting: %v", err)
-		sendJSONError(w, http.StatusInternalServerError, "Failed to end meeting")
-		return
+	if meeting != nil {
+		return meeting.ID, nil
 	}
 
-	if llmClient != nil {
-		go func() {
-			if err := meeting.GenerateMeetingMinutes(mtg.ID, "en", llmClient); err != nil {
-				log.Printf("Minutes generation failed for meeting %s: %v", mtg.ID, err)
-			}
-		}()
-	}
+	return "", nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-	})
+type videoUploadResponse struct {
+	Success       bool    `json:"success"`
+	SessionID     string  `json:"sessionId,omitempty"`
+	JobID         int     `json:"jobId,omitempty"`
+	Transcription string  `json:"transcription,omitempty"`
+	Translation   string  `json:"translation,omitempty"`
+	Duration      float64 `json:"duration,omitempty"`
+	VideoPath     string  `json:"videoPath,omitempty"`
+	DetectedLang  string  `json:"detectedLang,omitempty"`
+	Error         string  `json:"error,omitempty"`
 }
 
-func formatTranscript(entries []meeting.TranscriptEntry) string {
-	if len(entries) == 0 {
-		return ""
-	}
-	var b strings.Builder
-	for _, entry := range entries {
-		speaker := entry.SpeakerName
-		if speaker == "" {
-			speaker = entry.SpeakerID
+type authResponse struct {
+	Success bool           `json:"success"`
+	User    *database.User `json:"user,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+func handleKeycloakLogin(verifier *auth.KeycloakVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
 		}
-		if speaker == "" {
-			speaker = "Speaker"
+
+		if verifier == nil {
+			sendJSONError(w, http.StatusServiceUnavailable, "Keycloak auth not configured")
+			return
 		}
-		ts := entry.Timestamp.Format("15:04:05")
-		b.WriteString(fmt.Sprintf("[%s] %s: %s\n", ts, speaker, entry.Text))
+
+		tokenStr, err := extractBearerToken(r)
+		if err != nil {
+			sendJSONError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		claims, err := verifier.VerifyToken(r.Context(), tokenStr)
+		if err != nil {
+			sendJSONError(w, http.StatusUnauthorized, "Invalid token")
+			return
+		}
+
+		user, err := upsertUserFromClaims(claims)
+		if err != nil {
+			log.Printf("Keycloak upsert failed: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to persist user")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(authResponse{
+			Success: true,
+			User:    user,
+		})
 	}
-	return b.String()
 }
 
+type historyVideoRequest struct {
+	SessionID       string     `json:"sessionId"`
+	Filename        string     `json:"filename"`
+	Transcription   string     `json:"transcription,omitempty"`
+	Translation     string     `json:"translation,omitempty"`
+	VideoPath       string     `json:"videoPath,omitempty"`
+	AudioPath       string     `json:"audioPath,omitempty"`
+	TTSPath         string     `json:"ttsPath,omitempty"`
+	SourceLang      string     `json:"sourceLang,omitempty"`
+	TargetLang      string     `json:"targetLang,omitempty"`
+	DurationSeconds int        `json:"durationSeconds,omitempty"`
+	ExpiresAt       *time.Time `json:"expiresAt,omitempty"`
+}
 
-func getMeetingByCodeOrID(codeOrID string) (*database.Meeting, error) {
-	mtg, err := database.GetMeetingByRoomCode(codeOrID)
-	if err != nil {
-		return nil, err
-	}
-	if mtg != nil {
-		return mtg, nil
-	}
-	return database.GetMeetingByID(codeOrID)
+type historyAudioRequest struct {
+	SessionID      string          `json:"sessionId"`
+	Filename       string          `json:"filename"`
+	Transcription  string          `json:"transcription,omitempty"`
+	Translation    string          `json:"translation,omitempty"`
+	AudioPath      string          `json:"audioPath,omitempty"`
+	TTSPath        string          `json:"ttsPath,omitempty"`
+	SourceLang     string          `json:"sourceLang,omitempty"`
+	TargetLang     string          `json:"targetLang,omitempty"`
+	HasDiarization bool            `json:"hasDiarization,omitempty"`
+	NumSpeakers    int             `json:"numSpeakers,omitempty"`
+	Segments       json.RawMessage `json:"segments,omitempty"`
 }
 
-func handleMeetingOperations(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, llmClient *llm.Client, keycloakVerifier *auth.KeycloakVerifier) {
-	// Route based on URL pattern
-	// /api/meetings/{roomCode} - GET meeting info
-	// /api/meetings/{roomCode}/join - POST to join
-	// /api/meetings/{roomCode}/speakers/{speakerId} - POST to update speaker name
-	// /api/meetings/{roomCode}/transcript - GET to download transcript (lang query param)
-	// /api/meetings/{roomCode}/transcript-snapshots - GET to list available snapshots
-	// /api/meetings/{roomCode}/transcript-snapshot - GET to download snapshot (lang query param)
-	// /api/meetings/{roomCode}/end - POST to end meeting (host only)
-	pathParts := strings.Split(r.URL.Path, "/")
+type historyStreamingRequest struct {
+	SessionID            string `json:"sessionId"`
+	SourceLang           string `json:"sourceLang,omitempty"`
+	TargetLang           string `json:"targetLang,omitempty"`
+	TotalChunks          int    `json:"totalChunks,omitempty"`
+	TotalDurationSeconds int    `json:"totalDurationSeconds,omitempty"`
+	FinalTranscript      string `json:"finalTranscript,omitempty"`
+	FinalTranslation     string `json:"finalTranslation,omitempty"`
+}
 
-	if len(pathParts) < 4 {
-		sendJSONError(w, http.StatusBadRequest, "Invalid URL")
-		return
-	}
+type userFileRequest struct {
+	SessionType   string     `json:"sessionType"`
+	SessionID     string     `json:"sessionId"`
+	BucketName    string     `json:"bucketName"`
+	FileKey       string     `json:"fileKey"`
+	ContentHash   string     `json:"contentHash,omitempty"`
+	Etag          string     `json:"etag,omitempty"`
+	MimeType      string     `json:"mimeType,omitempty"`
+	FileSizeBytes int64      `json:"fileSizeBytes,omitempty"`
+	AccessedAt    *time.Time `json:"accessedAt,omitempty"`
+}
 
-	// Check if it's a join request
-	if len(pathParts) >= 5 && pathParts[4] == "join" {
-		handleJoinMeeting(w, r, roomManager, keycloakVerifier)
-		return
-	}
+type historyResponse struct {
+	Success bool   `json:"success"`
+	ID      int    `json:"id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
 
-	// Check if it's a transcript download: /api/meetings/{roomCode}/transcript
-	if len(pathParts) >= 5 && pathParts[4] == "transcript" && r.Method == "GET" {
-		handleDownloadTranscript(w, r, roomManager, pathParts[3])
-		return
-	}
+func handleCreateVideoHistory(verifier *auth.KeycloakVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
 
-	// Check if it's a transcript snapshot download
-	if len(pathParts) >= 5 && pathParts[4] == "transcript-snapshot" && r.Method == "GET" {
-		handleDownloadTranscriptSnapshot(w, r, pathParts[3])
-		return
+		user, ok := authenticateUserFromRequest(verifier, w, r)
+		if !ok {
+			return
+		}
+
+		var req historyVideoRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+
+		id, err := database.CreateUserVideoSession(user.ID, database.UserVideoSessionInput{
+			SessionID:       req.SessionID,
+			Filename:        req.Filename,
+			Transcription:   req.Transcription,
+			Translation:     req.Translation,
+			VideoPath:       req.VideoPath,
+			AudioPath:       req.AudioPath,
+			TTSPath:         req.TTSPath,
+			SourceLang:      req.SourceLang,
+			TargetLang:      req.TargetLang,
+			DurationSeconds: req.DurationSeconds,
+			ExpiresAt:       req.ExpiresAt,
+		})
+		if err != nil {
+			log.Printf("Create video history failed: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to store history")
+			return
+		}
+
+		writeJSON(w, historyResponse{Success: true, ID: id})
 	}
+}
 
-	// Check if it's a transcript snapshot list
-	if len(pathParts) >= 5 && pathParts[4] == "transcript-snapshots" && r.Method == "GET" {
-		handleListTranscriptSnapshots(w, r, pathParts[3])
-		return
+func handleCreateAudioHistory(verifier *auth.KeycloakVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user, ok := authenticateUserFromRequest(verifier, w, r)
+		if !ok {
+			return
+		}
+
+		var req historyAudioRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+
+		hasDiarization := req.HasDiarization || len(req.Segments) > 0
+
+		id, err := database.CreateUserAudioSession(user.ID, database.UserAudioSessionInput{
+			SessionID:      req.SessionID,
+			Filename:       req.Filename,
+			Transcription:  req.Transcription,
+			Translation:    req.Translation,
+			AudioPath:      req.AudioPath,
+			TTSPath:        req.TTSPath,
+			SourceLang:     req.SourceLang,
+			TargetLang:     req.TargetLang,
+			HasDiarization: hasDiarization,
+			NumSpeakers:    req.NumSpeakers,
+			Segments:       req.Segments,
+		})
+		if err != nil {
+			log.Printf("Create audio history failed: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to store history")
+			return
+		}
+
+		writeJSON(w, historyResponse{Success: true, ID: id})
 	}
+}
 
-	// Check if it's an end meeting request
-	if len(pathParts) >= 5 && pathParts[4] == "end" && r.Method == "POST" {
-		handleEndMeeting(w, r, roomManager, llmClient, pathParts[3])
-		return
+func handleCreateStreamingHistory(verifier *auth.KeycloakVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user, ok := authenticateUserFromRequest(verifier, w, r)
+		if !ok {
+			return
+		}
+
+		var req historyStreamingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+
+		id, err := database.CreateUserStreamingSession(user.ID, database.UserStreamingSessionInput{
+			SessionID:            req.SessionID,
+			SourceLang:           req.SourceLang,
+			TargetLang:           req.TargetLang,
+			TotalChunks:          req.TotalChunks,
+			TotalDurationSeconds: req.TotalDurationSeconds,
+			FinalTranscript:      req.FinalTranscript,
+			FinalTranslation:     req.FinalTranslation,
+		})
+		if err != nil {
+			log.Printf("Create streaming history failed: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to store history")
+			return
+		}
+
+		writeJSON(w, historyResponse{Success: true, ID: id})
 	}
+}
 
-	// Check if it's a participant link request
-	if len(pathParts) >= 5 && pathParts[4] == "link" && r.Method == "POST" {
-		handleLinkParticipant(w, r, keycloakVerifier, pathParts[3])
+func handleCreateUserFile(verifier *auth.KeycloakVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user, ok := authenticateUserFromRequest(verifier, w, r)
+		if !ok {
+			return
+		}
+
+		var req userFileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+
+		id, err := database.CreateUserFile(&user.ID, database.UserFileInput{
+			SessionType:   req.SessionType,
+			SessionID:     req.SessionID,
+			BucketName:    req.BucketName,
+			FileKey:       req.FileKey,
+			ContentHash:   req.ContentHash,
+			Etag:          req.Etag,
+			MimeType:      req.MimeType,
+			FileSizeBytes: req.FileSizeBytes,
+			AccessedAt:    req.AccessedAt,
+		})
+		if err != nil {
+			log.Printf("Create user file failed: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to store file metadata")
+			return
+		}
+
+		writeJSON(w, historyResponse{Success: true, ID: id})
+	}
+}
+
+// handleUserFileURL serves GET /api/files/{id}/url: a presigned download link
+// for one of the caller's stored files, so large media is fetched from MinIO
+// directly. `?expires=` sets the lifetime in seconds.
+func handleUserFileURL(verifier *auth.KeycloakVerifier, minioClient *storage.MinioClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/files/"), "/"), "/")
+		if len(parts) != 2 || parts[1] != "url" {
+			sendJSONError(w, http.StatusNotFound, "Not found")
+			return
+		}
+		fileID, err := strconv.Atoi(parts[0])
+		if err != nil || fileID <= 0 {
+			sendJSONError(w, http.StatusBadRequest, "Invalid file ID")
+			return
+		}
+
+		user, ok := authenticateUserFromRequest(verifier, w, r)
+		if !ok {
+			return
+		}
+		if !minioClient.Enabled() {
+			sendJSONError(w, http.StatusServiceUnavailable, "Object storage is disabled")
+			return
+		}
+
+		var expiry time.Duration
+		if raw := r.URL.Query().Get("expires"); raw != "" {
+			seconds, err := strconv.Atoi(raw)
+			if err != nil || seconds <= 0 {
+				sendJSONError(w, http.StatusBadRequest, "expires must be a positive number of seconds")
+				return
+			}
+			expiry = time.Duration(seconds) * time.Second
+		}
+
+		file, err := database.GetUserFile(fileID)
+		if err != nil {
+			log.Printf("Get user file %d failed: %v", fileID, err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load file")
+			return
+		}
+		// Other users' files are reported as missing
+		if file == nil || file.UserID == nil || *file.UserID != user.ID || file.BucketName != minioClient.Bucket() {
+			sendJSONError(w, http.StatusNotFound, "File not found")
+			return
+		}
+
+		if expiry <= 0 || expiry > minioClient.PresignExpiry() {
+			expiry = minioClient.PresignExpiry()
+		}
+		url, err := minioClient.PresignedDownloadURL(r.Context(), file.FileKey, path.Base(file.FileKey), expiry)
+		if err != nil {
+			log.Printf("Presign failed for %s: %v", file.FileKey, err)
+			sendJSONError(w, http.StatusBadGateway, "Failed to create download link")
+			return
+		}
+		if err := database.TouchUserFile(file.ID); err != nil {
+			log.Printf("Failed to update access time of file %d: %v", file.ID, err)
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"id":        file.ID,
+			"url":       url,
+			"expiresAt": time.Now().Add(expiry).UTC(),
+			"fileKey":   file.FileKey,
+			"mimeType":  file.MimeType,
+			"sizeBytes": file.FileSizeBytes,
+		})
+	}
+}
+
+// parseTagFilter reads the tag and folder IDs to filter a history list by
+// (`?tag=1,2&folder=3`) and checks they belong to the user. An unknown ID
+// sends a 400 and returns false.
+func parseTagFilter(w http.ResponseWriter, r *http.Request, userID int) ([]int64, bool) {
+	tagIDs := []int64{}
+	seen := make(map[int]bool)
+	for _, key := range []string{"tag", "folder"} {
+		for _, value := range r.URL.Query()[key] {
+			for _, part := range strings.Split(value, ",") {
+				part = strings.TrimSpace(part)
+				if part == "" {
+					continue
+				}
+				id, err := strconv.Atoi(part)
+				if err != nil {
+					sendJSONError(w, http.StatusBadRequest, "Invalid "+key+" ID")
+					return nil, false
+				}
+				// Items must carry as many distinct tags as are listed, so repeats
+				// would match nothing
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				tag, err := database.GetUserTag(userID, id)
+				if err != nil {
+					log.Printf("Failed to load tag %d: %v", id, err)
+					sendJSONError(w, http.StatusInternalServerError, "Failed to load tags")
+					return nil, false
+				}
+				if tag == nil {
+					sendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Unknown %s %d", key, id))
+					return nil, false
+				}
+				tagIDs = append(tagIDs, int64(id))
+			}
+		}
+	}
+	return tagIDs, true
+}
+
+// parseHistoryDate reads a date filter of the history list: an RFC 3339
+// time or a YYYY-MM-DD date. A date given for `to` covers that whole day.
+func parseHistoryDate(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// handleListHistory lists the user's video, audio and streaming sessions,
+// filtered by `type`, `language` (source or target), `from` and `to`
+// (RFC 3339 or YYYY-MM-DD), `tag` and `folder`
+func handleListHistory(verifier *auth.KeycloakVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user, ok := authenticateUserFromRequest(verifier, w, r)
+		if !ok {
+			return
+		}
+
+		query := r.URL.Query()
+		limit := 20
+		offset := 0
+		if l := query.Get("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+				limit = parsed
+			}
+		}
+		if o := query.Get("offset"); o != "" {
+			if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+				offset = parsed
+			}
+		}
+		sessionType := query.Get("type")
+		switch sessionType {
+		case "", database.TagItemVideo, database.TagItemAudio, database.TagItemStreaming:
+		default:
+			sendJSONError(w, http.StatusBadRequest, "type must be video, audio or streaming")
+			return
+		}
+		tagIDs, ok := parseTagFilter(w, r, user.ID)
+		if !ok {
+			return
+		}
+		from, err := parseHistoryDate(query.Get("from"), false)
+		if err != nil {
+			sendJSONError(w, http.StatusBadRequest, "from must be an RFC 3339 time or a YYYY-MM-DD date")
+			return
+		}
+		to, err := parseHistoryDate(query.Get("to"), true)
+		if err != nil {
+			sendJSONError(w, http.StatusBadRequest, "to must be an RFC 3339 time or a YYYY-MM-DD date")
+			return
+		}
+
+		sessions, total, err := database.ListUserHistorySessions(user.ID, database.HistoryFilter{
+			Type:     sessionType,
+			TagIDs:   tagIDs,
+			Language: query.Get("language"),
+			From:     from,
+			To:       to,
+		}, limit, offset)
+		if err != nil {
+			log.Printf("List history failed: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to list history")
+			return
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"success":  true,
+			"sessions": sessions,
+			"total":    total,
+			"limit":    limit,
+			"offset":   offset,
+		})
+	}
+}
+
+// handleHistorySession shows or deletes one of the user's history sessions:
+//
+//	GET    /api/history/{video|audio|streaming}/{sessionId}   the session with its files and tags
+//	DELETE /api/history/{video|audio|streaming}/{sessionId}   the session, its files in object storage and its indexed knowledge
+//
+// Other users' sessions are reported as missing.
+func handleHistorySession(verifier *auth.KeycloakVerifier, minioClient *storage.MinioClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/history/"), "/"), "/")
+		if len(parts) != 2 || parts[1] == "" {
+			sendJSONError(w, http.StatusNotFound, "Not found")
+			return
+		}
+		sessionType, sessionID := parts[0], parts[1]
+		switch sessionType {
+		case database.TagItemVideo, database.TagItemAudio, database.TagItemStreaming:
+		default:
+			sendJSONError(w, http.StatusBadRequest, "type must be video, audio or streaming")
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		user, ok := authenticateUserFromRequest(verifier, w, r)
+		if !ok {
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			files, found, err := database.DeleteUserHistorySession(user.ID, sessionType, sessionID)
+			if err != nil {
+				log.Printf("Delete %s history %s failed: %v", sessionType, sessionID, err)
+				sendJSONError(w, http.StatusInternalServerError, "Failed to delete history")
+				return
+			}
+			if !found {
+				sendJSONError(w, http.StatusNotFound, "Session not found")
+				return
+			}
+
+			removed := 0
+			for _, file := range files {
+				if minioClient == nil || !minioClient.Enabled() || file.BucketName != minioClient.Bucket() {
+					log.Printf("Cannot remove %s/%s of deleted %s session %s: object storage unavailable",
+						file.BucketName, file.FileKey, sessionType, sessionID)
+					continue
+				}
+				if err := minioClient.Remove(r.Context(), file.FileKey); err != nil {
+					log.Printf("Failed to remove %s of deleted %s session %s: %v", file.FileKey, sessionType, sessionID, err)
+					continue
+				}
+				removed++
+			}
+			log.Printf("User %d deleted %s session %s (%d of %d files removed)", user.ID, sessionType, sessionID, removed, len(files))
+
+			writeJSON(w, map[string]interface{}{
+				"success":      true,
+				"type":         sessionType,
+				"sessionId":    sessionID,
+				"filesRemoved": removed,
+				"filesFailed":  len(files) - removed,
+			})
+			return
+		}
+
+		var session interface{}
+		var err error
+		switch sessionType {
+		case database.TagItemVideo:
+			var record *database.UserVideoSessionRecord
+			if record, err = database.GetUserVideoSessionBySessionID(user.ID, sessionID); record != nil {
+				session = record
+			}
+		case database.TagItemAudio:
+			var record *database.UserAudioSessionRecord
+			if record, err = database.GetUserAudioSessionBySessionID(user.ID, sessionID); record != nil {
+				session = record
+			}
+		case database.TagItemStreaming:
+			var record *database.UserStreamingSessionRecord
+			if record, err = database.GetUserStreamingSessionBySessionID(user.ID, sessionID); record != nil {
+				session = record
+			}
+		}
+		if err != nil {
+			log.Printf("Get %s history %s failed: %v", sessionType, sessionID, err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load history")
+			return
+		}
+		if session == nil {
+			sendJSONError(w, http.StatusNotFound, "Session not found")
+			return
+		}
+
+		files, err := database.ListHistorySessionFiles(user.ID, sessionType, sessionID)
+		if err != nil {
+			log.Printf("List files of %s history %s failed: %v", sessionType, sessionID, err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load history")
+			return
+		}
+		tags, err := database.GetHistorySessionTags(user.ID, sessionType, sessionID)
+		if err != nil {
+			log.Printf("Load tags of %s history %s failed: %v", sessionType, sessionID, err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load history")
+			return
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"success": true,
+			"type":    sessionType,
+			"session": session,
+			"files":   files,
+			"tags":    tags,
+		})
+	}
+}
+
+// handleTags manages the user's tags and folders and what they are attached to:
+//
+//	GET    /api/tags[?kind=folder]   list with item counts
+//	POST   /api/tags                 create {"name", "kind", "color"}
+//	PUT    /api/tags/{id}            rename or recolor
+//	DELETE /api/tags/{id}            delete, detaching it everywhere
+//	POST   /api/tags/{id}/items      attach {"type": "video|audio|streaming|meeting", "id": "..."}
+//	DELETE /api/tags/{id}/items      detach, same body or ?type=&id=
+func handleTags(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	type tagRequest struct {
+		Name  string `json:"name"`
+		Kind  string `json:"kind"`
+		Color string `json:"color"`
+	}
+	decodeTag := func() (*database.Tag, bool) {
+		var req tagRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return nil, false
+		}
+		tag := &database.Tag{
+			Name:  strings.TrimSpace(req.Name),
+			Kind:  req.Kind,
+			Color: strings.TrimSpace(req.Color),
+		}
+		if tag.Kind == "" {
+			tag.Kind = database.TagKindTag
+		}
+		if tag.Kind != database.TagKindTag && tag.Kind != database.TagKindFolder {
+			sendJSONError(w, http.StatusBadRequest, "kind must be tag or folder")
+			return nil, false
+		}
+		if tag.Name == "" || len(tag.Name) > 100 {
+			sendJSONError(w, http.StatusBadRequest, "name is required (at most 100 characters)")
+			return nil, false
+		}
+		if len(tag.Color) > 20 {
+			sendJSONError(w, http.StatusBadRequest, "color is too long")
+			return nil, false
+		}
+		return tag, true
+	}
+	saveFailed := func(err error, action string) {
+		if errors.Is(err, database.ErrTagExists) {
+			sendJSONError(w, http.StatusConflict, "A tag or folder with this name already exists")
+			return
+		}
+		log.Printf("Failed to %s tag: %v", action, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to "+action+" tag")
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/tags"), "/"), "/")
+
+	if parts[0] == "" {
+		switch r.Method {
+		case http.MethodGet:
+			kind := r.URL.Query().Get("kind")
+			if kind != "" && kind != database.TagKindTag && kind != database.TagKindFolder {
+				sendJSONError(w, http.StatusBadRequest, "kind must be tag or folder")
+				return
+			}
+			tags, err := database.ListUserTags(user.ID, kind)
+			if err != nil {
+				log.Printf("Failed to list tags: %v", err)
+				sendJSONError(w, http.StatusInternalServerError, "Failed to list tags")
+				return
+			}
+			writeJSON(w, map[string]interface{}{"success": true, "tags": tags})
+
+		case http.MethodPost:
+			tag, ok := decodeTag()
+			if !ok {
+				return
+			}
+			if err := database.CreateUserTag(user.ID, tag); err != nil {
+				saveFailed(err, "create")
+				return
+			}
+			writeJSON(w, map[string]interface{}{"success": true, "tag": tag})
+
+		default:
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || len(parts) > 2 || (len(parts) == 2 && parts[1] != "items") {
+		sendJSONError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	existing, err := database.GetUserTag(user.ID, id)
+	if err != nil {
+		log.Printf("Failed to load tag %d: %v", id, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load tag")
+		return
+	}
+	if existing == nil {
+		sendJSONError(w, http.StatusNotFound, "Tag not found")
 		return
 	}
 
-	// Check if it's a speaker name update: /api/meetings/{roomCode}/speakers/{speakerId}
-	if len(pathParts) >= 6 && pathParts[4] == "speakers" && r.Method == "POST" {
-		handleUpdateSpeakerName(w, r, roomManager, pathParts[3], pathParts[5])
-		return
-	}
+	if len(parts) == 2 {
+		handleTagItems(w, r, user.ID, existing)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		tag, ok := decodeTag()
+		if !ok {
+			return
+		}
+		tag.ID = existing.ID
+		tag.Kind = existing.Kind
+		if err := database.UpdateUserTag(user.ID, tag); err != nil {
+			saveFailed(err, "update")
+			return
+		}
+		tag.CreatedAt = existing.CreatedAt
+		writeJSON(w, map[string]interface{}{"success": true, "tag": tag})
+
+	case http.MethodDelete:
+		if err := database.DeleteUserTag(user.ID, id); err != nil {
+			log.Printf("Failed to delete tag %d: %v", id, err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to delete tag")
+			return
+		}
+		writeJSON(w, map[string]interface{}{"success": true})
+
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleTagItems attaches a tag to, or detaches it from, a session or meeting
+// the user can see
+func handleTagItems(w http.ResponseWriter, r *http.Request, userID int, tag *database.Tag) {
+	var req struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	}
+	switch r.Method {
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+	case http.MethodDelete:
+		req.Type = r.URL.Query().Get("type")
+		req.ID = r.URL.Query().Get("id")
+		if req.Type == "" && req.ID == "" {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				sendJSONError(w, http.StatusBadRequest, "Invalid request")
+				return
+			}
+		}
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	req.ID = strings.TrimSpace(req.ID)
+	if req.ID == "" {
+		sendJSONError(w, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	var visible bool
+	var err error
+	switch req.Type {
+	case database.TagItemMeeting:
+		visible, err = database.UserCanAccessMeeting(userID, req.ID)
+	case database.TagItemVideo, database.TagItemAudio, database.TagItemStreaming:
+		visible, err = database.UserOwnsHistorySession(userID, req.Type, req.ID)
+	default:
+		sendJSONError(w, http.StatusBadRequest, "type must be video, audio, streaming or meeting")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to check %s %s for tagging: %v", req.Type, req.ID, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load item")
+		return
+	}
+	// Detaching stays allowed after access is lost so stale tags can be cleaned up
+	if !visible && r.Method == http.MethodPost {
+		sendJSONError(w, http.StatusNotFound, "Item not found")
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		err = database.TagItem(userID, tag, req.Type, req.ID)
+	} else {
+		err = database.UntagItem(tag.ID, req.Type, req.ID)
+	}
+	if err != nil {
+		log.Printf("Failed to update tag %d on %s %s: %v", tag.ID, req.Type, req.ID, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to update tags")
+		return
+	}
+	writeJSON(w, map[string]interface{}{"success": true})
+}
+
+func extractBearerToken(r *http.Request) (string, error) {
+	authHeader := strings.TrimSpace(r.Header.Get("Authorization"))
+	if authHeader == "" {
+		return "", fmt.Errorf("Authorization header missing")
+	}
+
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return "", fmt.Errorf("Authorization header must be Bearer token")
+	}
+
+	token := strings.TrimSpace(parts[1])
+	if token == "" {
+		return "", fmt.Errorf("Bearer token is empty")
+	}
+
+	return token, nil
+}
+
+// applyQueryToken lets clients that cannot set headers (download links and,
+// with WS_QUERY_TOKENS, legacy socket clients) authenticate with ?token=
+func applyQueryToken(r *http.Request) {
+	if token := r.URL.Query().Get("token"); token != "" && r.Header.Get("Authorization") == "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+func authenticateUserFromRequest(verifier *auth.KeycloakVerifier, w http.ResponseWriter, r *http.Request) (*database.User, bool) {
+	if user := userFromContext(r.Context()); user != nil {
+		return user, true
+	}
+	if verifier == nil {
+		sendJSONError(w, http.StatusServiceUnavailable, "Keycloak auth not configured")
+		return nil, false
+	}
+
+	tokenStr, err := extractBearerToken(r)
+	if err != nil {
+		sendJSONError(w, http.StatusUnauthorized, err.Error())
+		return nil, false
+	}
+
+	claims, err := verifier.VerifyToken(r.Context(), tokenStr)
+	if err != nil {
+		sendJSONError(w, http.StatusUnauthorized, "Invalid token")
+		return nil, false
+	}
+
+	user, err := upsertUserFromClaims(claims)
+	if err != nil {
+		log.Printf("Keycloak upsert failed: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to persist user")
+		return nil, false
+	}
+
+	return user, true
+}
+
+func maybeAuthenticateUserFromRequest(verifier *auth.KeycloakVerifier, r *http.Request) (*database.User, error) {
+	if user := userFromContext(r.Context()); user != nil {
+		return user, nil
+	}
+	if verifier == nil {
+		return nil, nil
+	}
+
+	authHeader := strings.TrimSpace(r.Header.Get("Authorization"))
+	if authHeader == "" {
+		return nil, nil
+	}
+
+	tokenStr, err := extractBearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := verifier.VerifyToken(r.Context(), tokenStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return upsertUserFromClaims(claims)
+}
+
+func upsertUserFromClaims(claims map[string]interface{}) (*database.User, error) {
+	sub, _ := claims["sub"].(string)
+	preferredUsername, _ := claims["preferred_username"].(string)
+	displayName, _ := claims["name"].(string)
+	email, _ := claims["email"].(string)
+	emailVerified := parseEmailVerified(claims["email_verified"])
+
+	return database.UpsertKeycloakUser(sub, preferredUsername, email, emailVerified, displayName)
+}
+
+// protectedRoutePrefixes are the paths requireAuth guards: uploads, recording
+// sessions, WebSockets, progress streams and meeting endpoints
+var protectedRoutePrefixes = []string{"/upload", "/recording/", "/ws", "/progress/", "/api/meetings"}
+
+// requestUserKey is the context key for the user attached by requireAuth
+type requestUserKey struct{}
+
+// userFromContext returns the user requireAuth attached to the request, if any
+func userFromContext(ctx context.Context) *database.User {
+	user, _ := ctx.Value(requestUserKey{}).(*database.User)
+	return user
+}
+
+// socketAuth configures how WebSocket and event-stream requests, which
+// cannot set headers, authenticate
+type socketAuth struct {
+	tickets     *wsticket.Store
+	queryTokens bool // also accept a Keycloak access token as ?token= (legacy clients)
+}
+
+// requireAuth verifies the Keycloak bearer token on protected routes,
+// upserts the user and attaches it to the request context. WebSocket and
+// event-stream requests authenticate with a single-use ticket instead (see
+// handleSocketTickets), or with ?token= when sockets.queryTokens is set.
+// Paths under publicPrefixes stay open, though a ticket sent to one still
+// identifies its user; without a verifier every route is open.
+func requireAuth(verifier *auth.KeycloakVerifier, publicPrefixes []string, sockets socketAuth, next http.Handler) http.Handler {
+	if verifier == nil {
+		log.Printf("[Auth] Keycloak not configured; upload, recording, WebSocket and meeting routes are unauthenticated")
+		return next
+	}
+	if len(publicPrefixes) > 0 {
+		log.Printf("[Auth] Public routes: %s", strings.Join(publicPrefixes, ", "))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		socket := websocket.IsWebSocketUpgrade(r) || strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+		if socket {
+			if token := wsticket.FromRequest(r); token != "" {
+				purpose, target, ok := wsticket.Route(r.URL.Path)
+				if !ok {
+					sendJSONError(w, http.StatusUnauthorized, "Tickets are not accepted on this route")
+					return
+				}
+				ticket, err := sockets.tickets.Redeem(token, purpose, target)
+				if err != nil {
+					sendJSONError(w, http.StatusUnauthorized, err.Error())
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestUserKey{}, ticket.User)))
+				return
+			}
+		}
+
+		if !hasAnyPrefix(r.URL.Path, protectedRoutePrefixes) || hasAnyPrefix(r.URL.Path, publicPrefixes) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if socket && sockets.queryTokens {
+			applyQueryToken(r)
+		}
+		user, ok := authenticateUserFromRequest(verifier, w, r)
+		if !ok {
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestUserKey{}, user)))
+	})
+}
+
+// handleSocketTickets mints a single-use ticket for one WebSocket or
+// event-stream connection. The ticket expires after WS_TICKET_TTL_SECONDS;
+// target binds it to one room code or session ID.
+//
+//	POST /api/ws-tickets {"purpose": "meeting", "target": "ABC123"}
+func handleSocketTickets(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, tickets *wsticket.Store) {
+	if r.Method != http.MethodPost {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Purpose string `json:"purpose"`
+		Target  string `json:"target"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if !wsticket.ValidPurpose(req.Purpose) {
+		sendJSONError(w, http.StatusBadRequest, "purpose must be stream, meeting, recording, progress, notifications or captions")
+		return
+	}
+
+	token, ticket, err := tickets.Issue(user, req.Purpose, strings.TrimSpace(req.Target))
+	if err != nil {
+		log.Printf("Failed to issue socket ticket: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to issue ticket")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"ticket":    token,
+		"purpose":   ticket.Purpose,
+		"target":    ticket.Target,
+		"expiresAt": ticket.ExpiresAt.UTC(),
+		"protocol":  wsticket.Protocol,
+	})
+}
+
+func hasAnyPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseEmailVerified(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	default:
+		return false
+	}
+}
+
+// languageParam reads a language code query parameter in canonical form
+func languageParam(r *http.Request, name string) string {
+	return langcode.Normalize(r.URL.Query().Get(name))
+}
+
+func writeJSON(w http.ResponseWriter, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payload)
+}
+
+func storageDetectContentType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == "" {
+		return "application/octet-stream"
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// dedupSourceLang is the source language an upload's results can be reused
+// for: any when it is auto-detected
+func dedupSourceLang(sourceLang string, autoDetect bool) string {
+	if autoDetect {
+		return ""
+	}
+	return sourceLang
+}
+
+func computeFileHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// videoJobKind is the job queue kind for uploaded video processing
+const videoJobKind = "video_upload"
+
+// videoJobPayload is everything a video job needs to run after the upload
+// request has returned. The upload itself is spooled to FilePath.
+type videoJobPayload struct {
+	FilePath        string `json:"filePath"`
+	Filename        string `json:"filename"`
+	Size            int64  `json:"size"`
+	SourceLang      string `json:"sourceLang"`
+	TargetLang      string `json:"targetLang"`
+	GenerateTTS     bool   `json:"generateTTS"`
+	CloneVoice      bool   `json:"cloneVoice"`
+	ForceProcessing bool   `json:"force"`
+	MatchDuration   bool   `json:"matchDuration"`   // time-stretch the dub to the video length
+	SegmentDubbing  bool   `json:"segmentDubbing"`  // synthesize per ASR segment at its original timestamp
+	MixOriginal     bool   `json:"mixOriginal"`     // keep the original audio ducked under the dub
+	Diarization     bool   `json:"diarization"`     // with cloneVoice, clone each speaker's voice separately
+	OrgID           string `json:"orgId,omitempty"` // selects the pronunciation lexicon
+
+	// Receives the results (or the failure) once the job has finished
+	CallbackURL string `json:"callbackUrl,omitempty"`
+
+	// Set for files uploaded through /upload/batch
+	BatchID string `json:"batchId,omitempty"`
+
+	// Set by /upload/{sessionId}/retry: stages before it reuse the stored
+	// outputs of the failed job
+	ResumeFrom string `json:"resumeFrom,omitempty"`
+}
+
+// webhookJobKind is the job queue kind for upload completion callbacks, so
+// failed deliveries are retried with the queue's backoff
+const webhookJobKind = "upload_callback"
+
+// Upload callback events
+const (
+	uploadCallbackCompleted = "upload.completed"
+	uploadCallbackFailed    = "upload.failed"
+)
+
+// webhookJobPayload is a callback body waiting to be delivered; it is signed
+// at delivery time so retries carry a fresh timestamp
+type webhookJobPayload struct {
+	URL   string          `json:"url"`
+	Event string          `json:"event"`
+	Body  json.RawMessage `json:"body"`
+}
+
+// uploadCallback is the body POSTed to an upload's callbackUrl. Results are
+// the same map the progress WebSocket receives on completion.
+type uploadCallback struct {
+	Event     string                 `json:"event"`
+	SessionID string                 `json:"sessionId"`
+	JobID     int                    `json:"jobId"`
+	Kind      string                 `json:"kind"`
+	Filename  string                 `json:"filename"`
+	Results   map[string]interface{} `json:"results,omitempty"`
+	Stage     string                 `json:"stage,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// queueUploadCallback queues delivery of a finished upload job's outcome to
+// its callback URL, if it has one
+func queueUploadCallback(jobQueue *jobs.Queue, callbackURL string, job *database.Job, callback uploadCallback) {
+	if callbackURL == "" {
+		return
+	}
+	callback.SessionID = job.SessionID
+	callback.JobID = job.ID
+	callback.Timestamp = time.Now().UTC()
+
+	body, err := json.Marshal(callback)
+	if err != nil {
+		log.Printf("Failed to encode upload callback for job %d: %v", job.ID, err)
+		return
+	}
+	if _, err := jobQueue.Enqueue(webhookJobKind, job.SessionID, job.UserID, webhookJobPayload{
+		URL:   callbackURL,
+		Event: callback.Event,
+		Body:  body,
+	}); err != nil {
+		log.Printf("Failed to queue upload callback for job %d: %v", job.ID, err)
+	}
+}
+
+// newWebhookJobHandler delivers a queued callback. Refused addresses and 4xx
+// answers (other than 408 and 429) are not retried.
+func newWebhookJobHandler(sender *webhook.Sender) jobs.Handler {
+	return func(ctx context.Context, job *database.Job) error {
+		var payload webhookJobPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return jobs.Permanent(fmt.Errorf("invalid callback job payload: %w", err))
+		}
+		if err := sender.Send(ctx, payload.URL, payload.Event, payload.Body); err != nil {
+			if webhook.Permanent(err) {
+				return jobs.Permanent(err)
+			}
+			return err
+		}
+		log.Printf("Delivered %s callback for session %s", payload.Event, job.SessionID)
+		return nil
+	}
+}
+
+// maxEstimateSampleBytes bounds the sample clip accepted by /estimate
+const maxEstimateSampleBytes = 25 << 20
+
+// handleEstimate predicts processing time, queue wait and service usage for
+// an upload without submitting it.
+//
+//	POST /estimate  JSON: {"kind":"video","durationSeconds":5400,"targetLangs":["es","fr"],"generateTTS":true}
+//	POST /estimate  multipart: sample (a short clip or the head of the file), sizeBytes (full file size), plus the same options as form fields
+//
+// With a sample, the media duration is probed from it and scaled by
+// sizeBytes/sample size when the sample is only part of the file.
+func handleEstimate(w http.ResponseWriter, r *http.Request, processor *video.Processor, jobQueue *jobs.Queue, estimator *estimate.Estimator) {
+	if r.Method != http.MethodPost {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req estimate.Request
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		r.Body = http.MaxBytesReader(w, r.Body, maxEstimateSampleBytes+(1<<20))
+		if err := r.ParseMultipartForm(maxEstimateSampleBytes); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Failed to parse form (samples are limited to 25MB)")
+			return
+		}
+		req = estimate.Request{
+			Kind:           r.FormValue("kind"),
+			SourceLang:     langcode.Normalize(r.FormValue("sourceLang")),
+			Diarization:    r.FormValue("diarization") == "true",
+			EnhanceAudio:   r.FormValue("enhanceAudio") == "true",
+			GenerateTTS:    r.FormValue("generateTTS") == "true",
+			CloneVoice:     r.FormValue("cloneVoice") == "true",
+			SegmentDubbing: r.FormValue("segmentDubbing") == "true",
+		}
+		for _, value := range append(r.Form["targetLangs"], r.Form["targetLang"]...) {
+			req.TargetLangs = append(req.TargetLangs, strings.Split(value, ",")...)
+		}
+		if value := r.FormValue("durationSeconds"); value != "" {
+			duration, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				sendJSONError(w, http.StatusBadRequest, "durationSeconds must be a number")
+				return
+			}
+			req.DurationSeconds = duration
+		}
+
+		if file, header, err := r.FormFile("sample"); err == nil {
+			defer file.Close()
+			duration, err := probeSampleDuration(processor, file, header.Filename)
+			if err != nil {
+				log.Printf("Failed to probe estimate sample: %v", err)
+				sendJSONError(w, http.StatusBadRequest, "Could not read the sample's duration; send durationSeconds instead")
+				return
+			}
+			if sizeBytes, err := strconv.ParseInt(r.FormValue("sizeBytes"), 10, 64); err == nil && sizeBytes > header.Size && header.Size > 0 {
+				duration *= float64(sizeBytes) / float64(header.Size)
+			}
+			req.DurationSeconds = duration
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.DurationSeconds <= 0 {
+		sendJSONError(w, http.StatusBadRequest, "durationSeconds or a sample is required")
+		return
+	}
+
+	queued, err := jobQueue.Depth()
+	if err != nil {
+		log.Printf("Failed to read job queue depth: %v", err)
+	}
+	writeJSON(w, map[string]interface{}{
+		"success":  true,
+		"estimate": estimator.Estimate(req, queued),
+	})
+}
+
+// probeSampleDuration spools an uploaded sample to the temp dir and probes it
+func probeSampleDuration(processor *video.Processor, file io.Reader, filename string) (float64, error) {
+	temp, err := os.CreateTemp(processor.TempDir, "estimate_*"+filepath.Ext(filename))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	if _, err := io.Copy(temp, file); err != nil {
+		return 0, fmt.Errorf("failed to save sample: %w", err)
+	}
+	return processor.ProbeDuration(temp.Name())
+}
+
+// serviceBacklog is the outstanding work for one model service, for an
+// external autoscaler. Pending seconds are the estimated service time still
+// needed by queued and running upload jobs; live audio shows up as calls.
+type serviceBacklog struct {
+	PendingSeconds           float64 `json:"pendingSeconds"`
+	QueuedJobs               int     `json:"queuedJobs"`
+	RunningJobs              int     `json:"runningJobs"`
+	InFlightCalls            int     `json:"inFlightCalls"`
+	QueuedCalls              int     `json:"queuedCalls"`
+	Replicas                 int     `json:"replicas,omitempty"`
+	PendingSecondsPerReplica float64 `json:"pendingSecondsPerReplica,omitempty"`
+}
+
+// backlogDurations caches the media duration of spooled uploads by job ID so
+// repeated backlog polls probe each file once
+type backlogDurations struct {
+	mu        sync.Mutex
+	durations map[int]float64
+}
+
+// duration returns the job's media duration, probing the file on first use
+func (b *backlogDurations) duration(processor *video.Processor, jobID int, path string) float64 {
+	b.mu.Lock()
+	duration, ok := b.durations[jobID]
+	b.mu.Unlock()
+	if ok {
+		return duration
+	}
+
+	duration, err := processor.ProbeDuration(path)
+	if err != nil {
+		log.Printf("Backlog: failed to probe job %d: %v", jobID, err)
+		duration = 0
+	}
+	b.mu.Lock()
+	b.durations[jobID] = duration
+	b.mu.Unlock()
+	return duration
+}
+
+// retain drops cached durations of jobs that are no longer active
+func (b *backlogDurations) retain(active map[int]bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id := range b.durations {
+		if !active[id] {
+			delete(b.durations, id)
+		}
+	}
+}
+
+// activeJobFiles returns the spooled inputs of queued and running upload
+// jobs, and of failed ones that can still be requeued from the failure
+// inbox, which the temp janitor must keep however long they wait
+func activeJobFiles(processor *video.Processor) ([]string, error) {
+	activeJobs, err := database.ListActiveJobs([]string{videoJobKind, audioJobKind})
+	if err != nil {
+		return nil, err
+	}
+	openFailures, err := database.ListProcessingFailures(database.FailureFilter{
+		Status: database.FailureOpen,
+		Source: database.FailureSourceJob,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(activeJobs)+len(openFailures))
+	for _, job := range activeJobs {
+		if path := spooledUpload(job.Payload); path != "" {
+			paths = append(paths, path)
+		}
+		// A resumed video job reads the stage outputs of the failed one
+		if job.Kind == videoJobKind {
+			paths = append(paths, processor.JobStages(job.SessionID).Files()...)
+		}
+	}
+	for _, failure := range openFailures {
+		if failure.Kind != videoJobKind && failure.Kind != audioJobKind {
+			continue
+		}
+		if path := spooledUpload(failure.Context); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// spooledUpload returns the spooled input of an upload job's payload; both
+// payload kinds spool their upload to filePath
+func spooledUpload(payload json.RawMessage) string {
+	var upload struct {
+		FilePath string `json:"filePath"`
+	}
+	if err := json.Unmarshal(payload, &upload); err != nil {
+		return ""
+	}
+	return upload.FilePath
+}
+
+// handleBacklog reports pending work per model service for autoscaling
+//
+//	GET /api/autoscaling/backlog
+func handleBacklog(w http.ResponseWriter, r *http.Request, processor *video.Processor, estimator *estimate.Estimator, durations *backlogDurations, limiters map[string]*ratelimit.Limiter, pools map[string]*replica.Pool) {
+	if r.Method != http.MethodGet {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	activeJobs, err := database.ListActiveJobs([]string{videoJobKind, audioJobKind})
+	if err != nil {
+		log.Printf("Failed to list active jobs: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to read job queue")
+		return
+	}
+
+	services := map[string]*serviceBacklog{
+		estimate.ServiceASR:         {},
+		estimate.ServiceTranslation: {},
+		estimate.ServiceTTS:         {},
+	}
+	active := make(map[int]bool, len(activeJobs))
+	for _, job := range activeJobs {
+		active[job.ID] = true
+
+		var req estimate.Request
+		var filePath string
+		switch job.Kind {
+		case videoJobKind:
+			var payload videoJobPayload
+			if err := json.Unmarshal(job.Payload, &payload); err != nil {
+				continue
+			}
+			filePath = payload.FilePath
+			req = estimate.Request{
+				Kind:           estimate.KindVideo,
+				TargetLangs:    []string{payload.TargetLang},
+				GenerateTTS:    payload.GenerateTTS,
+				CloneVoice:     payload.CloneVoice,
+				SegmentDubbing: payload.SegmentDubbing,
+			}
+		case audioJobKind:
+			var payload audioJobPayload
+			if err := json.Unmarshal(job.Payload, &payload); err != nil {
+				continue
+			}
+			filePath = payload.FilePath
+			req = estimate.Request{
+				Kind:         estimate.KindAudio,
+				TargetLangs:  []string{payload.TargetLang},
+				Diarization:  payload.EnableDiarization,
+				EnhanceAudio: payload.EnhanceAudio,
+				GenerateTTS:  payload.GenerateTTS,
+				CloneVoice:   payload.CloneVoice,
+			}
+		}
+		req.DurationSeconds = durations.duration(processor, job.ID, filePath)
+
+		var elapsed time.Duration
+		if job.Status == database.JobRunning {
+			elapsed = time.Since(job.UpdatedAt)
+		}
+		for service, seconds := range estimator.ServiceSeconds(req, elapsed) {
+			if seconds <= 0 {
+				continue
+			}
+			backlog := services[service]
+			backlog.PendingSeconds += seconds
+			if job.Status == database.JobRunning {
+				backlog.RunningJobs++
+			} else {
+				backlog.QueuedJobs++
+			}
+		}
+	}
+	durations.retain(active)
+
+	for service, backlog := range services {
+		backlog.PendingSeconds = math.Round(backlog.PendingSeconds*10) / 10
+		stats := limiters[service].Stats()
+		backlog.InFlightCalls = stats.InFlight
+		for _, priority := range stats.Priorities {
+			backlog.QueuedCalls += priority.Queued
+		}
+		if replicas := pools[service].Size(); replicas > 0 {
+			backlog.Replicas = replicas
+			backlog.PendingSecondsPerReplica = math.Round(backlog.PendingSeconds/float64(replicas)*10) / 10
+		}
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"generatedAt": time.Now().UTC(),
+		"services":    services,
+	})
+}
+
+// handleReplicas lists and changes the replicas calls are spread over, so an
+// autoscaler can add a replica once it is ready and remove one before
+// draining it. Jobs already running use the new set from their next call.
+//
+//	GET    /api/autoscaling/replicas
+//	POST   /api/autoscaling/replicas/{asr|tts}  {"url": "http://10.0.0.7:8003"}
+//	DELETE /api/autoscaling/replicas/{asr|tts}  {"url": "..."} or ?url=
+func handleReplicas(w http.ResponseWriter, r *http.Request, pools map[string]*replica.Pool) {
+	service := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/autoscaling/replicas"), "/")
+
+	if service == "" {
+		if r.Method != http.MethodGet {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		stats := make([]replica.Stats, 0, len(pools))
+		for _, name := range []string{estimate.ServiceASR, estimate.ServiceTTS} {
+			stats = append(stats, pools[name].Stats())
+		}
+		writeJSON(w, map[string]interface{}{"success": true, "pools": stats})
+		return
+	}
+
+	pool, ok := pools[service]
+	if !ok {
+		sendJSONError(w, http.StatusNotFound, "Unknown service (use asr or tts)")
+		return
+	}
+
+	var req struct {
+		URL string `json:"url"`
+	}
+	req.URL = r.URL.Query().Get("url")
+	if req.URL == "" && (r.Method == http.MethodPost || r.Method == http.MethodDelete) {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, map[string]interface{}{"success": true, "pool": pool.Stats()})
+		return
+
+	case http.MethodPost:
+		parsed, err := url.Parse(strings.TrimSpace(req.URL))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			sendJSONError(w, http.StatusBadRequest, "url must be an http(s) base URL")
+			return
+		}
+		if pool.Register(req.URL) {
+			log.Printf("Registered %s replica %s", service, req.URL)
+		}
+
+	case http.MethodDelete:
+		if pool.Size() == 1 {
+			sendJSONError(w, http.StatusConflict, "Cannot remove the last replica")
+			return
+		}
+		if !pool.Deregister(req.URL) {
+			sendJSONError(w, http.StatusNotFound, "Replica not registered")
+			return
+		}
+		log.Printf("Removed %s replica %s", service, req.URL)
+
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{"success": true, "pool": pool.Stats()})
+}
+
+func handleVideoUpload(w http.ResponseWriter, r *http.Request, processor *video.Processor, jobQueue *jobs.Queue, verifier *auth.KeycloakVerifier, webhooks *webhook.Sender) {
+	if r.Method != "POST" {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Parse multipart form first (max 500MB)
+	if err := r.ParseMultipartForm(500 << 20); err != nil {
+		log.Printf("Error parsing form: %v", err)
+		json.NewEncoder(w).Encode(videoUploadResponse{
+			Success: false,
+			Error:   "Failed to parse upload",
+		})
+		return
+	}
+
+	file, header, err := r.FormFile("video")
+	if err != nil {
+		log.Printf("Error getting file: %v", err)
+		json.NewEncoder(w).Encode(videoUploadResponse{
+			Success: false,
+			Error:   "No video file provided",
+		})
+		return
+	}
+
+	// Generate session ID for progress tracking
+	sessionID := fmt.Sprintf("upload_%d", time.Now().UnixNano())
+
+	// Read form values before queueing the job
+	targetLang := langcode.Normalize(r.FormValue("targetLang"))
+	if targetLang == "" {
+		targetLang = "ar" // Default to Arabic
+	}
+
+	sourceLang := langcode.Normalize(r.FormValue("sourceLang"))
+	if sourceLang == "" {
+		sourceLang = "en" // Default to English
+	}
+
+	// Check if user wants translated audio
+	generateTTS := r.FormValue("generateTTS") == "true"
+
+	// Check if user wants voice cloning
+	cloneVoice := r.FormValue("cloneVoice") == "true"
+	forceProcessing := r.FormValue("force") == "true"
+
+	// Stretch the dub to the video length instead of looping/trimming it
+	matchDuration := r.FormValue("matchDuration") == "true"
+
+	// Dub segment by segment at the original speech timestamps
+	segmentDubbing := r.FormValue("segmentDubbing") == "true"
+
+	// Keep the original audio (music, ambience) ducked under the dub
+	mixOriginal := r.FormValue("mixOriginal") == "true"
+
+	// Tell speakers apart so a cloned dub keeps each one's voice
+	diarization := r.FormValue("enableDiarization") == "true"
+
+	user, err := maybeAuthenticateUserFromRequest(verifier, r)
+	if err != nil {
+		sendJSONError(w, http.StatusUnauthorized, "Invalid token")
+		return
+	}
+
+	// Headless clients can be called back instead of watching progress
+	callbackURL := strings.TrimSpace(r.FormValue("callbackUrl"))
+	if callbackURL != "" {
+		if !webhooks.Enabled() {
+			sendJSONError(w, http.StatusBadRequest, "Upload callbacks are not configured on this server")
+			return
+		}
+		if err := webhooks.ValidateURL(r.Context(), callbackURL); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid callbackUrl: "+err.Error())
+			return
+		}
+	}
+	var userID *int
+	if user != nil {
+		userID = &user.ID
+	}
+	cloneVoice = cloneVoice && flags.Enabled(flags.VoiceCloning, flags.SubjectForUser(user))
+
+	// Spool the upload to disk so the job survives a restart
+	spoolPath, err := spoolUpload(processor, sessionID, header.Filename, file)
+	file.Close()
+	if err != nil {
+		log.Printf("Error spooling video upload: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to save video")
+		return
+	}
+	if _, ok := checkUploadQuota(w, processor, userID, spoolPath, header.Size); !ok {
+		os.Remove(spoolPath)
+		return
+	}
+
+	job, err := enqueueUserJob(jobQueue, videoJobKind, sessionID, userID, videoJobPayload{
+		FilePath:        spoolPath,
+		Filename:        header.Filename,
+		Size:            header.Size,
+		SourceLang:      sourceLang,
+		TargetLang:      targetLang,
+		GenerateTTS:     generateTTS,
+		CloneVoice:      cloneVoice,
+		ForceProcessing: forceProcessing,
+		MatchDuration:   matchDuration,
+		SegmentDubbing:  segmentDubbing,
+		MixOriginal:     mixOriginal,
+		Diarization:     diarization,
+		OrgID:           flags.SubjectForUser(user).OrgID,
+		CallbackURL:     callbackURL,
+	})
+	if exceeded, ok := quota.Exceeded(err); ok {
+		os.Remove(spoolPath)
+		writeQuotaExceeded(w, exceeded)
+		return
+	}
+	if err != nil {
+		os.Remove(spoolPath)
+		log.Printf("Error queueing video job: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to queue video processing")
+		return
+	}
+
+	// Send initial response with session and job IDs immediately
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(videoUploadResponse{
+		Success:   true,
+		SessionID: sessionID,
+		JobID:     job.ID,
+	})
+}
+
+// handleUploadRetry requeues a failed video upload, reusing the stored
+// outputs of the stages before fromStage (extraction, transcription,
+// translation or tts) instead of starting over:
+//
+//	POST /upload/{sessionId}/retry[?fromStage=tts]
+//
+// Without fromStage the job resumes after the last stage it finished. The
+// upload and stage outputs are kept for TEMP_FILE_TTL_HOURS after a failure.
+func handleUploadRetry(w http.ResponseWriter, r *http.Request, processor *video.Processor, jobQueue *jobs.Queue, verifier *auth.KeycloakVerifier) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/upload/"), "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "retry" {
+		sendJSONError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	sessionID := parts[0]
+
+	user, err := maybeAuthenticateUserFromRequest(verifier, r)
+	if err != nil {
+		sendJSONError(w, http.StatusUnauthorized, "Invalid token")
+		return
+	}
+
+	job, err := database.GetLatestJobBySession(videoJobKind, sessionID)
+	if err != nil {
+		log.Printf("Failed to load job: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load job")
+		return
+	}
+	if job == nil || (job.UserID != nil && (user == nil || user.ID != *job.UserID)) {
+		sendJSONError(w, http.StatusNotFound, "Upload not found")
+		return
+	}
+	if job.Status != database.JobFailed {
+		sendJSONError(w, http.StatusConflict, fmt.Sprintf("Upload is %s, only failed uploads can be retried", job.Status))
+		return
+	}
+
+	var payload videoJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		sendJSONError(w, http.StatusInternalServerError, "Invalid job payload")
+		return
+	}
+	if _, err := os.Stat(payload.FilePath); err != nil {
+		sendJSONError(w, http.StatusGone, "Uploaded file is no longer available, please upload it again")
+		return
+	}
+
+	state, _, err := processor.JobStages(sessionID).Load()
+	if err != nil {
+		log.Printf("Failed to load stored stages of %s: %v", sessionID, err)
+		state = nil
+	}
+	fromStage := r.URL.Query().Get("fromStage")
+	if fromStage == "" {
+		fromStage = video.StageExtraction
+		if state != nil {
+			if next := video.StageIndex(state.Completed) + 1; next < len(video.Stages) {
+				fromStage = video.Stages[next]
+			} else {
+				fromStage = video.StageTTS
+			}
+		}
+	}
+	idx := video.StageIndex(fromStage)
+	if idx < 0 {
+		sendJSONError(w, http.StatusBadRequest, "fromStage must be one of "+strings.Join(video.Stages, ", "))
+		return
+	}
+	if idx > 0 && !state.Reached(video.Stages[idx-1]) {
+		completed := ""
+		if state != nil {
+			completed = state.Completed
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":        false,
+			"error":          fmt.Sprintf("Cannot resume from %s: the %s output is not stored", fromStage, video.Stages[idx-1]),
+			"completedStage": completed,
+		})
+		return
+	}
+
+	payload.ResumeFrom = fromStage
+	retry, err := jobQueue.Enqueue(videoJobKind, sessionID, job.UserID, payload)
+	if err != nil {
+		log.Printf("Error queueing video retry: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to queue retry")
+		return
+	}
+	log.Printf("Retrying video upload %s from %s (job %d)", sessionID, fromStage, retry.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"sessionId": sessionID,
+		"jobId":     retry.ID,
+		"fromStage": fromStage,
+	})
+}
+
+// spoolUpload saves an upload under the temp dir's jobs folder, where queued
+// jobs read it from
+func spoolUpload(processor *video.Processor, sessionID, filename string, src io.Reader) (string, error) {
+	spoolDir := filepath.Join(processor.TempDir, "jobs")
+	if err := os.MkdirAll(spoolDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create job spool directory: %w", err)
+	}
+	spoolPath := filepath.Join(spoolDir, fmt.Sprintf("%s_%s", sessionID, filepath.Base(filename)))
+	outFile, err := os.Create(spoolPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create spool file: %w", err)
+	}
+	_, err = io.Copy(outFile, src)
+	outFile.Close()
+	if err != nil {
+		os.Remove(spoolPath)
+		return "", fmt.Errorf("failed to save upload: %w", err)
+	}
+	return spoolPath, nil
+}
+
+// checkQuota enforces the user's quotas for req, setting remaining-allowance
+// headers. Over a quota it writes a 429 (or 403 for storage) with the
+// details and returns false. Anonymous requests are not limited, and quota
+// lookup failures let the request through.
+func checkQuota(w http.ResponseWriter, userID *int, req quota.Request) bool {
+	if userID == nil {
+		return true
+	}
+	status, err := quota.Check(*userID, req)
+	if status != nil {
+		status.SetHeaders(w.Header())
+	}
+	if err == nil {
+		return true
+	}
+	exceeded, ok := quota.Exceeded(err)
+	if !ok {
+		log.Printf("[Quota] Failed to check quota for user %d: %v", *userID, err)
+		return true
+	}
+	writeQuotaExceeded(w, exceeded)
+	return false
+}
+
+// writeQuotaExceeded writes a 429 (or 403 for storage) with the details of
+// the quota a request would go over
+func writeQuotaExceeded(w http.ResponseWriter, exceeded *quota.ExceededError) {
+	if retryAfter := exceeded.RetryAfter(); retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(exceeded.StatusCode())
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   exceeded.Error(),
+		"code":    "quota_exceeded",
+		"quota":   exceeded,
+	})
+}
+
+// enqueueUserJob queues a job within its user's concurrent job quota. The
+// limit is enforced together with the insert, so requests that passed
+// checkQuota at the same time cannot go over it; over the limit it returns
+// an *quota.ExceededError.
+func enqueueUserJob(jobQueue *jobs.Queue, kind, sessionID string, userID *int, payload interface{}) (*database.Job, error) {
+	limit := 0
+	if userID != nil && database.DB != nil {
+		if status, err := quota.Load(*userID); err != nil {
+			log.Printf("[Quota] Failed to load quota for user %d: %v", *userID, err)
+		} else {
+			limit = status.Limits.ConcurrentJobs
+		}
+	}
+	job, active, err := jobQueue.EnqueueWithin(kind, sessionID, userID, payload, limit)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, quota.JobsExceeded(limit, active, 1)
+	}
+	return job, nil
+}
+
+// checkUploadQuota probes a spooled upload and enforces the user's quotas
+// for processing it. It returns the media duration, or false after writing
+// the error response.
+func checkUploadQuota(w http.ResponseWriter, processor *video.Processor, userID *int, spoolPath string, size int64) (float64, bool) {
+	if userID == nil {
+		return 0, true
+	}
+	duration, err := processor.ProbeDuration(spoolPath)
+	if err != nil {
+		log.Printf("[Quota] Failed to probe %s: %v", filepath.Base(spoolPath), err)
+		duration = 0
+	}
+	return duration, checkQuota(w, userID, quota.Request{AudioSeconds: duration, Jobs: 1, StorageBytes: size})
+}
+
+// startAPIJob spools a file uploaded through the gRPC API and queues it like
+// the HTTP upload handlers; the extension selects video or audio processing
+func startAPIJob(processor *video.Processor, jobQueue *jobs.Queue, user *database.User, upload grpcapi.Upload) (*database.Job, error) {
+	kind := uploadKind(upload.Filename)
+	if kind == "" {
+		return nil, grpcapi.ErrUnsupportedMedia
+	}
+	sessionID := fmt.Sprintf("upload_%d", time.Now().UnixNano())
+	if kind == "audio" {
+		sessionID = fmt.Sprintf("audio_%d", time.Now().UnixNano())
+	}
+
+	spoolPath, err := spoolUpload(processor, sessionID, upload.Filename, upload.Media)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(spoolPath)
+	if err != nil {
+		os.Remove(spoolPath)
+		return nil, err
+	}
+
+	var userID *int
+	if user != nil {
+		userID = &user.ID
+		duration, err := processor.ProbeDuration(spoolPath)
+		if err != nil {
+			log.Printf("[Quota] Failed to probe %s: %v", filepath.Base(spoolPath), err)
+		}
+		if _, err := quota.Check(user.ID, quota.Request{AudioSeconds: duration, Jobs: 1, StorageBytes: info.Size()}); err != nil {
+			if _, exceeded := quota.Exceeded(err); exceeded {
+				os.Remove(spoolPath)
+				return nil, err
+			}
+			log.Printf("[Quota] Failed to check quota for user %d: %v", user.ID, err)
+		}
+	}
+
+	// Defaults match POST /upload and /upload/audio
+	sourceLang, targetLang := upload.SourceLang, upload.TargetLang
+	orgID := flags.SubjectForUser(user).OrgID
+	var payload interface{}
+	jobKind := videoJobKind
+	if kind == "audio" {
+		jobKind = audioJobKind
+		if sourceLang == "" {
+			sourceLang = "auto"
+		}
+		if targetLang == "" {
+			targetLang = "en"
+		}
+		payload = audioJobPayload{
+			FilePath:    spoolPath,
+			Filename:    upload.Filename,
+			Size:        info.Size(),
+			SourceLang:  sourceLang,
+			TargetLang:  targetLang,
+			GenerateTTS: upload.Dub,
+			OrgID:       orgID,
+		}
+	} else {
+		if sourceLang == "" {
+			sourceLang = "en"
+		}
+		if targetLang == "" {
+			targetLang = "ar"
+		}
+		payload = videoJobPayload{
+			FilePath:    spoolPath,
+			Filename:    upload.Filename,
+			Size:        info.Size(),
+			SourceLang:  sourceLang,
+			TargetLang:  targetLang,
+			GenerateTTS: upload.Dub,
+			OrgID:       orgID,
+		}
+	}
+	job, err := enqueueUserJob(jobQueue, jobKind, sessionID, userID, payload)
+	if err != nil {
+		os.Remove(spoolPath)
+		return nil, err
+	}
+	return job, nil
+}
+
+// newVideoJobHandler processes a queued video upload: extract audio,
+// transcribe, translate, optionally dub, and store the results. Failed
+// attempts are retried by the queue; the user is only told about the failure
+// once the last attempt has failed.
+func newVideoJobHandler(processor *video.Processor, asrClient *asr.Client, translator translate.Translator, ttsClient *tts.Client, progressMgr *progress.Manager, minioClient *storage.MinioClient, estimator *estimate.Estimator, jobQueue *jobs.Queue) jobs.Handler {
+	return func(ctx context.Context, job *database.Job) error {
+		var payload videoJobPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return jobs.Permanent(fmt.Errorf("invalid video job payload: %w", err))
+		}
+		// The org's TTS policy filters text and limits voices and cloning
+		ttsClient := ttsClient.WithPolicy(voicepolicy.For(payload.OrgID))
+		if _, err := os.Stat(payload.FilePath); err != nil {
+			queueUploadCallback(jobQueue, payload.CallbackURL, job, uploadCallback{
+				Event:    uploadCallbackFailed,
+				Kind:     "video",
+				Filename: payload.Filename,
+				Stage:    "upload",
+				Error:    "Uploaded file is no longer available",
+			})
+			recordBatchItem(payload.BatchID, job.SessionID, nil, "Uploaded file is no longer available")
+			return jobs.Permanent(fmt.Errorf("spooled upload is missing: %w", err))
+		}
+
+		sessionID := job.SessionID
+		userID := job.UserID
+		sourceLang := payload.SourceLang
+		targetLang := payload.TargetLang
+		autoDetect := sourceLang == "auto" || sourceLang == "detect"
+		generateTTS := payload.GenerateTTS
+		cloneVoice := payload.CloneVoice
+		forceProcessing := payload.ForceProcessing
+		tempVideoPath := payload.FilePath
+		// Diarized cloning dubs segment by segment in each speaker's voice
+		speakerVoices := generateTTS && cloneVoice && payload.Diarization
+
+		tracker := progressMgr.NewTracker(sessionID)
+		tracker.Language = targetLang
+		started := time.Now()
+
+		// Outputs of finished stages are stored so a retry resumes after
+		// them. Queue retries reuse everything stored; a manual retry reuses
+		// the stages before the one it resumes from.
+		stages := processor.JobStages(sessionID)
+		resumeIdx := 0
+		if payload.ResumeFrom != "" {
+			resumeIdx = video.StageIndex(payload.ResumeFrom)
+		} else if job.Attempts > 1 {
+			resumeIdx = len(video.Stages)
+		}
+		var stageState *video.StageState
+		var storedAudio []byte
+		if resumeIdx > 0 {
+			var err error
+			stageState, storedAudio, err = stages.Load()
+			if err != nil {
+				log.Printf("Failed to load stored stages of %s, starting over: %v", sessionID, err)
+				stageState = nil
+			}
+		}
+		reuse := func(stage string) bool {
+			return video.StageIndex(stage) < resumeIdx && stageState.Reached(stage)
+		}
+		saveStage := func(stage string) {
+			stageState.Completed = stage
+			if err := stages.Save(stageState); err != nil {
+				log.Printf("Failed to store %s output of %s: %v", stage, sessionID, err)
+			}
+		}
+
+		fail := func(stage, message string, err error) error {
+			if job.FinalAttempt() {
+				// The upload and stage outputs are kept for
+				// /upload/{sessionId}/retry until the temp janitor sweeps them
+				metrics.UploadsFailed.Inc("video")
+				tracker.Error(stage, message, err)
+				notifyProcessingFailed(userID, "video", payload.Filename, message)
+				recordBatchItem(payload.BatchID, sessionID, nil, message)
+				queueUploadCallback(jobQueue, payload.CallbackURL, job, uploadCallback{
+					Event:    uploadCallbackFailed,
+					Kind:     "video",
+					Filename: payload.Filename,
+					Stage:    stage,
+					Error:    message,
+				})
+			} else {
+				tracker.Updatef(stage, 0, "%s, retrying (attempt %d of %d)", tracker.T(message), job.Attempts, job.MaxAttempts)
+			}
+			return fmt.Errorf("%s: %w", strings.ToLower(message), err)
+		}
+
+		tracker.Updatef("upload", 10, "Received %s (%.2f MB)", payload.Filename, float64(payload.Size)/(1024*1024))
+
+		log.Printf("Processing video: %s (%.2f MB), target language: %s", payload.Filename, float64(payload.Size)/(1024*1024), targetLang)
+
+		tempDir := processor.TempDir
+		var contentHash string
+		if userID != nil {
+			hashValue, err := computeFileHash(tempVideoPath)
+			if err != nil {
+				log.Printf("Failed to hash video: %v", err)
+			} else {
+				contentHash = hashValue
+			}
+		}
+
+		if userID != nil && contentHash != "" && !forceProcessing && resumeIdx == 0 {
+			match, err := database.FindUserFileByHash(*userID, "video", contentHash, dedupSourceLang(sourceLang, autoDetect), targetLang)
+			if err != nil {
+				log.Printf("Failed to lookup video hash: %v", err)
+			} else if match != nil {
+				results := map[string]interface{}{
+					"existing":          true,
+					"existingSessionId": match.SessionID,
+					"existingFileKey":   match.FileKey,
+				}
+				if sessionData, err := database.GetUserVideoSessionBySessionID(*userID, match.SessionID); err != nil {
+					log.Printf("Failed to load existing video session: %v", err)
+				} else if sessionData != nil {
+					results["transcription"] = sessionData.Transcription
+					results["translation"] = sessionData.Translation
+					results["duration"] = float64(sessionData.DurationSeconds)
+					results["minioVideoKey"] = sessionData.VideoPath
+					results["minioAudioKey"] = sessionData.AudioPath
+					results["minioTtsKey"] = sessionData.TTSPath
+				}
+
+				recordBatchItem(payload.BatchID, sessionID, results, "")
+				tracker.CompleteWithResults("Existing upload found", results)
+				queueUploadCallback(jobQueue, payload.CallbackURL, job, uploadCallback{
+					Event:    uploadCallbackCompleted,
+					Kind:     "video",
+					Filename: payload.Filename,
+					Results:  results,
+				})
+				os.Remove(tempVideoPath)
+				return nil
+			}
+		}
+
+		var audioResult *video.ExtractAudioResult
+		var err error
+		if reuse(video.StageExtraction) {
+			audioResult = &video.ExtractAudioResult{AudioData: storedAudio, SampleRate: 16000, Channels: 1, Duration: stageState.Duration}
+			tracker.Updatef("extraction", 35, "Reusing extracted audio: %.2f seconds", audioResult.Duration)
+		} else {
+			tracker.Update("extraction", 25, "Extracting audio from video...")
+
+			// Extract audio
+			log.Println("Extracting audio from video...")
+			audioResult, err = processor.ExtractAudio(tempVideoPath)
+			if err != nil {
+				log.Printf("Error extracting audio: %v", err)
+				return fail("extraction", "Failed to extract audio", err)
+			}
+
+			log.Printf("Audio extracted: %.2f seconds, %d bytes", audioResult.Duration, len(audioResult.AudioData))
+			tracker.Updatef("extraction", 35, "Audio extracted: %.2f seconds", audioResult.Duration)
+			if stageState, err = stages.SaveAudio(audioResult.AudioData, audioResult.Duration); err != nil {
+				log.Printf("Failed to store extracted audio of %s: %v", sessionID, err)
+				stageState = &video.StageState{Completed: video.StageExtraction, Duration: audioResult.Duration}
+			}
+		}
+
+		var detectedLang string
+		var transcription string
+		var speechSegments []asr.Segment
+		if reuse(video.StageTranscription) {
+			transcription, sourceLang, detectedLang = stageState.Transcription, stageState.SourceLang, stageState.DetectedLang
+			if len(stageState.Segments) > 0 {
+				if err := json.Unmarshal(stageState.Segments, &speechSegments); err != nil {
+					log.Printf("Failed to decode stored segments of %s: %v", sessionID, err)
+				}
+			}
+			tracker.Update("transcription", 60, "Reusing transcription")
+		} else {
+			if transcription, speechSegments, sourceLang, detectedLang, err = transcribeVideoAudio(asrClient, tracker, audioResult.AudioData, sourceLang, autoDetect, generateTTS && (payload.SegmentDubbing || speakerVoices), speakerVoices); err != nil {
+				log.Printf("Error transcribing: %v", err)
+				return fail("transcription", "Failed to transcribe audio", err)
+			}
+			transcription = hookedTranscription(hooks.SourceVideo, sessionID, transcription, sourceLang)
+			log.Printf("Transcription: %s", transcription)
+			tracker.Update("transcription", 60, "Transcription complete")
+
+			stageState.SourceLang, stageState.DetectedLang, stageState.Transcription = sourceLang, detectedLang, transcription
+			if len(speechSegments) > 0 {
+				stageState.Segments, _ = json.Marshal(speechSegments)
+			}
+			saveStage(video.StageTranscription)
+		}
+
+		var translation string
+		if reuse(video.StageTranslation) {
+			translation = stageState.Translation
+			tracker.Update("translation", 70, "Reusing translation")
+		} else {
+			// Translate transcription
+			tracker.Updatef("translation", 65, "Translating from %s to %s...", sourceLang, targetLang)
+			log.Printf("Translating from %s to %s...", sourceLang, targetLang)
+			translation, err = hookedTranslation(translator, hooks.SourceVideo, sessionID, transcription, sourceLang, targetLang)
+			if err != nil {
+				log.Printf("Error translating: %v", err)
+				return fail("translation", "Failed to translate", err)
+			}
+
+			log.Printf("Translation: %s", translation)
+			tracker.Update("translation", 70, "Translation complete")
+			stageState.Translation = translation
+			saveStage(video.StageTranslation)
+		}
+
+		// Generate TTS and replace audio if requested
+		var videoPath string
+		var dubProvenance *provenance.Info
+		var spoken string
+		if generateTTS && len(speechSegments) == 0 {
+			spoken = hookedSpeech(hooks.SourceVideo, sessionID, translation, targetLang)
+		}
+		if generateTTS && len(speechSegments) > 0 {
+			// Per-segment dubbing: one clip per ASR segment, placed at its timestamp
+			var speakerRefs map[string][]byte
+			if speakerVoices {
+				speakerRefs = speakerReferences(audioResult.AudioData, speechSegments)
+			}
+			tracker.Updatef("tts", 75, "Generating TTS for %d segments...", len(speechSegments))
+			clips, voice, err := synthesizeDubSegments(translator, ttsClient, speechSegments, sessionID, sourceLang, targetLang, payload.OrgID, cloneVoice, audioResult.AudioData, speakerRefs, tracker)
+			if err != nil {
+				log.Printf("Error generating segment TTS: %v", err)
+				return fail("tts", "Failed to generate TTS", err)
+			}
+			tracker.Update("tts", 85, "TTS generation complete")
+
+			tracker.Update("processing", 90, "Placing dubbed segments in video...")
+			dubProvenance = &provenance.Info{
+				SessionID:  sessionID,
+				SourceLang: sourceLang,
+				TargetLang: targetLang,
+				Voice:      voice,
+				CreatedAt:  time.Now(),
+			}
+			outputVideoPath, err := processor.DubSegments(tempVideoPath, clips, video.ReplaceOptions{
+				MixOriginal: payload.MixOriginal,
+				Metadata:    dubProvenance.Tags(),
+			})
+			if err != nil {
+				log.Printf("Error dubbing segments: %v", err)
+				return fail("processing", "Failed to replace audio", err)
+			}
+
+			videoPath = filepath.Base(outputVideoPath)
+			log.Printf("Video with segment-aligned dub ready: %s", videoPath)
+			tracker.Update("processing", 95, "Video processing complete")
+		} else if spoken != "" {
+			var ttsAudio []byte
+			var err error
+			voice := provenance.VoiceStandard
+
+			// Respell brand and place names so they are pronounced correctly
+			spokenText := lexicon.Apply(payload.OrgID, targetLang, spoken)
+
+			if cloneVoice {
+				// Use voice cloning with original audio as reference
+				tracker.Update("tts", 75, "Generating TTS with voice cloning...")
+				log.Printf("Generating TTS with voice cloning...")
+				ttsAudio, err = ttsClient.SynthesizeWithVoice(spokenText, targetLang, audioResult.AudioData)
+				if err == nil {
+					voice = provenance.VoiceCloned
+				} else {
+					log.Printf("Error with voice cloning, falling back to standard TTS: %v", err)
+					tracker.Update("tts", 75, "Voice cloning failed, using standard TTS...")
+					// Fallback to standard TTS if voice cloning fails
+					ttsAudio, err = ttsClient.Synthesize(spokenText, targetLang)
+					if err != nil {
+						log.Printf("Error generating TTS: %v", err)
+						return fail("tts", "Failed to generate TTS", err)
+					}
+				}
+			} else {
+				// Standard TTS without voice cloning
+				tracker.Update("tts", 75, "Generating TTS audio...")
+				log.Printf("Generating TTS audio for translation...")
+				ttsAudio, err = ttsClient.Synthesize(spokenText, targetLang)
+				if err != nil {
+					log.Printf("Error generating TTS: %v", err)
+					return fail("tts", "Failed to generate TTS", err)
+				}
+			}
+
+			log.Printf("Generated TTS audio: %d bytes", len(ttsAudio))
+			tracker.Update("tts", 85, "TTS generation complete")
+
+			// Replace audio in video, labelling the result as machine-generated
+			tracker.Update("processing", 90, "Replacing audio in video...")
+			log.Println("Replacing audio in video...")
+			dubProvenance = &provenance.Info{
+				SessionID:  sessionID,
+				SourceLang: sourceLang,
+				TargetLang: targetLang,
+				Voice:      voice,
+				CreatedAt:  time.Now(),
+			}
+			replaceOpts := video.ReplaceOptions{
+				Fit:         video.AudioFitLoop,
+				MixOriginal: payload.MixOriginal,
+				Metadata:    dubProvenance.Tags(),
+			}
+			if payload.MatchDuration {
+				replaceOpts.Fit = video.AudioFitStretch
+			}
+			outputVideoPath, err := processor.ReplaceAudio(tempVideoPath, ttsAudio, replaceOpts)
+			if err != nil {
+				log.Printf("Error replacing audio: %v", err)
+				return fail("processing", "Failed to replace audio", err)
+			}
+
+			// Store the path for download (relative to temp dir)
+			videoPath = filepath.Base(outputVideoPath)
+			log.Printf("Video with translated audio ready: %s", videoPath)
+			tracker.Update("processing", 95, "Video processing complete")
+		}
+
+		var minioOriginalKey string
+		var minioAudioKey string
+		var minioTTSKey string
+
+		if minioClient != nil && minioClient.Enabled() {
+			originalKey := storage.SafeObjectKey("videos", sessionID, fmt.Sprintf("original_%s", payload.Filename))
+			etag, size, err := minioClient.UploadFile(ctx, originalKey, tempVideoPath, "")
+			if err != nil {
+				log.Printf("MinIO upload failed (original video): %v", err)
+			} else {
+				minioOriginalKey = originalKey
+				if userID != nil {
+					_, _ = database.CreateUserFile(userID, database.UserFileInput{
+						SessionType:   "video",
+						SessionID:     sessionID,
+						BucketName:    minioClient.Bucket(),
+						FileKey:       originalKey,
+						ContentHash:   contentHash,
+						Etag:          etag,
+						MimeType:      storageDetectContentType(payload.Filename),
+						FileSizeBytes: size,
+					})
+				}
+			}
+
+			audioKey := storage.SafeObjectKey("videos", sessionID, "extracted_audio.wav")
+			etag, size, err = minioClient.UploadBytes(ctx, audioKey, audioResult.AudioData, "audio/wav")
+			if err != nil {
+				log.Printf("MinIO upload failed (extracted audio): %v", err)
+			} else {
+				minioAudioKey = audioKey
+				if userID != nil {
+					_, _ = database.CreateUserFile(userID, database.UserFileInput{
+						SessionType:   "video",
+						SessionID:     sessionID,
+						BucketName:    minioClient.Bucket(),
+						FileKey:       audioKey,
+						Etag:          etag,
+						MimeType:      "audio/wav",
+						FileSizeBytes: size,
+					})
+				}
+			}
+
+			if generateTTS && videoPath != "" && userID != nil {
+				// The key ends in videoPath so /download/{videoPath} can find it
+				// among the owner's files; anonymous outputs stay in the temp dir
+				translatedKey := storage.SafeObjectKey("videos", sessionID, videoPath)
+				etag, size, err = minioClient.UploadFile(ctx, translatedKey, filepath.Join(tempDir, videoPath), "")
+				if err != nil {
+					log.Printf("MinIO upload failed (translated video): %v", err)
+				} else if _, err := database.CreateUserFile(userID, database.UserFileInput{
+					SessionType:   "video",
+					SessionID:     sessionID,
+					BucketName:    minioClient.Bucket(),
+					FileKey:       translatedKey,
+					Etag:          etag,
+					MimeType:      storageDetectContentType(videoPath),
+					FileSizeBytes: size,
+				}); err != nil {
+					log.Printf("Failed to record translated video %s: %v", translatedKey, err)
+				} else {
+					minioTTSKey = translatedKey
+					os.Remove(filepath.Join(tempDir, videoPath))
+				}
+			}
+		}
+
+		// Send completion with results
+		results := map[string]interface{}{
+			"transcription": transcription,
+			"translation":   translation,
+			"duration":      audioResult.Duration,
+			"videoPath":     videoPath,
+			"minioBucket":   "",
+			"minioVideoKey": minioOriginalKey,
+			"minioAudioKey": minioAudioKey,
+			"minioTtsKey":   minioTTSKey,
+		}
+		if minioClient != nil && minioClient.Enabled() {
+			results["minioBucket"] = minioClient.Bucket()
+		}
+		if detectedLang != "" {
+			results["detectedLang"] = detectedLang
+		}
+		if dubProvenance != nil {
+			results["provenance"] = dubProvenance
+		}
+		metrics.UploadDuration.ObserveSince(started, "video")
+		if userID != nil {
+			quota.RecordAudio(*userID, audioResult.Duration)
+		}
+		estimator.Observe(estimate.Request{
+			Kind:            estimate.KindVideo,
+			DurationSeconds: audioResult.Duration,
+			TargetLangs:     []string{targetLang},
+			GenerateTTS:     generateTTS,
+			CloneVoice:      cloneVoice,
+			SegmentDubbing:  payload.SegmentDubbing || speakerVoices,
+			Diarization:     speakerVoices,
+		}, time.Since(started))
+		recordBatchItem(payload.BatchID, sessionID, results, "")
+		tracker.CompleteWithResults("Video processing completed successfully", results)
+		queueUploadCallback(jobQueue, payload.CallbackURL, job, uploadCallback{
+			Event:    uploadCallbackCompleted,
+			Kind:     "video",
+			Filename: payload.Filename,
+			Results:  results,
+		})
+		log.Printf("Video processing completed for session %s", sessionID)
+		os.Remove(tempVideoPath)
+		if err := stages.Remove(); err != nil {
+			log.Printf("Failed to remove stage outputs of %s: %v", sessionID, err)
+		}
+		return nil
+	}
+}
+
+// transcribeVideoAudio detects the language of a video's audio when asked
+// (falling back to English) and transcribes it; segment dubbing also needs
+// the speech timestamps, and diarize labels each segment with its speaker.
+// Returns the transcription, its segments, the source language used and the
+// detected language.
+func transcribeVideoAudio(asrClient *asr.Client, tracker *progress.Tracker, audio []byte, sourceLang string, autoDetect, withSegments, diarize bool) (string, []asr.Segment, string, string, error) {
+	var detectedLang string
+	if autoDetect {
+		tracker.Update("detection", 40, "Detecting language...")
+		log.Println("Auto-detecting language...")
+		detected, err := asrClient.DetectLanguage(audio)
+		if err != nil {
+			log.Printf("Error detecting language: %v, defaulting to 'en'", err)
+			detectedLang = "en"
+			sourceLang = "en" // Update sourceLang for transcription
+			tracker.Update("detection", 45, "Language detection failed, using English")
+		} else {
+			log.Printf("Detected language: %s", detected)
+			detectedLang = detected
+			sourceLang = detected
+			tracker.Updatef("detection", 45, "Detected language: %s", detected)
+		}
+	}
+
+	tracker.Update("transcription", 50, "Transcribing audio...")
+	log.Println("Transcribing audio...")
+	if diarize {
+		diarized, err := asrClient.TranscribeWithDiarization(audio, sourceLang)
+		if err == nil {
+			log.Printf("Diarization complete: %d speakers, %d segments", diarized.NumSpeakers, len(diarized.Segments))
+			return diarized.Text, diarized.TimedSegments(), sourceLang, detectedLang, nil
+		}
+		log.Printf("Error with diarization, falling back to one voice for all speakers: %v", err)
+		tracker.Update("transcription", 50, "Speaker detection failed, transcribing without it...")
+	}
+	if withSegments {
+		timed, err := asrClient.TranscribeWAVSegments(audio, sourceLang)
+		if err != nil {
+			return "", nil, sourceLang, detectedLang, err
+		}
+		return timed.Text, timed.Segments, sourceLang, detectedLang, nil
+	}
+	transcription, err := asrClient.TranscribeWAV(audio, sourceLang)
+	return transcription, nil, sourceLang, detectedLang, err
+}
+
+// synthesizeDubSegments translates and voices each ASR segment separately.
+// Segments that fail are skipped so one bad clip does not sink the dub; it
+// is an error only if no segment could be voiced. A cloned segment uses its
+// speaker's clip from speakerRefs when there is one, else referenceAudio.
+// Returns the voice used (cloned only if every clip was cloned).
+func synthesizeDubSegments(translator translate.Translator, ttsClient *tts.Client, segments []asr.Segment, sessionID, sourceLang, targetLang, orgID string, cloneVoice bool, referenceAudio []byte, speakerRefs map[string][]byte, tracker *progress.Tracker) ([]video.DubSegment, string, error) {
+	voice := provenance.VoiceStandard
+	if cloneVoice {
+		voice = provenance.VoiceCloned
+	}
+
+	var clips []video.DubSegment
+	var lastErr error
+	for i, segment := range segments {
+		text := hookedTranscription(hooks.SourceVideo, sessionID, strings.TrimSpace(segment.Text), sourceLang)
+		if text == "" {
+			continue
+		}
+		translated, err := hookedTranslation(translator, hooks.SourceVideo, sessionID, text, sourceLang, targetLang)
+		if err != nil {
+			log.Printf("Skipping dub segment %d: translation failed: %v", i, err)
+			lastErr = err
+			continue
+		}
+		spoken := hookedSpeech(hooks.SourceVideo, sessionID, translated, targetLang)
+		if spoken == "" {
+			continue
+		}
+		spokenText := lexicon.Apply(orgID, targetLang, spoken)
+
+		var audio []byte
+		if cloneVoice {
+			reference := referenceAudio
+			if clip, ok := speakerRefs[segment.Speaker]; ok {
+				reference = clip
+			}
+			audio, err = ttsClient.SynthesizeWithVoice(spokenText, targetLang, reference)
+			if err != nil {
+				log.Printf("Voice cloning failed for segment %d, using standard TTS: %v", i, err)
+				voice = provenance.VoiceStandard
+			}
+		}
+		if audio == nil {
+			audio, err = ttsClient.Synthesize(spokenText, targetLang)
+			if err != nil {
+				log.Printf("Skipping dub segment %d: TTS failed: %v", i, err)
+				lastErr = err
+				continue
+			}
+		}
+
+		clips = append(clips, video.DubSegment{Start: segment.Start, End: segment.End, Audio: audio})
+		tracker.Updatef("tts", 75+10*float64(i+1)/float64(len(segments)), "Voiced segment %d of %d", i+1, len(segments))
+	}
+
+	if len(clips) == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no speech to dub")
+		}
+		return nil, "", lastErr
+	}
+	return clips, voice, nil
+}
+
+// speakerReferences cuts a voice cloning reference clip per diarized
+// speaker out of the extracted audio. Speakers without a clip are cloned
+// from the whole recording.
+func speakerReferences(audio []byte, segments []asr.Segment) map[string][]byte {
+	spans := make([]video.SpeakerSpan, 0, len(segments))
+	for _, segment := range segments {
+		spans = append(spans, video.SpeakerSpan{Speaker: segment.Speaker, Start: segment.Start, End: segment.End})
+	}
+	references, err := video.SpeakerReferences(audio, spans)
+	if err != nil {
+		log.Printf("Failed to cut speaker reference clips, cloning one voice: %v", err)
+		return nil
+	}
+	log.Printf("Cut voice reference clips for %d speakers", len(references))
+	return references
+}
+
+// handleJobStatus reports a queued job's state:
+//
+//	GET /api/jobs/{id}
+//
+// Jobs submitted by a signed-in user are only visible to that user.
+func handleJobStatus(w http.ResponseWriter, r *http.Request, verifier *auth.KeycloakVerifier) {
+	if r.Method != http.MethodGet {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	jobID, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/jobs/"))
+	if err != nil || jobID <= 0 {
+		sendJSONError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	user, err := maybeAuthenticateUserFromRequest(verifier, r)
+	if err != nil {
+		sendJSONError(w, http.StatusUnauthorized, "Invalid token")
+		return
+	}
+
+	job, err := database.GetJob(jobID)
+	if err != nil {
+		log.Printf("Failed to load job: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load job")
+		return
+	}
+	if job == nil || (job.UserID != nil && (user == nil || user.ID != *job.UserID)) {
+		sendJSONError(w, http.StatusNotFound, "Job not found")
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"success": true,
+		"job":     job,
+	})
+}
+
+// handleProvenanceInspect reads the provenance labels from an uploaded media
+// file so published dubs can be checked for machine-generated content:
+//
+//	POST /api/provenance/inspect   (multipart field "file")
+func handleProvenanceInspect(w http.ResponseWriter, r *http.Request, processor *video.Processor) {
+	if r.Method != http.MethodPost {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if err := r.ParseMultipartForm(500 << 20); err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Failed to parse upload")
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		sendJSONError(w, http.StatusBadRequest, "No file provided")
+		return
+	}
+	defer file.Close()
+
+	tempPath := filepath.Join(processor.TempDir, fmt.Sprintf("inspect_%d_%s", time.Now().UnixNano(), filepath.Base(header.Filename)))
+	defer os.Remove(tempPath)
+	outFile, err := os.Create(tempPath)
+	if err != nil {
+		log.Printf("Error creating temp file: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to read file")
+		return
+	}
+	_, err = io.Copy(outFile, file)
+	outFile.Close()
+	if err != nil {
+		log.Printf("Error copying file: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to read file")
+		return
+	}
+
+	tags, err := processor.ReadMetadata(tempPath)
+	if err != nil {
+		log.Printf("Failed to read media metadata: %v", err)
+		sendJSONError(w, http.StatusBadRequest, "Could not read media metadata")
+		return
+	}
+
+	info, generated := provenance.Parse(tags)
+	response := map[string]interface{}{
+		"success":          true,
+		"machineGenerated": generated,
+	}
+	if generated {
+		response["provenance"] = info
+	}
+	writeJSON(w, response)
+}
+
+// handleVoiceEnrollment manages the signed-in user's voice print, which
+// names them automatically when diarization hears them in a shared room,
+// through any participant's device:
+//
+//	GET    /api/voice-enrollment
+//	POST   /api/voice-enrollment   (multipart field "file", a few seconds of speech; optional "displayName")
+//	DELETE /api/voice-enrollment
+func handleVoiceEnrollment(w http.ResponseWriter, r *http.Request, processor *video.Processor, asrClient *asr.Client, keycloakVerifier *auth.KeycloakVerifier) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		enrollment, err := database.GetVoiceEnrollment(user.ID)
+		if err != nil {
+			log.Printf("Failed to get voice enrollment: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to get voice enrollment")
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"success":    true,
+			"enrolled":   enrollment != nil,
+			"enrollment": enrollment,
+		})
+
+	case http.MethodPost:
+		if err := r.ParseMultipartForm(20 << 20); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Failed to parse upload")
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			sendJSONError(w, http.StatusBadRequest, "No file provided")
+			return
+		}
+		defer file.Close()
+
+		tempPath := filepath.Join(processor.TempDir, fmt.Sprintf("voice_%d_%s", time.Now().UnixNano(), filepath.Base(header.Filename)))
+		defer os.Remove(tempPath)
+		outFile, err := os.Create(tempPath)
+		if err != nil {
+			log.Printf("Error creating temp file: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to read file")
+			return
+		}
+		_, err = io.Copy(outFile, file)
+		outFile.Close()
+		if err != nil {
+			log.Printf("Error copying file: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to read file")
+			return
+		}
+
+		audio, err := processor.ConvertAudioToWAV(tempPath)
+		if err != nil {
+			log.Printf("Failed to convert voice sample: %v", err)
+			sendJSONError(w, http.StatusBadRequest, "Could not read audio sample")
+			return
+		}
+
+		voice, err := asrClient.EmbedSpeaker(audio.AudioData)
+		if err != nil {
+			if errors.Is(err, asr.ErrInvalidSample) {
+				sendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			log.Printf("Failed to embed voice sample: %v", err)
+			sendJSONError(w, http.StatusBadGateway, "Voice enrollment is unavailable")
+			return
+		}
+
+		displayName := strings.TrimSpace(r.FormValue("displayName"))
+		if len(displayName) > 255 {
+			sendJSONError(w, http.StatusBadRequest, "displayName is too long")
+			return
+		}
+
+		enrollment, err := database.SaveVoiceEnrollment(user.ID, voice.Embedding, voice.Duration, displayName)
+		if err != nil {
+			log.Printf("Failed to save voice enrollment: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to save voice enrollment")
+			return
+		}
+		log.Printf("Voice enrolled for user %d (%.1fs sample)", user.ID, voice.Duration)
+
+		writeJSON(w, map[string]interface{}{
+			"success":    true,
+			"enrolled":   true,
+			"enrollment": enrollment,
+		})
+
+	case http.MethodDelete:
+		if _, err := database.DeleteVoiceEnrollment(user.ID); err != nil {
+			log.Printf("Failed to delete voice enrollment: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to delete voice enrollment")
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"success":  true,
+			"enrolled": false,
+		})
+
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleAudioUpload queues an uploaded audio file (MP3, M4A, OGG, WAV...) to
+// be transcribed and translated, reporting progress on the session ID it
+// returns. Form fields: audio (file), sourceLang ("auto" detects),
+// targetLang, enableDiarization, enhanceAudio, generateTTS, cloneVoice, force.
+// Signed-in users get the result saved to their audio history.
+func handleAudioUpload(w http.ResponseWriter, r *http.Request, processor *video.Processor, jobQueue *jobs.Queue, verifier *auth.KeycloakVerifier) {
+	if r.Method != "POST" {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Parse multipart form first (max 100MB)
+	if err := r.ParseMultipartForm(100 << 20); err != nil {
+		log.Printf("Error parsing form: %v", err)
+		json.NewEncoder(w).Encode(videoUploadResponse{
+			Success: false,
+			Error:   "Failed to parse upload",
+		})
+		return
+	}
+
+	file, header, err := r.FormFile("audio")
+	if err != nil {
+		log.Printf("Error getting file: %v", err)
+		json.NewEncoder(w).Encode(videoUploadResponse{
+			Success: false,
+			Error:   "No audio file provided",
+		})
+		return
+	}
+
+	// Generate session ID for progress tracking
+	sessionID := fmt.Sprintf("audio_%d", time.Now().UnixNano())
+
+	// Read form values before queueing the job
+	targetLang := langcode.Normalize(r.FormValue("targetLang"))
+	if targetLang == "" {
+		targetLang = "en" // Default to English
+	}
+
+	sourceLang := langcode.Normalize(r.FormValue("sourceLang"))
+	if sourceLang == "" {
+		sourceLang = "auto" // Default to auto-detect
+	}
+
+	// Check if user wants speaker diarization
+	enableDiarization := r.FormValue("enableDiarization") == "true"
+	enhanceAudio := r.FormValue("enhanceAudio") == "true"
+	forceProcessing := r.FormValue("force") == "true"
+	generateTTS := r.FormValue("generateTTS") == "true"
+	cloneVoice := r.FormValue("cloneVoice") == "true"
+
+	user, err := maybeAuthenticateUserFromRequest(verifier, r)
+	if err != nil {
+		sendJSONError(w, http.StatusUnauthorized, "Invalid token")
+		return
+	}
+	var userID *int
+	if user != nil {
+		userID = &user.ID
+	}
+	cloneVoice = cloneVoice && flags.Enabled(flags.VoiceCloning, flags.SubjectForUser(user))
+
+	// Spool the upload to disk so the job survives a restart
+	spoolPath, err := spoolUpload(processor, sessionID, header.Filename, file)
+	file.Close()
+	if err != nil {
+		log.Printf("Error spooling audio upload: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to save audio")
+		return
+	}
+	if _, ok := checkUploadQuota(w, processor, userID, spoolPath, header.Size); !ok {
+		os.Remove(spoolPath)
+		return
+	}
+
+	job, err := enqueueUserJob(jobQueue, audioJobKind, sessionID, userID, audioJobPayload{
+		FilePath:          spoolPath,
+		Filename:          header.Filename,
+		Size:              header.Size,
+		SourceLang:        sourceLang,
+		TargetLang:        targetLang,
+		EnableDiarization: enableDiarization,
+		EnhanceAudio:      enhanceAudio,
+		ForceProcessing:   forceProcessing,
+		GenerateTTS:       generateTTS,
+		CloneVoice:        cloneVoice,
+		OrgID:             flags.SubjectForUser(user).OrgID,
+	})
+	if exceeded, ok := quota.Exceeded(err); ok {
+		os.Remove(spoolPath)
+		writeQuotaExceeded(w, exceeded)
+		return
+	}
+	if err != nil {
+		os.Remove(spoolPath)
+		log.Printf("Error queueing audio job: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to queue audio processing")
+		return
+	}
+
+	// Send initial response with session and job IDs immediately
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(videoUploadResponse{
+		Success:   true,
+		SessionID: sessionID,
+		JobID:     job.ID,
+	})
+}
+
+// audioJobKind is the job queue kind for uploaded audio processing
+const audioJobKind = "audio_upload"
+
+// audioJobPayload is everything an audio job needs; the upload is spooled to FilePath
+type audioJobPayload struct {
+	FilePath          string `json:"filePath"`
+	Filename          string `json:"filename"`
+	Size              int64  `json:"size"`
+	SourceLang        string `json:"sourceLang"`
+	TargetLang        string `json:"targetLang"`
+	EnableDiarization bool   `json:"enableDiarization"`
+	EnhanceAudio      bool   `json:"enhanceAudio"`
+	ForceProcessing   bool   `json:"force"`
+	GenerateTTS       bool   `json:"generateTTS"`
+	CloneVoice        bool   `json:"cloneVoice"`
+	OrgID             string `json:"orgId,omitempty"`
+	BatchID           string `json:"batchId,omitempty"` // set for files uploaded through /upload/batch
+}
+
+// newAudioJobHandler processes a queued audio upload: convert, transcribe
+// (optionally with diarization), translate, optionally voice the translation,
+// and store the results. Signed-in users get the result saved to their audio
+// history. Failed attempts are retried like video jobs.
+func newAudioJobHandler(processor *video.Processor, asrClient *asr.Client, translator translate.Translator, ttsClient *tts.Client, progressMgr *progress.Manager, minioClient *storage.MinioClient, estimator *estimate.Estimator) jobs.Handler {
+	return func(ctx context.Context, job *database.Job) error {
+		var payload audioJobPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return jobs.Permanent(fmt.Errorf("invalid audio job payload: %w", err))
+		}
+		// The org's TTS policy filters text and limits voices and cloning
+		ttsClient := ttsClient.WithPolicy(voicepolicy.For(payload.OrgID))
+		if _, err := os.Stat(payload.FilePath); err != nil {
+			recordBatchItem(payload.BatchID, job.SessionID, nil, "Uploaded file is no longer available")
+			return jobs.Permanent(fmt.Errorf("spooled upload is missing: %w", err))
+		}
+
+		sessionID := job.SessionID
+		userID := job.UserID
+		sourceLang := payload.SourceLang
+		targetLang := payload.TargetLang
+		autoDetect := sourceLang == "auto" || sourceLang == "detect"
+		enableDiarization := payload.EnableDiarization
+		enhanceAudio := payload.EnhanceAudio
+		forceProcessing := payload.ForceProcessing
+		generateTTS := payload.GenerateTTS
+		cloneVoice := payload.CloneVoice
+		orgID := payload.OrgID
+		tempAudioPath := payload.FilePath
+
+		tracker := progressMgr.NewTracker(sessionID)
+		tracker.Language = targetLang
+		started := time.Now()
+		fail := func(stage, message string, err error) error {
+			if job.FinalAttempt() {
+				// The upload is kept for a requeue from the failure inbox
+				// until the failure is dismissed
+				metrics.UploadsFailed.Inc("audio")
+				tracker.Error(stage, message, err)
+				notifyProcessingFailed(userID, "audio", payload.Filename, message)
+				recordBatchItem(payload.BatchID, sessionID, nil, message)
+			} else {
+				tracker.Updatef(stage, 0, "%s, retrying (attempt %d of %d)", tracker.T(message), job.Attempts, job.MaxAttempts)
+			}
+			return fmt.Errorf("%s: %w", strings.ToLower(message), err)
+		}
+
+		tracker.Updatef("upload", 10, "Received %s (%.2f MB)", payload.Filename, float64(payload.Size)/(1024*1024))
+
+		log.Printf("Processing audio: %s (%.2f MB), source: %s, target: %s", payload.Filename, float64(payload.Size)/(1024*1024), sourceLang, targetLang)
+
+		tempDir := processor.TempDir
+
+		var contentHash string
+		if userID != nil {
+			hashValue, err := computeFileHash(tempAudioPath)
+			if err != nil {
+				log.Printf("Failed to hash audio: %v", err)
+			} else {
+				contentHash = hashValue
+			}
+		}
+
+		if userID != nil && contentHash != "" && !forceProcessing {
+			match, err := database.FindUserFileByHash(*userID, "audio", contentHash, dedupSourceLang(sourceLang, autoDetect), targetLang)
+			if err != nil {
+				log.Printf("Failed to lookup audio hash: %v", err)
+			} else if match != nil {
+				results := map[string]interface{}{
+					"existing":          true,
+					"existingSessionId": match.SessionID,
+					"existingFileKey":   match.FileKey,
+				}
+				if sessionData, err := database.GetUserAudioSessionBySessionID(*userID, match.SessionID); err != nil {
+					log.Printf("Failed to load existing audio session: %v", err)
+				} else if sessionData != nil {
+					results["transcription"] = sessionData.Transcription
+					results["translation"] = sessionData.Translation
+					results["minioAudioKey"] = sessionData.AudioPath
+					results["minioTtsKey"] = sessionData.TTSPath
+					results["num_speakers"] = sessionData.NumSpeakers
+					results["segments"] = sessionData.Segments
+				}
+
+				recordBatchItem(payload.BatchID, sessionID, results, "")
+				tracker.CompleteWithResults("Existing upload found", results)
+				os.Remove(tempAudioPath)
+				return nil
+			}
+		}
+
+		if enhanceAudio {
+			tracker.Update("processing", 30, "Cleaning up audio and converting to WAV...")
+		} else {
+			tracker.Update("processing", 30, "Converting audio to WAV format...")
+		}
+
+		// Convert audio to WAV format
+		log.Println("Converting audio to WAV...")
+		audioResult, err := processor.ConvertAudioToWAVWithEnhancement(tempAudioPath, enhanceAudio)
+		if err != nil && enhanceAudio {
+			log.Printf("Audio enhancement failed, retrying without enhancement: %v", err)
+			audioResult, err = processor.ConvertAudioToWAVWithEnhancement(tempAudioPath, false)
+		}
+		if err != nil {
+			log.Printf("Error converting audio: %v", err)
+			return fail("processing", "Failed to convert audio", err)
+		}
+
+		log.Printf("Audio converted: %.2f seconds, %d bytes", audioResult.Duration, len(audioResult.AudioData))
+		tracker.Updatef("processing", 40, "Audio converted: %.2f seconds", audioResult.Duration)
+
+		// Auto-detect language if requested
+		var detectedLang string
+		if autoDetect {
+			tracker.Update("detection", 45, "Detecting language...")
+			log.Println("Auto-detecting language...")
+			detectedLang, err = asrClient.DetectLanguage(audioResult.AudioData)
+			if err != nil {
+				log.Printf("Error detecting language: %v, defaulting to 'en'", err)
+				detectedLang = "en"
+				sourceLang = "en" // Update sourceLang for transcription
+				tracker.Update("detection", 50, "Language detection failed, using English")
+			} else {
+				log.Printf("Detected language: %s", detectedLang)
+				sourceLang = detectedLang
+				tracker.Updatef("detection", 50, "Detected language: %s", detectedLang)
+			}
+		}
+
+		// Transcribe audio (with or without diarization)
+		tracker.Update("transcription", 60, "Transcribing audio...")
+		log.Println("Transcribing audio...")
+
+		var transcription string
+		var segments []map[string]interface{}
+		var numSpeakers int
+
+		if enableDiarization {
+			// Use diarization endpoint
+			tracker.Update("transcription", 60, "Transcribing with speaker identification...")
+			log.Println("Using speaker diarization...")
+
+			diarizationResult, err := asrClient.TranscribeWithDiarization(audioResult.AudioData, sourceLang)
+			if err != nil {
+				log.Printf("Error with diarization, falling back to normal transcription: %v", err)
+				// Fallback to normal transcription
+				transcription, err = asrClient.TranscribeWAV(audioResult.AudioData, sourceLang)
+				if err != nil {
+					log.Printf("Error transcribing: %v", err)
+					return fail("transcription", "Failed to transcribe audio", err)
+				}
+			} else {
+				transcription = diarizationResult.Text
+				segments = diarizationResult.Segments
+				numSpeakers = diarizationResult.NumSpeakers
+				log.Printf("Diarization complete: %d speakers, %d segments", numSpeakers, len(segments))
+			}
+		} else {
+			// Normal transcription
+			transcription, err = asrClient.TranscribeWAV(audioResult.AudioData, sourceLang)
+			if err != nil {
+				log.Printf("Error transcribing: %v", err)
+				return fail("transcription", "Failed to transcribe audio", err)
+			}
+		}
+
+		transcription = hookedTranscription(hooks.SourceAudio, sessionID, transcription, sourceLang)
+		log.Printf("Transcription: %s", transcription[:min(len(transcription), 100)])
+		tracker.Update("transcription", 75, "Transcription complete")
+
+		// Translate transcription
+		var translation string
+
+		if len(segments) > 0 {
+			// Translate each segment
+			tracker.Updatef("translation", 80, "Translating %d segments...", len(segments))
+			log.Printf("Translating %d segments from %s to %s...", len(segments), sourceLang, targetLang)
+
+			for i, seg := range segments {
+				segText := hookedTranscription(hooks.SourceAudio, sessionID, seg["text"].(string), sourceLang)
+				seg["text"] = segText
+				translatedText, err := hookedTranslation(translator, hooks.SourceAudio, sessionID, segText, sourceLang, targetLang)
+				if err != nil {
+					log.Printf("Error translating segment %d: %v", i, err)
+					translatedText = segText // Fallback to original
+				}
+				seg["translation"] = translatedText
+				segments[i] = seg
+			}
+
+			// Also create full translation
+			translation, _ = hookedTranslation(translator, hooks.SourceAudio, sessionID, transcription, sourceLang, targetLang)
+		} else {
+			// Single translation
+			tracker.Updatef("translation", 80, "Translating from %s to %s...", sourceLang, targetLang)
+			log.Printf("Translating from %s to %s...", sourceLang, targetLang)
+			translation, err = hookedTranslation(translator, hooks.SourceAudio, sessionID, transcription, sourceLang, targetLang)
+			if err != nil {
+				log.Printf("Error translating: %v", err)
+				return fail("translation", "Failed to translate", err)
+			}
+		}
+
+		log.Printf("Translation complete")
+		tracker.Update("translation", 85, "Translation complete")
+
+		// Optionally voice the translation; a TTS failure keeps the text results
+		var ttsPath string
+		var ttsProvenance *provenance.Info
+		var spoken string
+		if generateTTS {
+			spoken = hookedSpeech(hooks.SourceAudio, sessionID, translation, targetLang)
+		}
+		if spoken != "" {
+			spokenText := lexicon.Apply(orgID, targetLang, spoken)
+			voice := provenance.VoiceStandard
+
+			var ttsAudio []byte
+			if cloneVoice {
+				tracker.Update("tts", 88, "Generating TTS with voice cloning...")
+				ttsAudio, err = ttsClient.SynthesizeWithVoice(spokenText, targetLang, audioResult.AudioData)
+				if err == nil {
+					voice = provenance.VoiceCloned
+				} else {
+					log.Printf("Error with voice cloning, falling back to standard TTS: %v", err)
+					tracker.Update("tts", 88, "Voice cloning failed, using standard TTS...")
+					ttsAudio, err = ttsClient.Synthesize(spokenText, targetLang)
+				}
+			} else {
+				tracker.Update("tts", 88, "Generating TTS audio...")
+				ttsAudio, err = ttsClient.Synthesize(spokenText, targetLang)
+			}
+
+			if err != nil {
+				log.Printf("Error generating TTS: %v", err)
+				tracker.Update("tts", 90, "TTS generation failed; returning text only")
+			} else {
+				ttsPath = fmt.Sprintf("translated_%s.wav", sessionID)
+				if err := os.WriteFile(filepath.Join(tempDir, ttsPath), ttsAudio, 0644); err != nil {
+					log.Printf("Error saving TTS audio: %v", err)
+					ttsPath = ""
+				} else {
+					ttsProvenance = &provenance.Info{
+						SessionID:  sessionID,
+						SourceLang: sourceLang,
+						TargetLang: targetLang,
+						Voice:      voice,
+						CreatedAt:  time.Now(),
+					}
+					log.Printf("Generated TTS audio: %d bytes", len(ttsAudio))
+					tracker.Update("tts", 90, "TTS generation complete")
+				}
+			}
+		}
+
+		var minioAudioKey string
+		var minioTTSKey string
+		if minioClient != nil && minioClient.Enabled() {
+			audioKey := storage.SafeObjectKey("audio", sessionID, fmt.Sprintf("original_%s", payload.Filename))
+			etag, size, err := minioClient.UploadFile(ctx, audioKey, tempAudioPath, "")
+			if err != nil {
+				log.Printf("MinIO upload failed (audio): %v", err)
+			} else {
+				minioAudioKey = audioKey
+				if userID != nil {
+					_, _ = database.CreateUserFile(userID, database.UserFileInput{
+						SessionType:   "audio",
+						SessionID:     sessionID,
+						BucketName:    minioClient.Bucket(),
+						FileKey:       audioKey,
+						ContentHash:   contentHash,
+						Etag:          etag,
+						MimeType:      storageDetectContentType(payload.Filename),
+						FileSizeBytes: size,
+					})
+				}
+			}
+
+			if ttsPath != "" && userID != nil {
+				ttsKey := storage.SafeObjectKey("audio", sessionID, ttsPath)
+				etag, size, err := minioClient.UploadFile(ctx, ttsKey, filepath.Join(tempDir, ttsPath), "audio/wav")
+				if err != nil {
+					log.Printf("MinIO upload failed (TTS audio): %v", err)
+				} else if _, err := database.CreateUserFile(userID, database.UserFileInput{
+					SessionType:   "audio",
+					SessionID:     sessionID,
+					BucketName:    minioClient.Bucket(),
+					FileKey:       ttsKey,
+					Etag:          etag,
+					MimeType:      "audio/wav",
+					FileSizeBytes: size,
+				}); err != nil {
+					log.Printf("Failed to record TTS audio %s: %v", ttsKey, err)
+				} else {
+					minioTTSKey = ttsKey
+					os.Remove(filepath.Join(tempDir, ttsPath))
+				}
+			}
+		}
+
+		// Send completion with results
+		results := map[string]interface{}{
+			"transcription": transcription,
+			"translation":   translation,
+			"duration":      audioResult.Duration,
+			"minioBucket":   "",
+			"minioAudioKey": minioAudioKey,
+			"minioTtsKey":   minioTTSKey,
+		}
+		if minioClient != nil && minioClient.Enabled() {
+			results["minioBucket"] = minioClient.Bucket()
+		}
+		if detectedLang != "" {
+			results["detectedLang"] = detectedLang
+		}
+		if len(segments) > 0 {
+			results["segments"] = segments
+			results["num_speakers"] = numSpeakers
+		}
+		if ttsPath != "" {
+			results["ttsPath"] = ttsPath
+			results["provenance"] = ttsProvenance
+		}
+
+		// Save to the user's audio history here so API clients get it too
+		if userID != nil {
+			var segmentsJSON json.RawMessage
+			if len(segments) > 0 {
+				if encoded, err := json.Marshal(segments); err == nil {
+					segmentsJSON = encoded
+				}
+			}
+			historyID, err := database.CreateUserAudioSession(*userID, database.UserAudioSessionInput{
+				SessionID:      sessionID,
+				Filename:       payload.Filename,
+				Transcription:  transcription,
+				Translation:    translation,
+				AudioPath:      minioAudioKey,
+				TTSPath:        minioTTSKey,
+				SourceLang:     sourceLang,
+				TargetLang:     targetLang,
+				HasDiarization: enableDiarization || len(segments) > 0,
+				NumSpeakers:    numSpeakers,
+				Segments:       segmentsJSON,
+			})
+			if err != nil {
+				log.Printf("Failed to save audio history for session %s: %v", sessionID, err)
+			} else {
+				results["historyId"] = historyID
+			}
+		}
+		metrics.UploadDuration.ObserveSince(started, "audio")
+		if userID != nil {
+			quota.RecordAudio(*userID, audioResult.Duration)
+		}
+		estimator.Observe(estimate.Request{
+			Kind:            estimate.KindAudio,
+			DurationSeconds: audioResult.Duration,
+			TargetLangs:     []string{targetLang},
+			Diarization:     enableDiarization,
+			EnhanceAudio:    enhanceAudio,
+			GenerateTTS:     generateTTS,
+			CloneVoice:      cloneVoice,
+		}, time.Since(started))
+		recordBatchItem(payload.BatchID, sessionID, results, "")
+		tracker.CompleteWithResults("Audio processing completed successfully", results)
+		log.Printf("Audio processing completed for session %s", sessionID)
+		os.Remove(tempAudioPath)
+		return nil
+	}
+}
+
+// Extensions accepted by /upload/batch; anything else is skipped
+var (
+	batchVideoExtensions = map[string]bool{".mp4": true, ".mov": true, ".mkv": true, ".webm": true, ".avi": true, ".m4v": true}
+	batchAudioExtensions = map[string]bool{".mp3": true, ".wav": true, ".m4a": true, ".ogg": true, ".flac": true, ".aac": true, ".opus": true}
+)
+
+// uploadKind returns "video" or "audio" from a file's extension, or "" when
+// the file is neither
+func uploadKind(filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	switch {
+	case batchVideoExtensions[ext]:
+		return "video"
+	case batchAudioExtensions[ext]:
+		return "audio"
+	}
+	return ""
+}
+
+// batchFile is a file of a batch upload, spooled for its job
+type batchFile struct {
+	name      string
+	kind      string
+	sessionID string
+	path      string
+	size      int64
+}
+
+// handleBatchUpload queues several video and audio files from one request.
+//
+//	POST /upload/batch  multipart: files (repeatable; .zip archives are expanded),
+//	                    sourceLang ("auto" detects), targetLang, generateTTS, cloneVoice,
+//	                    enableDiarization, enhanceAudio (audio), matchDuration,
+//	                    segmentDubbing, mixOriginal (video), force
+//
+// Every file gets its own upload session and job, exactly as if uploaded
+// alone. The returned batchId is a progress session that aggregates them, and
+// GET /api/batches/{batchId} returns the combined results manifest.
+func handleBatchUpload(w http.ResponseWriter, r *http.Request, processor *video.Processor, jobQueue *jobs.Queue, progressMgr *progress.Manager, verifier *auth.KeycloakVerifier, maxFiles int, maxBytes int64) {
+	if r.Method != http.MethodPost {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		log.Printf("Error parsing batch upload: %v", err)
+		sendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Failed to parse upload (batches are limited to %d MB)", maxBytes>>20))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	user, err := maybeAuthenticateUserFromRequest(verifier, r)
+	if err != nil {
+		sendJSONError(w, http.StatusUnauthorized, "Invalid token")
+		return
+	}
+	var userID *int
+	if user != nil {
+		userID = &user.ID
+	}
+
+	batchID := fmt.Sprintf("batch_%d", time.Now().UnixNano())
+
+	// Spool every file; archives are expanded and unsupported files skipped
+	var files []batchFile
+	var skipped []string
+	cleanup := func() {
+		for _, file := range files {
+			os.Remove(file.path)
+		}
+	}
+	spool := func(name string, size int64, src io.Reader) error {
+		kind := uploadKind(name)
+		if kind == "" {
+			skipped = append(skipped, name)
+			return nil
+		}
+		if len(files) >= maxFiles {
+			return fmt.Errorf("a batch can hold at most %d files", maxFiles)
+		}
+		sessionID := fmt.Sprintf("%s_%d", batchID, len(files)+1)
+		spoolPath, err := spoolUpload(processor, sessionID, name, src)
+		if err != nil {
+			log.Printf("Error spooling batch file %s: %v", name, err)
+			return errors.New("failed to save " + name)
+		}
+		files = append(files, batchFile{name: filepath.Base(name), kind: kind, sessionID: sessionID, path: spoolPath, size: size})
+		return nil
+	}
+
+	for _, header := range r.MultipartForm.File["files"] {
+		file, err := header.Open()
+		if err != nil {
+			cleanup()
+			sendJSONError(w, http.StatusBadRequest, "Failed to read "+header.Filename)
+			return
+		}
+		if strings.EqualFold(filepath.Ext(header.Filename), ".zip") {
+			err = expandBatchArchive(file, header.Size, maxBytes, spool)
+		} else {
+			err = spool(header.Filename, header.Size, file)
+		}
+		file.Close()
+		if err != nil {
+			cleanup()
+			sendJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if len(files) == 0 {
+		sendJSONError(w, http.StatusBadRequest, "No video or audio files provided")
+		return
+	}
+	if userID != nil {
+		// The whole batch counts against the quotas up front
+		request := quota.Request{Jobs: len(files)}
+		for _, file := range files {
+			duration, err := processor.ProbeDuration(file.path)
+			if err != nil {
+				log.Printf("[Quota] Failed to probe batch file %s: %v", file.name, err)
+			}
+			request.AudioSeconds += duration
+			request.StorageBytes += file.size
+		}
+		if !checkQuota(w, userID, request) {
+			cleanup()
+			return
+		}
+	}
+
+	sourceLang := langcode.Normalize(r.FormValue("sourceLang"))
+	if sourceLang == "" {
+		sourceLang = "auto"
+	}
+	targetLang := langcode.Normalize(r.FormValue("targetLang"))
+	if targetLang == "" {
+		targetLang = "en"
+	}
+	generateTTS := r.FormValue("generateTTS") == "true"
+	cloneVoice := r.FormValue("cloneVoice") == "true" && flags.Enabled(flags.VoiceCloning, flags.SubjectForUser(user))
+	forceProcessing := r.FormValue("force") == "true"
+	orgID := flags.SubjectForUser(user).OrgID
+
+	batch := &database.UploadBatch{
+		BatchID:    batchID,
+		UserID:     userID,
+		SourceLang: sourceLang,
+		TargetLang: targetLang,
+	}
+	children := make([]progress.Child, len(files))
+	for i, file := range files {
+		batch.Items = append(batch.Items, database.UploadBatchItem{
+			Position:  i + 1,
+			Filename:  file.name,
+			Kind:      file.kind,
+			SessionID: file.sessionID,
+		})
+		children[i] = progress.Child{SessionID: file.sessionID, Name: file.name}
+	}
+	if err := database.CreateUploadBatch(batch); err != nil {
+		cleanup()
+		log.Printf("Error creating upload batch: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to create batch")
+		return
+	}
+
+	// Subscribe before the jobs can start reporting
+	progressMgr.Aggregate(batchID, children)
+
+	for i, file := range files {
+		var payload interface{}
+		jobKind := videoJobKind
+		if file.kind == "audio" {
+			jobKind = audioJobKind
+			payload = audioJobPayload{
+				FilePath:          file.path,
+				Filename:          file.name,
+				Size:              file.size,
+				SourceLang:        sourceLang,
+				TargetLang:        targetLang,
+				EnableDiarization: r.FormValue("enableDiarization") == "true",
+				EnhanceAudio:      r.FormValue("enhanceAudio") == "true",
+				ForceProcessing:   forceProcessing,
+				GenerateTTS:       generateTTS,
+				CloneVoice:        cloneVoice,
+				OrgID:             orgID,
+				BatchID:           batchID,
+			}
+		} else {
+			payload = videoJobPayload{
+				FilePath:        file.path,
+				Filename:        file.name,
+				Size:            file.size,
+				SourceLang:      sourceLang,
+				TargetLang:      targetLang,
+				GenerateTTS:     generateTTS,
+				CloneVoice:      cloneVoice,
+				ForceProcessing: forceProcessing,
+				MatchDuration:   r.FormValue("matchDuration") == "true",
+				SegmentDubbing:  r.FormValue("segmentDubbing") == "true",
+				MixOriginal:     r.FormValue("mixOriginal") == "true",
+				Diarization:     r.FormValue("enableDiarization") == "true",
+				OrgID:           orgID,
+				BatchID:         batchID,
+			}
+		}
+
+		job, err := enqueueUserJob(jobQueue, jobKind, file.sessionID, userID, payload)
+		if err != nil {
+			// Report the file as failed so the batch can still finish
+			message := "Failed to queue processing"
+			if exceeded, ok := quota.Exceeded(err); ok {
+				message = exceeded.Error()
+			}
+			log.Printf("Error queueing batch file %s: %v", file.name, err)
+			os.Remove(file.path)
+			recordBatchItem(batchID, file.sessionID, nil, message)
+			progressMgr.NewTracker(file.sessionID).Error("upload", message, err)
+			batch.Items[i].Status = database.BatchItemFailed
+			continue
+		}
+		batch.Items[i].JobID = &job.ID
+		if err := database.SetUploadBatchItemJob(batchID, i+1, job.ID); err != nil {
+			log.Printf("Error linking batch file %s to job %d: %v", file.name, job.ID, err)
+		}
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"success":   true,
+		"batchId":   batchID,
+		"sessionId": batchID,
+		"files":     batch.Items,
+		"skipped":   skipped,
+	})
+}
+
+// expandBatchArchive spools the media files of a zip archive. The total
+// uncompressed size is bounded by maxBytes so a small archive cannot expand
+// without limit.
+func expandBatchArchive(file io.ReaderAt, size, maxBytes int64, spool func(name string, size int64, src io.Reader) error) error {
+	archive, err := zip.NewReader(file, size)
+	if err != nil {
+		return errors.New("failed to read zip archive")
+	}
+
+	remaining := maxBytes
+	for _, entry := range archive.File {
+		name := filepath.Base(entry.Name)
+		if entry.FileInfo().IsDir() || strings.HasPrefix(name, ".") || strings.HasPrefix(entry.Name, "__MACOSX/") {
+			continue
+		}
+		if uploadKind(name) == "" {
+			// Only reported as skipped; spool does not read unsupported files
+			if err := spool(name, 0, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		if int64(entry.UncompressedSize64) > remaining {
+			return fmt.Errorf("zip archive expands beyond %d MB", maxBytes>>20)
+		}
+
+		src, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read %s from zip archive", entry.Name)
+		}
+		// The header size is only a claim; the limit holds the actual data to it
+		err = spool(name, int64(entry.UncompressedSize64), io.LimitReader(src, int64(entry.UncompressedSize64)))
+		src.Close()
+		if err != nil {
+			return err
+		}
+		remaining -= int64(entry.UncompressedSize64)
+	}
+	return nil
+}
+
+// recordBatchItem stores a finished upload's outcome on its batch item when
+// the upload came in a batch; failure is empty on success
+func recordBatchItem(batchID, sessionID string, results map[string]interface{}, failure string) {
+	if batchID == "" {
+		return
+	}
+	var err error
+	if failure != "" {
+		err = database.FailUploadBatchItem(sessionID, failure)
+	} else {
+		err = database.CompleteUploadBatchItem(sessionID, results)
+	}
+	if err != nil {
+		log.Printf("Failed to record batch result for session %s: %v", sessionID, err)
+	}
+}
+
+// handleBatchOperations serves a batch's manifest and outputs:
+//
+//	GET /api/batches/{batchId}           combined results manifest
+//	GET /api/batches/{batchId}/download  zip of transcripts, translations and generated media
+//
+// Batches submitted by a signed-in user are only visible to that user.
+func handleBatchOperations(w http.ResponseWriter, r *http.Request, processor *video.Processor, minioClient *storage.MinioClient, verifier *auth.KeycloakVerifier) {
+	if r.Method != http.MethodGet {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	pathParts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/batches/"), "/"), "/")
+	if pathParts[0] == "" || len(pathParts) > 2 || (len(pathParts) == 2 && pathParts[1] != "download") {
+		sendJSONError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	user, err := maybeAuthenticateUserFromRequest(verifier, r)
+	if err != nil {
+		sendJSONError(w, http.StatusUnauthorized, "Invalid token")
+		return
+	}
+	batch, err := database.GetUploadBatch(pathParts[0])
+	if err != nil {
+		log.Printf("Failed to load batch: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load batch")
+		return
+	}
+	if batch == nil || (batch.UserID != nil && (user == nil || user.ID != *batch.UserID)) {
+		sendJSONError(w, http.StatusNotFound, "Batch not found")
+		return
+	}
+
+	manifest := batchManifest(batch)
+	if len(pathParts) == 1 {
+		writeJSON(w, map[string]interface{}{
+			"success": true,
+			"batch":   manifest,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip", batch.BatchID))
+	if err := writeBatchArchive(r.Context(), w, processor, minioClient, batch, manifest); err != nil {
+		// Headers are gone; the client sees a truncated archive
+		log.Printf("Failed to write batch archive %s: %v", batch.BatchID, err)
+	}
+}
+
+// batchManifest summarizes a batch: overall status and progress, counts and
+// every file with its results
+func batchManifest(batch *database.UploadBatch) map[string]interface{} {
+	counts := map[string]int{}
+	for _, item := range batch.Items {
+		counts[item.Status]++
+	}
+	finished := counts[database.BatchItemSucceeded] + counts[database.BatchItemFailed]
+
+	status := "processing"
+	if finished == len(batch.Items) {
+		status = "complete"
+		if counts[database.BatchItemFailed] > 0 {
+			status = "completed_with_errors"
+		}
+	}
+	var progressPercent float64
+	if len(batch.Items) > 0 {
+		progressPercent = math.Round(float64(finished)*1000/float64(len(batch.Items))) / 10
+	}
+
+	return map[string]interface{}{
+		"batchId":    batch.BatchID,
+		"status":     status,
+		"progress":   progressPercent,
+		"total":      len(batch.Items),
+		"succeeded":  counts[database.BatchItemSucceeded],
+		"failed":     counts[database.BatchItemFailed],
+		"running":    counts[database.BatchItemRunning],
+		"queued":     counts[database.BatchItemQueued],
+		"sourceLang": batch.SourceLang,
+		"targetLang": batch.TargetLang,
+		"createdAt":  batch.CreatedAt,
+		"files":      batch.Items,
+	}
+}
+
+// writeBatchArchive writes manifest.json plus a folder per finished file with
+// its transcription, translation and generated media. Media is read from the
+// temp dir while it is still there, otherwise from MinIO.
+func writeBatchArchive(ctx context.Context, w io.Writer, processor *video.Processor, minioClient *storage.MinioClient, batch *database.UploadBatch, manifest map[string]interface{}) error {
+	zw := zip.NewWriter(w)
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := writeZipEntry(zw, "manifest.json", bytes.NewReader(manifestJSON)); err != nil {
+		return err
+	}
+
+	for _, item := range batch.Items {
+		if item.Status != database.BatchItemSucceeded || item.Results == nil {
+			continue
+		}
+		folder := fmt.Sprintf("%02d_%s", item.Position, strings.TrimSuffix(item.Filename, filepath.Ext(item.Filename)))
+		for _, text := range []string{"transcription", "translation"} {
+			if value, _ := item.Results[text].(string); value != "" {
+				if err := writeZipEntry(zw, folder+"/"+text+".txt", strings.NewReader(value)); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Generated media: the dubbed video or the voiced translation
+		localName, _ := item.Results["videoPath"].(string)
+		if item.Kind == "audio" {
+			localName, _ = item.Results["ttsPath"].(string)
+		}
+		objectKey, _ := item.Results["minioTtsKey"].(string)
+		var media io.ReadCloser
+		var mediaName string
+		if localName != "" {
+			if file, err := os.Open(filepath.Join(processor.TempDir, filepath.Base(localName))); err == nil {
+				media, mediaName = file, filepath.Base(localName)
+			}
+		}
+		if media == nil && objectKey != "" && minioClient.Enabled() {
+			if object, err := minioClient.Open(ctx, objectKey); err != nil {
+				log.Printf("Failed to read %s for batch archive: %v", objectKey, err)
+			} else {
+				media, mediaName = object, path.Base(objectKey)
+			}
+		}
+		if media != nil {
+			err := writeZipEntry(zw, folder+"/"+mediaName, media)
+			media.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return zw.Close()
+}
+
+func writeZipEntry(zw *zip.Writer, name string, src io.Reader) error {
+	entry, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := io.Copy(entry, src); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Meeting API Handlers
+
+func handleCreateMeeting(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, defaultMaxDuration int) {
+	if r.Method != "POST" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Method not allowed",
+		})
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		Mode               string `json:"mode"`                         // "individual" or "shared"
+		MaxDurationMinutes int    `json:"maxDurationMinutes,omitempty"` // may shorten, not extend, the org limit
+	}
+
+	// Try to parse JSON, but don't fail if empty (default to individual)
+	json.NewDecoder(r.Body).Decode(&req)
+
+	// Default to individual mode
+	if req.Mode == "" {
+		req.Mode = "individual"
+	}
+
+	// Validate mode
+	if req.Mode != "individual" && req.Mode != "shared" && req.Mode != meeting.ModeInterpreted {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid mode. Must be 'individual', 'shared' or 'interpreted'",
+		})
+		return
+	}
+
+	user, err := maybeAuthenticateUserFromRequest(keycloakVerifier, r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid or expired authentication token",
+		})
+		return
+	}
+	var userID *int
+	if user != nil {
+		userID = &user.ID
+	}
+	// The creator's speech is billed to them, so they need daily audio left
+	if !checkQuota(w, userID, quota.Request{}) {
+		return
+	}
+
+	// Create meeting in database
+	meeting, err := database.CreateMeeting(userID, req.Mode)
+	if err != nil {
+		log.Printf("Error creating meeting: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to create meeting",
+		})
+		return
+	}
+
+	log.Printf("Created meeting: %s (room code: %s, mode: %s)", meeting.ID, meeting.RoomCode, meeting.Mode)
+
+	maxDuration := resolveMeetingMaxDuration(user, req.MaxDurationMinutes, defaultMaxDuration)
+	if maxDuration > 0 {
+		if err := database.SetMeetingMaxDuration(meeting.ID, maxDuration); err != nil {
+			log.Printf("Failed to set duration limit for meeting %s: %v", meeting.ID, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":            true,
+		"meetingId":          meeting.ID,
+		"roomCode":           meeting.RoomCode,
+		"mode":               meeting.Mode,
+		"hostToken":          meeting.HostToken,
+		"maxDurationMinutes": maxDuration,
+	})
+}
+
+// resolveMeetingMaxDuration picks a new meeting's duration limit in minutes:
+// the creator's org limit, else the server default, shortened by the
+// requested limit if that is lower. 0 means unlimited.
+func resolveMeetingMaxDuration(user *database.User, requested, defaultMaxDuration int) int {
+	limit := defaultMaxDuration
+	if orgID := flags.SubjectForUser(user).OrgID; orgID != "" {
+		orgLimit, err := database.GetOrgMeetingMaxDuration(orgID)
+		if err != nil {
+			log.Printf("Failed to load meeting limits for org %s: %v", orgID, err)
+		} else if orgLimit > 0 {
+			limit = orgLimit
+		}
+	}
+	if requested > 0 && (limit <= 0 || requested < limit) {
+		limit = requested
+	}
+	return max(limit, 0)
+}
+
+func handleJoinMeeting(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, keycloakVerifier *auth.KeycloakVerifier) {
+	if r.Method != "POST" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Method not allowed",
+		})
+		return
+	}
+
+	// Extract room code from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 4 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid room code",
+		})
+		return
+	}
+	roomCode := pathParts[3]
+
+	// Parse request body
+	var req struct {
+		ParticipantName string `json:"participantName"`
+		TargetLanguage  string `json:"targetLanguage"`
+		InviteToken     string `json:"inviteToken"` // from a join link
+		HostToken       string `json:"hostToken"`   // skips the waiting room
+		GuestKey        string `json:"guestKey"`    // a guest's browser, from an earlier join
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	// Validate inputs
+	if req.ParticipantName == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Participant name is required",
+		})
+		return
+	}
+	req.TargetLanguage = langcode.Normalize(req.TargetLanguage)
+	if req.TargetLanguage == "" {
+		req.TargetLanguage = "en" // Default to English
+	}
+
+	// Get meeting by room code
+	mtg, err := database.GetMeetingByRoomCode(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to find meeting",
+		})
+		return
+	}
+
+	if mtg == nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Meeting not found",
+		})
+		return
+	}
+
+	if !mtg.IsActive {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Meeting has ended",
+		})
+		return
+	}
+
+	user, err := maybeAuthenticateUserFromRequest(keycloakVerifier, r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid or expired authentication token",
+		})
+		return
+	}
+	var userID *int
+	if user != nil {
+		userID = &user.ID
+	}
+
+	// Guests are told a key for their browser, which a removal is kept
+	// against; users are identified by their account
+	guestKey := ""
+	if userID == nil {
+		guestKey = strings.TrimSpace(req.GuestKey)
+		if guestKey == "" || len(guestKey) > 64 {
+			if guestKey, err = database.NewGuestKey(); err != nil {
+				log.Printf("Error generating guest key: %v", err)
+				sendJSONError(w, http.StatusInternalServerError, "Failed to join meeting")
+				return
+			}
+		}
+	}
+
+	// Moderators keep out participants they removed, and everyone but the
+	// host while the room is locked
+	isHost, _ := database.ValidateMeetingHostToken(mtg.ID, req.HostToken)
+	if !isHost {
+		removed, err := database.IsRemovedFromMeeting(mtg.ID, userID, guestKey)
+		if err != nil {
+			log.Printf("Error checking meeting removal: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to join meeting")
+			return
+		}
+		if removed {
+			sendJSONError(w, http.StatusForbidden, "You were removed from this meeting")
+			return
+		}
+	}
+	if roomManager.IsLocked(mtg.ID) && !isHost {
+		moderator := false
+		if userID != nil {
+			if role, err := database.GetUserMeetingRole(*userID, mtg.ID); err == nil {
+				moderator = database.IsModeratorRole(role)
+			}
+		}
+		if !moderator {
+			sendJSONError(w, http.StatusForbidden, "The meeting is locked")
+			return
+		}
+	}
+
+	// An invite is redeemed on join; invite-only meetings admit only invitees
+	// and users who already have access
+	var invite *database.MeetingInvite
+	if req.InviteToken != "" {
+		invite, err = database.RedeemMeetingInvite(mtg.ID, req.InviteToken)
+		if err != nil {
+			log.Printf("Error redeeming invite: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to check invite")
+			return
+		}
+		if invite == nil {
+			sendJSONError(w, http.StatusForbidden, "Invite is invalid, expired or used up")
+			return
+		}
+	} else {
+		inviteOnly, err := database.IsMeetingInviteOnly(mtg.ID)
+		if err != nil {
+			log.Printf("Error checking invite-only: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to join meeting")
+			return
+		}
+		if inviteOnly {
+			role := ""
+			if userID != nil {
+				if role, err = database.GetUserMeetingRole(*userID, mtg.ID); err != nil {
+					log.Printf("Error getting user role: %v", err)
+				}
+			}
+			if role == "" {
+				sendJSONError(w, http.StatusForbidden, "This meeting requires an invite")
+				return
+			}
+		}
+	}
+
+	// Add participant to database
+	participant, err := database.AddParticipant(mtg.ID, userID, guestKey, req.ParticipantName, req.TargetLanguage)
+	if err != nil {
+		log.Printf("Error adding participant: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to join meeting",
+		})
+		return
+	}
+
+	// With the waiting room on, participants wait for a host to admit them
+	// unless they are the host or an editor
+	waiting, err := database.IsMeetingWaitingRoom(mtg.ID)
+	if err != nil {
+		log.Printf("Error checking waiting room: %v", err)
+	}
+	if waiting {
+		if isHost {
+			waiting = false
+		} else if userID != nil {
+			if invite != nil && invite.Role == database.RoleEditor {
+				waiting = false
+			} else if editor, _ := database.UserHasMinimumRole(*userID, mtg.ID, database.RoleEditor); editor {
+				waiting = false
+			}
+		}
+	}
+	if waiting {
+		if err := database.SetParticipantAdmission(participant.ID, database.AdmissionWaiting); err != nil {
+			log.Printf("Error putting participant %d in the waiting room: %v", participant.ID, err)
+		}
+	}
+
+	// Record invitees in the ACL with the invite's role; other signed-in
+	// participants get viewer access
+	if userID != nil && invite != nil {
+		if err := database.GrantInviteAccess(mtg.ID, *userID, invite); err != nil {
+			log.Printf("Warning: Failed to grant invite access for user %d in meeting %s: %v", *userID, mtg.ID, err)
+		}
+	} else if userID != nil {
+		err = database.AutoGrantViewerAccess(mtg.ID, *userID)
+		if err != nil {
+			// Log error but don't fail the join - they can still participate
+			log.Printf("Warning: Failed to auto-grant viewer access for user %d in meeting %s: %v", *userID, mtg.ID, err)
+		}
+	}
+
+	log.Printf("Participant %d (%s) joined meeting %s", participant.ID, participant.ParticipantName, mtg.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":       true,
+		"participantId":    participant.ID,
+		"participantToken": participant.JoinToken,
+		"guestKey":         guestKey,
+		"meetingId":        mtg.ID,
+		"waiting":          waiting,
+	})
+}
+
+func handleGetMeeting(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager) {
+	if r.Method != "GET" {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Extract room code from URL path: /api/meetings/K1N-G-A
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 4 {
+		sendJSONError(w, http.StatusBadRequest, "Invalid room code")
+		return
+	}
+	roomCode := pathParts[3]
+
+	// Get meeting by room code or ID
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to find meeting",
+		})
+		return
+	}
+
+	if mtg == nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Meeting not found",
+		})
+		return
+	}
+
+	// Get active participants from database
+	participants, err := database.GetActiveParticipants(mtg.ID)
+	if err != nil {
+		log.Printf("Error getting participants: %v", err)
+		participants = []database.MeetingParticipant{} // Return empty array on error
+	}
+
+	// Convert to response format
+	participantList := make([]map[string]interface{}, len(participants))
+	for i, p := range participants {
+		participantList[i] = map[string]interface{}{
+			"id":             p.ID,
+			"name":           p.ParticipantName,
+			"targetLanguage": p.TargetLanguage,
+			"joinedAt":       p.JoinedAt,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":      true,
+		"meetingId":    mtg.ID,
+		"roomCode":     mtg.RoomCode,
+		"mode":         mtg.Mode,
+		"isActive":     mtg.IsActive,
+		"participants": participantList,
+	})
+}
+
+// handleMeetingSpeakers lists a meeting's diarized speakers and renames
+// them. A rename is written back into the stored transcript and RAG chunks,
+// so it also works after the meeting has ended. Listing takes viewer
+// access, renaming editor access; both also accept the host token as a
+// hostToken query parameter.
+//
+//	GET  /api/meetings/{roomCode}/speakers
+//	PUT  /api/meetings/{roomCode}/speakers/{speakerId}   {"speakerName": "..."}  (POST is accepted too)
+func handleMeetingSpeakers(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, keycloakVerifier *auth.KeycloakVerifier, roomCode, speakerID string) {
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	requiredRole := database.RoleViewer
+	switch {
+	case speakerID == "" && r.Method == http.MethodGet:
+	case speakerID != "" && (r.Method == http.MethodPut || r.Method == http.MethodPost):
+		requiredRole = database.RoleEditor
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if hostToken := r.URL.Query().Get("hostToken"); hostToken != "" {
+		valid, err := database.ValidateMeetingHostToken(mtg.ID, hostToken)
+		if err != nil {
+			log.Printf("Failed to validate host token: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to validate host token")
+			return
+		}
+		if !valid {
+			sendJSONError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+	} else {
+		user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+		if !ok {
+			return
+		}
+		allowed, err := database.UserHasMinimumRole(user.ID, mtg.ID, requiredRole)
+		if err != nil {
+			log.Printf("Failed to check meeting role: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+			return
+		}
+		if !allowed {
+			sendJSONError(w, http.StatusForbidden, "Insufficient permissions for meeting speakers")
+			return
+		}
+	}
+
+	if speakerID == "" {
+		speakers, err := database.ListMeetingSpeakers(mtg.ID)
+		if err != nil {
+			log.Printf("Failed to list meeting speakers: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to list speakers")
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"success":   true,
+			"meetingId": mtg.ID,
+			"speakers":  speakers,
+		})
+		return
+	}
+
+	var req struct {
+		SpeakerName string `json:"speakerName"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.SpeakerName = strings.TrimSpace(req.SpeakerName)
+	if req.SpeakerName == "" {
+		sendJSONError(w, http.StatusBadRequest, "Speaker name is required")
+		return
+	}
+	if len(req.SpeakerName) > 255 {
+		sendJSONError(w, http.StatusBadRequest, "Speaker name is too long")
+		return
+	}
+
+	rename, err := roomManager.RenameSpeaker(mtg.ID, speakerID, req.SpeakerName)
+	if err != nil {
+		log.Printf("Error renaming speaker: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to save speaker name")
+		return
+	}
+	log.Printf("Renamed speaker %s in meeting %s to %q (%d transcript entries, %d chunks)",
+		speakerID, mtg.ID, req.SpeakerName, rename.TranscriptEntries, rename.Chunks)
+
+	writeJSON(w, map[string]interface{}{
+		"success":     true,
+		"speakerId":   speakerID,
+		"speakerName": req.SpeakerName,
+		"updated":     rename,
+	})
+}
+
+// handleMeetingCaptionCorrection corrects the text of a finalized caption,
+// identified by the entryId that final transcriptions carry. The corrected
+// text is translated again, written into the stored transcript entry, which
+// keeps the original text, and sent to participants as "caption_updated".
+// Takes editor access or the host token as a hostToken query parameter.
+//
+//	PUT /api/meetings/{roomCode}/transcript-entries/{entryId}   {"text": "..."}  (PATCH is accepted too)
+func handleMeetingCaptionCorrection(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, keycloakVerifier *auth.KeycloakVerifier, roomCode, entryIDParam string) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	entryID, err := strconv.ParseInt(entryIDParam, 10, 64)
+	if err != nil || entryID <= 0 {
+		sendJSONError(w, http.StatusBadRequest, "Invalid entry ID")
+		return
+	}
+
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	var editorID *int
+	if hostToken := r.URL.Query().Get("hostToken"); hostToken != "" {
+		valid, err := database.ValidateMeetingHostToken(mtg.ID, hostToken)
+		if err != nil {
+			log.Printf("Failed to validate host token: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to validate host token")
+			return
+		}
+		if !valid {
+			sendJSONError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+	} else {
+		user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+		if !ok {
+			return
+		}
+		allowed, err := database.UserHasMinimumRole(user.ID, mtg.ID, database.RoleEditor)
+		if err != nil {
+			log.Printf("Failed to check meeting role: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+			return
+		}
+		if !allowed {
+			sendJSONError(w, http.StatusForbidden, "Insufficient permissions to correct captions")
+			return
+		}
+		editorID = &user.ID
+	}
+
+	var req struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.Text = strings.TrimSpace(req.Text)
+	if req.Text == "" {
+		sendJSONError(w, http.StatusBadRequest, "Caption text is required")
+		return
+	}
+	if len(req.Text) > 10000 {
+		sendJSONError(w, http.StatusBadRequest, "Caption text is too long")
+		return
+	}
+
+	entry, err := roomManager.CorrectCaption(mtg.ID, entryID, req.Text, editorID)
+	if errors.Is(err, meeting.ErrCaptionNotFound) {
+		sendJSONError(w, http.StatusNotFound, "Caption not found")
+		return
+	}
+	if errors.Is(err, meeting.ErrCaptionDropped) {
+		sendJSONError(w, http.StatusUnprocessableEntity, "Correction was rejected by a pipeline hook")
+		return
+	}
+	if err != nil {
+		log.Printf("Error correcting caption %d of meeting %s: %v", entryID, mtg.ID, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to correct caption")
+		return
+	}
+	log.Printf("Corrected caption %d of meeting %s", entryID, mtg.ID)
+
+	writeJSON(w, map[string]interface{}{
+		"success": true,
+		"entry":   entry,
+	})
+}
+
+// wantsTranscriptPage reports whether a transcript request asks for a JSON
+// page of persisted entries rather than the plain text download, which
+// needs lang
+func wantsTranscriptPage(r *http.Request) bool {
+	query := r.URL.Query()
+	if query.Get("format") == "json" || query.Get("lang") == "" {
+		return true
+	}
+	for _, name := range []string{"after", "limit", "speaker", "sourceLanguage", "from", "to"} {
+		if query.Has(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func handleDownloadTranscript(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, roomCode string) {
+	if r.Method != "GET" {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	lang := languageParam(r, "lang")
+	if lang == "" {
+		sendJSONError(w, http.StatusBadRequest, "lang is required")
+		return
+	}
+
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	entries := roomManager.GetTranscript(mtg.ID, lang)
+	content := formatTranscript(entries, clientLocation(r))
+
+	filename := fmt.Sprintf("meeting_%s_%s.txt", mtg.RoomCode, lang)
+	if mtg.RoomCode == "" {
+		filename = fmt.Sprintf("meeting_%s_%s.txt", mtg.ID, lang)
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(content)); err != nil {
+		log.Printf("Failed to write transcript response: %v", err)
+	}
+}
+
+func handleDownloadTranscriptSnapshot(w http.ResponseWriter, r *http.Request, roomCode string) {
+	if r.Method != "GET" {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	lang := languageParam(r, "lang")
+	if lang == "" {
+		sendJSONError(w, http.StatusBadRequest, "lang is required")
+		return
+	}
+
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	snapshot, err := database.GetMeetingTranscriptSnapshot(mtg.ID, lang)
+	if err != nil {
+		log.Printf("Failed to get transcript snapshot: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load transcript snapshot")
+		return
+	}
+	if snapshot == nil || snapshot.Transcript == "" {
+		sendJSONError(w, http.StatusNotFound, "Transcript snapshot not found")
+		return
+	}
+
+	filename := fmt.Sprintf("meeting_%s_%s_snapshot.txt", mtg.RoomCode, lang)
+	if mtg.RoomCode == "" {
+		filename = fmt.Sprintf("meeting_%s_%s_snapshot.txt", mtg.ID, lang)
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(snapshot.Transcript)); err != nil {
+		log.Printf("Failed to write transcript snapshot response: %v", err)
+	}
+}
+
+// handleExportTranscript renders a meeting's transcript, with its minutes when
+// available, as a downloadable file. Live meetings export the running
+// transcript; ended meetings export the stored snapshot.
+//
+//	GET /api/meetings/{roomCode}/export?format=txt|docx|pdf&lang=en[&minutes=false][&tz=Europe/Paris]
+func handleExportTranscript(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, keycloakVerifier *auth.KeycloakVerifier, roomCode string) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatTXT
+	}
+	if format != export.FormatTXT && format != export.FormatDOCX && format != export.FormatPDF {
+		sendJSONError(w, http.StatusBadRequest, "format must be txt, docx or pdf")
+		return
+	}
+	lang := languageParam(r, "lang")
+	if lang == "" {
+		sendJSONError(w, http.StatusBadRequest, "lang is required")
+		return
+	}
+
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	userRole, err := database.GetUserMeetingRole(user.ID, mtg.ID)
+	if err != nil {
+		log.Printf("Failed to get user role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+	if userRole == "" {
+		sendJSONError(w, http.StatusForbidden, "You don't have access to this meeting")
+		return
+	}
+
+	var entries []export.Entry
+	if live := roomManager.GetTranscript(mtg.ID, lang); len(live) > 0 {
+		for _, entry := range live {
+			speaker := entry.SpeakerName
+			if speaker == "" {
+				speaker = entry.SpeakerID
+			}
+			entries = append(entries, export.Entry{Timestamp: entry.Timestamp, Speaker: speaker, Text: entry.Text})
+		}
+	} else {
+		snapshot, err := database.GetMeetingTranscriptSnapshot(mtg.ID, lang)
+		if err != nil {
+			log.Printf("Failed to get transcript snapshot: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load transcript")
+			return
+		}
+		if snapshot != nil {
+			entries = export.ParseSnapshot(snapshot.Transcript, mtg.CreatedAt)
+		}
+	}
+
+	var minutes *database.MeetingMinutesContent
+	if r.URL.Query().Get("minutes") != "false" {
+		stored, err := database.GetMeetingMinutes(mtg.ID, lang)
+		if err != nil {
+			log.Printf("Failed to get meeting minutes: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load meeting minutes")
+			return
+		}
+		if stored != nil {
+			minutes = &stored.Content
+		}
+	}
+
+	if len(entries) == 0 && minutes == nil {
+		sendJSONError(w, http.StatusNotFound, "No transcript or minutes found for this language")
+		return
+	}
+
+	name := mtg.RoomCode
+	if name == "" {
+		name = mtg.ID
+	}
+	content, err := export.Render(format, export.Document{
+		Title:     "Meeting " + name,
+		Language:  lang,
+		StartedAt: mtg.CreatedAt,
+		EndedAt:   mtg.EndedAt,
+		Location:  clientLocation(r),
+		Entries:   entries,
+		Minutes:   minutes,
+	})
+	if errors.Is(err, export.ErrUnsupportedScript) {
+		sendJSONError(w, http.StatusUnprocessableEntity, "PDF export cannot draw this language's script; use format=docx or txt")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to render %s export for meeting %s: %v", format, mtg.ID, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to render transcript")
+		return
+	}
+
+	filename := fmt.Sprintf("meeting_%s_%s.%s", name, lang, format)
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(content); err != nil {
+		log.Printf("Failed to write transcript export response: %v", err)
+	}
+}
+
+func handleListTranscriptSnapshots(w http.ResponseWriter, r *http.Request, roomCode string) {
+	if r.Method != "GET" {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	snapshots, err := database.ListMeetingTranscriptSnapshots(mtg.ID)
+	if err != nil {
+		log.Printf("Failed to list transcript snapshots: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to list transcript snapshots")
+		return
+	}
+
+	type snapshotInfo struct {
+		Language  string `json:"language"`
+		CreatedAt string `json:"createdAt"`
+	}
+
+	items := make([]snapshotInfo, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		items = append(items, snapshotInfo{
+			Language:  snapshot.Language,
+			CreatedAt: snapshot.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"snapshots": items,
+	})
+}
+
+// handleMeetingInterpretation returns the parallel transcript of an
+// interpreted meeting: original speech aligned with the interpreter's output
+// and the machine translation. ?lag= shifts the interpreter channel back by
+// that many seconds before aligning (default 3); ?format=jsonl exports the
+// matched pairs as training data, one pair per line.
+func handleMeetingInterpretation(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, roomCode string) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	allowed, err := database.UserHasMinimumRole(user.ID, mtg.ID, database.RoleViewer)
+	if err != nil {
+		log.Printf("Failed to check meeting role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+	if !allowed {
+		sendJSONError(w, http.StatusForbidden, "Insufficient permissions for meeting transcript")
+		return
+	}
+	if mtg.Mode != meeting.ModeInterpreted {
+		sendJSONError(w, http.StatusBadRequest, "Meeting is not in interpreted mode")
+		return
+	}
+
+	lag := 3 * time.Second
+	if value := r.URL.Query().Get("lag"); value != "" {
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || seconds < 0 || seconds > 60 {
+			sendJSONError(w, http.StatusBadRequest, "lag must be between 0 and 60 seconds")
+			return
+		}
+		lag = time.Duration(seconds * float64(time.Second))
+	}
+
+	segments, err := database.ListInterpretationSegments(mtg.ID)
+	if err != nil {
+		log.Printf("Failed to list interpretation segments: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load interpretation transcript")
+		return
+	}
+	report := meeting.AlignInterpretation(segments, lag)
+
+	if r.URL.Query().Get("format") == "jsonl" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"interpretation-%s.jsonl\"", mtg.RoomCode))
+		encoder := json.NewEncoder(w)
+		for _, pair := range report.Pairs {
+			if pair.Original == "" || pair.Interpretation == "" {
+				continue
+			}
+			encoder.Encode(pair)
+		}
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"success": true,
+		"report":  report,
+	})
+}
+
+// handleMeetingChunkAudio redirects to the archived audio a meeting caption
+// was transcribed from, for auditing disputed captions
+func handleMeetingChunkAudio(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, archive *audioarchive.Archive, roomCode, audioRef string) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+	if archive == nil {
+		sendJSONError(w, http.StatusNotFound, "Audio archive is disabled")
+		return
+	}
+	chunkID, err := strconv.ParseInt(audioRef, 10, 64)
+	if err != nil || chunkID <= 0 {
+		sendJSONError(w, http.StatusBadRequest, "Invalid audio reference")
+		return
+	}
+
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil || mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+	allowed, err := database.UserHasMinimumRole(user.ID, mtg.ID, database.RoleViewer)
+	if err != nil {
+		log.Printf("Failed to check meeting role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+	if !allowed {
+		sendJSONError(w, http.StatusForbidden, "Insufficient permissions for meeting audio")
+		return
+	}
+
+	chunk, err := database.GetAudioChunk(chunkID)
+	if err != nil {
+		log.Printf("Failed to load audio chunk %d: %v", chunkID, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load audio")
+		return
+	}
+	if chunk == nil || chunk.SessionType != audioarchive.SessionMeeting || chunk.SessionID != mtg.ID {
+		sendJSONError(w, http.StatusNotFound, "Audio not found or expired")
+		return
+	}
+	serveArchivedChunk(w, r, archive, chunk)
+}
+
+// serveArchivedChunk redirects to a presigned URL for an archived chunk
+func serveArchivedChunk(w http.ResponseWriter, r *http.Request, archive *audioarchive.Archive, chunk *database.AudioChunk) {
+	url, err := archive.URL(r.Context(), chunk)
+	if err != nil {
+		log.Printf("Presign failed for audio chunk %d: %v", chunk.ID, err)
+		sendJSONError(w, http.StatusBadGateway, "Failed to prepare audio")
+		return
+	}
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// handleMeetingTranscriptEntries pages through a meeting's persisted final
+// captions in the order they were broadcast. It works while the meeting is
+// live: poll with after set to the last returned ID. speaker (ID or name),
+// sourceLanguage and from/to (seconds from the meeting start) filter the
+// entries; lang keeps only that language's translation on each entry.
+//
+//	GET /api/meetings/{roomCode}/transcript-entries?after=120&limit=200
+//	GET /api/meetings/{roomCode}/transcript?format=json&speaker=Ana&from=60&to=300&lang=es
+func handleMeetingTranscriptEntries(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, roomCode string) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	allowed, err := database.UserHasMinimumRole(user.ID, mtg.ID, database.RoleViewer)
+	if err != nil {
+		log.Printf("Failed to check meeting role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+	if !allowed {
+		sendJSONError(w, http.StatusForbidden, "Insufficient permissions for meeting transcript")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := database.TranscriptEntryFilter{Limit: 200}
+	if value := query.Get("after"); value != "" {
+		filter.AfterID, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || filter.AfterID < 0 {
+			sendJSONError(w, http.StatusBadRequest, "after must be a non-negative entry ID")
+			return
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil || filter.Limit < 1 || filter.Limit > 1000 {
+			sendJSONError(w, http.StatusBadRequest, "limit must be between 1 and 1000")
+			return
+		}
+	}
+	filter.Speaker = strings.TrimSpace(query.Get("speaker"))
+	filter.Language = languageParam(r, "sourceLanguage")
+	for name, bound := range map[string]**float64{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || seconds < 0 {
+			sendJSONError(w, http.StatusBadRequest, name+" must be a non-negative number of seconds")
+			return
+		}
+		*bound = &seconds
+	}
+	if filter.From != nil && filter.To != nil && *filter.From > *filter.To {
+		sendJSONError(w, http.StatusBadRequest, "from must not be after to")
+		return
+	}
+
+	entries, err := database.ListMeetingTranscriptEntries(mtg.ID, filter)
+	if err != nil {
+		log.Printf("Failed to list transcript entries: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load transcript")
+		return
+	}
+	if lang := languageParam(r, "lang"); lang != "" {
+		for i := range entries {
+			translation, ok := entries[i].Translations[lang]
+			entries[i].Translations = nil
+			if ok {
+				entries[i].Translations = map[string]string{lang: translation}
+			}
+		}
+	}
+
+	lastID := filter.AfterID
+	if len(entries) > 0 {
+		lastID = entries[len(entries)-1].ID
+	}
+	writeJSON(w, map[string]interface{}{
+		"success": true,
+		"entries": entries,
+		"lastId":  lastID,
+		"hasMore": len(entries) == filter.Limit,
+		"live":    mtg.IsActive,
+	})
+}
+
+// handleMeetingActionItems lists the action items extracted from a
+// meeting's minutes and lets editors edit and complete them. Edited and
+// completed items are kept when the minutes are regenerated.
+//
+//	GET   /api/meetings/{roomCode}/action-items?lang=en&status=open
+//	PATCH /api/meetings/{roomCode}/action-items/{id} {"text", "assignee", "dueDate", "status"}
+//	POST  /api/meetings/{roomCode}/action-items/{id}/complete
+func handleMeetingActionItems(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, roomCode string, rest []string) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	requiredRole := database.RoleEditor
+	if r.Method == http.MethodGet {
+		requiredRole = database.RoleViewer
+	}
+	allowed, err := database.UserHasMinimumRole(user.ID, mtg.ID, requiredRole)
+	if err != nil {
+		log.Printf("Failed to check meeting role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+	if !allowed {
+		sendJSONError(w, http.StatusForbidden, "Insufficient permissions for action items")
+		return
+	}
+
+	if len(rest) == 0 || rest[0] == "" {
+		if r.Method != http.MethodGet {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		status := r.URL.Query().Get("status")
+		if status != "" && status != database.ActionItemOpen && status != database.ActionItemDone {
+			sendJSONError(w, http.StatusBadRequest, "status must be open or done")
+			return
+		}
+		items, err := database.ListMeetingActionItems(mtg.ID, languageParam(r, "lang"), status)
+		if err != nil {
+			log.Printf("Failed to list action items: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load action items")
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"success":     true,
+			"actionItems": items,
+		})
+		return
+	}
+
+	itemID, err := strconv.Atoi(rest[0])
+	if err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Invalid action item ID")
+		return
+	}
+
+	var update database.ActionItemUpdate
+	switch {
+	case len(rest) == 1 && r.Method == http.MethodPatch:
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	case len(rest) == 2 && rest[1] == "complete" && r.Method == http.MethodPost:
+		done := database.ActionItemDone
+		update.Status = &done
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	item, err := database.UpdateMeetingActionItem(mtg.ID, itemID, update, user.ID)
+	if errors.Is(err, database.ErrInvalidActionItem) {
+		sendJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to update action item %d: %v", itemID, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to update action item")
+		return
+	}
+	if item == nil {
+		sendJSONError(w, http.StatusNotFound, "Action item not found")
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"success":    true,
+		"actionItem": item,
+	})
+}
+
+// handleMeetingRecordings lists a meeting's recordings, with presigned links
+// to the audio and caption transcript of finished ones, and lets the owner
+// start and stop recording the live room. Start and stop take the owner's
+// login or the host token as a hostToken query parameter.
+//
+//	GET  /api/meetings/{roomCode}/recordings
+//	POST /api/meetings/{roomCode}/recordings/start
+//	POST /api/meetings/{roomCode}/recordings/stop
+func handleMeetingRecordings(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, keycloakVerifier *auth.KeycloakVerifier, roomCode, action string) {
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	if action == "" {
+		if r.Method != http.MethodGet {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+		if !ok {
+			return
+		}
+		allowed, err := database.UserHasMinimumRole(user.ID, mtg.ID, database.RoleViewer)
+		if err != nil {
+			log.Printf("Failed to check meeting role: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+			return
+		}
+		if !allowed {
+			sendJSONError(w, http.StatusForbidden, "Insufficient permissions for meeting recordings")
+			return
+		}
+
+		recordings, err := database.ListMeetingRecordings(mtg.ID)
+		if err != nil {
+			log.Printf("Failed to list meeting recordings: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to list recordings")
+			return
+		}
+		type recordingResponse struct {
+			database.MeetingRecording
+			AudioURL      string `json:"audioUrl,omitempty"`
+			TranscriptURL string `json:"transcriptUrl,omitempty"`
+		}
+		response := make([]recordingResponse, 0, len(recordings))
+		for _, recording := range recordings {
+			item := recordingResponse{MeetingRecording: recording}
+			if recording.Status == database.RecordingReady {
+				item.AudioURL, item.TranscriptURL, err = meeting.RecordingURLs(r.Context(), &recording)
+				if err != nil {
+					log.Printf("Presign failed for meeting recording %d: %v", recording.ID, err)
+				}
+			}
+			response = append(response, item)
+		}
+		writeJSON(w, map[string]interface{}{
+			"meetingId":  mtg.ID,
+			"recording":  roomManager.IsRecording(mtg.ID),
+			"recordings": response,
+		})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if action != "start" && action != "stop" {
+		sendJSONError(w, http.StatusNotFound, "Unknown recording action")
+		return
+	}
+
+	var userID *int
+	if hostToken := r.URL.Query().Get("hostToken"); hostToken != "" {
+		valid, err := database.ValidateMeetingHostToken(mtg.ID, hostToken)
+		if err != nil {
+			log.Printf("Failed to validate host token: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to validate host token")
+			return
+		}
+		if !valid {
+			sendJSONError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+	} else {
+		user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+		if !ok {
+			return
+		}
+		userRole, err := database.GetUserMeetingRole(user.ID, mtg.ID)
+		if err != nil {
+			log.Printf("Failed to get user role: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+			return
+		}
+		if !database.IsModeratorRole(userRole) {
+			sendJSONError(w, http.StatusForbidden, "Only meeting owners or co-hosts can record meetings")
+			return
+		}
+		userID = &user.ID
+	}
+
+	if action == "stop" {
+		recordingID, err := roomManager.StopRecording(mtg.ID)
+		if err != nil {
+			sendJSONError(w, http.StatusConflict, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"recordingId": recordingID,
+			"status":      database.RecordingProcessing,
+		})
+		return
+	}
+
+	recording, err := roomManager.StartRecording(mtg.ID, userID)
+	switch {
+	case errors.Is(err, meeting.ErrRecordingDisabled):
+		sendJSONError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case errors.Is(err, meeting.ErrRecordingActive), errors.Is(err, meeting.ErrRoomNotActive):
+		sendJSONError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		log.Printf("Failed to start recording meeting %s: %v", mtg.ID, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to start recording")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(recording)
+}
+
+// handleMeetingEvents returns a meeting's broadcast log in sequence order for
+// catch-up, replay and debugging. Captions of speakers who did not consent
+// to recording appear without text.
+//
+//	GET /api/meetings/{roomCode}/events?since=120&type=transcription,error&limit=500
+func handleMeetingEvents(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, roomCode string) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	allowed, err := database.UserHasMinimumRole(user.ID, mtg.ID, database.RoleViewer)
+	if err != nil {
+		log.Printf("Failed to check meeting role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+	if !allowed {
+		sendJSONError(w, http.StatusForbidden, "Insufficient permissions for meeting events")
+		return
+	}
+
+	query := r.URL.Query()
+	var sinceSeq int64
+	if value := query.Get("since"); value != "" {
+		sinceSeq, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || sinceSeq < 0 {
+			sendJSONError(w, http.StatusBadRequest, "since must be a non-negative sequence number")
+			return
+		}
+	}
+	limit := 500
+	if value := query.Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > 5000 {
+			sendJSONError(w, http.StatusBadRequest, "limit must be between 1 and 5000")
+			return
+		}
+	}
+	var types []string
+	for _, eventType := range strings.Split(query.Get("type"), ",") {
+		if eventType = strings.TrimSpace(eventType); eventType != "" {
+			types = append(types, eventType)
+		}
+	}
+
+	events, err := database.ListMeetingEvents(mtg.ID, sinceSeq, types, limit)
+	if err != nil {
+		log.Printf("Failed to list meeting events: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load meeting events")
+		return
+	}
+	counts, err := database.GetMeetingEventCounts(mtg.ID)
+	if err != nil {
+		log.Printf("Failed to count meeting events: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load meeting events")
+		return
+	}
+
+	lastSeq := sinceSeq
+	if len(events) > 0 {
+		lastSeq = events[len(events)-1].Seq
+	}
+	writeJSON(w, map[string]interface{}{
+		"success": true,
+		"events":  events,
+		"lastSeq": lastSeq,
+		"hasMore": len(events) == limit,
+		"counts":  counts,
+	})
+}
+
+// handleMeetingConsent reports each participant's recording consent to the
+// meeting owner. The host may authenticate with their host token instead.
+func handleMeetingConsent(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, keycloakVerifier *auth.KeycloakVerifier, roomCode string) {
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	if hostToken := r.URL.Query().Get("hostToken"); hostToken != "" {
+		valid, err := database.ValidateMeetingHostToken(mtg.ID, hostToken)
+		if err != nil {
+			log.Printf("Failed to validate host token: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to validate host token")
+			return
+		}
+		if !valid {
+			sendJSONError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+	} else {
+		user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+		if !ok {
+			return
+		}
+		userRole, err := database.GetUserMeetingRole(user.ID, mtg.ID)
+		if err != nil {
+			log.Printf("Failed to get user role: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+			return
+		}
+		if !database.IsModeratorRole(userRole) {
+			sendJSONError(w, http.StatusForbidden, "Only meeting owners or co-hosts can view consent status")
+			return
+		}
+	}
+
+	statuses, err := roomManager.GetConsentStatus(mtg.ID)
+	if err != nil {
+		log.Printf("Failed to load consent status: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load consent status")
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"success":      true,
+		"participants": statuses,
+	})
+}
+
+func handleEndMeeting(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, llmClient *llm.Client, roomCode string) {
+	if r.Method != http.MethodPost {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req struct {
+		HostToken string `json:"hostToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.HostToken == "" {
+		sendJSONError(w, http.StatusBadRequest, "Host token required")
+		return
+	}
+
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	valid, err := database.ValidateMeetingHostToken(mtg.ID, req.HostToken)
+	if err != nil {
+		log.Printf("Failed to validate host token: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to validate host token")
+		return
+	}
+	if !valid {
+		sendJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := roomManager.EndMeeting(mtg.ID); err != nil {
+		log.Printf("Failed to end meeting: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to end meeting")
+		return
+	}
+
+	go finalizeMeeting(mtg, llmClient)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
+
+// finalizeMeeting runs the post-meeting work for an ended meeting: minutes
+// generation and the owner's notification
+func finalizeMeeting(mtg *database.Meeting, llmClient *llm.Client) {
+	if llmClient == nil {
+		return
+	}
+	if err := meeting.GenerateMeetingMinutes(mtg.ID, "en", llmClient); err != nil {
+		log.Printf("Minutes generation failed for meeting %s: %v", mtg.ID, err)
+		failures.Record(failures.Failure{
+			Source:    database.FailureSourceMinutes,
+			Kind:      meetingMinutesJobKind,
+			Err:       err,
+			Context:   meetingMinutesJobPayload{MeetingID: mtg.ID, Language: "en"},
+			MeetingID: mtg.ID,
+			UserID:    mtg.CreatedBy,
+		})
+		if mtg.CreatedBy != nil {
+			notify.Send(*mtg.CreatedBy, notify.TypeProcessingFailed, "Minutes generation failed",
+				fmt.Sprintf("Minutes for meeting %s could not be generated", mtg.RoomCode),
+				meetingDetailLink(mtg.ID), map[string]interface{}{"meetingId": mtg.ID})
+		}
+		return
+	}
+	notifyMinutesReady(mtg)
+}
+
+// meetingMinutesJobKind is the job queue kind for regenerating a meeting's
+// minutes, queued when a minutes failure is requeued from the inbox
+const meetingMinutesJobKind = "meeting_minutes"
+
+type meetingMinutesJobPayload struct {
+	MeetingID string `json:"meetingId"`
+	Language  string `json:"language"`
+}
+
+// newMeetingMinutesJobHandler generates a meeting's minutes and notifies its
+// members when they are ready
+func newMeetingMinutesJobHandler(llmClient *llm.Client) jobs.Handler {
+	return func(ctx context.Context, job *database.Job) error {
+		var payload meetingMinutesJobPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil || payload.MeetingID == "" {
+			return jobs.Permanent(fmt.Errorf("invalid minutes job payload: %v", err))
+		}
+		if llmClient == nil {
+			return jobs.Permanent(fmt.Errorf("LLM is not configured"))
+		}
+		mtg, err := database.GetMeetingByID(payload.MeetingID)
+		if err != nil {
+			return err
+		}
+		if mtg == nil {
+			return jobs.Permanent(fmt.Errorf("meeting %s not found", payload.MeetingID))
+		}
+
+		language := langcode.Normalize(payload.Language)
+		if language == "" {
+			language = "en"
+		}
+		if err := meeting.GenerateMeetingMinutes(mtg.ID, language, llmClient); err != nil {
+			return err
+		}
+		notifyMinutesReady(mtg)
+		return nil
+	}
+}
+
+// meetingReprocessJobKind is the job queue kind for rebuilding a meeting's
+// chunks, embeddings and minutes from its transcript snapshots
+const meetingReprocessJobKind = "meeting_reprocess"
+
+type meetingReprocessJobPayload struct {
+	MeetingID string `json:"meetingId"`
+}
+
+// newMeetingReprocessJobHandler rebuilds a meeting's knowledge artifacts,
+// reporting progress on the job's session and storing the before/after
+// summary as the job result
+func newMeetingReprocessJobHandler(ragProcessor *rag.Processor, llmClient *llm.Client, progressMgr *progress.Manager) jobs.Handler {
+	return func(ctx context.Context, job *database.Job) error {
+		var payload meetingReprocessJobPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil || payload.MeetingID == "" {
+			return jobs.Permanent(fmt.Errorf("invalid reprocess job payload: %v", err))
+		}
+
+		tracker := progressMgr.NewTracker(job.SessionID)
+		summary, err := meeting.ReprocessKnowledge(payload.MeetingID, ragProcessor, llmClient, tracker)
+		if err != nil {
+			tracker.Error("reprocess", "Reprocessing failed", err)
+			return err
+		}
+		if err := database.SetJobResult(job.ID, summary); err != nil {
+			log.Printf("Failed to store reprocess summary for job %d: %v", job.ID, err)
+		}
+		log.Printf("Reprocessed knowledge of meeting %s (%d errors)", payload.MeetingID, len(summary.Errors))
+		tracker.CompleteWithResults("Reprocessing complete", map[string]interface{}{
+			"jobId":   job.ID,
+			"summary": summary,
+		})
+		return nil
+	}
+}
+
+// handleReprocessMeeting queues a rebuild of a meeting's chunks, embeddings
+// and minutes. Meeting owners use the meeting route; the admin route is
+// localhost only. Progress streams on /ws/progress/{sessionId} and the
+// summary is the job's result (GET /api/jobs/{jobId}). Only one rebuild per
+// meeting runs at a time.
+//
+//	POST /api/meetings/{roomCode}/reprocess
+//	POST /api/admin/meetings/{meetingId}/reprocess
+func handleReprocessMeeting(w http.ResponseWriter, r *http.Request, jobQueue *jobs.Queue, keycloakVerifier *auth.KeycloakVerifier, roomCode string, admin bool) {
+	if r.Method != http.MethodPost {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	var userID *int
+	if !admin {
+		user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+		if !ok {
+			return
+		}
+		userRole, err := database.GetUserMeetingRole(user.ID, mtg.ID)
+		if err != nil {
+			log.Printf("Failed to get user role: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+			return
+		}
+		if !database.IsModeratorRole(userRole) {
+			sendJSONError(w, http.StatusForbidden, "Only meeting owners or co-hosts can reprocess a meeting")
+			return
+		}
+		userID = &user.ID
+	}
+
+	sessionID := "reprocess_" + mtg.ID
+	active, err := database.ListActiveJobs([]string{meetingReprocessJobKind})
+	if err != nil {
+		log.Printf("Failed to list reprocess jobs: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to queue reprocessing")
+		return
+	}
+	for _, job := range active {
+		if job.SessionID == sessionID {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success":   false,
+				"error":     "Meeting is already being reprocessed",
+				"jobId":     job.ID,
+				"sessionId": sessionID,
+			})
+			return
+		}
+	}
+
+	job, err := jobQueue.Enqueue(meetingReprocessJobKind, sessionID, userID, meetingReprocessJobPayload{MeetingID: mtg.ID})
+	if err != nil {
+		log.Printf("Failed to queue reprocessing of meeting %s: %v", mtg.ID, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to queue reprocessing")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"jobId":     job.ID,
+		"sessionId": sessionID,
+	})
+}
+
+// handleAdminMeetings routes the localhost-only meeting admin actions
+func handleAdminMeetings(w http.ResponseWriter, r *http.Request, jobQueue *jobs.Queue) {
+	if !isLocalRequest(r) {
+		sendJSONError(w, http.StatusForbidden, "Admin API available only on localhost")
+		return
+	}
+
+	// /api/admin/meetings/{meetingId}/reprocess
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/admin/meetings/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "reprocess" {
+		sendJSONError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	handleReprocessMeeting(w, r, jobQueue, nil, parts[0], true)
+}
+
+// handleAdminFailures serves the localhost-only failure inbox: terminal
+// failures of jobs, chunk embeddings and minutes, most urgent first.
+// Requeues may override the retried job's payload fields with parameters;
+// a null parameter removes the field.
+//
+//	GET  /api/admin/failures?status=open&source=&kind=&code=&meetingId=&limit=
+//	POST /api/admin/failures/{id}/requeue   {"parameters": {...}}
+//	POST /api/admin/failures/{id}/dismiss
+//	POST /api/admin/failures/requeue        {"ids": [...]} or {"source", "kind", "code"}, plus "parameters"
+func handleAdminFailures(w http.ResponseWriter, r *http.Request, jobQueue *jobs.Queue) {
+	if !isLocalRequest(r) {
+		sendJSONError(w, http.StatusForbidden, "Admin API available only on localhost")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/failures"), "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "":
+		if r.Method != http.MethodGet {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		listAdminFailures(w, r)
+	case len(parts) == 1 && parts[0] == "requeue":
+		if r.Method != http.MethodPost {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		bulkRequeueFailures(w, r, jobQueue)
+	case len(parts) == 2 && (parts[1] == "requeue" || parts[1] == "dismiss"):
+		if r.Method != http.MethodPost {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		id, err := strconv.Atoi(parts[0])
+		if err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid failure ID")
+			return
+		}
+		if parts[1] == "dismiss" {
+			dismissFailure(w, id)
+			return
+		}
+		requeueSingleFailure(w, r, jobQueue, id)
+	default:
+		sendJSONError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// failureFilterFromQuery reads the inbox filters; status defaults to open
+func failureFilterFromQuery(r *http.Request) database.FailureFilter {
+	query := r.URL.Query()
+	filter := database.FailureFilter{
+		Status:    query.Get("status"),
+		Source:    query.Get("source"),
+		Kind:      query.Get("kind"),
+		ErrorCode: query.Get("code"),
+		MeetingID: query.Get("meetingId"),
+		Limit:     100,
+	}
+	if filter.Status == "" {
+		filter.Status = database.FailureOpen
+	} else if filter.Status == "all" {
+		filter.Status = ""
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 {
+		filter.Limit = min(limit, 500)
+	}
+	return filter
+}
+
+func listAdminFailures(w http.ResponseWriter, r *http.Request) {
+	list, err := database.ListProcessingFailures(failureFilterFromQuery(r))
+	if err != nil {
+		log.Printf("Failed to list processing failures: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to list failures")
+		return
+	}
+	if list == nil {
+		list = []database.ProcessingFailure{}
+	}
+	counts, err := database.CountOpenFailures()
+	if err != nil {
+		log.Printf("Failed to count open failures: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to list failures")
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"success":  true,
+		"failures": list,
+		"open":     counts,
+	})
+}
+
+// dismissFailure closes an open failure for good; a failed upload's spooled
+// input, kept for a requeue, is deleted with it
+func dismissFailure(w http.ResponseWriter, id int) {
+	failure, err := database.GetProcessingFailure(id)
+	if err != nil {
+		log.Printf("Failed to get failure %d: %v", id, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to dismiss failure")
+		return
+	}
+	dismissed, err := database.ResolveProcessingFailure(id, database.FailureDismissed, nil)
+	if err != nil {
+		log.Printf("Failed to dismiss failure %d: %v", id, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to dismiss failure")
+		return
+	}
+	if !dismissed {
+		sendJSONError(w, http.StatusConflict, "Failure is not open")
+		return
+	}
+	if failure != nil && failure.Source == database.FailureSourceJob &&
+		(failure.Kind == videoJobKind || failure.Kind == audioJobKind) {
+		if path := spooledUpload(failure.Context); path != "" {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				log.Printf("Failed to remove upload of dismissed failure %d: %v", id, err)
+			}
+		}
+	}
+	writeJSON(w, map[string]interface{}{"success": true, "id": id})
+}
+
+type failureRequeueRequest struct {
+	IDs        []int                      `json:"ids"`
+	Source     string                     `json:"source"`
+	Kind       string                     `json:"kind"`
+	Code       string                     `json:"code"`
+	Limit      int                        `json:"limit"`
+	Parameters map[string]json.RawMessage `json:"parameters"`
+}
+
+// decodeFailureRequeue reads an optional requeue body; an empty body
+// requeues with the original parameters
+func decodeFailureRequeue(r *http.Request) (failureRequeueRequest, error) {
+	var req failureRequeueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		return req, err
+	}
+	return req, nil
+}
+
+func requeueSingleFailure(w http.ResponseWriter, r *http.Request, jobQueue *jobs.Queue, id int) {
+	req, err := decodeFailureRequeue(r)
+	if err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	failure, err := database.GetProcessingFailure(id)
+	if err != nil {
+		log.Printf("Failed to get failure %d: %v", id, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to get failure")
+		return
+	}
+	if failure == nil {
+		sendJSONError(w, http.StatusNotFound, "Failure not found")
+		return
+	}
+
+	job, err := requeueFailure(failure, req.Parameters, jobQueue)
+	if err != nil {
+		log.Printf("Failed to requeue failure %d: %v", id, err)
+		sendJSONError(w, http.StatusConflict, err.Error())
+		return
+	}
+	result := map[string]interface{}{"success": true, "id": id}
+	if job != nil {
+		result["jobId"] = job.ID
+	}
+	writeJSON(w, result)
+}
+
+// bulkRequeueFailures requeues the listed open failures, or those matching
+// source, kind and code, applying the same parameters to each
+func bulkRequeueFailures(w http.ResponseWriter, r *http.Request, jobQueue *jobs.Queue) {
+	req, err := decodeFailureRequeue(r)
+	if err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(req.IDs) == 0 && req.Source == "" && req.Kind == "" && req.Code == "" {
+		sendJSONError(w, http.StatusBadRequest, "ids or a source, kind or code filter is required")
+		return
+	}
+	filter := database.FailureFilter{
+		Status:    database.FailureOpen,
+		Source:    req.Source,
+		Kind:      req.Kind,
+		ErrorCode: req.Code,
+		IDs:       req.IDs,
+		Limit:     100,
+	}
+	if req.Limit > 0 {
+		filter.Limit = min(req.Limit, 500)
+	}
+	list, err := database.ListProcessingFailures(filter)
+	if err != nil {
+		log.Printf("Failed to list processing failures: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to list failures")
+		return
+	}
+
+	type outcome struct {
+		ID    int    `json:"id"`
+		JobID int    `json:"jobId,omitempty"`
+		Error string `json:"error,omitempty"`
+	}
+	outcomes := make([]outcome, 0, len(list))
+	requeued := 0
+	for i := range list {
+		result := outcome{ID: list[i].ID}
+		job, err := requeueFailure(&list[i], req.Parameters, jobQueue)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			requeued++
+			if job != nil {
+				result.JobID = job.ID
+			}
+		}
+		outcomes = append(outcomes, result)
+	}
+	log.Printf("Requeued %d of %d failures", requeued, len(list))
+
+	writeJSON(w, map[string]interface{}{
+		"success":  true,
+		"requeued": requeued,
+		"results":  outcomes,
+	})
+}
+
+// requeueFailure retries a failure: jobs and minutes are queued again with
+// their recorded payload merged with parameters, failed chunks get a fresh
+// embedding retry budget. The failure is claimed first so concurrent
+// requeues cannot run it twice; it is reopened if the retry cannot be queued.
+func requeueFailure(failure *database.ProcessingFailure, parameters map[string]json.RawMessage, jobQueue *jobs.Queue) (*database.Job, error) {
+	if failure.Source == database.FailureSourceChunk && len(parameters) > 0 {
+		return nil, fmt.Errorf("chunk failures take no parameters")
+	}
+	claimed, err := database.ResolveProcessingFailure(failure.ID, database.FailureRequeued, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, fmt.Errorf("failure %d is not open", failure.ID)
+	}
+
+	job, err := retryFailure(failure, parameters, jobQueue)
+	if err != nil {
+		if reopenErr := database.ReopenProcessingFailure(failure.ID); reopenErr != nil {
+			log.Printf("Failed to reopen failure %d: %v", failure.ID, reopenErr)
+		}
+		return nil, err
+	}
+	if job != nil {
+		if err := database.SetFailureRequeuedJob(failure.ID, job.ID); err != nil {
+			log.Printf("Failed to link failure %d to job %d: %v", failure.ID, job.ID, err)
+		}
+	}
+	return job, nil
+}
+
+func retryFailure(failure *database.ProcessingFailure, parameters map[string]json.RawMessage, jobQueue *jobs.Queue) (*database.Job, error) {
+	switch failure.Source {
+	case database.FailureSourceChunk:
+		if failure.ChunkID == nil {
+			return nil, fmt.Errorf("chunk no longer exists")
+		}
+		requeued, err := database.RequeueFailedChunk(*failure.ChunkID)
+		if err != nil {
+			return nil, err
+		}
+		if !requeued {
+			return nil, fmt.Errorf("chunk %d is no longer failed", *failure.ChunkID)
+		}
+		return nil, nil
+	case database.FailureSourceJob, database.FailureSourceMinutes:
+		payload := make(map[string]json.RawMessage)
+		if len(failure.Context) > 0 {
+			if err := json.Unmarshal(failure.Context, &payload); err != nil {
+				return nil, fmt.Errorf("recorded payload is not an object: %w", err)
+			}
+		}
+		for key, value := range parameters {
+			if string(value) == "null" {
+				delete(payload, key)
+			} else {
+				payload[key] = value
+			}
+		}
+		kind := failure.Kind
+		if failure.Source == database.FailureSourceMinutes {
+			kind = meetingMinutesJobKind
+		}
+		return jobQueue.Enqueue(kind, failure.SessionID, failure.UserID, payload)
+	}
+	return nil, fmt.Errorf("unknown failure source %q", failure.Source)
+}
+
+// meetingDetailLink is the web page notifications about a meeting point to
+func meetingDetailLink(meetingID string) string {
+	return "/features/history/meeting-detail.html?id=" + url.QueryEscape(meetingID)
+}
+
+// notifyMinutesReady tells the meeting owner and every member with access
+// that minutes were generated
+func notifyMinutesReady(mtg *database.Meeting) {
+	recipients := make(map[int]bool)
+	if mtg.CreatedBy != nil {
+		recipients[*mtg.CreatedBy] = true
+	}
+	entries, err := database.ListMeetingAccessControl(mtg.ID)
+	if err != nil {
+		log.Printf("[Notify] Failed to list members of meeting %s: %v", mtg.ID, err)
+	}
+	for _, entry := range entries {
+		recipients[entry.UserID] = true
+	}
+
+	for userID := range recipients {
+		notify.Send(userID, notify.TypeMinutesReady, "Meeting minutes ready",
+			fmt.Sprintf("Minutes for meeting %s are available", mtg.RoomCode),
+			meetingDetailLink(mtg.ID), map[string]interface{}{"meetingId": mtg.ID})
+	}
+}
+
+// notifyProcessingFailed tells the uploader (if signed in) that processing failed
+func notifyProcessingFailed(userID *int, kind, filename, message string) {
+	if userID == nil {
+		return
+	}
+	notify.Send(*userID, notify.TypeProcessingFailed, fmt.Sprintf("Processing failed for %s", filename),
+		fmt.Sprintf("%s (%s upload)", message, kind), "", map[string]interface{}{"filename": filename, "kind": kind})
+}
+
+// clientLocation returns the time zone hinted by the tz query parameter (an
+// IANA name such as "Europe/Madrid"), or UTC when it is missing or unknown
+func clientLocation(r *http.Request) *time.Location {
+	if name := r.URL.Query().Get("tz"); name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// formatTranscript renders transcript lines with timestamps in loc
+func formatTranscript(entries []meeting.TranscriptEntry, loc *time.Location) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		speaker := entry.SpeakerName
+		if speaker == "" {
+			speaker = entry.SpeakerID
+		}
+		if speaker == "" {
+			speaker = "Speaker"
+		}
+		ts := entry.Timestamp.In(loc).Format("15:04:05")
+		b.WriteString(fmt.Sprintf("[%s] %s: %s\n", ts, speaker, entry.Text))
+	}
+	return b.String()
+}
+
+
+func getMeetingByCodeOrID(codeOrID string) (*database.Meeting, error) {
+	mtg, err := database.GetMeetingByRoomCode(codeOrID)
+	if err != nil {
+		return nil, err
+	}
+	if mtg != nil {
+		return mtg, nil
+	}
+	return database.GetMeetingByID(codeOrID)
+}
+
+func handleMeetingOperations(w http.ResponseWriter, r *http.Request, roomManager *meeting.RoomManager, ragProcessor *rag.Processor, queryEngine *rag.QueryEngine, llmClient *llm.Client, keycloakVerifier *auth.KeycloakVerifier, captionDelays captionDelayConfig, chunkArchive *audioarchive.Archive, jobQueue *jobs.Queue) {
+	// Route based on URL pattern
+	// /api/meetings/{roomCode} - GET meeting info
+	// /api/meetings/{roomCode}/join - POST to join
+	// /api/meetings/{roomCode}/speakers[/{speakerId}] - GET to list diarized speakers, PUT/POST to rename one and its stored transcript (editor)
+	// /api/meetings/{roomCode}/transcript - GET to download transcript (lang query param), or a JSON page of entries (format=json, after, limit, speaker, sourceLanguage, from, to)
+	// /api/meetings/{roomCode}/transcript-snapshots - GET to list available snapshots
+	// /api/meetings/{roomCode}/transcript-snapshot - GET to download snapshot (lang query param)
+	// /api/meetings/{roomCode}/export - GET transcript and minutes as txt, docx or pdf (format, lang query params)
+	// /api/meetings/{roomCode}/end - POST to end meeting (host only)
+	// /api/meetings/{roomCode}/documents[/{documentId}] - GET/POST/DELETE reference documents
+	// /api/meetings/{roomCode}/rag-settings - GET/PUT retrieval defaults (topK, minSimilarity)
+	// /api/meetings/{roomCode}/text-filter - GET the caption redaction policy, PUT/DELETE to change it (owner/co-host)
+	// /api/meetings/{roomCode}/invites[/{id}|/settings] - GET/POST join invites, DELETE to revoke, PUT invite-only (owner/co-host)
+	// /api/meetings/{roomCode}/waiting-room - GET waiting participants, PUT to turn the waiting room on or off (owner/co-host)
+	// /api/meetings/{roomCode}/rag/{status|ingest} - GET ingestion status, POST to re-ingest (owner/co-host)
+	// /api/meetings/{roomCode}/chunks/export - GET RAG chunks with embeddings (format=jsonl|parquet)
+	// /api/meetings/{roomCode}/chunks/import - POST JSONL chunks to replace the knowledge base
+	// /api/meetings/{roomCode}/captions/stream - GET delayed captions as server-sent events
+	// /api/meetings/{roomCode}/interpretation - GET aligned original/interpreter transcript (interpreted mode)
+	// /api/meetings/{roomCode}/consent - GET recording consent status (owner only)
+	// /api/meetings/{roomCode}/events - GET the sequenced broadcast log (since, type, limit query params)
+	// /api/meetings/{roomCode}/reprocess - POST to rebuild chunks, embeddings and minutes (owner only)
+	// /api/meetings/{roomCode}/transcript-entries[/{entryId}] - GET persisted final captions, paged (after, limit query params), PUT to correct one (editor)
+	// /api/meetings/{roomCode}/recordings[/{start|stop}] - GET recordings, POST to start/stop recording (owner only)
+	// /api/meetings/{roomCode}/action-items[/{id}[/complete]] - GET action items (lang, status), PATCH to edit, POST to complete (editor)
+	// /api/meetings/{roomCode}/chat[/{sessionId}[/messages]] - GET/POST chat sessions, GET history, POST questions (viewer)
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	if len(pathParts) < 4 {
+		sendJSONError(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	// Check if it's a join request
+	if len(pathParts) >= 5 && pathParts[4] == "join" {
+		handleJoinMeeting(w, r, roomManager, keycloakVerifier)
+		return
+	}
+
+	// Check if it's a transcript download: /api/meetings/{roomCode}/transcript.
+	// Paging, filters or format=json ask for the persisted entries instead.
+	if len(pathParts) >= 5 && pathParts[4] == "transcript" && r.Method == "GET" {
+		if wantsTranscriptPage(r) {
+			handleMeetingTranscriptEntries(w, r, keycloakVerifier, pathParts[3])
+			return
+		}
+		handleDownloadTranscript(w, r, roomManager, pathParts[3])
+		return
+	}
+
+	// Check if it's a transcript snapshot download
+	if len(pathParts) >= 5 && pathParts[4] == "transcript-snapshot" && r.Method == "GET" {
+		handleDownloadTranscriptSnapshot(w, r, pathParts[3])
+		return
+	}
+
+	// Check if it's a transcript snapshot list
+	if len(pathParts) >= 5 && pathParts[4] == "transcript-snapshots" && r.Method == "GET" {
+		handleListTranscriptSnapshots(w, r, pathParts[3])
+		return
+	}
+
+	// Check if it's a transcript export: /api/meetings/{roomCode}/export
+	if len(pathParts) >= 5 && pathParts[4] == "export" && r.Method == "GET" {
+		handleExportTranscript(w, r, roomManager, keycloakVerifier, pathParts[3])
+		return
+	}
+
+	// Check if it's an end meeting request
+	if len(pathParts) >= 5 && pathParts[4] == "end" && r.Method == "POST" {
+		handleEndMeeting(w, r, roomManager, llmClient, pathParts[3])
+		return
+	}
+
+	// Check if it's a participant link request
+	if len(pathParts) >= 5 && pathParts[4] == "link" && r.Method == "POST" {
+		handleLinkParticipant(w, r, keycloakVerifier, pathParts[3])
+		return
+	}
+
+	// Check if it's a caption feed: /api/meetings/{roomCode}/captions/stream
+	if len(pathParts) >= 6 && pathParts[4] == "captions" && pathParts[5] == "stream" && r.Method == "GET" {
+		handleCaptionStream(w, r, roomManager, keycloakVerifier, captionDelays, pathParts[3])
+		return
+	}
+
+	// Check if it's an interpretation transcript: /api/meetings/{roomCode}/interpretation
+	if len(pathParts) >= 5 && pathParts[4] == "interpretation" && r.Method == "GET" {
+		handleMeetingInterpretation(w, r, keycloakVerifier, pathParts[3])
+		return
+	}
+
+	// Check if it's a consent status request: /api/meetings/{roomCode}/consent
+	if len(pathParts) >= 5 && pathParts[4] == "consent" && r.Method == "GET" {
+		handleMeetingConsent(w, r, roomManager, keycloakVerifier, pathParts[3])
+		return
+	}
+
+	// Check if it's an event log request: /api/meetings/{roomCode}/events
+	if len(pathParts) >= 5 && pathParts[4] == "events" && r.Method == "GET" {
+		handleMeetingEvents(w, r, keycloakVerifier, pathParts[3])
+		return
+	}
+
+	// Check if it's a reprocess request: /api/meetings/{roomCode}/reprocess
+	if len(pathParts) >= 5 && pathParts[4] == "reprocess" {
+		handleReprocessMeeting(w, r, jobQueue, keycloakVerifier, pathParts[3], false)
+		return
+	}
+
+	// Check if it's a caption correction: /api/meetings/{roomCode}/transcript-entries/{entryId}
+	if len(pathParts) >= 6 && pathParts[4] == "transcript-entries" && pathParts[5] != "" {
+		handleMeetingCaptionCorrection(w, r, roomManager, keycloakVerifier, pathParts[3], pathParts[5])
+		return
+	}
+
+	// Check if it's a transcript page request: /api/meetings/{roomCode}/transcript-entries
+	if len(pathParts) >= 5 && pathParts[4] == "transcript-entries" && r.Method == "GET" {
+		handleMeetingTranscriptEntries(w, r, keycloakVerifier, pathParts[3])
+		return
+	}
+
+	// Check if it's an action item request: /api/meetings/{roomCode}/action-items[/{id}[/complete]]
+	if len(pathParts) >= 5 && pathParts[4] == "action-items" {
+		handleMeetingActionItems(w, r, keycloakVerifier, pathParts[3], pathParts[5:])
+		return
+	}
+
+	// Check if it's a chat request: /api/meetings/{roomCode}/chat[/{sessionId}[/messages]]
+	if len(pathParts) >= 5 && pathParts[4] == "chat" {
+		handleMeetingChat(w, r, queryEngine, keycloakVerifier, pathParts[3], pathParts[5:])
+		return
+	}
+
+	// Check if it's a recording request: /api/meetings/{roomCode}/recordings[/{start|stop}]
+	if len(pathParts) >= 5 && pathParts[4] == "recordings" {
+		action := ""
+		if len(pathParts) >= 6 {
+			action = pathParts[5]
+		}
+		handleMeetingRecordings(w, r, roomManager, keycloakVerifier, pathParts[3], action)
+		return
+	}
+
+	// Check if it's a caption audio request: /api/meetings/{roomCode}/audio/{audioRef}
+	if len(pathParts) >= 6 && pathParts[4] == "audio" && r.Method == "GET" {
+		handleMeetingChunkAudio(w, r, keycloakVerifier, chunkArchive, pathParts[3], pathParts[5])
+		return
+	}
+
+	// Check if it's a reference document request: /api/meetings/{roomCode}/documents[/{documentId}]
+	if len(pathParts) >= 5 && pathParts[4] == "documents" {
+		documentID := ""
+		if len(pathParts) >= 6 {
+			documentID = pathParts[5]
+		}
+		handleMeetingDocuments(w, r, ragProcessor, keycloakVerifier, pathParts[3], documentID)
+		return
+	}
+
+	// Check if it's an ingestion request: /api/meetings/{roomCode}/rag/{status|ingest}
+	if len(pathParts) >= 6 && pathParts[4] == "rag" {
+		handleMeetingRAGIngestion(w, r, ragProcessor, keycloakVerifier, pathParts[3], pathParts[5])
+		return
+	}
+
+	// Check if it's a retrieval settings request: /api/meetings/{roomCode}/rag-settings
+	if len(pathParts) >= 5 && pathParts[4] == "rag-settings" {
+		handleMeetingRAGSettings(w, r, keycloakVerifier, pathParts[3])
+		return
+	}
+
+	// Check if it's a redaction policy request: /api/meetings/{roomCode}/text-filter
+	if len(pathParts) >= 5 && pathParts[4] == "text-filter" {
+		handleMeetingTextFilter(w, r, keycloakVerifier, pathParts[3])
+		return
+	}
+
+	// Check if it's an invite request: /api/meetings/{roomCode}/invites[/{id}|/settings]
+	if len(pathParts) >= 5 && pathParts[4] == "invites" {
+		handleMeetingInvites(w, r, keycloakVerifier, pathParts[3], pathParts[5:])
+		return
+	}
+
+	// Check if it's a waiting room request: /api/meetings/{roomCode}/waiting-room
+	if len(pathParts) >= 5 && pathParts[4] == "waiting-room" {
+		handleMeetingWaitingRoom(w, r, roomManager, keycloakVerifier, pathParts[3])
+		return
+	}
+
+	// Check if it's a chunk export or import: /api/meetings/{roomCode}/chunks/{export|import}
+	if len(pathParts) >= 6 && pathParts[4] == "chunks" {
+		handleMeetingChunks(w, r, ragProcessor, keycloakVerifier, pathParts[3], pathParts[5])
+		return
+	}
+
+	// Check if it's a speaker list or rename: /api/meetings/{roomCode}/speakers[/{speakerId}]
+	if len(pathParts) >= 5 && pathParts[4] == "speakers" {
+		speakerID := ""
+		if len(pathParts) >= 6 {
+			speakerID = pathParts[5]
+		}
+		handleMeetingSpeakers(w, r, roomManager, keycloakVerifier, pathParts[3], speakerID)
+		return
+	}
+
+	// Otherwise, it's a get meeting info request
+	handleGetMeeting(w, r, roomManager)
+}
+
+func handleLinkParticipant(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, roomCode string) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ParticipantID int `json:"participantId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ParticipantID <= 0 {
+		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	participant, err := database.GetParticipantByID(req.ParticipantID)
+	if err != nil {
+		log.Printf("Failed to get participant: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to find participant")
+		return
+	}
+	if participant == nil || participant.MeetingID != mtg.ID {
+		sendJSONError(w, http.StatusNotFound, "Participant not found")
+		return
+	}
+
+	if participant.UserID != nil {
+		if *participant.UserID != user.ID {
+			sendJSONError(w, http.StatusConflict, "Participant already linked")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"linked":  true,
+		})
+		return
+	}
+
+	if err := database.UpdateParticipantUserID(req.ParticipantID, user.ID); err != nil {
+		log.Printf("Failed to link participant: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to link participant")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"linked":  true,
+	})
+}
+
+func handleSpeakerProfiles(w http.ResponseWriter, r *http.Request) {
+	sessionID := strings.TrimPrefix(r.URL.Path, "/api/speaker-profiles/")
+	if sessionID == "" || sessionID == r.URL.Path {
+		sendJSONError(w, http.StatusBadRequest, "Session ID required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		profiles, err := database.GetSpeakerProfiles(sessionID)
+		if err != nil {
+			log.Printf("Failed to get speaker profiles: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to get speaker profiles")
+			return
+		}
+
+		type profileInfo struct {
+			ProfileID string    `json:"profileId"`
+			Embedding []float32 `json:"embedding"`
+			Count     int       `json:"count"`
+			UpdatedAt string    `json:"updatedAt"`
+		}
+
+		response := make([]profileInfo, 0, len(profiles))
+		for _, profile := range profiles {
+			response = append(response, profileInfo{
+				ProfileID: profile.ProfileID,
+				Embedding: profile.Embedding,
+				Count:     profile.Count,
+				UpdatedAt: profile.UpdatedAt.Format(time.RFC3339),
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"profiles": response,
+		})
+	case http.MethodPut:
+		type profilePayload struct {
+			ProfileID string    `json:"profileId"`
+			Embedding []float32 `json:"embedding"`
+			Count     int       `json:"count"`
+		}
+		var payload struct {
+			Profiles []profilePayload `json:"profiles"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid payload")
+			return
+		}
+
+		profiles := make([]database.SpeakerProfile, 0, len(payload.Profiles))
+		for _, item := range payload.Profiles {
+			if item.ProfileID == "" || len(item.Embedding) == 0 {
+				continue
+			}
+			count := item.Count
+			if count <= 0 {
+				count = 1
+			}
+			profiles = append(profiles, database.SpeakerProfile{
+				SessionID: sessionID,
+				ProfileID: item.ProfileID,
+				Embedding: item.Embedding,
+				Count:     count,
+			})
+		}
+
+		if err := database.ReplaceSpeakerProfiles(sessionID, profiles); err != nil {
+			log.Printf("Failed to persist speaker profiles: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to persist speaker profiles")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"profiles": len(profiles),
+		})
+	case http.MethodDelete:
+		if err := database.DeleteSpeakerProfiles(sessionID); err != nil {
+			log.Printf("Failed to delete speaker profiles: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to delete speaker profiles")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func handleSpeakerProfileCleanup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	ttlSeconds := int64(0)
+	if ttlParam := r.URL.Query().Get("ttl_seconds"); ttlParam != "" {
+		if parsed, err := strconv.ParseInt(ttlParam, 10, 64); err == nil {
+			ttlSeconds = parsed
+		}
+	}
+
+	if ttlSeconds <= 0 {
+		ttlEnv := os.Getenv("SPEAKER_PROFILE_DB_TTL_SECONDS")
+		if ttlEnv != "" {
+			if parsed, err := strconv.ParseInt(ttlEnv, 10, 64); err == nil {
+				ttlSeconds = parsed
+			}
+		}
+	}
+
+	if ttlSeconds <= 0 {
+		sendJSONError(w, http.StatusBadRequest, "ttl_seconds is required")
+		return
+	}
+
+	cutoff := time.Now().UTC().Add(-time.Duration(ttlSeconds) * time.Second)
+	deleted, err := database.DeleteExpiredSpeakerProfiles(cutoff)
+	if err != nil {
+		log.Printf("Failed to delete expired speaker profiles: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to delete expired speaker profiles")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"deletedRows": deleted,
+	})
+}
+
+func main() {
+	// Keep recent error lines for the admin overview
+	errorLog := logring.New(200)
+	log.SetOutput(io.MultiWriter(os.Stderr, errorLog))
+
+	// Initialize database
+	log.Println("Initializing database connection...")
+	if err := database.Init(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+	log.Println("Database connection established")
+
+	// Create RAG processor (will be initialized after embedding client is created)
+	var roomManager *meeting.RoomManager
+
+	log.Println("Meeting room manager will be initialized after RAG components")
+
+	// Optional speaker profile cleanup job
+	if ttlEnv := os.Getenv("SPEAKER_PROFILE_DB_TTL_SECONDS"); ttlEnv != "" {
+		if ttlSeconds, err := strconv.ParseInt(ttlEnv, 10, 64); err == nil && ttlSeconds > 0 {
+			intervalSeconds := int64(300)
+			if intervalEnv := os.Getenv("SPEAKER_PROFILE_DB_CLEANUP_INTERVAL_SECONDS"); intervalEnv != "" {
+				if parsed, err := strconv.ParseInt(intervalEnv, 10, 64); err == nil && parsed > 0 {
+					intervalSeconds = parsed
+				}
+			}
+
+			go func() {
+				ticker := time.NewTicker(time.Duration(intervalSeconds) * time.Second)
+				defer ticker.Stop()
+				for range ticker.C {
+					cutoff := time.Now().UTC().Add(-time.Duration(ttlSeconds) * time.Second)
+					deleted, err := database.DeleteExpiredSpeakerProfiles(cutoff)
+					if err != nil {
+						log.Printf("Speaker profile cleanup failed: %v", err)
+						continue
+					}
+					if deleted > 0 {
+						log.Printf("Speaker profile cleanup removed %d row(s)", deleted)
+					}
+				}
+			}()
+		}
+	}
+
+	// Check if ffmpeg is installed
+	if err := video.CheckFFmpegInstalled(); err != nil {
+		log.Printf("Warning: %v - Video upload feature will not work", err)
+	}
+
+	// Create temp directory for video processing
+	tempDir := "./temp"
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		log.Fatalf("Failed to create temp directory: %v", err)
+	}
+
+	// ASR_BASE_URL and TTS_BASE_URL may list several replicas; more can be
+	// registered at runtime through /api/autoscaling/replicas
+	asrReplicas := replica.NewPool("asr", replica.ParseURLs(getEnv("ASR_BASE_URL", "http://127.0.0.1:8003"))...)
+	ttsReplicas := replica.NewPool("tts", replica.ParseURLs(getEnv("TTS_BASE_URL", "http://127.0.0.1:8005"))...)
+	asrBaseURL := asrReplicas.URLs()[0]
+	translationBaseURL := getEnv("TRANSLATION_BASE_URL", "http://127.0.0.1:8004")
+	ttsBaseURL := ttsReplicas.URLs()[0]
+	embeddingBaseURL := getEnv("EMBEDDING_BASE_URL", "http://127.0.0.1:8006")
+	llmBaseURL := getEnv("LLM_BASE_URL", "http://127.0.0.1:8007")
+
+	// Optional A/B experiment over live pipeline parameters
+	var liveExperiment *experiment.Experiment
+	if path := getEnv("LIVE_EXPERIMENT_FILE", ""); path != "" {
+		exp, err := experiment.Load(path)
+		if err != nil {
+			log.Printf("Warning: live experiment disabled: %v", err)
+		} else {
+			liveExperiment = exp
+			log.Printf("Live experiment %q running with %d variants", exp.Name, len(exp.Variants))
+		}
+	}
+
+	// WebSocket keepalive: peers that miss pongs for WS_PONG_WAIT_SECONDS are
+	// disconnected; WS_PING_INTERVAL_SECONDS=0 disables pings
+	keepalive.Configure(keepalive.Config{
+		PingInterval: time.Duration(getEnvInt("WS_PING_INTERVAL_SECONDS", 30)) * time.Second,
+		PongWait:     time.Duration(getEnvInt("WS_PONG_WAIT_SECONDS", 60)) * time.Second,
+	})
+
+	// GPU service call limits shared by live sessions, meetings and uploads.
+	// Live audio queues ahead of upload jobs; calls beyond a full queue fail
+	// fast so bursts degrade into skipped chunks rather than a stalled service.
+	asrLimiter := ratelimit.New("asr", getEnvInt("ASR_MAX_CONCURRENCY", 4), 0).
+		WithMaxQueued(getEnvInt("ASR_MAX_QUEUED", 32))
+	translationLimiter := ratelimit.New("translation", getEnvInt("TRANSLATION_MAX_CONCURRENCY", 8), 0).
+		WithMaxQueued(getEnvInt("TRANSLATION_MAX_QUEUED", 64))
+	ttsLimiter := ratelimit.New("tts", getEnvInt("TTS_MAX_CONCURRENCY", 2), 0).
+		WithMaxQueued(getEnvInt("TTS_MAX_QUEUED", 16))
+
+	// Retries and circuit breakers, one per service, shared by every client
+	breakerPolicy := httpx.Policy{
+		MaxAttempts:      getEnvInt("SERVICE_MAX_ATTEMPTS", httpx.DefaultPolicy.MaxAttempts),
+		FailureThreshold: getEnvInt("SERVICE_BREAKER_THRESHOLD", httpx.DefaultPolicy.FailureThreshold),
+		Cooldown:         time.Duration(getEnvInt("SERVICE_BREAKER_COOLDOWN_SECONDS", 30)) * time.Second,
+	}
+	asrBreaker := httpx.NewBreaker("asr", breakerPolicy)
+	translationBreaker := httpx.NewBreaker("translation", breakerPolicy)
+	ttsBreaker := httpx.NewBreaker("tts", breakerPolicy)
+	embeddingBreaker := httpx.NewBreaker("embedding", breakerPolicy)
+	llmBreaker := httpx.NewBreaker("llm", breakerPolicy)
+
+	meeting.SetServiceLimiters(asrLimiter, translationLimiter)
+	meeting.SetServiceBreakers(asrBreaker, translationBreaker)
+	meeting.SetASRReplicas(asrReplicas)
+
+	srv := session.NewServer(session.Config{
+		ASRBaseURL:       asrBaseURL,
+		TTSBaseURL:       ttsBaseURL,
+		PollInterval:     800 * time.Millisecond,
+		WindowSeconds:    8,
+		FinalizeAfter:    500 * time.Millisecond, // Reduced from 900ms for faster finalization
+		VADThreshold:     getEnvFloat("STREAMING_VAD_THRESHOLD", 0),
+		Experiment:       liveExperiment,
+		ASRLimiter:       asrLimiter,
+		TranslateLimiter: translationLimiter,
+		TTSLimiter:       ttsLimiter,
+		ASRReplicas:      asrReplicas,
+		TTSReplicas:      ttsReplicas,
+		ASRBreaker:       asrBreaker,
+		TranslateBreaker: translationBreaker,
+		TTSBreaker:       ttsBreaker,
+
+		LanguageRedetectInterval: time.Duration(getEnvInt("STREAMING_LANGUAGE_REDETECT_SECONDS", 10)) * time.Second,
+		LanguageSwitchConfidence: getEnvFloat("STREAMING_LANGUAGE_SWITCH_CONFIDENCE", 0.8),
+	})
+
+	// Create progress manager
+	progressMgr := progress.NewManager()
+
+	// Create video processor
+	videoProcessor := video.NewProcessor(tempDir)
+
+	// Delete temp files left behind by failed jobs and undownloaded results
+	if tempTTL := time.Duration(getEnvInt("TEMP_FILE_TTL_HOURS", 24)) * time.Hour; tempTTL > 0 {
+		sweepInterval := time.Duration(getEnvInt("TEMP_JANITOR_INTERVAL_MINUTES", 30)) * time.Minute
+		if sweepInterval <= 0 {
+			sweepInterval = 30 * time.Minute
+		}
+		go videoProcessor.StartJanitor(sweepInterval, tempTTL, func() ([]string, error) {
+			return activeJobFiles(videoProcessor)
+		}, nil)
+	}
+
+	// Deployment-specific pipeline hooks; a broken configuration stops the
+	// server rather than silently skipping compliance hooks
+	hooks.SetOptions(time.Duration(getEnvInt("PIPELINE_HOOK_TIMEOUT_MS", 2000))*time.Millisecond,
+		getEnv("PIPELINE_HOOKS_FAIL_CLOSED", "false") == "true")
+	if err := textfilter.Register(); err != nil {
+		log.Fatalf("Failed to register caption redaction: %v", err)
+	}
+	if err := hooks.LoadPlugins(strings.Split(getEnv("PIPELINE_HOOK_PLUGINS", ""), ",")); err != nil {
+		log.Fatalf("Failed to load pipeline hooks: %v", err)
+	}
+	if err := hooks.Configure(getEnv("PIPELINE_HOOKS", ""), getEnv("PIPELINE_HOOK_SECRET", "")); err != nil {
+		log.Fatalf("Failed to configure pipeline hooks: %v", err)
+	}
+	if fontPath := getEnv("PDF_FONT_PATH", ""); fontPath != "" {
+		if err := export.LoadPDFFont(fontPath); err != nil {
+			log.Fatalf("Failed to load PDF export font: %v", err)
+		}
+	}
+
+	// Create ASR client for batch processing
+	asrClient := asr.New(asrBaseURL)
+	asrClient.Limiter = asrLimiter
+	asrClient.Replicas = asrReplicas
+	asrClient.Breaker = asrBreaker
+
+	// Create translator
+	translator := &translate.HTTPTranslator{
+		BaseURL: translationBaseURL,
+		Limiter: translationLimiter,
+		Breaker: translationBreaker,
+	}
+
+	// Create TTS client
+	ttsClient := tts.New(ttsBaseURL)
+	ttsClient.Limiter = ttsLimiter
+	ttsClient.Replicas = ttsReplicas
+	ttsClient.Breaker = ttsBreaker
+
+	// Create RAG components (embedding + LLM clients)
+	embeddingClient := embedding.New(embeddingBaseURL)
+	embeddingClient.Limiter = ratelimit.New("embedding",
+		getEnvInt("EMBEDDING_MAX_CONCURRENCY", 8), getEnvFloat("EMBEDDING_QPS", 0))
+	embeddingClient.Breaker = embeddingBreaker
+	embeddingClient.MaxBatchSize = getEnvInt("EMBEDDING_MAX_BATCH_SIZE", embedding.DefaultMaxBatchSize)
+	llmClient := llm.New(llmBaseURL)
+	llmClient.Limiter = ratelimit.New("llm",
+		getEnvInt("LLM_MAX_CONCURRENCY", 2), getEnvFloat("LLM_QPS", 0))
+	llmClient.Breaker = llmBreaker
+
+	// Response caches (TTL of 0 disables a cache)
+	cacheMaxEntries := getEnvInt("RAG_CACHE_MAX_ENTRIES", 5000)
+	embeddingClient.Cache = cache.New[string, []float32]("embedding",
+		time.Duration(getEnvInt("EMBEDDING_CACHE_TTL_SECONDS", 3600))*time.Second, cacheMaxEntries)
+	llmClient.Cache = cache.New[string, string]("llm",
+		time.Duration(getEnvInt("LLM_CACHE_TTL_SECONDS", 600))*time.Second, cacheMaxEntries)
+
+	// Chat uses the interactive clients; transcript processing, minutes and
+	// summaries queue behind them at batch priority
+	batchEmbeddingClient := embeddingClient.WithPriority(ratelimit.Batch)
+	batchLLMClient := llmClient.WithPriority(ratelimit.Batch)
+	ragProcessor := rag.NewProcessor(batchEmbeddingClient)
+	ragProcessor.Chunking = rag.ChunkOptions{
+		MaxChars:     getEnvInt("RAG_CHUNK_MAX_CHARS", rag.DefaultChunkOptions.MaxChars),
+		MinChars:     getEnvInt("RAG_CHUNK_MIN_CHARS", rag.DefaultChunkOptions.MinChars),
+		OverlapChars: getEnvInt("RAG_CHUNK_OVERLAP_CHARS", rag.DefaultChunkOptions.OverlapChars),
+	}
+	ragQueryEngine := rag.NewQueryEngine(embeddingClient, llmClient)
+	ragQueryEngine.MinSimilarity = getEnvFloat("RAG_MIN_SIMILARITY", 0.25)
+	ragQueryEngine.GroundingMode = getEnv("RAG_GROUNDING_MODE", rag.GroundingFlag)
+	ragQueryEngine.GroundingMethod = getEnv("RAG_GROUNDING_METHOD", rag.GroundingMethodEmbedding)
+	if thresholdEnv := os.Getenv("RAG_GROUNDING_THRESHOLD"); thresholdEnv != "" {
+		if parsed, err := strconv.ParseFloat(thresholdEnv, 64); err == nil && parsed > 0 {
+			ragQueryEngine.GroundingThreshold = parsed
+		}
+	}
+	if rerankBaseURL := os.Getenv("RERANK_BASE_URL"); rerankBaseURL != "" {
+		ragQueryEngine.Reranker = rerank.New(rerankBaseURL)
+		ragQueryEngine.Reranker.Breaker = httpx.NewBreaker("rerank", breakerPolicy)
+		ragQueryEngine.RerankCandidates = getEnvInt("RERANK_CANDIDATES", 30)
+		log.Printf("RAG reranking enabled (%d candidates)", ragQueryEngine.RerankCandidates)
+	}
+	log.Println("RAG components initialized")
+
+	// Background retry of chunks whose embedding failed
+	ragRetryInterval := 60 * time.Second
+	if intervalEnv := os.Getenv("RAG_RETRY_INTERVAL_SECONDS"); intervalEnv != "" {
+		if parsed, err := strconv.ParseInt(intervalEnv, 10, 64); err == nil && parsed > 0 {
+			ragRetryInterval = time.Duration(parsed) * time.Second
+		}
+	}
+	go ragProcessor.StartRetryWorker(ragRetryInterval, 50, nil)
+
+	// Per-user quotas for users without overrides in user_quotas; 0 is unlimited
+	quota.SetDefaults(quota.Limits{
+		AudioMinutesPerDay: getEnvInt("QUOTA_AUDIO_MINUTES_PER_DAY", 0),
+		ConcurrentJobs:     getEnvInt("QUOTA_CONCURRENT_JOBS", 0),
+		StorageBytes:       int64(getEnvInt("QUOTA_STORAGE_MB", 0)) << 20,
+	})
+
+	// Initialize RoomManager with RAG processor
+	roomManager = meeting.NewRoomManager(ragProcessor)
+	log.Println("Meeting room manager initialized with RAG support")
+
+	// Meetings end automatically at their duration limit so forgotten rooms
+	// do not hold ASR capacity; 0 leaves meetings without an org limit unlimited
+	meetingMaxDuration := getEnvInt("MEETING_MAX_DURATION_MINUTES", 240)
+	roomManager.SetAutoEndHandler(func(meetingID string) {
+		mtg, err := database.GetMeetingByID(meetingID)
+		if err != nil || mtg == nil {
+			log.Printf("Failed to load auto-ended meeting %s: %v", meetingID, err)
+			return
+		}
+		finalizeMeeting(mtg, batchLLMClient)
+	})
+	// Rooms where nobody has spoken for a while stop buffering audio until
+	// the next voiced frame; 0 keeps rooms active
+	roomManager.SetIdleTimeout(time.Duration(getEnvInt("MEETING_IDLE_SUSPEND_MINUTES", 5)) * time.Minute)
+	roomManager.SetPartialCaptions(
+		time.Duration(getEnvInt("MEETING_PARTIAL_INTERVAL_MS", 1500))*time.Millisecond,
+		time.Duration(getEnvInt("MEETING_PARTIAL_WINDOW_SECONDS", 4))*time.Second,
+	)
+	roomManager.SetVoiceMatchThreshold(getEnvFloat("VOICE_MATCH_THRESHOLD", 0.7))
+	// A participant's source language changes only once most of their recent
+	// confidently detected chunks agree, so it does not flicker mid-sentence
+	roomManager.SetLanguageSmoothing(getEnvInt("MEETING_LANGUAGE_WINDOW", 5), getEnvFloat("MEETING_LANGUAGE_MIN_CONFIDENCE", 0.6))
+	// Speech detection of live audio; the first seconds of each stream
+	// calibrate the noise floor, and speech must stand out from it
+	vadConfig := vad.Config{
+		Threshold:   getEnvFloat("VAD_FRAME_THRESHOLD", vad.DefaultThreshold),
+		Calibration: time.Duration(getEnvFloat("VAD_CALIBRATION_SECONDS", 2) * float64(time.Second)),
+		NoiseMargin: getEnvFloat("VAD_NOISE_MARGIN", vad.DefaultNoiseMargin),
+	}
+	roomManager.SetVoiceDetection(vadConfig, getEnvFloat("VAD_CHUNK_THRESHOLD", meeting.DefaultChunkThreshold))
+	recordingMinRMS := getEnvFloat("RECORDING_MIN_RMS", session.DefaultMinRMS)
+	// A dropped recording or meeting connection can reattach within this
+	// grace period instead of ending the recording or leaving the meeting
+	resumeGrace := time.Duration(getEnvInt("RESUME_GRACE_SECONDS", 60)) * time.Second
+	roomManager.SetResumeGrace(resumeGrace)
+	// Caption simplification runs inline with live captions, so it gets
+	// interactive priority and a short timeout instead of the generation default
+	if getEnv("CAPTION_SIMPLIFY_ENABLED", "true") == "true" {
+		captionSimplifier := llmClient.WithPriority(ratelimit.Interactive)
+		captionSimplifier.HTTP = &http.Client{
+			Timeout: time.Duration(getEnvInt("CAPTION_SIMPLIFY_TIMEOUT_SECONDS", 8)) * time.Second,
+		}
+		roomManager.SetCaptionSimplifier(captionSimplifier)
+	}
+	// Spoken captions are opt-in per participant and follow the live
+	// captions, so they queue with interactive priority too
+	if getEnv("MEETING_SPEECH_ENABLED", "false") == "true" {
+		roomManager.SetSpeechSynthesizer(ttsClient.WithPriority(ratelimit.Interactive))
+	}
+	go roomManager.WatchRooms(context.Background())
+
+	keycloakVerifier, err := auth.NewKeycloakVerifierFromEnv()
+	if err != nil {
+		log.Printf("Keycloak auth disabled: %v", err)
+	}
+
+	minioClient, err := storage.NewMinioFromEnv()
+	if err != nil {
+		log.Printf("MinIO disabled: %v", err)
+	}
+
+	// Optional archive of the audio behind each recording and meeting caption
+	var chunkArchive *audioarchive.Archive
+	if getEnv("AUDIO_ARCHIVE_ENABLED", "false") == "true" {
+		retention := time.Duration(getEnvInt("AUDIO_ARCHIVE_RETENTION_DAYS", 30)) * 24 * time.Hour
+		chunkArchive = audioarchive.New(minioClient, getEnv("AUDIO_ARCHIVE_FORMAT", audioarchive.FormatFLAC), retention)
+		if chunkArchive == nil {
+			log.Printf("Audio archive disabled: needs MinIO and a positive retention")
+		} else {
+			meeting.SetAudioArchive(chunkArchive)
+			go chunkArchive.StartSweeper(time.Hour, nil)
+		}
+	}
+
+	// Owner-started meeting recordings (mixed audio + caption transcript)
+	meeting.SetRecordingStore(minioClient, getEnv("MEETING_RECORDING_DIR", ""))
+	if failed, err := database.FailInterruptedMeetingRecordings(); err != nil {
+		log.Printf("Failed to clean up interrupted meeting recordings: %v", err)
+	} else if failed > 0 {
+		log.Printf("Marked %d interrupted meeting recording(s) failed", failed)
+	}
+
+	// Persistent job queue for upload processing
+	jobWorkers := getEnvInt("JOB_WORKERS", 2)
+	jobQueue := jobs.New(jobs.Config{
+		Workers:     jobWorkers,
+		MaxAttempts: getEnvInt("JOB_MAX_ATTEMPTS", 3),
+		RetryBase:   time.Duration(getEnvInt("JOB_RETRY_BASE_SECONDS", 30)) * time.Second,
+		Priorities: map[string]int{
+			videoJobKind: failures.PriorityHigh,
+			audioJobKind: failures.PriorityHigh,
+		},
+	})
+
+	// Processing estimates, corrected by each completed upload; prices are
+	// optional and only turn usage into a cost figure
+	estimator := estimate.New(jobWorkers, estimate.Prices{
+		ASRMinute:             getEnvFloat("ESTIMATE_PRICE_ASR_MINUTE", 0),
+		TranslationPer1KChars: getEnvFloat("ESTIMATE_PRICE_TRANSLATION_1K_CHARS", 0),
+		TTSMinute:             getEnvFloat("ESTIMATE_PRICE_TTS_MINUTE", 0),
+		ClonedVoiceMinute:     getEnvFloat("ESTIMATE_PRICE_CLONED_VOICE_MINUTE", 0),
+		Currency:              getEnv("ESTIMATE_CURRENCY", "USD"),
+	})
+	// Upload jobs queue behind live audio for the GPU services
+	batchASRClient := asrClient.WithPriority(ratelimit.Batch)
+	batchTranslator := translator.WithPriority(ratelimit.Batch)
+	batchTTSClient := ttsClient.WithPriority(ratelimit.Batch)
+	jobQueue.Register(videoJobKind, newVideoJobHandler(videoProcessor, batchASRClient, batchTranslator, batchTTSClient, progressMgr, minioClient, estimator, jobQueue))
+	jobQueue.Register(audioJobKind, newAudioJobHandler(videoProcessor, batchASRClient, batchTranslator, batchTTSClient, progressMgr, minioClient, estimator))
+
+	// Signed completion callbacks for uploads that pass a callbackUrl
+	webhooks := webhook.New(
+		getEnv("WEBHOOK_SECRET", ""),
+		getEnv("WEBHOOK_ALLOW_PRIVATE", "false") == "true",
+		time.Duration(getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10))*time.Second,
+	)
+	jobQueue.Register(webhookJobKind, newWebhookJobHandler(webhooks))
+	jobQueue.Register(meetingReprocessJobKind, newMeetingReprocessJobHandler(ragProcessor, batchLLMClient, progressMgr))
+	jobQueue.Register(meetingMinutesJobKind, newMeetingMinutesJobHandler(batchLLMClient))
+	jobQueue.Start(context.Background())
+
+	// Pipeline metrics for Prometheus; gauges that need no bookkeeping are read at scrape time
+	metrics.NewGaugeFunc("meeting_rooms_active", "Meeting rooms with connected participants", func() float64 {
+		return float64(roomManager.GetActiveRoomCount())
+	})
+	metrics.NewGaugeFunc("job_queue_depth", "Background jobs waiting to run", func() float64 {
+		depth, err := jobQueue.Depth()
+		if err != nil {
+			log.Printf("[Metrics] %v", err)
+			return math.NaN()
+		}
+		return float64(depth)
+	})
+	http.Handle("/metrics", requireMetricsAccess(getEnv("METRICS_TOKEN", ""), metrics.Handler()))
+
+	// Autoscaler signals: pending work per service and replica registration
+	autoscalerToken := getEnv("AUTOSCALER_TOKEN", "")
+	autoscalerDenied := "Autoscaling API is only available from localhost or with AUTOSCALER_TOKEN"
+	serviceLimiters := map[string]*ratelimit.Limiter{
+		estimate.ServiceASR:         asrLimiter,
+		estimate.ServiceTranslation: translationLimiter,
+		estimate.ServiceTTS:         ttsLimiter,
+	}
+	servicePools := map[string]*replica.Pool{
+		estimate.ServiceASR: asrReplicas,
+		estimate.ServiceTTS: ttsReplicas,
+	}
+	durations := &backlogDurations{durations: make(map[int]float64)}
+	http.Handle("/api/autoscaling/backlog", requireLocalOrToken(autoscalerToken, autoscalerDenied,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handleBacklog(w, r, videoProcessor, estimator, durations, serviceLimiters, servicePools)
+		})))
+	replicasHandler := requireLocalOrToken(autoscalerToken, autoscalerDenied,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handleReplicas(w, r, servicePools)
+		}))
+	http.Handle("/api/autoscaling/replicas", replicasHandler)
+	http.Handle("/api/autoscaling/replicas/", replicasHandler)
+
+	// Static file server
+	http.Handle("/", http.FileServer(http.Dir("./web")))
+
+	// Redirects for new file structure
+	http.HandleFunc("/meeting.html", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./web/features/meeting/meeting-create.html")
+	})
+	http.HandleFunc("/meeting-join.html", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./web/features/meeting/meeting-join.html")
+	})
+	http.HandleFunc("/meeting-room.html", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./web/features/meeting/meeting-room.html")
+	})
+	http.HandleFunc("/streaming.html", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./web/features/streaming/streaming.html")
+	})
+	http.HandleFunc("/recording.html", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./web/features/recording/recording.html")
+	})
+	http.HandleFunc("/video.html", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./web/features/video/video.html")
+	})
+
+	// JavaScript file redirects for compatibility
+	http.HandleFunc("/meeting-room.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		http.ServeFile(w, r, "./web/features/meeting/meeting-room.js")
+	})
+	http.HandleFunc("/assets/js/audio-processor.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		http.ServeFile(w, r, "./web/assets/js/audio-processor.js")
+	})
+	http.HandleFunc("/assets/js/utils.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		http.ServeFile(w, r, "./web/assets/js/utils.js")
+	})
+
+	http.HandleFunc("/api/speaker-profiles/cleanup", handleSpeakerProfileCleanup)
+	http.HandleFunc("/api/speaker-profiles/", handleSpeakerProfiles)
+	http.HandleFunc("/api/voice-enrollment", func(w http.ResponseWriter, r *http.Request) {
+		handleVoiceEnrollment(w, r, videoProcessor, asrClient, keycloakVerifier)
+	})
+	http.HandleFunc("/api/auth/keycloak", handleKeycloakLogin(keycloakVerifier))
+	http.HandleFunc("/api/history/video", handleCreateVideoHistory(keycloakVerifier))
+	http.HandleFunc("/api/history/audio", handleCreateAudioHistory(keycloakVerifier))
+	http.HandleFunc("/api/history/streaming", handleCreateStreamingHistory(keycloakVerifier))
+	http.HandleFunc("/api/history", handleListHistory(keycloakVerifier))
+	http.HandleFunc("/api/history/", handleHistorySession(keycloakVerifier, minioClient))
+	http.HandleFunc("/api/tags", func(w http.ResponseWriter, r *http.Request) {
+		handleTags(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/tags/", func(w http.ResponseWriter, r *http.Request) {
+		handleTags(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/files", handleCreateUserFile(keycloakVerifier))
+	http.HandleFunc("/api/files/", handleUserFileURL(keycloakVerifier, minioClient))
+
+	// User meetings history API endpoints
+	http.HandleFunc("/api/notifications", func(w http.ResponseWriter, r *http.Request) {
+		handleNotifications(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/notifications/", func(w http.ResponseWriter, r *http.Request) {
+		handleNotifications(w, r, keycloakVerifier)
+	})
+	// Single-use tickets authenticating WebSocket and event-stream connections
+	socketTickets := wsticket.NewStore(time.Duration(getEnvInt("WS_TICKET_TTL_SECONDS", 60)) * time.Second)
+	http.HandleFunc("/api/ws-tickets", func(w http.ResponseWriter, r *http.Request) {
+		handleSocketTickets(w, r, keycloakVerifier, socketTickets)
+	})
+	http.HandleFunc("/ws/notifications", func(w http.ResponseWriter, r *http.Request) {
+		handleNotificationsWebSocket(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/users/me/flags", func(w http.ResponseWriter, r *http.Request) {
+		handleUserFlags(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/users/me/quota", func(w http.ResponseWriter, r *http.Request) {
+		handleUserQuota(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/lexicon", func(w http.ResponseWriter, r *http.Request) {
+		handleLexicon(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/lexicon/", func(w http.ResponseWriter, r *http.Request) {
+		handleLexicon(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/users/me/meetings", func(w http.ResponseWriter, r *http.Request) {
+		handleListUserMeetings(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/users/me/meetings/", func(w http.ResponseWriter, r *http.Request) {
+		handleGetUserMeetingDetail(w, r, keycloakVerifier)
+	})
+
+	// Meeting Access Control API endpoints
+	http.HandleFunc("/api/meetings/access/list/", func(w http.ResponseWriter, r *http.Request) {
+		handleListMeetingAccess(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/meetings/access/grant", func(w http.ResponseWriter, r *http.Request) {
+		handleGrantMeetingAccess(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/meetings/access/update", func(w http.ResponseWriter, r *http.Request) {
+		handleUpdateMeetingAccess(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/meetings/access/revoke", func(w http.ResponseWriter, r *http.Request) {
+		handleRevokeMeetingAccess(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/meetings/access/cohost", func(w http.ResponseWriter, r *http.Request) {
+		handleMeetingCoHost(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/meetings/access/interpreter", func(w http.ResponseWriter, r *http.Request) {
+		handleMeetingInterpreter(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/meetings/access/transfer", func(w http.ResponseWriter, r *http.Request) {
+		handleTransferMeetingOwnership(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/meetings/participants/available/", func(w http.ResponseWriter, r *http.Request) {
+		handleGetAvailableParticipants(w, r, keycloakVerifier)
+	})
+
+	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Println("upgrade:", err)
+			return
+		}
+		subject := flags.SubjectForUser(userFromContext(r.Context()))
+		go srv.HandleConn(conn, session.ConnOptions{
+			LiveDubbing: flags.Enabled(flags.LiveDubbing, subject),
+			OrgID:       subject.OrgID,
+		})
+	})
+
+	http.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		handleVideoUpload(w, r, videoProcessor, jobQueue, keycloakVerifier, webhooks)
+	})
+
+	http.HandleFunc("/upload/", func(w http.ResponseWriter, r *http.Request) {
+		handleUploadRetry(w, r, videoProcessor, jobQueue, keycloakVerifier)
+	})
+
+	http.HandleFunc("/estimate", func(w http.ResponseWriter, r *http.Request) {
+		handleEstimate(w, r, videoProcessor, jobQueue, estimator)
+	})
+
+	http.HandleFunc("/api/jobs/", func(w http.ResponseWriter, r *http.Request) {
+		handleJobStatus(w, r, keycloakVerifier)
+	})
+
+	http.HandleFunc("/api/provenance/inspect", func(w http.ResponseWriter, r *http.Request) {
+		handleProvenanceInspect(w, r, videoProcessor)
+	})
+
+	// Batch audio-file translation; /upload-audio is the original path
+	audioUploadHandler := func(w http.ResponseWriter, r *http.Request) {
+		handleAudioUpload(w, r, videoProcessor, jobQueue, keycloakVerifier)
+	}
+	http.HandleFunc("/upload/audio", audioUploadHandler)
+	http.HandleFunc("/upload-audio", audioUploadHandler)
+
+	// Several files in one request, processed as one job each
+	batchMaxFiles := getEnvInt("BATCH_MAX_FILES", 20)
+	batchMaxBytes := int64(getEnvInt("BATCH_MAX_UPLOAD_MB", 2048)) << 20
+	http.HandleFunc("/upload/batch", func(w http.ResponseWriter, r *http.Request) {
+		handleBatchUpload(w, r, videoProcessor, jobQueue, progressMgr, keycloakVerifier, batchMaxFiles, batchMaxBytes)
+	})
+	http.HandleFunc("/api/batches/", func(w http.ResponseWriter, r *http.Request) {
+		handleBatchOperations(w, r, videoProcessor, minioClient, keycloakVerifier)
+	})
+
+	// Meeting API endpoints
+	http.HandleFunc("/api/meetings", func(w http.ResponseWriter, r *http.Request) {
+		handleCreateMeeting(w, r, keycloakVerifier, meetingMaxDuration)
+	})
+	// Caption feeds for broadcast overlays, delayed to match the video feed
+	captionDelays := captionDelayConfig{
+		Default: time.Duration(getEnvFloat("CAPTION_DEFAULT_DELAY_SECONDS", 0) * float64(time.Second)),
+		Max:     time.Duration(getEnvFloat("CAPTION_MAX_DELAY_SECONDS", 120) * float64(time.Second)),
+	}
+	http.HandleFunc("/api/meetings/", func(w http.ResponseWriter, r *http.Request) {
+		handleMeetingOperations(w, r, roomManager, ragProcessor, ragQueryEngine, batchLLMClient, keycloakVerifier, captionDelays, chunkArchive, jobQueue)
+	})
+	http.HandleFunc("/ws/captions/", func(w http.ResponseWriter, r *http.Request) {
+		handleCaptionWebSocket(w, r, roomManager, keycloakVerifier, captionDelays, strings.TrimPrefix(r.URL.Path, "/ws/captions/"))
+	})
+
+	// RAG Chat API endpoints
+	http.HandleFunc("/api/chat/sessions", func(w http.ResponseWriter, r *http.Request) {
+		handleChatSessions(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/chat/sessions/", func(w http.ResponseWriter, r *http.Request) {
+		handleChatSessionOperations(w, r, keycloakVerifier)
+	})
+	http.HandleFunc("/api/chat/query", func(w http.ResponseWriter, r *http.Request) {
+		handleChatQuery(w, r, ragQueryEngine, keycloakVerifier, false)
+	})
+	http.HandleFunc("/api/chat/query/stream", func(w http.ResponseWriter, r *http.Request) {
+		handleChatQuery(w, r, ragQueryEngine, keycloakVerifier, true)
+	})
+
+	// Video and recording summaries / RAG processing
+	http.HandleFunc("/api/sources/", func(w http.ResponseWriter, r *http.Request) {
+		handleSourceOperations(w, r, ragProcessor, batchLLMClient, keycloakVerifier)
+	})
+
+	// Liveness and readiness probes
+	serviceURLs := map[string]string{
+		"asr":         asrBaseURL,
+		"translation": translationBaseURL,
+		"tts":         ttsBaseURL,
+		"embedding":   embeddingBaseURL,
+		"llm":         llmBaseURL,
+	}
+	healthHandler := func(w http.ResponseWriter, r *http.Request) {
+		handleHealth(w, r, serviceURLs)
+	}
+	http.HandleFunc("/healthz", healthHandler)
+	http.HandleFunc("/readyz", healthHandler)
+	http.HandleFunc("/health/services", handleServiceHealth)
+
+	// Diagnostics API endpoints (localhost only)
+	http.HandleFunc("/api/diagnostics", handleDiagnostics)
+	http.HandleFunc("/api/diagnostics/services/", handleDiagnosticsService)
+
+	// Admin API endpoints (localhost only)
+	http.HandleFunc("/api/admin/rag/failed-chunks/", handleAdminFailedChunks)
+	http.HandleFunc("/api/admin/meetings/", func(w http.ResponseWriter, r *http.Request) {
+		handleAdminMeetings(w, r, jobQueue)
+	})
+	http.HandleFunc("/api/admin/failures", func(w http.ResponseWriter, r *http.Request) {
+		handleAdminFailures(w, r, jobQueue)
+	})
+	http.HandleFunc("/api/admin/failures/", func(w http.ResponseWriter, r *http.Request) {
+		handleAdminFailures(w, r, jobQueue)
+	})
+	http.HandleFunc("/api/admin/overview", func(w http.ResponseWriter, r *http.Request) {
+		handleAdminOverview(w, r, adminOverviewDeps{
+			roomManager: roomManager,
+			limiters:    []*ratelimit.Limiter{embeddingClient.Limiter, llmClient.Limiter, asrLimiter, translationLimiter, ttsLimiter},
+			services:    serviceURLs,
+			tempDir:     tempDir,
+			errorLog:    errorLog,
+		})
+	})
+	http.HandleFunc("/api/admin/experiments", func(w http.ResponseWriter, r *http.Request) {
+		handleAdminExperiments(w, r, liveExperiment)
+	})
+	http.HandleFunc("/api/admin/flags", handleAdminFlags)
+	http.HandleFunc("/api/admin/flags/", handleAdminFlags)
+	http.HandleFunc("/api/admin/meeting-limits", handleAdminMeetingLimits)
+	http.HandleFunc("/api/admin/meeting-limits/", handleAdminMeetingLimits)
+	http.HandleFunc("/api/admin/user-quotas", handleAdminUserQuotas)
+	http.HandleFunc("/api/admin/user-quotas/", handleAdminUserQuotas)
+	http.HandleFunc("/api/admin/tts-policies", handleAdminTTSPolicies)
+	http.HandleFunc("/api/admin/tts-policies/", handleAdminTTSPolicies)
+	http.HandleFunc("/api/admin/cache", func(w http.ResponseWriter, r *http.Request) {
+		handleAdminCache(w, r, embeddingClient.Cache, llmClient.Cache)
+	})
+	http.HandleFunc("/api/admin/limits", func(w http.ResponseWriter, r *http.Request) {
+		handleAdminLimits(w, r, embeddingClient.Limiter, llmClient.Limiter, asrLimiter, translationLimiter, ttsLimiter)
+	})
+	http.HandleFunc("/api/admin/documents", func(w http.ResponseWriter, r *http.Request) {
+		handleAdminDocuments(w, r, ragProcessor)
+	})
+	http.HandleFunc("/api/admin/documents/", func(w http.ResponseWriter, r *http.Request) {
+		handleAdminDocuments(w, r, ragProcessor)
+	})
+
+	// Recording session management
+	var (
+		recordingMu       sync.Mutex
+		recordingSessions = make(map[string]*session.RecordingSession)
+	)
+
+	http.HandleFunc("/recording/start", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		var req struct {
+			SessionID    string  `json:"sessionId"`
+			SourceLang   string  `json:"sourceLang"`
+			TargetLang   string  `json:"targetLang"`
+			VADThreshold float64 `json:"vadThreshold"` // optional frame threshold override
+			MinRMS       float64 `json:"minRms"`       // optional chunk threshold override
+			session.Latency
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+		if req.VADThreshold < 0 || req.VADThreshold >= 1 || req.MinRMS < 0 || req.MinRMS >= 1 {
+			sendJSONError(w, http.StatusBadRequest, "vadThreshold and minRms must be between 0 and 1")
+			return
+		}
+		if err := req.Latency.Validate(); err != nil {
+			sendJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		recVAD := vadConfig
+		if req.VADThreshold > 0 {
+			recVAD.Threshold = req.VADThreshold
+		}
+		// For recordings the window is the longest chunk and finalizeAfter
+		// the pause that ends one
+		if recVAD.Hangover <= 0 {
+			recVAD.Hangover = vad.DefaultHangover
+		}
+		latency := req.Latency.Over(session.Latency{
+			WindowSeconds:   8,
+			PollIntervalMs:  int(session.DefaultRecordingPollInterval.Milliseconds()),
+			FinalizeAfterMs: int(recVAD.Hangover.Milliseconds()),
+		})
+		recVAD.Hangover = latency.FinalizeAfter()
+		minRMS := recordingMinRMS
+		if req.MinRMS > 0 {
+			minRMS = req.MinRMS
+		}
+
+		// Signed-in users need daily audio left to start recording; each
+		// chunk is charged to it as the recording goes
+		user, err := maybeAuthenticateUserFromRequest(keycloakVerifier, r)
+		if err != nil {
+			sendJSONError(w, http.StatusUnauthorized, "Invalid token")
+			return
+		}
+		var userID *int
+		var meter *quota.Meter
+		if user != nil {
+			userID = &user.ID
+			meter = quota.NewMeter(user.ID)
+		}
+		if !checkQuota(w, userID, quota.Request{}) {
+			return
+		}
+
+		// Create recording session
+		recSession := session.NewRecordingSession(session.RecordingConfig{
+			SessionID:     req.SessionID,
+			SourceLang:    langcode.Normalize(req.SourceLang),
+			TargetLang:    langcode.Normalize(req.TargetLang),
+			ASRClient:     asrClient,
+			Translator:    translator,
+			ProgressMgr:   progressMgr,
+			SampleRate:    16000,
+			WindowSeconds: latency.WindowSeconds,
+			PollInterval:  latency.PollInterval(),
+			Archive:       chunkArchive,
+			VAD:           recVAD,
+			MinRMS:        minRMS,
+			ResumeGrace:   resumeGrace,
+			Meter:         meter,
+		})
+
+		recordingMu.Lock()
+		recordingSessions[req.SessionID] = recSession
+		recordingMu.Unlock()
+
+		// Cleanup after session completes
+		go func() {
+			<-recSession.Done()
+			meter.Flush()
+			time.Sleep(5 * time.Minute)
+			recordingMu.Lock()
+			if recordingSessions[req.SessionID] == recSession {
+				delete(recordingSessions, req.SessionID)
+			}
+			recordingMu.Unlock()
+			log.Printf("Recording session cleaned up: %s", req.SessionID)
+		}()
+
+		log.Printf("Recording session started: %s", req.SessionID)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"sessionId":   req.SessionID,
+			"latency":     latency,
+			"resumeToken": recSession.ResumeToken(),
+		})
+	})
+
+	// Archived audio of a recording chunk: /recording/audio?sessionId=...&ref=...
+	http.HandleFunc("/recording/audio", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		if chunkArchive == nil {
+			sendJSONError(w, http.StatusNotFound, "Audio archive is disabled")
+			return
+		}
+		chunkID, err := strconv.ParseInt(r.URL.Query().Get("ref"), 10, 64)
+		if err != nil || chunkID <= 0 {
+			sendJSONError(w, http.StatusBadRequest, "Invalid audio reference")
+			return
+		}
+		chunk, err := database.GetAudioChunk(chunkID)
+		if err != nil {
+			log.Printf("Failed to load audio chunk %d: %v", chunkID, err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load audio")
+			return
+		}
+		// The session ID is the capability, as for the other /recording routes
+		if chunk == nil || chunk.SessionType != audioarchive.SessionRecording || chunk.SessionID != r.URL.Query().Get("sessionId") {
+			sendJSONError(w, http.StatusNotFound, "Audio not found or expired")
+			return
+		}
+		serveArchivedChunk(w, r, chunkArchive, chunk)
+	})
+
+	http.HandleFunc("/recording/stop", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		var req struct {
+			SessionID string `json:"sessionId"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+
+		recordingMu.Lock()
+		recSession, exists := recordingSessions[req.SessionID]
+		recordingMu.Unlock()
+
+		if !exists {
+			sendJSONError(w, http.StatusNotFound, "Session not found")
+			return
+		}
+
+		totalChunks, err := recSession.Stop()
+		if err != nil {
+			sendJSONError(w, http.StatusInternalServerError, "Failed to stop session")
+			return
+		}
+
+		log.Printf("Recording session stopped: %s, total chunks: %d", req.SessionID, totalChunks)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"totalChunks": totalChunks,
+		})
+	})
+
+	http.HandleFunc("/ws/recording/", func(w http.ResponseWriter, r *http.Request) {
+		pathParts := strings.Split(r.URL.Path, "/")
+		if len(pathParts) < 4 {
+			sendJSONError(w, http.StatusBadRequest, "Invalid session ID")
+			return
+		}
+		sessionID := pathParts[3]
+
+		recordingMu.Lock()
+		recSession, exists := recordingSessions[sessionID]
+		recordingMu.Unlock()
+
+		if !exists {
+			sendJSONError(w, http.StatusNotFound, "Session not found")
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Println("Recording WebSocket upgrade error:", err)
+			return
+		}
+
+		// A client that lost its connection reattaches with its resume
+		// token and the last result index it received
+		if token := r.URL.Query().Get("resumeToken"); token != "" {
+			since, _ := strconv.Atoi(r.URL.Query().Get("since"))
+			if err := recSession.Resume(conn, token, since); err != nil {
+				conn.WriteJSON(map[string]interface{}{"type": "error", "error": err.Error()})
+				conn.Close()
+			}
+			return
+		}
+
+		log.Printf("Recording WebSocket connected: %s", sessionID)
+		recSession.HandleWebSocket(conn)
+	})
+
+	http.HandleFunc("/ws/progress/", func(w http.ResponseWriter, r *http.Request) {
+		// Extract session ID from URL path
+		pathParts := strings.Split(r.URL.Path, "/")
+		if len(pathParts) < 4 {
+			sendJSONError(w, http.StatusBadRequest, "Invalid session ID")
+			return
+		}
+		sessionID := pathParts[3]
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Println("Progress WebSocket upgrade error:", err)
+			return
+		}
+		defer conn.Close()
+
+		progressMgr.Subscribe(sessionID, conn)
+		defer progressMgr.Unsubscribe(sessionID, conn)
 
-	// Otherwise, it's a get meeting info request
-	handleGetMeeting(w, r, roomManager)
-}
+		log.Printf("Progress WebSocket connected for session: %s", sessionID)
 
-func handleLinkParticipant(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, roomCode string) {
-	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
-	if !ok {
-		return
-	}
+		alive := keepalive.Start(conn)
+		defer alive.Stop()
 
-	var req struct {
-		ParticipantID int `json:"participantId"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ParticipantID <= 0 {
-		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
-		return
-	}
+		// Keep connection alive and wait for messages
+		for {
+			_, _, err := conn.ReadMessage()
+			if err != nil {
+				log.Printf("Progress WebSocket read error: %v", err)
+				break
+			}
+			alive.Touch()
+		}
+	})
 
-	mtg, err := getMeetingByCodeOrID(roomCode)
-	if err != nil {
-		log.Printf("Error getting meeting: %v", err)
-		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
-		return
-	}
-	if mtg == nil {
-		sendJSONError(w, http.StatusNotFound, "Meeting not found")
-		return
-	}
+	http.HandleFunc("/progress/", func(w http.ResponseWriter, r *http.Request) {
+		handleProgressEvents(w, r, progressMgr)
+	})
 
-	participant, err := database.GetParticipantByID(req.ParticipantID)
-	if err != nil {
-		log.Printf("Failed to get participant: %v", err)
-		sendJSONError(w, http.StatusInternalServerError, "Failed to find participant")
-		return
-	}
-	if participant == nil || participant.MeetingID != mtg.ID {
-		sendJSONError(w, http.StatusNotFound, "Participant not found")
-		return
-	}
+	http.HandleFunc("/download/", func(w http.ResponseWriter, r *http.Request) {
+		filename := filepath.Base(r.URL.Path)
+		filePath := filepath.Join(tempDir, filename)
 
-	if participant.UserID != nil {
-		if *participant.UserID != user.ID {
-			sendJSONError(w, http.StatusConflict, "Participant already linked")
+		// Outputs of signed-in users are kept in object storage and served
+		// through a presigned URL, looked up among the caller's own files only.
+		// Links cannot set headers, so the token may be passed as ?token=.
+		if minioClient.Enabled() && keycloakVerifier != nil {
+			applyQueryToken(r)
+			var user *database.User
+			if r.Header.Get("Authorization") != "" {
+				var ok bool
+				if user, ok = authenticateUserFromRequest(keycloakVerifier, w, r); !ok {
+					return
+				}
+			}
+			var match *database.UserFileMatch
+			if user != nil {
+				var err error
+				match, err = database.FindUserFileByName(user.ID, minioClient.Bucket(), filename)
+				if err != nil {
+					log.Printf("Download lookup failed for %s: %v", filename, err)
+				}
+			}
+			if match != nil {
+				url, err := minioClient.PresignedDownloadURL(r.Context(), match.FileKey, filename, 0)
+				if err != nil {
+					log.Printf("Presign failed for %s: %v", match.FileKey, err)
+					sendJSONError(w, http.StatusBadGateway, "Failed to prepare download")
+					return
+				}
+				http.Redirect(w, r, url, http.StatusFound)
+				return
+			}
+		}
+
+		// Security check: ensure file exists and is in temp dir
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			sendJSONError(w, http.StatusNotFound, "File not found")
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": true,
-			"linked":  true,
-		})
-		return
-	}
 
-	if err := database.UpdateParticipantUserID(req.ParticipantID, user.ID); err != nil {
-		log.Printf("Failed to link participant: %v", err)
-		sendJSONError(w, http.StatusInternalServerError, "Failed to link participant")
-		return
-	}
+		contentType := "video/mp4"
+		if strings.EqualFold(filepath.Ext(filename), ".wav") {
+			contentType = "audio/wav" // translated speech from audio uploads
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+		http.ServeFile(w, r, filePath)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"linked":  true,
+		// Cleanup after download
+		go func() {
+			time.Sleep(30 * time.Second)
+			os.Remove(filePath)
+		}()
 	})
-}
-
-func handleSpeakerProfiles(w http.ResponseWriter, r *http.Request) {
-	sessionID := strings.TrimPrefix(r.URL.Path, "/api/speaker-profiles/")
-	if sessionID == "" || sessionID == r.URL.Path {
-		sendJSONError(w, http.StatusBadRequest, "Session ID required")
-		return
-	}
 
-	switch r.Method {
-	case http.MethodGet:
-		profiles, err := database.GetSpeakerProfiles(sessionID)
+	// Streaming WebSocket - proxy to ASR streaming service
+	http.HandleFunc("/ws/stream", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		user, err := maybeAuthenticateUserFromRequest(keycloakVerifier, r)
 		if err != nil {
-			log.Printf("Failed to get speaker profiles: %v", err)
-			sendJSONError(w, http.StatusInternalServerError, "Failed to get speaker profiles")
+			sendJSONError(w, http.StatusUnauthorized, "Invalid or expired authentication token")
 			return
 		}
-
-		type profileInfo struct {
-			ProfileID string    `json:"profileId"`
-			Embedding []float32 `json:"embedding"`
-			Count     int       `json:"count"`
-			UpdatedAt string    `json:"updatedAt"`
+		var userID *int
+		if user != nil {
+			userID = &user.ID
 		}
-
-		response := make([]profileInfo, 0, len(profiles))
-		for _, profile := range profiles {
-			response = append(response, profileInfo{
-				ProfileID: profile.ProfileID,
-				Embedding: profile.Embedding,
-				Count:     profile.Count,
-				UpdatedAt: profile.UpdatedAt.Format(time.RFC3339),
-			})
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Println("upgrade:", err)
+			return
 		}
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":  true,
-			"profiles": response,
+		// The session ID is made by the server and sent in the "session" event
+		go srv.HandleStream(conn, session.StreamOptions{
+			Language:   query.Get("language"),
+			TargetLang: query.Get("targetLang"),
+			UserID:     userID,
 		})
-	case http.MethodPut:
-		type profilePayload struct {
-			ProfileID string    `json:"profileId"`
-			Embedding []float32 `json:"embedding"`
-			Count     int       `json:"count"`
+	})
+
+	// High-quality transcript of a finished /ws/stream session
+	http.HandleFunc("/api/stream/transcription/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
 		}
-		var payload struct {
-			Profiles []profilePayload `json:"profiles"`
+		user, err := maybeAuthenticateUserFromRequest(keycloakVerifier, r)
+		if err != nil {
+			sendJSONError(w, http.StatusUnauthorized, "Invalid or expired authentication token")
+			return
 		}
-		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-			sendJSONError(w, http.StatusBadRequest, "Invalid payload")
+		var userID *int
+		if user != nil {
+			userID = &user.ID
+		}
+		sessionID := strings.TrimPrefix(r.URL.Path, "/api/stream/transcription/")
+		status, body, err := srv.FetchStreamTranscript(sessionID, userID)
+		if err != nil {
+			sendJSONError(w, status, err.Error())
 			return
 		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(body)
+	})
 
-		profiles := make([]database.SpeakerProfile, 0, len(payload.Profiles))
-		for _, item := range payload.Profiles {
-			if item.ProfileID == "" || len(item.Embedding) == 0 {
-				continue
-			}
-			count := item.Count
-			if count <= 0 {
-				count = 1
-			}
-			profiles = append(profiles, database.SpeakerProfile{
-				SessionID: sessionID,
-				ProfileID: item.ProfileID,
-				Embedding: item.Embedding,
-				Count:     count,
-			})
+	// Meeting WebSocket - for real-time meeting rooms
+	http.HandleFunc("/ws/meeting/", func(w http.ResponseWriter, r *http.Request) {
+		// Extract meeting ID from URL path
+		pathParts := strings.Split(r.URL.Path, "/")
+		if len(pathParts) < 4 {
+			sendJSONError(w, http.StatusBadRequest, "Invalid meeting ID")
+			return
 		}
+		meetingID := pathParts[3]
 
-		if err := database.ReplaceSpeakerProfiles(sessionID, profiles); err != nil {
-			log.Printf("Failed to persist speaker profiles: %v", err)
-			sendJSONError(w, http.StatusInternalServerError, "Failed to persist speaker profiles")
+		// Get query parameters
+		query := r.URL.Query()
+		participantIDStr := query.Get("participantId")
+		participantName := query.Get("participantName")
+		targetLang := langcode.Normalize(query.Get("targetLang"))
+		minSpeakersStr := query.Get("minSpeakers")
+		maxSpeakersStr := query.Get("maxSpeakers")
+		strictnessStr := query.Get("strictness")
+		interpretLang := langcode.Normalize(query.Get("interpretLang")) // set by the interpreter channel in interpreted meetings
+		sinceSeq, _ := strconv.ParseInt(query.Get("since"), 10, 64)     // last event seq seen, when reconnecting
+
+		// The host token lets the host admit participants from the waiting room
+		hostToken := query.Get("hostToken")
+
+		// Validate parameters
+		if participantIDStr == "" || participantName == "" || targetLang == "" {
+			sendJSONError(w, http.StatusBadRequest, "Missing required parameters: participantId, participantName, targetLang")
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":  true,
-			"profiles": len(profiles),
-		})
-	case http.MethodDelete:
-		if err := database.DeleteSpeakerProfiles(sessionID); err != nil {
-			log.Printf("Failed to delete speaker profiles: %v", err)
-			sendJSONError(w, http.StatusInternalServerError, "Failed to delete speaker profiles")
+		// Parse participant ID
+		var participantID int
+		if _, err := fmt.Sscanf(participantIDStr, "%d", &participantID); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid participant ID")
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": true,
-		})
-	default:
-		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	}
-}
 
-func handleSpeakerProfileCleanup(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
+		minSpeakers := 0
+		if minSpeakersStr != "" {
+			if parsed, err := strconv.Atoi(minSpeakersStr); err == nil {
+				minSpeakers = parsed
+			}
+		}
 
-	ttlSeconds := int64(0)
-	if ttlParam := r.URL.Query().Get("ttl_seconds"); ttlParam != "" {
-		if parsed, err := strconv.ParseInt(ttlParam, 10, 64); err == nil {
-			ttlSeconds = parsed
+		maxSpeakers := 0
+		if maxSpeakersStr != "" {
+			if parsed, err := strconv.Atoi(maxSpeakersStr); err == nil {
+				maxSpeakers = parsed
+			}
+		}
+
+		strictness := 0.0
+		if strictnessStr != "" {
+			if parsed, err := strconv.ParseFloat(strictnessStr, 64); err == nil {
+				strictness = parsed
+			}
+		}
+
+		// The connection must hold the participant's join token, and a
+		// signed-in participant must be connected as their own user
+		user, err := maybeAuthenticateUserFromRequest(keycloakVerifier, r)
+		if err != nil {
+			sendJSONError(w, http.StatusUnauthorized, "Invalid or expired authentication token")
+			return
+		}
+		dbParticipant, err := database.AuthenticateParticipant(meetingID, participantID, query.Get("participantToken"))
+		if err != nil {
+			log.Printf("Failed to authenticate participant %d: %v", participantID, err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to check participant")
+			return
+		}
+		if dbParticipant == nil {
+			sendJSONError(w, http.StatusForbidden, "Not a participant of this meeting")
+			return
+		}
+		var userID *int
+		if user != nil {
+			userID = &user.ID
+		}
+		if dbParticipant.UserID != nil && (userID == nil || *userID != *dbParticipant.UserID) {
+			sendJSONError(w, http.StatusForbidden, "This participant belongs to another user")
+			return
+		}
+		if interpretLang != "" && !meeting.CanInterpret(meetingID, userID, hostToken) {
+			sendJSONError(w, http.StatusForbidden, "Only hosts, co-hosts and designated interpreters can join as the interpreter")
+			return
 		}
-	}
 
-	if ttlSeconds <= 0 {
-		ttlEnv := os.Getenv("SPEAKER_PROFILE_DB_TTL_SECONDS")
-		if ttlEnv != "" {
-			if parsed, err := strconv.ParseInt(ttlEnv, 10, 64); err == nil {
-				ttlSeconds = parsed
-			}
+		// Upgrade to WebSocket
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Printf("Meeting WebSocket upgrade error: %v", err)
+			return
 		}
-	}
-
-	if ttlSeconds <= 0 {
-		sendJSONError(w, http.StatusBadRequest, "ttl_seconds is required")
-		return
-	}
-
-	cutoff := time.Now().Add(-time.Duration(ttlSeconds) * time.Second)
-	deleted, err := database.DeleteExpiredSpeakerProfiles(cutoff)
-	if err != nil {
-		log.Printf("Failed to delete expired speaker profiles: %v", err)
-		sendJSONError(w, http.StatusInternalServerError, "Failed to delete expired speaker profiles")
-		return
-	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":     true,
-		"deletedRows": deleted,
+		// Handle the connection
+		go roomManager.HandleMeetingWebSocket(conn, meetingID, participantID, userID, participantName, targetLang, minSpeakers, maxSpeakers, strictness, interpretLang, sinceSeq, hostToken, query.Get("resumeToken"))
 	})
-}
-
-func main() {
-	// Initialize database
-	log.Println("Initializing database connection...")
-	if err := database.Init(); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
-	}
-	defer database.Close()
-	log.Println("Database connection established")
 
-	// Create RAG processor (will be initialized after embedding client is created)
-	var roomManager *meeting.RoomManager
-
-	log.Println("Meeting room manager will be initialized after RAG components")
-
-	// Optional speaker profile cleanup job
-	if ttlEnv := os.Getenv("SPEAKER_PROFILE_DB_TTL_SECONDS"); ttlEnv != "" {
-		if ttlSeconds, err := strconv.ParseInt(ttlEnv, 10, 64); err == nil && ttlSeconds > 0 {
-			intervalSeconds := int64(300)
-			if intervalEnv := os.Getenv("SPEAKER_PROFILE_DB_CLEANUP_INTERVAL_SECONDS"); intervalEnv != "" {
-				if parsed, err := strconv.ParseInt(intervalEnv, 10, 64); err == nil && parsed > 0 {
-					intervalSeconds = parsed
+	// gRPC API for backend integrators (api/proto/captions.proto); with
+	// Keycloak configured every call needs a bearer token. 0 disables it.
+	if grpcPort := getEnvInt("GRPC_PORT", 9090); grpcPort > 0 {
+		grpcConfig := grpcapi.Config{
+			ASR:        asrClient,
+			Translator: translator,
+			TTS:        ttsClient,
+			Live:       srv,
+			Progress:   progressMgr,
+			StartJob: func(user *database.User, upload grpcapi.Upload) (*database.Job, error) {
+				return startAPIJob(videoProcessor, jobQueue, user, upload)
+			},
+		}
+		if keycloakVerifier != nil {
+			grpcConfig.Authenticate = func(ctx context.Context, token string) (*database.User, error) {
+				claims, err := keycloakVerifier.VerifyToken(ctx, token)
+				if err != nil {
+					return nil, err
 				}
+				return upsertUserFromClaims(claims)
 			}
-
-			go func() {
-				ticker := time.NewTicker(time.Duration(intervalSeconds) * time.Second)
-				defer ticker.Stop()
-				for range ticker.C {
-					cutoff := time.Now().Add(-time.Duration(ttlSeconds) * time.Second)
-					deleted, err := database.DeleteExpiredSpeakerProfiles(cutoff)
-					if err != nil {
-						log.Printf("Speaker profile cleanup failed: %v", err)
-						continue
-					}
-					if deleted > 0 {
-						log.Printf("Speaker profile cleanup removed %d row(s)", deleted)
-					}
-				}
-			}()
 		}
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", grpcPort))
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC on :%d: %v", grpcPort, err)
+		}
+		go func() {
+			log.Printf("gRPC listening on :%d", grpcPort)
+			if err := grpcapi.NewGRPCServer(grpcConfig).Serve(listener); err != nil {
+				log.Printf("gRPC server stopped: %v", err)
+			}
+		}()
 	}
 
-	// Check if ffmpeg is installed
-	if err := video.CheckFFmpegInstalled(); err != nil {
-		log.Printf("Warning: %v - Video upload feature will not work", err)
-	}
-
-	// Create temp directory for video processing
-	tempDir := "./temp"
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		log.Fatalf("Failed to create temp directory: %v", err)
+	// Comma-separated path prefixes left open while Keycloak is configured,
+	// e.g. AUTH_PUBLIC_ROUTES=/ws/meeting/,/api/meetings/ for guest meetings in dev
+	var publicRoutes []string
+	for _, prefix := range strings.Split(getEnv("AUTH_PUBLIC_ROUTES", ""), ",") {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			publicRoutes = append(publicRoutes, prefix)
+		}
 	}
 
-	asrBaseURL := getEnv("ASR_BASE_URL", "http://127.0.0.1:8003")
-	translationBaseURL := getEnv("TRANSLATION_BASE_URL", "http://127.0.0.1:8004")
-	ttsBaseURL := getEnv("TTS_BASE_URL", "http://127.0.0.1:8005")
-	embeddingBaseURL := getEnv("EMBEDDING_BASE_URL", "http://127.0.0.1:8006")
-	llmBaseURL := getEnv("LLM_BASE_URL", "http://127.0.0.1:8007")
-
-	srv := session.NewServer(session.Config{
-		ASRBaseURL:    asrBaseURL,
-		PollInterval:  800 * time.Millisecond,
-		WindowSeconds: 8,
-		FinalizeAfter: 500 * time.Millisecond, // Reduced from 900ms for faster finalization
-	})
+	log.Println("listening on :8080")
+	log.Fatal(http.ListenAndServe(":8080", requireAuth(keycloakVerifier, publicRoutes, socketAuth{
+		tickets:     socketTickets,
+		queryTokens: getEnv("WS_QUERY_TOKENS", "false") == "true",
+	}, http.DefaultServeMux)))
+}
 
-	// Create progress manager
-	progressMgr := progress.NewManager()
+// translateWithChunking wraps the translator to handle texts larger than 5000 characters
+func translateWithChunking(t translate.Translator, text, sourceLang, targetLang string) (string, error) {
+	const maxChunkSize = 5000
 
-	// Create video processor
-	videoProcessor := video.NewProcessor(tempDir)
+	// Check if the translator is an HTTPTranslator with ChunkAndTranslate method
+	if httpTrans, ok := t.(*translate.HTTPTranslator); ok {
+		return httpTrans.ChunkAndTranslate(text, sourceLang, targetLang)
+	}
 
-	// Create ASR client for batch processing
-	asrClient := asr.New(asrBaseURL)
+	// Fallback to regular translation for other translator types
+	return t.TranslateWithSource(text, sourceLang, targetLang)
+}
 
-	// Create translator
-	translator := &translate.HTTPTranslator{
-		BaseURL: translationBaseURL,
+// hookedTranscription runs an upload's transcription through the
+// post-transcription hooks; text a hook drops becomes ""
+func hookedTranscription(source, sessionID, text, language string) string {
+	if strings.TrimSpace(text) == "" {
+		return text
 	}
+	text, _ = hooks.Run(hooks.PostTranscription, hooks.Event{Source: source, SessionID: sessionID, Text: text, Language: language, Final: true})
+	return text
+}
 
-	// Create TTS client
-	ttsClient := tts.New(ttsBaseURL)
-
-	// Create RAG components (embedding + LLM clients)
-	embeddingClient := embedding.New(embeddingBaseURL)
-	llmClient := llm.New(llmBaseURL)
-	ragProcessor := rag.NewProcessor(embeddingClient)
-	ragQueryEngine := rag.NewQueryEngine(embeddingClient, llmClient)
-	log.Println("RAG components initialized")
+// hookedTranslation translates an upload's text through the translation
+// hooks; text a hook drops translates to ""
+func hookedTranslation(t translate.Translator, source, sessionID, text, sourceLang, targetLang string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", nil
+	}
+	translation, _, err := hooks.Translate(hooks.Event{
+		Source:         source,
+		SessionID:      sessionID,
+		Text:           text,
+		SourceLanguage: sourceLang,
+		TargetLanguage: targetLang,
+		Final:          true,
+	}, func(text string) (string, error) {
+		return translateWithChunking(t, text, sourceLang, targetLang)
+	})
+	return translation, err
+}
 
-	// Initialize RoomManager with RAG processor
-	roomManager = meeting.NewRoomManager(ragProcessor)
-	log.Println("Meeting room manager initialized with RAG support")
+// hookedSpeech runs text about to be voiced through the pre-TTS hooks;
+// text a hook drops becomes ""
+func hookedSpeech(source, sessionID, text, language string) string {
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	text, _ = hooks.Run(hooks.PreTTS, hooks.Event{Source: source, SessionID: sessionID, Text: text, Language: language, Final: true})
+	return strings.TrimSpace(text)
+}
 
-	keycloakVerifier, err := auth.NewKeycloakVerifierFromEnv()
-	if err != nil {
-		log.Printf("Keycloak auth disabled: %v", err)
+func getEnvInt(key string, fallback int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
 	}
+	return fallback
+}
 
-	minioClient, err := storage.NewMinioFromEnv()
-	if err != nil {
-		log.Printf("MinIO disabled: %v", err)
+func getEnvFloat(key string, fallback float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
 	}
+	return fallback
+}
 
-	// Static file server
-	http.Handle("/", http.FileServer(http.Dir("./web")))
+func getEnv(key, fallback string) string {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+	return value
+}
 
-	// Redirects for new file structure
-	http.HandleFunc("/meeting.html", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "./web/features/meeting/meeting-create.html")
-	})
-	http.HandleFunc("/meeting-join.html", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "./web/features/meeting/meeting-join.html")
-	})
-	http.HandleFunc("/meeting-room.html", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "./web/features/meeting/meeting-room.html")
-	})
-	http.HandleFunc("/streaming.html", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "./web/features/streaming/streaming.html")
-	})
-	http.HandleFunc("/recording.html", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "./web/features/recording/recording.html")
-	})
-	http.HandleFunc("/video.html", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "./web/features/video/video.html")
-	})
+// handleChatSessions creates a new chat session for a meeting
+func handleChatSessions(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
+	if r.Method != http.MethodPost {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
 
-	// JavaScript file redirects for compatibility
-	http.HandleFunc("/meeting-room.js", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/javascript")
-		http.ServeFile(w, r, "./web/features/meeting/meeting-room.js")
-	})
-	http.HandleFunc("/assets/js/audio-processor.js", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/javascript")
-		http.ServeFile(w, r, "./web/assets/js/audio-processor.js")
-	})
-	http.HandleFunc("/assets/js/utils.js", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/javascript")
-		http.ServeFile(w, r, "./web/assets/js/utils.js")
-	})
+	var req struct {
+		MeetingID  string `json:"meetingId"`
+		SourceType string `json:"sourceType,omitempty"`
+		SourceID   string `json:"sourceId,omitempty"`
+		Language   string `json:"language"`
+	}
 
-	http.HandleFunc("/api/speaker-profiles/cleanup", handleSpeakerProfileCleanup)
-	http.HandleFunc("/api/speaker-profiles/", handleSpeakerProfiles)
-	http.HandleFunc("/api/auth/keycloak", handleKeycloakLogin(keycloakVerifier))
-	http.HandleFunc("/api/history/video", handleCreateVideoHistory(keycloakVerifier))
-	http.HandleFunc("/api/history/audio", handleCreateAudioHistory(keycloakVerifier))
-	http.HandleFunc("/api/history/streaming", handleCreateStreamingHistory(keycloakVerifier))
-	http.HandleFunc("/api/files", handleCreateUserFile(keycloakVerifier))
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
 
-	// User meetings history API endpoints
-	http.HandleFunc("/api/users/me/meetings", func(w http.ResponseWriter, r *http.Request) {
-		handleListUserMeetings(w, r, keycloakVerifier)
-	})
-	http.HandleFunc("/api/users/me/meetings/", func(w http.ResponseWriter, r *http.Request) {
-		handleGetUserMeetingDetail(w, r, keycloakVerifier)
-	})
+	req.Language = langcode.Normalize(req.Language)
+	if req.Language == "" {
+		sendJSONError(w, http.StatusBadRequest, "Missing required fields: meetingId, language")
+		return
+	}
 
-	// Meeting Access Control API endpoints
-	http.HandleFunc("/api/meetings/access/list/", func(w http.ResponseWriter, r *http.Request) {
-		handleListMeetingAccess(w, r, keycloakVerifier)
-	})
-	http.HandleFunc("/api/meetings/access/grant", func(w http.ResponseWriter, r *http.Request) {
-		handleGrantMeetingAccess(w, r, keycloakVerifier)
-	})
-	http.HandleFunc("/api/meetings/access/update", func(w http.ResponseWriter, r *http.Request) {
-		handleUpdateMeetingAccess(w, r, keycloakVerifier)
-	})
-	http.HandleFunc("/api/meetings/access/revoke", func(w http.ResponseWriter, r *http.Request) {
-		handleRevokeMeetingAccess(w, r, keycloakVerifier)
-	})
-	http.HandleFunc("/api/meetings/participants/available/", func(w http.ResponseWriter, r *http.Request) {
-		handleGetAvailableParticipants(w, r, keycloakVerifier)
-	})
+	resolvedID, ok := resolveChatSourceID(w, r, keycloakVerifier, req.SourceType, req.SourceID, req.MeetingID)
+	if !ok {
+		return
+	}
+	req.MeetingID = resolvedID
 
-	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		conn, err := upgrader.Upgrade(w, r, nil)
-		if err != nil {
-			log.Println("upgrade:", err)
-			return
+	// Optionally extract user ID from auth token
+	var userID *int
+	if keycloakVerifier != nil {
+		tokenStr := r.Header.Get("Authorization")
+		if tokenStr != "" && len(tokenStr) > 7 && tokenStr[:7] == "Bearer " {
+			tokenStr = tokenStr[7:]
+			claims, err := keycloakVerifier.VerifyToken(r.Context(), tokenStr)
+			if err == nil {
+				if preferredUsername, ok := claims["preferred_username"].(string); ok && preferredUsername != "" {
+					user, _ := database.GetUserByUsername(preferredUsername)
+					if user != nil {
+						userID = &user.ID
+					}
+				}
+			}
 		}
-		go srv.HandleConn(conn)
-	})
+	}
 
-	http.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
-		handleVideoUpload(w, r, videoProcessor, asrClient, translator, ttsClient, progressMgr, minioClient, keycloakVerifier)
-	})
+	session, err := database.CreateChatSession(req.MeetingID, req.Language, userID)
+	if err != nil {
+		log.Printf("Failed to create chat session: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to create session")
+		return
+	}
 
-	http.HandleFunc("/upload-audio", func(w http.ResponseWriter, r *http.Request) {
-		handleAudioUpload(w, r, videoProcessor, asrClient, translator, progressMgr, minioClient, keycloakVerifier)
-	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
 
-	// Meeting API endpoints
-	http.HandleFunc("/api/meetings", func(w http.ResponseWriter, r *http.Request) {
-		handleCreateMeeting(w, r, keycloakVerifier)
-	})
-	http.HandleFunc("/api/meetings/", func(w http.ResponseWriter, r *http.Request) {
-		handleMeetingOperations(w, r, roomManager, llmClient, keycloakVerifier)
-	})
+// handleChatSessionOperations serves read-only access to chat sessions:
+//
+//	GET  /api/chat/sessions/{sessionId}                         - session with messages
+//	GET  /api/chat/sessions/{sessionId}/export?format=markdown  - export (markdown or json)
+//	POST /api/chat/sessions/{sessionId}/share                   - {"shared": bool}, owner only
+func handleChatSessionOperations(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
+	pathParts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/chat/sessions/"), "/"), "/")
+	if pathParts[0] == "" || len(pathParts) > 2 {
+		sendJSONError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	sessionID := pathParts[0]
+	action := ""
+	if len(pathParts) == 2 {
+		action = pathParts[1]
+	}
 
-	// RAG Chat API endpoints
-	http.HandleFunc("/api/chat/sessions", func(w http.ResponseWriter, r *http.Request) {
-		handleChatSessions(w, r, keycloakVerifier)
-	})
-	http.HandleFunc("/api/chat/query", func(w http.ResponseWriter, r *http.Request) {
-		handleChatQuery(w, r, ragQueryEngine, keycloakVerifier)
-	})
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
 
-	// Diagnostics API endpoints (localhost only)
-	http.HandleFunc("/api/diagnostics", handleDiagnostics)
-	http.HandleFunc("/api/diagnostics/services/", handleDiagnosticsService)
+	chatSession, err := database.GetChatSession(sessionID)
+	if err != nil {
+		sendJSONError(w, http.StatusNotFound, "Chat session not found")
+		return
+	}
 
-	// Recording session management
-	var (
-		recordingMu       sync.Mutex
-		recordingSessions = make(map[string]*session.RecordingSession)
-	)
+	canRead, canShare, err := chatSessionAccess(chatSession, user.ID)
+	if err != nil {
+		log.Printf("Failed to check chat session access: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
 
-	http.HandleFunc("/recording/start", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != "POST" {
-			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	switch {
+	case action == "share" && r.Method == http.MethodPost:
+		if !canShare {
+			sendJSONError(w, http.StatusForbidden, "Only the session owner can change sharing")
 			return
 		}
 
-		var req struct {
-			SessionID  string `json:"sessionId"`
-			SourceLang string `json:"sourceLang"`
-			TargetLang string `json:"targetLang"`
+		req := struct {
+			Shared *bool `json:"shared"`
+		}{}
+		if r.ContentLength > 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				sendJSONError(w, http.StatusBadRequest, "Invalid request")
+				return
+			}
 		}
+		shared := req.Shared == nil || *req.Shared
 
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+		if err := database.SetChatSessionShared(sessionID, shared); err != nil {
+			log.Printf("Failed to update chat session sharing: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to update sharing")
 			return
 		}
 
-		// Create recording session
-		recSession := session.NewRecordingSession(session.RecordingConfig{
-			SessionID:     req.SessionID,
-			SourceLang:    req.SourceLang,
-			TargetLang:    req.TargetLang,
-			ASRClient:     asrClient,
-			Translator:    translator,
-			ProgressMgr:   progressMgr,
-			SampleRate:    16000,
-			WindowSeconds: 8,
-		})
-
-		recordingMu.Lock()
-		recordingSessions[req.SessionID] = recSession
-		recordingMu.Unlock()
-
-		log.Printf("Recording session started: %s", req.SessionID)
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
+		writeJSON(w, map[string]interface{}{
 			"success":   true,
-			"sessionId": req.SessionID,
+			"sessionId": sessionID,
+			"isShared":  shared,
 		})
-	})
 
-	http.HandleFunc("/recording/stop", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != "POST" {
-			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	case (action == "" || action == "export") && r.Method == http.MethodGet:
+		if !canRead {
+			sendJSONError(w, http.StatusForbidden, "Access denied to this chat session")
 			return
 		}
 
-		var req struct {
-			SessionID string `json:"sessionId"`
+		export, err := rag.BuildChatExport(chatSession)
+		if err != nil {
+			log.Printf("Failed to export chat session: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load chat session")
+			return
 		}
 
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			sendJSONError(w, http.StatusBadRequest, "Invalid request")
+		if action == "export" && r.URL.Query().Get("format") != "json" {
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"chat_%s.md\"", sessionID))
+			w.Write([]byte(export.Markdown()))
 			return
 		}
+		if action == "export" {
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"chat_%s.json\"", sessionID))
+		}
+		writeJSON(w, export)
 
-		recordingMu.Lock()
-		recSession, exists := recordingSessions[req.SessionID]
-		recordingMu.Unlock()
+	default:
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
 
-		if !exists {
-			sendJSONError(w, http.StatusNotFound, "Session not found")
-			return
+// chatSessionAccess derives read/share rights from ownership and meeting roles.
+// Owners can read and share. Shared meeting sessions are readable by anyone with
+// viewer access to the meeting. Anonymous meeting sessions fall to meeting editors.
+func chatSessionAccess(chatSession *database.ChatSession, userID int) (canRead, canShare bool, err error) {
+	if chatSession.UserID != nil && *chatSession.UserID == userID {
+		return true, true, nil
+	}
+	if database.RAGSourceType(chatSession.MeetingID) != database.SourceTypeMeeting {
+		return false, false, nil
+	}
+
+	if chatSession.UserID == nil {
+		isEditor, err := database.UserHasMinimumRole(userID, chatSession.MeetingID, database.RoleEditor)
+		if err != nil {
+			return false, false, err
+		}
+		if isEditor {
+			return true, true, nil
 		}
+	}
 
-		totalChunks, err := recSession.Stop()
+	if chatSession.IsShared {
+		isViewer, err := database.UserHasMinimumRole(userID, chatSession.MeetingID, database.RoleViewer)
 		if err != nil {
-			sendJSONError(w, http.StatusInternalServerError, "Failed to stop session")
-			return
+			return false, false, err
 		}
+		return isViewer, false, nil
+	}
 
-		log.Printf("Recording session stopped: %s, total chunks: %d", req.SessionID, totalChunks)
+	return false, false, nil
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":     true,
-			"totalChunks": totalChunks,
-		})
-	})
+// handleChatQuery performs a RAG query on a meeting transcript. With stream
+// set the answer is sent as server-sent events while it is generated.
+func handleChatQuery(w http.ResponseWriter, r *http.Request, queryEngine *rag.QueryEngine, keycloakVerifier *auth.KeycloakVerifier, stream bool) {
+	if r.Method != http.MethodPost {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	flusher, canFlush := w.(http.Flusher)
+	if stream && !canFlush {
+		sendJSONError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
 
-	http.HandleFunc("/ws/recording/", func(w http.ResponseWriter, r *http.Request) {
-		pathParts := strings.Split(r.URL.Path, "/")
-		if len(pathParts) < 4 {
-			sendJSONError(w, http.StatusBadRequest, "Invalid session ID")
-			return
-		}
-		sessionID := pathParts[3]
+	var req struct {
+		SessionID     string   `json:"sessionId"`
+		Question      string   `json:"question"`
+		MeetingID     string   `json:"meetingId"`
+		SourceType    string   `json:"sourceType,omitempty"`
+		SourceID      string   `json:"sourceId,omitempty"`
+		Language      string   `json:"language"`
+		ChatLanguage  string   `json:"chatLanguage,omitempty"`
+		TopK          int      `json:"topK,omitempty"`
+		MinSimilarity *float64 `json:"minSimilarity,omitempty"`
+		NoCache       bool     `json:"noCache,omitempty"` // skip cached embeddings/answers
+	}
 
-		recordingMu.Lock()
-		recSession, exists := recordingSessions[sessionID]
-		recordingMu.Unlock()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
 
-		if !exists {
-			sendJSONError(w, http.StatusNotFound, "Session not found")
-			return
-		}
+	req.Language = langcode.Normalize(req.Language)
+	req.ChatLanguage = langcode.Normalize(req.ChatLanguage)
+	if req.SessionID == "" || req.Question == "" || req.Language == "" {
+		sendJSONError(w, http.StatusBadRequest, "Missing required fields: sessionId, question, meetingId, language")
+		return
+	}
 
-		conn, err := upgrader.Upgrade(w, r, nil)
+	resolvedID, ok := resolveChatSourceID(w, r, keycloakVerifier, req.SourceType, req.SourceID, req.MeetingID)
+	if !ok {
+		return
+	}
+	req.MeetingID = resolvedID
+
+	var minSimilarity float64
+	req.TopK, minSimilarity = chatRetrievalSettings(queryEngine, req.MeetingID, req.TopK, req.MinSimilarity)
+
+	// Default chat language to English if not provided (backward compatibility)
+	if req.ChatLanguage == "" {
+		req.ChatLanguage = "en"
+	}
+
+	// Save user question to database
+	userMsg := &database.ChatMessage{
+		SessionID: req.SessionID,
+		Role:      "user",
+		Content:   req.Question,
+	}
+	if err := database.SaveChatMessage(userMsg); err != nil {
+		log.Printf("Failed to save user message: %v", err)
+	}
+
+	// Update session activity
+	database.UpdateChatSessionActivity(req.SessionID)
+
+	// Perform RAG query with specified chat language
+	engine := queryEngine
+	if req.NoCache {
+		engine = queryEngine.WithoutCache()
+	}
+	user, _ := maybeAuthenticateUserFromRequest(keycloakVerifier, r)
+	engine = engine.WithHybrid(flags.Enabled(flags.HybridRetrieval, flags.SubjectForUser(user)))
+
+	// Streamed answers arrive as "token" events, then one "done" event with
+	// the same payload as the JSON response, or an "error" event
+	sendEvent := func(event string, payload interface{}) error {
+		data, err := json.Marshal(payload)
 		if err != nil {
-			log.Println("Recording WebSocket upgrade error:", err)
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+	var onToken func(string) error
+	if stream {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		onToken = func(text string) error {
+			return sendEvent("token", map[string]string{"text": text})
+		}
+	}
+
+	result, err := engine.QueryDetailedStream(r.Context(), req.MeetingID, req.Language, req.ChatLanguage, req.Question, req.TopK, minSimilarity, onToken)
+	if err != nil {
+		log.Printf("RAG query failed: %v", err)
+		if stream {
+			sendEvent("error", map[string]string{"error": fmt.Sprintf("Query failed: %v", err)})
 			return
 		}
+		sendJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Query failed: %v", err))
+		return
+	}
+	answer, chunkIDs := result.Answer, result.ChunkIDs()
 
-		log.Printf("Recording WebSocket connected: %s", sessionID)
-		recSession.HandleWebSocket(conn)
+	// Save assistant response to database
+	assistantMsg := &database.ChatMessage{
+		SessionID:       req.SessionID,
+		Role:            "assistant",
+		Content:         answer,
+		ContextChunkIDs: chunkIDs,
+	}
+	if err := database.SaveChatMessage(assistantMsg); err != nil {
+		log.Printf("Failed to save assistant message: %v", err)
+	}
 
-		// Cleanup after session completes
-		go func() {
-			time.Sleep(5 * time.Minute)
-			recordingMu.Lock()
-			delete(recordingSessions, sessionID)
-			recordingMu.Unlock()
-			log.Printf("Recording session cleaned up: %s", sessionID)
-		}()
-	})
+	// Update session activity again
+	database.UpdateChatSessionActivity(req.SessionID)
 
-	http.HandleFunc("/ws/progress/", func(w http.ResponseWriter, r *http.Request) {
-		// Extract session ID from URL path
-		pathParts := strings.Split(r.URL.Path, "/")
-		if len(pathParts) < 4 {
-			sendJSONError(w, http.StatusBadRequest, "Invalid session ID")
-			return
-		}
-		sessionID := pathParts[3]
+	response := map[string]interface{}{
+		"answer":    answer,
+		"chunkIds":  chunkIDs,
+		"citations": result.Citations,
+		"grounding": result.Grounding,
+		"retrieval": map[string]interface{}{
+			"topK":          req.TopK,
+			"minSimilarity": minSimilarity,
+		},
+		"sessionId": req.SessionID,
+	}
 
-		conn, err := upgrader.Upgrade(w, r, nil)
+	if stream {
+		sendEvent("done", response)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// chatRetrievalSettings resolves topK and the similarity cutoff of a chat
+// query: request overrides, then meeting defaults, then server defaults
+func chatRetrievalSettings(queryEngine *rag.QueryEngine, sourceID string, topK int, minSimilarity *float64) (int, float64) {
+	cutoff := queryEngine.MinSimilarity
+	if database.RAGSourceType(sourceID) == database.SourceTypeMeeting {
+		settings, err := database.GetMeetingRAGSettings(sourceID)
 		if err != nil {
-			log.Println("Progress WebSocket upgrade error:", err)
-			return
+			log.Printf("Failed to load meeting RAG settings: %v", err)
+		} else {
+			if topK == 0 && settings.TopK != nil {
+				topK = *settings.TopK
+			}
+			if settings.MinSimilarity != nil {
+				cutoff = *settings.MinSimilarity
+			}
 		}
-		defer conn.Close()
+	}
+	if minSimilarity != nil {
+		cutoff = *minSimilarity
+	}
 
-		progressMgr.Subscribe(sessionID, conn)
-		defer progressMgr.Unsubscribe(sessionID, conn)
+	// Default to top 5 chunks
+	if topK <= 0 {
+		topK = 5
+	}
+	return topK, cutoff
+}
 
-		log.Printf("Progress WebSocket connected for session: %s", sessionID)
+// handleMeetingChat serves RAG chat scoped to one meeting:
+//
+//	GET  /api/meetings/{roomCode}/chat                         - the caller's sessions and shared ones
+//	POST /api/meetings/{roomCode}/chat                         - {"language": "en"} creates a session
+//	GET  /api/meetings/{roomCode}/chat/{sessionId}             - session with messages and cited chunks
+//	POST /api/meetings/{roomCode}/chat/{sessionId}/messages    - {"question": "...", "chatLanguage", "topK", "minSimilarity"}
+//
+// Questions are answered with the session's recent messages as context, and
+// answers carry the cited chunks with speaker and timestamp. Viewer role.
+func handleMeetingChat(w http.ResponseWriter, r *http.Request, queryEngine *rag.QueryEngine, keycloakVerifier *auth.KeycloakVerifier, roomCode string, rest []string) {
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
 
-		// Keep connection alive and wait for messages
-		for {
-			_, _, err := conn.ReadMessage()
+	mtg, err := getMeetingByCodeOrID(roomCode)
+	if err != nil {
+		log.Printf("Error getting meeting: %v", err)
+		sendJSONError(w, http.StatusNotFound, "Failed to find meeting")
+		return
+	}
+	if mtg == nil {
+		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	allowed, err := database.UserHasMinimumRole(user.ID, mtg.ID, database.RoleViewer)
+	if err != nil {
+		log.Printf("Failed to check meeting role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+	if !allowed {
+		sendJSONError(w, http.StatusForbidden, "Access denied to this meeting")
+		return
+	}
+
+	if len(rest) == 0 || rest[0] == "" {
+		switch r.Method {
+		case http.MethodGet:
+			sessions, err := database.ListChatSessions(mtg.ID, user.ID)
+			if err != nil {
+				log.Printf("Failed to list chat sessions: %v", err)
+				sendJSONError(w, http.StatusInternalServerError, "Failed to list chat sessions")
+				return
+			}
+			writeJSON(w, map[string]interface{}{"success": true, "sessions": sessions})
+		case http.MethodPost:
+			var req struct {
+				Language string `json:"language"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+				return
+			}
+			req.Language = langcode.Normalize(req.Language)
+			if req.Language == "" {
+				sendJSONError(w, http.StatusBadRequest, "language is required")
+				return
+			}
+			session, err := database.CreateChatSession(mtg.ID, req.Language, &user.ID)
 			if err != nil {
-				log.Printf("Progress WebSocket read error: %v", err)
-				break
+				log.Printf("Failed to create chat session: %v", err)
+				sendJSONError(w, http.StatusInternalServerError, "Failed to create session")
+				return
 			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "session": session})
+		default:
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		}
-	})
+		return
+	}
 
-	http.HandleFunc("/download/", func(w http.ResponseWriter, r *http.Request) {
-		filename := filepath.Base(r.URL.Path)
-		filePath := filepath.Join(tempDir, filename)
+	chatSession, err := database.GetChatSession(rest[0])
+	if err != nil || chatSession.MeetingID != mtg.ID {
+		sendJSONError(w, http.StatusNotFound, "Chat session not found")
+		return
+	}
+	canRead, canAsk, err := chatSessionAccess(chatSession, user.ID)
+	if err != nil {
+		log.Printf("Failed to check chat session access: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
 
-		// Security check: ensure file exists and is in temp dir
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			sendJSONError(w, http.StatusNotFound, "File not found")
+	switch {
+	case len(rest) == 1 && r.Method == http.MethodGet:
+		if !canRead {
+			sendJSONError(w, http.StatusForbidden, "Access denied to this chat session")
 			return
 		}
-
-		w.Header().Set("Content-Type", "video/mp4")
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-		http.ServeFile(w, r, filePath)
-
-		// Cleanup after download
-		go func() {
-			time.Sleep(30 * time.Second)
-			os.Remove(filePath)
-		}()
-	})
-
-	// Streaming WebSocket - proxy to ASR streaming service
-	http.HandleFunc("/ws/stream", func(w http.ResponseWriter, r *http.Request) {
-		log.Println("Streaming WebSocket connection requested")
-		// Note: Clients should connect directly to ws://localhost:8003/stream
-		sendJSONError(w, http.StatusOK, "Connect to ws://localhost:8003/stream")
-	})
-
-	// Meeting WebSocket - for real-time meeting rooms
-	http.HandleFunc("/ws/meeting/", func(w http.ResponseWriter, r *http.Request) {
-		// Extract meeting ID from URL path
-		pathParts := strings.Split(r.URL.Path, "/")
-		if len(pathParts) < 4 {
-			sendJSONError(w, http.StatusBadRequest, "Invalid meeting ID")
+		history, err := rag.BuildChatExport(chatSession)
+		if err != nil {
+			log.Printf("Failed to load chat session: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load chat session")
 			return
 		}
-		meetingID := pathParts[3]
-
-		// Get query parameters
-		query := r.URL.Query()
-		participantIDStr := query.Get("participantId")
-		participantName := query.Get("participantName")
-		targetLang := query.Get("targetLang")
-		minSpeakersStr := query.Get("minSpeakers")
-		maxSpeakersStr := query.Get("maxSpeakers")
-		strictnessStr := query.Get("strictness")
+		writeJSON(w, map[string]interface{}{"success": true, "session": history.Session, "messages": history.Messages})
 
-		// Validate parameters
-		if participantIDStr == "" || participantName == "" || targetLang == "" {
-			sendJSONError(w, http.StatusBadRequest, "Missing required parameters: participantId, participantName, targetLang")
+	case len(rest) == 2 && rest[1] == "messages" && r.Method == http.MethodPost:
+		if !canAsk {
+			sendJSONError(w, http.StatusForbidden, "Only the session owner can ask questions")
 			return
 		}
-
-		// Parse participant ID
-		var participantID int
-		if _, err := fmt.Sscanf(participantIDStr, "%d", &participantID); err != nil {
-			sendJSONError(w, http.StatusBadRequest, "Invalid participant ID")
-			return
+		var req struct {
+			Question      string   `json:"question"`
+			ChatLanguage  string   `json:"chatLanguage,omitempty"`
+			TopK          int      `json:"topK,omitempty"`
+			MinSimilarity *float64 `json:"minSimilarity,omitempty"`
 		}
-
-		minSpeakers := 0
-		if minSpeakersStr != "" {
-			if parsed, err := strconv.Atoi(minSpeakersStr); err == nil {
-				minSpeakers = parsed
-			}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+			return
 		}
-
-		maxSpeakers := 0
-		if maxSpeakersStr != "" {
-			if parsed, err := strconv.Atoi(maxSpeakersStr); err == nil {
-				maxSpeakers = parsed
-			}
+		req.Question = strings.TrimSpace(req.Question)
+		if req.Question == "" {
+			sendJSONError(w, http.StatusBadRequest, "question is required")
+			return
 		}
-
-		strictness := 0.0
-		if strictnessStr != "" {
-			if parsed, err := strconv.ParseFloat(strictnessStr, 64); err == nil {
-				strictness = parsed
-			}
+		req.ChatLanguage = langcode.Normalize(req.ChatLanguage)
+		if req.ChatLanguage == "" {
+			req.ChatLanguage = "en"
 		}
+		topK, minSimilarity := chatRetrievalSettings(queryEngine, mtg.ID, req.TopK, req.MinSimilarity)
 
-		// Upgrade to WebSocket
-		conn, err := upgrader.Upgrade(w, r, nil)
+		// History is read before the question joins it
+		engine := queryEngine.WithHybrid(flags.Enabled(flags.HybridRetrieval, flags.SubjectForUser(user)))
+		result, err := engine.QueryDetailedWithHistory(r.Context(), mtg.ID, chatSession.Language, req.ChatLanguage,
+			chatSession.SessionID, req.Question, topK, minSimilarity, nil)
 		if err != nil {
-			log.Printf("Meeting WebSocket upgrade error: %v", err)
+			log.Printf("RAG query failed: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Query failed: %v", err))
 			return
 		}
 
-		// Handle the connection
-		go roomManager.HandleMeetingWebSocket(conn, meetingID, participantID, participantName, targetLang, minSpeakers, maxSpeakers, strictness)
-	})
+		question := &database.ChatMessage{SessionID: chatSession.SessionID, Role: "user", Content: req.Question}
+		answer := &database.ChatMessage{
+			SessionID:       chatSession.SessionID,
+			Role:            "assistant",
+			Content:         result.Answer,
+			ContextChunkIDs: result.ChunkIDs(),
+		}
+		for _, msg := range []*database.ChatMessage{question, answer} {
+			if err := database.SaveChatMessage(msg); err != nil {
+				log.Printf("Failed to save %s message: %v", msg.Role, err)
+			}
+		}
+		database.UpdateChatSessionActivity(chatSession.SessionID)
 
-	log.Println("listening on :8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+		writeJSON(w, map[string]interface{}{
+			"success":   true,
+			"sessionId": chatSession.SessionID,
+			"answer":    result.Answer,
+			"citations": result.Citations,
+			"grounding": result.Grounding,
+			"retrieval": map[string]interface{}{
+				"topK":          topK,
+				"minSimilarity": minSimilarity,
+			},
+			"messages": []*database.ChatMessage{question, answer},
+		})
+
+	default:
+		sendJSONError(w, http.StatusNotFound, "Not found")
+	}
 }
 
-// translateWithChunking wraps the translator to handle texts larger than 5000 characters
-func translateWithChunking(t translate.Translator, text, sourceLang, targetLang string) (string, error) {
-	const maxChunkSize = 5000
+// resolveChatSourceID returns the RAG source ID for a chat request: a meeting
+// by default, or one of the caller's own uploaded videos or recordings when
+// sourceType is set. Writes the error response and returns false on failure.
+func resolveChatSourceID(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier, sourceType, sourceID, meetingID string) (string, bool) {
+	if sourceType == "" || sourceType == database.SourceTypeMeeting {
+		if meetingID == "" {
+			sendJSONError(w, http.StatusBadRequest, "Missing required fields: meetingId, language")
+			return "", false
+		}
+		resolvedID, err := resolveMeetingID(meetingID)
+		if err != nil {
+			log.Printf("Failed to resolve meeting: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to resolve meeting")
+			return "", false
+		}
+		if resolvedID == "" {
+			sendJSONError(w, http.StatusNotFound, "Meeting not found")
+			return "", false
+		}
+		return resolvedID, true
+	}
 
-	// Check if the translator is an HTTPTranslator with ChunkAndTranslate method
-	if httpTrans, ok := t.(*translate.HTTPTranslator); ok {
-		return httpTrans.ChunkAndTranslate(text, sourceLang, targetLang)
+	if sourceID == "" {
+		sendJSONError(w, http.StatusBadRequest, "Missing required field: sourceId")
+		return "", false
 	}
 
-	// Fallback to regular translation for other translator types
-	return t.TranslateWithSource(text, sourceLang, targetLang)
-}
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return "", false
+	}
 
-func getEnv(key, fallback string) string {
-	value := strings.TrimSpace(os.Getenv(key))
-	if value == "" {
-		return fallback
+	transcripts, err := loadSourceTranscripts(user.ID, sourceType, sourceID)
+	if err != nil {
+		if errors.Is(err, errUnknownSourceType) {
+			sendJSONError(w, http.StatusBadRequest, err.Error())
+			return "", false
+		}
+		log.Printf("Failed to load source %s/%s: %v", sourceType, sourceID, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load source")
+		return "", false
 	}
-	return value
+	if transcripts == nil {
+		sendJSONError(w, http.StatusNotFound, "Source not found")
+		return "", false
+	}
+
+	resolvedID := database.RAGSourceID(sourceType, sourceID)
+	// A source merged into a meeting is answered from the meeting's chunks
+	link, err := database.GetSourceMeetingLink(resolvedID)
+	if err != nil {
+		log.Printf("Failed to get source link: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load source")
+		return "", false
+	}
+	if link != nil {
+		return link.MeetingID, true
+	}
+	return resolvedID, true
 }
 
-// handleChatSessions creates a new chat session for a meeting
-func handleChatSessions(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
-	if r.Method != http.MethodPost {
-		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
+var errUnknownSourceType = errors.New("sourceType must be video or recording")
+
+// loadSourceTranscripts returns the transcripts of a user's video or recording
+// keyed by language, or nil if the user has no such session.
+func loadSourceTranscripts(userID int, sourceType, sessionID string) (map[string]string, error) {
+	var transcription, translation, sourceLang, targetLang string
+
+	switch sourceType {
+	case database.SourceTypeVideo:
+		record, err := database.GetUserVideoSessionBySessionID(userID, sessionID)
+		if err != nil || record == nil {
+			return nil, err
+		}
+		transcription, translation = record.Transcription, record.Translation
+		sourceLang, targetLang = record.SourceLang, record.TargetLang
+	case database.SourceTypeRecording:
+		record, err := database.GetUserAudioSessionBySessionID(userID, sessionID)
+		if err != nil || record == nil {
+			return nil, err
+		}
+		transcription, translation = record.Transcription, record.Translation
+		sourceLang, targetLang = record.SourceLang, record.TargetLang
+	default:
+		return nil, errUnknownSourceType
 	}
 
-	var req struct {
-		MeetingID string `json:"meetingId"`
-		Language  string `json:"language"`
+	if sourceLang == "" {
+		sourceLang = "en"
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendJSONError(w, http.StatusBadRequest, "Invalid request")
-		return
+	transcripts := make(map[string]string)
+	if strings.TrimSpace(transcription) != "" {
+		transcripts[sourceLang] = transcription
 	}
+	if strings.TrimSpace(translation) != "" && targetLang != "" && targetLang != sourceLang {
+		transcripts[targetLang] = translation
+	}
+	return transcripts, nil
+}
 
-	if req.MeetingID == "" || req.Language == "" {
-		sendJSONError(w, http.StatusBadRequest, "Missing required fields: meetingId, language")
+// handleSourceOperations serves summaries and RAG processing for uploaded
+// videos and recordings:
+//
+//	POST   /api/sources/{video|recording}/{sessionId}/process[?force=true]
+//	GET    /api/sources/{video|recording}/{sessionId}/summary?language=xx
+//	GET    /api/sources/{video|recording}/{sessionId}/duplicates[?language=xx]
+//	POST   /api/sources/{video|recording}/{sessionId}/link  {"meetingId": "..."}
+//	DELETE /api/sources/{video|recording}/{sessionId}/link
+//
+// Processing a recording that overlaps a live-captured meeting returns 409
+// with the candidate meetings; the client can link it to one of them instead
+// (its knowledge is then served from the meeting) or retry with force=true.
+func handleSourceOperations(w http.ResponseWriter, r *http.Request, processor *rag.Processor, llmClient *llm.Client, keycloakVerifier *auth.KeycloakVerifier) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/sources/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(pathParts) != 3 || pathParts[0] == "" || pathParts[1] == "" {
+		sendJSONError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	sourceType, sessionID, action := pathParts[0], pathParts[1], pathParts[2]
+
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
 		return
 	}
 
-	resolvedID, err := resolveMeetingID(req.MeetingID)
+	transcripts, err := loadSourceTranscripts(user.ID, sourceType, sessionID)
 	if err != nil {
-		log.Printf("Failed to resolve meeting: %v", err)
-		sendJSONError(w, http.StatusInternalServerError, "Failed to resolve meeting")
+		if errors.Is(err, errUnknownSourceType) {
+			sendJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Failed to load source %s/%s: %v", sourceType, sessionID, err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to load source")
 		return
 	}
-	if resolvedID == "" {
-		sendJSONError(w, http.StatusNotFound, "Meeting not found")
+	if transcripts == nil {
+		sendJSONError(w, http.StatusNotFound, "Source not found")
 		return
 	}
-	req.MeetingID = resolvedID
 
-	// Optionally extract user ID from auth token
-	var userID *int
-	if keycloakVerifier != nil {
-		tokenStr := r.Header.Get("Authorization")
-		if tokenStr != "" && len(tokenStr) > 7 && tokenStr[:7] == "Bearer " {
-			tokenStr = tokenStr[7:]
-			claims, err := keycloakVerifier.VerifyToken(r.Context(), tokenStr)
-			if err == nil {
-				if preferredUsername, ok := claims["preferred_username"].(string); ok && preferredUsername != "" {
-					user, _ := database.GetUserByUsername(preferredUsername)
-					if user != nil {
-						userID = &user.ID
-					}
-				}
+	sourceID := database.RAGSourceID(sourceType, sessionID)
+
+	switch action {
+	case "process":
+		if r.Method != http.MethodPost {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		if len(transcripts) == 0 {
+			sendJSONError(w, http.StatusBadRequest, "Source has no transcript")
+			return
+		}
+
+		link, err := database.GetSourceMeetingLink(sourceID)
+		if err != nil {
+			log.Printf("Failed to get source link: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load source")
+			return
+		}
+		if link != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Source is linked to a meeting; unlink it to index it separately",
+				"link":    link,
+			})
+			return
+		}
+
+		if r.URL.Query().Get("force") != "true" {
+			// A failed check only logs: indexing a duplicate beats not indexing
+			language, duplicates, err := findSourceDuplicates(processor, user.ID, sourceID, transcripts, "")
+			if err != nil {
+				log.Printf("[RAG] Duplicate check failed for source %s: %v", sourceID, err)
+			} else if len(duplicates) > 0 {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"success":    false,
+					"error":      "Source appears to duplicate a meeting",
+					"language":   language,
+					"duplicates": duplicates,
+				})
+				return
 			}
 		}
-	}
 
-	session, err := database.CreateChatSession(req.MeetingID, req.Language, userID)
-	if err != nil {
-		log.Printf("Failed to create chat session: %v", err)
-		sendJSONError(w, http.StatusInternalServerError, "Failed to create session")
-		return
-	}
+		languages := make([]string, 0, len(transcripts))
+		for language, transcript := range transcripts {
+			languages = append(languages, language)
+			go func(language, transcript string) {
+				var partial *rag.PartialError
+				if err := processor.ProcessTranscript(sourceID, language, transcript); errors.As(err, &partial) {
+					log.Printf("[RAG] Partly processed source %s (%s): %v", sourceID, language, err)
+				} else if err != nil {
+					log.Printf("[RAG] Failed to process source %s (%s): %v", sourceID, language, err)
+					notify.Send(user.ID, notify.TypeProcessingFailed, fmt.Sprintf("Indexing failed for %s %s", sourceType, sessionID),
+						fmt.Sprintf("The %s transcript could not be indexed for chat", language), "",
+						map[string]interface{}{"sourceId": sourceID, "language": language})
+				}
+				if err := rag.GenerateSourceSummary(sourceID, language, transcript, llmClient); err != nil {
+					log.Printf("[RAG] Failed to summarize source %s (%s): %v", sourceID, language, err)
+				}
+			}(language, transcript)
+		}
+		sort.Strings(languages)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(session)
-}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":   true,
+			"sourceId":  sourceID,
+			"languages": languages,
+		})
 
-// handleChatQuery performs a RAG query on a meeting transcript
-func handleChatQuery(w http.ResponseWriter, r *http.Request, queryEngine *rag.QueryEngine, keycloakVerifier *auth.KeycloakVerifier) {
-	if r.Method != http.MethodPost {
-		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
+	case "summary":
+		if r.Method != http.MethodGet {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
 
-	var req struct {
-		SessionID    string `json:"sessionId"`
-		Question     string `json:"question"`
-		MeetingID    string `json:"meetingId"`
-		Language     string `json:"language"`
-		ChatLanguage string `json:"chatLanguage,omitempty"`
-		TopK         int    `json:"topK,omitempty"`
-	}
+		summary, err := database.GetSourceSummary(sourceID, languageParam(r, "language"))
+		if err != nil {
+			log.Printf("Failed to get source summary: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to get summary")
+			return
+		}
+		if summary == nil {
+			sendJSONError(w, http.StatusNotFound, "Summary not available")
+			return
+		}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendJSONError(w, http.StatusBadRequest, "Invalid request")
-		return
-	}
+		writeJSON(w, summary)
+
+	case "duplicates":
+		if r.Method != http.MethodGet {
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		link, err := database.GetSourceMeetingLink(sourceID)
+		if err != nil {
+			log.Printf("Failed to get source link: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to load source")
+			return
+		}
+
+		language, duplicates, err := findSourceDuplicates(processor, user.ID, sourceID, transcripts, languageParam(r, "language"))
+		if err != nil {
+			log.Printf("[RAG] Duplicate check failed for source %s: %v", sourceID, err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to check for duplicates")
+			return
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"success":    true,
+			"sourceId":   sourceID,
+			"language":   language,
+			"duplicates": duplicates,
+			"link":       link,
+		})
+
+	case "link":
+		switch r.Method {
+		case http.MethodPost:
+			var req struct {
+				MeetingID string `json:"meetingId"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MeetingID == "" {
+				sendJSONError(w, http.StatusBadRequest, "Missing required field: meetingId")
+				return
+			}
+
+			meetingID, err := resolveMeetingID(req.MeetingID)
+			if err != nil {
+				log.Printf("Failed to resolve meeting: %v", err)
+				sendJSONError(w, http.StatusInternalServerError, "Failed to resolve meeting")
+				return
+			}
+			if meetingID == "" {
+				sendJSONError(w, http.StatusNotFound, "Meeting not found")
+				return
+			}
+			allowed, err := database.UserHasMinimumRole(user.ID, meetingID, database.RoleViewer)
+			if err != nil {
+				log.Printf("Failed to check meeting access: %v", err)
+				sendJSONError(w, http.StatusInternalServerError, "Failed to check meeting access")
+				return
+			}
+			if !allowed {
+				sendJSONError(w, http.StatusForbidden, "Access denied")
+				return
+			}
 
-	if req.SessionID == "" || req.Question == "" || req.MeetingID == "" || req.Language == "" {
-		sendJSONError(w, http.StatusBadRequest, "Missing required fields: sessionId, question, meetingId, language")
-		return
-	}
+			// Record how closely the transcripts matched, when the meeting is a detected duplicate
+			var similarity *float64
+			if _, duplicates, err := findSourceDuplicates(processor, user.ID, sourceID, transcripts, ""); err != nil {
+				log.Printf("[RAG] Duplicate check failed for source %s: %v", sourceID, err)
+			} else {
+				for _, duplicate := range duplicates {
+					if duplicate.MeetingID == meetingID {
+						similarity = &duplicate.Similarity
+						break
+					}
+				}
+			}
 
-	resolvedID, err := resolveMeetingID(req.MeetingID)
-	if err != nil {
-		log.Printf("Failed to resolve meeting: %v", err)
-		sendJSONError(w, http.StatusInternalServerError, "Failed to resolve meeting")
-		return
-	}
-	if resolvedID == "" {
-		sendJSONError(w, http.StatusNotFound, "Meeting not found")
-		return
-	}
-	req.MeetingID = resolvedID
+			link, err := database.LinkSourceToMeeting(sourceID, meetingID, similarity, user.ID)
+			if err != nil {
+				log.Printf("Failed to link source %s to meeting %s: %v", sourceID, meetingID, err)
+				sendJSONError(w, http.StatusInternalServerError, "Failed to link source")
+				return
+			}
+			log.Printf("[RAG] Linked source %s to meeting %s", sourceID, meetingID)
 
-	// Default to top 5 chunks
-	if req.TopK == 0 {
-		req.TopK = 5
-	}
+			writeJSON(w, map[string]interface{}{
+				"success": true,
+				"link":    link,
+			})
 
-	// Default chat language to English if not provided (backward compatibility)
-	if req.ChatLanguage == "" {
-		req.ChatLanguage = "en"
-	}
+		case http.MethodDelete:
+			removed, err := database.UnlinkSource(sourceID)
+			if err != nil {
+				log.Printf("Failed to unlink source %s: %v", sourceID, err)
+				sendJSONError(w, http.StatusInternalServerError, "Failed to unlink source")
+				return
+			}
+			if !removed {
+				sendJSONError(w, http.StatusNotFound, "Source is not linked")
+				return
+			}
 
-	// Save user question to database
-	userMsg := &database.ChatMessage{
-		SessionID: req.SessionID,
-		Role:      "user",
-		Content:   req.Question,
-	}
-	if err := database.SaveChatMessage(userMsg); err != nil {
-		log.Printf("Failed to save user message: %v", err)
-	}
+			writeJSON(w, map[string]interface{}{
+				"success": true,
+			})
 
-	// Update session activity
-	database.UpdateChatSessionActivity(req.SessionID)
+		default:
+			sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
 
-	// Perform RAG query with specified chat language
-	answer, chunkIDs, err := queryEngine.QueryWithLanguage(req.MeetingID, req.Language, req.ChatLanguage, req.Question, req.TopK)
-	if err != nil {
-		log.Printf("RAG query failed: %v", err)
-		sendJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Query failed: %v", err))
-		return
+	default:
+		sendJSONError(w, http.StatusNotFound, "Not found")
 	}
+}
 
-	// Save assistant response to database
-	assistantMsg := &database.ChatMessage{
-		SessionID:       req.SessionID,
-		Role:            "assistant",
-		Content:         answer,
-		ContextChunkIDs: chunkIDs,
-	}
-	if err := database.SaveChatMessage(assistantMsg); err != nil {
-		log.Printf("Failed to save assistant message: %v", err)
+// findSourceDuplicates checks a source's transcript in language (or in each
+// of its languages in turn when empty) against the user's meetings and returns
+// the first language with likely duplicates
+func findSourceDuplicates(processor *rag.Processor, userID int, sourceID string, transcripts map[string]string, language string) (string, []rag.DuplicateMeeting, error) {
+	languages := []string{language}
+	if language == "" {
+		languages = make([]string, 0, len(transcripts))
+		for lang := range transcripts {
+			languages = append(languages, lang)
+		}
+		sort.Strings(languages)
 	}
 
-	// Update session activity again
-	database.UpdateChatSessionActivity(req.SessionID)
-
-	response := map[string]interface{}{
-		"answer":    answer,
-		"chunkIds":  chunkIDs,
-		"sessionId": req.SessionID,
+	for _, lang := range languages {
+		transcript := transcripts[lang]
+		if strings.TrimSpace(transcript) == "" {
+			continue
+		}
+		duplicates, err := processor.FindDuplicateMeetings(userID, sourceID, lang, transcript)
+		if err != nil {
+			return lang, nil, err
+		}
+		if len(duplicates) > 0 {
+			return lang, duplicates, nil
+		}
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	return language, []rag.DuplicateMeeting{}, nil
 }
 
 // handleListUserMeetings returns all meetings for the authenticated user
@@ -3039,8 +11218,12 @@ func handleListUserMeetings(w http.ResponseWriter, r *http.Request, keycloakVeri
 	if s := query.Get("status"); s == "active" || s == "ended" {
 		status = s
 	}
+	tagIDs, ok := parseTagFilter(w, r, user.ID)
+	if !ok {
+		return
+	}
 
-	meetings, total, err := database.GetUserMeetings(user.ID, limit, offset, status)
+	meetings, total, err := database.GetUserMeetings(user.ID, limit, offset, status, tagIDs)
 	if err != nil {
 		log.Printf("Failed to get user meetings: %v", err)
 		sendJSONError(w, http.StatusInternalServerError, "Failed to get meetings")
@@ -3121,7 +11304,7 @@ func handleListMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakVer
 		return
 	}
 
-	// Check if user is owner
+	// Check if user is owner or co-host
 	userRole, err := database.GetUserMeetingRole(user.ID, meetingID)
 	if err != nil {
 		log.Printf("Failed to get user role: %v", err)
@@ -3129,8 +11312,8 @@ func handleListMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakVer
 		return
 	}
 
-	if userRole != database.RoleOwner {
-		sendJSONError(w, http.StatusForbidden, "Only meeting owners can manage access")
+	if !database.IsModeratorRole(userRole) {
+		sendJSONError(w, http.StatusForbidden, "Only meeting owners or co-hosts can manage access")
 		return
 	}
 
@@ -3149,7 +11332,7 @@ func handleListMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakVer
 	})
 }
 
-// handleGrantMeetingAccess grants access to a user (owner only)
+// handleGrantMeetingAccess grants access to a user (owner or co-host)
 func handleGrantMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
 	if r.Method != http.MethodPost {
 		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -3177,7 +11360,7 @@ func handleGrantMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakVe
 		return
 	}
 
-	// Check if user is owner
+	// Check if user is owner or co-host
 	userRole, err := database.GetUserMeetingRole(user.ID, req.MeetingID)
 	if err != nil {
 		log.Printf("Failed to get user role: %v", err)
@@ -3185,8 +11368,11 @@ func handleGrantMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakVe
 		return
 	}
 
-	if userRole != database.RoleOwner {
-		sendJSONError(w, http.StatusForbidden, "Only meeting owners can grant access")
+	if !database.IsModeratorRole(userRole) {
+		sendJSONError(w, http.StatusForbidden, "Only meeting owners or co-hosts can grant access")
+		return
+	}
+	if !canManageMember(w, userRole, req.MeetingID, req.UserID) {
 		return
 	}
 
@@ -3206,6 +11392,10 @@ func handleGrantMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakVe
 		return
 	}
 
+	notify.Send(req.UserID, notify.TypeAccessGranted, fmt.Sprintf("You were granted %s access", req.Role),
+		fmt.Sprintf("%s gave you %s access to a meeting", user.DisplayName, req.Role),
+		meetingDetailLink(req.MeetingID), map[string]interface{}{"meetingId": req.MeetingID, "role": req.Role})
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -3213,7 +11403,7 @@ func handleGrantMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakVe
 	})
 }
 
-// handleUpdateMeetingAccess updates a user's role (owner only)
+// handleUpdateMeetingAccess updates a user's role (owner or co-host)
 func handleUpdateMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
 	if r.Method != http.MethodPut {
 		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -3241,7 +11431,7 @@ func handleUpdateMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakV
 		return
 	}
 
-	// Check if user is owner
+	// Check if user is owner or co-host
 	userRole, err := database.GetUserMeetingRole(user.ID, req.MeetingID)
 	if err != nil {
 		log.Printf("Failed to get user role: %v", err)
@@ -3249,8 +11439,11 @@ func handleUpdateMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakV
 		return
 	}
 
-	if userRole != database.RoleOwner {
-		sendJSONError(w, http.StatusForbidden, "Only meeting owners can update access")
+	if !database.IsModeratorRole(userRole) {
+		sendJSONError(w, http.StatusForbidden, "Only meeting owners or co-hosts can update access")
+		return
+	}
+	if !canManageMember(w, userRole, req.MeetingID, req.UserID) {
 		return
 	}
 
@@ -3270,6 +11463,10 @@ func handleUpdateMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakV
 		return
 	}
 
+	notify.Send(req.UserID, notify.TypeAccessGranted, fmt.Sprintf("You were granted %s access", req.Role),
+		fmt.Sprintf("%s gave you %s access to a meeting", user.DisplayName, req.Role),
+		meetingDetailLink(req.MeetingID), map[string]interface{}{"meetingId": req.MeetingID, "role": req.Role})
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -3277,7 +11474,7 @@ func handleUpdateMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakV
 	})
 }
 
-// handleRevokeMeetingAccess revokes access from a user (owner only)
+// handleRevokeMeetingAccess revokes access from a user (owner or co-host)
 func handleRevokeMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
 	if r.Method != http.MethodDelete {
 		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -3304,7 +11501,7 @@ func handleRevokeMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakV
 		return
 	}
 
-	// Check if user is owner
+	// Check if user is owner or co-host
 	userRole, err := database.GetUserMeetingRole(user.ID, req.MeetingID)
 	if err != nil {
 		log.Printf("Failed to get user role: %v", err)
@@ -3312,8 +11509,11 @@ func handleRevokeMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakV
 		return
 	}
 
-	if userRole != database.RoleOwner {
-		sendJSONError(w, http.StatusForbidden, "Only meeting owners can revoke access")
+	if !database.IsModeratorRole(userRole) {
+		sendJSONError(w, http.StatusForbidden, "Only meeting owners or co-hosts can revoke access")
+		return
+	}
+	if !canManageMember(w, userRole, req.MeetingID, req.UserID) {
 		return
 	}
 
@@ -3340,7 +11540,240 @@ func handleRevokeMeetingAccess(w http.ResponseWriter, r *http.Request, keycloakV
 	})
 }
 
-// handleGetAvailableParticipants returns participants without explicit ACL (owner only)
+// handleMeetingInterpreter designates a member as an interpreter who may join
+// as the interpreter channel (POST) or withdraws it (DELETE) (owner or co-host)
+func handleMeetingInterpreter(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		MeetingID string `json:"meetingId"`
+		UserID    int    `json:"userId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.MeetingID == "" || req.UserID == 0 {
+		sendJSONError(w, http.StatusBadRequest, "meetingId and userId are required")
+		return
+	}
+
+	userRole, err := database.GetUserMeetingRole(user.ID, req.MeetingID)
+	if err != nil {
+		log.Printf("Failed to get user role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+	if !database.IsModeratorRole(userRole) {
+		sendJSONError(w, http.StatusForbidden, "Only meeting owners or co-hosts can manage interpreters")
+		return
+	}
+
+	designate := r.Method == http.MethodPost
+	if designate {
+		memberRole, err := database.GetUserMeetingRole(req.UserID, req.MeetingID)
+		if err != nil {
+			log.Printf("Failed to get user role: %v", err)
+			sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+			return
+		}
+		if memberRole == "" {
+			sendJSONError(w, http.StatusBadRequest, "user has no access to this meeting")
+			return
+		}
+	}
+	if err := database.SetMeetingInterpreter(req.MeetingID, req.UserID, designate, user.ID); err != nil {
+		log.Printf("Failed to update interpreter: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to update interpreter")
+		return
+	}
+
+	message := "Interpreter removed successfully"
+	if designate {
+		message = "Interpreter added successfully"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": message,
+	})
+}
+
+// handleMeetingCoHost promotes an editor to co-host (POST) or demotes a
+// co-host back to editor (DELETE) (owner only)
+func handleMeetingCoHost(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		MeetingID string `json:"meetingId"`
+		UserID    int    `json:"userId"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.MeetingID == "" || req.UserID == 0 {
+		sendJSONError(w, http.StatusBadRequest, "meetingId and userId are required")
+		return
+	}
+
+	// Check if user is owner
+	userRole, err := database.GetUserMeetingRole(user.ID, req.MeetingID)
+	if err != nil {
+		log.Printf("Failed to get user role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+
+	if userRole != database.RoleOwner {
+		sendJSONError(w, http.StatusForbidden, "Only meeting owners can manage co-hosts")
+		return
+	}
+
+	promote := r.Method == http.MethodPost
+	if err := database.SetMeetingCoHost(req.MeetingID, req.UserID, promote, user.ID); err != nil {
+		log.Printf("Failed to update co-host: %v", err)
+		if strings.Contains(err.Error(), "is not an") {
+			sendJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		sendJSONError(w, http.StatusInternalServerError, "Failed to update co-host")
+		return
+	}
+
+	message := "Co-host removed successfully"
+	if promote {
+		message = "Co-host added successfully"
+		notify.Send(req.UserID, notify.TypeAccessGranted, "You are now a co-host",
+			fmt.Sprintf("%s made you a co-host of a meeting", user.DisplayName),
+			meetingDetailLink(req.MeetingID), map[string]interface{}{"meetingId": req.MeetingID, "role": database.RoleCoHost})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": message,
+	})
+}
+
+// handleTransferMeetingOwnership hands a meeting to another member with
+// explicit access (owner only). The previous owner stays on as
+// previousOwnerRole, co-host by default, or "none" to leave the meeting.
+func handleTransferMeetingOwnership(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
+	if r.Method != http.MethodPost {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	user, ok := authenticateUserFromRequest(keycloakVerifier, w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		MeetingID         string `json:"meetingId"`
+		UserID            int    `json:"userId"`
+		PreviousOwnerRole string `json:"previousOwnerRole"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.MeetingID == "" || req.UserID == 0 {
+		sendJSONError(w, http.StatusBadRequest, "meetingId and userId are required")
+		return
+	}
+
+	switch req.PreviousOwnerRole {
+	case "":
+		req.PreviousOwnerRole = database.RoleCoHost
+	case "none":
+		req.PreviousOwnerRole = ""
+	}
+
+	// Check if user is owner
+	userRole, err := database.GetUserMeetingRole(user.ID, req.MeetingID)
+	if err != nil {
+		log.Printf("Failed to get user role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+
+	if userRole != database.RoleOwner {
+		sendJSONError(w, http.StatusForbidden, "Only meeting owners can transfer ownership")
+		return
+	}
+
+	err = database.TransferMeetingOwnership(req.MeetingID, user.ID, req.UserID, req.PreviousOwnerRole)
+	if err != nil {
+		log.Printf("Failed to transfer ownership: %v", err)
+		switch {
+		case strings.Contains(err.Error(), "invalid role"), strings.Contains(err.Error(), "already owns"):
+			sendJSONError(w, http.StatusBadRequest, err.Error())
+		case strings.Contains(err.Error(), "does not have explicit access"):
+			sendJSONError(w, http.StatusBadRequest, "Ownership can only be transferred to a member of the meeting")
+		case strings.Contains(err.Error(), "not owned"):
+			sendJSONError(w, http.StatusConflict, "Meeting ownership changed, reload and try again")
+		default:
+			sendJSONError(w, http.StatusInternalServerError, "Failed to transfer ownership")
+		}
+		return
+	}
+
+	log.Printf("Meeting %s ownership transferred from user %d to user %d", req.MeetingID, user.ID, req.UserID)
+	notify.Send(req.UserID, notify.TypeAccessGranted, "You now own a meeting",
+		fmt.Sprintf("%s transferred ownership of a meeting to you", user.DisplayName),
+		meetingDetailLink(req.MeetingID), map[string]interface{}{"meetingId": req.MeetingID, "role": database.RoleOwner})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Ownership transferred successfully",
+	})
+}
+
+// canManageMember reports whether a moderator with actorRole may change
+// another member's access. Co-hosts manage editors and viewers only; the
+// owner's and other co-hosts' roles are left to the owner. Writes the error
+// response when not.
+func canManageMember(w http.ResponseWriter, actorRole, meetingID string, memberID int) bool {
+	if actorRole == database.RoleOwner {
+		return true
+	}
+	memberRole, err := database.GetUserMeetingRole(memberID, meetingID)
+	if err != nil {
+		log.Printf("Failed to get member role: %v", err)
+		sendJSONError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return false
+	}
+	if database.IsModeratorRole(memberRole) {
+		sendJSONError(w, http.StatusForbidden, "Only the meeting owner can change a co-host's or owner's access")
+		return false
+	}
+	return true
+}
+
+// handleGetAvailableParticipants returns participants without explicit ACL (owner or co-host)
 func handleGetAvailableParticipants(w http.ResponseWriter, r *http.Request, keycloakVerifier *auth.KeycloakVerifier) {
 	if r.Method != http.MethodGet {
 		sendJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -3361,7 +11794,7 @@ func handleGetAvailableParticipants(w http.ResponseWriter, r *http.Request, keyc
 		return
 	}
 
-	// Check if user is owner
+	// Check if user is owner or co-host
 	userRole, err := database.GetUserMeetingRole(user.ID, meetingID)
 	if err != nil {
 		log.Printf("Failed to get user role: %v", err)
@@ -3369,8 +11802,8 @@ func handleGetAvailableParticipants(w http.ResponseWriter, r *http.Request, keyc
 		return
 	}
 
-	if userRole != database.RoleOwner {
-		sendJSONError(w, http.StatusForbidden, "Only meeting owners can view available participants")
+	if !database.IsModeratorRole(userRole) {
+		sendJSONError(w, http.StatusForbidden, "Only meeting owners or co-hosts can view available participants")
 		return
 	}
 
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"realtime-caption-translator/internal/benchmark"
+	"realtime-caption-translator/internal/translate"
+)
+
+// provider is a named translation endpoint under test
+type provider struct {
+	name       string
+	translator translate.Translator
+}
+
+// pairScore holds one provider's scores for one language pair
+type pairScore struct {
+	provider string
+	pair     string
+	samples  int
+	failed   int
+	bleu     benchmark.BLEU
+	chrf     benchmark.ChrF
+	latency  time.Duration
+}
+
+func (s *pairScore) avgLatencyMs() float64 {
+	if done := s.samples - s.failed; done > 0 {
+		return float64(s.latency.Milliseconds()) / float64(done)
+	}
+	return 0
+}
+
+func main() {
+	referencePath := flag.String("references", "", "Path to the reference set (JSONL: {\"source\",\"reference\",\"sourceLang\",\"targetLang\"})")
+	providersFlag := flag.String("providers", "", "Comma-separated translation providers as name=url (default default=$TRANSLATION_BASE_URL)")
+	reportPath := flag.String("report", "", "Also write the comparison report as Markdown to this file")
+	verbose := flag.Bool("v", false, "Print per-sentence translations")
+	flag.Parse()
+
+	if *referencePath == "" {
+		log.Fatal("-references is required")
+	}
+	pairs, err := benchmark.LoadReferenceSet(*referencePath)
+	if err != nil {
+		log.Fatalf("Failed to load reference set: %v", err)
+	}
+	if len(pairs) == 0 {
+		log.Fatal("Reference set is empty")
+	}
+
+	providers, err := parseProviders(*providersFlag)
+	if err != nil {
+		log.Fatalf("Invalid -providers: %v", err)
+	}
+
+	log.Printf("Evaluating %d sentences against %d provider(s)", len(pairs), len(providers))
+
+	var scores []*pairScore
+	for _, p := range providers {
+		scores = append(scores, evaluate(p, pairs, *verbose)...)
+	}
+
+	writeReport(os.Stdout, *referencePath, scores)
+
+	if *reportPath != "" {
+		file, err := os.Create(*reportPath)
+		if err != nil {
+			log.Fatalf("Failed to create report: %v", err)
+		}
+		defer file.Close()
+		writeReport(file, *referencePath, scores)
+		log.Printf("Report written to %s", *reportPath)
+	}
+}
+
+func evaluate(p provider, pairs []benchmark.TranslationPair, verbose bool) []*pairScore {
+	byPair := make(map[string]*pairScore)
+	var order []string
+
+	for _, pair := range pairs {
+		key := pair.LanguagePair()
+		score := byPair[key]
+		if score == nil {
+			score = &pairScore{provider: p.name, pair: key}
+			byPair[key] = score
+			order = append(order, key)
+		}
+		score.samples++
+
+		start := time.Now()
+		hypothesis, err := p.translator.TranslateWithSource(pair.Source, pair.SourceLang, pair.TargetLang)
+		elapsed := time.Since(start)
+		if err != nil {
+			log.Printf("[%s] %s: translation failed: %v", p.name, pair.ID, err)
+			score.failed++
+			continue
+		}
+
+		score.latency += elapsed
+		score.bleu.Add(pair.Reference, hypothesis)
+		score.chrf.Add(pair.Reference, hypothesis)
+		if verbose {
+			log.Printf("[%s] %s\n  src: %s\n  ref: %s\n  hyp: %s", p.name, pair.ID, pair.Source, pair.Reference, hypothesis)
+		}
+	}
+
+	scores := make([]*pairScore, 0, len(order))
+	for _, key := range order {
+		scores = append(scores, byPair[key])
+	}
+	return scores
+}
+
+// writeReport prints a per-language-pair comparison (best chrF first) and a
+// suggested provider order for the translation fallback chain
+func writeReport(w io.Writer, referencePath string, scores []*pairScore) {
+	byPair := make(map[string][]*pairScore)
+	for _, score := range scores {
+		byPair[score.pair] = append(byPair[score.pair], score)
+	}
+	languagePairs := make([]string, 0, len(byPair))
+	for pair := range byPair {
+		languagePairs = append(languagePairs, pair)
+	}
+	sort.Strings(languagePairs)
+
+	fmt.Fprintf(w, "# Translation Benchmark\n\n")
+	fmt.Fprintf(w, "Reference set: `%s`, generated %s\n\n", referencePath, time.Now().Format("2006-01-02 15:04"))
+
+	for _, pair := range languagePairs {
+		rows := byPair[pair]
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].chrf.Score() > rows[j].chrf.Score() })
+
+		fmt.Fprintf(w, "## %s\n\n", pair)
+		fmt.Fprintf(w, "| Provider | Sentences | Failed | chrF | BLEU | Avg latency |\n")
+		fmt.Fprintf(w, "|---|---:|---:|---:|---:|---:|\n")
+		for _, row := range rows {
+			fmt.Fprintf(w, "| %s | %d | %d | %.1f | %.1f | %.0fms |\n",
+				row.provider, row.samples, row.failed, row.chrf.Score(), row.bleu.Score(), row.avgLatencyMs())
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "## Suggested fallback order\n\n")
+	for i, entry := range rankProviders(scores) {
+		fmt.Fprintf(w, "%d. %s (mean chrF %.1f, %d failed)\n", i+1, entry.name, entry.meanChrF, entry.failed)
+	}
+	fmt.Fprintln(w)
+}
+
+type providerRank struct {
+	name     string
+	meanChrF float64
+	failed   int
+}
+
+// rankProviders orders providers by chrF averaged over language pairs. Failed
+// sentences count as zero so an unreliable provider is not ranked first.
+func rankProviders(scores []*pairScore) []providerRank {
+	totals := make(map[string]*providerRank)
+	pairsSeen := make(map[string]int)
+	var names []string
+
+	for _, score := range scores {
+		rank := totals[score.provider]
+		if rank == nil {
+			rank = &providerRank{name: score.provider}
+			totals[score.provider] = rank
+			names = append(names, score.provider)
+		}
+		successRate := float64(score.samples-score.failed) / float64(score.samples)
+		rank.meanChrF += score.chrf.Score() * successRate
+		rank.failed += score.failed
+		pairsSeen[score.provider]++
+	}
+
+	ranks := make([]providerRank, 0, len(names))
+	for _, name := range names {
+		rank := *totals[name]
+		rank.meanChrF /= float64(pairsSeen[name])
+		ranks = append(ranks, rank)
+	}
+	sort.SliceStable(ranks, func(i, j int) bool { return ranks[i].meanChrF > ranks[j].meanChrF })
+	return ranks
+}
+
+func parseProviders(value string) ([]provider, error) {
+	if value == "" {
+		value = "default=" + getEnv("TRANSLATION_BASE_URL", "http://127.0.0.1:8004")
+	}
+
+	var providers []provider
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, url, found := strings.Cut(entry, "=")
+		if !found {
+			name, url = entry, entry
+		}
+		if url == "" {
+			return nil, fmt.Errorf("provider %q has no URL", name)
+		}
+		providers = append(providers, provider{
+			name: name,
+			translator: &translate.HTTPTranslator{
+				BaseURL:    url,
+				HTTPClient: &http.Client{Timeout: 60 * time.Second},
+			},
+		})
+	}
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no providers given")
+	}
+	return providers, nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -4,14 +4,33 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
+
+	"realtime-caption-translator/internal/httpx"
+	"realtime-caption-translator/internal/metrics"
+	"realtime-caption-translator/internal/ratelimit"
+	"realtime-caption-translator/internal/replica"
 )
 
 type Client struct {
 	BaseURL string
 	HTTP    *http.Client
+
+	// Limiter, when set, bounds concurrent requests and rejects calls once
+	// its queue is full; Priority decides how this client's calls queue
+	Limiter  *ratelimit.Limiter
+	Priority ratelimit.Priority
+
+	// Replicas, when set, spreads calls over the service's registered
+	// replicas instead of always using BaseURL
+	Replicas *replica.Pool
+
+	// Breaker, when set, retries transient failures and stops calling the
+	// service while it is unhealthy
+	Breaker *httpx.Breaker
 }
 
 func New(baseURL string) *Client {
@@ -25,6 +44,35 @@ type Resp struct {
 	Text string `json:"text"`
 }
 
+// WithPriority returns a copy of the client that queues at the given priority
+// while sharing the same HTTP client, limiter and breaker
+func (c *Client) WithPriority(priority ratelimit.Priority) *Client {
+	clone := *c
+	clone.Priority = priority
+	return &clone
+}
+
+// do sends a request to the ASR service once the limiter admits it and
+// records its latency. The slot is held until the response body is closed.
+func (c *Client) do(operation string, req *http.Request) (*http.Response, error) {
+	release, err := c.Limiter.TryAcquire(c.Priority)
+	if err != nil {
+		return nil, fmt.Errorf("asr %s: %w", operation, err)
+	}
+
+	start := time.Now()
+	res, err := c.Breaker.Do(req, func(req *http.Request) (*http.Response, error) {
+		return c.Replicas.Do(c.HTTP, c.BaseURL, req)
+	})
+	metrics.ObserveRequest(metrics.ASRLatency, metrics.StageASR, operation, start, res, err)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	res.Body = ratelimit.ReleaseOnClose(res.Body, release)
+	return res, nil
+}
+
 // Minimal WAV (PCM16 mono) wrapper
 func pcm16ToWav(pcm []int16, sampleRate int) ([]byte, error) {
 	dataBytes := len(pcm) * 2
@@ -75,7 +123,7 @@ func (c *Client) TranscribePCM16WithLang(pcm []int16, sampleRate int, language s
 		req.Header.Set("x-language", language)
 	}
 
-	res, err := c.HTTP.Do(req)
+	res, err := c.do("transcribe", req)
 	if err != nil {
 		return "", err
 	}
@@ -103,7 +151,7 @@ func (c *Client) TranscribeWAV(wavData []byte, language string) (string, error)
 		req.Header.Set("x-language", language)
 	}
 
-	res, err := c.HTTP.Do(req)
+	res, err := c.do("transcribe", req)
 	if err != nil {
 		return "", err
 	}
@@ -120,11 +168,105 @@ func (c *Client) TranscribeWAV(wavData []byte, language string) (string, error)
 	return r.Text, nil
 }
 
+// Segment is a timed span of a batch transcription (seconds from the start)
+type Segment struct {
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Text    string  `json:"text"`
+	Speaker string  `json:"speaker,omitempty"` // set by diarization
+}
+
+// TimedTranscript is a batch transcription with segment timestamps
+type TimedTranscript struct {
+	Text     string    `json:"text"`
+	Language string    `json:"language"`
+	Segments []Segment `json:"segments"`
+}
+
+// TranscribeWAVSegments transcribes a complete WAV file and keeps Whisper's
+// segment timestamps (for aligning dubbed speech)
+func (c *Client) TranscribeWAVSegments(wavData []byte, language string) (*TimedTranscript, error) {
+	req, err := http.NewRequest("POST", c.BaseURL+"/transcribe", bytes.NewReader(wavData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "audio/wav")
+	if language != "" {
+		req.Header.Set("x-language", language)
+	}
+
+	res, err := c.do("transcribe_segments", req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("asr status: %s", res.Status)
+	}
+
+	var result TimedTranscript
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Word is one recognized word with the model's probability for it. Text
+// keeps Whisper's leading space and punctuation.
+type Word struct {
+	Text        string  `json:"word"`
+	Probability float64 `json:"probability"`
+}
+
+// WordTranscript is a transcription with per-word probabilities. Words is
+// empty when the ASR service does not report them.
+type WordTranscript struct {
+	Text  string `json:"text"`
+	Words []Word `json:"words"`
+}
+
+// TranscribePCM16Words transcribes a PCM16 window and asks for word-level
+// probabilities, which cost the service an extra alignment pass
+func (c *Client) TranscribePCM16Words(pcm []int16, sampleRate int, language string) (*WordTranscript, error) {
+	wav, err := pcm16ToWav(pcm, sampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", c.BaseURL+"/transcribe", bytes.NewReader(wav))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "audio/wav")
+	req.Header.Set("x-word-confidence", "true")
+	if language != "" {
+		req.Header.Set("x-language", language)
+	}
+
+	res, err := c.do("transcribe", req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("asr status: %s", res.Status)
+	}
+
+	var result WordTranscript
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // DetectLanguageResponse represents the response from language detection
 type DetectLanguageResponse struct {
-	Language string `json:"language"`
-	Text     string `json:"text"`
-	Segments []struct {
+	Language    string  `json:"language"`
+	Probability float64 `json:"probability,omitempty"`
+	Text        string  `json:"text"`
+	Segments    []struct {
 		Start    float64 `json:"start"`
 		End      float64 `json:"end"`
 		Text     string  `json:"text"`
@@ -140,7 +282,7 @@ func (c *Client) DetectLanguage(wavData []byte) (string, error) {
 	}
 	req.Header.Set("Content-Type", "audio/wav")
 
-	res, err := c.HTTP.Do(req)
+	res, err := c.do("detect_language", req)
 	if err != nil {
 		return "", err
 	}
@@ -157,6 +299,38 @@ func (c *Client) DetectLanguage(wavData []byte) (string, error) {
 	return r.Language, nil
 }
 
+// DetectLanguagePCM16 identifies the language of a PCM16 window without
+// transcribing it and returns the model's probability for that language
+func (c *Client) DetectLanguagePCM16(pcm []int16, sampleRate int) (string, float64, error) {
+	wav, err := pcm16ToWav(pcm, sampleRate)
+	if err != nil {
+		return "", 0, err
+	}
+
+	req, err := http.NewRequest("POST", c.BaseURL+"/detect-language", bytes.NewReader(wav))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "audio/wav")
+	req.Header.Set("x-detect-only", "true")
+
+	res, err := c.do("detect_language", req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return "", 0, fmt.Errorf("language detection status: %s", res.Status)
+	}
+
+	var r DetectLanguageResponse
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return "", 0, err
+	}
+	return r.Language, r.Probability, nil
+}
+
 // DiarizationResult represents transcription with speaker diarization
 type DiarizationResult struct {
 	Text        string                   `json:"text"`
@@ -176,7 +350,7 @@ func (c *Client) TranscribeWithDiarization(wavData []byte, language string) (*Di
 		req.Header.Set("x-language", language)
 	}
 
-	res, err := c.HTTP.Do(req)
+	res, err := c.do("diarize", req)
 	if err != nil {
 		return nil, err
 	}
@@ -192,3 +366,60 @@ func (c *Client) TranscribeWithDiarization(wavData []byte, language string) (*Di
 	}
 	return &result, nil
 }
+
+// TimedSegments returns the diarized segments with their speaker labels
+func (r *DiarizationResult) TimedSegments() []Segment {
+	segments := make([]Segment, 0, len(r.Segments))
+	for _, raw := range r.Segments {
+		var segment Segment
+		segment.Start, _ = raw["start"].(float64)
+		segment.End, _ = raw["end"].(float64)
+		segment.Text, _ = raw["text"].(string)
+		segment.Speaker, _ = raw["speaker"].(string)
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// ErrInvalidSample is returned when the ASR service cannot embed a voice
+// sample, e.g. because it is too short
+var ErrInvalidSample = errors.New("invalid voice sample")
+
+// SpeakerEmbedding is the voice embedding of a whole audio sample
+type SpeakerEmbedding struct {
+	Embedding []float32 `json:"embedding"`
+	Duration  float64   `json:"duration"` // seconds
+}
+
+// EmbedSpeaker computes a voice embedding for speaker enrollment, using the
+// same model as diarization so enrolled and live voices are comparable
+func (c *Client) EmbedSpeaker(wavData []byte) (*SpeakerEmbedding, error) {
+	req, err := http.NewRequest("POST", c.BaseURL+"/speaker-embedding", bytes.NewReader(wavData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "audio/wav")
+
+	res, err := c.do("speaker_embedding", req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusBadRequest {
+		var body struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&body)
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSample, body.Error)
+	}
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("speaker embedding status: %s", res.Status)
+	}
+
+	var result SpeakerEmbedding
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
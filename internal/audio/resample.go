@@ -0,0 +1,93 @@
+package audio
+
+import "math"
+
+// Resampler converts a PCM16 stream from one sample rate to another by linear
+// interpolation. When downsampling, input is first averaged over the rate
+// ratio so content above the new Nyquist frequency folds back less. It keeps
+// its position across calls, so streams can be converted buffer by buffer
+// without clicks at the boundaries. It is not safe for concurrent use.
+type Resampler struct {
+	from, to int
+	step     float64 // input samples per output sample
+	pos      float64 // position of the next output sample in the current input
+	prev     float64 // last filtered sample of the previous input
+
+	history []float64 // recent raw samples for the averaging filter
+	sum     float64
+	next    int // oldest sample in history
+}
+
+// NewResampler creates a resampler from one rate to another (Hz)
+func NewResampler(from, to int) *Resampler {
+	r := &Resampler{from: from, to: to, step: float64(from) / float64(to)}
+	if taps := int(math.Round(r.step)); taps > 1 {
+		r.history = make([]float64, taps)
+	}
+	return r
+}
+
+// From returns the input sample rate
+func (r *Resampler) From() int {
+	return r.from
+}
+
+// Process converts the next buffer of the stream. Output lags input by up
+// to one sample, which the next call picks up.
+func (r *Resampler) Process(samples []int16) []int16 {
+	if r.from == r.to {
+		return samples
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	filtered := make([]float64, len(samples))
+	for i, s := range samples {
+		filtered[i] = r.filter(float64(s))
+	}
+
+	out := make([]int16, 0, int(float64(len(samples))/r.step)+1)
+	at := func(i int) float64 {
+		if i < 0 {
+			return r.prev
+		}
+		return filtered[i]
+	}
+	for {
+		i := int(math.Floor(r.pos))
+		if i+1 >= len(filtered) {
+			break
+		}
+		a, b := at(i), filtered[i+1]
+		out = append(out, clampSample(a+(b-a)*(r.pos-float64(i))))
+		r.pos += r.step
+	}
+	r.pos -= float64(len(filtered))
+	r.prev = filtered[len(filtered)-1]
+	return out
+}
+
+// filter returns the moving average of the last taps samples ending at s;
+// without taps it returns s
+func (r *Resampler) filter(s float64) float64 {
+	if r.history == nil {
+		return s
+	}
+	r.sum += s - r.history[r.next]
+	r.history[r.next] = s
+	r.next = (r.next + 1) % len(r.history)
+	return r.sum / float64(len(r.history))
+}
+
+// clampSample rounds to the nearest PCM16 value
+func clampSample(v float64) int16 {
+	v = math.Round(v)
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
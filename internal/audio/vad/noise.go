@@ -0,0 +1,48 @@
+package vad
+
+import "sort"
+
+// Noise floor tracking after calibration: the floor follows quieter frames
+// quickly and louder non-speech frames slowly, so a pause in a noisy room
+// does not make the room's noise count as speech
+const (
+	noiseFallRate = 0.05
+	noiseRiseRate = 0.005
+)
+
+// noiseFloor estimates the background level of a stream. It calibrates on
+// the frames of the first stretch of audio, taking a low percentile so
+// speech during calibration does not inflate the floor.
+type noiseFloor struct {
+	frames     int // calibration frames
+	levels     []float64
+	floor      float64
+	calibrated bool
+}
+
+func newNoiseFloor(frames int) *noiseFloor {
+	if frames < 1 {
+		frames = 1
+	}
+	return &noiseFloor{frames: frames}
+}
+
+// observe adds a frame's RMS; speech frames only count during calibration
+func (n *noiseFloor) observe(rms float64, speech bool) {
+	if !n.calibrated {
+		n.levels = append(n.levels, rms)
+		if len(n.levels) >= n.frames {
+			sort.Float64s(n.levels)
+			n.floor = n.levels[len(n.levels)/5]
+			n.levels = nil
+			n.calibrated = true
+		}
+		return
+	}
+	switch {
+	case rms < n.floor:
+		n.floor += noiseFallRate * (rms - n.floor)
+	case !speech:
+		n.floor += noiseRiseRate * (rms - n.floor)
+	}
+}
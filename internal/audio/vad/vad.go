@@ -0,0 +1,317 @@
+// Package vad splits a PCM16 stream into chunks that end at pauses in speech,
+// so ASR sees whole phrases instead of fixed windows that cut words in half.
+// Speech is detected per frame by RMS energy; a chunk ends once speech has
+// been followed by a run of silent frames (the hangover). With calibration,
+// the speech threshold also adapts to the stream's background noise.
+package vad
+
+import (
+	"math"
+	"time"
+)
+
+// Config tunes the segmenter; zero fields take the defaults below
+type Config struct {
+	SampleRate int
+	Frame      time.Duration // analysis frame length
+	Threshold  float64       // frame RMS (0-1) above which a frame is speech
+	Hangover   time.Duration // silence after speech that ends a chunk
+	MinChunk   time.Duration // chunks are not ended at a pause before this length
+	MaxChunk   time.Duration // chunks are cut here even without a pause
+	PreRoll    time.Duration // silence kept before speech starts
+
+	// Calibration, when set, measures the background noise over the first
+	// stretch of audio; frames must then also be NoiseMargin times louder
+	// than the noise floor to count as speech
+	Calibration time.Duration
+	NoiseMargin float64
+}
+
+// Defaults
+const (
+	DefaultFrame     = 30 * time.Millisecond
+	DefaultThreshold = 0.02
+	DefaultHangover  = 600 * time.Millisecond
+	DefaultMinChunk  = 2 * time.Second
+	DefaultMaxChunk  = 12 * time.Second
+	DefaultPreRoll   = 300 * time.Millisecond
+
+	DefaultNoiseMargin = 3.0 // about 10 dB above the noise floor
+)
+
+// speakingOnset is how many voiced frames in a row make a speaker count as
+// speaking, so clicks and bumps do not light up speaking indicators
+const speakingOnset = 3
+
+// Segmenter accumulates audio and returns chunks at silence boundaries. It is
+// not safe for concurrent use.
+type Segmenter struct {
+	frame    int // samples per frame
+	hangover int // silent frames that end a chunk
+	minLen   int // samples
+	maxLen   int // samples
+	preRoll  int // samples
+	thresh   float64
+	margin   float64
+	noise    *noiseFloor // nil without calibration
+	level    float64     // RMS of the latest frame
+
+	pending []int16 // samples not yet making up a whole frame
+	chunk   []int16
+	speech  bool // chunk contains speech
+	silent  int  // consecutive silent frames since the last speech frame
+	lastCut int  // end of the most recent silent frame in chunk, for MaxChunk cuts
+
+	// Speaking state for indicators, independent of chunk boundaries
+	speaking  bool
+	voicedRun int // consecutive voiced frames
+	quietRun  int // consecutive silent frames
+}
+
+// New creates a segmenter; SampleRate is required
+func New(cfg Config) *Segmenter {
+	if cfg.Frame <= 0 {
+		cfg.Frame = DefaultFrame
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = DefaultThreshold
+	}
+	if cfg.Hangover <= 0 {
+		cfg.Hangover = DefaultHangover
+	}
+	if cfg.MinChunk <= 0 {
+		cfg.MinChunk = DefaultMinChunk
+	}
+	if cfg.MaxChunk <= 0 {
+		cfg.MaxChunk = DefaultMaxChunk
+	}
+	if cfg.MaxChunk < cfg.MinChunk {
+		cfg.MaxChunk = cfg.MinChunk
+	}
+	if cfg.PreRoll < 0 {
+		cfg.PreRoll = 0
+	} else if cfg.PreRoll == 0 {
+		cfg.PreRoll = DefaultPreRoll
+	}
+	if cfg.NoiseMargin <= 0 {
+		cfg.NoiseMargin = DefaultNoiseMargin
+	}
+
+	samples := func(d time.Duration) int {
+		return int(int64(cfg.SampleRate) * int64(d) / int64(time.Second))
+	}
+	frame := samples(cfg.Frame)
+	if frame < 1 {
+		frame = 1
+	}
+	hangover := int(cfg.Hangover / cfg.Frame)
+	if hangover < 1 {
+		hangover = 1
+	}
+	s := &Segmenter{
+		frame:    frame,
+		hangover: hangover,
+		minLen:   samples(cfg.MinChunk),
+		maxLen:   samples(cfg.MaxChunk),
+		preRoll:  samples(cfg.PreRoll),
+		thresh:   cfg.Threshold,
+		margin:   cfg.NoiseMargin,
+	}
+	if cfg.Calibration > 0 {
+		s.noise = newNoiseFloor(int(cfg.Calibration / cfg.Frame))
+	}
+	return s
+}
+
+// Level describes the input level of a stream, for mic-level feedback
+type Level struct {
+	RMS        float64 `json:"rms"`        // latest frame, 0-1
+	NoiseFloor float64 `json:"noiseFloor"` // estimated background level; 0 until calibrated
+	Threshold  float64 `json:"threshold"`  // level above which a frame is speech
+	Calibrated bool    `json:"calibrated"`
+}
+
+// Threshold returns the frame RMS above which a frame currently counts as
+// speech: the configured threshold, raised to NoiseMargin times the noise
+// floor once calibrated
+func (s *Segmenter) Threshold() float64 {
+	return max(s.thresh, s.NoiseThreshold())
+}
+
+// NoiseThreshold returns NoiseMargin times the noise floor, or 0 until
+// calibrated
+func (s *Segmenter) NoiseThreshold() float64 {
+	if s.noise == nil || !s.noise.calibrated {
+		return 0
+	}
+	return s.noise.floor * s.margin
+}
+
+// Level returns the current input level, noise floor and threshold
+func (s *Segmenter) Level() Level {
+	level := Level{RMS: s.level, Threshold: s.Threshold()}
+	if s.noise != nil && s.noise.calibrated {
+		level.NoiseFloor = s.noise.floor
+		level.Calibrated = true
+	}
+	return level
+}
+
+// SetThreshold changes the configured speech threshold; 0 restores the
+// default
+func (s *Segmenter) SetThreshold(threshold float64) {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	s.thresh = threshold
+}
+
+// Recalibrate measures the noise floor again from the coming audio, e.g.
+// after the speaker moved or changed microphones. No-op without calibration.
+func (s *Segmenter) Recalibrate() {
+	if s.noise != nil {
+		s.noise = newNoiseFloor(s.noise.frames)
+	}
+}
+
+// Push adds samples and returns any chunks they complete
+func (s *Segmenter) Push(samples []int16) [][]int16 {
+	var out [][]int16
+	s.pending = append(s.pending, samples...)
+	for len(s.pending) >= s.frame {
+		frame := s.pending[:s.frame]
+		if chunk := s.addFrame(frame); chunk != nil {
+			out = append(out, chunk)
+		}
+		s.pending = s.pending[s.frame:]
+	}
+	// Keep the leftover in its own array so pending does not pin old audio
+	s.pending = append([]int16(nil), s.pending...)
+	return out
+}
+
+// Flush returns whatever audio is buffered, speech or not, and resets the
+// segmenter. It returns nil when nothing is buffered.
+func (s *Segmenter) Flush() []int16 {
+	chunk := append(s.chunk, s.pending...)
+	s.chunk = nil
+	s.pending = nil
+	s.speech = false
+	s.silent = 0
+	s.lastCut = 0
+	s.speaking = false
+	s.voicedRun, s.quietRun = 0, 0
+	if len(chunk) == 0 {
+		return nil
+	}
+	return chunk
+}
+
+// Speaking reports whether the stream is currently speech: set after a few
+// voiced frames in a row, cleared after the hangover of silence
+func (s *Segmenter) Speaking() bool {
+	return s.speaking
+}
+
+// Buffered returns the number of samples held back waiting for a boundary
+func (s *Segmenter) Buffered() int {
+	return len(s.chunk) + len(s.pending)
+}
+
+// InProgress returns a copy of the chunk being collected once it contains
+// speech, for previewing it before it is cut; nil otherwise
+func (s *Segmenter) InProgress() []int16 {
+	if !s.speech {
+		return nil
+	}
+	return append(append([]int16(nil), s.chunk...), s.pending...)
+}
+
+func (s *Segmenter) addFrame(frame []int16) []int16 {
+	s.level = RMS(frame)
+	voiced := s.level > s.Threshold()
+	if s.noise != nil {
+		s.noise.observe(s.level, voiced)
+	}
+	s.trackSpeaking(voiced)
+	s.chunk = append(s.chunk, frame...)
+
+	if !s.speech {
+		if !voiced {
+			// Only keep a short lead-in of silence before speech starts
+			if excess := len(s.chunk) - s.preRoll; excess > 0 {
+				s.chunk = append(s.chunk[:0], s.chunk[excess:]...)
+			}
+			return nil
+		}
+		s.speech = true
+	}
+
+	if voiced {
+		s.silent = 0
+	} else {
+		s.silent++
+		s.lastCut = len(s.chunk)
+	}
+
+	switch {
+	case s.silent >= s.hangover && len(s.chunk) >= s.minLen:
+		return s.cut(len(s.chunk))
+	case len(s.chunk) >= s.maxLen:
+		// No pause long enough: cut at the latest silent frame, if any
+		// falls in the second half, so the carried-over audio stays short
+		at := len(s.chunk)
+		if s.lastCut > len(s.chunk)/2 {
+			at = s.lastCut
+		}
+		return s.cut(at)
+	}
+	return nil
+}
+
+// trackSpeaking updates the speaking state with the latest frame
+func (s *Segmenter) trackSpeaking(voiced bool) {
+	if voiced {
+		s.voicedRun++
+		s.quietRun = 0
+		if s.voicedRun >= speakingOnset {
+			s.speaking = true
+		}
+		return
+	}
+	s.quietRun++
+	s.voicedRun = 0
+	if s.quietRun >= s.hangover {
+		s.speaking = false
+	}
+}
+
+// cut returns chunk[:at] and starts the next chunk with the remainder
+func (s *Segmenter) cut(at int) []int16 {
+	out := s.chunk[:at:at]
+	rest := append([]int16(nil), s.chunk[at:]...)
+	s.chunk = rest
+	s.silent = 0
+	s.lastCut = 0
+	s.speech = false
+	for i := 0; i+s.frame <= len(rest); i += s.frame {
+		if RMS(rest[i:i+s.frame]) > s.Threshold() {
+			s.speech = true
+			break
+		}
+	}
+	return out
+}
+
+// RMS returns the root mean square of the samples, normalized to 0-1
+func RMS(samples []int16) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, sample := range samples {
+		v := float64(sample) / 32768.0
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
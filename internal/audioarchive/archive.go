@@ -0,0 +1,185 @@
+// Package audioarchive keeps the audio of processed recording and meeting
+// chunks in object storage, so a disputed caption can be checked against what
+// the system actually heard. Chunks are stored as WAV or, to save space, as
+// FLAC, and deleted once their retention passes.
+package audioarchive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/metrics"
+	"realtime-caption-translator/internal/storage"
+)
+
+// Formats chunks can be stored in
+const (
+	FormatWAV  = "wav"
+	FormatFLAC = "flac"
+)
+
+// Session types chunks belong to
+const (
+	SessionRecording = "recording"
+	SessionMeeting   = "meeting"
+)
+
+// sweepBatch bounds how many expired chunks one sweep deletes
+const sweepBatch = 500
+
+// Archive stores chunk audio; a nil *Archive stores nothing
+type Archive struct {
+	store     *storage.MinioClient
+	format    string
+	retention time.Duration
+}
+
+// New returns an archive writing to store, or nil when object storage is
+// disabled. Formats other than FLAC store WAV.
+func New(store *storage.MinioClient, format string, retention time.Duration) *Archive {
+	if !store.Enabled() || retention <= 0 {
+		return nil
+	}
+	if format != FormatFLAC {
+		format = FormatWAV
+	}
+	return &Archive{store: store, format: format, retention: retention}
+}
+
+// Chunk describes one chunk to archive
+type Chunk struct {
+	SessionType   string
+	SessionID     string
+	Index         int // recording chunk index; 0 for meetings
+	ParticipantID int // meeting speaker
+	WAV           []byte
+	Duration      float64 // seconds
+}
+
+// Store uploads a chunk and records it, returning the reference captions
+// carry as audioRef. A nil archive returns 0.
+func (a *Archive) Store(ctx context.Context, chunk Chunk) (int64, error) {
+	if a == nil {
+		return 0, nil
+	}
+
+	data, format, contentType := chunk.WAV, FormatWAV, "audio/wav"
+	if a.format == FormatFLAC {
+		flac, err := encodeFLAC(ctx, chunk.WAV)
+		if err != nil {
+			// Keep the audio even if compression fails
+			log.Printf("[AudioArchive] FLAC encoding failed, storing WAV: %v", err)
+		} else {
+			data, format, contentType = flac, FormatFLAC, "audio/flac"
+		}
+	}
+
+	key := storage.SafeObjectKey("archive", chunk.SessionType, chunk.SessionID,
+		fmt.Sprintf("%d_%d.%s", time.Now().UnixNano(), chunk.Index, format))
+	_, size, err := a.store.UploadBytes(ctx, key, data, contentType)
+	if err != nil {
+		return 0, fmt.Errorf("upload chunk audio: %w", err)
+	}
+
+	id, err := database.CreateAudioChunk(&database.AudioChunk{
+		SessionType:     chunk.SessionType,
+		SessionID:       chunk.SessionID,
+		ChunkIndex:      chunk.Index,
+		ParticipantID:   chunk.ParticipantID,
+		BucketName:      a.store.Bucket(),
+		FileKey:         key,
+		Format:          format,
+		DurationSeconds: chunk.Duration,
+		FileSizeBytes:   size,
+		ExpiresAt:       time.Now().UTC().Add(a.retention),
+	})
+	if err != nil {
+		_ = a.store.Remove(ctx, key)
+		return 0, err
+	}
+	return id, nil
+}
+
+// URL returns a presigned link to an archived chunk
+func (a *Archive) URL(ctx context.Context, chunk *database.AudioChunk) (string, error) {
+	if a == nil {
+		return "", fmt.Errorf("audio archive disabled")
+	}
+	return a.store.PresignedDownloadURL(ctx, chunk.FileKey, fmt.Sprintf("chunk_%d.%s", chunk.ID, chunk.Format), 0)
+}
+
+// Sweep deletes chunks past their retention and returns how many went
+func (a *Archive) Sweep(ctx context.Context) (int, error) {
+	if a == nil {
+		return 0, nil
+	}
+	expired, err := database.ListExpiredAudioChunks(sweepBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, chunk := range expired {
+		if chunk.BucketName == a.store.Bucket() {
+			if err := a.store.Remove(ctx, chunk.FileKey); err != nil {
+				log.Printf("[AudioArchive] Failed to remove %s: %v", chunk.FileKey, err)
+				continue
+			}
+		}
+		if err := database.DeleteAudioChunk(chunk.ID); err != nil {
+			log.Printf("[AudioArchive] Failed to delete chunk %d: %v", chunk.ID, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// StartSweeper runs Sweep on an interval until stop is closed
+func (a *Archive) StartSweeper(interval time.Duration, stop <-chan struct{}) {
+	if a == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := a.Sweep(context.Background())
+			if err != nil {
+				log.Printf("[AudioArchive] Sweep failed: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("[AudioArchive] Deleted %d expired chunk(s)", deleted)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// encodeFLAC compresses WAV audio with ffmpeg
+func encodeFLAC(ctx context.Context, wav []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-loglevel", "error",
+		"-f", "wav", "-i", "pipe:0", "-f", "flac", "pipe:1")
+	cmd.Stdin = bytes.NewReader(wav)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	metrics.FFmpegDuration.ObserveSince(start, "archive_flac")
+	if err != nil {
+		metrics.StageErrors.Inc(metrics.StageFFmpeg)
+		return nil, fmt.Errorf("ffmpeg: %w, stderr: %s", err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
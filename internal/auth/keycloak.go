@@ -196,3 +196,22 @@ func parseRSAPublicKey(n, e string) (*rsa.PublicKey, error) {
 		E: eInt,
 	}, nil
 }
+
+// HasRealmRole reports whether verified claims grant a Keycloak realm role
+// (realm_access.roles)
+func HasRealmRole(claims jwt.MapClaims, role string) bool {
+	realmAccess, ok := claims["realm_access"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	roles, ok := realmAccess["roles"].([]interface{})
+	if !ok {
+		return false
+	}
+	for _, r := range roles {
+		if name, ok := r.(string); ok && name == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package benchmark
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sample is one labeled utterance of the benchmark corpus
+type Sample struct {
+	ID        string `json:"id"`
+	Audio     string `json:"audio"`    // WAV path, relative to the manifest
+	Text      string `json:"text"`     // reference transcript
+	Language  string `json:"language"` // ISO code passed to the ASR backend
+	AudioPath string `json:"-"`
+}
+
+// LoadCorpus reads a JSONL manifest with one Sample per line. Audio paths
+// are resolved relative to the manifest's directory.
+func LoadCorpus(manifestPath string) ([]Sample, error) {
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer file.Close()
+
+	baseDir := filepath.Dir(manifestPath)
+	var samples []Sample
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var sample Sample
+		if err := json.Unmarshal([]byte(line), &sample); err != nil {
+			return nil, fmt.Errorf("manifest line %d: %w", lineNo, err)
+		}
+		if sample.Audio == "" || sample.Text == "" {
+			return nil, fmt.Errorf("manifest line %d: audio and text are required", lineNo)
+		}
+		if sample.ID == "" {
+			sample.ID = strings.TrimSuffix(filepath.Base(sample.Audio), filepath.Ext(sample.Audio))
+		}
+		sample.AudioPath = sample.Audio
+		if !filepath.IsAbs(sample.AudioPath) {
+			sample.AudioPath = filepath.Join(baseDir, sample.Audio)
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	return samples, nil
+}
+
+// ReadWAV decodes a PCM16 mono WAV file
+func ReadWAV(path string) ([]int16, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, 0, fmt.Errorf("%s: not a WAV file", path)
+	}
+
+	var sampleRate int
+	var formatSeen bool
+	offset := 12
+	for offset+8 <= len(data) {
+		chunkID := string(data[offset : offset+4])
+		chunkSize := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+		if body+chunkSize > len(data) {
+			chunkSize = len(data) - body
+		}
+
+		switch chunkID {
+		case "fmt ":
+			if chunkSize < 16 {
+				return nil, 0, fmt.Errorf("%s: invalid fmt chunk", path)
+			}
+			audioFormat := binary.LittleEndian.Uint16(data[body : body+2])
+			channels := binary.LittleEndian.Uint16(data[body+2 : body+4])
+			bitsPerSample := binary.LittleEndian.Uint16(data[body+14 : body+16])
+			if audioFormat != 1 || channels != 1 || bitsPerSample != 16 {
+				return nil, 0, fmt.Errorf("%s: expected PCM16 mono (got format %d, %d channels, %d bits)", path, audioFormat, channels, bitsPerSample)
+			}
+			sampleRate = int(binary.LittleEndian.Uint32(data[body+4 : body+8]))
+			formatSeen = true
+		case "data":
+			if !formatSeen {
+				return nil, 0, fmt.Errorf("%s: data chunk before fmt chunk", path)
+			}
+			pcm := make([]int16, chunkSize/2)
+			for i := range pcm {
+				pcm[i] = int16(binary.LittleEndian.Uint16(data[body+i*2 : body+i*2+2]))
+			}
+			return pcm, sampleRate, nil
+		}
+
+		// chunks are word aligned
+		offset = body + chunkSize + chunkSize%2
+	}
+
+	return nil, 0, fmt.Errorf("%s: no data chunk", path)
+}
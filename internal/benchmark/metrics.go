@@ -0,0 +1,98 @@
+package benchmark
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ErrorRate is an edit-distance based error rate (WER or CER)
+type ErrorRate struct {
+	Errors    int     `json:"errors"`    // substitutions + deletions + insertions
+	Reference int     `json:"reference"` // reference length in tokens
+	Rate      float64 `json:"rate"`
+}
+
+// Add accumulates another score so rates can be pooled over a corpus
+func (e *ErrorRate) Add(other ErrorRate) {
+	e.Errors += other.Errors
+	e.Reference += other.Reference
+	e.Rate = rate(e.Errors, e.Reference)
+}
+
+// Normalize lowercases text, strips punctuation and collapses whitespace so
+// formatting differences between ASR backends are not counted as errors
+func Normalize(text string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), unicode.IsMark(r):
+			builder.WriteRune(r)
+		case r == '\'':
+			// keep contractions ("don't") intact
+			builder.WriteRune(r)
+		default:
+			builder.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// WER computes the word error rate of hypothesis against reference
+func WER(reference, hypothesis string) ErrorRate {
+	ref := strings.Fields(Normalize(reference))
+	hyp := strings.Fields(Normalize(hypothesis))
+	errors := editDistance(ref, hyp)
+	return ErrorRate{Errors: errors, Reference: len(ref), Rate: rate(errors, len(ref))}
+}
+
+// CER computes the character error rate of hypothesis against reference.
+// Whitespace is ignored so it also works for languages written without spaces.
+func CER(reference, hypothesis string) ErrorRate {
+	ref := characters(Normalize(reference))
+	hyp := characters(Normalize(hypothesis))
+	errors := editDistance(ref, hyp)
+	return ErrorRate{Errors: errors, Reference: len(ref), Rate: rate(errors, len(ref))}
+}
+
+func characters(text string) []string {
+	chars := make([]string, 0, len(text))
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			chars = append(chars, string(r))
+		}
+	}
+	return chars
+}
+
+func rate(errors, reference int) float64 {
+	if reference == 0 {
+		if errors == 0 {
+			return 0
+		}
+		return 1
+	}
+	return float64(errors) / float64(reference)
+}
+
+// editDistance is the Levenshtein distance between two token sequences
+func editDistance(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
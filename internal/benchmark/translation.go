@@ -0,0 +1,201 @@
+package benchmark
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// TranslationPair is one source sentence with its reference translation
+type TranslationPair struct {
+	ID         string `json:"id"`
+	Source     string `json:"source"`
+	Reference  string `json:"reference"`
+	SourceLang string `json:"sourceLang"`
+	TargetLang string `json:"targetLang"`
+}
+
+// LanguagePair returns the "src-tgt" key the pair is reported under
+func (p TranslationPair) LanguagePair() string {
+	return p.SourceLang + "-" + p.TargetLang
+}
+
+// LoadReferenceSet reads a JSONL reference set with one TranslationPair per line
+func LoadReferenceSet(path string) ([]TranslationPair, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reference set: %w", err)
+	}
+	defer file.Close()
+
+	var pairs []TranslationPair
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var pair TranslationPair
+		if err := json.Unmarshal([]byte(line), &pair); err != nil {
+			return nil, fmt.Errorf("reference set line %d: %w", lineNo, err)
+		}
+		if pair.Source == "" || pair.Reference == "" || pair.SourceLang == "" || pair.TargetLang == "" {
+			return nil, fmt.Errorf("reference set line %d: source, reference, sourceLang and targetLang are required", lineNo)
+		}
+		if pair.ID == "" {
+			pair.ID = fmt.Sprintf("%s-%d", pair.LanguagePair(), lineNo)
+		}
+		pairs = append(pairs, pair)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read reference set: %w", err)
+	}
+
+	return pairs, nil
+}
+
+const (
+	bleuMaxOrder = 4
+	chrfMaxOrder = 6
+	chrfBeta     = 2
+)
+
+// BLEU accumulates corpus-level BLEU statistics (Papineni et al.). Sentences
+// are added one at a time; Score pools n-gram matches over all of them.
+type BLEU struct {
+	matches   [bleuMaxOrder]int
+	totals    [bleuMaxOrder]int
+	hypLength int
+	refLength int
+}
+
+// Add scores one hypothesis against its reference
+func (b *BLEU) Add(reference, hypothesis string) {
+	ref := tokenize(reference)
+	hyp := tokenize(hypothesis)
+	b.refLength += len(ref)
+	b.hypLength += len(hyp)
+
+	for n := 1; n <= bleuMaxOrder; n++ {
+		matched, total := ngramOverlap(ref, hyp, n)
+		b.matches[n-1] += matched
+		b.totals[n-1] += total
+	}
+}
+
+// Score returns BLEU on a 0-100 scale
+func (b *BLEU) Score() float64 {
+	if b.hypLength == 0 {
+		return 0
+	}
+
+	var logPrecision float64
+	for n := 0; n < bleuMaxOrder; n++ {
+		if b.matches[n] == 0 || b.totals[n] == 0 {
+			return 0
+		}
+		logPrecision += math.Log(float64(b.matches[n]) / float64(b.totals[n]))
+	}
+	logPrecision /= bleuMaxOrder
+
+	brevity := 1.0
+	if b.hypLength < b.refLength {
+		brevity = math.Exp(1 - float64(b.refLength)/float64(b.hypLength))
+	}
+
+	return 100 * brevity * math.Exp(logPrecision)
+}
+
+// ChrF accumulates corpus-level chrF statistics (Popović, 2015): character
+// n-gram F-score with n up to 6 and recall weighted by beta = 2.
+type ChrF struct {
+	matches  [chrfMaxOrder]int
+	hypGrams [chrfMaxOrder]int
+	refGrams [chrfMaxOrder]int
+}
+
+// Add scores one hypothesis against its reference
+func (c *ChrF) Add(reference, hypothesis string) {
+	ref := characters(strings.ToLower(reference))
+	hyp := characters(strings.ToLower(hypothesis))
+
+	for n := 1; n <= chrfMaxOrder; n++ {
+		matched, hypTotal := ngramOverlap(ref, hyp, n)
+		c.matches[n-1] += matched
+		c.hypGrams[n-1] += hypTotal
+		if len(ref) >= n {
+			c.refGrams[n-1] += len(ref) - n + 1
+		}
+	}
+}
+
+// Score returns chrF on a 0-100 scale
+func (c *ChrF) Score() float64 {
+	var precision, recall float64
+	orders := 0
+	for n := 0; n < chrfMaxOrder; n++ {
+		if c.hypGrams[n] == 0 || c.refGrams[n] == 0 {
+			continue
+		}
+		precision += float64(c.matches[n]) / float64(c.hypGrams[n])
+		recall += float64(c.matches[n]) / float64(c.refGrams[n])
+		orders++
+	}
+	if orders == 0 {
+		return 0
+	}
+	precision /= float64(orders)
+	recall /= float64(orders)
+	if precision == 0 && recall == 0 {
+		return 0
+	}
+
+	beta2 := float64(chrfBeta * chrfBeta)
+	return 100 * (1 + beta2) * precision * recall / (beta2*precision + recall)
+}
+
+// tokenize splits text into lowercase words and separate punctuation marks,
+// close to the 13a tokenizer used by sacreBLEU
+func tokenize(text string) []string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			builder.WriteRune(' ')
+			builder.WriteRune(r)
+			builder.WriteRune(' ')
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return strings.Fields(builder.String())
+}
+
+// ngramOverlap counts clipped n-gram matches of hyp against ref and the
+// number of n-grams in hyp
+func ngramOverlap(ref, hyp []string, n int) (matched, total int) {
+	if len(hyp) < n {
+		return 0, 0
+	}
+
+	refCounts := make(map[string]int)
+	for i := 0; i+n <= len(ref); i++ {
+		refCounts[strings.Join(ref[i:i+n], "\x00")]++
+	}
+	for i := 0; i+n <= len(hyp); i++ {
+		gram := strings.Join(hyp[i:i+n], "\x00")
+		if refCounts[gram] > 0 {
+			refCounts[gram]--
+			matched++
+		}
+		total++
+	}
+	return matched, total
+}
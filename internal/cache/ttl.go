@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// TTL is a size-bounded in-memory cache whose entries expire after a fixed
+// duration. A nil *TTL is a disabled cache: Get always misses and Set is a no-op.
+type TTL[K comparable, V any] struct {
+	name       string
+	ttl        time.Duration
+	maxEntries int
+
+	mu     sync.Mutex
+	items  map[K]entry[V]
+	hits   int64
+	misses int64
+}
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// Stats is a snapshot of cache metrics
+type Stats struct {
+	Name       string `json:"name"`
+	Entries    int    `json:"entries"`
+	MaxEntries int    `json:"maxEntries"`
+	TTLSeconds int    `json:"ttlSeconds"`
+	Hits       int64  `json:"hits"`
+	Misses     int64  `json:"misses"`
+}
+
+// New creates a cache. Returns nil (caching disabled) when ttl <= 0.
+func New[K comparable, V any](name string, ttl time.Duration, maxEntries int) *TTL[K, V] {
+	if ttl <= 0 {
+		return nil
+	}
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &TTL[K, V]{
+		name:       name,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		items:      make(map[K]entry[V]),
+	}
+}
+
+// Get returns the cached value for key if present and not expired
+func (c *TTL[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if c == nil {
+		return zero, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[key]
+	if !ok || time.Now().After(item.expiresAt) {
+		if ok {
+			delete(c.items, key)
+		}
+		c.misses++
+		return zero, false
+	}
+
+	c.hits++
+	return item.value, true
+}
+
+// Set stores value under key, evicting expired entries (then the entry closest
+// to expiry) when the cache is full
+func (c *TTL[K, V]) Set(key K, value V) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if _, exists := c.items[key]; !exists && len(c.items) >= c.maxEntries {
+		var oldestKey K
+		var oldest time.Time
+		for k, item := range c.items {
+			if now.After(item.expiresAt) {
+				delete(c.items, k)
+				continue
+			}
+			if oldest.IsZero() || item.expiresAt.Before(oldest) {
+				oldestKey, oldest = k, item.expiresAt
+			}
+		}
+		if len(c.items) >= c.maxEntries {
+			delete(c.items, oldestKey)
+		}
+	}
+
+	c.items[key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// Clear removes all entries and resets hit/miss counters
+func (c *TTL[K, V]) Clear() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[K]entry[V])
+	c.hits = 0
+	c.misses = 0
+}
+
+// Stats returns a snapshot of the cache metrics
+func (c *TTL[K, V]) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return Stats{
+		Name:       c.name,
+		Entries:    len(c.items),
+		MaxEntries: c.maxEntries,
+		TTLSeconds: int(c.ttl.Seconds()),
+		Hits:       c.hits,
+		Misses:     c.misses,
+	}
+}
@@ -0,0 +1,239 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"realtime-caption-translator/internal/langcode"
+)
+
+// Action item statuses
+const (
+	ActionItemOpen = "open"
+	ActionItemDone = "done"
+)
+
+// ErrInvalidActionItem is returned for updates with invalid values
+var ErrInvalidActionItem = errors.New("invalid action item")
+
+// dueDateLayout is the format of action item due dates
+const dueDateLayout = "2006-01-02"
+
+// MeetingActionItem is an action item extracted from a meeting's minutes
+type MeetingActionItem struct {
+	ID             int        `json:"id"`
+	MeetingID      string     `json:"meetingId"`
+	Language       string     `json:"language"`
+	Position       int        `json:"position"`
+	Text           string     `json:"text"`
+	Assignee       string     `json:"assignee,omitempty"`
+	DueDate        string     `json:"dueDate,omitempty"` // YYYY-MM-DD
+	SourceChunkIDs []int      `json:"sourceChunkIds"`
+	Status         string     `json:"status"`
+	Edited         bool       `json:"edited"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+	CompletedBy    *int       `json:"completedBy,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+const actionItemColumns = `id, meeting_id, language, position, text, assignee, due_date, source_chunk_ids,
+	status, edited, completed_at, completed_by, created_at, updated_at`
+
+func scanActionItem(row interface{ Scan(...interface{}) error }) (*MeetingActionItem, error) {
+	var item MeetingActionItem
+	var assignee sql.NullString
+	var dueDate, completedAt sql.NullTime
+	var completedBy sql.NullInt64
+	var chunkIDs []int64
+	if err := row.Scan(&item.ID, &item.MeetingID, &item.Language, &item.Position, &item.Text, &assignee,
+		&dueDate, pq.Array(&chunkIDs), &item.Status, &item.Edited, &completedAt, &completedBy,
+		&item.CreatedAt, &item.UpdatedAt); err != nil {
+		return nil, err
+	}
+	item.Assignee = assignee.String
+	if dueDate.Valid {
+		item.DueDate = dueDate.Time.Format(dueDateLayout)
+	}
+	item.SourceChunkIDs = make([]int, len(chunkIDs))
+	for i, id := range chunkIDs {
+		item.SourceChunkIDs[i] = int(id)
+	}
+	if completedAt.Valid {
+		item.CompletedAt = &completedAt.Time
+	}
+	item.CompletedBy = nullIntPtr(completedBy)
+	return &item, nil
+}
+
+// parseDueDate validates a YYYY-MM-DD due date; "" means none
+func parseDueDate(value string) (interface{}, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(dueDateLayout, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: due date must be YYYY-MM-DD", ErrInvalidActionItem)
+	}
+	return date, nil
+}
+
+// ReplaceExtractedActionItems stores the action items of freshly generated
+// minutes for a meeting/language. Earlier extracted items are replaced;
+// items users edited or completed are kept, and new items repeating one of
+// them are skipped.
+func ReplaceExtractedActionItems(meetingID, language string, items []MeetingActionItem) error {
+	language = langcode.Normalize(language)
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		DELETE FROM meeting_action_items
+		WHERE meeting_id = $1 AND language = $2 AND NOT edited AND status = $3
+	`, meetingID, language, ActionItemOpen); err != nil {
+		return fmt.Errorf("failed to clear extracted action items: %w", err)
+	}
+
+	rows, err := tx.Query(`SELECT LOWER(text) FROM meeting_action_items WHERE meeting_id = $1 AND language = $2`, meetingID, language)
+	if err != nil {
+		return fmt.Errorf("failed to load kept action items: %w", err)
+	}
+	kept := make(map[string]bool)
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan action item: %w", err)
+		}
+		kept[text] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to load kept action items: %w", err)
+	}
+
+	for position, item := range items {
+		text := strings.TrimSpace(item.Text)
+		if text == "" || kept[strings.ToLower(text)] {
+			continue
+		}
+		dueDate, err := parseDueDate(item.DueDate)
+		if err != nil {
+			dueDate = nil
+		}
+		chunkIDs := make([]int64, len(item.SourceChunkIDs))
+		for i, id := range item.SourceChunkIDs {
+			chunkIDs[i] = int64(id)
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO meeting_action_items (meeting_id, language, position, text, assignee, due_date, source_chunk_ids)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, meetingID, language, position, text, nullString(strings.TrimSpace(item.Assignee)), dueDate, pq.Array(chunkIDs)); err != nil {
+			return fmt.Errorf("failed to save action item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit action items: %w", err)
+	}
+	return nil
+}
+
+// ListMeetingActionItems returns a meeting's action items in minutes order.
+// Empty language or status match every item.
+func ListMeetingActionItems(meetingID, language, status string) ([]MeetingActionItem, error) {
+	rows, err := DB.Query(`
+		SELECT `+actionItemColumns+`
+		FROM meeting_action_items
+		WHERE meeting_id = $1 AND ($2 = '' OR language = $2) AND ($3 = '' OR status = $3)
+		ORDER BY language, position, id
+	`, meetingID, langcode.Normalize(language), status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list action items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []MeetingActionItem{}
+	for rows.Next() {
+		item, err := scanActionItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan action item: %w", err)
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// ActionItemUpdate holds the fields of an action item to change; nil
+// fields are left as they are. An empty Assignee or DueDate clears it.
+type ActionItemUpdate struct {
+	Text     *string `json:"text"`
+	Assignee *string `json:"assignee"`
+	DueDate  *string `json:"dueDate"`
+	Status   *string `json:"status"`
+}
+
+// UpdateMeetingActionItem applies an update to one of a meeting's action
+// items. Completing an item records when and by whom. Returns nil when the
+// item does not exist.
+func UpdateMeetingActionItem(meetingID string, id int, update ActionItemUpdate, userID int) (*MeetingActionItem, error) {
+	sets := []string{"updated_at = NOW()"}
+	args := []interface{}{meetingID, id}
+	set := func(clause string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf(clause, len(args)))
+	}
+
+	if update.Text != nil {
+		text := strings.TrimSpace(*update.Text)
+		if text == "" {
+			return nil, fmt.Errorf("%w: text must not be empty", ErrInvalidActionItem)
+		}
+		set("text = $%d", text)
+	}
+	if update.Assignee != nil {
+		set("assignee = $%d", nullString(strings.TrimSpace(*update.Assignee)))
+	}
+	if update.DueDate != nil {
+		dueDate, err := parseDueDate(strings.TrimSpace(*update.DueDate))
+		if err != nil {
+			return nil, err
+		}
+		set("due_date = $%d", dueDate)
+	}
+	if update.Text != nil || update.Assignee != nil || update.DueDate != nil {
+		sets = append(sets, "edited = TRUE")
+	}
+	if update.Status != nil {
+		switch *update.Status {
+		case ActionItemDone:
+			set("status = $%d", ActionItemDone)
+			set("completed_at = CASE WHEN status = 'done' THEN completed_at ELSE NOW() END, completed_by = CASE WHEN status = 'done' THEN completed_by ELSE $%d END", userID)
+		case ActionItemOpen:
+			set("status = $%d", ActionItemOpen)
+			sets = append(sets, "completed_at = NULL", "completed_by = NULL")
+		default:
+			return nil, fmt.Errorf("%w: status must be open or done", ErrInvalidActionItem)
+		}
+	}
+
+	item, err := scanActionItem(DB.QueryRow(`
+		UPDATE meeting_action_items SET `+strings.Join(sets, ", ")+`
+		WHERE meeting_id = $1 AND id = $2
+		RETURNING `+actionItemColumns, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update action item: %w", err)
+	}
+	return item, nil
+}
@@ -0,0 +1,96 @@
+package database
+
+import "fmt"
+
+// StorageUsage summarizes database and object storage consumption
+type StorageUsage struct {
+	DatabaseBytes int64            `json:"databaseBytes"`
+	FileBytes     map[string]int64 `json:"fileBytes"` // session type -> bytes stored in object storage
+	FileCount     int              `json:"fileCount"`
+}
+
+// GetGlobalChunkStatusCounts returns RAG chunk counts by processing status across all sources
+func GetGlobalChunkStatusCounts() (map[string]int, error) {
+	rows, err := DB.Query(`
+		SELECT processing_status, COUNT(*)
+		FROM meeting_chunks
+		GROUP BY processing_status
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count chunk statuses: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk status count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// GetJobStatusCounts counts processing_jobs by kind and status
+func GetJobStatusCounts() (map[string]map[string]int, error) {
+	rows, err := DB.Query(`
+		SELECT kind, status, COUNT(*)
+		FROM processing_jobs
+		GROUP BY kind, status
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count job statuses: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]map[string]int)
+	for rows.Next() {
+		var kind, status string
+		var count int
+		if err := rows.Scan(&kind, &status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan job status count: %w", err)
+		}
+		if counts[kind] == nil {
+			counts[kind] = make(map[string]int)
+		}
+		counts[kind][status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// GetStorageUsage reports the database size and the bytes of uploaded files
+// tracked in user_files
+func GetStorageUsage() (*StorageUsage, error) {
+	usage := &StorageUsage{FileBytes: make(map[string]int64)}
+
+	if err := DB.QueryRow(`SELECT pg_database_size(current_database())`).Scan(&usage.DatabaseBytes); err != nil {
+		return nil, fmt.Errorf("failed to get database size: %w", err)
+	}
+
+	rows, err := DB.Query(`
+		SELECT session_type, COUNT(*), COALESCE(SUM(file_size_bytes), 0)
+		FROM user_files
+		GROUP BY session_type
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file usage: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sessionType string
+		var count int
+		var bytes int64
+		if err := rows.Scan(&sessionType, &count, &bytes); err != nil {
+			return nil, fmt.Errorf("failed to scan file usage: %w", err)
+		}
+		usage.FileBytes[sessionType] = bytes
+		usage.FileCount += count
+	}
+
+	return usage, rows.Err()
+}
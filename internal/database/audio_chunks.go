@@ -0,0 +1,101 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// AudioChunk is the archived audio of one chunk sent to ASR
+type AudioChunk struct {
+	ID              int64     `json:"id"`
+	SessionType     string    `json:"sessionType"`
+	SessionID       string    `json:"sessionId"`
+	ChunkIndex      int       `json:"chunkIndex"`
+	ParticipantID   int       `json:"participantId,omitempty"`
+	BucketName      string    `json:"-"`
+	FileKey         string    `json:"fileKey"`
+	Format          string    `json:"format"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	FileSizeBytes   int64     `json:"fileSizeBytes"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// CreateAudioChunk records an archived chunk and returns its ID
+func CreateAudioChunk(chunk *AudioChunk) (int64, error) {
+	var participant interface{}
+	if chunk.ParticipantID != 0 {
+		participant = chunk.ParticipantID
+	}
+
+	var id int64
+	err := DB.QueryRow(`
+		INSERT INTO audio_chunks (
+			session_type, session_id, chunk_index, participant_id, bucket_name, file_key,
+			format, duration_seconds, file_size_bytes, expires_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`, chunk.SessionType, chunk.SessionID, chunk.ChunkIndex, participant, chunk.BucketName, chunk.FileKey,
+		chunk.Format, chunk.DurationSeconds, chunk.FileSizeBytes, chunk.ExpiresAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record audio chunk: %w", err)
+	}
+	return id, nil
+}
+
+// GetAudioChunk returns an unexpired archived chunk, or nil if there is none
+func GetAudioChunk(id int64) (*AudioChunk, error) {
+	var chunk AudioChunk
+	var participant sql.NullInt64
+	err := DB.QueryRow(`
+		SELECT id, session_type, session_id, chunk_index, participant_id, bucket_name, file_key,
+		       format, duration_seconds, file_size_bytes, expires_at, created_at
+		FROM audio_chunks
+		WHERE id = $1 AND expires_at > NOW()
+	`, id).Scan(&chunk.ID, &chunk.SessionType, &chunk.SessionID, &chunk.ChunkIndex, &participant,
+		&chunk.BucketName, &chunk.FileKey, &chunk.Format, &chunk.DurationSeconds, &chunk.FileSizeBytes,
+		&chunk.ExpiresAt, &chunk.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audio chunk: %w", err)
+	}
+	chunk.ParticipantID = int(participant.Int64)
+	return &chunk, nil
+}
+
+// ListExpiredAudioChunks returns up to limit chunks past their retention
+func ListExpiredAudioChunks(limit int) ([]AudioChunk, error) {
+	rows, err := DB.Query(`
+		SELECT id, bucket_name, file_key
+		FROM audio_chunks
+		WHERE expires_at <= NOW()
+		ORDER BY expires_at
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired audio chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []AudioChunk
+	for rows.Next() {
+		var chunk AudioChunk
+		if err := rows.Scan(&chunk.ID, &chunk.BucketName, &chunk.FileKey); err != nil {
+			return nil, fmt.Errorf("failed to scan audio chunk: %w", err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, rows.Err()
+}
+
+// DeleteAudioChunk removes an archived chunk's row
+func DeleteAudioChunk(id int64) error {
+	if _, err := DB.Exec(`DELETE FROM audio_chunks WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete audio chunk: %w", err)
+	}
+	return nil
+}
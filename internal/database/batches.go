@@ -0,0 +1,192 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"realtime-caption-translator/internal/langcode"
+)
+
+// Batch item statuses; running is derived from the item's job
+const (
+	BatchItemQueued    = "queued"
+	BatchItemRunning   = "running"
+	BatchItemSucceeded = "succeeded"
+	BatchItemFailed    = "failed"
+)
+
+// UploadBatch is a set of uploads submitted in one request
+type UploadBatch struct {
+	BatchID    string            `json:"batchId"`
+	UserID     *int              `json:"userId,omitempty"`
+	SourceLang string            `json:"sourceLang"`
+	TargetLang string            `json:"targetLang"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	Items      []UploadBatchItem `json:"files"`
+}
+
+// UploadBatchItem is one file of a batch, processed by its own job
+type UploadBatchItem struct {
+	Position    int                    `json:"index"`
+	Filename    string                 `json:"filename"`
+	Kind        string                 `json:"kind"`
+	SessionID   string                 `json:"sessionId"`
+	JobID       *int                   `json:"jobId,omitempty"`
+	Status      string                 `json:"status"`
+	Results     map[string]interface{} `json:"results,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	CompletedAt *time.Time             `json:"completedAt,omitempty"`
+}
+
+// CreateUploadBatch stores a batch and its items, all queued
+func CreateUploadBatch(batch *UploadBatch) error {
+	batch.SourceLang = langcode.Normalize(batch.SourceLang)
+	batch.TargetLang = langcode.Normalize(batch.TargetLang)
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var userID interface{}
+	if batch.UserID != nil {
+		userID = *batch.UserID
+	}
+	err = tx.QueryRow(`
+		INSERT INTO upload_batches (batch_id, user_id, source_lang, target_lang)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`, batch.BatchID, userID, batch.SourceLang, batch.TargetLang).Scan(&batch.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create upload batch: %w", err)
+	}
+
+	for i := range batch.Items {
+		item := &batch.Items[i]
+		item.Status = BatchItemQueued
+		_, err := tx.Exec(`
+			INSERT INTO upload_batch_items (batch_id, position, filename, kind, session_id)
+			VALUES ($1, $2, $3, $4, $5)
+		`, batch.BatchID, item.Position, item.Filename, item.Kind, item.SessionID)
+		if err != nil {
+			return fmt.Errorf("failed to create upload batch item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit upload batch: %w", err)
+	}
+	return nil
+}
+
+// SetUploadBatchItemJob records the job processing a batch item
+func SetUploadBatchItemJob(batchID string, position, jobID int) error {
+	_, err := DB.Exec(`
+		UPDATE upload_batch_items SET job_id = $3 WHERE batch_id = $1 AND position = $2
+	`, batchID, position, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to set upload batch item job: %w", err)
+	}
+	return nil
+}
+
+// CompleteUploadBatchItem stores the results of a batch item's upload session
+func CompleteUploadBatchItem(sessionID string, results map[string]interface{}) error {
+	encoded, err := json.Marshal(results)
+	if err != nil {
+		return fmt.Errorf("failed to encode batch item results: %w", err)
+	}
+	_, err = DB.Exec(`
+		UPDATE upload_batch_items
+		SET status = 'succeeded', results = $2, error = NULL, completed_at = NOW()
+		WHERE session_id = $1
+	`, sessionID, encoded)
+	if err != nil {
+		return fmt.Errorf("failed to complete upload batch item: %w", err)
+	}
+	return nil
+}
+
+// FailUploadBatchItem marks a batch item's upload session as failed
+func FailUploadBatchItem(sessionID, message string) error {
+	_, err := DB.Exec(`
+		UPDATE upload_batch_items
+		SET status = 'failed', error = $2, completed_at = NOW()
+		WHERE session_id = $1
+	`, sessionID, message)
+	if err != nil {
+		return fmt.Errorf("failed to mark upload batch item failed: %w", err)
+	}
+	return nil
+}
+
+// GetUploadBatch returns a batch with its items in request order, or nil if
+// it does not exist. Items still queued take the status of their job when it
+// is running, or failed without reporting back (e.g. a panic).
+func GetUploadBatch(batchID string) (*UploadBatch, error) {
+	var batch UploadBatch
+	var userID sql.NullInt64
+	var sourceLang, targetLang sql.NullString
+	err := DB.QueryRow(`
+		SELECT batch_id, user_id, source_lang, target_lang, created_at
+		FROM upload_batches WHERE batch_id = $1
+	`, batchID).Scan(&batch.BatchID, &userID, &sourceLang, &targetLang, &batch.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload batch: %w", err)
+	}
+	if userID.Valid {
+		id := int(userID.Int64)
+		batch.UserID = &id
+	}
+	batch.SourceLang = sourceLang.String
+	batch.TargetLang = targetLang.String
+
+	rows, err := DB.Query(`
+		SELECT i.position, i.filename, i.kind, i.session_id, i.job_id,
+		       CASE WHEN i.status = 'queued' AND j.status IN ('running', 'failed') THEN j.status ELSE i.status END,
+		       i.results, COALESCE(i.error, CASE WHEN j.status = 'failed' THEN j.last_error END), i.completed_at
+		FROM upload_batch_items i
+		LEFT JOIN processing_jobs j ON j.id = i.job_id
+		WHERE i.batch_id = $1
+		ORDER BY i.position
+	`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload batch items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item UploadBatchItem
+		var jobID sql.NullInt64
+		var results []byte
+		var itemError sql.NullString
+		var completedAt sql.NullTime
+		if err := rows.Scan(&item.Position, &item.Filename, &item.Kind, &item.SessionID, &jobID,
+			&item.Status, &results, &itemError, &completedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan upload batch item: %w", err)
+		}
+		if jobID.Valid {
+			id := int(jobID.Int64)
+			item.JobID = &id
+		}
+		if len(results) > 0 {
+			if err := json.Unmarshal(results, &item.Results); err != nil {
+				return nil, fmt.Errorf("failed to decode upload batch item results: %w", err)
+			}
+		}
+		item.Error = itemError.String
+		if completedAt.Valid {
+			item.CompletedAt = &completedAt.Time
+		}
+		batch.Items = append(batch.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read upload batch items: %w", err)
+	}
+	return &batch, nil
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// BenchmarkRun is one ASR accuracy benchmark run
+type BenchmarkRun struct {
+	ID            int               `json:"id"`
+	Backend       string            `json:"backend"`
+	ASRURL        string            `json:"asrUrl"`
+	WindowSeconds int               `json:"windowSeconds"`
+	Label         string            `json:"label,omitempty"`
+	Corpus        string            `json:"corpus"`
+	SampleCount   int               `json:"sampleCount"`
+	FailedCount   int               `json:"failedCount"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	Results       []BenchmarkResult `json:"results"`
+}
+
+// BenchmarkResult holds the pooled error rates for one language of a run
+type BenchmarkResult struct {
+	Language       string  `json:"language"`
+	SampleCount    int     `json:"sampleCount"`
+	WordErrors     int     `json:"wordErrors"`
+	ReferenceWords int     `json:"referenceWords"`
+	WER            float64 `json:"wer"`
+	CharErrors     int     `json:"charErrors"`
+	ReferenceChars int     `json:"referenceChars"`
+	CER            float64 `json:"cer"`
+	AvgLatencyMs   float64 `json:"avgLatencyMs"`
+}
+
+// SaveBenchmarkRun stores a run with its per-language results
+func SaveBenchmarkRun(run *BenchmarkRun) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO asr_benchmark_runs (backend, asr_url, window_seconds, label, corpus, sample_count, failed_count)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, run.Backend, run.ASRURL, run.WindowSeconds, nullString(run.Label), run.Corpus, run.SampleCount, run.FailedCount).Scan(&run.ID, &run.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save benchmark run: %w", err)
+	}
+
+	for _, result := range run.Results {
+		_, err := tx.Exec(`
+			INSERT INTO asr_benchmark_results
+				(run_id, language, sample_count, word_errors, reference_words, wer,
+				 char_errors, reference_chars, cer, avg_latency_ms)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, run.ID, result.Language, result.SampleCount, result.WordErrors, result.ReferenceWords, result.WER,
+			result.CharErrors, result.ReferenceChars, result.CER, result.AvgLatencyMs)
+		if err != nil {
+			return fmt.Errorf("failed to save benchmark result: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit benchmark run: %w", err)
+	}
+	return nil
+}
+
+// ListBenchmarkRuns returns the most recent runs with their results, newest first
+func ListBenchmarkRuns(limit int) ([]BenchmarkRun, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	rows, err := DB.Query(`
+		SELECT id, backend, asr_url, window_seconds, label, corpus, sample_count, failed_count, created_at
+		FROM asr_benchmark_runs
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list benchmark runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []BenchmarkRun
+	index := make(map[int]int)
+	for rows.Next() {
+		var run BenchmarkRun
+		var label sql.NullString
+		if err := rows.Scan(&run.ID, &run.Backend, &run.ASRURL, &run.WindowSeconds, &label, &run.Corpus,
+			&run.SampleCount, &run.FailedCount, &run.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan benchmark run: %w", err)
+		}
+		run.Label = label.String
+		index[run.ID] = len(runs)
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read benchmark runs: %w", err)
+	}
+	if len(runs) == 0 {
+		return runs, nil
+	}
+
+	ids := make([]int, 0, len(runs))
+	for _, run := range runs {
+		ids = append(ids, run.ID)
+	}
+	resultRows, err := DB.Query(`
+		SELECT run_id, language, sample_count, word_errors, reference_words, wer,
+		       char_errors, reference_chars, cer, avg_latency_ms
+		FROM asr_benchmark_results
+		WHERE run_id = ANY($1)
+		ORDER BY language
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list benchmark results: %w", err)
+	}
+	defer resultRows.Close()
+
+	for resultRows.Next() {
+		var runID int
+		var result BenchmarkResult
+		if err := resultRows.Scan(&runID, &result.Language, &result.SampleCount, &result.WordErrors, &result.ReferenceWords,
+			&result.WER, &result.CharErrors, &result.ReferenceChars, &result.CER, &result.AvgLatencyMs); err != nil {
+			return nil, fmt.Errorf("failed to scan benchmark result: %w", err)
+		}
+		runs[index[runID]].Results = append(runs[index[runID]].Results, result)
+	}
+
+	return runs, resultRows.Err()
+}
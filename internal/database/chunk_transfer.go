@@ -0,0 +1,198 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"realtime-caption-translator/internal/langcode"
+)
+
+// EmbeddingDimensions is the size of the meeting_chunks.embedding vector column
+const EmbeddingDimensions = 384
+
+// GetChunksForExport returns a source's chunks with their content hashes and
+// embeddings, ordered by language and index. An empty language returns all.
+func GetChunksForExport(meetingID, language string) ([]MeetingChunk, error) {
+	language = langcode.Normalize(language)
+	query := `
+		SELECT
+			id, meeting_id, language, chunk_index, chunk_text, content_hash,
+			speaker_id, speaker_name, start_timestamp, end_timestamp,
+			start_offset_seconds, end_offset_seconds, embedding::text,
+			processing_status, created_at
+		FROM meeting_chunks
+		WHERE meeting_id = $1 AND ($2 = '' OR language = $2)
+		ORDER BY language, chunk_index
+	`
+
+	rows, err := DB.Query(query, meetingID, language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks for export: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []MeetingChunk
+	for rows.Next() {
+		var chunk MeetingChunk
+		var contentHash, speakerID, speakerName, embedding sql.NullString
+		var startTimestamp, endTimestamp sql.NullTime
+		var startOffset, endOffset sql.NullFloat64
+
+		err := rows.Scan(
+			&chunk.ID,
+			&chunk.MeetingID,
+			&chunk.Language,
+			&chunk.ChunkIndex,
+			&chunk.ChunkText,
+			&contentHash,
+			&speakerID,
+			&speakerName,
+			&startTimestamp,
+			&endTimestamp,
+			&startOffset,
+			&endOffset,
+			&embedding,
+			&chunk.ProcessingStatus,
+			&chunk.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+
+		chunk.ContentHash = contentHash.String
+		if speakerID.Valid {
+			chunk.SpeakerID = &speakerID.String
+		}
+		if speakerName.Valid {
+			chunk.SpeakerName = &speakerName.String
+		}
+		if startTimestamp.Valid {
+			chunk.StartTimestamp = &startTimestamp.Time
+		}
+		if endTimestamp.Valid {
+			chunk.EndTimestamp = &endTimestamp.Time
+		}
+		if startOffset.Valid {
+			chunk.StartOffsetSeconds = &startOffset.Float64
+		}
+		if endOffset.Valid {
+			chunk.EndOffsetSeconds = &endOffset.Float64
+		}
+		if embedding.Valid {
+			chunk.Embedding, err = parseEmbedding(embedding.String)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse embedding of chunk %d: %w", chunk.ID, err)
+			}
+		}
+
+		chunks = append(chunks, chunk)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating chunks: %w", err)
+	}
+
+	return chunks, nil
+}
+
+// ReplaceMeetingChunks swaps the stored chunks of every language present in
+// chunks for the given ones, in one transaction, so readers see either the
+// old chunks or the new ones. Chunks that carry an embedding are stored
+// completed; the rest are stored pending.
+func ReplaceMeetingChunks(meetingID string, chunks []*MeetingChunk) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin chunk import: %w", err)
+	}
+	defer tx.Rollback()
+
+	languages := make(map[string]bool)
+	for _, chunk := range chunks {
+		chunk.Language = langcode.Normalize(chunk.Language)
+		languages[chunk.Language] = true
+	}
+	languageList := make([]string, 0, len(languages))
+	for language := range languages {
+		languageList = append(languageList, language)
+	}
+	if _, err := tx.Exec(`DELETE FROM meeting_chunks WHERE meeting_id = $1 AND language = ANY($2)`,
+		meetingID, pq.Array(languageList)); err != nil {
+		return fmt.Errorf("failed to clear chunks for import: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO meeting_chunks (
+			meeting_id, language, chunk_index, chunk_text, content_hash,
+			speaker_id, speaker_name, start_timestamp, end_timestamp,
+			start_offset_seconds, end_offset_seconds, embedding, processing_status, source_type, chunk_version, speaker_names
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id, created_at
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare chunk import: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, chunk := range chunks {
+		chunk.MeetingID = meetingID
+		chunk.ProcessingStatus = ChunkStatusPending
+		var embedding interface{}
+		if len(chunk.Embedding) > 0 {
+			embedding = embeddingToString(chunk.Embedding)
+			chunk.ProcessingStatus = ChunkStatusCompleted
+		}
+
+		err := stmt.QueryRow(
+			meetingID,
+			chunk.Language,
+			chunk.ChunkIndex,
+			chunk.ChunkText,
+			nullString(chunk.ContentHash),
+			chunk.SpeakerID,
+			chunk.SpeakerName,
+			chunk.StartTimestamp,
+			chunk.EndTimestamp,
+			chunk.StartOffsetSeconds,
+			chunk.EndOffsetSeconds,
+			embedding,
+			chunk.ProcessingStatus,
+			RAGSourceType(meetingID),
+			chunk.ChunkVersion,
+			pq.Array(speakerNames(chunk)),
+		).Scan(&chunk.ID, &chunk.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to import chunk %d (%s): %w", chunk.ChunkIndex, chunk.Language, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit chunk import: %w", err)
+	}
+	return nil
+}
+
+// parseEmbedding reads a pgvector text value such as "[0.1,0.2,0.3]"
+func parseEmbedding(value string) ([]float32, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "[")
+	value = strings.TrimSuffix(value, "]")
+	if value == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(value, ",")
+	embedding := make([]float32, len(parts))
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, err
+		}
+		embedding[i] = float32(f)
+	}
+	return embedding, nil
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MeetingConsent is a participant's answer to the recording consent prompt
+type MeetingConsent struct {
+	MeetingID       string    `json:"meetingId"`
+	ParticipantID   int       `json:"participantId"`
+	UserID          *int      `json:"userId,omitempty"`
+	ParticipantName string    `json:"participantName"`
+	Consented       bool      `json:"consented"`
+	RespondedAt     time.Time `json:"respondedAt"`
+}
+
+const consentColumns = `meeting_id, participant_id, user_id, participant_name, consented, responded_at`
+
+func scanMeetingConsent(row interface{ Scan(...interface{}) error }) (*MeetingConsent, error) {
+	var consent MeetingConsent
+	var userID sql.NullInt64
+	if err := row.Scan(&consent.MeetingID, &consent.ParticipantID, &userID, &consent.ParticipantName,
+		&consent.Consented, &consent.RespondedAt); err != nil {
+		return nil, err
+	}
+	if userID.Valid {
+		id := int(userID.Int64)
+		consent.UserID = &id
+	}
+	return &consent, nil
+}
+
+// SaveMeetingConsent records a participant's answer, replacing any earlier
+// answer from the same participant, and fills in RespondedAt
+func SaveMeetingConsent(consent *MeetingConsent) error {
+	var userID interface{}
+	if consent.UserID != nil {
+		userID = *consent.UserID
+	}
+
+	err := DB.QueryRow(`
+		INSERT INTO meeting_consents (meeting_id, participant_id, user_id, participant_name, consented)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (meeting_id, participant_id)
+		DO UPDATE SET consented = EXCLUDED.consented, participant_name = EXCLUDED.participant_name, responded_at = NOW()
+		RETURNING responded_at
+	`, consent.MeetingID, consent.ParticipantID, userID, consent.ParticipantName, consent.Consented).
+		Scan(&consent.RespondedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save meeting consent: %w", err)
+	}
+	return nil
+}
+
+// ListMeetingConsents returns every recorded answer for a meeting, oldest first
+func ListMeetingConsents(meetingID string) ([]MeetingConsent, error) {
+	rows, err := DB.Query(`SELECT `+consentColumns+` FROM meeting_consents WHERE meeting_id = $1 ORDER BY responded_at`, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meeting consents: %w", err)
+	}
+	defer rows.Close()
+
+	consents := []MeetingConsent{}
+	for rows.Next() {
+		consent, err := scanMeetingConsent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan meeting consent: %w", err)
+		}
+		consents = append(consents, *consent)
+	}
+	return consents, rows.Err()
+}
+
+// GetUserMeetingConsent returns a signed-in user's latest answer in a
+// meeting, so a reconnect does not prompt again. Returns nil if they never answered.
+func GetUserMeetingConsent(meetingID string, userID int) (*MeetingConsent, error) {
+	consent, err := scanMeetingConsent(DB.QueryRow(`
+		SELECT `+consentColumns+` FROM meeting_consents
+		WHERE meeting_id = $1 AND user_id = $2
+		ORDER BY responded_at DESC
+		LIMIT 1
+	`, meetingID, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meeting consent: %w", err)
+	}
+	return consent, nil
+}
@@ -32,8 +32,10 @@ func Init() error {
 		DBName:   getEnv("DB_NAME", "audio_translator"),
 	}
 
+	// Sessions run in UTC so NOW() and TIMESTAMP columns store UTC regardless
+	// of the database server's time zone
 	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable timezone=UTC",
 		config.Host,
 		config.Port,
 		config.User,
@@ -0,0 +1,240 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"realtime-caption-translator/internal/langcode"
+)
+
+// KnowledgeDocument is a reference document blended into RAG answers.
+// A nil MeetingID means the document belongs to the global knowledge base of
+// OrgID.
+type KnowledgeDocument struct {
+	ID         int       `json:"id"`
+	MeetingID  *string   `json:"meetingId,omitempty"`
+	OrgID      string    `json:"orgId,omitempty"`
+	UploadedBy *int      `json:"uploadedBy,omitempty"`
+	Title      string    `json:"title"`
+	Filename   string    `json:"filename"`
+	MimeType   string    `json:"mimeType,omitempty"`
+	Language   string    `json:"language"`
+	Content    string    `json:"-"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// DocumentChunkMatch is a document chunk returned by similarity search
+type DocumentChunkMatch struct {
+	MeetingChunk
+	DocumentID    int    `json:"documentId"`
+	DocumentTitle string `json:"documentTitle"`
+}
+
+// DocumentSourceID returns the RAG source ID for a document's chunks
+func DocumentSourceID(documentID int) string {
+	return RAGSourceID(SourceTypeDocument, strconv.Itoa(documentID))
+}
+
+// CreateKnowledgeDocument stores a document and its extracted text
+func CreateKnowledgeDocument(doc *KnowledgeDocument) error {
+	doc.Language = langcode.Normalize(doc.Language)
+	if doc.Language == "" {
+		doc.Language = "en"
+	}
+
+	query := `
+		INSERT INTO knowledge_documents (meeting_id, org_id, uploaded_by, title, filename, mime_type, language, content_text)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	err := DB.QueryRow(
+		query,
+		doc.MeetingID,
+		nullString(doc.OrgID),
+		doc.UploadedBy,
+		doc.Title,
+		doc.Filename,
+		nullString(doc.MimeType),
+		doc.Language,
+		doc.Content,
+	).Scan(&doc.ID, &doc.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create knowledge document: %w", err)
+	}
+
+	return nil
+}
+
+// GetKnowledgeDocument returns a document by ID, or nil if it does not exist
+func GetKnowledgeDocument(documentID int) (*KnowledgeDocument, error) {
+	query := `
+		SELECT id, meeting_id, org_id, uploaded_by, title, filename, mime_type, language, content_text, created_at
+		FROM knowledge_documents
+		WHERE id = $1
+	`
+
+	rows, err := DB.Query(query, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get knowledge document: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	return scanKnowledgeDocument(rows)
+}
+
+// ListKnowledgeDocuments returns the documents pinned to a meeting. An empty
+// meetingID lists the global knowledge base, limited to orgID's documents
+// when orgID is set.
+func ListKnowledgeDocuments(meetingID, orgID string) ([]KnowledgeDocument, error) {
+	query := `
+		SELECT id, meeting_id, org_id, uploaded_by, title, filename, mime_type, language, content_text, created_at
+		FROM knowledge_documents
+		WHERE ($1 = '' AND meeting_id IS NULL AND ($2 = '' OR org_id = $2)) OR meeting_id = NULLIF($1, '')
+		ORDER BY created_at DESC
+	`
+
+	rows, err := DB.Query(query, meetingID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list knowledge documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []KnowledgeDocument
+	for rows.Next() {
+		doc, err := scanKnowledgeDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, *doc)
+	}
+
+	return docs, rows.Err()
+}
+
+// DeleteKnowledgeDocument removes a document together with its chunks
+func DeleteKnowledgeDocument(documentID int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM meeting_chunks WHERE meeting_id = $1`, DocumentSourceID(documentID)); err != nil {
+		return fmt.Errorf("failed to delete document chunks: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM knowledge_documents WHERE id = $1`, documentID); err != nil {
+		return fmt.Errorf("failed to delete knowledge document: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeleteOrphanedDocumentChunks removes a document's chunks if the document no
+// longer exists, for chunks embedded while the document was being deleted
+func DeleteOrphanedDocumentChunks(documentID int) error {
+	query := `
+		DELETE FROM meeting_chunks
+		WHERE meeting_id = $1
+		  AND NOT EXISTS (SELECT 1 FROM knowledge_documents WHERE id = $2)
+	`
+	if _, err := DB.Exec(query, DocumentSourceID(documentID), documentID); err != nil {
+		return fmt.Errorf("failed to delete orphaned document chunks: %w", err)
+	}
+	return nil
+}
+
+// SearchSimilarDocumentChunks finds the document chunks closest to a query
+// embedding among documents pinned to the meeting and the global knowledge
+// base of orgID (none when orgID is empty)
+func SearchSimilarDocumentChunks(meetingID, orgID string, queryEmbedding []float32, topK int) ([]DocumentChunkMatch, error) {
+	query := `
+		SELECT
+			c.id, c.meeting_id, c.language, c.chunk_index, c.chunk_text,
+			c.processing_status, c.created_at,
+			1 - (c.embedding <=> $1::vector) as similarity,
+			d.id, d.title
+		FROM meeting_chunks c
+		JOIN knowledge_documents d ON c.meeting_id = 'document:' || d.id
+		WHERE (d.meeting_id = $2 OR (d.meeting_id IS NULL AND d.org_id = NULLIF($4, '')))
+		  AND c.processing_status = 'completed'
+		ORDER BY c.embedding <=> $1::vector
+		LIMIT $3
+	`
+
+	rows, err := DB.Query(query, embeddingToString(queryEmbedding), meetingID, topK, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search document chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []DocumentChunkMatch
+	for rows.Next() {
+		var match DocumentChunkMatch
+		err := rows.Scan(
+			&match.ID,
+			&match.MeetingID,
+			&match.Language,
+			&match.ChunkIndex,
+			&match.ChunkText,
+			&match.ProcessingStatus,
+			&match.CreatedAt,
+			&match.Similarity,
+			&match.DocumentID,
+			&match.DocumentTitle,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document chunk: %w", err)
+		}
+		matches = append(matches, match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating document chunks: %w", err)
+	}
+
+	return matches, nil
+}
+
+func scanKnowledgeDocument(rows *sql.Rows) (*KnowledgeDocument, error) {
+	var doc KnowledgeDocument
+	var meetingID, orgID, mimeType sql.NullString
+	var uploadedBy sql.NullInt64
+
+	err := rows.Scan(
+		&doc.ID,
+		&meetingID,
+		&orgID,
+		&uploadedBy,
+		&doc.Title,
+		&doc.Filename,
+		&mimeType,
+		&doc.Language,
+		&doc.Content,
+		&doc.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan knowledge document: %w", err)
+	}
+
+	if meetingID.Valid {
+		doc.MeetingID = &meetingID.String
+	}
+	if orgID.Valid {
+		doc.OrgID = orgID.String
+	}
+	if uploadedBy.Valid {
+		uid := int(uploadedBy.Int64)
+		doc.UploadedBy = &uid
+	}
+	if mimeType.Valid {
+		doc.MimeType = mimeType.String
+	}
+
+	return &doc, nil
+}
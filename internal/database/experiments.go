@@ -0,0 +1,123 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ExperimentSession is the metrics record of one live session in an experiment
+type ExperimentSession struct {
+	Experiment        string
+	Variant           string
+	Params            json.RawMessage
+	Duration          time.Duration
+	ASRCalls          int
+	ASRErrors         int
+	ASRLatency        time.Duration
+	SilentWindows     int
+	PartialRevisions  int
+	Finals            int
+	FinalizeLatency   time.Duration
+	FirstPartialDelay *time.Duration
+	StartedAt         time.Time
+}
+
+// VariantReport aggregates the sessions of one experiment variant
+type VariantReport struct {
+	Variant              string          `json:"variant"`
+	Params               json.RawMessage `json:"params"`
+	Sessions             int             `json:"sessions"`
+	TotalMinutes         float64         `json:"totalMinutes"`
+	AvgASRLatencyMs      float64         `json:"avgAsrLatencyMs"`
+	ASRErrorRate         float64         `json:"asrErrorRate"`
+	SilentWindowRate     float64         `json:"silentWindowRate"`
+	AvgFirstPartialMs    *float64        `json:"avgFirstPartialMs"`
+	AvgFinalizeLatencyMs float64         `json:"avgFinalizeLatencyMs"`
+	RevisionsPerFinal    float64         `json:"revisionsPerFinal"`
+	FinalsPerMinute      float64         `json:"finalsPerMinute"`
+	ASRCallsPerMinute    float64         `json:"asrCallsPerMinute"`
+}
+
+// SaveExperimentSession stores the metrics of a finished live session
+func SaveExperimentSession(s *ExperimentSession) error {
+	var firstPartial interface{}
+	if s.FirstPartialDelay != nil {
+		firstPartial = s.FirstPartialDelay.Milliseconds()
+	}
+
+	_, err := DB.Exec(`
+		INSERT INTO live_experiment_sessions
+			(experiment, variant, params, duration_ms, asr_calls, asr_errors, asr_latency_ms,
+			 silent_windows, partial_revisions, finals, finalize_latency_ms, first_partial_ms, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, s.Experiment, s.Variant, []byte(s.Params), s.Duration.Milliseconds(), s.ASRCalls, s.ASRErrors, s.ASRLatency.Milliseconds(),
+		s.SilentWindows, s.PartialRevisions, s.Finals, s.FinalizeLatency.Milliseconds(), firstPartial, s.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save experiment session: %w", err)
+	}
+	return nil
+}
+
+// GetExperimentReport aggregates recorded sessions per variant of an experiment
+func GetExperimentReport(experiment string) ([]VariantReport, error) {
+	rows, err := DB.Query(`
+		SELECT variant,
+		       (ARRAY_AGG(params ORDER BY ended_at DESC))[1],
+		       COUNT(*),
+		       COALESCE(SUM(duration_ms), 0),
+		       COALESCE(SUM(asr_calls), 0),
+		       COALESCE(SUM(asr_errors), 0),
+		       COALESCE(SUM(asr_latency_ms), 0),
+		       COALESCE(SUM(silent_windows), 0),
+		       COALESCE(SUM(partial_revisions), 0),
+		       COALESCE(SUM(finals), 0),
+		       COALESCE(SUM(finalize_latency_ms), 0),
+		       AVG(first_partial_ms)
+		FROM live_experiment_sessions
+		WHERE experiment = $1
+		GROUP BY variant
+		ORDER BY variant
+	`, experiment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experiment report: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []VariantReport{}
+	for rows.Next() {
+		var r VariantReport
+		var params []byte
+		var durationMs, asrCalls, asrErrors, asrLatencyMs, silentWindows, revisions, finals, finalizeMs int64
+		var firstPartial sql.NullFloat64
+		if err := rows.Scan(&r.Variant, &params, &r.Sessions, &durationMs, &asrCalls, &asrErrors, &asrLatencyMs,
+			&silentWindows, &revisions, &finals, &finalizeMs, &firstPartial); err != nil {
+			return nil, fmt.Errorf("failed to scan experiment report: %w", err)
+		}
+
+		r.Params = json.RawMessage(params)
+		r.TotalMinutes = float64(durationMs) / 60000
+		if succeeded := asrCalls - asrErrors; succeeded > 0 {
+			r.AvgASRLatencyMs = float64(asrLatencyMs) / float64(succeeded)
+		}
+		if windows := asrCalls + silentWindows; windows > 0 {
+			r.ASRErrorRate = float64(asrErrors) / float64(max(asrCalls, 1))
+			r.SilentWindowRate = float64(silentWindows) / float64(windows)
+		}
+		if firstPartial.Valid {
+			r.AvgFirstPartialMs = &firstPartial.Float64
+		}
+		if finals > 0 {
+			r.AvgFinalizeLatencyMs = float64(finalizeMs) / float64(finals)
+			r.RevisionsPerFinal = float64(revisions) / float64(finals)
+		}
+		if r.TotalMinutes > 0 {
+			r.FinalsPerMinute = float64(finals) / r.TotalMinutes
+			r.ASRCallsPerMinute = float64(asrCalls) / r.TotalMinutes
+		}
+		reports = append(reports, r)
+	}
+
+	return reports, rows.Err()
+}
@@ -0,0 +1,268 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Failure sources
+const (
+	FailureSourceJob     = "job"     // a queued job that exhausted its attempts
+	FailureSourceChunk   = "chunk"   // a meeting chunk whose embedding exhausted its retries
+	FailureSourceMinutes = "minutes" // meeting minutes generation after a meeting ended
+)
+
+// Failure statuses
+const (
+	FailureOpen      = "open"
+	FailureRequeued  = "requeued"
+	FailureDismissed = "dismissed"
+)
+
+// ProcessingFailure is a terminal failure of background processing kept
+// for triage. Context holds what is needed to retry it, e.g. the payload of
+// a failed job.
+type ProcessingFailure struct {
+	ID            int             `json:"id"`
+	Source        string          `json:"source"`
+	Kind          string          `json:"kind"`
+	Priority      int             `json:"priority"`
+	ErrorCode     string          `json:"errorCode"`
+	Error         string          `json:"error"`
+	Context       json.RawMessage `json:"context"`
+	JobID         *int            `json:"jobId,omitempty"`
+	ChunkID       *int            `json:"chunkId,omitempty"`
+	MeetingID     string          `json:"meetingId,omitempty"`
+	SessionID     string          `json:"sessionId,omitempty"`
+	UserID        *int            `json:"userId,omitempty"`
+	Attempts      int             `json:"attempts"`
+	Status        string          `json:"status"`
+	RequeuedJobID *int            `json:"requeuedJobId,omitempty"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	ResolvedAt    *time.Time      `json:"resolvedAt,omitempty"`
+}
+
+const failureColumns = `id, source, kind, priority, error_code, error, context, job_id, chunk_id, meeting_id,
+	session_id, user_id, attempts, status, requeued_job_id, created_at, resolved_at`
+
+func scanFailure(row interface{ Scan(...interface{}) error }) (*ProcessingFailure, error) {
+	var failure ProcessingFailure
+	var jobID, chunkID, userID, requeuedJobID sql.NullInt64
+	var meetingID, sessionID sql.NullString
+	var resolvedAt sql.NullTime
+	if err := row.Scan(&failure.ID, &failure.Source, &failure.Kind, &failure.Priority, &failure.ErrorCode,
+		&failure.Error, &failure.Context, &jobID, &chunkID, &meetingID, &sessionID, &userID,
+		&failure.Attempts, &failure.Status, &requeuedJobID, &failure.CreatedAt, &resolvedAt); err != nil {
+		return nil, err
+	}
+	failure.JobID = nullIntPtr(jobID)
+	failure.ChunkID = nullIntPtr(chunkID)
+	failure.UserID = nullIntPtr(userID)
+	failure.RequeuedJobID = nullIntPtr(requeuedJobID)
+	failure.MeetingID = meetingID.String
+	failure.SessionID = sessionID.String
+	if resolvedAt.Valid {
+		failure.ResolvedAt = &resolvedAt.Time
+	}
+	return &failure, nil
+}
+
+func nullIntPtr(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	id := int(value.Int64)
+	return &id
+}
+
+func intPtrArg(value *int) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// CreateProcessingFailure records a failure and fills in its ID, status
+// and creation time
+func CreateProcessingFailure(failure *ProcessingFailure) error {
+	if len(failure.Context) == 0 {
+		failure.Context = json.RawMessage("{}")
+	}
+	if failure.Attempts <= 0 {
+		failure.Attempts = 1
+	}
+
+	err := DB.QueryRow(`
+		INSERT INTO processing_failures
+			(source, kind, priority, error_code, error, context, job_id, chunk_id, meeting_id, session_id, user_id, attempts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, status, created_at
+	`, failure.Source, failure.Kind, failure.Priority, failure.ErrorCode, failure.Error, []byte(failure.Context),
+		intPtrArg(failure.JobID), intPtrArg(failure.ChunkID), nullString(failure.MeetingID),
+		nullString(failure.SessionID), intPtrArg(failure.UserID), failure.Attempts).
+		Scan(&failure.ID, &failure.Status, &failure.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record processing failure: %w", err)
+	}
+	return nil
+}
+
+// FailureFilter selects failures; empty fields match everything
+type FailureFilter struct {
+	Status    string
+	Source    string
+	Kind      string
+	ErrorCode string
+	MeetingID string
+	IDs       []int
+	Limit     int
+}
+
+// ListProcessingFailures returns failures matching the filter, most urgent
+// first: by priority, then newest
+func ListProcessingFailures(filter FailureFilter) ([]ProcessingFailure, error) {
+	query := `SELECT ` + failureColumns + ` FROM processing_failures WHERE 1=1`
+	var args []interface{}
+	add := func(clause string, value interface{}) {
+		args = append(args, value)
+		query += fmt.Sprintf(" AND "+clause, len(args))
+	}
+	if filter.Status != "" {
+		add("status = $%d", filter.Status)
+	}
+	if filter.Source != "" {
+		add("source = $%d", filter.Source)
+	}
+	if filter.Kind != "" {
+		add("kind = $%d", filter.Kind)
+	}
+	if filter.ErrorCode != "" {
+		add("error_code = $%d", filter.ErrorCode)
+	}
+	if filter.MeetingID != "" {
+		add("meeting_id = $%d", filter.MeetingID)
+	}
+	if len(filter.IDs) > 0 {
+		ids := make([]int64, len(filter.IDs))
+		for i, id := range filter.IDs {
+			ids[i] = int64(id)
+		}
+		add("id = ANY($%d)", pq.Array(ids))
+	}
+	query += ` ORDER BY priority, created_at DESC, id DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processing failures: %w", err)
+	}
+	defer rows.Close()
+
+	var failures []ProcessingFailure
+	for rows.Next() {
+		failure, err := scanFailure(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan processing failure: %w", err)
+		}
+		failures = append(failures, *failure)
+	}
+	return failures, rows.Err()
+}
+
+// CountOpenFailures returns the number of open failures per source
+func CountOpenFailures() (map[string]int, error) {
+	rows, err := DB.Query(`SELECT source, COUNT(*) FROM processing_failures WHERE status = $1 GROUP BY source`, FailureOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open failures: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var source string
+		var count int
+		if err := rows.Scan(&source, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan failure count: %w", err)
+		}
+		counts[source] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetProcessingFailure returns a failure by ID, or nil if it does not exist
+func GetProcessingFailure(id int) (*ProcessingFailure, error) {
+	failure, err := scanFailure(DB.QueryRow(`SELECT `+failureColumns+` FROM processing_failures WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get processing failure: %w", err)
+	}
+	return failure, nil
+}
+
+// ResolveProcessingFailure closes an open failure as requeued or dismissed.
+// requeuedJobID is the job that retries it, if any. Returns false when the
+// failure was not open, e.g. it was requeued concurrently.
+func ResolveProcessingFailure(id int, status string, requeuedJobID *int) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE processing_failures
+		SET status = $2, requeued_job_id = $3, resolved_at = NOW()
+		WHERE id = $1 AND status = $4
+	`, id, status, intPtrArg(requeuedJobID), FailureOpen)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve processing failure: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// RequeueFailedChunk schedules a failed chunk for an immediate embedding
+// retry with a fresh attempt budget
+func RequeueFailedChunk(chunkID int) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE meeting_chunks
+		SET attempt_count = 0, next_retry_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND processing_status = $2
+	`, chunkID, ChunkStatusFailed)
+	if err != nil {
+		return false, fmt.Errorf("failed to requeue chunk: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// SetFailureRequeuedJob links a requeued failure to the job retrying it
+func SetFailureRequeuedJob(id, jobID int) error {
+	if _, err := DB.Exec(`UPDATE processing_failures SET requeued_job_id = $2 WHERE id = $1`, id, jobID); err != nil {
+		return fmt.Errorf("failed to link requeued job: %w", err)
+	}
+	return nil
+}
+
+// ReopenProcessingFailure returns a failure to the inbox after a requeue
+// could not be carried out
+func ReopenProcessingFailure(id int) error {
+	_, err := DB.Exec(`
+		UPDATE processing_failures
+		SET status = $2, requeued_job_id = NULL, resolved_at = NULL
+		WHERE id = $1
+	`, id, FailureOpen)
+	if err != nil {
+		return fmt.Errorf("failed to reopen processing failure: %w", err)
+	}
+	return nil
+}
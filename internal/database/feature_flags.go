@@ -0,0 +1,131 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// FeatureFlag is the stored global state of a flag
+type FeatureFlag struct {
+	Key         string    `json:"key"`
+	Enabled     bool      `json:"enabled"`
+	Description string    `json:"description,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// FeatureFlagOverride enables or disables a flag for one org or user
+type FeatureFlagOverride struct {
+	FlagKey   string    `json:"flagKey"`
+	ScopeType string    `json:"scopeType"` // "org" or "user"
+	ScopeID   string    `json:"scopeId"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Flag override scopes
+const (
+	FlagScopeOrg  = "org"
+	FlagScopeUser = "user"
+)
+
+// ListFeatureFlags returns all flags with a stored global state
+func ListFeatureFlags() ([]FeatureFlag, error) {
+	rows, err := DB.Query(`
+		SELECT key, enabled, description, updated_at
+		FROM feature_flags
+		ORDER BY key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	var flags []FeatureFlag
+	for rows.Next() {
+		var flag FeatureFlag
+		var description sql.NullString
+		if err := rows.Scan(&flag.Key, &flag.Enabled, &description, &flag.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag: %w", err)
+		}
+		if description.Valid {
+			flag.Description = description.String
+		}
+		flags = append(flags, flag)
+	}
+
+	return flags, rows.Err()
+}
+
+// SetFeatureFlag stores the global state of a flag
+func SetFeatureFlag(key string, enabled bool, description string) error {
+	_, err := DB.Exec(`
+		INSERT INTO feature_flags (key, enabled, description)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key)
+		DO UPDATE SET enabled = EXCLUDED.enabled,
+		              description = COALESCE(EXCLUDED.description, feature_flags.description),
+		              updated_at = NOW()
+	`, key, enabled, nullString(description))
+	if err != nil {
+		return fmt.Errorf("failed to set feature flag: %w", err)
+	}
+
+	return nil
+}
+
+// ListFeatureFlagOverrides returns every org and user override
+func ListFeatureFlagOverrides() ([]FeatureFlagOverride, error) {
+	rows, err := DB.Query(`
+		SELECT flag_key, scope_type, scope_id, enabled, updated_at
+		FROM feature_flag_overrides
+		ORDER BY flag_key, scope_type, scope_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flag overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []FeatureFlagOverride
+	for rows.Next() {
+		var override FeatureFlagOverride
+		if err := rows.Scan(&override.FlagKey, &override.ScopeType, &override.ScopeID, &override.Enabled, &override.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag override: %w", err)
+		}
+		overrides = append(overrides, override)
+	}
+
+	return overrides, rows.Err()
+}
+
+// SetFeatureFlagOverride upserts an org or user override for a flag
+func SetFeatureFlagOverride(key, scopeType, scopeID string, enabled bool) error {
+	if scopeType != FlagScopeOrg && scopeType != FlagScopeUser {
+		return fmt.Errorf("invalid scope type %q", scopeType)
+	}
+
+	_, err := DB.Exec(`
+		INSERT INTO feature_flag_overrides (flag_key, scope_type, scope_id, enabled)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (flag_key, scope_type, scope_id)
+		DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = NOW()
+	`, key, scopeType, scopeID, enabled)
+	if err != nil {
+		return fmt.Errorf("failed to set feature flag override: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFeatureFlagOverride removes an org or user override
+func DeleteFeatureFlagOverride(key, scopeType, scopeID string) error {
+	_, err := DB.Exec(`
+		DELETE FROM feature_flag_overrides
+		WHERE flag_key = $1 AND scope_type = $2 AND scope_id = $3
+	`, key, scopeType, scopeID)
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag override: %w", err)
+	}
+
+	return nil
+}
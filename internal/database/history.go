@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/lib/pq"
+
+	"realtime-caption-translator/internal/langcode"
 )
 
 type UserVideoSessionInput struct {
@@ -28,6 +32,7 @@ type UserAudioSessionInput struct {
 	Transcription  string
 	Translation    string
 	AudioPath      string
+	TTSPath        string
 	SourceLang     string
 	TargetLang     string
 	HasDiarization bool
@@ -65,48 +70,106 @@ type UserFileMatch struct {
 }
 
 type UserVideoSessionRecord struct {
-	SessionID       string
-	Filename        string
-	Transcription   string
-	Translation     string
-	VideoPath       string
-	AudioPath       string
-	TTSPath         string
-	SourceLang      string
-	TargetLang      string
-	DurationSeconds int
-	CreatedAt       time.Time
+	SessionID       string    `json:"sessionId"`
+	Filename        string    `json:"filename"`
+	Transcription   string    `json:"transcription,omitempty"`
+	Translation     string    `json:"translation,omitempty"`
+	VideoPath       string    `json:"videoPath,omitempty"`
+	AudioPath       string    `json:"audioPath,omitempty"`
+	TTSPath         string    `json:"ttsPath,omitempty"`
+	SourceLang      string    `json:"sourceLang,omitempty"`
+	TargetLang      string    `json:"targetLang,omitempty"`
+	DurationSeconds int       `json:"durationSeconds,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
 }
 
 type UserAudioSessionRecord struct {
-	SessionID      string
-	Filename       string
-	Transcription  string
-	Translation    string
-	AudioPath      string
-	SourceLang     string
-	TargetLang     string
-	HasDiarization bool
-	NumSpeakers    int
-	Segments       json.RawMessage
-	CreatedAt      time.Time
+	SessionID      string          `json:"sessionId"`
+	Filename       string          `json:"filename"`
+	Transcription  string          `json:"transcription,omitempty"`
+	Translation    string          `json:"translation,omitempty"`
+	AudioPath      string          `json:"audioPath,omitempty"`
+	TTSPath        string          `json:"ttsPath,omitempty"`
+	SourceLang     string          `json:"sourceLang,omitempty"`
+	TargetLang     string          `json:"targetLang,omitempty"`
+	HasDiarization bool            `json:"hasDiarization"`
+	NumSpeakers    int             `json:"numSpeakers,omitempty"`
+	Segments       json.RawMessage `json:"segments,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+// UserStreamingSessionRecord is a finished live caption session
+type UserStreamingSessionRecord struct {
+	SessionID            string    `json:"sessionId"`
+	SourceLang           string    `json:"sourceLang,omitempty"`
+	TargetLang           string    `json:"targetLang,omitempty"`
+	TotalChunks          int       `json:"totalChunks,omitempty"`
+	TotalDurationSeconds int       `json:"totalDurationSeconds,omitempty"`
+	FinalTranscript      string    `json:"finalTranscript,omitempty"`
+	FinalTranslation     string    `json:"finalTranslation,omitempty"`
+	CreatedAt            time.Time `json:"createdAt"`
 }
 
-func FindUserFileByHash(userID int, sessionType, contentHash string) (*UserFileMatch, error) {
+// FindUserFileByHash returns the user's most recent stored upload with the
+// content hash whose session was processed for the same language pair, or
+// nil if there is none. sourceLang "" (auto-detect) matches any source
+// language; an explicit one only matches sessions stored with it. Files
+// whose session was never recorded in the history do not match, as there
+// are no results to reuse.
+func FindUserFileByHash(userID int, sessionType, contentHash, sourceLang, targetLang string) (*UserFileMatch, error) {
 	if strings.TrimSpace(contentHash) == "" {
 		return nil, nil
 	}
+	table := historySessionTables[sessionType]
+	if table == "" {
+		return nil, fmt.Errorf("unknown session type %q", sessionType)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT f.id, f.session_id, f.file_key, f.created_at
+		FROM user_files f
+		JOIN %s s ON s.user_id = f.user_id AND s.session_id = f.session_id
+		WHERE f.user_id = $1 AND f.session_type = $2 AND f.content_hash = $3
+		  AND COALESCE(s.target_lang, '') = $4
+		  AND ($5 = '' OR s.source_lang = $5)
+		ORDER BY f.created_at DESC
+		LIMIT 1
+	`, table)
+
+	var match UserFileMatch
+	err := DB.QueryRow(query, userID, sessionType, contentHash,
+		langcode.Normalize(targetLang), langcode.Normalize(sourceLang)).Scan(
+		&match.ID,
+		&match.SessionID,
+		&match.FileKey,
+		&match.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lookup user file hash: %w", err)
+	}
+	return &match, nil
+}
+
+// FindUserFileByName returns the user's most recent stored file whose key
+// ends in fileName, as served from /download/{fileName}
+func FindUserFileByName(userID int, bucket, fileName string) (*UserFileMatch, error) {
+	if strings.TrimSpace(fileName) == "" {
+		return nil, nil
+	}
 
 	query := `
 		SELECT id, session_id, file_key, created_at
 		FROM user_files
-		WHERE user_id = $1 AND session_type = $2 AND content_hash = $3
+		WHERE user_id = $1 AND bucket_name = $2 AND right(file_key, length($3) + 1) = '/' || $3
 		ORDER BY created_at DESC
 		LIMIT 1
 	`
 
 	var match UserFileMatch
-	err := DB.QueryRow(query, userID, sessionType, contentHash).Scan(
+	err := DB.QueryRow(query, userID, bucket, fileName).Scan(
 		&match.ID,
 		&match.SessionID,
 		&match.FileKey,
@@ -116,11 +179,67 @@ func FindUserFileByHash(userID int, sessionType, contentHash string) (*UserFileM
 		return nil, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("lookup user file hash: %w", err)
+		return nil, fmt.Errorf("lookup user file by name: %w", err)
 	}
 	return &match, nil
 }
 
+// UserFileRecord is a stored object and its owner
+type UserFileRecord struct {
+	ID            int       `json:"id"`
+	UserID        *int      `json:"-"`
+	SessionType   string    `json:"sessionType"`
+	SessionID     string    `json:"sessionId"`
+	BucketName    string    `json:"bucketName"`
+	FileKey       string    `json:"fileKey"`
+	MimeType      string    `json:"mimeType,omitempty"`
+	FileSizeBytes int64     `json:"fileSizeBytes,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// GetUserFile returns a stored file by ID, or nil if there is none
+func GetUserFile(id int) (*UserFileRecord, error) {
+	query := `
+		SELECT id, user_id, session_type, session_id, bucket_name, file_key,
+		       COALESCE(mime_type, ''), COALESCE(file_size_bytes, 0), created_at
+		FROM user_files
+		WHERE id = $1
+	`
+
+	var record UserFileRecord
+	var userID sql.NullInt64
+	err := DB.QueryRow(query, id).Scan(
+		&record.ID,
+		&userID,
+		&record.SessionType,
+		&record.SessionID,
+		&record.BucketName,
+		&record.FileKey,
+		&record.MimeType,
+		&record.FileSizeBytes,
+		&record.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user file: %w", err)
+	}
+	if userID.Valid {
+		owner := int(userID.Int64)
+		record.UserID = &owner
+	}
+	return &record, nil
+}
+
+// TouchUserFile records that a stored file was accessed
+func TouchUserFile(id int) error {
+	if _, err := DB.Exec(`UPDATE user_files SET accessed_at = NOW(), updated_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("touch user file: %w", err)
+	}
+	return nil
+}
+
 func GetUserVideoSessionBySessionID(userID int, sessionID string) (*UserVideoSessionRecord, error) {
 	if strings.TrimSpace(sessionID) == "" {
 		return nil, nil
@@ -199,7 +318,7 @@ func GetUserAudioSessionBySessionID(userID int, sessionID string) (*UserAudioSes
 	}
 
 	query := `
-		SELECT session_id, filename, transcription, translation, audio_path, source_lang, target_lang,
+		SELECT session_id, filename, transcription, translation, audio_path, tts_path, source_lang, target_lang,
 		       has_diarization, num_speakers, segments, created_at
 		FROM user_audio_sessions
 		WHERE user_id = $1 AND session_id = $2
@@ -211,6 +330,7 @@ func GetUserAudioSessionBySessionID(userID int, sessionID string) (*UserAudioSes
 	var transcription sql.NullString
 	var translation sql.NullString
 	var audioPath sql.NullString
+	var ttsPath sql.NullString
 	var sourceLang sql.NullString
 	var targetLang sql.NullString
 	var numSpeakers sql.NullInt64
@@ -222,6 +342,7 @@ func GetUserAudioSessionBySessionID(userID int, sessionID string) (*UserAudioSes
 		&transcription,
 		&translation,
 		&audioPath,
+		&ttsPath,
 		&sourceLang,
 		&targetLang,
 		&record.HasDiarization,
@@ -245,6 +366,9 @@ func GetUserAudioSessionBySessionID(userID int, sessionID string) (*UserAudioSes
 	if audioPath.Valid {
 		record.AudioPath = audioPath.String
 	}
+	if ttsPath.Valid {
+		record.TTSPath = ttsPath.String
+	}
 	if sourceLang.Valid {
 		record.SourceLang = sourceLang.String
 	}
@@ -260,7 +384,61 @@ func GetUserAudioSessionBySessionID(userID int, sessionID string) (*UserAudioSes
 
 	return &record, nil
 }
+
+// GetUserStreamingSessionBySessionID returns the user's latest streaming
+// session with the ID, or nil if there is none
+func GetUserStreamingSessionBySessionID(userID int, sessionID string) (*UserStreamingSessionRecord, error) {
+	if strings.TrimSpace(sessionID) == "" {
+		return nil, nil
+	}
+
+	query := `
+		SELECT session_id, source_lang, target_lang, total_chunks, total_duration_seconds,
+		       final_transcript, final_translation, created_at
+		FROM user_streaming_sessions
+		WHERE user_id = $1 AND session_id = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var record UserStreamingSessionRecord
+	var sourceLang sql.NullString
+	var targetLang sql.NullString
+	var chunks sql.NullInt64
+	var duration sql.NullInt64
+	var transcript sql.NullString
+	var translation sql.NullString
+
+	err := DB.QueryRow(query, userID, sessionID).Scan(
+		&record.SessionID,
+		&sourceLang,
+		&targetLang,
+		&chunks,
+		&duration,
+		&transcript,
+		&translation,
+		&record.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load streaming session: %w", err)
+	}
+
+	record.SourceLang = sourceLang.String
+	record.TargetLang = targetLang.String
+	record.TotalChunks = int(chunks.Int64)
+	record.TotalDurationSeconds = int(duration.Int64)
+	record.FinalTranscript = transcript.String
+	record.FinalTranslation = translation.String
+
+	return &record, nil
+}
+
 func CreateUserVideoSession(userID int, input UserVideoSessionInput) (int, error) {
+	input.SourceLang = langcode.Normalize(input.SourceLang)
+	input.TargetLang = langcode.Normalize(input.TargetLang)
 	if strings.TrimSpace(input.SessionID) == "" || strings.TrimSpace(input.Filename) == "" {
 		return 0, fmt.Errorf("session_id and filename are required")
 	}
@@ -304,6 +482,8 @@ func CreateUserVideoSession(userID int, input UserVideoSessionInput) (int, error
 }
 
 func CreateUserAudioSession(userID int, input UserAudioSessionInput) (int, error) {
+	input.SourceLang = langcode.Normalize(input.SourceLang)
+	input.TargetLang = langcode.Normalize(input.TargetLang)
 	if strings.TrimSpace(input.SessionID) == "" || strings.TrimSpace(input.Filename) == "" {
 		return 0, fmt.Errorf("session_id and filename are required")
 	}
@@ -316,10 +496,10 @@ func CreateUserAudioSession(userID int, input UserAudioSessionInput) (int, error
 	query := `
 		INSERT INTO user_audio_sessions (
 			user_id, session_id, filename, transcription, translation, audio_path, source_lang, target_lang,
-			has_diarization, num_speakers, segments
+			has_diarization, num_speakers, segments, tts_path
 		)
 		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''),
-		        $9, NULLIF($10, 0), $11)
+		        $9, NULLIF($10, 0), $11, NULLIF($12, ''))
 		RETURNING id
 	`
 
@@ -337,6 +517,7 @@ func CreateUserAudioSession(userID int, input UserAudioSessionInput) (int, error
 		input.HasDiarization,
 		input.NumSpeakers,
 		segments,
+		input.TTSPath,
 	).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("insert audio session: %w", err)
@@ -346,6 +527,8 @@ func CreateUserAudioSession(userID int, input UserAudioSessionInput) (int, error
 }
 
 func CreateUserStreamingSession(userID int, input UserStreamingSessionInput) (int, error) {
+	input.SourceLang = langcode.Normalize(input.SourceLang)
+	input.TargetLang = langcode.Normalize(input.TargetLang)
 	if strings.TrimSpace(input.SessionID) == "" {
 		return 0, fmt.Errorf("session_id is required")
 	}
@@ -424,3 +607,253 @@ func CreateUserFile(userID *int, input UserFileInput) (int, error) {
 
 	return id, nil
 }
+
+// UserHistorySession is a video, audio or streaming session in the user's history list
+type UserHistorySession struct {
+	Type            string    `json:"type"`
+	SessionID       string    `json:"sessionId"`
+	Filename        string    `json:"filename,omitempty"`
+	SourceLang      string    `json:"sourceLang,omitempty"`
+	TargetLang      string    `json:"targetLang,omitempty"`
+	DurationSeconds *int      `json:"durationSeconds,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
+	Tags            []ItemTag `json:"tags"`
+}
+
+// HistoryFilter narrows the user's history list. Zero fields match everything.
+type HistoryFilter struct {
+	Type     string     // video, audio or streaming
+	TagIDs   []int64    // sessions carrying every one of these tags
+	Language string     // source or target language
+	From     *time.Time // created at or after
+	To       *time.Time // created before
+}
+
+// ListUserHistorySessions returns the user's sessions matching filter,
+// newest first
+func ListUserHistorySessions(userID int, filter HistoryFilter, limit, offset int) ([]UserHistorySession, int, error) {
+	tagIDs := filter.TagIDs
+	if tagIDs == nil {
+		tagIDs = []int64{} // a nil array would be sent as NULL
+	}
+	var from, to sql.NullTime
+	if filter.From != nil {
+		from = sql.NullTime{Time: filter.From.UTC(), Valid: true}
+	}
+	if filter.To != nil {
+		to = sql.NullTime{Time: filter.To.UTC(), Valid: true}
+	}
+
+	query := `
+		WITH sessions AS (
+			SELECT 'video' AS type, session_id, filename, source_lang, target_lang, duration_seconds, created_at
+			FROM user_video_sessions WHERE user_id = $1
+			UNION ALL
+			SELECT 'audio', session_id, filename, source_lang, target_lang, NULL::INTEGER, created_at
+			FROM user_audio_sessions WHERE user_id = $1
+			UNION ALL
+			SELECT 'streaming', session_id, '', source_lang, target_lang, total_duration_seconds, created_at
+			FROM user_streaming_sessions WHERE user_id = $1
+		)
+		SELECT s.type, s.session_id, s.filename, s.source_lang, s.target_lang, s.duration_seconds, s.created_at,
+		       COUNT(*) OVER () AS total
+		FROM sessions s
+		WHERE ($2 = '' OR s.type = $2)
+		  AND (
+			CARDINALITY($3::INTEGER[]) = 0 OR
+			(SELECT COUNT(DISTINCT st.tag_id) FROM session_tags st
+			 WHERE st.session_type = s.type AND st.session_id = s.session_id AND st.tag_id = ANY($3)) = CARDINALITY($3::INTEGER[])
+		  )
+		  AND ($6 = '' OR s.source_lang = $6 OR s.target_lang = $6)
+		  AND ($7::TIMESTAMP IS NULL OR s.created_at >= $7)
+		  AND ($8::TIMESTAMP IS NULL OR s.created_at < $8)
+		ORDER BY s.created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := DB.Query(query, userID, filter.Type, pq.Array(tagIDs), limit, offset,
+		langcode.Normalize(filter.Language), from, to)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list history sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []UserHistorySession{}
+	var sessionIDs []string
+	total := 0
+	for rows.Next() {
+		var item UserHistorySession
+		var sourceLang sql.NullString
+		var targetLang sql.NullString
+		var duration sql.NullInt64
+		if err := rows.Scan(&item.Type, &item.SessionID, &item.Filename, &sourceLang, &targetLang,
+			&duration, &item.CreatedAt, &total); err != nil {
+			return nil, 0, fmt.Errorf("scan history session: %w", err)
+		}
+		item.SourceLang = sourceLang.String
+		item.TargetLang = targetLang.String
+		if duration.Valid {
+			seconds := int(duration.Int64)
+			item.DurationSeconds = &seconds
+		}
+		item.Tags = []ItemTag{}
+		sessions = append(sessions, item)
+		sessionIDs = append(sessionIDs, item.SessionID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate history sessions: %w", err)
+	}
+
+	tags, err := getSessionTagsBulk(userID, sessionIDs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("load session tags: %w", err)
+	}
+	for i := range sessions {
+		if itemTags, ok := tags[sessions[i].Type+":"+sessions[i].SessionID]; ok {
+			sessions[i].Tags = itemTags
+		}
+	}
+
+	return sessions, total, nil
+}
+
+// UserOwnsHistorySession reports whether the user has a history session of the type with the ID
+func UserOwnsHistorySession(userID int, sessionType, sessionID string) (bool, error) {
+	table := historySessionTables[sessionType]
+	if table == "" {
+		return false, nil
+	}
+
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE user_id = $1 AND session_id = $2)`, table)
+	if err := DB.QueryRow(query, userID, sessionID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check history session owner: %w", err)
+	}
+	return exists, nil
+}
+
+// historySessionTables maps history session types to their tables
+var historySessionTables = map[string]string{
+	TagItemVideo:     "user_video_sessions",
+	TagItemAudio:     "user_audio_sessions",
+	TagItemStreaming: "user_streaming_sessions",
+}
+
+// historyRAGSourceTypes maps history session types to the RAG source type
+// their transcripts are indexed under
+var historyRAGSourceTypes = map[string]string{
+	TagItemVideo: SourceTypeVideo,
+	TagItemAudio: SourceTypeRecording,
+}
+
+// ListHistorySessionFiles returns the user's stored files of a session
+func ListHistorySessionFiles(userID int, sessionType, sessionID string) ([]UserFileRecord, error) {
+	rows, err := DB.Query(`
+		SELECT id, user_id, session_type, session_id, bucket_name, file_key,
+		       COALESCE(mime_type, ''), COALESCE(file_size_bytes, 0), created_at
+		FROM user_files
+		WHERE user_id = $1 AND session_type = $2 AND session_id = $3
+		ORDER BY created_at
+	`, userID, sessionType, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("list session files: %w", err)
+	}
+	defer rows.Close()
+
+	files := []UserFileRecord{}
+	for rows.Next() {
+		var record UserFileRecord
+		var owner sql.NullInt64
+		if err := rows.Scan(&record.ID, &owner, &record.SessionType, &record.SessionID, &record.BucketName,
+			&record.FileKey, &record.MimeType, &record.FileSizeBytes, &record.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan session file: %w", err)
+		}
+		if owner.Valid {
+			id := int(owner.Int64)
+			record.UserID = &id
+		}
+		files = append(files, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate session files: %w", err)
+	}
+	return files, nil
+}
+
+// GetHistorySessionTags returns the user's tags on a session
+func GetHistorySessionTags(userID int, sessionType, sessionID string) ([]ItemTag, error) {
+	tags, err := getSessionTagsBulk(userID, []string{sessionID})
+	if err != nil {
+		return nil, fmt.Errorf("load session tags: %w", err)
+	}
+	if itemTags, ok := tags[sessionType+":"+sessionID]; ok {
+		return itemTags, nil
+	}
+	return []ItemTag{}, nil
+}
+
+// DeleteUserHistorySession deletes the user's session of the type with the
+// ID together with its tags, the records of its stored files and, for
+// videos and recordings, its chunks, summaries and chat sessions. It returns
+// the stored files so the caller can remove the objects. It reports false when
+// the user has no such session.
+func DeleteUserHistorySession(userID int, sessionType, sessionID string) ([]UserFileRecord, bool, error) {
+	table := historySessionTables[sessionType]
+	if table == "" {
+		return nil, false, nil
+	}
+
+	files, err := ListHistorySessionFiles(userID, sessionType, sessionID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, false, fmt.Errorf("begin history delete: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE user_id = $1 AND session_id = $2`, table), userID, sessionID)
+	if err != nil {
+		return nil, false, fmt.Errorf("delete history session: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return nil, false, fmt.Errorf("delete history session: %w", err)
+	}
+	if deleted == 0 {
+		return nil, false, nil
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM session_tags st USING user_tags t
+		WHERE st.tag_id = t.id AND t.user_id = $1 AND st.session_type = $2 AND st.session_id = $3
+	`, userID, sessionType, sessionID); err != nil {
+		return nil, false, fmt.Errorf("delete history session tags: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM user_files WHERE user_id = $1 AND session_type = $2 AND session_id = $3`,
+		userID, sessionType, sessionID); err != nil {
+		return nil, false, fmt.Errorf("delete history session files: %w", err)
+	}
+
+	// Uploaded videos and recordings may have been indexed for chat
+	if sourceType := historyRAGSourceTypes[sessionType]; sourceType != "" {
+		sourceID := RAGSourceID(sourceType, sessionID)
+		for _, statement := range []string{
+			`DELETE FROM meeting_chunks WHERE meeting_id = $1`,
+			`DELETE FROM rag_source_summaries WHERE source_id = $1`,
+			`DELETE FROM rag_source_links WHERE source_id = $1`,
+			`DELETE FROM meeting_chat_sessions WHERE meeting_id = $1`,
+		} {
+			if _, err := tx.Exec(statement, sourceID); err != nil {
+				return nil, false, fmt.Errorf("delete history session knowledge: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("commit history delete: %w", err)
+	}
+	return files, true, nil
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"realtime-caption-translator/internal/langcode"
+)
+
+// Interpretation channels
+const (
+	ChannelOriginal    = "original"
+	ChannelInterpreter = "interpreter"
+)
+
+// InterpretationSegment is a timed transcript segment from one channel of an
+// interpreted meeting
+type InterpretationSegment struct {
+	ID            int               `json:"id"`
+	MeetingID     string            `json:"meetingId"`
+	Channel       string            `json:"channel"`
+	ParticipantID int               `json:"participantId,omitempty"`
+	SpeakerName   string            `json:"speakerName,omitempty"`
+	Language      string            `json:"language,omitempty"`
+	Text          string            `json:"text"`
+	Translations  map[string]string `json:"translations,omitempty"`
+	StartedAt     time.Time         `json:"startedAt"`
+	EndedAt       time.Time         `json:"endedAt"`
+}
+
+// SaveInterpretationSegment stores a segment of either channel
+func SaveInterpretationSegment(segment *InterpretationSegment) error {
+	segment.Language = langcode.Normalize(segment.Language)
+	var translations interface{}
+	if len(segment.Translations) > 0 {
+		data, err := json.Marshal(segment.Translations)
+		if err != nil {
+			return fmt.Errorf("failed to encode translations: %w", err)
+		}
+		translations = data
+	}
+
+	var participantID interface{}
+	if segment.ParticipantID != 0 {
+		participantID = segment.ParticipantID
+	}
+
+	err := DB.QueryRow(`
+		INSERT INTO interpretation_segments
+			(meeting_id, channel, participant_id, speaker_name, language, text, translations, started_at, ended_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, segment.MeetingID, segment.Channel, participantID, nullString(segment.SpeakerName), nullString(segment.Language),
+		segment.Text, translations, segment.StartedAt, segment.EndedAt).Scan(&segment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save interpretation segment: %w", err)
+	}
+	return nil
+}
+
+// ListInterpretationSegments returns a meeting's segments of both channels in time order
+func ListInterpretationSegments(meetingID string) ([]InterpretationSegment, error) {
+	rows, err := DB.Query(`
+		SELECT id, meeting_id, channel, participant_id, speaker_name, language, text, translations, started_at, ended_at
+		FROM interpretation_segments
+		WHERE meeting_id = $1
+		ORDER BY started_at, id
+	`, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interpretation segments: %w", err)
+	}
+	defer rows.Close()
+
+	var segments []InterpretationSegment
+	for rows.Next() {
+		var segment InterpretationSegment
+		var participantID sql.NullInt64
+		var speakerName, language sql.NullString
+		var translations []byte
+		if err := rows.Scan(&segment.ID, &segment.MeetingID, &segment.Channel, &participantID, &speakerName, &language,
+			&segment.Text, &translations, &segment.StartedAt, &segment.EndedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan interpretation segment: %w", err)
+		}
+		segment.ParticipantID = int(participantID.Int64)
+		segment.SpeakerName = speakerName.String
+		segment.Language = language.String
+		if len(translations) > 0 {
+			if err := json.Unmarshal(translations, &segment.Translations); err != nil {
+				return nil, fmt.Errorf("failed to decode translations: %w", err)
+			}
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments, rows.Err()
+}
+
+// SetMeetingInterpreter designates a user as an interpreter of a meeting, or
+// withdraws the designation when designate is false
+func SetMeetingInterpreter(meetingID string, userID int, designate bool, grantedBy int) error {
+	if !designate {
+		if _, err := DB.Exec(`DELETE FROM meeting_interpreters WHERE meeting_id = $1 AND user_id = $2`, meetingID, userID); err != nil {
+			return fmt.Errorf("failed to remove interpreter: %w", err)
+		}
+		return nil
+	}
+	_, err := DB.Exec(`
+		INSERT INTO meeting_interpreters (meeting_id, user_id, granted_by, granted_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (meeting_id, user_id) DO UPDATE SET granted_by = EXCLUDED.granted_by, granted_at = NOW()
+	`, meetingID, userID, grantedBy)
+	if err != nil {
+		return fmt.Errorf("failed to add interpreter: %w", err)
+	}
+	return nil
+}
+
+// IsMeetingInterpreter reports whether a user is a designated interpreter of
+// a meeting
+func IsMeetingInterpreter(meetingID string, userID int) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM meeting_interpreters WHERE meeting_id = $1 AND user_id = $2)
+	`, meetingID, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check interpreter: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,297 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// Job is a unit of background work in the processing_jobs queue
+type Job struct {
+	ID          int             `json:"id"`
+	Kind        string          `json:"kind"`
+	SessionID   string          `json:"sessionId,omitempty"`
+	UserID      *int            `json:"userId,omitempty"`
+	Payload     json.RawMessage `json:"-"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"maxAttempts"`
+	RunAt       time.Time       `json:"runAt"`
+	LastError   string          `json:"lastError,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	CompletedAt *time.Time      `json:"completedAt,omitempty"`
+}
+
+// FinalAttempt reports whether a failure of the current attempt is terminal
+func (j *Job) FinalAttempt() bool {
+	return j.Attempts >= j.MaxAttempts
+}
+
+const jobColumns = `id, kind, session_id, user_id, payload, status, attempts, max_attempts, run_at, last_error, result, created_at, updated_at, completed_at`
+
+func scanJob(row interface{ Scan(...interface{}) error }) (*Job, error) {
+	var job Job
+	var sessionID, lastError sql.NullString
+	var userID sql.NullInt64
+	var completedAt sql.NullTime
+	var result []byte
+	if err := row.Scan(&job.ID, &job.Kind, &sessionID, &userID, &job.Payload, &job.Status, &job.Attempts,
+		&job.MaxAttempts, &job.RunAt, &lastError, &result, &job.CreatedAt, &job.UpdatedAt, &completedAt); err != nil {
+		return nil, err
+	}
+	job.SessionID = sessionID.String
+	job.LastError = lastError.String
+	if len(result) > 0 {
+		job.Result = result
+	}
+	if userID.Valid {
+		id := int(userID.Int64)
+		job.UserID = &id
+	}
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+	return &job, nil
+}
+
+// EnqueueJob inserts a queued job and fills in its ID and timestamps
+func EnqueueJob(job *Job) error {
+	if len(job.Payload) == 0 {
+		job.Payload = json.RawMessage("{}")
+	}
+	var userID interface{}
+	if job.UserID != nil {
+		userID = *job.UserID
+	}
+
+	err := DB.QueryRow(`
+		INSERT INTO processing_jobs (kind, session_id, user_id, payload, max_attempts)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, run_at, created_at, updated_at
+	`, job.Kind, nullString(job.SessionID), userID, []byte(job.Payload), job.MaxAttempts).
+		Scan(&job.ID, &job.Status, &job.RunAt, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return nil
+}
+
+// EnqueueJobWithin inserts a queued job unless its user already has
+// maxActive or more queued or running jobs (0 for no limit). The count and
+// the insert run under a lock on the user, so concurrent requests cannot
+// both pass the check. Returns the user's active job count before the
+// insert and whether the job was queued.
+func EnqueueJobWithin(job *Job, maxActive int) (int, bool, error) {
+	if job.UserID == nil || maxActive <= 0 {
+		return 0, true, EnqueueJob(job)
+	}
+	if len(job.Payload) == 0 {
+		job.Payload = json.RawMessage("{}")
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('processing_jobs'), $1)`, *job.UserID); err != nil {
+		return 0, false, fmt.Errorf("failed to lock user jobs: %w", err)
+	}
+	var active int
+	if err := tx.QueryRow(`
+		SELECT COUNT(*) FROM processing_jobs WHERE user_id = $1 AND status IN ('queued', 'running')
+	`, *job.UserID).Scan(&active); err != nil {
+		return 0, false, fmt.Errorf("failed to count active jobs: %w", err)
+	}
+	if active >= maxActive {
+		return active, false, nil
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO processing_jobs (kind, session_id, user_id, payload, max_attempts)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, run_at, created_at, updated_at
+	`, job.Kind, nullString(job.SessionID), *job.UserID, []byte(job.Payload), job.MaxAttempts).
+		Scan(&job.ID, &job.Status, &job.RunAt, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, false, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return active, true, nil
+}
+
+// ClaimJob locks the oldest ready job of one of the given kinds, marks it
+// running and counts the attempt. Returns nil when nothing is ready.
+func ClaimJob(kinds []string) (*Job, error) {
+	row := DB.QueryRow(`
+		UPDATE processing_jobs
+		SET status = 'running', attempts = attempts + 1, locked_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM processing_jobs
+			WHERE status = 'queued' AND run_at <= NOW() AND kind = ANY($1)
+			ORDER BY run_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING `+jobColumns, pq.Array(kinds))
+
+	job, err := scanJob(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return job, nil
+}
+
+// CompleteJob marks a running job as succeeded
+func CompleteJob(id int) error {
+	_, err := DB.Exec(`
+		UPDATE processing_jobs
+		SET status = 'succeeded', locked_at = NULL, last_error = NULL, updated_at = NOW(), completed_at = NOW()
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+	return nil
+}
+
+// SetJobResult stores the summary a job reports, e.g. before it completes
+func SetJobResult(id int, result interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode job result: %w", err)
+	}
+	if _, err := DB.Exec(`UPDATE processing_jobs SET result = $2, updated_at = NOW() WHERE id = $1`, id, data); err != nil {
+		return fmt.Errorf("failed to save job result: %w", err)
+	}
+	return nil
+}
+
+// RetryJob puts a failed attempt back in the queue to run again at runAt
+func RetryJob(id int, lastError string, runAt time.Time) error {
+	_, err := DB.Exec(`
+		UPDATE processing_jobs
+		SET status = 'queued', locked_at = NULL, last_error = $2, run_at = $3, updated_at = NOW()
+		WHERE id = $1
+	`, id, lastError, runAt)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule job: %w", err)
+	}
+	return nil
+}
+
+// FailJob marks a job as permanently failed
+func FailJob(id int, lastError string) error {
+	_, err := DB.Exec(`
+		UPDATE processing_jobs
+		SET status = 'failed', locked_at = NULL, last_error = $2, updated_at = NOW(), completed_at = NOW()
+		WHERE id = $1
+	`, id, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to mark job failed: %w", err)
+	}
+	return nil
+}
+
+// RequeueStaleJobs returns running jobs locked before the cutoff to the
+// queue. Jobs left running by a crashed or restarted server are picked up
+// again; their interrupted attempt still counts.
+func RequeueStaleJobs(lockedBefore time.Time) (int64, error) {
+	result, err := DB.Exec(`
+		UPDATE processing_jobs
+		SET status = CASE WHEN attempts >= max_attempts THEN 'failed' ELSE 'queued' END,
+		    completed_at = CASE WHEN attempts >= max_attempts THEN NOW() ELSE NULL END,
+		    last_error = COALESCE(last_error, 'interrupted by server restart'),
+		    locked_at = NULL, run_at = NOW(), updated_at = NOW()
+		WHERE status = 'running' AND locked_at < $1
+	`, lockedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue stale jobs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// CountQueuedJobs returns how many jobs are waiting to run, including
+// retries scheduled for later
+func CountQueuedJobs() (int, error) {
+	var count int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM processing_jobs WHERE status = 'queued'`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count queued jobs: %w", err)
+	}
+	return count, nil
+}
+
+// ListActiveJobs returns the queued and running jobs of the given kinds,
+// oldest first. A running job's UpdatedAt is when it was claimed.
+func ListActiveJobs(kinds []string) ([]Job, error) {
+	rows, err := DB.Query(`
+		SELECT `+jobColumns+`
+		FROM processing_jobs
+		WHERE status IN ('queued', 'running') AND kind = ANY($1)
+		ORDER BY run_at, id
+	`, pq.Array(kinds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+// GetJob returns a job by ID, or nil if it does not exist
+func GetJob(id int) (*Job, error) {
+	job, err := scanJob(DB.QueryRow(`SELECT `+jobColumns+` FROM processing_jobs WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// GetLatestJobBySession returns the newest job of a kind for a progress
+// session, or nil if there is none
+func GetLatestJobBySession(kind, sessionID string) (*Job, error) {
+	job, err := scanJob(DB.QueryRow(`
+		SELECT `+jobColumns+`
+		FROM processing_jobs
+		WHERE kind = $1 AND session_id = $2
+		ORDER BY id DESC
+		LIMIT 1
+	`, kind, sessionID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
@@ -25,7 +25,7 @@ func UpsertKeycloakUser(sub, preferredUsername, email string, emailVerified bool
 
 	var userID int
 	err = tx.QueryRow(`SELECT user_id FROM keycloak_users WHERE keycloak_sub = $1`, sub).Scan(&userID)
-	now := time.Now()
+	now := time.Now().UTC()
 
 	if err == sql.ErrNoRows {
 		username, err := generateUniqueUsername(tx, preferredUsername, email, sub)
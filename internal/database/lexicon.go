@@ -0,0 +1,122 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"realtime-caption-translator/internal/langcode"
+)
+
+// Lexicon match types
+const (
+	LexiconMatchWord  = "word"  // whole word, case-insensitive
+	LexiconMatchExact = "exact" // case-sensitive substring
+	LexiconMatchRegex = "regex" // regular expression; replacement may use $1
+)
+
+// LexiconEntry rewrites text before synthesis so a name is pronounced correctly
+type LexiconEntry struct {
+	ID          int       `json:"id"`
+	OrgID       string    `json:"orgId"` // "" applies to every org
+	Language    string    `json:"language"`
+	Pattern     string    `json:"pattern"`
+	Replacement string    `json:"replacement"`
+	MatchType   string    `json:"matchType"`
+	CreatedBy   *int      `json:"createdBy,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+const lexiconColumns = `id, org_id, language, pattern, replacement, match_type, created_by, created_at, updated_at`
+
+func scanLexiconEntry(row interface{ Scan(...interface{}) error }) (*LexiconEntry, error) {
+	var entry LexiconEntry
+	var createdBy sql.NullInt64
+	if err := row.Scan(&entry.ID, &entry.OrgID, &entry.Language, &entry.Pattern, &entry.Replacement,
+		&entry.MatchType, &createdBy, &entry.CreatedAt, &entry.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if createdBy.Valid {
+		id := int(createdBy.Int64)
+		entry.CreatedBy = &id
+	}
+	return &entry, nil
+}
+
+// ListLexiconEntries returns every lexicon entry, global entries first
+func ListLexiconEntries() ([]LexiconEntry, error) {
+	rows, err := DB.Query(`SELECT ` + lexiconColumns + ` FROM pronunciation_lexicon ORDER BY org_id, language, pattern`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lexicon entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []LexiconEntry{}
+	for rows.Next() {
+		entry, err := scanLexiconEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan lexicon entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+// GetLexiconEntry returns an entry by ID, or nil if it does not exist
+func GetLexiconEntry(id int) (*LexiconEntry, error) {
+	entry, err := scanLexiconEntry(DB.QueryRow(`SELECT `+lexiconColumns+` FROM pronunciation_lexicon WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lexicon entry: %w", err)
+	}
+	return entry, nil
+}
+
+// UpsertLexiconEntry creates an entry, or replaces the one with the same
+// org, language and pattern, and fills in its ID and timestamps
+func UpsertLexiconEntry(entry *LexiconEntry) error {
+	entry.Language = langcode.NormalizeTag(entry.Language)
+	var createdBy interface{}
+	if entry.CreatedBy != nil {
+		createdBy = *entry.CreatedBy
+	}
+
+	err := DB.QueryRow(`
+		INSERT INTO pronunciation_lexicon (org_id, language, pattern, replacement, match_type, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (org_id, language, pattern)
+		DO UPDATE SET replacement = EXCLUDED.replacement, match_type = EXCLUDED.match_type, updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`, entry.OrgID, entry.Language, entry.Pattern, entry.Replacement, entry.MatchType, createdBy).
+		Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save lexicon entry: %w", err)
+	}
+	return nil
+}
+
+// UpdateLexiconEntry changes an existing entry's rule
+func UpdateLexiconEntry(entry *LexiconEntry) error {
+	entry.Language = langcode.NormalizeTag(entry.Language)
+	err := DB.QueryRow(`
+		UPDATE pronunciation_lexicon
+		SET language = $2, pattern = $3, replacement = $4, match_type = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, entry.ID, entry.Language, entry.Pattern, entry.Replacement, entry.MatchType).Scan(&entry.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update lexicon entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteLexiconEntry removes an entry
+func DeleteLexiconEntry(id int) error {
+	if _, err := DB.Exec(`DELETE FROM pronunciation_lexicon WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete lexicon entry: %w", err)
+	}
+	return nil
+}
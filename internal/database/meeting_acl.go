@@ -15,6 +15,9 @@ type MeetingACLEntry struct {
 	GrantedBy *int      `json:"grantedBy,omitempty"`
 	GrantedAt time.Time `json:"grantedAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+	// Set when a moderator removed the user from the live meeting
+	RemovedAt   *time.Time `json:"removedAt,omitempty"`
+	RemovalNote string     `json:"removalNote,omitempty"`
 	// Additional fields for API responses
 	Username    string `json:"username,omitempty"`
 	DisplayName string `json:"displayName,omitempty"`
@@ -23,6 +26,7 @@ type MeetingACLEntry struct {
 // Role hierarchy levels for comparison
 const (
 	RoleOwner  = "owner"
+	RoleCoHost = "cohost" // moderator powers of the owner, without ownership
 	RoleEditor = "editor"
 	RoleViewer = "viewer"
 )
@@ -31,6 +35,8 @@ const (
 func roleLevel(role string) int {
 	switch role {
 	case RoleOwner:
+		return 4
+	case RoleCoHost:
 		return 3
 	case RoleEditor:
 		return 2
@@ -43,7 +49,7 @@ func roleLevel(role string) int {
 
 // GetUserMeetingRole returns the role a user has for a meeting
 // Returns "owner" if user is the meeting creator, otherwise checks ACL table
-// Returns empty string if user has no access
+// Returns empty string if user has no access or was removed from the meeting
 func GetUserMeetingRole(userID int, meetingID string) (string, error) {
 	// First check if user is the meeting creator (automatic owner)
 	var createdBy sql.NullInt64
@@ -63,7 +69,7 @@ func GetUserMeetingRole(userID int, meetingID string) (string, error) {
 	var role string
 	err = DB.QueryRow(`
 		SELECT role FROM meeting_access_control
-		WHERE meeting_id = $1 AND user_id = $2
+		WHERE meeting_id = $1 AND user_id = $2 AND removed_at IS NULL
 	`, meetingID, userID).Scan(&role)
 	if err == sql.ErrNoRows {
 		return "", nil // No access
@@ -75,8 +81,14 @@ func GetUserMeetingRole(userID int, meetingID string) (string, error) {
 	return role, nil
 }
 
+// IsModeratorRole reports whether a role may moderate a meeting: record,
+// review consent, reprocess and manage editors and viewers
+func IsModeratorRole(role string) bool {
+	return role == RoleOwner || role == RoleCoHost
+}
+
 // UserHasMinimumRole checks if a user has at least the required role level
-// Role hierarchy: owner > editor > viewer
+// Role hierarchy: owner > cohost > editor > viewer
 func UserHasMinimumRole(userID int, meetingID string, requiredRole string) (bool, error) {
 	userRole, err := GetUserMeetingRole(userID, meetingID)
 	if err != nil {
@@ -118,12 +130,17 @@ func GrantMeetingAccess(meetingID string, userID int, role string, grantedBy int
 		INSERT INTO meeting_access_control (meeting_id, user_id, role, granted_by, granted_at, updated_at)
 		VALUES ($1, $2, $3, $4, NOW(), NOW())
 		ON CONFLICT (meeting_id, user_id)
-		DO UPDATE SET role = EXCLUDED.role, granted_by = EXCLUDED.granted_by, updated_at = NOW()
+		DO UPDATE SET role = EXCLUDED.role, granted_by = EXCLUDED.granted_by, updated_at = NOW(),
+			removed_at = NULL, removal_note = NULL
 	`
 	_, err = DB.Exec(query, meetingID, userID, role, grantedBy)
 	if err != nil {
 		return fmt.Errorf("failed to grant meeting access: %w", err)
 	}
+	// Granting access anew lifts an earlier removal
+	if _, err := DB.Exec(`DELETE FROM meeting_removals WHERE meeting_id = $1 AND user_id = $2`, meetingID, userID); err != nil {
+		return fmt.Errorf("failed to lift meeting removal: %w", err)
+	}
 
 	return nil
 }
@@ -173,6 +190,7 @@ func ListMeetingAccessControl(meetingID string) ([]MeetingACLEntry, error) {
 		SELECT
 			mac.id, mac.meeting_id, mac.user_id, mac.role,
 			mac.granted_by, mac.granted_at, mac.updated_at,
+			mac.removed_at, COALESCE(mac.removal_note, ''),
 			u.username, u.display_name
 		FROM meeting_access_control mac
 		JOIN users u ON mac.user_id = u.id
@@ -180,8 +198,9 @@ func ListMeetingAccessControl(meetingID string) ([]MeetingACLEntry, error) {
 		ORDER BY
 			CASE mac.role
 				WHEN 'owner' THEN 1
-				WHEN 'editor' THEN 2
-				WHEN 'viewer' THEN 3
+				WHEN 'cohost' THEN 2
+				WHEN 'editor' THEN 3
+				WHEN 'viewer' THEN 4
 			END,
 			u.display_name ASC
 	`
@@ -196,6 +215,7 @@ func ListMeetingAccessControl(meetingID string) ([]MeetingACLEntry, error) {
 	for rows.Next() {
 		var entry MeetingACLEntry
 		var grantedBy sql.NullInt64
+		var removedAt sql.NullTime
 		err := rows.Scan(
 			&entry.ID,
 			&entry.MeetingID,
@@ -204,6 +224,8 @@ func ListMeetingAccessControl(meetingID string) ([]MeetingACLEntry, error) {
 			&grantedBy,
 			&entry.GrantedAt,
 			&entry.UpdatedAt,
+			&removedAt,
+			&entry.RemovalNote,
 			&entry.Username,
 			&entry.DisplayName,
 		)
@@ -215,6 +237,9 @@ func ListMeetingAccessControl(meetingID string) ([]MeetingACLEntry, error) {
 			grantedByInt := int(grantedBy.Int64)
 			entry.GrantedBy = &grantedByInt
 		}
+		if removedAt.Valid {
+			entry.RemovedAt = &removedAt.Time
+		}
 
 		entries = append(entries, entry)
 	}
@@ -344,3 +369,157 @@ func AutoGrantViewerAccess(meetingID string, userID int) error {
 
 	return nil
 }
+
+// SetMeetingCoHost promotes an editor to co-host, or demotes a co-host back
+// to editor when promote is false
+func SetMeetingCoHost(meetingID string, userID int, promote bool, grantedBy int) error {
+	from, to := RoleEditor, RoleCoHost
+	if !promote {
+		from, to = RoleCoHost, RoleEditor
+	}
+
+	result, err := DB.Exec(`
+		UPDATE meeting_access_control
+		SET role = $4, granted_by = $5, updated_at = NOW()
+		WHERE meeting_id = $1 AND user_id = $2 AND role = $3
+	`, meetingID, userID, from, to, grantedBy)
+	if err != nil {
+		return fmt.Errorf("failed to update co-host: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("user is not an %s of this meeting", from)
+	}
+	return nil
+}
+
+// TransferMeetingOwnership makes a member with explicit access the meeting's
+// owner. The previous owner keeps previousOwnerRole (co-host, editor or
+// viewer), or loses access when it is empty.
+func TransferMeetingOwnership(meetingID string, fromUserID, toUserID int, previousOwnerRole string) error {
+	if previousOwnerRole != "" && previousOwnerRole != RoleCoHost && previousOwnerRole != RoleEditor && previousOwnerRole != RoleViewer {
+		return fmt.Errorf("invalid role: previous owner can keep 'cohost', 'editor' or 'viewer'")
+	}
+	if fromUserID == toUserID {
+		return fmt.Errorf("user already owns this meeting")
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE meetings SET created_by = $3 WHERE id = $1 AND created_by = $2`, meetingID, fromUserID, toUserID)
+	if err != nil {
+		return fmt.Errorf("failed to transfer ownership: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+		return fmt.Errorf("meeting not found or not owned by user")
+	}
+
+	// The new owner is owner by definition, so their explicit entry goes
+	result, err = tx.Exec(`DELETE FROM meeting_access_control WHERE meeting_id = $1 AND user_id = $2`, meetingID, toUserID)
+	if err != nil {
+		return fmt.Errorf("failed to update new owner's access: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+		return fmt.Errorf("user does not have explicit access to this meeting")
+	}
+
+	if previousOwnerRole != "" {
+		if _, err := tx.Exec(`
+			INSERT INTO meeting_access_control (meeting_id, user_id, role, granted_by, granted_at, updated_at)
+			VALUES ($1, $2, $3, $4, NOW(), NOW())
+			ON CONFLICT (meeting_id, user_id)
+			DO UPDATE SET role = EXCLUDED.role, granted_by = EXCLUDED.granted_by, updated_at = NOW()
+		`, meetingID, fromUserID, previousOwnerRole, toUserID); err != nil {
+			return fmt.Errorf("failed to keep previous owner's access: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit ownership transfer: %w", err)
+	}
+	return nil
+}
+
+// NoteMeetingRemoval records that a moderator removed a participant from the
+// live meeting. The removal is kept against the participant's user or, for
+// guests, their guest key, and an existing ACL entry of the user is marked
+// so its role no longer applies. No access is created. The meeting creator
+// cannot be removed.
+func NoteMeetingRemoval(meetingID string, participantID int, note string, removedBy *int) error {
+	var userID sql.NullInt64
+	var guestKey sql.NullString
+	var createdBy sql.NullInt64
+	err := DB.QueryRow(`
+		SELECT p.user_id, p.guest_key, m.created_by
+		FROM meeting_participants p
+		JOIN meetings m ON m.id = p.meeting_id
+		WHERE p.id = $1 AND p.meeting_id = $2
+	`, participantID, meetingID).Scan(&userID, &guestKey, &createdBy)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("participant not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get participant: %w", err)
+	}
+	if userID.Valid && createdBy.Valid && userID.Int64 == createdBy.Int64 {
+		return fmt.Errorf("cannot remove the meeting creator")
+	}
+	if !userID.Valid && (!guestKey.Valid || guestKey.String == "") {
+		return nil // Nothing identifies the guest beyond their participant ID
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if userID.Valid {
+		guestKey = sql.NullString{}
+		if _, err := tx.Exec(`
+			UPDATE meeting_access_control
+			SET removed_at = NOW(), removal_note = $3, updated_at = NOW()
+			WHERE meeting_id = $1 AND user_id = $2
+		`, meetingID, userID, note); err != nil {
+			return fmt.Errorf("failed to note removal on access entry: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO meeting_removals (meeting_id, user_id, guest_key, note, removed_by)
+		VALUES ($1, $2, $3, $4, $5)
+	`, meetingID, userID, guestKey, note, removedBy); err != nil {
+		return fmt.Errorf("failed to note meeting removal: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit meeting removal: %w", err)
+	}
+	return nil
+}
+
+// IsRemovedFromMeeting reports whether a moderator removed the user, or the
+// guest with this guest key, from the live meeting since the user was last
+// granted access
+func IsRemovedFromMeeting(meetingID string, userID *int, guestKey string) (bool, error) {
+	if userID == nil && guestKey == "" {
+		return false, nil
+	}
+	var removed bool
+	err := DB.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM meeting_removals
+			WHERE meeting_id = $1 AND (user_id = $2 OR ($3 <> '' AND guest_key = $3))
+		)
+	`, meetingID, userID, guestKey).Scan(&removed)
+	if err != nil {
+		return false, fmt.Errorf("failed to check meeting removal: %w", err)
+	}
+	return removed, nil
+}
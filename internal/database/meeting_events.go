@@ -0,0 +1,106 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// appendEventAttempts bounds retries when concurrent appends pick the same
+// sequence number
+const appendEventAttempts = 5
+
+// MeetingEvent is one entry in a meeting's broadcast log
+type MeetingEvent struct {
+	ID            int64           `json:"id"`
+	MeetingID     string          `json:"meetingId"`
+	Seq           int64           `json:"seq"`
+	Type          string          `json:"type"`
+	ParticipantID int             `json:"participantId,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"createdAt"`
+}
+
+// AppendMeetingEvent stores an event under the meeting's next sequence
+// number and returns that number
+func AppendMeetingEvent(meetingID, eventType string, participantID int, payload []byte) (int64, error) {
+	var participant interface{}
+	if participantID != 0 {
+		participant = participantID
+	}
+
+	for attempt := 1; ; attempt++ {
+		var seq int64
+		err := DB.QueryRow(`
+			INSERT INTO meeting_events (meeting_id, seq, type, participant_id, payload)
+			SELECT $1, COALESCE(MAX(seq), 0) + 1, $2, $3, $4
+			FROM meeting_events WHERE meeting_id = $1
+			RETURNING seq
+		`, meetingID, eventType, participant, string(payload)).Scan(&seq)
+		if isUniqueViolation(err) && attempt < appendEventAttempts {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to append meeting event: %w", err)
+		}
+		return seq, nil
+	}
+}
+
+// ListMeetingEvents returns a meeting's events after sinceSeq in sequence
+// order, optionally only those of the given types
+func ListMeetingEvents(meetingID string, sinceSeq int64, types []string, limit int) ([]MeetingEvent, error) {
+	if types == nil {
+		types = []string{}
+	}
+	rows, err := DB.Query(`
+		SELECT id, meeting_id, seq, type, participant_id, payload, created_at
+		FROM meeting_events
+		WHERE meeting_id = $1 AND seq > $2 AND (CARDINALITY($3::text[]) = 0 OR type = ANY($3))
+		ORDER BY seq
+		LIMIT $4
+	`, meetingID, sinceSeq, pq.Array(types), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meeting events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []MeetingEvent{}
+	for rows.Next() {
+		var event MeetingEvent
+		var participantID sql.NullInt64
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.MeetingID, &event.Seq, &event.Type, &participantID, &payload, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan meeting event: %w", err)
+		}
+		event.ParticipantID = int(participantID.Int64)
+		event.Payload = payload
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// GetMeetingEventCounts returns how many events of each type a meeting logged
+func GetMeetingEventCounts(meetingID string) (map[string]int, error) {
+	rows, err := DB.Query(`
+		SELECT type, COUNT(*) FROM meeting_events WHERE meeting_id = $1 GROUP BY type
+	`, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count meeting events: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var eventType string
+		var count int
+		if err := rows.Scan(&eventType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan meeting event count: %w", err)
+		}
+		counts[eventType] = count
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,187 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// MeetingInvite is a join link token for a meeting. The token itself is only
+// known when the invite is created; the database keeps its hash.
+type MeetingInvite struct {
+	ID        int        `json:"id"`
+	MeetingID string     `json:"meetingId"`
+	Role      string     `json:"role"`              // editor or viewer, granted on join
+	MaxUses   *int       `json:"maxUses,omitempty"` // nil for unlimited
+	Uses      int        `json:"uses"`
+	CreatedBy *int       `json:"createdBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	Token     string     `json:"token,omitempty"` // only set on creation
+}
+
+// hashInviteToken returns the stored form of an invite token
+func hashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateMeetingInvite creates an invite granting role until expiresAt, usable
+// maxUses times (0 for unlimited). The returned invite carries its token.
+func CreateMeetingInvite(meetingID, role string, expiresAt time.Time, maxUses int, createdBy int) (*MeetingInvite, error) {
+	if role != RoleEditor && role != RoleViewer {
+		return nil, fmt.Errorf("invalid role: invites can only grant 'editor' or 'viewer' roles")
+	}
+	token, err := generateHostToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invite token: %w", err)
+	}
+
+	invite := &MeetingInvite{
+		MeetingID: meetingID,
+		Role:      role,
+		CreatedBy: &createdBy,
+		ExpiresAt: expiresAt,
+		Token:     token,
+	}
+	var limit sql.NullInt64
+	if maxUses > 0 {
+		limit = sql.NullInt64{Int64: int64(maxUses), Valid: true}
+		invite.MaxUses = &maxUses
+	}
+	err = DB.QueryRow(`
+		INSERT INTO meeting_invites (meeting_id, token_hash, role, max_uses, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, meetingID, hashInviteToken(token), role, limit, createdBy, expiresAt).Scan(&invite.ID, &invite.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create meeting invite: %w", err)
+	}
+	return invite, nil
+}
+
+// ListMeetingInvites returns a meeting's invites, newest first, without
+// their tokens
+func ListMeetingInvites(meetingID string) ([]MeetingInvite, error) {
+	rows, err := DB.Query(`
+		SELECT id, meeting_id, role, max_uses, uses, created_by, created_at, expires_at, revoked_at
+		FROM meeting_invites
+		WHERE meeting_id = $1
+		ORDER BY created_at DESC
+	`, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meeting invites: %w", err)
+	}
+	defer rows.Close()
+
+	invites := []MeetingInvite{}
+	for rows.Next() {
+		var invite MeetingInvite
+		var maxUses, createdBy sql.NullInt64
+		var revokedAt sql.NullTime
+		if err := rows.Scan(&invite.ID, &invite.MeetingID, &invite.Role, &maxUses, &invite.Uses,
+			&createdBy, &invite.CreatedAt, &invite.ExpiresAt, &revokedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan meeting invite: %w", err)
+		}
+		invite.MaxUses = nullIntPtr(maxUses)
+		invite.CreatedBy = nullIntPtr(createdBy)
+		if revokedAt.Valid {
+			invite.RevokedAt = &revokedAt.Time
+		}
+		invites = append(invites, invite)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read meeting invites: %w", err)
+	}
+	return invites, nil
+}
+
+// RevokeMeetingInvite stops an invite from being used. It reports false when
+// the meeting has no such invite or it was already revoked.
+func RevokeMeetingInvite(meetingID string, inviteID int) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE meeting_invites SET revoked_at = NOW()
+		WHERE id = $1 AND meeting_id = $2 AND revoked_at IS NULL
+	`, inviteID, meetingID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke meeting invite: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke meeting invite: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// RedeemMeetingInvite uses up one use of an invite to a meeting. It returns
+// nil when the token is unknown, revoked, expired, used up or for another
+// meeting.
+func RedeemMeetingInvite(meetingID, token string) (*MeetingInvite, error) {
+	var invite MeetingInvite
+	var maxUses, createdBy sql.NullInt64
+	err := DB.QueryRow(`
+		UPDATE meeting_invites SET uses = uses + 1
+		WHERE token_hash = $1 AND meeting_id = $2 AND revoked_at IS NULL
+			AND expires_at > NOW() AND (max_uses IS NULL OR uses < max_uses)
+		RETURNING id, meeting_id, role, max_uses, uses, created_by, created_at, expires_at
+	`, hashInviteToken(token), meetingID).Scan(&invite.ID, &invite.MeetingID, &invite.Role, &maxUses,
+		&invite.Uses, &createdBy, &invite.CreatedAt, &invite.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem meeting invite: %w", err)
+	}
+	invite.MaxUses = nullIntPtr(maxUses)
+	invite.CreatedBy = nullIntPtr(createdBy)
+	return &invite, nil
+}
+
+// GrantInviteAccess records in the ACL that a user joined through an invite,
+// granting the invite's role. Users who already have that role or a higher
+// one keep theirs.
+func GrantInviteAccess(meetingID string, userID int, invite *MeetingInvite) error {
+	current, err := GetUserMeetingRole(userID, meetingID)
+	if err != nil {
+		return err
+	}
+	if roleLevel(current) >= roleLevel(invite.Role) {
+		return nil
+	}
+
+	_, err = DB.Exec(`
+		INSERT INTO meeting_access_control (meeting_id, user_id, role, granted_by, invite_id, granted_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		ON CONFLICT (meeting_id, user_id)
+		DO UPDATE SET role = EXCLUDED.role, granted_by = EXCLUDED.granted_by,
+			invite_id = EXCLUDED.invite_id, updated_at = NOW()
+	`, meetingID, userID, invite.Role, invite.CreatedBy, invite.ID)
+	if err != nil {
+		return fmt.Errorf("failed to grant invite access: %w", err)
+	}
+	return nil
+}
+
+// IsMeetingInviteOnly reports whether joining a meeting needs an invite
+func IsMeetingInviteOnly(meetingID string) (bool, error) {
+	var inviteOnly bool
+	err := DB.QueryRow(`SELECT invite_only FROM meetings WHERE id = $1`, meetingID).Scan(&inviteOnly)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check invite-only: %w", err)
+	}
+	return inviteOnly, nil
+}
+
+// SetMeetingInviteOnly sets whether joining a meeting needs an invite
+func SetMeetingInviteOnly(meetingID string, inviteOnly bool) error {
+	if _, err := DB.Exec(`UPDATE meetings SET invite_only = $2 WHERE id = $1`, meetingID, inviteOnly); err != nil {
+		return fmt.Errorf("failed to set invite-only: %w", err)
+	}
+	return nil
+}
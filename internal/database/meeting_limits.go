@@ -0,0 +1,94 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// OrgMeetingSettings holds an organization's meeting limits
+type OrgMeetingSettings struct {
+	OrgID              string    `json:"orgId"`
+	MaxDurationMinutes int       `json:"maxDurationMinutes"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+// GetMeetingMaxDuration returns a meeting's duration limit in minutes, or 0
+// when it has none
+func GetMeetingMaxDuration(meetingID string) (int, error) {
+	var minutes sql.NullInt64
+	err := DB.QueryRow(`SELECT max_duration_minutes FROM meetings WHERE id = $1`, meetingID).Scan(&minutes)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get meeting duration limit: %w", err)
+	}
+	return int(minutes.Int64), nil
+}
+
+// SetMeetingMaxDuration stores a meeting's duration limit; 0 removes it
+func SetMeetingMaxDuration(meetingID string, minutes int) error {
+	var value interface{}
+	if minutes > 0 {
+		value = minutes
+	}
+	if _, err := DB.Exec(`UPDATE meetings SET max_duration_minutes = $2 WHERE id = $1`, meetingID, value); err != nil {
+		return fmt.Errorf("failed to set meeting duration limit: %w", err)
+	}
+	return nil
+}
+
+// GetOrgMeetingMaxDuration returns an org's meeting duration limit in
+// minutes, or 0 when the org has none
+func GetOrgMeetingMaxDuration(orgID string) (int, error) {
+	var minutes int
+	err := DB.QueryRow(`SELECT max_duration_minutes FROM org_meeting_settings WHERE org_id = $1`, orgID).Scan(&minutes)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get org meeting settings: %w", err)
+	}
+	return minutes, nil
+}
+
+// ListOrgMeetingSettings returns every org with meeting limits
+func ListOrgMeetingSettings() ([]OrgMeetingSettings, error) {
+	rows, err := DB.Query(`SELECT org_id, max_duration_minutes, updated_at FROM org_meeting_settings ORDER BY org_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list org meeting settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := []OrgMeetingSettings{}
+	for rows.Next() {
+		var s OrgMeetingSettings
+		if err := rows.Scan(&s.OrgID, &s.MaxDurationMinutes, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan org meeting settings: %w", err)
+		}
+		settings = append(settings, s)
+	}
+	return settings, rows.Err()
+}
+
+// SetOrgMeetingMaxDuration creates or updates an org's duration limit
+func SetOrgMeetingMaxDuration(orgID string, minutes int) error {
+	_, err := DB.Exec(`
+		INSERT INTO org_meeting_settings (org_id, max_duration_minutes)
+		VALUES ($1, $2)
+		ON CONFLICT (org_id) DO UPDATE SET max_duration_minutes = EXCLUDED.max_duration_minutes, updated_at = NOW()
+	`, orgID, minutes)
+	if err != nil {
+		return fmt.Errorf("failed to set org meeting settings: %w", err)
+	}
+	return nil
+}
+
+// DeleteOrgMeetingSettings removes an org's limits so the server default applies
+func DeleteOrgMeetingSettings(orgID string) error {
+	if _, err := DB.Exec(`DELETE FROM org_meeting_settings WHERE org_id = $1`, orgID); err != nil {
+		return fmt.Errorf("failed to delete org meeting settings: %w", err)
+	}
+	return nil
+}
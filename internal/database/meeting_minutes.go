@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"realtime-caption-translator/internal/langcode"
 )
 
 // MeetingMinutesContent captures the structured minutes fields.
@@ -28,6 +30,7 @@ type MeetingMinutes struct {
 
 // SaveMeetingMinutes upserts meeting minutes for a meeting/language.
 func SaveMeetingMinutes(meetingID, language string, content MeetingMinutesContent) error {
+	language = langcode.Normalize(language)
 	if language == "" {
 		language = "en"
 	}
@@ -54,6 +57,7 @@ func SaveMeetingMinutes(meetingID, language string, content MeetingMinutesConten
 
 // GetMeetingMinutes returns meeting minutes for a meeting/language.
 func GetMeetingMinutes(meetingID, language string) (*MeetingMinutes, error) {
+	language = langcode.Normalize(language)
 	if language == "" {
 		language = "en"
 	}
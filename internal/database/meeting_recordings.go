@@ -0,0 +1,151 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Meeting recording statuses
+const (
+	RecordingActive     = "recording"
+	RecordingProcessing = "processing"
+	RecordingReady      = "ready"
+	RecordingFailed     = "failed"
+)
+
+// MeetingRecording is a recorded stretch of a meeting; its audio and
+// transcript are objects in BucketName once it is ready
+type MeetingRecording struct {
+	ID              int        `json:"id"`
+	MeetingID       string     `json:"meetingId"`
+	StartedBy       *int       `json:"startedBy,omitempty"`
+	Status          string     `json:"status"`
+	StartedAt       time.Time  `json:"startedAt"`
+	EndedAt         *time.Time `json:"endedAt,omitempty"`
+	DurationSeconds float64    `json:"durationSeconds"`
+	EntryCount      int        `json:"entryCount"`
+	BucketName      string     `json:"-"`
+	AudioKey        string     `json:"-"`
+	TranscriptKey   string     `json:"-"`
+	Error           string     `json:"error,omitempty"`
+}
+
+const meetingRecordingColumns = `id, meeting_id, started_by, status, started_at, ended_at, duration_seconds,
+	entry_count, bucket_name, audio_key, transcript_key, error`
+
+func scanMeetingRecording(row interface{ Scan(...interface{}) error }) (*MeetingRecording, error) {
+	var recording MeetingRecording
+	var startedBy sql.NullInt64
+	var endedAt sql.NullTime
+	var bucket, audioKey, transcriptKey, errMsg sql.NullString
+	if err := row.Scan(&recording.ID, &recording.MeetingID, &startedBy, &recording.Status, &recording.StartedAt,
+		&endedAt, &recording.DurationSeconds, &recording.EntryCount, &bucket, &audioKey, &transcriptKey, &errMsg); err != nil {
+		return nil, err
+	}
+	if startedBy.Valid {
+		id := int(startedBy.Int64)
+		recording.StartedBy = &id
+	}
+	if endedAt.Valid {
+		recording.EndedAt = &endedAt.Time
+	}
+	recording.BucketName = bucket.String
+	recording.AudioKey = audioKey.String
+	recording.TranscriptKey = transcriptKey.String
+	recording.Error = errMsg.String
+	return &recording, nil
+}
+
+// CreateMeetingRecording starts a recording row for a meeting
+func CreateMeetingRecording(meetingID string, startedBy *int) (*MeetingRecording, error) {
+	var user interface{}
+	if startedBy != nil {
+		user = *startedBy
+	}
+	recording, err := scanMeetingRecording(DB.QueryRow(`
+		INSERT INTO meeting_recordings (meeting_id, started_by)
+		VALUES ($1, $2)
+		RETURNING `+meetingRecordingColumns, meetingID, user))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create meeting recording: %w", err)
+	}
+	return recording, nil
+}
+
+// EndMeetingRecording marks a recording as stopped and being stored
+func EndMeetingRecording(id int, duration float64, entryCount int) error {
+	_, err := DB.Exec(`
+		UPDATE meeting_recordings
+		SET status = 'processing', ended_at = NOW(), duration_seconds = $2, entry_count = $3
+		WHERE id = $1
+	`, id, duration, entryCount)
+	if err != nil {
+		return fmt.Errorf("failed to end meeting recording: %w", err)
+	}
+	return nil
+}
+
+// CompleteMeetingRecording records where a recording's objects were stored
+func CompleteMeetingRecording(id int, bucket, audioKey, transcriptKey string) error {
+	_, err := DB.Exec(`
+		UPDATE meeting_recordings
+		SET status = 'ready', bucket_name = $2, audio_key = $3, transcript_key = $4, error = NULL
+		WHERE id = $1
+	`, id, bucket, audioKey, transcriptKey)
+	if err != nil {
+		return fmt.Errorf("failed to complete meeting recording: %w", err)
+	}
+	return nil
+}
+
+// FailMeetingRecording marks a recording that could not be stored
+func FailMeetingRecording(id int, errMsg string) error {
+	_, err := DB.Exec(`
+		UPDATE meeting_recordings
+		SET status = 'failed', ended_at = COALESCE(ended_at, NOW()), error = $2
+		WHERE id = $1
+	`, id, errMsg)
+	if err != nil {
+		return fmt.Errorf("failed to mark meeting recording failed: %w", err)
+	}
+	return nil
+}
+
+// FailInterruptedMeetingRecordings fails recordings left active or
+// processing by a server restart, whose audio was lost with the process
+func FailInterruptedMeetingRecordings() (int64, error) {
+	result, err := DB.Exec(`
+		UPDATE meeting_recordings
+		SET status = 'failed', ended_at = COALESCE(ended_at, NOW()), error = 'interrupted by server restart'
+		WHERE status IN ('recording', 'processing')
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail interrupted meeting recordings: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ListMeetingRecordings returns a meeting's recordings, oldest first
+func ListMeetingRecordings(meetingID string) ([]MeetingRecording, error) {
+	rows, err := DB.Query(`
+		SELECT `+meetingRecordingColumns+`
+		FROM meeting_recordings
+		WHERE meeting_id = $1
+		ORDER BY started_at, id
+	`, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meeting recordings: %w", err)
+	}
+	defer rows.Close()
+
+	recordings := []MeetingRecording{}
+	for rows.Next() {
+		recording, err := scanMeetingRecording(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan meeting recording: %w", err)
+		}
+		recordings = append(recordings, *recording)
+	}
+	return recordings, rows.Err()
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // MeetingHistoryItem represents a meeting in the user's history list
@@ -21,6 +23,7 @@ type MeetingHistoryItem struct {
 	AvailableLanguages []string   `json:"availableLanguages"`
 	DurationSeconds    *int       `json:"durationSeconds,omitempty"`
 	MinutesSummary     *string    `json:"minutesSummary,omitempty"`
+	Tags               []ItemTag  `json:"tags"` // the user's own tags and folder
 }
 
 // MeetingDetail represents detailed meeting information
@@ -57,8 +60,9 @@ type TranscriptSnapshotInfo struct {
 	CreatedAt time.Time `json:"createdAt"`
 }
 
-// GetUserMeetings returns meetings where user is creator or participant
-func GetUserMeetings(userID int, limit, offset int, status string) ([]MeetingHistoryItem, int, error) {
+// GetUserMeetings returns meetings where user is creator or participant,
+// limited to meetings carrying every tag in tagIDs when any are given
+func GetUserMeetings(userID int, limit, offset int, status string, tagIDs []int64) ([]MeetingHistoryItem, int, error) {
 	// Build status filter
 	statusFilter := ""
 	switch status {
@@ -70,6 +74,21 @@ func GetUserMeetings(userID int, limit, offset int, status string) ([]MeetingHis
 		// "all" - no filter
 	}
 
+	// Build tag filter; the tag IDs follow the query's other arguments
+	tagFilter := func(param int) string {
+		if len(tagIDs) == 0 {
+			return ""
+		}
+		return fmt.Sprintf(`AND (SELECT COUNT(DISTINCT mt.tag_id) FROM meeting_tags mt
+			WHERE mt.meeting_id = m.id AND mt.tag_id = ANY($%d)) = %d`, param, len(tagIDs))
+	}
+	args := []interface{}{userID, limit, offset}
+	countArgs := []interface{}{userID}
+	if len(tagIDs) > 0 {
+		args = append(args, pq.Array(tagIDs))
+		countArgs = append(countArgs, pq.Array(tagIDs))
+	}
+
 	// Main query to get meetings with ACL role information
 	query := fmt.Sprintf(`
 		SELECT DISTINCT ON (m.id)
@@ -96,11 +115,12 @@ func GetUserMeetings(userID int, limit, offset int, status string) ([]MeetingHis
 			mm.summary as minutes_summary
 		FROM meetings m
 		LEFT JOIN meeting_participants mp ON mp.meeting_id = m.id AND mp.user_id = $1
-		LEFT JOIN meeting_access_control mac ON mac.meeting_id = m.id AND mac.user_id = $1
+		LEFT JOIN meeting_access_control mac ON mac.meeting_id = m.id AND mac.user_id = $1 AND mac.removed_at IS NULL
 		LEFT JOIN meeting_minutes mm ON mm.meeting_id = m.id AND mm.language = 'en'
-		WHERE (m.created_by = $1 OR mp.user_id = $1 OR mac.user_id = $1) %s
+		WHERE (m.created_by = $1 OR mp.user_id = $1 OR mac.user_id = $1)
+			AND NOT EXISTS (SELECT 1 FROM meeting_removals r WHERE r.meeting_id = m.id AND r.user_id = $1) %s %s
 		ORDER BY m.id, m.created_at DESC
-	`, statusFilter)
+	`, statusFilter, tagFilter(4))
 
 	// Wrap with ordering and pagination
 	paginatedQuery := fmt.Sprintf(`
@@ -109,7 +129,7 @@ func GetUserMeetings(userID int, limit, offset int, status string) ([]MeetingHis
 		LIMIT $2 OFFSET $3
 	`, query)
 
-	rows, err := DB.Query(paginatedQuery, userID, limit, offset)
+	rows, err := DB.Query(paginatedQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query user meetings: %w", err)
 	}
@@ -152,8 +172,9 @@ func GetUserMeetings(userID int, limit, offset int, status string) ([]MeetingHis
 			item.MinutesSummary = &minutesSummary.String
 		}
 
-		// Initialize empty languages array
+		// Initialize empty languages and tags arrays
 		item.AvailableLanguages = []string{}
+		item.Tags = []ItemTag{}
 
 		meetings = append(meetings, item)
 		meetingIDs = append(meetingIDs, item.ID)
@@ -179,17 +200,30 @@ func GetUserMeetings(userID int, limit, offset int, status string) ([]MeetingHis
 		}
 	}
 
+	if len(meetingIDs) > 0 {
+		tagsMap, err := getMeetingTagsBulk(userID, meetingIDs)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to load meeting tags: %w", err)
+		}
+		for i := range meetings {
+			if tags, ok := tagsMap[meetings[i].ID]; ok {
+				meetings[i].Tags = tags
+			}
+		}
+	}
+
 	// Get total count
 	countQuery := fmt.Sprintf(`
 		SELECT COUNT(DISTINCT m.id)
 		FROM meetings m
 		LEFT JOIN meeting_participants mp ON mp.meeting_id = m.id AND mp.user_id = $1
-		LEFT JOIN meeting_access_control mac ON mac.meeting_id = m.id AND mac.user_id = $1
-		WHERE (m.created_by = $1 OR mp.user_id = $1 OR mac.user_id = $1) %s
-	`, statusFilter)
+		LEFT JOIN meeting_access_control mac ON mac.meeting_id = m.id AND mac.user_id = $1 AND mac.removed_at IS NULL
+		WHERE (m.created_by = $1 OR mp.user_id = $1 OR mac.user_id = $1)
+			AND NOT EXISTS (SELECT 1 FROM meeting_removals r WHERE r.meeting_id = m.id AND r.user_id = $1) %s %s
+	`, statusFilter, tagFilter(2))
 
 	var total int
-	err = DB.QueryRow(countQuery, userID).Scan(&total)
+	err = DB.QueryRow(countQuery, countArgs...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count user meetings: %w", err)
 	}
@@ -309,10 +343,10 @@ func GetUserMeetingDetail(userID int, meetingID string) (*MeetingDetail, error)
 
 	// Set user's role and permissions
 	detail.UserRole = userRole
-	detail.CanManageAccess = (userRole == RoleOwner)
+	detail.CanManageAccess = IsModeratorRole(userRole)
 
-	// If user is owner, include access control list
-	if userRole == RoleOwner {
+	// If user is owner or co-host, include access control list
+	if IsModeratorRole(userRole) {
 		acl, err := ListMeetingAccessControl(meetingID)
 		if err != nil {
 			// Don't fail, just log
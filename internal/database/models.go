@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"realtime-caption-translator/internal/langcode"
 )
 
 // User represents a registered user
@@ -26,7 +28,7 @@ type User struct {
 type Meeting struct {
 	ID        string     `json:"id"`
 	RoomCode  string     `json:"roomCode"`
-	Mode      string     `json:"mode"` // "individual" or "shared"
+	Mode      string     `json:"mode"` // "individual", "shared" or "interpreted"
 	CreatedBy *int       `json:"createdBy,omitempty"`
 	CreatedAt time.Time  `json:"createdAt"`
 	EndedAt   *time.Time `json:"endedAt,omitempty"`
@@ -70,12 +72,14 @@ type MeetingParticipant struct {
 	JoinedAt        time.Time  `json:"joinedAt"`
 	LeftAt          *time.Time `json:"leftAt,omitempty"`
 	IsActive        bool       `json:"isActive"`
+	JoinToken       string     `json:"-"` // only set by AddParticipant
 }
 
 // --- User CRUD operations ---
 
 // CreateUser creates a new user
 func CreateUser(username, displayName, preferredLang string) (*User, error) {
+	preferredLang = langcode.Normalize(preferredLang)
 	query := `
 		INSERT INTO users (username, display_name, preferred_language)
 		VALUES ($1, $2, $3)
@@ -147,6 +151,44 @@ func GetUserByUsername(username string) (*User, error) {
 	return &user, nil
 }
 
+// GetUserByID retrieves a user by ID, or nil if there is none
+func GetUserByID(userID int) (*User, error) {
+	query := `
+		SELECT id, username, display_name, preferred_language, email, email_verified, last_login, created_at
+		FROM users
+		WHERE id = $1
+	`
+
+	var user User
+	var email sql.NullString
+	var lastLogin sql.NullTime
+	err := DB.QueryRow(query, userID).Scan(
+		&user.ID,
+		&user.Username,
+		&user.DisplayName,
+		&user.PreferredLanguage,
+		&email,
+		&user.EmailVerified,
+		&lastLogin,
+		&user.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if email.Valid {
+		user.Email = email.String
+	}
+	if lastLogin.Valid {
+		user.LastLogin = &lastLogin.Time
+	}
+
+	return &user, nil
+}
+
 // --- Meeting CRUD operations ---
 
 // generateRoomCode generates a random 6-character room code (e.g., "ABC-123")
@@ -166,6 +208,11 @@ func generateRoomCode() (string, error) {
 	return code, nil
 }
 
+// NewGuestKey returns a random key identifying a guest's browser
+func NewGuestKey() (string, error) {
+	return generateHostToken()
+}
+
 func generateHostToken() (string, error) {
 	bytes := make([]byte, 24)
 	if _, err := rand.Read(bytes); err != nil {
@@ -307,16 +354,22 @@ func EndMeeting(meetingID string) error {
 
 // --- Participant CRUD operations ---
 
-// AddParticipant adds a participant to a meeting
-func AddParticipant(meetingID string, userID *int, participantName, targetLang string) (*MeetingParticipant, error) {
+// AddParticipant adds a participant to a meeting. guestKey identifies the
+// browser of a guest so a removal can keep them out; it is empty for users.
+func AddParticipant(meetingID string, userID *int, guestKey, participantName, targetLang string) (*MeetingParticipant, error) {
+	targetLang = langcode.Normalize(targetLang)
+	joinToken, err := generateHostToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate join token: %w", err)
+	}
 	query := `
-		INSERT INTO meeting_participants (meeting_id, user_id, participant_name, target_language, is_active)
-		VALUES ($1, $2, $3, $4, true)
+		INSERT INTO meeting_participants (meeting_id, user_id, participant_name, target_language, is_active, join_token, guest_key)
+		VALUES ($1, $2, $3, $4, true, $5, NULLIF($6, ''))
 		RETURNING id, meeting_id, user_id, participant_name, target_language, joined_at, left_at, is_active
 	`
 
-	var participant MeetingParticipant
-	err := DB.QueryRow(query, meetingID, userID, participantName, targetLang).Scan(
+	participant := MeetingParticipant{JoinToken: joinToken}
+	err = DB.QueryRow(query, meetingID, userID, participantName, targetLang, joinToken, guestKey).Scan(
 		&participant.ID,
 		&participant.MeetingID,
 		&participant.UserID,
@@ -407,6 +460,39 @@ func GetMeetingParticipants(meetingID string) ([]MeetingParticipant, error) {
 	return participants, rows.Err()
 }
 
+// AuthenticateParticipant returns the participant of a meeting whose join
+// token matches, or nil when the ID, meeting or token do not match
+func AuthenticateParticipant(meetingID string, participantID int, joinToken string) (*MeetingParticipant, error) {
+	if joinToken == "" {
+		return nil, nil
+	}
+	query := `
+		SELECT id, meeting_id, user_id, participant_name, target_language, joined_at, left_at, is_active
+		FROM meeting_participants
+		WHERE id = $1 AND meeting_id = $2 AND join_token = $3
+	`
+
+	var participant MeetingParticipant
+	err := DB.QueryRow(query, participantID, meetingID, joinToken).Scan(
+		&participant.ID,
+		&participant.MeetingID,
+		&participant.UserID,
+		&participant.ParticipantName,
+		&participant.TargetLanguage,
+		&participant.JoinedAt,
+		&participant.LeftAt,
+		&participant.IsActive,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate participant: %w", err)
+	}
+
+	return &participant, nil
+}
+
 // GetParticipantByID retrieves a participant by ID
 func GetParticipantByID(participantID int) (*MeetingParticipant, error) {
 	query := `
@@ -438,6 +524,7 @@ func GetParticipantByID(participantID int) (*MeetingParticipant, error) {
 
 // UpdateParticipantLanguage updates a participant's target language
 func UpdateParticipantLanguage(participantID int, targetLang string) error {
+	targetLang = langcode.Normalize(targetLang)
 	query := `
 		UPDATE meeting_participants
 		SET target_language = $1
@@ -578,6 +665,7 @@ func GetSpeakerName(meetingID, speakerID string) (string, error) {
 
 // SaveMeetingTranscriptSnapshot stores the final transcript for a meeting/language
 func SaveMeetingTranscriptSnapshot(meetingID, language, transcript string) error {
+	language = langcode.Normalize(language)
 	if meetingID == "" || language == "" || transcript == "" {
 		return fmt.Errorf("meeting transcript snapshot requires meetingID, language, and transcript")
 	}
@@ -691,6 +779,7 @@ func DeleteExpiredSpeakerProfiles(cutoff time.Time) (int64, error) {
 
 // GetMeetingTranscriptSnapshot retrieves a transcript snapshot for a meeting/language.
 func GetMeetingTranscriptSnapshot(meetingID, language string) (*TranscriptSnapshot, error) {
+	language = langcode.Normalize(language)
 	query := `
 		SELECT meeting_id, language, transcript, created_at
 		FROM meeting_transcript_snapshots
@@ -0,0 +1,190 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Notification is an in-app notification for a user
+type Notification struct {
+	ID        int             `json:"id"`
+	UserID    int             `json:"userId"`
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body,omitempty"`
+	Link      string          `json:"link,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	ReadAt    *time.Time      `json:"readAt,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// CreateNotification stores a notification and fills in its ID and timestamp
+func CreateNotification(n *Notification) error {
+	var data interface{}
+	if len(n.Data) > 0 {
+		data = []byte(n.Data)
+	}
+
+	err := DB.QueryRow(`
+		INSERT INTO notifications (user_id, type, title, body, link, data)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, n.UserID, n.Type, n.Title, nullString(n.Body), nullString(n.Link), data).Scan(&n.ID, &n.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// ListNotifications returns a user's most recent notifications, newest first
+func ListNotifications(userID int, unreadOnly bool, limit, offset int) ([]Notification, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := DB.Query(`
+		SELECT id, user_id, type, title, body, link, data, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		var body, link sql.NullString
+		var data []byte
+		var readAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &body, &link, &data, &readAt, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		n.Body = body.String
+		n.Link = link.String
+		if len(data) > 0 {
+			n.Data = json.RawMessage(data)
+		}
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// CountUnreadNotifications returns how many notifications a user has not read
+func CountUnreadNotifications(userID int) (int, error) {
+	var count int
+	err := DB.QueryRow(`
+		SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL
+	`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkNotificationRead marks one of the user's notifications as read.
+// Returns false when the notification does not belong to the user.
+func MarkNotificationRead(userID, notificationID int) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE notifications SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+	`, notificationID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// MarkAllNotificationsRead marks every unread notification of the user as read
+func MarkAllNotificationsRead(userID int) (int64, error) {
+	result, err := DB.Exec(`
+		UPDATE notifications SET read_at = NOW()
+		WHERE user_id = $1 AND read_at IS NULL
+	`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// DeleteNotification removes one of the user's notifications
+func DeleteNotification(userID, notificationID int) (bool, error) {
+	result, err := DB.Exec(`DELETE FROM notifications WHERE id = $1 AND user_id = $2`, notificationID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete notification: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete notification: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// GetNotificationPreferences returns the user's stored per-type settings.
+// Types without a row are enabled.
+func GetNotificationPreferences(userID int) (map[string]bool, error) {
+	rows, err := DB.Query(`
+		SELECT type, enabled FROM notification_preferences WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	prefs := make(map[string]bool)
+	for rows.Next() {
+		var notificationType string
+		var enabled bool
+		if err := rows.Scan(&notificationType, &enabled); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		prefs[notificationType] = enabled
+	}
+
+	return prefs, rows.Err()
+}
+
+// SetNotificationPreference enables or disables a notification type for a user
+func SetNotificationPreference(userID int, notificationType string, enabled bool) error {
+	_, err := DB.Exec(`
+		INSERT INTO notification_preferences (user_id, type, enabled)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, type)
+		DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = NOW()
+	`, userID, notificationType, enabled)
+	if err != nil {
+		return fmt.Errorf("failed to set notification preference: %w", err)
+	}
+	return nil
+}
+
+// NotificationTypeEnabled reports whether the user wants notifications of a type
+func NotificationTypeEnabled(userID int, notificationType string) (bool, error) {
+	var enabled bool
+	err := DB.QueryRow(`
+		SELECT enabled FROM notification_preferences WHERE user_id = $1 AND type = $2
+	`, userID, notificationType).Scan(&enabled)
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check notification preference: %w", err)
+	}
+	return enabled, nil
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// UserQuota overrides the default quotas for a user. A nil field keeps the
+// default; 0 means unlimited.
+type UserQuota struct {
+	UserID             int       `json:"userId"`
+	AudioMinutesPerDay *int      `json:"audioMinutesPerDay"`
+	ConcurrentJobs     *int      `json:"concurrentJobs"`
+	StorageBytes       *int64    `json:"storageBytes"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+// UserQuotaUsage is what counts against a user's quotas
+type UserQuotaUsage struct {
+	AudioSecondsToday float64 `json:"audioSecondsToday"` // UTC day
+	ActiveJobs        int     `json:"activeJobs"`        // queued or running
+	StorageBytes      int64   `json:"storageBytes"`      // files tracked in user_files
+}
+
+// scanUserQuota reads the columns of a user_quotas row
+func scanUserQuota(row interface{ Scan(...interface{}) error }) (*UserQuota, error) {
+	var quota UserQuota
+	var minutes, jobs, storage sql.NullInt64
+	if err := row.Scan(&quota.UserID, &minutes, &jobs, &storage, &quota.UpdatedAt); err != nil {
+		return nil, err
+	}
+	quota.AudioMinutesPerDay = nullIntPtr(minutes)
+	quota.ConcurrentJobs = nullIntPtr(jobs)
+	if storage.Valid {
+		quota.StorageBytes = &storage.Int64
+	}
+	return &quota, nil
+}
+
+// GetUserQuota returns a user's quota overrides, or nil when they have none
+func GetUserQuota(userID int) (*UserQuota, error) {
+	quota, err := scanUserQuota(DB.QueryRow(`
+		SELECT user_id, audio_minutes_per_day, concurrent_jobs, storage_bytes, updated_at
+		FROM user_quotas WHERE user_id = $1
+	`, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user quota: %w", err)
+	}
+	return quota, nil
+}
+
+// ListUserQuotas returns every user with quota overrides
+func ListUserQuotas() ([]UserQuota, error) {
+	rows, err := DB.Query(`
+		SELECT user_id, audio_minutes_per_day, concurrent_jobs, storage_bytes, updated_at
+		FROM user_quotas ORDER BY user_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user quotas: %w", err)
+	}
+	defer rows.Close()
+
+	quotas := []UserQuota{}
+	for rows.Next() {
+		quota, err := scanUserQuota(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user quota: %w", err)
+		}
+		quotas = append(quotas, *quota)
+	}
+	return quotas, rows.Err()
+}
+
+// SetUserQuota creates or replaces a user's quota overrides
+func SetUserQuota(quota *UserQuota) error {
+	_, err := DB.Exec(`
+		INSERT INTO user_quotas (user_id, audio_minutes_per_day, concurrent_jobs, storage_bytes)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET
+			audio_minutes_per_day = EXCLUDED.audio_minutes_per_day,
+			concurrent_jobs = EXCLUDED.concurrent_jobs,
+			storage_bytes = EXCLUDED.storage_bytes,
+			updated_at = NOW()
+	`, quota.UserID, quota.AudioMinutesPerDay, quota.ConcurrentJobs, quota.StorageBytes)
+	if err != nil {
+		return fmt.Errorf("failed to set user quota: %w", err)
+	}
+	return nil
+}
+
+// DeleteUserQuota removes a user's overrides so the defaults apply
+func DeleteUserQuota(userID int) error {
+	if _, err := DB.Exec(`DELETE FROM user_quotas WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete user quota: %w", err)
+	}
+	return nil
+}
+
+// GetUserQuotaUsage returns what currently counts against a user's quotas
+func GetUserQuotaUsage(userID int) (*UserQuotaUsage, error) {
+	var usage UserQuotaUsage
+	err := DB.QueryRow(`
+		SELECT
+			COALESCE((SELECT audio_seconds FROM user_audio_usage
+			          WHERE user_id = $1 AND usage_date = (NOW() AT TIME ZONE 'UTC')::date), 0),
+			(SELECT COUNT(*) FROM processing_jobs WHERE user_id = $1 AND status IN ('queued', 'running')),
+			COALESCE((SELECT SUM(file_size_bytes) FROM user_files WHERE user_id = $1), 0)
+	`, userID).Scan(&usage.AudioSecondsToday, &usage.ActiveJobs, &usage.StorageBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user quota usage: %w", err)
+	}
+	return &usage, nil
+}
+
+// AddUserAudioUsage counts seconds of processed audio against the user's
+// current UTC day
+func AddUserAudioUsage(userID int, seconds float64) error {
+	_, err := DB.Exec(`
+		INSERT INTO user_audio_usage (user_id, usage_date, audio_seconds)
+		VALUES ($1, (NOW() AT TIME ZONE 'UTC')::date, $2)
+		ON CONFLICT (user_id, usage_date) DO UPDATE SET
+			audio_seconds = user_audio_usage.audio_seconds + EXCLUDED.audio_seconds,
+			updated_at = NOW()
+	`, userID, seconds)
+	if err != nil {
+		return fmt.Errorf("failed to add user audio usage: %w", err)
+	}
+	return nil
+}
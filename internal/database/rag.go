@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/lib/pq"
+
+	"realtime-caption-translator/internal/langcode"
 )
 
 // MeetingChunk represents a chunk of meeting transcript with embedding
@@ -16,17 +18,59 @@ type MeetingChunk struct {
 	Language           string     `json:"language"`
 	ChunkIndex         int        `json:"chunkIndex"`
 	ChunkText          string     `json:"chunkText"`
+	ContentHash        string     `json:"contentHash,omitempty"`
+	ChunkVersion       string     `json:"chunkVersion,omitempty"`
 	SpeakerID          *string    `json:"speakerId,omitempty"`
-	SpeakerName        *string    `json:"speakerName,omitempty"`
+	SpeakerName        *string    `json:"speakerName,omitempty"` // set when the chunk has one speaker
+	SpeakerNames       []string   `json:"speakerNames,omitempty"`
 	StartTimestamp     *time.Time `json:"startTimestamp,omitempty"`
 	EndTimestamp       *time.Time `json:"endTimestamp,omitempty"`
 	StartOffsetSeconds *float64   `json:"startOffsetSeconds,omitempty"`
 	EndOffsetSeconds   *float64   `json:"endOffsetSeconds,omitempty"`
 	Embedding          []float32  `json:"-"`
 	ProcessingStatus   string     `json:"processingStatus"`
+	AttemptCount       int        `json:"attemptCount"`
+	LastError          *string    `json:"lastError,omitempty"`
+	NextRetryAt        *time.Time `json:"nextRetryAt,omitempty"`
+	Similarity         float64    `json:"similarity,omitempty"`
 	CreatedAt          time.Time  `json:"createdAt"`
 }
 
+// Chunk processing statuses
+const (
+	ChunkStatusPending    = "pending"
+	ChunkStatusProcessing = "processing"
+	ChunkStatusCompleted  = "completed"
+	ChunkStatusFailed     = "failed"
+)
+
+// RAG source types. Meetings use the bare meeting ID as their source ID;
+// other sources are stored as "<type>:<session_id>" in the same tables.
+const (
+	SourceTypeMeeting   = "meeting"
+	SourceTypeVideo     = "video"
+	SourceTypeRecording = "recording"
+	SourceTypeDocument  = "document"
+)
+
+// RAGSourceID builds the source ID stored in meeting_id columns
+func RAGSourceID(sourceType, id string) string {
+	if sourceType == "" || sourceType == SourceTypeMeeting {
+		return id
+	}
+	return sourceType + ":" + id
+}
+
+// RAGSourceType returns the source type encoded in a source ID
+func RAGSourceType(sourceID string) string {
+	for _, sourceType := range []string{SourceTypeVideo, SourceTypeRecording, SourceTypeDocument} {
+		if strings.HasPrefix(sourceID, sourceType+":") {
+			return sourceType
+		}
+	}
+	return SourceTypeMeeting
+}
+
 // ChatSession represents a RAG conversation session
 type ChatSession struct {
 	ID           int       `json:"id"`
@@ -34,6 +78,7 @@ type ChatSession struct {
 	MeetingID    string    `json:"meetingId"`
 	Language     string    `json:"language"`
 	UserID       *int      `json:"userId,omitempty"`
+	IsShared     bool      `json:"isShared"`
 	CreatedAt    time.Time `json:"createdAt"`
 	LastActivity time.Time `json:"lastActivity"`
 }
@@ -52,6 +97,7 @@ type ChatMessage struct {
 
 // CreateMeetingChunk inserts a new chunk with its embedding
 func CreateMeetingChunk(chunk *MeetingChunk) error {
+	chunk.Language = langcode.Normalize(chunk.Language)
 	query := `
 		INSERT INTO meeting_chunks (
 			meeting_id, language, chunk_index, chunk_text,
@@ -62,8 +108,11 @@ func CreateMeetingChunk(chunk *MeetingChunk) error {
 		RETURNING id, created_at
 	`
 
-	// Convert embedding slice to pgvector format string
-	embeddingStr := embeddingToString(chunk.Embedding)
+	// Chunks are inserted before embedding, so a missing vector is stored as NULL
+	var embeddingStr interface{}
+	if len(chunk.Embedding) > 0 {
+		embeddingStr = embeddingToString(chunk.Embedding)
+	}
 
 	err := DB.QueryRow(
 		query,
@@ -88,23 +137,212 @@ func CreateMeetingChunk(chunk *MeetingChunk) error {
 	return nil
 }
 
+// UpsertMeetingChunk inserts a chunk or replaces the chunk stored at the same
+// (meeting, language, index). Replaced chunks are reset to pending with no embedding.
+func UpsertMeetingChunk(chunk *MeetingChunk) error {
+	chunk.Language = langcode.Normalize(chunk.Language)
+	query := `
+		INSERT INTO meeting_chunks (
+			meeting_id, language, chunk_index, chunk_text, content_hash,
+			speaker_id, speaker_name, start_timestamp, end_timestamp,
+			start_offset_seconds, end_offset_seconds, processing_status, source_type, chunk_version, speaker_names
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (meeting_id, language, chunk_index)
+		DO UPDATE SET
+			chunk_text = EXCLUDED.chunk_text,
+			content_hash = EXCLUDED.content_hash,
+			chunk_version = EXCLUDED.chunk_version,
+			speaker_id = EXCLUDED.speaker_id,
+			speaker_name = EXCLUDED.speaker_name,
+			speaker_names = EXCLUDED.speaker_names,
+			start_timestamp = EXCLUDED.start_timestamp,
+			end_timestamp = EXCLUDED.end_timestamp,
+			start_offset_seconds = EXCLUDED.start_offset_seconds,
+			end_offset_seconds = EXCLUDED.end_offset_seconds,
+			processing_status = EXCLUDED.processing_status,
+			embedding = NULL,
+			attempt_count = 0,
+			last_error = NULL,
+			next_retry_at = NULL,
+			updated_at = NOW()
+		RETURNING id, created_at
+	`
+
+	err := DB.QueryRow(
+		query,
+		chunk.MeetingID,
+		chunk.Language,
+		chunk.ChunkIndex,
+		chunk.ChunkText,
+		nullString(chunk.ContentHash),
+		chunk.SpeakerID,
+		chunk.SpeakerName,
+		chunk.StartTimestamp,
+		chunk.EndTimestamp,
+		chunk.StartOffsetSeconds,
+		chunk.EndOffsetSeconds,
+		ChunkStatusPending,
+		RAGSourceType(chunk.MeetingID),
+		chunk.ChunkVersion,
+		pq.Array(speakerNames(chunk)),
+	).Scan(&chunk.ID, &chunk.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert meeting chunk: %w", err)
+	}
+
+	chunk.ProcessingStatus = ChunkStatusPending
+	return nil
+}
+
+// speakerNames returns the speakers to store for a chunk: SpeakerNames, or
+// the single SpeakerName of chunks built without the list
+func speakerNames(chunk *MeetingChunk) []string {
+	if len(chunk.SpeakerNames) > 0 {
+		return chunk.SpeakerNames
+	}
+	if chunk.SpeakerName != nil {
+		return []string{*chunk.SpeakerName}
+	}
+	return []string{}
+}
+
+// ChunkFingerprint identifies a stored chunk by its content hash, chunker
+// version and status
+type ChunkFingerprint struct {
+	ID               int
+	ContentHash      string
+	ChunkVersion     string
+	ProcessingStatus string
+}
+
+// GetChunkFingerprints returns stored chunk hashes keyed by chunk index
+func GetChunkFingerprints(meetingID, language string) (map[int]ChunkFingerprint, error) {
+	language = langcode.Normalize(language)
+	query := `
+		SELECT id, chunk_index, COALESCE(content_hash, ''), chunk_version, processing_status
+		FROM meeting_chunks
+		WHERE meeting_id = $1 AND language = $2
+	`
+
+	rows, err := DB.Query(query, meetingID, language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunk fingerprints: %w", err)
+	}
+	defer rows.Close()
+
+	fingerprints := make(map[int]ChunkFingerprint)
+	for rows.Next() {
+		var fp ChunkFingerprint
+		var index int
+		if err := rows.Scan(&fp.ID, &index, &fp.ContentHash, &fp.ChunkVersion, &fp.ProcessingStatus); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk fingerprint: %w", err)
+		}
+		fingerprints[index] = fp
+	}
+
+	return fingerprints, rows.Err()
+}
+
+// DeleteChunksFromIndex removes chunks at or beyond fromIndex, used when a
+// re-processed transcript produces fewer chunks than before
+func DeleteChunksFromIndex(meetingID, language string, fromIndex int) (int64, error) {
+	language = langcode.Normalize(language)
+	result, err := DB.Exec(`
+		DELETE FROM meeting_chunks
+		WHERE meeting_id = $1 AND language = $2 AND chunk_index >= $3
+	`, meetingID, language, fromIndex)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stale chunks: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteMeetingChunks removes every chunk of a source in all languages
+func DeleteMeetingChunks(meetingID string) (int64, error) {
+	result, err := DB.Exec(`DELETE FROM meeting_chunks WHERE meeting_id = $1`, meetingID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete chunks: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// GetChunkHashesByLanguage returns the content hashes of a source's chunks
+// grouped by language, for comparing a knowledge base before and after it is
+// rebuilt
+func GetChunkHashesByLanguage(meetingID string) (map[string][]string, error) {
+	rows, err := DB.Query(`
+		SELECT language, COALESCE(content_hash, '')
+		FROM meeting_chunks
+		WHERE meeting_id = $1
+		ORDER BY language, chunk_index
+	`, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunk hashes: %w", err)
+	}
+	defer rows.Close()
+
+	hashes := make(map[string][]string)
+	for rows.Next() {
+		var language, hash string
+		if err := rows.Scan(&language, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk hash: %w", err)
+		}
+		hashes[language] = append(hashes[language], hash)
+	}
+
+	return hashes, rows.Err()
+}
+
 // SearchSimilarChunks finds top-k most similar chunks using cosine similarity
 func SearchSimilarChunks(meetingID, language string, queryEmbedding []float32, topK int) ([]MeetingChunk, error) {
+	language = langcode.Normalize(language)
 	query := `
 		SELECT
 			id, meeting_id, language, chunk_index, chunk_text,
 			speaker_id, speaker_name, start_timestamp, end_timestamp,
 			start_offset_seconds, end_offset_seconds, processing_status, created_at,
-			1 - (embedding <=> $1::vector) as similarity
+			speaker_names, 1 - (embedding <=> $1::vector) as similarity
 		FROM meeting_chunks
 		WHERE meeting_id = $2 AND language = $3 AND processing_status = 'completed'
 		ORDER BY embedding <=> $1::vector
 		LIMIT $4
 	`
 
-	embeddingStr := embeddingToString(queryEmbedding)
+	return searchChunks(query, embeddingToString(queryEmbedding), meetingID, language, topK)
+}
+
+// hybridKeywordWeight is the share of the keyword rank in hybrid ordering
+const hybridKeywordWeight = 0.3
+
+// SearchHybridChunks finds top-k chunks ordered by a blend of cosine
+// similarity and full-text rank against queryText, so exact names and terms
+// surface even when their embeddings are not the closest. Similarity on the
+// returned chunks is still the cosine similarity.
+func SearchHybridChunks(meetingID, language, queryText string, queryEmbedding []float32, topK int) ([]MeetingChunk, error) {
+	language = langcode.Normalize(language)
+	query := `
+		SELECT
+			id, meeting_id, language, chunk_index, chunk_text,
+			speaker_id, speaker_name, start_timestamp, end_timestamp,
+			start_offset_seconds, end_offset_seconds, processing_status, created_at,
+			speaker_names, 1 - (embedding <=> $1::vector) as similarity
+		FROM meeting_chunks
+		WHERE meeting_id = $2 AND language = $3 AND processing_status = 'completed'
+		ORDER BY (1 - $5::float8) * (1 - (embedding <=> $1::vector))
+			+ $5::float8 * ts_rank_cd(to_tsvector('simple', chunk_text), plainto_tsquery('simple', $6), 32) DESC
+		LIMIT $4
+	`
+
+	return searchChunks(query, embeddingToString(queryEmbedding), meetingID, language, topK, hybridKeywordWeight, queryText)
+}
 
-	rows, err := DB.Query(query, embeddingStr, meetingID, language, topK)
+// searchChunks runs a chunk search query selecting the columns of
+// SearchSimilarChunks
+func searchChunks(query string, args ...interface{}) ([]MeetingChunk, error) {
+	rows, err := DB.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search similar chunks: %w", err)
 	}
@@ -113,7 +351,6 @@ func SearchSimilarChunks(meetingID, language string, queryEmbedding []float32, t
 	var chunks []MeetingChunk
 	for rows.Next() {
 		var chunk MeetingChunk
-		var similarity float64
 		var speakerID, speakerName sql.NullString
 		var startTimestamp, endTimestamp sql.NullTime
 		var startOffset, endOffset sql.NullFloat64
@@ -132,7 +369,8 @@ func SearchSimilarChunks(meetingID, language string, queryEmbedding []float32, t
 			&endOffset,
 			&chunk.ProcessingStatus,
 			&chunk.CreatedAt,
-			&similarity,
+			pq.Array(&chunk.SpeakerNames),
+			&chunk.Similarity,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan chunk: %w", err)
@@ -170,6 +408,7 @@ func SearchSimilarChunks(meetingID, language string, queryEmbedding []float32, t
 
 // UpdateChunkProcessingStatus updates the processing status of chunks
 func UpdateChunkProcessingStatus(meetingID, language, status string) error {
+	language = langcode.Normalize(language)
 	query := `
 		UPDATE meeting_chunks
 		SET processing_status = $1
@@ -186,6 +425,7 @@ func UpdateChunkProcessingStatus(meetingID, language, status string) error {
 
 // GetChunksByMeeting retrieves all chunks for a meeting
 func GetChunksByMeeting(meetingID, language string) ([]MeetingChunk, error) {
+	language = langcode.Normalize(language)
 	query := `
 		SELECT
 			id, meeting_id, language, chunk_index, chunk_text,
@@ -254,22 +494,267 @@ func GetChunksByMeeting(meetingID, language string) ([]MeetingChunk, error) {
 	return chunks, nil
 }
 
+// --- Chunk processing status operations ---
+
+// chunkStatusColumns lists the columns read by scanChunkWithStatus
+const chunkStatusColumns = `
+	id, meeting_id, language, chunk_index, chunk_text,
+	speaker_id, speaker_name, start_timestamp, end_timestamp,
+	start_offset_seconds, end_offset_seconds, processing_status,
+	COALESCE(attempt_count, 0), last_error, next_retry_at, created_at
+`
+
+// scanChunkWithStatus scans a row selected with chunkStatusColumns
+func scanChunkWithStatus(rows *sql.Rows) (MeetingChunk, error) {
+	var chunk MeetingChunk
+	var speakerID, speakerName, lastError sql.NullString
+	var startTimestamp, endTimestamp, nextRetryAt sql.NullTime
+	var startOffset, endOffset sql.NullFloat64
+
+	err := rows.Scan(
+		&chunk.ID,
+		&chunk.MeetingID,
+		&chunk.Language,
+		&chunk.ChunkIndex,
+		&chunk.ChunkText,
+		&speakerID,
+		&speakerName,
+		&startTimestamp,
+		&endTimestamp,
+		&startOffset,
+		&endOffset,
+		&chunk.ProcessingStatus,
+		&chunk.AttemptCount,
+		&lastError,
+		&nextRetryAt,
+		&chunk.CreatedAt,
+	)
+	if err != nil {
+		return chunk, fmt.Errorf("failed to scan chunk: %w", err)
+	}
+
+	if speakerID.Valid {
+		chunk.SpeakerID = &speakerID.String
+	}
+	if speakerName.Valid {
+		chunk.SpeakerName = &speakerName.String
+	}
+	if startTimestamp.Valid {
+		chunk.StartTimestamp = &startTimestamp.Time
+	}
+	if endTimestamp.Valid {
+		chunk.EndTimestamp = &endTimestamp.Time
+	}
+	if startOffset.Valid {
+		chunk.StartOffsetSeconds = &startOffset.Float64
+	}
+	if endOffset.Valid {
+		chunk.EndOffsetSeconds = &endOffset.Float64
+	}
+	if lastError.Valid {
+		chunk.LastError = &lastError.String
+	}
+	if nextRetryAt.Valid {
+		chunk.NextRetryAt = &nextRetryAt.Time
+	}
+
+	return chunk, nil
+}
+
+// MarkChunksProcessing moves the given chunks into the processing state
+func MarkChunksProcessing(chunkIDs []int) error {
+	if len(chunkIDs) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE meeting_chunks
+		SET processing_status = $1, updated_at = NOW()
+		WHERE id = ANY($2)
+	`
+
+	if _, err := DB.Exec(query, ChunkStatusProcessing, pq.Array(chunkIDs)); err != nil {
+		return fmt.Errorf("failed to mark chunks processing: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteChunk stores the embedding for a chunk and marks it completed
+func CompleteChunk(chunkID int, embedding []float32) error {
+	query := `
+		UPDATE meeting_chunks
+		SET embedding = $1, processing_status = $2, attempt_count = COALESCE(attempt_count, 0) + 1,
+			last_error = NULL, next_retry_at = NULL, updated_at = NOW()
+		WHERE id = $3
+	`
+
+	if _, err := DB.Exec(query, embeddingToString(embedding), ChunkStatusCompleted, chunkID); err != nil {
+		return fmt.Errorf("failed to complete chunk: %w", err)
+	}
+
+	return nil
+}
+
+// FailChunk records a failed embedding attempt and schedules the next retry.
+// A nil nextRetryAt leaves the chunk failed without further retries.
+func FailChunk(chunkID int, errMsg string, nextRetryAt *time.Time) error {
+	query := `
+		UPDATE meeting_chunks
+		SET processing_status = $1, attempt_count = COALESCE(attempt_count, 0) + 1,
+			last_error = $2, next_retry_at = $3, updated_at = NOW()
+		WHERE id = $4
+	`
+
+	if _, err := DB.Exec(query, ChunkStatusFailed, errMsg, nextRetryAt, chunkID); err != nil {
+		return fmt.Errorf("failed to mark chunk failed: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimRetryableChunks atomically moves failed chunks whose retry time has passed
+// back into processing and returns them. Chunks processing for longer than
+// staleAfter, left behind by a crash or restart, are reclaimed as well.
+func ClaimRetryableChunks(limit int, staleAfter time.Duration) ([]MeetingChunk, error) {
+	query := `
+		UPDATE meeting_chunks
+		SET processing_status = $1, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM meeting_chunks
+			WHERE (processing_status = $2 AND next_retry_at IS NOT NULL AND next_retry_at <= NOW())
+			   OR (processing_status = $1 AND updated_at < NOW() - $4 * INTERVAL '1 second')
+			ORDER BY COALESCE(next_retry_at, updated_at)
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + chunkStatusColumns
+
+	rows, err := DB.Query(query, ChunkStatusProcessing, ChunkStatusFailed, limit, staleAfter.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim retryable chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []MeetingChunk
+	for rows.Next() {
+		chunk, err := scanChunkWithStatus(rows)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating chunks: %w", err)
+	}
+
+	return chunks, nil
+}
+
+// GetFailedChunks returns failed chunks for a meeting (all languages when language is empty)
+func GetFailedChunks(meetingID, language string) ([]MeetingChunk, error) {
+	language = langcode.Normalize(language)
+	query := `
+		SELECT ` + chunkStatusColumns + `
+		FROM meeting_chunks
+		WHERE meeting_id = $1 AND processing_status = $2 AND ($3 = '' OR language = $3)
+		ORDER BY language, chunk_index
+	`
+
+	rows, err := DB.Query(query, meetingID, ChunkStatusFailed, language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get failed chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []MeetingChunk
+	for rows.Next() {
+		chunk, err := scanChunkWithStatus(rows)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating chunks: %w", err)
+	}
+
+	return chunks, nil
+}
+
+// GetChunkStatusCounts returns the number of chunks per processing status for a meeting
+func GetChunkStatusCounts(meetingID string) (map[string]int, error) {
+	query := `
+		SELECT processing_status, COUNT(*)
+		FROM meeting_chunks
+		WHERE meeting_id = $1
+		GROUP BY processing_status
+	`
+
+	rows, err := DB.Query(query, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count chunk statuses: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk status count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// GetChunkVersionCounts counts a source's chunks per chunker version
+func GetChunkVersionCounts(meetingID string) (map[string]int, error) {
+	rows, err := DB.Query(`
+		SELECT chunk_version, COUNT(*)
+		FROM meeting_chunks
+		WHERE meeting_id = $1
+		GROUP BY chunk_version
+	`, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count chunk versions: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var version string
+		var count int
+		if err := rows.Scan(&version, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk version count: %w", err)
+		}
+		counts[version] = count
+	}
+
+	return counts, rows.Err()
+}
+
 // --- Chat Session operations ---
 
 // CreateChatSession creates a new chat session
 func CreateChatSession(meetingID, language string, userID *int) (*ChatSession, error) {
+	language = langcode.Normalize(language)
 	sessionID := fmt.Sprintf("CHAT_%d", time.Now().UnixNano())
 
 	query := `
-		INSERT INTO meeting_chat_sessions (session_id, meeting_id, language, user_id)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO meeting_chat_sessions (session_id, meeting_id, language, user_id, source_type)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, session_id, meeting_id, language, user_id, created_at, last_activity
 	`
 
 	var session ChatSession
 	var userIDVal sql.NullInt64
 
-	err := DB.QueryRow(query, sessionID, meetingID, language, userID).Scan(
+	err := DB.QueryRow(query, sessionID, meetingID, language, userID, RAGSourceType(meetingID)).Scan(
 		&session.ID,
 		&session.SessionID,
 		&session.MeetingID,
@@ -293,7 +778,7 @@ func CreateChatSession(meetingID, language string, userID *int) (*ChatSession, e
 // GetChatSession retrieves a chat session by session ID
 func GetChatSession(sessionID string) (*ChatSession, error) {
 	query := `
-		SELECT id, session_id, meeting_id, language, user_id, created_at, last_activity
+		SELECT id, session_id, meeting_id, language, user_id, COALESCE(is_shared, false), created_at, last_activity
 		FROM meeting_chat_sessions
 		WHERE session_id = $1
 	`
@@ -307,6 +792,7 @@ func GetChatSession(sessionID string) (*ChatSession, error) {
 		&session.MeetingID,
 		&session.Language,
 		&userID,
+		&session.IsShared,
 		&session.CreatedAt,
 		&session.LastActivity,
 	)
@@ -325,6 +811,37 @@ func GetChatSession(sessionID string) (*ChatSession, error) {
 	return &session, nil
 }
 
+// ListChatSessions returns a user's chat sessions on a source and the
+// sessions others shared on it, most recently active first
+func ListChatSessions(meetingID string, userID int) ([]ChatSession, error) {
+	query := `
+		SELECT id, session_id, meeting_id, language, user_id, COALESCE(is_shared, false), created_at, last_activity
+		FROM meeting_chat_sessions
+		WHERE meeting_id = $1 AND (user_id = $2 OR COALESCE(is_shared, false))
+		ORDER BY last_activity DESC
+	`
+
+	rows, err := DB.Query(query, meetingID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chat sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []ChatSession{}
+	for rows.Next() {
+		var session ChatSession
+		var uid sql.NullInt64
+		if err := rows.Scan(&session.ID, &session.SessionID, &session.MeetingID, &session.Language, &uid,
+			&session.IsShared, &session.CreatedAt, &session.LastActivity); err != nil {
+			return nil, fmt.Errorf("failed to scan chat session: %w", err)
+		}
+		session.UserID = nullIntPtr(uid)
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
 // UpdateChatSessionActivity updates the last activity time for a chat session
 func UpdateChatSessionActivity(sessionID string) error {
 	query := `
@@ -341,6 +858,135 @@ func UpdateChatSessionActivity(sessionID string) error {
 	return nil
 }
 
+// SetChatSessionShared toggles read-only sharing of a chat session
+func SetChatSessionShared(sessionID string, shared bool) error {
+	query := `
+		UPDATE meeting_chat_sessions
+		SET is_shared = $2, shared_at = CASE WHEN $2 THEN NOW() ELSE NULL END
+		WHERE session_id = $1
+	`
+
+	if _, err := DB.Exec(query, sessionID, shared); err != nil {
+		return fmt.Errorf("failed to update chat session sharing: %w", err)
+	}
+
+	return nil
+}
+
+// ChunkCitation describes a cited chunk for chat exports
+type ChunkCitation struct {
+	ChunkID            int      `json:"chunkId"`
+	SourceID           string   `json:"sourceId"`
+	SourceType         string   `json:"sourceType"`
+	DocumentTitle      string   `json:"documentTitle,omitempty"`
+	SpeakerName        *string  `json:"speakerName,omitempty"`
+	StartOffsetSeconds *float64 `json:"startOffsetSeconds,omitempty"`
+	EndOffsetSeconds   *float64 `json:"endOffsetSeconds,omitempty"`
+	ChunkText          string   `json:"chunkText"`
+}
+
+// GetChunkCitations returns citation details for chunk IDs keyed by chunk ID.
+// Chunks that were deleted since the message was saved are omitted.
+func GetChunkCitations(chunkIDs []int) (map[int]ChunkCitation, error) {
+	citations := make(map[int]ChunkCitation)
+	if len(chunkIDs) == 0 {
+		return citations, nil
+	}
+
+	query := `
+		SELECT c.id, c.meeting_id, c.speaker_name, c.start_offset_seconds, c.end_offset_seconds,
+		       c.chunk_text, COALESCE(d.title, '')
+		FROM meeting_chunks c
+		LEFT JOIN knowledge_documents d ON c.meeting_id = 'document:' || d.id
+		WHERE c.id = ANY($1)
+	`
+
+	rows, err := DB.Query(query, pq.Array(chunkIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunk citations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var citation ChunkCitation
+		var speakerName sql.NullString
+		var startOffset, endOffset sql.NullFloat64
+
+		err := rows.Scan(
+			&citation.ChunkID,
+			&citation.SourceID,
+			&speakerName,
+			&startOffset,
+			&endOffset,
+			&citation.ChunkText,
+			&citation.DocumentTitle,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan chunk citation: %w", err)
+		}
+
+		citation.SourceType = RAGSourceType(citation.SourceID)
+		if speakerName.Valid {
+			citation.SpeakerName = &speakerName.String
+		}
+		if startOffset.Valid {
+			citation.StartOffsetSeconds = &startOffset.Float64
+		}
+		if endOffset.Valid {
+			citation.EndOffsetSeconds = &endOffset.Float64
+		}
+		citations[citation.ChunkID] = citation
+	}
+
+	return citations, rows.Err()
+}
+
+// MeetingRAGSettings holds per-meeting retrieval defaults; nil fields fall
+// back to the server defaults
+type MeetingRAGSettings struct {
+	TopK          *int     `json:"topK,omitempty"`
+	MinSimilarity *float64 `json:"minSimilarity,omitempty"`
+}
+
+// GetMeetingRAGSettings returns the retrieval defaults for a meeting
+func GetMeetingRAGSettings(meetingID string) (*MeetingRAGSettings, error) {
+	var topK sql.NullInt64
+	var minSimilarity sql.NullFloat64
+
+	err := DB.QueryRow(`
+		SELECT rag_top_k, rag_min_similarity FROM meetings WHERE id = $1
+	`, meetingID).Scan(&topK, &minSimilarity)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &MeetingRAGSettings{}, nil
+		}
+		return nil, fmt.Errorf("failed to get meeting RAG settings: %w", err)
+	}
+
+	settings := &MeetingRAGSettings{}
+	if topK.Valid {
+		value := int(topK.Int64)
+		settings.TopK = &value
+	}
+	if minSimilarity.Valid {
+		settings.MinSimilarity = &minSimilarity.Float64
+	}
+
+	return settings, nil
+}
+
+// UpdateMeetingRAGSettings stores the retrieval defaults for a meeting
+func UpdateMeetingRAGSettings(meetingID string, settings MeetingRAGSettings) error {
+	_, err := DB.Exec(`
+		UPDATE meetings SET rag_top_k = $2, rag_min_similarity = $3 WHERE id = $1
+	`, meetingID, settings.TopK, settings.MinSimilarity)
+	if err != nil {
+		return fmt.Errorf("failed to update meeting RAG settings: %w", err)
+	}
+
+	return nil
+}
+
 // --- Chat Message operations ---
 
 // SaveChatMessage saves a chat message
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"realtime-caption-translator/internal/langcode"
+)
+
+// RAG ingestion statuses
+const (
+	IngestionPending    = "pending"
+	IngestionProcessing = "processing"
+	IngestionCompleted  = "completed"
+	IngestionFailed     = "failed"
+)
+
+// RAGIngestion is the chunking and embedding state of one transcript
+// language of a meeting
+type RAGIngestion struct {
+	MeetingID  string     `json:"meetingId"`
+	Language   string     `json:"language"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	Attempts   int        `json:"attempts"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// SetRAGIngestionStatus records the ingestion status of a meeting/language.
+// Moving to processing counts an attempt and clears the last error; moving
+// to completed or failed records when it finished.
+func SetRAGIngestionStatus(meetingID, language, status, errMsg string) error {
+	_, err := DB.Exec(`
+		INSERT INTO meeting_rag_ingestions (meeting_id, language, status, error, attempts, started_at, finished_at)
+		VALUES ($1, $2, $3, $4,
+			CASE WHEN $3 = 'processing' THEN 1 ELSE 0 END,
+			CASE WHEN $3 = 'processing' THEN NOW() END,
+			CASE WHEN $3 IN ('completed', 'failed') THEN NOW() END)
+		ON CONFLICT (meeting_id, language) DO UPDATE SET
+			status = EXCLUDED.status,
+			error = EXCLUDED.error,
+			attempts = meeting_rag_ingestions.attempts + EXCLUDED.attempts,
+			started_at = COALESCE(EXCLUDED.started_at, meeting_rag_ingestions.started_at),
+			finished_at = CASE WHEN $3 = 'pending' OR $3 = 'processing' THEN NULL ELSE EXCLUDED.finished_at END,
+			updated_at = NOW()
+	`, meetingID, langcode.Normalize(language), status, nullString(errMsg))
+	if err != nil {
+		return fmt.Errorf("failed to set RAG ingestion status: %w", err)
+	}
+	return nil
+}
+
+// StartRAGIngestions marks languages of a meeting pending unless an
+// ingestion of the meeting is already pending or processing. Ingestions not
+// updated for staleAfter are assumed abandoned by a crashed or restarted
+// server and do not block. The check and the update run under a lock on the
+// meeting, so concurrent callers cannot both start. Returns false when an
+// ingestion is already running.
+func StartRAGIngestions(meetingID string, languages []string, staleAfter time.Duration) (bool, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT 1 FROM meetings WHERE id = $1 FOR UPDATE`, meetingID); err != nil {
+		return false, fmt.Errorf("failed to lock meeting: %w", err)
+	}
+
+	var running bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM meeting_rag_ingestions
+			WHERE meeting_id = $1
+			  AND status IN ('pending', 'processing')
+			  AND updated_at > NOW() - $2 * INTERVAL '1 second'
+		)
+	`, meetingID, staleAfter.Seconds()).Scan(&running)
+	if err != nil {
+		return false, fmt.Errorf("failed to check RAG ingestions: %w", err)
+	}
+	if running {
+		return false, nil
+	}
+
+	normalized := make([]string, len(languages))
+	for i, language := range languages {
+		normalized[i] = langcode.Normalize(language)
+	}
+	_, err = tx.Exec(`
+		INSERT INTO meeting_rag_ingestions (meeting_id, language, status)
+		SELECT $1, language, 'pending' FROM unnest($2::text[]) AS language
+		ON CONFLICT (meeting_id, language) DO UPDATE SET
+			status = 'pending',
+			error = NULL,
+			finished_at = NULL,
+			updated_at = NOW()
+	`, meetingID, pq.Array(normalized))
+	if err != nil {
+		return false, fmt.Errorf("failed to start RAG ingestions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit RAG ingestions: %w", err)
+	}
+	return true, nil
+}
+
+// ListRAGIngestions returns the ingestion state of each transcript language
+// of a meeting
+func ListRAGIngestions(meetingID string) ([]RAGIngestion, error) {
+	rows, err := DB.Query(`
+		SELECT meeting_id, language, status, error, attempts, started_at, finished_at, updated_at
+		FROM meeting_rag_ingestions
+		WHERE meeting_id = $1
+		ORDER BY language
+	`, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list RAG ingestions: %w", err)
+	}
+	defer rows.Close()
+
+	ingestions := []RAGIngestion{}
+	for rows.Next() {
+		var ingestion RAGIngestion
+		var errMsg sql.NullString
+		var startedAt, finishedAt sql.NullTime
+		if err := rows.Scan(&ingestion.MeetingID, &ingestion.Language, &ingestion.Status, &errMsg,
+			&ingestion.Attempts, &startedAt, &finishedAt, &ingestion.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan RAG ingestion: %w", err)
+		}
+		ingestion.Error = errMsg.String
+		if startedAt.Valid {
+			ingestion.StartedAt = &startedAt.Time
+		}
+		if finishedAt.Valid {
+			ingestion.FinishedAt = &finishedAt.Time
+		}
+		ingestions = append(ingestions, ingestion)
+	}
+	return ingestions, rows.Err()
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"realtime-caption-translator/internal/langcode"
+)
+
+// SourceMeetingLink records that an uploaded video or recording duplicates a
+// meeting captured live, so its knowledge is served from the meeting
+type SourceMeetingLink struct {
+	SourceID   string    `json:"sourceId"`
+	MeetingID  string    `json:"meetingId"`
+	Similarity *float64  `json:"similarity,omitempty"`
+	LinkedBy   *int      `json:"linkedBy,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// LinkSourceToMeeting links a source to a meeting and removes the source's own
+// chunks and summaries in one transaction, so the recording is not indexed twice
+func LinkSourceToMeeting(sourceID, meetingID string, similarity *float64, userID int) (*SourceMeetingLink, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin source link: %w", err)
+	}
+	defer tx.Rollback()
+
+	link := &SourceMeetingLink{SourceID: sourceID, MeetingID: meetingID, Similarity: similarity, LinkedBy: &userID}
+	err = tx.QueryRow(`
+		INSERT INTO rag_source_links (source_id, meeting_id, similarity, linked_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (source_id)
+		DO UPDATE SET meeting_id = EXCLUDED.meeting_id, similarity = EXCLUDED.similarity,
+			linked_by = EXCLUDED.linked_by, created_at = NOW()
+		RETURNING created_at
+	`, sourceID, meetingID, similarity, userID).Scan(&link.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link source: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM meeting_chunks WHERE meeting_id = $1`, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to remove source chunks: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM rag_source_summaries WHERE source_id = $1`, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to remove source summaries: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit source link: %w", err)
+	}
+	return link, nil
+}
+
+// GetSourceMeetingLink returns the meeting a source is linked to, or nil
+func GetSourceMeetingLink(sourceID string) (*SourceMeetingLink, error) {
+	var link SourceMeetingLink
+	var similarity sql.NullFloat64
+	var linkedBy sql.NullInt64
+
+	err := DB.QueryRow(`
+		SELECT source_id, meeting_id, similarity, linked_by, created_at
+		FROM rag_source_links
+		WHERE source_id = $1
+	`, sourceID).Scan(&link.SourceID, &link.MeetingID, &similarity, &linkedBy, &link.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get source link: %w", err)
+	}
+
+	if similarity.Valid {
+		link.Similarity = &similarity.Float64
+	}
+	if linkedBy.Valid {
+		id := int(linkedBy.Int64)
+		link.LinkedBy = &id
+	}
+	return &link, nil
+}
+
+// UnlinkSource removes a source's meeting link; it returns false if there was none
+func UnlinkSource(sourceID string) (bool, error) {
+	result, err := DB.Exec(`DELETE FROM rag_source_links WHERE source_id = $1`, sourceID)
+	if err != nil {
+		return false, fmt.Errorf("failed to unlink source: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to unlink source: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// SearchSimilarMeetingChunks finds the chunks closest to an embedding across
+// all meetings the user owns or has been granted access to. Only ID,
+// MeetingID, ChunkIndex and Similarity are set.
+func SearchSimilarMeetingChunks(userID int, language string, queryEmbedding []float32, topK int) ([]MeetingChunk, error) {
+	language = langcode.Normalize(language)
+	query := `
+		SELECT c.id, c.meeting_id, c.chunk_index, 1 - (c.embedding <=> $1::vector) AS similarity
+		FROM meeting_chunks c
+		JOIN meetings m ON m.id = c.meeting_id
+		WHERE c.source_type = 'meeting'
+			AND c.language = $2
+			AND c.processing_status = 'completed'
+			AND (m.created_by = $3 OR EXISTS (
+				SELECT 1 FROM meeting_access_control a
+				WHERE a.meeting_id = m.id AND a.user_id = $3 AND a.removed_at IS NULL
+			))
+		ORDER BY c.embedding <=> $1::vector
+		LIMIT $4
+	`
+
+	rows, err := DB.Query(query, embeddingToString(queryEmbedding), language, userID, topK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search meeting chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []MeetingChunk
+	for rows.Next() {
+		var chunk MeetingChunk
+		if err := rows.Scan(&chunk.ID, &chunk.MeetingID, &chunk.ChunkIndex, &chunk.Similarity); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+		chunk.Language = language
+		chunks = append(chunks, chunk)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating chunks: %w", err)
+	}
+
+	return chunks, nil
+}
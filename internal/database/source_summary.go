@@ -0,0 +1,111 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"realtime-caption-translator/internal/langcode"
+)
+
+// SourceChapter is a titled section of a video or recording.
+type SourceChapter struct {
+	Title        string   `json:"title"`
+	StartSeconds *float64 `json:"start_seconds,omitempty"`
+	Summary      string   `json:"summary,omitempty"`
+}
+
+// SourceSummary represents a stored summary for a non-meeting RAG source.
+type SourceSummary struct {
+	SourceID   string          `json:"sourceId"`
+	SourceType string          `json:"sourceType"`
+	Language   string          `json:"language"`
+	Summary    string          `json:"summary"`
+	Chapters   []SourceChapter `json:"chapters"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	UpdatedAt  time.Time       `json:"updatedAt"`
+}
+
+// SaveSourceSummary upserts the summary and chapters for a source/language.
+func SaveSourceSummary(sourceID, language, summary string, chapters []SourceChapter) error {
+	language = langcode.Normalize(language)
+	if language == "" {
+		language = "en"
+	}
+	if chapters == nil {
+		chapters = []SourceChapter{}
+	}
+
+	payload, err := json.Marshal(chapters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal source chapters: %w", err)
+	}
+
+	query := `
+		INSERT INTO rag_source_summaries (source_id, source_type, language, summary, chapters)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (source_id, language)
+		DO UPDATE SET summary = EXCLUDED.summary, chapters = EXCLUDED.chapters, updated_at = NOW()
+	`
+
+	if _, err := DB.Exec(query, sourceID, RAGSourceType(sourceID), language, summary, payload); err != nil {
+		return fmt.Errorf("failed to save source summary: %w", err)
+	}
+
+	return nil
+}
+
+// GetSourceSummary returns the summary for a source/language.
+func GetSourceSummary(sourceID, language string) (*SourceSummary, error) {
+	language = langcode.Normalize(language)
+	if language == "" {
+		language = "en"
+	}
+
+	query := `
+		SELECT source_id, source_type, language, summary, chapters, created_at, updated_at
+		FROM rag_source_summaries
+		WHERE source_id = $1 AND language = $2
+	`
+
+	var (
+		result        SourceSummary
+		summary       sql.NullString
+		chaptersBytes []byte
+		createdAt     sql.NullTime
+		updatedAt     sql.NullTime
+	)
+
+	err := DB.QueryRow(query, sourceID, language).Scan(
+		&result.SourceID,
+		&result.SourceType,
+		&result.Language,
+		&summary,
+		&chaptersBytes,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get source summary: %w", err)
+	}
+
+	if err := json.Unmarshal(chaptersBytes, &result.Chapters); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal source chapters: %w", err)
+	}
+
+	if summary.Valid {
+		result.Summary = summary.String
+	}
+	if createdAt.Valid {
+		result.CreatedAt = createdAt.Time
+	}
+	if updatedAt.Valid {
+		result.UpdatedAt = updatedAt.Time
+	}
+
+	return &result, nil
+}
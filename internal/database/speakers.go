@@ -0,0 +1,155 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MeetingSpeaker is a diarized speaker of a meeting: a per-device ID such as
+// "P1_SPEAKER_00", or an enrolled speaker's "USER_{id}"
+type MeetingSpeaker struct {
+	SpeakerID     string     `json:"speakerId"`
+	SpeakerName   string     `json:"speakerName,omitempty"`
+	Entries       int        `json:"entries"` // persisted transcript entries
+	FirstSpokenAt *time.Time `json:"firstSpokenAt,omitempty"`
+	LastSpokenAt  *time.Time `json:"lastSpokenAt,omitempty"`
+}
+
+// SpeakerRename counts what a rename rewrote
+type SpeakerRename struct {
+	TranscriptEntries int64 `json:"transcriptEntries"`
+	Chunks            int64 `json:"chunks"`
+}
+
+// ListMeetingSpeakers returns the speakers that have a name mapping or have
+// spoken in a meeting's persisted transcript, in order of first speech;
+// named speakers who never spoke come last
+func ListMeetingSpeakers(meetingID string) ([]MeetingSpeaker, error) {
+	rows, err := DB.Query(`
+		WITH spoken AS (
+			SELECT speaker_id, COUNT(*) AS entries, MIN(spoken_at) AS first_spoken, MAX(spoken_at) AS last_spoken
+			FROM meeting_transcript_entries
+			WHERE meeting_id = $1 AND speaker_id IS NOT NULL AND speaker_id <> ''
+			GROUP BY speaker_id
+		)
+		SELECT COALESCE(s.speaker_id, m.speaker_id), m.speaker_name,
+			COALESCE(s.entries, 0), s.first_spoken, s.last_spoken
+		FROM spoken s
+		FULL OUTER JOIN (
+			SELECT speaker_id, speaker_name FROM speaker_mappings WHERE meeting_id = $1
+		) m ON m.speaker_id = s.speaker_id
+		ORDER BY s.first_spoken NULLS LAST, 1
+	`, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meeting speakers: %w", err)
+	}
+	defer rows.Close()
+
+	speakers := []MeetingSpeaker{}
+	for rows.Next() {
+		var speaker MeetingSpeaker
+		var name sql.NullString
+		var first, last sql.NullTime
+		if err := rows.Scan(&speaker.SpeakerID, &name, &speaker.Entries, &first, &last); err != nil {
+			return nil, fmt.Errorf("failed to scan meeting speaker: %w", err)
+		}
+		speaker.SpeakerName = name.String
+		if first.Valid {
+			speaker.FirstSpokenAt = &first.Time
+		}
+		if last.Valid {
+			speaker.LastSpokenAt = &last.Time
+		}
+		speakers = append(speakers, speaker)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list meeting speakers: %w", err)
+	}
+	return speakers, nil
+}
+
+// RenameMeetingSpeaker sets a speaker's name and rewrites it into what was
+// already stored: the meeting's transcript entries and the speaker fields
+// and transcript lines of its RAG chunks. Chunks only record names, so a
+// previous name is replaced in the chunks whose time span (the time of day
+// of their snapshot lines) holds one of the speaker's entries; chunks without
+// offsets are renamed only when no other speaker went by that name. Chunk
+// embeddings are left as they are.
+func RenameMeetingSpeaker(meetingID, speakerID, speakerName string) (*SpeakerRename, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var previous []string
+	err = tx.QueryRow(`
+		SELECT COALESCE(ARRAY_AGG(DISTINCT name) FILTER (WHERE name IS NOT NULL AND name <> '' AND name <> $3), '{}')
+		FROM (
+			SELECT speaker_name AS name FROM meeting_transcript_entries WHERE meeting_id = $1 AND speaker_id = $2
+			UNION
+			SELECT speaker_name FROM speaker_mappings WHERE meeting_id = $1 AND speaker_id = $2
+		) names
+	`, meetingID, speakerID, speakerName).Scan(pq.Array(&previous))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load previous speaker names: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO speaker_mappings (meeting_id, speaker_id, speaker_name)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (meeting_id, speaker_id)
+		DO UPDATE SET speaker_name = EXCLUDED.speaker_name
+	`, meetingID, speakerID, speakerName); err != nil {
+		return nil, fmt.Errorf("failed to set speaker name: %w", err)
+	}
+
+	rename := &SpeakerRename{}
+	result, err := tx.Exec(`
+		UPDATE meeting_transcript_entries SET speaker_name = $3
+		WHERE meeting_id = $1 AND speaker_id = $2 AND speaker_name IS DISTINCT FROM $3
+	`, meetingID, speakerID, speakerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename speaker in transcript: %w", err)
+	}
+	if rename.TranscriptEntries, err = result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to rename speaker in transcript: %w", err)
+	}
+
+	for _, old := range previous {
+		result, err := tx.Exec(`
+			UPDATE meeting_chunks c SET
+				speaker_name = CASE WHEN c.speaker_name = $2 THEN $3 ELSE c.speaker_name END,
+				speaker_names = array_replace(c.speaker_names, $2, $3),
+				chunk_text = replace(c.chunk_text, '] ' || $2 || ': ', '] ' || $3 || ': ')
+			WHERE c.meeting_id = $1 AND (c.speaker_name = $2 OR $2 = ANY(c.speaker_names))
+			  AND (
+				EXISTS (
+					SELECT 1 FROM meeting_transcript_entries e
+					WHERE e.meeting_id = $1 AND e.speaker_id = $4
+					  AND FLOOR(EXTRACT(EPOCH FROM e.spoken_at::time)) BETWEEN c.start_offset_seconds AND c.end_offset_seconds
+				)
+				OR (c.start_offset_seconds IS NULL AND NOT EXISTS (
+					SELECT 1 FROM meeting_transcript_entries e
+					WHERE e.meeting_id = $1 AND e.speaker_name = $2 AND e.speaker_id IS DISTINCT FROM $4
+				))
+			  )
+		`, meetingID, old, speakerName, speakerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rename speaker in chunks: %w", err)
+		}
+		chunks, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rename speaker in chunks: %w", err)
+		}
+		rename.Chunks += chunks
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit speaker rename: %w", err)
+	}
+	return rename, nil
+}
@@ -0,0 +1,258 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Tag kinds
+const (
+	TagKindTag    = "tag"
+	TagKindFolder = "folder" // an item sits in at most one folder
+)
+
+// Tagged item types; video, audio and streaming are history sessions
+const (
+	TagItemVideo     = "video"
+	TagItemAudio     = "audio"
+	TagItemStreaming = "streaming"
+	TagItemMeeting   = "meeting"
+)
+
+// ErrTagExists is returned when a user already has a tag or folder with the name
+var ErrTagExists = errors.New("a tag with this name already exists")
+
+// Tag is a user-defined label or folder for history items
+type Tag struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Color     string    `json:"color,omitempty"`
+	ItemCount int       `json:"itemCount"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ItemTag is a tag as attached to a history item
+type ItemTag struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Kind  string `json:"kind"`
+	Color string `json:"color,omitempty"`
+}
+
+// ListUserTags returns the user's tags and folders with how many items each holds
+func ListUserTags(userID int, kind string) ([]Tag, error) {
+	rows, err := DB.Query(`
+		SELECT t.id, t.name, t.kind, COALESCE(t.color, ''), t.created_at, t.updated_at,
+		       (SELECT COUNT(*) FROM session_tags st WHERE st.tag_id = t.id) +
+		       (SELECT COUNT(*) FROM meeting_tags mt WHERE mt.tag_id = t.id) AS item_count
+		FROM user_tags t
+		WHERE t.user_id = $1 AND ($2 = '' OR t.kind = $2)
+		ORDER BY t.kind, LOWER(t.name)
+	`, userID, kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Kind, &tag.Color, &tag.CreatedAt, &tag.UpdatedAt, &tag.ItemCount); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// GetUserTag returns one of the user's tags, or nil if it does not exist or
+// belongs to someone else
+func GetUserTag(userID, tagID int) (*Tag, error) {
+	var tag Tag
+	err := DB.QueryRow(`
+		SELECT id, name, kind, COALESCE(color, ''), created_at, updated_at
+		FROM user_tags
+		WHERE id = $1 AND user_id = $2
+	`, tagID, userID).Scan(&tag.ID, &tag.Name, &tag.Kind, &tag.Color, &tag.CreatedAt, &tag.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return &tag, nil
+}
+
+// CreateUserTag stores a new tag or folder and fills in its ID and timestamps
+func CreateUserTag(userID int, tag *Tag) error {
+	err := DB.QueryRow(`
+		INSERT INTO user_tags (user_id, name, kind, color)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING id, created_at, updated_at
+	`, userID, tag.Name, tag.Kind, tag.Color).Scan(&tag.ID, &tag.CreatedAt, &tag.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrTagExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+	return nil
+}
+
+// UpdateUserTag renames or recolors a tag; its kind cannot change
+func UpdateUserTag(userID int, tag *Tag) error {
+	err := DB.QueryRow(`
+		UPDATE user_tags
+		SET name = $3, color = NULLIF($4, ''), updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING updated_at
+	`, tag.ID, userID, tag.Name, tag.Color).Scan(&tag.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrTagExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update tag: %w", err)
+	}
+	return nil
+}
+
+// DeleteUserTag removes a tag and detaches it from every item
+func DeleteUserTag(userID, tagID int) error {
+	if _, err := DB.Exec(`DELETE FROM user_tags WHERE id = $1 AND user_id = $2`, tagID, userID); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return nil
+}
+
+// TagItem attaches a tag to a session or meeting. Filing an item into a
+// folder moves it out of the user's other folders.
+func TagItem(userID int, tag *Tag, itemType, itemID string) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if itemType == TagItemMeeting {
+		if tag.Kind == TagKindFolder {
+			if _, err := tx.Exec(`
+				DELETE FROM meeting_tags
+				WHERE meeting_id = $1 AND tag_id IN (SELECT id FROM user_tags WHERE user_id = $2 AND kind = $3)
+			`, itemID, userID, TagKindFolder); err != nil {
+				return fmt.Errorf("failed to clear meeting folder: %w", err)
+			}
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO meeting_tags (tag_id, meeting_id) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, tag.ID, itemID); err != nil {
+			return fmt.Errorf("failed to tag meeting: %w", err)
+		}
+	} else {
+		if tag.Kind == TagKindFolder {
+			if _, err := tx.Exec(`
+				DELETE FROM session_tags
+				WHERE session_type = $1 AND session_id = $2
+				  AND tag_id IN (SELECT id FROM user_tags WHERE user_id = $3 AND kind = $4)
+			`, itemType, itemID, userID, TagKindFolder); err != nil {
+				return fmt.Errorf("failed to clear session folder: %w", err)
+			}
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO session_tags (tag_id, session_type, session_id) VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING
+		`, tag.ID, itemType, itemID); err != nil {
+			return fmt.Errorf("failed to tag session: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UntagItem detaches a tag from a session or meeting
+func UntagItem(tagID int, itemType, itemID string) error {
+	var err error
+	if itemType == TagItemMeeting {
+		_, err = DB.Exec(`DELETE FROM meeting_tags WHERE tag_id = $1 AND meeting_id = $2`, tagID, itemID)
+	} else {
+		_, err = DB.Exec(`DELETE FROM session_tags WHERE tag_id = $1 AND session_type = $2 AND session_id = $3`,
+			tagID, itemType, itemID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to untag item: %w", err)
+	}
+	return nil
+}
+
+// getMeetingTagsBulk returns the user's tags on each meeting, keyed by meeting ID
+func getMeetingTagsBulk(userID int, meetingIDs []string) (map[string][]ItemTag, error) {
+	result := make(map[string][]ItemTag)
+	if len(meetingIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := DB.Query(`
+		SELECT mt.meeting_id, t.id, t.name, t.kind, COALESCE(t.color, '')
+		FROM meeting_tags mt
+		JOIN user_tags t ON t.id = mt.tag_id
+		WHERE t.user_id = $1 AND mt.meeting_id = ANY($2)
+		ORDER BY t.kind, LOWER(t.name)
+	`, userID, pq.Array(meetingIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var meetingID string
+		var tag ItemTag
+		if err := rows.Scan(&meetingID, &tag.ID, &tag.Name, &tag.Kind, &tag.Color); err != nil {
+			return nil, err
+		}
+		result[meetingID] = append(result[meetingID], tag)
+	}
+	return result, rows.Err()
+}
+
+// getSessionTagsBulk returns the user's tags on each session, keyed by
+// "type:sessionId"
+func getSessionTagsBulk(userID int, sessionIDs []string) (map[string][]ItemTag, error) {
+	result := make(map[string][]ItemTag)
+	if len(sessionIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := DB.Query(`
+		SELECT st.session_type, st.session_id, t.id, t.name, t.kind, COALESCE(t.color, '')
+		FROM session_tags st
+		JOIN user_tags t ON t.id = st.tag_id
+		WHERE t.user_id = $1 AND st.session_id = ANY($2)
+		ORDER BY t.kind, LOWER(t.name)
+	`, userID, pq.Array(sessionIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sessionType, sessionID string
+		var tag ItemTag
+		if err := rows.Scan(&sessionType, &sessionID, &tag.ID, &tag.Name, &tag.Kind, &tag.Color); err != nil {
+			return nil, err
+		}
+		key := sessionType + ":" + sessionID
+		result[key] = append(result[key], tag)
+	}
+	return result, rows.Err()
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MeetingTextFilter is a meeting's caption redaction policy
+type MeetingTextFilter struct {
+	MeetingID         string    `json:"meetingId"`
+	Mode              string    `json:"mode"`      // mask or remove
+	Profanity         string    `json:"profanity"` // off, standard or kid_safe
+	RedactEmails      bool      `json:"redactEmails"`
+	RedactCardNumbers bool      `json:"redactCardNumbers"`
+	Terms             []string  `json:"terms"` // names and other words to redact
+	UpdatedBy         *int      `json:"updatedBy,omitempty"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// GetMeetingTextFilter returns a meeting's redaction policy, or nil when it
+// has none
+func GetMeetingTextFilter(meetingID string) (*MeetingTextFilter, error) {
+	var filter MeetingTextFilter
+	var updatedBy sql.NullInt64
+	err := DB.QueryRow(`
+		SELECT meeting_id, mode, profanity, redact_emails, redact_card_numbers, terms, updated_by, updated_at
+		FROM meeting_text_filters
+		WHERE meeting_id = $1
+	`, meetingID).Scan(&filter.MeetingID, &filter.Mode, &filter.Profanity, &filter.RedactEmails,
+		&filter.RedactCardNumbers, pq.Array(&filter.Terms), &updatedBy, &filter.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meeting text filter: %w", err)
+	}
+	if filter.Terms == nil {
+		filter.Terms = []string{}
+	}
+	filter.UpdatedBy = nullIntPtr(updatedBy)
+	return &filter, nil
+}
+
+// SetMeetingTextFilter creates or replaces a meeting's redaction policy
+func SetMeetingTextFilter(filter *MeetingTextFilter) error {
+	terms := filter.Terms
+	if terms == nil {
+		terms = []string{}
+	}
+	err := DB.QueryRow(`
+		INSERT INTO meeting_text_filters (meeting_id, mode, profanity, redact_emails, redact_card_numbers, terms, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (meeting_id) DO UPDATE SET
+			mode = EXCLUDED.mode,
+			profanity = EXCLUDED.profanity,
+			redact_emails = EXCLUDED.redact_emails,
+			redact_card_numbers = EXCLUDED.redact_card_numbers,
+			terms = EXCLUDED.terms,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, filter.MeetingID, filter.Mode, filter.Profanity, filter.RedactEmails, filter.RedactCardNumbers,
+		pq.Array(terms), filter.UpdatedBy).Scan(&filter.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set meeting text filter: %w", err)
+	}
+	return nil
+}
+
+// DeleteMeetingTextFilter removes a meeting's redaction policy
+func DeleteMeetingTextFilter(meetingID string) error {
+	if _, err := DB.Exec(`DELETE FROM meeting_text_filters WHERE meeting_id = $1`, meetingID); err != nil {
+		return fmt.Errorf("failed to delete meeting text filter: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"realtime-caption-translator/internal/langcode"
+)
+
+// MeetingTranscriptEntry is a final caption persisted while the meeting runs.
+// Offsets are seconds from the meeting's creation.
+type MeetingTranscriptEntry struct {
+	ID            int64             `json:"id"`
+	MeetingID     string            `json:"meetingId"`
+	EventSeq      int64             `json:"eventSeq,omitempty"`
+	ParticipantID int               `json:"participantId,omitempty"`
+	SpeakerID     string            `json:"speakerId,omitempty"`
+	SpeakerName   string            `json:"speakerName,omitempty"`
+	Language      string            `json:"language,omitempty"`
+	Text          string            `json:"text"`
+	Translations  map[string]string `json:"translations,omitempty"`
+	SpokenAt      time.Time         `json:"spokenAt"`
+	StartOffset   float64           `json:"startOffset"`
+	EndOffset     float64           `json:"endOffset"`
+	AudioRef      int64             `json:"audioRef,omitempty"`
+	SegmentID     string            `json:"segmentId,omitempty"` // the caption's segmentId in the meeting
+
+	// A corrected entry keeps the text ASR produced in OriginalText
+	OriginalText string     `json:"originalText,omitempty"`
+	CorrectedAt  *time.Time `json:"correctedAt,omitempty"`
+}
+
+// transcriptEntryColumns are the columns scanTranscriptEntry reads
+const transcriptEntryColumns = `id, meeting_id, event_seq, participant_id, speaker_id, speaker_name, language, text,
+	translations, spoken_at, start_offset_seconds, end_offset_seconds, audio_ref, segment_id, original_text, corrected_at`
+
+// SaveMeetingTranscriptEntry stores an entry, computing its offsets from the
+// meeting's creation time. endedAt is when the speech ended.
+func SaveMeetingTranscriptEntry(entry *MeetingTranscriptEntry, endedAt time.Time) error {
+	entry.Language = langcode.Normalize(entry.Language)
+
+	var translations interface{}
+	if len(entry.Translations) > 0 {
+		data, err := json.Marshal(entry.Translations)
+		if err != nil {
+			return fmt.Errorf("failed to encode translations: %w", err)
+		}
+		translations = data
+	}
+	var participantID, eventSeq, audioRef interface{}
+	if entry.ParticipantID != 0 {
+		participantID = entry.ParticipantID
+	}
+	if entry.EventSeq != 0 {
+		eventSeq = entry.EventSeq
+	}
+	if entry.AudioRef != 0 {
+		audioRef = entry.AudioRef
+	}
+
+	err := DB.QueryRow(`
+		INSERT INTO meeting_transcript_entries
+			(meeting_id, event_seq, participant_id, speaker_id, speaker_name, language, text, translations,
+			 spoken_at, start_offset_seconds, end_offset_seconds, audio_ref, segment_id)
+		SELECT m.id, $2, $3, $4, $5, $6, $7, $8, $9,
+			GREATEST(EXTRACT(EPOCH FROM ($9::timestamp - m.created_at)), 0),
+			GREATEST(EXTRACT(EPOCH FROM ($10::timestamp - m.created_at)), 0),
+			$11, $12
+		FROM meetings m WHERE m.id = $1
+		RETURNING id, start_offset_seconds, end_offset_seconds
+	`, entry.MeetingID, eventSeq, participantID, nullString(entry.SpeakerID), nullString(entry.SpeakerName),
+		nullString(entry.Language), entry.Text, translations, entry.SpokenAt.UTC(), endedAt.UTC(), audioRef,
+		nullString(entry.SegmentID)).
+		Scan(&entry.ID, &entry.StartOffset, &entry.EndOffset)
+	if err != nil {
+		return fmt.Errorf("failed to save meeting transcript entry: %w", err)
+	}
+	return nil
+}
+
+// TranscriptEntryFilter selects a page of a meeting's transcript entries;
+// empty fields match every entry
+type TranscriptEntryFilter struct {
+	AfterID  int64 // entries with a larger ID; 0 starts from the beginning
+	Limit    int
+	Speaker  string   // speaker ID, or speaker name in any case
+	Language string   // source language
+	From     *float64 // entries ending at or after this offset (seconds)
+	To       *float64 // entries starting at or before this offset (seconds)
+}
+
+// ListMeetingTranscriptEntries returns a meeting's entries in the order they
+// were spoken
+func ListMeetingTranscriptEntries(meetingID string, filter TranscriptEntryFilter) ([]MeetingTranscriptEntry, error) {
+	query := `
+		SELECT ` + transcriptEntryColumns + `
+		FROM meeting_transcript_entries
+		WHERE meeting_id = $1 AND id > $2`
+	args := []interface{}{meetingID, filter.AfterID}
+	if filter.Speaker != "" {
+		args = append(args, filter.Speaker)
+		query += fmt.Sprintf(" AND (speaker_id = $%d OR LOWER(speaker_name) = LOWER($%d))", len(args), len(args))
+	}
+	if language := langcode.Normalize(filter.Language); language != "" {
+		args = append(args, language)
+		query += fmt.Sprintf(" AND language = $%d", len(args))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		query += fmt.Sprintf(" AND end_offset_seconds >= $%d", len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		query += fmt.Sprintf(" AND start_offset_seconds <= $%d", len(args))
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY id LIMIT $%d", len(args))
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meeting transcript entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []MeetingTranscriptEntry{}
+	for rows.Next() {
+		entry, err := scanTranscriptEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+// GetMeetingTranscriptEntry returns one of a meeting's entries, or nil if
+// the meeting has no entry with that ID
+func GetMeetingTranscriptEntry(meetingID string, entryID int64) (*MeetingTranscriptEntry, error) {
+	entry, err := scanTranscriptEntry(DB.QueryRow(`
+		SELECT `+transcriptEntryColumns+`
+		FROM meeting_transcript_entries
+		WHERE meeting_id = $1 AND id = $2
+	`, meetingID, entryID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return entry, err
+}
+
+// CorrectMeetingTranscriptEntry replaces an entry's text and translations
+// with a correction. The text ASR produced is kept from the first
+// correction on. Returns nil if the meeting has no entry with that ID.
+func CorrectMeetingTranscriptEntry(meetingID string, entryID int64, text string, translations map[string]string, correctedBy *int) (*MeetingTranscriptEntry, error) {
+	var encoded interface{}
+	if len(translations) > 0 {
+		data, err := json.Marshal(translations)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode translations: %w", err)
+		}
+		encoded = data
+	}
+	entry, err := scanTranscriptEntry(DB.QueryRow(`
+		UPDATE meeting_transcript_entries SET
+			original_text = COALESCE(original_text, text),
+			text = $3,
+			translations = $4,
+			corrected_at = NOW(),
+			corrected_by = $5
+		WHERE meeting_id = $1 AND id = $2
+		RETURNING `+transcriptEntryColumns, meetingID, entryID, text, encoded, correctedBy))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to correct meeting transcript entry: %w", err)
+	}
+	return entry, nil
+}
+
+// scanTranscriptEntry reads the transcriptEntryColumns of a row
+func scanTranscriptEntry(row interface{ Scan(...interface{}) error }) (*MeetingTranscriptEntry, error) {
+	var entry MeetingTranscriptEntry
+	var eventSeq, participantID, audioRef sql.NullInt64
+	var speakerID, speakerName, language, segmentID, originalText sql.NullString
+	var correctedAt sql.NullTime
+	var translations []byte
+	if err := row.Scan(&entry.ID, &entry.MeetingID, &eventSeq, &participantID, &speakerID, &speakerName,
+		&language, &entry.Text, &translations, &entry.SpokenAt, &entry.StartOffset, &entry.EndOffset, &audioRef,
+		&segmentID, &originalText, &correctedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan meeting transcript entry: %w", err)
+	}
+	entry.EventSeq = eventSeq.Int64
+	entry.ParticipantID = int(participantID.Int64)
+	entry.AudioRef = audioRef.Int64
+	entry.SpeakerID = speakerID.String
+	entry.SpeakerName = speakerName.String
+	entry.Language = language.String
+	entry.SegmentID = segmentID.String
+	entry.OriginalText = originalText.String
+	if correctedAt.Valid {
+		entry.CorrectedAt = &correctedAt.Time
+	}
+	if len(translations) > 0 {
+		if err := json.Unmarshal(translations, &entry.Translations); err != nil {
+			return nil, fmt.Errorf("failed to decode translations: %w", err)
+		}
+	}
+	return &entry, nil
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// OrgTTSPolicy restricts speech synthesis for an org; OrgID "" is the
+// server-wide default
+type OrgTTSPolicy struct {
+	OrgID             string    `json:"orgId"`
+	AllowedVoices     []string  `json:"allowedVoices"`
+	DefaultVoice      string    `json:"defaultVoice,omitempty"`
+	AllowVoiceCloning bool      `json:"allowVoiceCloning"`
+	ProfanityFilter   string    `json:"profanityFilter"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// ListOrgTTSPolicies returns every TTS policy
+func ListOrgTTSPolicies() ([]OrgTTSPolicy, error) {
+	rows, err := DB.Query(`
+		SELECT org_id, COALESCE(allowed_voices, '{}'), COALESCE(default_voice, ''),
+		       allow_voice_cloning, profanity_filter, updated_at
+		FROM org_tts_policies
+		ORDER BY org_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tts policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []OrgTTSPolicy{}
+	for rows.Next() {
+		var p OrgTTSPolicy
+		if err := rows.Scan(&p.OrgID, pq.Array(&p.AllowedVoices), &p.DefaultVoice,
+			&p.AllowVoiceCloning, &p.ProfanityFilter, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tts policy: %w", err)
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// SetOrgTTSPolicy creates or replaces an org's TTS policy
+func SetOrgTTSPolicy(policy *OrgTTSPolicy) error {
+	voices := policy.AllowedVoices
+	if voices == nil {
+		voices = []string{}
+	}
+	err := DB.QueryRow(`
+		INSERT INTO org_tts_policies (org_id, allowed_voices, default_voice, allow_voice_cloning, profanity_filter)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+		ON CONFLICT (org_id) DO UPDATE SET
+			allowed_voices = EXCLUDED.allowed_voices,
+			default_voice = EXCLUDED.default_voice,
+			allow_voice_cloning = EXCLUDED.allow_voice_cloning,
+			profanity_filter = EXCLUDED.profanity_filter,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, policy.OrgID, pq.Array(voices), policy.DefaultVoice, policy.AllowVoiceCloning, policy.ProfanityFilter).
+		Scan(&policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set tts policy: %w", err)
+	}
+	return nil
+}
+
+// DeleteOrgTTSPolicy removes an org's policy so the default applies
+func DeleteOrgTTSPolicy(orgID string) error {
+	if _, err := DB.Exec(`DELETE FROM org_tts_policies WHERE org_id = $1`, orgID); err != nil {
+		return fmt.Errorf("failed to delete tts policy: %w", err)
+	}
+	return nil
+}
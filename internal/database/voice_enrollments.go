@@ -0,0 +1,128 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// VoiceEnrollment is a user's enrolled voice print
+type VoiceEnrollment struct {
+	UserID        int       `json:"userId"`
+	DisplayName   string    `json:"displayName,omitempty"` // name matched speakers are shown under
+	Embedding     []float32 `json:"-"`
+	SampleSeconds float64   `json:"sampleSeconds"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// VoiceMatch is the enrolled user closest to a speaker embedding
+type VoiceMatch struct {
+	UserID     int
+	Name       string
+	Similarity float64
+}
+
+// SaveVoiceEnrollment stores or replaces a user's voice print
+func SaveVoiceEnrollment(userID int, embedding []float32, sampleSeconds float64, displayName string) (*VoiceEnrollment, error) {
+	enrollment := &VoiceEnrollment{UserID: userID, DisplayName: displayName, Embedding: embedding, SampleSeconds: sampleSeconds}
+	err := DB.QueryRow(`
+		INSERT INTO voice_enrollments (user_id, voice_print, sample_seconds, display_name)
+		VALUES ($1, $2::vector, $3, NULLIF($4, ''))
+		ON CONFLICT (user_id)
+		DO UPDATE SET voice_print = EXCLUDED.voice_print, sample_seconds = EXCLUDED.sample_seconds,
+			display_name = EXCLUDED.display_name, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`, userID, embeddingToString(embedding), sampleSeconds, displayName).Scan(&enrollment.CreatedAt, &enrollment.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save voice enrollment: %w", err)
+	}
+	return enrollment, nil
+}
+
+// GetVoiceEnrollment returns a user's enrollment, or nil if they have none
+func GetVoiceEnrollment(userID int) (*VoiceEnrollment, error) {
+	var enrollment VoiceEnrollment
+	var voicePrint string
+	var displayName sql.NullString
+	var sampleSeconds sql.NullFloat64
+
+	err := DB.QueryRow(`
+		SELECT user_id, voice_print::text, display_name, sample_seconds, created_at, updated_at
+		FROM voice_enrollments
+		WHERE user_id = $1
+	`, userID).Scan(&enrollment.UserID, &voicePrint, &displayName, &sampleSeconds, &enrollment.CreatedAt, &enrollment.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get voice enrollment: %w", err)
+	}
+
+	if enrollment.Embedding, err = parseEmbedding(voicePrint); err != nil {
+		return nil, fmt.Errorf("failed to parse voice print: %w", err)
+	}
+	enrollment.DisplayName = displayName.String
+	enrollment.SampleSeconds = sampleSeconds.Float64
+	return &enrollment, nil
+}
+
+// DeleteVoiceEnrollment removes a user's enrollment; it returns false if there was none
+func DeleteVoiceEnrollment(userID int) (bool, error) {
+	result, err := DB.Exec(`DELETE FROM voice_enrollments WHERE user_id = $1`, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete voice enrollment: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete voice enrollment: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// MatchMeetingVoice returns the enrolled user whose voice print is closest to
+// a speaker embedding, or nil when none reaches minSimilarity. Candidates
+// are the meeting's owner, the users it is shared with and its signed-in
+// participants, so someone can be recognized through another participant's
+// microphone without joining on a device of their own. Matches are named by
+// the enrollment's display name, else as the user last joined the meeting,
+// else by their account name. Voice prints from a model with a different
+// embedding size are skipped.
+func MatchMeetingVoice(meetingID string, embedding []float32, minSimilarity float64) (*VoiceMatch, error) {
+	var match VoiceMatch
+	err := DB.QueryRow(`
+		WITH candidates AS (
+			SELECT user_id FROM meeting_participants WHERE meeting_id = $1 AND user_id IS NOT NULL
+			UNION
+			SELECT user_id FROM meeting_access_control WHERE meeting_id = $1 AND removed_at IS NULL
+			UNION
+			SELECT created_by FROM meetings WHERE id = $1 AND created_by IS NOT NULL
+		)
+		SELECT v.user_id,
+			COALESCE(
+				NULLIF(v.display_name, ''),
+				(SELECT p.participant_name FROM meeting_participants p
+				 WHERE p.meeting_id = $1 AND p.user_id = v.user_id
+				 ORDER BY p.joined_at DESC LIMIT 1),
+				NULLIF(u.display_name, ''),
+				u.username
+			),
+			1 - (v.voice_print <=> $2::vector) AS similarity
+		FROM voice_enrollments v
+		JOIN candidates c ON c.user_id = v.user_id
+		JOIN users u ON u.id = v.user_id
+		WHERE vector_dims(v.voice_print) = vector_dims($2::vector)
+		ORDER BY v.voice_print <=> $2::vector
+		LIMIT 1
+	`, meetingID, embeddingToString(embedding)).Scan(&match.UserID, &match.Name, &match.Similarity)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to match voice print: %w", err)
+	}
+	if match.Similarity < minSimilarity {
+		return nil, nil
+	}
+	return &match, nil
+}
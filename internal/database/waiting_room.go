@@ -0,0 +1,56 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Participant admission states of waiting-room meetings
+const (
+	AdmissionWaiting  = "waiting"
+	AdmissionAdmitted = "admitted"
+	AdmissionRejected = "rejected"
+)
+
+// IsMeetingWaitingRoom reports whether new participants of a meeting wait to
+// be admitted
+func IsMeetingWaitingRoom(meetingID string) (bool, error) {
+	var enabled bool
+	err := DB.QueryRow(`SELECT waiting_room FROM meetings WHERE id = $1`, meetingID).Scan(&enabled)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check waiting room: %w", err)
+	}
+	return enabled, nil
+}
+
+// SetMeetingWaitingRoom turns a meeting's waiting room on or off
+func SetMeetingWaitingRoom(meetingID string, enabled bool) error {
+	if _, err := DB.Exec(`UPDATE meetings SET waiting_room = $2 WHERE id = $1`, meetingID, enabled); err != nil {
+		return fmt.Errorf("failed to set waiting room: %w", err)
+	}
+	return nil
+}
+
+// GetParticipantAdmission returns a participant's admission state
+func GetParticipantAdmission(participantID int) (string, error) {
+	var admission string
+	err := DB.QueryRow(`SELECT admission FROM meeting_participants WHERE id = $1`, participantID).Scan(&admission)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get participant admission: %w", err)
+	}
+	return admission, nil
+}
+
+// SetParticipantAdmission sets a participant's admission state
+func SetParticipantAdmission(participantID int, admission string) error {
+	if _, err := DB.Exec(`UPDATE meeting_participants SET admission = $2 WHERE id = $1`, participantID, admission); err != nil {
+		return fmt.Errorf("failed to set participant admission: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// ExtractText returns the plain text of a PDF, DOCX, Markdown or text file.
+// PDF extraction shells out to pdftotext (poppler-utils).
+func ExtractText(filename string, data []byte) (string, error) {
+	var (
+		text string
+		err  error
+	)
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".md", ".markdown", ".txt":
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("file is not valid UTF-8 text")
+		}
+		text = string(data)
+	case ".docx":
+		text, err = extractDOCX(data)
+	case ".pdf":
+		text, err = extractPDF(data)
+	default:
+		return "", fmt.Errorf("unsupported document type %q (supported: .pdf, .docx, .md, .txt)", filepath.Ext(filename))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("document contains no text")
+	}
+	return text, nil
+}
+
+// extractPDF converts a PDF to text with pdftotext reading from stdin
+func extractPDF(data []byte) (string, error) {
+	cmd := exec.Command("pdftotext", "-layout", "-", "-")
+	cmd.Stdin = bytes.NewReader(data)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("pdftotext error: %w, stderr: %s", err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
+
+// extractDOCX reads word/document.xml from a DOCX archive, emitting one line
+// per paragraph
+func extractDOCX(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("open docx: %w", err)
+	}
+
+	for _, file := range archive.File {
+		if file.Name != "word/document.xml" {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return "", fmt.Errorf("open document.xml: %w", err)
+		}
+		defer rc.Close()
+
+		return docxText(rc)
+	}
+
+	return "", fmt.Errorf("docx missing word/document.xml")
+}
+
+func docxText(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+	var builder strings.Builder
+	inText := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("parse document.xml: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				builder.WriteString("\t")
+			case "br":
+				builder.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				builder.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				builder.Write(t)
+			}
+		}
+	}
+
+	return builder.String(), nil
+}
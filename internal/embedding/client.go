@@ -6,12 +6,37 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"realtime-caption-translator/internal/cache"
+	"realtime-caption-translator/internal/httpx"
+	"realtime-caption-translator/internal/ratelimit"
 )
 
+// DefaultMaxBatchSize is the number of texts sent in one batch request when
+// MaxBatchSize is unset
+const DefaultMaxBatchSize = 64
+
 // Client is an HTTP client for the embedding service
 type Client struct {
 	BaseURL string
 	HTTP    *http.Client
+
+	// MaxBatchSize bounds the texts of one batch request; EmbedBatch splits
+	// longer lists into several requests
+	MaxBatchSize int
+
+	// Limiter, when set, bounds request rate and concurrency; Priority
+	// decides how this client's calls queue against other callers
+	Limiter  *ratelimit.Limiter
+	Priority ratelimit.Priority
+
+	// Breaker, when set, retries transient failures and stops calling the
+	// service while it is unhealthy
+	Breaker *httpx.Breaker
+
+	// Cache, when set, memoizes embeddings by text
+	Cache       *cache.TTL[string, []float32]
+	bypassCache bool
 }
 
 // New creates a new embedding service client
@@ -24,6 +49,22 @@ func New(baseURL string) *Client {
 	}
 }
 
+// WithPriority returns a copy of the client that queues at the given priority
+// while sharing the same HTTP client, limiter and breaker
+func (c *Client) WithPriority(priority ratelimit.Priority) *Client {
+	clone := *c
+	clone.Priority = priority
+	return &clone
+}
+
+// WithoutCache returns a copy of the client that skips cache lookups but still
+// refreshes the cache with new results
+func (c *Client) WithoutCache() *Client {
+	clone := *c
+	clone.bypassCache = true
+	return &clone
+}
+
 // EmbedRequest represents a request to embed a single text
 type EmbedRequest struct {
 	Text string `json:"text"`
@@ -49,17 +90,22 @@ type EmbedBatchResponse struct {
 
 // Embed generates an embedding for a single text
 func (c *Client) Embed(text string) ([]float32, error) {
+	if !c.bypassCache {
+		if cached, ok := c.Cache.Get(text); ok {
+			return cached, nil
+		}
+	}
+
 	reqBody := EmbedRequest{Text: text}
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.HTTP.Post(
-		c.BaseURL+"/embed",
-		"application/json",
-		bytes.NewReader(jsonData),
-	)
+	release := c.Limiter.Acquire(c.Priority)
+	defer release()
+
+	resp, err := c.post("/embed", jsonData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -74,22 +120,71 @@ func (c *Client) Embed(text string) ([]float32, error) {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	c.Cache.Set(text, result.Embedding)
 	return result.Embedding, nil
 }
 
-// EmbedBatch generates embeddings for multiple texts (more efficient than calling Embed multiple times)
+// BatchSize returns the number of texts sent in one batch request
+func (c *Client) BatchSize() int {
+	if c.MaxBatchSize > 0 {
+		return c.MaxBatchSize
+	}
+	return DefaultMaxBatchSize
+}
+
+// EmbedBatch generates embeddings for multiple texts (more efficient than calling Embed multiple times).
+// Cached texts are served locally and only the misses are sent to the service,
+// in requests of at most BatchSize texts. Fails if any request fails.
 func (c *Client) EmbedBatch(texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	var missing []string
+	var missingIdx []int
+	for i, text := range texts {
+		if !c.bypassCache {
+			if cached, ok := c.Cache.Get(text); ok {
+				embeddings[i] = cached
+				continue
+			}
+		}
+		missing = append(missing, text)
+		missingIdx = append(missingIdx, i)
+	}
+	if len(missing) == 0 {
+		return embeddings, nil
+	}
+
+	size := c.BatchSize()
+	for start := 0; start < len(missing); start += size {
+		end := min(start+size, len(missing))
+		fetched, err := c.embedBatchRemote(missing[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if len(fetched) != end-start {
+			return nil, fmt.Errorf("embedding service returned %d embeddings for %d texts", len(fetched), end-start)
+		}
+
+		for j, embedding := range fetched {
+			embeddings[missingIdx[start+j]] = embedding
+			c.Cache.Set(missing[start+j], embedding)
+		}
+	}
+
+	return embeddings, nil
+}
+
+// embedBatchRemote sends texts to the embedding service's batch endpoint
+func (c *Client) embedBatchRemote(texts []string) ([][]float32, error) {
 	reqBody := EmbedBatchRequest{Texts: texts}
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.HTTP.Post(
-		c.BaseURL+"/embed-batch",
-		"application/json",
-		bytes.NewReader(jsonData),
-	)
+	release := c.Limiter.Acquire(c.Priority)
+	defer release()
+
+	resp, err := c.post("/embed-batch", jsonData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -106,3 +201,13 @@ func (c *Client) EmbedBatch(texts []string) ([][]float32, error) {
 
 	return result.Embeddings, nil
 }
+
+// post sends a JSON body to the service through the breaker
+func (c *Client) post(path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, c.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.Breaker.Do(req, c.HTTP.Do)
+}
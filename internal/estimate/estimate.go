@@ -0,0 +1,332 @@
+// Package estimate predicts how long an upload will take to process, how long
+// it will wait in the job queue and how much ASR, translation and TTS it will
+// consume, so users can decide before submitting a long file. Predictions use
+// per-stage real-time factors that are corrected by observed uploads.
+package estimate
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Upload kinds
+const (
+	KindVideo = "video"
+	KindAudio = "audio"
+)
+
+// Request describes the media and the processing options to estimate
+type Request struct {
+	Kind            string   `json:"kind"` // "video" or "audio"
+	DurationSeconds float64  `json:"durationSeconds"`
+	SourceLang      string   `json:"sourceLang,omitempty"`
+	TargetLangs     []string `json:"targetLangs,omitempty"`
+	Diarization     bool     `json:"diarization,omitempty"`
+	EnhanceAudio    bool     `json:"enhanceAudio,omitempty"`
+	GenerateTTS     bool     `json:"generateTTS,omitempty"`
+	CloneVoice      bool     `json:"cloneVoice,omitempty"`
+	SegmentDubbing  bool     `json:"segmentDubbing,omitempty"`
+}
+
+// Usage is the service consumption of a request. Transcription is counted
+// once; translation and speech once per target language.
+type Usage struct {
+	ASRMinutes            float64 `json:"asrMinutes"`
+	TranslationCharacters int     `json:"translationCharacters"`
+	TTSMinutes            float64 `json:"ttsMinutes"`
+	ClonedVoiceMinutes    float64 `json:"clonedVoiceMinutes"`
+}
+
+// Estimate is the prediction for a request. Times are in seconds.
+type Estimate struct {
+	DurationSeconds   float64            `json:"durationSeconds"`
+	ProcessingSeconds float64            `json:"processingSeconds"`
+	QueueWaitSeconds  float64            `json:"queueWaitSeconds"`
+	TotalSeconds      float64            `json:"totalSeconds"`
+	QueuedJobs        int                `json:"queuedJobs"`
+	Stages            map[string]float64 `json:"stages"`
+	Usage             Usage              `json:"usage"`
+	Cost              *float64           `json:"cost,omitempty"`
+	Currency          string             `json:"currency,omitempty"`
+	Calibrated        bool               `json:"calibrated"` // observed uploads have corrected the model
+}
+
+// Rates are processing seconds per second of media unless noted
+type Rates struct {
+	Overhead        float64 // fixed seconds per upload (saving, storage, bookkeeping)
+	Decode          float64 // audio extraction / conversion
+	Enhancement     float64
+	ASR             float64
+	Diarization     float64 // on top of ASR
+	TranslationPerK float64 // seconds per 1000 characters
+	TTS             float64 // per second of speech
+	Cloning         float64 // on top of TTS
+	Mux             float64 // writing the dubbed video
+	CharsPerSecond  float64 // characters of transcript per second of speech
+}
+
+// DefaultRates are conservative CPU figures for the bundled services
+func DefaultRates() Rates {
+	return Rates{
+		Overhead:        5,
+		Decode:          0.02,
+		Enhancement:     0.1,
+		ASR:             0.3,
+		Diarization:     0.25,
+		TranslationPerK: 2,
+		TTS:             0.5,
+		Cloning:         0.5,
+		Mux:             0.05,
+		CharsPerSecond:  15,
+	}
+}
+
+// Prices convert usage into cost; all zero leaves cost out of estimates
+type Prices struct {
+	ASRMinute             float64
+	TranslationPer1KChars float64
+	TTSMinute             float64
+	ClonedVoiceMinute     float64
+	Currency              string
+}
+
+func (p Prices) zero() bool {
+	return p.ASRMinute == 0 && p.TranslationPer1KChars == 0 && p.TTSMinute == 0 && p.ClonedVoiceMinute == 0
+}
+
+// defaultJobSeconds is assumed for queued jobs until an upload has been observed
+const defaultJobSeconds = 300
+
+// Correction factors are a moving average of actual/predicted time, bounded
+// so one unusual upload cannot swing estimates far
+const (
+	correctionWeight = 0.2
+	minCorrection    = 0.25
+	maxCorrection    = 4
+)
+
+// Estimator predicts processing and learns from completed uploads
+type Estimator struct {
+	Rates   Rates
+	Prices  Prices
+	Workers int // job queue workers sharing the queued video jobs
+
+	mu         sync.Mutex
+	correction map[string]float64 // kind -> actual/predicted
+	jobSeconds map[string]float64 // kind -> average processing time
+}
+
+// New creates an estimator for a job queue with the given worker count
+func New(workers int, prices Prices) *Estimator {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Estimator{
+		Rates:      DefaultRates(),
+		Prices:     prices,
+		Workers:    workers,
+		correction: make(map[string]float64),
+		jobSeconds: make(map[string]float64),
+	}
+}
+
+// Estimate predicts a request. queuedJobs is the current job queue depth;
+// only video uploads go through the queue, audio starts immediately.
+func (e *Estimator) Estimate(req Request, queuedJobs int) Estimate {
+	req = normalize(req)
+	stages := e.stages(req)
+
+	e.mu.Lock()
+	correction, calibrated := e.correction[req.Kind]
+	jobSeconds, observed := e.jobSeconds[KindVideo]
+	e.mu.Unlock()
+	if !calibrated {
+		correction = 1
+	}
+	if !observed {
+		jobSeconds = defaultJobSeconds
+	}
+
+	var processing float64
+	for stage, seconds := range stages {
+		stages[stage] = round(seconds * correction)
+		processing += seconds * correction
+	}
+
+	estimate := Estimate{
+		DurationSeconds:   round(req.DurationSeconds),
+		ProcessingSeconds: round(processing),
+		Stages:            stages,
+		Usage:             e.usage(req),
+		Calibrated:        calibrated,
+	}
+	if req.Kind == KindVideo && queuedJobs > 0 {
+		estimate.QueuedJobs = queuedJobs
+		rounds := math.Ceil(float64(queuedJobs) / float64(e.Workers))
+		estimate.QueueWaitSeconds = round(rounds * jobSeconds)
+	}
+	estimate.TotalSeconds = round(estimate.ProcessingSeconds + estimate.QueueWaitSeconds)
+
+	if !e.Prices.zero() {
+		u := estimate.Usage
+		cost := u.ASRMinutes*e.Prices.ASRMinute +
+			float64(u.TranslationCharacters)/1000*e.Prices.TranslationPer1KChars +
+			u.TTSMinutes*e.Prices.TTSMinute +
+			u.ClonedVoiceMinutes*e.Prices.ClonedVoiceMinute
+		cost = math.Round(cost*100) / 100
+		estimate.Cost = &cost
+		estimate.Currency = e.Prices.Currency
+	}
+	return estimate
+}
+
+// Observe records how long a completed upload took and corrects later
+// estimates for its kind
+func (e *Estimator) Observe(req Request, elapsed time.Duration) {
+	req = normalize(req)
+	var predicted float64
+	for _, seconds := range e.stages(req) {
+		predicted += seconds
+	}
+	actual := elapsed.Seconds()
+	if predicted <= 0 || actual <= 0 {
+		return
+	}
+	ratio := math.Min(math.Max(actual/predicted, minCorrection), maxCorrection)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if previous, ok := e.correction[req.Kind]; ok {
+		ratio = previous + correctionWeight*(ratio-previous)
+	}
+	e.correction[req.Kind] = ratio
+	if previous, ok := e.jobSeconds[req.Kind]; ok {
+		actual = previous + correctionWeight*(actual-previous)
+	}
+	e.jobSeconds[req.Kind] = actual
+}
+
+// Model services that pipeline stages run on
+const (
+	ServiceASR         = "asr"
+	ServiceTranslation = "translation"
+	ServiceTTS         = "tts"
+)
+
+// pipelineOrder is the order stages run in, for charging elapsed time
+var pipelineOrder = []string{"upload", "processing", "transcription", "translation", "tts"}
+
+// serviceStages maps the stages that run on a model service to the service
+var serviceStages = map[string]string{
+	"transcription": ServiceASR,
+	"translation":   ServiceTranslation,
+	"tts":           ServiceTTS,
+}
+
+// ServiceSeconds returns the processing seconds a request still needs from
+// each model service, corrected like Estimate. elapsed is how long the job
+// has been running (0 for a queued job) and is charged to stages in pipeline
+// order, so a job past transcription no longer counts against ASR.
+func (e *Estimator) ServiceSeconds(req Request, elapsed time.Duration) map[string]float64 {
+	req = normalize(req)
+	stages := e.stages(req)
+
+	e.mu.Lock()
+	correction, calibrated := e.correction[req.Kind]
+	e.mu.Unlock()
+	if !calibrated {
+		correction = 1
+	}
+
+	remaining := map[string]float64{ServiceASR: 0, ServiceTranslation: 0, ServiceTTS: 0}
+	spent := elapsed.Seconds()
+	for _, stage := range pipelineOrder {
+		seconds := stages[stage] * correction
+		charged := math.Min(seconds, spent)
+		spent -= charged
+		if service, ok := serviceStages[stage]; ok {
+			remaining[service] += seconds - charged
+		}
+	}
+	return remaining
+}
+
+// stages returns uncorrected seconds per pipeline stage
+func (e *Estimator) stages(req Request) map[string]float64 {
+	r := e.Rates
+	d := req.DurationSeconds
+	languages := float64(len(req.TargetLangs))
+
+	stages := map[string]float64{
+		"upload":        r.Overhead,
+		"processing":    d * r.Decode,
+		"transcription": d * r.ASR,
+		"translation":   languages * d * r.CharsPerSecond / 1000 * r.TranslationPerK,
+	}
+	if req.EnhanceAudio {
+		stages["processing"] += d * r.Enhancement
+	}
+	if req.Diarization {
+		stages["transcription"] += d * r.Diarization
+	}
+	if req.GenerateTTS {
+		tts := d * r.TTS
+		if req.CloneVoice {
+			tts += d * r.Cloning
+		}
+		stages["tts"] = languages * tts
+		if req.Kind == KindVideo {
+			stages["processing"] += languages * d * r.Mux
+		}
+	}
+	return stages
+}
+
+func (e *Estimator) usage(req Request) Usage {
+	minutes := req.DurationSeconds / 60
+	languages := float64(len(req.TargetLangs))
+	usage := Usage{
+		ASRMinutes:            round(minutes),
+		TranslationCharacters: int(math.Round(languages * req.DurationSeconds * e.Rates.CharsPerSecond)),
+	}
+	if req.GenerateTTS {
+		usage.TTSMinutes = round(languages * minutes)
+		if req.CloneVoice {
+			usage.ClonedVoiceMinutes = usage.TTSMinutes
+		}
+	}
+	return usage
+}
+
+// normalize fills defaults: audio kind, one English target, no cloning without TTS
+func normalize(req Request) Request {
+	if req.Kind != KindVideo {
+		req.Kind = KindAudio
+	}
+	if req.DurationSeconds < 0 {
+		req.DurationSeconds = 0
+	}
+	var targets []string
+	seen := make(map[string]bool)
+	for _, lang := range req.TargetLangs {
+		lang = strings.TrimSpace(lang)
+		if lang != "" && !seen[lang] {
+			seen[lang] = true
+			targets = append(targets, lang)
+		}
+	}
+	if len(targets) == 0 {
+		targets = []string{"en"}
+	}
+	req.TargetLangs = targets
+	if !req.GenerateTTS {
+		req.CloneVoice = false
+	}
+	return req
+}
+
+func round(seconds float64) float64 {
+	return math.Round(seconds*10) / 10
+}
@@ -0,0 +1,256 @@
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// Params are the live pipeline parameters a variant can override. Zero values
+// keep the server defaults.
+type Params struct {
+	WindowSeconds   int     `json:"windowSeconds,omitempty"`
+	FinalizeAfterMs int     `json:"finalizeAfterMs,omitempty"`
+	VADThreshold    float64 `json:"vadThreshold,omitempty"` // RMS (0-1) below which a window is treated as silence
+}
+
+// FinalizeAfter returns the finalize delay as a duration
+func (p Params) FinalizeAfter() time.Duration {
+	return time.Duration(p.FinalizeAfterMs) * time.Millisecond
+}
+
+// Variant is one arm of an experiment
+type Variant struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+	Params
+}
+
+// Experiment assigns live sessions to weighted variants
+type Experiment struct {
+	Name     string    `json:"name"`
+	Variants []Variant `json:"variants"`
+
+	totalWeight int
+	mu          sync.Mutex
+	rng         *rand.Rand
+}
+
+// Load reads an experiment definition from a JSON file:
+//
+//	{"name": "window-size", "variants": [
+//	  {"name": "control", "weight": 1},
+//	  {"name": "w6-f700", "weight": 1, "windowSeconds": 6, "finalizeAfterMs": 700, "vadThreshold": 0.01}
+//	]}
+func Load(path string) (*Experiment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read experiment file: %w", err)
+	}
+
+	var exp Experiment
+	if err := json.Unmarshal(data, &exp); err != nil {
+		return nil, fmt.Errorf("failed to parse experiment file: %w", err)
+	}
+	if exp.Name == "" || len(exp.Variants) == 0 {
+		return nil, fmt.Errorf("experiment needs a name and at least one variant")
+	}
+
+	seen := make(map[string]bool)
+	for i := range exp.Variants {
+		v := &exp.Variants[i]
+		if v.Name == "" || seen[v.Name] {
+			return nil, fmt.Errorf("variant %d needs a unique name", i)
+		}
+		seen[v.Name] = true
+		if v.Weight <= 0 {
+			v.Weight = 1
+		}
+		if v.WindowSeconds < 0 || v.FinalizeAfterMs < 0 || v.VADThreshold < 0 {
+			return nil, fmt.Errorf("variant %s has negative parameters", v.Name)
+		}
+		exp.totalWeight += v.Weight
+	}
+	exp.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	return &exp, nil
+}
+
+// Assign picks a variant for a new session, weighted by variant weight.
+// A nil experiment assigns nothing.
+func (e *Experiment) Assign() *Variant {
+	if e == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	pick := e.rng.Intn(e.totalWeight)
+	e.mu.Unlock()
+
+	for i := range e.Variants {
+		pick -= e.Variants[i].Weight
+		if pick < 0 {
+			return &e.Variants[i]
+		}
+	}
+	return &e.Variants[len(e.Variants)-1]
+}
+
+// Recorder collects latency and stability proxies for one session. A nil
+// *Recorder ignores all observations, so the pipeline can call it
+// unconditionally.
+type Recorder struct {
+	experiment string
+	variant    string
+	params     Params
+
+	mu                sync.Mutex
+	startedAt         time.Time
+	audioStartedAt    time.Time
+	asrCalls          int
+	asrErrors         int
+	asrLatency        time.Duration
+	silentWindows     int
+	partialRevisions  int
+	finals            int
+	finalizeLatency   time.Duration
+	firstPartialDelay *time.Duration
+	segmentStart      time.Time
+	discarded         bool
+}
+
+// NewRecorder starts recording a session assigned to variant; params are the
+// effective values (server defaults merged with the variant's overrides)
+func (e *Experiment) NewRecorder(variant *Variant, params Params) *Recorder {
+	if e == nil || variant == nil {
+		return nil
+	}
+	return &Recorder{
+		experiment: e.Name,
+		variant:    variant.Name,
+		params:     params,
+		startedAt:  time.Now(),
+	}
+}
+
+// AudioStarted marks the moment the client started streaming audio
+func (r *Recorder) AudioStarted() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	if r.audioStartedAt.IsZero() {
+		r.audioStartedAt = time.Now()
+	}
+	r.mu.Unlock()
+}
+
+// ASRCall records one transcription request
+func (r *Recorder) ASRCall(latency time.Duration, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.asrCalls++
+	if err != nil {
+		r.asrErrors++
+	} else {
+		r.asrLatency += latency
+	}
+	r.mu.Unlock()
+}
+
+// SilentWindow records a window skipped by the VAD threshold
+func (r *Recorder) SilentWindow() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.silentWindows++
+	r.mu.Unlock()
+}
+
+// Partial records a changed partial transcript
+func (r *Recorder) Partial() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if r.firstPartialDelay == nil && !r.audioStartedAt.IsZero() {
+		delay := now.Sub(r.audioStartedAt)
+		r.firstPartialDelay = &delay
+	}
+	if r.segmentStart.IsZero() {
+		r.segmentStart = now
+	}
+	r.partialRevisions++
+}
+
+// Final records a finalized segment
+func (r *Recorder) Final() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.finals++
+	if !r.segmentStart.IsZero() {
+		r.finalizeLatency += time.Since(r.segmentStart)
+		r.segmentStart = time.Time{}
+	}
+}
+
+// Discard leaves the session out of the experiment, e.g. once the client
+// overrides the parameters its variant set
+func (r *Recorder) Discard() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.discarded = true
+	r.mu.Unlock()
+}
+
+// Finish stores the session's metrics. Sessions that never streamed audio
+// are dropped so idle connections do not skew the comparison.
+func (r *Recorder) Finish() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.audioStartedAt.IsZero() || r.discarded || database.DB == nil {
+		return
+	}
+
+	params, _ := json.Marshal(r.params)
+	record := &database.ExperimentSession{
+		Experiment:        r.experiment,
+		Variant:           r.variant,
+		Params:            params,
+		Duration:          time.Since(r.audioStartedAt),
+		ASRCalls:          r.asrCalls,
+		ASRErrors:         r.asrErrors,
+		ASRLatency:        r.asrLatency,
+		SilentWindows:     r.silentWindows,
+		PartialRevisions:  r.partialRevisions,
+		Finals:            r.finals,
+		FinalizeLatency:   r.finalizeLatency,
+		FirstPartialDelay: r.firstPartialDelay,
+		StartedAt:         r.startedAt,
+	}
+	if err := database.SaveExperimentSession(record); err != nil {
+		log.Printf("[Experiment] Failed to record session for %s/%s: %v", r.experiment, r.variant, err)
+	}
+}
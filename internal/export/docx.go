@@ -0,0 +1,115 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+</Types>`
+
+const docxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+// rtlLanguages are written right to left; their paragraphs are marked bidi
+var rtlLanguages = map[string]bool{"ar": true, "ur": true, "fa": true, "he": true}
+
+// renderDOCX writes a minimal WordprocessingML package. Formatting is applied
+// directly to runs so the file needs no styles part.
+func renderDOCX(doc Document) ([]byte, error) {
+	rtl := rtlLanguages[doc.Language]
+
+	var body strings.Builder
+	for _, blk := range doc.blocks() {
+		switch blk.kind {
+		case blockTitle:
+			body.WriteString(docxParagraph(rtl, 0, docxRun(blk.text, true, false, 36, rtl)))
+		case blockMeta:
+			body.WriteString(docxParagraph(rtl, 0, docxRun(blk.text, false, true, 20, false)))
+		case blockHeading:
+			body.WriteString(docxParagraph(rtl, 0, docxRun(blk.text, true, false, 30, false)))
+		case blockSubheading:
+			body.WriteString(docxParagraph(rtl, 0, docxRun(blk.text, true, false, 24, false)))
+		case blockParagraph:
+			body.WriteString(docxParagraph(rtl, 0, docxRun(blk.text, false, false, 22, rtl)))
+		case blockBullet:
+			body.WriteString(docxParagraph(rtl, 360, docxRun("• "+blk.text, false, false, 22, rtl)))
+		case blockEntry:
+			body.WriteString(docxParagraph(rtl, 0,
+				docxRun(blk.label+": ", true, false, 22, rtl)+docxRun(blk.text, false, false, 22, rtl)))
+		}
+	}
+
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		body.String() +
+		`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1134" w:right="1134" w:bottom="1134" w:left="1134" w:header="708" w:footer="708" w:gutter="0"/></w:sectPr></w:body></w:document>`
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRels},
+		{"word/document.xml", document},
+	} {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", part.name, err)
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish docx: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// docxParagraph wraps runs in a paragraph, indented by indent twips
+func docxParagraph(rtl bool, indent int, runs string) string {
+	var props strings.Builder
+	if rtl {
+		props.WriteString(`<w:bidi/>`)
+	}
+	if indent > 0 {
+		props.WriteString(fmt.Sprintf(`<w:ind w:left="%d"/>`, indent))
+	}
+	props.WriteString(`<w:spacing w:after="120"/>`)
+	return `<w:p><w:pPr>` + props.String() + `</w:pPr>` + runs + `</w:p>`
+}
+
+// docxRun formats text; size is in half-points. Line breaks become <w:br/>.
+func docxRun(text string, bold, italic bool, size int, rtl bool) string {
+	var props strings.Builder
+	if bold {
+		props.WriteString(`<w:b/>`)
+	}
+	if italic {
+		props.WriteString(`<w:i/>`)
+	}
+	if rtl {
+		props.WriteString(`<w:rtl/>`)
+	}
+	props.WriteString(fmt.Sprintf(`<w:sz w:val="%d"/><w:szCs w:val="%d"/>`, size, size))
+
+	var content strings.Builder
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			content.WriteString(`<w:br/>`)
+		}
+		content.WriteString(`<w:t xml:space="preserve">`)
+		xml.EscapeText(&content, []byte(line))
+		content.WriteString(`</w:t>`)
+	}
+	return `<w:r><w:rPr>` + props.String() + `</w:rPr>` + content.String() + `</w:r>`
+}
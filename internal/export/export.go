@@ -0,0 +1,208 @@
+// Package export renders meeting transcripts and minutes as plain text, DOCX
+// or PDF files for download. Output is deliberately simple: DOCX uses direct
+// run formatting and PDF uses the standard Courier fonts, or an embedded
+// TrueType font for scripts they do not cover.
+package export
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// Supported export formats
+const (
+	FormatTXT  = "txt"
+	FormatDOCX = "docx"
+	FormatPDF  = "pdf"
+)
+
+// ErrUnsupportedFormat is returned for formats other than txt, docx and pdf
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Entry is one transcript line
+type Entry struct {
+	Timestamp time.Time
+	Speaker   string
+	Text      string
+}
+
+// Document is a meeting transcript, with optional minutes, ready to render
+type Document struct {
+	Title     string
+	Language  string
+	StartedAt time.Time
+	EndedAt   *time.Time
+	Location  *time.Location // timestamps are shown in this zone; nil means UTC
+	Entries   []Entry
+	Minutes   *database.MeetingMinutesContent
+}
+
+// Render renders a document in the given format
+func Render(format string, doc Document) ([]byte, error) {
+	if doc.Location == nil {
+		doc.Location = time.UTC
+	}
+	switch format {
+	case FormatTXT:
+		return renderTXT(doc), nil
+	case FormatDOCX:
+		return renderDOCX(doc)
+	case FormatPDF:
+		return renderPDF(doc)
+	default:
+		return nil, fmt.Errorf("%w %q (supported: txt, docx, pdf)", ErrUnsupportedFormat, format)
+	}
+}
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) string {
+	switch format {
+	case FormatDOCX:
+		return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+var snapshotLine = regexp.MustCompile(`^\[(\d{2}:\d{2}:\d{2})\] ([^:]*): (.*)$`)
+
+// ParseSnapshot turns a stored transcript snapshot back into entries. Snapshot
+// lines only carry a UTC time of day, so dates are taken from the meeting
+// start and advance when the clock wraps past midnight. Lines that do not
+// match the snapshot format continue the previous entry.
+func ParseSnapshot(transcript string, startedAt time.Time) []Entry {
+	var entries []Entry
+	day := startedAt.UTC().Truncate(24 * time.Hour)
+	var previous time.Time
+
+	scanner := bufio.NewScanner(strings.NewReader(transcript))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		match := snapshotLine.FindStringSubmatch(line)
+		if match == nil {
+			if len(entries) > 0 && strings.TrimSpace(line) != "" {
+				entries[len(entries)-1].Text += "\n" + line
+			}
+			continue
+		}
+
+		clock, err := time.Parse("15:04:05", match[1])
+		if err != nil {
+			continue
+		}
+		ts := day.Add(time.Duration(clock.Hour())*time.Hour +
+			time.Duration(clock.Minute())*time.Minute +
+			time.Duration(clock.Second())*time.Second)
+		if ts.Before(previous) {
+			day = day.Add(24 * time.Hour)
+			ts = ts.Add(24 * time.Hour)
+		}
+		previous = ts
+
+		entries = append(entries, Entry{Timestamp: ts, Speaker: match[2], Text: match[3]})
+	}
+	return entries
+}
+
+// block kinds shared by all renderers
+const (
+	blockTitle = iota
+	blockMeta
+	blockHeading
+	blockSubheading
+	blockParagraph
+	blockBullet
+	blockEntry
+)
+
+// block is one layout element; renderers only differ in how they draw them
+type block struct {
+	kind  int
+	label string // entry timestamp and speaker
+	text  string
+}
+
+// blocks lays out the document: title, meeting details, minutes, transcript
+func (doc Document) blocks() []block {
+	blocks := []block{{kind: blockTitle, text: doc.Title}}
+
+	meta := fmt.Sprintf("Language: %s | Started: %s", doc.Language, doc.StartedAt.In(doc.Location).Format("2006-01-02 15:04 MST"))
+	if doc.EndedAt != nil {
+		meta += " | Ended: " + doc.EndedAt.In(doc.Location).Format("2006-01-02 15:04 MST")
+	}
+	blocks = append(blocks, block{kind: blockMeta, text: meta})
+
+	if minutes := doc.Minutes; minutes != nil {
+		blocks = append(blocks, block{kind: blockHeading, text: "Minutes"})
+		if minutes.Summary != "" {
+			blocks = append(blocks,
+				block{kind: blockSubheading, text: "Summary"},
+				block{kind: blockParagraph, text: minutes.Summary})
+		}
+		for _, section := range []struct {
+			title string
+			items []string
+		}{
+			{"Participants", minutes.Participants},
+			{"Key points", minutes.KeyPoints},
+			{"Decisions", minutes.Decisions},
+			{"Action items", minutes.ActionItems},
+		} {
+			if len(section.items) == 0 {
+				continue
+			}
+			blocks = append(blocks, block{kind: blockSubheading, text: section.title})
+			for _, item := range section.items {
+				blocks = append(blocks, block{kind: blockBullet, text: item})
+			}
+		}
+	}
+
+	blocks = append(blocks, block{kind: blockHeading, text: "Transcript"})
+	if len(doc.Entries) == 0 {
+		blocks = append(blocks, block{kind: blockParagraph, text: "No transcript was recorded."})
+	}
+	for _, entry := range doc.Entries {
+		speaker := entry.Speaker
+		if speaker == "" {
+			speaker = "Speaker"
+		}
+		label := fmt.Sprintf("[%s] %s", entry.Timestamp.In(doc.Location).Format("15:04:05"), speaker)
+		blocks = append(blocks, block{kind: blockEntry, label: label, text: entry.Text})
+	}
+	return blocks
+}
+
+// renderTXT renders plain text, with transcript lines in the same format as
+// the live transcript download
+func renderTXT(doc Document) []byte {
+	var b strings.Builder
+	for _, blk := range doc.blocks() {
+		switch blk.kind {
+		case blockTitle:
+			b.WriteString(blk.text + "\n" + strings.Repeat("=", len([]rune(blk.text))) + "\n")
+		case blockMeta:
+			b.WriteString(blk.text + "\n")
+		case blockHeading:
+			b.WriteString("\n" + blk.text + "\n" + strings.Repeat("-", len([]rune(blk.text))) + "\n")
+		case blockSubheading:
+			b.WriteString("\n" + blk.text + ":\n")
+		case blockParagraph:
+			b.WriteString(blk.text + "\n")
+		case blockBullet:
+			b.WriteString("- " + blk.text + "\n")
+		case blockEntry:
+			b.WriteString(blk.label + ": " + blk.text + "\n")
+		}
+	}
+	return []byte(b.String())
+}
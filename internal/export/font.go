@@ -0,0 +1,228 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// trueTypeFont is a TrueType font that PDF exports embed when the standard
+// fonts cannot encode their text
+type trueTypeFont struct {
+	name       string // PDF font name
+	unitsPerEm float64
+	bbox       [4]int16 // xMin, yMin, xMax, yMax in font units
+	ascent     int16
+	descent    int16
+	glyphs     map[rune]uint16
+	advances   []uint16 // per glyph, in font units
+	program    []byte   // zlib-compressed font file
+	length     int      // uncompressed size of the font file
+}
+
+// pdfFont is the font loaded by LoadPDFFont; nil keeps PDF export to the
+// standard fonts
+var pdfFont *trueTypeFont
+
+var errTruncatedFont = errors.New("truncated font file")
+
+// LoadPDFFont loads a TrueType (.ttf) font that PDF exports embed when their
+// text is outside the standard fonts' Latin range, such as Chinese, Japanese
+// or Korean transcripts. Fonts with CFF outlines (most .otf files) and font
+// collections (.ttc) are not supported. Call it before serving requests.
+func LoadPDFFont(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF font: %w", err)
+	}
+	font, err := parseTrueType(data)
+	if err != nil {
+		return fmt.Errorf("PDF font %s: %w", path, err)
+	}
+	font.name = fontName(path)
+
+	// The font file is the same in every export, so it is compressed once
+	var program bytes.Buffer
+	zw := zlib.NewWriter(&program)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	font.program = program.Bytes()
+	font.length = len(data)
+
+	pdfFont = font
+	return nil
+}
+
+// parseTrueType reads the metrics and Unicode character map of a font
+func parseTrueType(data []byte) (*trueTypeFont, error) {
+	if len(data) < 12 {
+		return nil, errTruncatedFont
+	}
+	switch string(data[:4]) {
+	case "\x00\x01\x00\x00", "true":
+	case "OTTO":
+		return nil, errors.New("CFF outlines are not supported; use a TrueType font")
+	case "ttcf":
+		return nil, errors.New("font collections are not supported; use a single .ttf font")
+	default:
+		return nil, errors.New("not a TrueType font")
+	}
+
+	tables := make(map[string][]byte)
+	for i := 0; i < int(u16(data, 4)); i++ {
+		record := 12 + 16*i
+		if record+16 > len(data) {
+			return nil, errTruncatedFont
+		}
+		offset, length := int(u32(data, record+8)), int(u32(data, record+12))
+		if offset+length > len(data) {
+			return nil, errTruncatedFont
+		}
+		tables[string(data[record:record+4])] = data[offset : offset+length]
+	}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "cmap", "glyf"} {
+		if tables[tag] == nil {
+			return nil, fmt.Errorf("missing %s table", tag)
+		}
+	}
+
+	head, hhea, maxp, hmtx := tables["head"], tables["hhea"], tables["maxp"], tables["hmtx"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, errTruncatedFont
+	}
+	font := &trueTypeFont{
+		unitsPerEm: float64(u16(head, 18)),
+		bbox:       [4]int16{int16(u16(head, 36)), int16(u16(head, 38)), int16(u16(head, 40)), int16(u16(head, 42))},
+		ascent:     int16(u16(hhea, 4)),
+		descent:    int16(u16(hhea, 6)),
+	}
+	if font.unitsPerEm == 0 {
+		return nil, errors.New("invalid unitsPerEm")
+	}
+
+	// Glyphs past the last horizontal metric repeat its advance
+	numGlyphs, numMetrics := int(u16(maxp, 4)), int(u16(hhea, 34))
+	if numMetrics == 0 || len(hmtx) < 4*numMetrics {
+		return nil, errTruncatedFont
+	}
+	font.advances = make([]uint16, numGlyphs)
+	for g := range font.advances {
+		font.advances[g] = u16(hmtx, 4*min(g, numMetrics-1))
+	}
+
+	glyphs, err := parseCmap(tables["cmap"], numGlyphs)
+	if err != nil {
+		return nil, err
+	}
+	font.glyphs = glyphs
+	return font, nil
+}
+
+// parseCmap maps characters to glyphs from the font's Unicode cmap,
+// preferring the full-range format 12 subtable over the BMP-only format 4
+func parseCmap(cmap []byte, numGlyphs int) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, errTruncatedFont
+	}
+	var format4, format12 []byte
+	for i := 0; i < int(u16(cmap, 2)); i++ {
+		record := 4 + 8*i
+		if record+8 > len(cmap) {
+			return nil, errTruncatedFont
+		}
+		platform, encoding, offset := u16(cmap, record), u16(cmap, record+2), int(u32(cmap, record+4))
+		if platform != 0 && !(platform == 3 && (encoding == 1 || encoding == 10)) {
+			continue
+		}
+		if offset+16 > len(cmap) {
+			continue
+		}
+		switch subtable := cmap[offset:]; u16(subtable, 0) {
+		case 4:
+			format4 = subtable
+		case 12:
+			format12 = subtable
+		}
+	}
+
+	glyphs := make(map[rune]uint16)
+	switch {
+	case format12 != nil:
+		groups := int(u32(format12, 12))
+		if 16+12*groups > len(format12) {
+			return nil, errTruncatedFont
+		}
+		for i := 0; i < groups; i++ {
+			group := 16 + 12*i
+			start, end, glyph := u32(format12, group), u32(format12, group+4), u32(format12, group+8)
+			for c := start; c <= end && c <= unicode.MaxRune; c++ {
+				if g := glyph + c - start; g < uint32(numGlyphs) {
+					glyphs[rune(c)] = uint16(g)
+				}
+			}
+		}
+	case format4 != nil:
+		segments2 := int(u16(format4, 6))
+		ends := 14
+		starts := ends + segments2 + 2
+		deltas := starts + segments2
+		rangeOffsets := deltas + segments2
+		if rangeOffsets+segments2 > len(format4) {
+			return nil, errTruncatedFont
+		}
+		for i := 0; i < segments2; i += 2 {
+			start, end := u16(format4, starts+i), u16(format4, ends+i)
+			delta, rangeOffset := u16(format4, deltas+i), int(u16(format4, rangeOffsets+i))
+			for c := uint32(start); c <= uint32(end) && c != 0xFFFF; c++ {
+				g := uint16(c) + delta
+				if rangeOffset != 0 {
+					at := rangeOffsets + i + rangeOffset + 2*int(c-uint32(start))
+					if at+2 > len(format4) {
+						break
+					}
+					if g = u16(format4, at); g != 0 {
+						g += delta
+					}
+				}
+				if g != 0 && int(g) < numGlyphs {
+					glyphs[rune(c)] = g
+				}
+			}
+		}
+	default:
+		return nil, errors.New("no Unicode character map")
+	}
+	return glyphs, nil
+}
+
+// fontName derives a PDF font name from the font's file name
+func fontName(path string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x80 && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-') {
+			return r
+		}
+		return -1
+	}, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	if name == "" {
+		return "EmbeddedFont"
+	}
+	return name
+}
+
+func u16(b []byte, at int) uint16 {
+	return binary.BigEndian.Uint16(b[at:])
+}
+
+func u32(b []byte, at int) uint32 {
+	return binary.BigEndian.Uint32(b[at:])
+}
@@ -0,0 +1,420 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedScript is returned when text cannot be drawn with the
+// standard PDF fonts, which only cover Latin scripts (WinAnsi encoding), nor
+// with the font loaded by LoadPDFFont
+var ErrUnsupportedScript = errors.New("PDF export cannot draw this script")
+
+// A4 page in points
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 56
+)
+
+// pdfLine is one laid-out line of text
+type pdfLine struct {
+	bold   bool
+	size   float64
+	indent float64
+	text   string  // string operand for Tj, encoded for the face
+	gap    float64 // extra space above the line
+}
+
+// pdfFace is the font a PDF is drawn in
+type pdfFace interface {
+	// advance is the width of r in thousandths of the font size
+	advance(r rune) float64
+	// encode returns text as a string operand for Tj
+	encode(text string) (string, error)
+	// selectFont returns the operators that select the regular or bold font
+	selectFont(bold bool, size float64) string
+	// resources is the page font resource dictionary
+	resources() string
+	// objects are the font objects, numbered from 3
+	objects() []string
+}
+
+// renderPDF lays the document out on A4 pages with a page number footer.
+// Text goes in Courier unless the standard fonts cannot encode it and a
+// font was loaded with LoadPDFFont.
+func renderPDF(doc Document) ([]byte, error) {
+	var face pdfFace = courierFace{}
+	pages, err := layoutPDF(doc, face)
+	if errors.Is(err, ErrUnsupportedScript) && pdfFont != nil {
+		face = &embeddedFace{font: pdfFont, used: make(map[uint16]rune)}
+		pages, err = layoutPDF(doc, face)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return writePDF(pages, face), nil
+}
+
+// layoutPDF wraps the document's text and breaks it into pages
+func layoutPDF(doc Document, face pdfFace) ([][]pdfLine, error) {
+	var lines []pdfLine
+	add := func(text string, bold bool, size, indent, gap float64) error {
+		width := (pdfPageWidth - 2*pdfMargin - indent) / size * 1000
+		for i, wrapped := range wrapText(cleanText(text), width, face.advance) {
+			encoded, err := face.encode(wrapped)
+			if err != nil {
+				return err
+			}
+			line := pdfLine{bold: bold, size: size, indent: indent, text: encoded}
+			if i == 0 {
+				line.gap = gap
+			}
+			lines = append(lines, line)
+		}
+		return nil
+	}
+
+	for _, blk := range doc.blocks() {
+		var err error
+		switch blk.kind {
+		case blockTitle:
+			err = add(blk.text, true, 16, 0, 0)
+		case blockMeta:
+			err = add(blk.text, false, 9, 0, 4)
+		case blockHeading:
+			err = add(blk.text, true, 13, 0, 16)
+		case blockSubheading:
+			err = add(blk.text, true, 11, 0, 8)
+		case blockParagraph:
+			err = add(blk.text, false, 10, 0, 2)
+		case blockBullet:
+			err = add("- "+blk.text, false, 10, 12, 2)
+		case blockEntry:
+			if err = add(blk.label, true, 10, 0, 6); err == nil {
+				err = add(blk.text, false, 10, 12, 0)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Break lines into pages
+	var pages [][]pdfLine
+	var page []pdfLine
+	y := float64(pdfPageHeight - pdfMargin)
+	for _, line := range lines {
+		height := line.size*1.3 + line.gap
+		if len(page) > 0 && y-height < pdfMargin+20 {
+			pages = append(pages, page)
+			page = nil
+			y = pdfPageHeight - pdfMargin
+		}
+		page = append(page, line)
+		y -= height
+	}
+	pages = append(pages, page)
+	return pages, nil
+}
+
+// writePDF serializes pages: catalog, page tree, the face's fonts, then a
+// page and content stream object per page, followed by the xref table
+func writePDF(pages [][]pdfLine, face pdfFace) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Page contents go first so the font lists the glyphs they use
+	contents := make([]string, len(pages))
+	for i, lines := range pages {
+		var content bytes.Buffer
+		y := float64(pdfPageHeight - pdfMargin)
+		for _, line := range lines {
+			y -= line.size*1.3 + line.gap
+			fmt.Fprintf(&content, "BT %s %.1f %.1f Td %s Tj ET\n",
+				face.selectFont(line.bold, line.size), pdfMargin+line.indent, y, line.text)
+		}
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(pages))
+		if encoded, err := face.encode(footer); err == nil {
+			fmt.Fprintf(&content, "BT %s %.1f %d Td %s Tj ET\n", face.selectFont(false, 8),
+				pdfPageWidth-pdfMargin-textWidth(footer, face.advance)*8/1000, pdfMargin/2, encoded)
+		}
+		contents[i] = content.String()
+	}
+
+	fonts := face.objects()
+	first := 3 + len(fonts)
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", first+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	for _, font := range fonts {
+		object(font)
+	}
+
+	for i, content := range contents {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, face.resources(), first+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// courierFace draws text in the standard Courier fonts, whose fixed width
+// lets wrapping count characters
+type courierFace struct{}
+
+func (courierFace) advance(rune) float64 {
+	return 600
+}
+
+func (courierFace) encode(text string) (string, error) {
+	encoded, err := winAnsi(text)
+	if err != nil {
+		return "", err
+	}
+	return "(" + pdfEscape(encoded) + ")", nil
+}
+
+func (courierFace) selectFont(bold bool, size float64) string {
+	if bold {
+		return fmt.Sprintf("/F2 %.1f Tf", size)
+	}
+	return fmt.Sprintf("/F1 %.1f Tf", size)
+}
+
+func (courierFace) resources() string {
+	return "/F1 3 0 R /F2 4 0 R"
+}
+
+func (courierFace) objects() []string {
+	return []string{
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+	}
+}
+
+// embeddedFace draws text in the font loaded by LoadPDFFont, embedded as a
+// CID font whose character codes are glyph IDs. It has no bold weight, so
+// bold text is also stroked. used collects the glyphs a document draws for
+// the font's width table and its ToUnicode map, which keeps text searchable.
+type embeddedFace struct {
+	font *trueTypeFont
+	used map[uint16]rune
+}
+
+func (f *embeddedFace) advance(r rune) float64 {
+	return float64(f.font.advances[f.font.glyphs[r]]) * 1000 / f.font.unitsPerEm
+}
+
+func (f *embeddedFace) encode(text string) (string, error) {
+	var b strings.Builder
+	b.WriteByte('<')
+	for _, r := range text {
+		glyph, ok := f.font.glyphs[r]
+		if !ok || needsShaping(r) {
+			return "", fmt.Errorf("%w: cannot draw %q", ErrUnsupportedScript, r)
+		}
+		f.used[glyph] = r
+		fmt.Fprintf(&b, "%04X", glyph)
+	}
+	b.WriteByte('>')
+	return b.String(), nil
+}
+
+func (f *embeddedFace) selectFont(bold bool, size float64) string {
+	if bold {
+		return fmt.Sprintf("/F1 %.1f Tf 2 Tr %.2f w", size, size*0.03)
+	}
+	return fmt.Sprintf("/F1 %.1f Tf 0 Tr", size)
+}
+
+func (f *embeddedFace) resources() string {
+	return "/F1 3 0 R"
+}
+
+// objects are the Type0 font (3), its CID font (4), font descriptor (5),
+// font file (6) and ToUnicode map (7)
+func (f *embeddedFace) objects() []string {
+	font := f.font
+	scale := func(v int16) int {
+		return int(float64(v) * 1000 / font.unitsPerEm)
+	}
+
+	glyphs := make([]int, 0, len(f.used))
+	for glyph := range f.used {
+		glyphs = append(glyphs, int(glyph))
+	}
+	sort.Ints(glyphs)
+	var widths strings.Builder
+	for _, glyph := range glyphs {
+		fmt.Fprintf(&widths, "%d [%d] ", glyph, int(float64(font.advances[glyph])*1000/font.unitsPerEm))
+	}
+
+	var cmap strings.Builder
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for start := 0; start < len(glyphs); start += 100 {
+		batch := glyphs[start:min(start+100, len(glyphs))]
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", len(batch))
+		for _, glyph := range batch {
+			fmt.Fprintf(&cmap, "<%04X> <", glyph)
+			for _, unit := range utf16.Encode([]rune{f.used[uint16(glyph)]}) {
+				fmt.Fprintf(&cmap, "%04X", unit)
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+
+	return []string{
+		fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [4 0 R] /ToUnicode 7 0 R >>", font.name),
+		fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor 5 0 R /CIDToGIDMap /Identity /W [%s] >>",
+			font.name, strings.TrimSpace(widths.String())),
+		fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 4 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 6 0 R >>",
+			font.name, scale(font.bbox[0]), scale(font.bbox[1]), scale(font.bbox[2]), scale(font.bbox[3]),
+			scale(font.ascent), scale(font.descent), scale(font.ascent)),
+		fmt.Sprintf("<< /Length %d /Length1 %d /Filter /FlateDecode >>\nstream\n%s\nendstream", len(font.program), font.length, font.program),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", cmap.Len(), cmap.String()),
+	}
+}
+
+// needsShaping reports whether r belongs to a script that is written right
+// to left or whose letters join or reorder, which PDF export does not do
+func needsShaping(r rune) bool {
+	return unicode.In(r, unicode.Arabic, unicode.Hebrew, unicode.Syriac, unicode.Thaana, unicode.Nko,
+		unicode.Devanagari, unicode.Bengali, unicode.Gurmukhi, unicode.Gujarati, unicode.Oriya,
+		unicode.Tamil, unicode.Telugu, unicode.Kannada, unicode.Malayalam, unicode.Sinhala,
+		unicode.Tibetan, unicode.Myanmar, unicode.Khmer)
+}
+
+// winAnsiExtras maps the characters WinAnsi places in 0x80-0x9F
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// cleanText turns tabs into spaces and drops other control characters
+func cleanText(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t':
+			return ' '
+		case r == '\n':
+			return r
+		case r < 0x20 || (r >= 0x7F && r < 0xA0):
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// winAnsi encodes cleaned text for the standard fonts
+func winAnsi(text string) ([]byte, error) {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r <= 0xFF:
+			out = append(out, byte(r))
+		default:
+			b, ok := winAnsiExtras[r]
+			if !ok {
+				return nil, fmt.Errorf("%w: cannot encode %q", ErrUnsupportedScript, r)
+			}
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+// textWidth is the width of text in thousandths of the font size
+func textWidth(text string, advance func(rune) float64) float64 {
+	var width float64
+	for _, r := range text {
+		width += advance(r)
+	}
+	return width
+}
+
+// wrapText splits text into lines no wider than width, in thousandths of the
+// font size, breaking at spaces where possible and at newlines always. Words
+// wider than a line, such as runs of CJK text, are broken between characters.
+func wrapText(text string, width float64, advance func(rune) float64) []string {
+	space := advance(' ')
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		var line string
+		var lineWidth float64
+		for _, word := range strings.Fields(paragraph) {
+			wordWidth := textWidth(word, advance)
+			for wordWidth > width {
+				if line != "" {
+					lines = append(lines, line)
+					line, lineWidth = "", 0
+				}
+				// Take as many characters as fit, at least one
+				var cut int
+				var cutWidth float64
+				for i, r := range word {
+					if i > 0 && cutWidth+advance(r) > width {
+						break
+					}
+					cut = i + utf8.RuneLen(r)
+					cutWidth += advance(r)
+				}
+				lines = append(lines, word[:cut])
+				word, wordWidth = word[cut:], wordWidth-cutWidth
+			}
+			switch {
+			case word == "":
+			case line == "":
+				line, lineWidth = word, wordWidth
+			case lineWidth+space+wordWidth <= width:
+				line, lineWidth = line+" "+word, lineWidth+space+wordWidth
+			default:
+				lines = append(lines, line)
+				line, lineWidth = word, wordWidth
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pdfEscape escapes a literal string's delimiters
+func pdfEscape(text []byte) string {
+	var b strings.Builder
+	for _, c := range text {
+		if c == '\\' || c == '(' || c == ')' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,137 @@
+// Package failures collects terminal failures of background processing into
+// the processing_failures inbox, classified by error code and ordered by
+// priority, so operators can triage and requeue them in one place.
+package failures
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"strings"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/ratelimit"
+)
+
+// Error codes
+const (
+	CodeTimeout     = "timeout"     // a deadline passed, usually worth retrying as is
+	CodeOverloaded  = "overloaded"  // a limiter or upstream service shed the work
+	CodeUnreachable = "unreachable" // a service could not be connected to
+	CodeUpstream    = "upstream"    // a service answered with an error status
+	CodeNotFound    = "not_found"   // an input file, meeting or object is gone
+	CodeInvalid     = "invalid"     // the input was rejected; retry with other parameters
+	CodePanic       = "panic"
+	CodeUnknown     = "unknown"
+)
+
+// Priorities; lower values are triaged first
+const (
+	PriorityHigh   = 1 // user-facing work such as uploads waiting on results
+	PriorityNormal = 2
+	PriorityLow    = 3 // background enrichment such as embeddings
+)
+
+// Failure describes a failure to record. Context is marshaled to JSON and
+// should carry whatever a requeue needs, e.g. the job payload.
+type Failure struct {
+	Source    string
+	Kind      string
+	Priority  int
+	Err       error
+	Code      string // classified from Err when empty
+	Context   interface{}
+	JobID     *int
+	ChunkID   *int
+	MeetingID string
+	SessionID string
+	UserID    *int
+	Attempts  int
+}
+
+// Record stores a failure in the inbox. Errors are logged rather than
+// returned: failing to record a failure must not hide the original one.
+func Record(f Failure) {
+	if f.Err == nil {
+		return
+	}
+	if f.Code == "" {
+		f.Code = Classify(f.Err)
+	}
+	if f.Priority == 0 {
+		f.Priority = PriorityNormal
+	}
+
+	var data json.RawMessage
+	if f.Context != nil {
+		encoded, err := json.Marshal(f.Context)
+		if err != nil {
+			log.Printf("[Failures] Failed to encode context of %s %s failure: %v", f.Source, f.Kind, err)
+		} else {
+			data = encoded
+		}
+	}
+
+	failure := &database.ProcessingFailure{
+		Source:    f.Source,
+		Kind:      f.Kind,
+		Priority:  f.Priority,
+		ErrorCode: f.Code,
+		Error:     f.Err.Error(),
+		Context:   data,
+		JobID:     f.JobID,
+		ChunkID:   f.ChunkID,
+		MeetingID: f.MeetingID,
+		SessionID: f.SessionID,
+		UserID:    f.UserID,
+		Attempts:  f.Attempts,
+	}
+	if err := database.CreateProcessingFailure(failure); err != nil {
+		log.Printf("[Failures] %v", err)
+		return
+	}
+	log.Printf("[Failures] Recorded %s %s failure %d (%s)", f.Source, f.Kind, failure.ID, f.Code)
+}
+
+// Classify maps an error to one of the error codes. Typed errors are
+// checked first; messages of the HTTP clients are matched as a fallback.
+func Classify(err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return CodeTimeout
+	}
+	if errors.Is(err, ratelimit.ErrOverloaded) {
+		return CodeOverloaded
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return CodeTimeout
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return CodeUnreachable
+	}
+
+	message := strings.ToLower(err.Error())
+	switch {
+	case strings.HasPrefix(message, "panic:"):
+		return CodePanic
+	case strings.Contains(message, "timeout"), strings.Contains(message, "timed out"), strings.Contains(message, "deadline exceeded"):
+		return CodeTimeout
+	case strings.Contains(message, "overloaded"), strings.Contains(message, "status 429"), strings.Contains(message, "status 503"):
+		return CodeOverloaded
+	case strings.Contains(message, "connection refused"), strings.Contains(message, "no such host"), strings.Contains(message, "connection reset"):
+		return CodeUnreachable
+	case strings.Contains(message, "returned status"), strings.Contains(message, "service error"):
+		return CodeUpstream
+	case strings.Contains(message, "not found"), strings.Contains(message, "no such file"):
+		return CodeNotFound
+	case strings.Contains(message, "invalid"), strings.Contains(message, "unsupported"):
+		return CodeInvalid
+	}
+	return CodeUnknown
+}
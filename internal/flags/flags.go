@@ -0,0 +1,173 @@
+package flags
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// Known feature flags
+const (
+	VoiceCloning    = "voice_cloning"
+	LiveDubbing     = "live_dubbing"
+	HybridRetrieval = "hybrid_retrieval"
+)
+
+// Definition describes a known flag
+type Definition struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+}
+
+// Definitions lists the flags gating experimental capabilities
+var Definitions = []Definition{
+	{Key: VoiceCloning, Description: "Clone speaker voices for dubbed audio"},
+	{Key: LiveDubbing, Description: "Synthesize translated speech during live sessions"},
+	{Key: HybridRetrieval, Description: "Blend keyword and vector search for RAG retrieval"},
+}
+
+// Subject identifies who a flag is evaluated for. Until explicit
+// organizations exist, users are grouped into orgs by the domain of a
+// verified email address.
+type Subject struct {
+	UserID int
+	OrgID  string
+}
+
+// SubjectForUser builds the evaluation subject for a user (nil for anonymous)
+func SubjectForUser(user *database.User) Subject {
+	if user == nil {
+		return Subject{}
+	}
+	subject := Subject{UserID: user.ID}
+	// Anyone can claim an unverified address at someone else's domain
+	if !user.EmailVerified {
+		return subject
+	}
+	if at := strings.LastIndex(user.Email, "@"); at != -1 {
+		subject.OrgID = strings.ToLower(user.Email[at+1:])
+	}
+	return subject
+}
+
+// SubjectForMeeting builds the evaluation subject for a meeting: the user who
+// created it (anonymous when it was created without signing in)
+func SubjectForMeeting(meetingID string) Subject {
+	mtg, err := database.GetMeetingByID(meetingID)
+	if err != nil || mtg == nil || mtg.CreatedBy == nil {
+		return Subject{}
+	}
+	user, err := database.GetUserByID(*mtg.CreatedBy)
+	if err != nil {
+		log.Printf("[Flags] Failed to load creator of meeting %s: %v", meetingID, err)
+		return Subject{}
+	}
+	return SubjectForUser(user)
+}
+
+// refreshInterval bounds how stale the cached DB state may be
+const refreshInterval = 30 * time.Second
+
+type overrideKey struct {
+	flag      string
+	scopeType string
+	scopeID   string
+}
+
+var (
+	mu        sync.RWMutex
+	loadedAt  time.Time
+	global    map[string]bool
+	overrides map[overrideKey]bool
+)
+
+// Enabled reports whether a flag is on for the subject. Resolution order:
+// user override, org override, global DB state, FEATURE_<KEY> env var, off.
+func Enabled(key string, subject Subject) bool {
+	refreshIfStale()
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if subject.UserID != 0 {
+		if enabled, ok := overrides[overrideKey{key, database.FlagScopeUser, strconv.Itoa(subject.UserID)}]; ok {
+			return enabled
+		}
+	}
+	if subject.OrgID != "" {
+		if enabled, ok := overrides[overrideKey{key, database.FlagScopeOrg, subject.OrgID}]; ok {
+			return enabled
+		}
+	}
+	if enabled, ok := global[key]; ok {
+		return enabled
+	}
+	return envDefault(key)
+}
+
+// Evaluate returns the state of every known flag for the subject
+func Evaluate(subject Subject) map[string]bool {
+	result := make(map[string]bool, len(Definitions))
+	for _, def := range Definitions {
+		result[def.Key] = Enabled(def.Key, subject)
+	}
+	return result
+}
+
+// Invalidate forces the next evaluation to reload flags from the database
+func Invalidate() {
+	mu.Lock()
+	loadedAt = time.Time{}
+	mu.Unlock()
+}
+
+// envDefault reads FEATURE_<KEY> (e.g. FEATURE_VOICE_CLONING=true)
+func envDefault(key string) bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("FEATURE_" + strings.ToUpper(key)))
+	return enabled
+}
+
+func refreshIfStale() {
+	mu.RLock()
+	fresh := time.Since(loadedAt) < refreshInterval
+	mu.RUnlock()
+	if fresh || database.DB == nil {
+		return
+	}
+
+	flagRows, err := database.ListFeatureFlags()
+	if err == nil {
+		var overrideRows []database.FeatureFlagOverride
+		overrideRows, err = database.ListFeatureFlagOverrides()
+		if err == nil {
+			store(flagRows, overrideRows)
+			return
+		}
+	}
+
+	// Keep the previous state and back off until the next interval
+	log.Printf("[Flags] Failed to load feature flags: %v", err)
+	mu.Lock()
+	loadedAt = time.Now()
+	mu.Unlock()
+}
+
+func store(flagRows []database.FeatureFlag, overrideRows []database.FeatureFlagOverride) {
+	newGlobal := make(map[string]bool, len(flagRows))
+	for _, flag := range flagRows {
+		newGlobal[flag.Key] = flag.Enabled
+	}
+	newOverrides := make(map[overrideKey]bool, len(overrideRows))
+	for _, o := range overrideRows {
+		newOverrides[overrideKey{o.FlagKey, o.ScopeType, o.ScopeID}] = o.Enabled
+	}
+
+	mu.Lock()
+	global, overrides, loadedAt = newGlobal, newOverrides, time.Now()
+	mu.Unlock()
+}
@@ -0,0 +1,493 @@
+// Package grpcapi serves the Captions gRPC API (api/proto/captions.proto)
+// for backend integrators. It wraps the same clients, pipeline hooks, job
+// queue and live session server as the HTTP API, so both behave alike.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"realtime-caption-translator/api/proto/captionsv1"
+	"realtime-caption-translator/internal/asr"
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/flags"
+	"realtime-caption-translator/internal/hooks"
+	"realtime-caption-translator/internal/langcode"
+	"realtime-caption-translator/internal/lexicon"
+	"realtime-caption-translator/internal/progress"
+	"realtime-caption-translator/internal/quota"
+	"realtime-caption-translator/internal/session"
+	"realtime-caption-translator/internal/translate"
+	"realtime-caption-translator/internal/tts"
+	"realtime-caption-translator/internal/voicepolicy"
+)
+
+// MaxMessageBytes bounds one request message, e.g. the WAV of a Transcribe
+// call; uploads stream larger files in chunks
+const MaxMessageBytes = 32 << 20
+
+// MaxUploadBytes bounds a file streamed through CreateJob, like the
+// multipart limit of POST /upload
+const MaxUploadBytes = 500 << 20
+
+// audioChunkSize bounds each AudioChunk of synthesized speech
+const audioChunkSize = 32 * 1024
+
+// jobPollInterval is how often WatchJob rereads the job, so a stream ends
+// even when the final progress update was missed
+const jobPollInterval = 5 * time.Second
+
+// ErrUnsupportedMedia is returned by Config.StartJob for files that are
+// neither video nor audio
+var ErrUnsupportedMedia = errors.New("unsupported file type")
+
+// Upload is a file streamed through CreateJob
+type Upload struct {
+	Filename   string
+	SourceLang string
+	TargetLang string
+	Dub        bool
+	Media      io.Reader
+}
+
+// Config holds the services behind the API
+type Config struct {
+	ASR        *asr.Client
+	Translator translate.Translator
+	TTS        *tts.Client
+	Live       *session.Server
+	Progress   *progress.Manager
+
+	// Authenticate resolves a bearer token to its user. Nil leaves every
+	// call anonymous; otherwise calls without a valid token are refused.
+	Authenticate func(ctx context.Context, token string) (*database.User, error)
+
+	// StartJob spools an upload and queues its processing like the HTTP
+	// upload handlers; user is nil for anonymous calls
+	StartJob func(user *database.User, upload Upload) (*database.Job, error)
+}
+
+// Server implements captionsv1.CaptionsServer
+type Server struct {
+	captionsv1.UnimplementedCaptionsServer
+	cfg Config
+}
+
+// NewGRPCServer returns a gRPC server with the Captions service registered
+// behind cfg's authentication
+func NewGRPCServer(cfg Config) *grpc.Server {
+	g := grpc.NewServer(
+		grpc.MaxRecvMsgSize(MaxMessageBytes),
+		grpc.ChainUnaryInterceptor(cfg.unaryAuth),
+		grpc.ChainStreamInterceptor(cfg.streamAuth),
+	)
+	captionsv1.RegisterCaptionsServer(g, &Server{cfg: cfg})
+	return g
+}
+
+// userKey is the context key for the authenticated user of a call
+type userKey struct{}
+
+func userFrom(ctx context.Context) *database.User {
+	user, _ := ctx.Value(userKey{}).(*database.User)
+	return user
+}
+
+// authenticate attaches the caller's user to ctx
+func (cfg Config) authenticate(ctx context.Context) (context.Context, error) {
+	if cfg.Authenticate == nil {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata missing")
+	}
+	scheme, token, ok := strings.Cut(strings.TrimSpace(values[0]), " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || strings.TrimSpace(token) == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization must be a Bearer token")
+	}
+	user, err := cfg.Authenticate(ctx, strings.TrimSpace(token))
+	if err != nil || user == nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return context.WithValue(ctx, userKey{}, user), nil
+}
+
+func (cfg Config) unaryAuth(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := cfg.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (cfg Config) streamAuth(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := cfg.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authedStream carries the authenticated context into stream handlers
+type authedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authedStream) Context() context.Context {
+	return s.ctx
+}
+
+// quotaError converts an exceeded quota into a gRPC status
+func quotaError(err error) error {
+	exceeded, ok := quota.Exceeded(err)
+	if !ok {
+		return nil
+	}
+	if exceeded.StatusCode() == http.StatusForbidden {
+		return status.Error(codes.FailedPrecondition, exceeded.Error())
+	}
+	return status.Error(codes.ResourceExhausted, exceeded.Error())
+}
+
+// Transcribe recognizes a WAV clip, charging it to the caller's daily
+// audio quota
+func (s *Server) Transcribe(ctx context.Context, req *captionsv1.TranscribeRequest) (*captionsv1.TranscribeResponse, error) {
+	if len(req.GetAudio()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "audio is required")
+	}
+	user := userFrom(ctx)
+	if user != nil {
+		if _, err := quota.Check(user.ID, quota.Request{}); err != nil {
+			if qerr := quotaError(err); qerr != nil {
+				return nil, qerr
+			}
+			log.Printf("[gRPC] Failed to check quota for user %d: %v", user.ID, err)
+		}
+	}
+
+	result, err := s.cfg.ASR.TranscribeWAVSegments(req.GetAudio(), langcode.Normalize(req.GetLanguage()))
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "transcription failed: %v", err)
+	}
+
+	resp := &captionsv1.TranscribeResponse{Language: result.Language}
+	var duration float64
+	for _, segment := range result.Segments {
+		duration = max(duration, segment.End)
+		text := hookedText(hooks.PostTranscription, strings.TrimSpace(segment.Text), result.Language)
+		if text == "" {
+			continue
+		}
+		resp.Segments = append(resp.Segments, &captionsv1.Segment{Text: text, Start: segment.Start, End: segment.End})
+	}
+	resp.Text = hookedText(hooks.PostTranscription, strings.TrimSpace(result.Text), result.Language)
+	if user != nil {
+		quota.RecordAudio(user.ID, duration)
+	}
+	return resp, nil
+}
+
+// hookedText runs final text through a pipeline point; text a hook drops
+// becomes ""
+func hookedText(point hooks.Point, text, language string) string {
+	if text == "" {
+		return ""
+	}
+	text, ok := hooks.Run(point, hooks.Event{Source: hooks.SourceAPI, Text: text, Language: language, Final: true})
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(text)
+}
+
+// Translate translates text through the translation hooks
+func (s *Server) Translate(ctx context.Context, req *captionsv1.TranslateRequest) (*captionsv1.TranslateResponse, error) {
+	text := strings.TrimSpace(req.GetText())
+	targetLang := langcode.Normalize(req.GetTargetLanguage())
+	if text == "" || targetLang == "" {
+		return nil, status.Error(codes.InvalidArgument, "text and target_language are required")
+	}
+	sourceLang := langcode.Normalize(req.GetSourceLanguage())
+
+	translation, _, err := hooks.Translate(hooks.Event{
+		Source:         hooks.SourceAPI,
+		Text:           text,
+		SourceLanguage: sourceLang,
+		TargetLanguage: targetLang,
+		Final:          true,
+	}, func(text string) (string, error) {
+		// Long texts are split the same way uploads split them
+		if chunker, ok := s.cfg.Translator.(*translate.HTTPTranslator); ok {
+			return chunker.ChunkAndTranslate(text, sourceLang, targetLang)
+		}
+		return s.cfg.Translator.TranslateWithSource(text, sourceLang, targetLang)
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "translation failed: %v", err)
+	}
+	return &captionsv1.TranslateResponse{Text: translation}, nil
+}
+
+// Synthesize voices text with the caller's organization's TTS policy and
+// pronunciation lexicon, after the pre-TTS hooks
+func (s *Server) Synthesize(req *captionsv1.SynthesizeRequest, stream grpc.ServerStreamingServer[captionsv1.AudioChunk]) error {
+	language := langcode.Normalize(req.GetLanguage())
+	if strings.TrimSpace(req.GetText()) == "" || language == "" {
+		return status.Error(codes.InvalidArgument, "text and language are required")
+	}
+	text := hookedText(hooks.PreTTS, strings.TrimSpace(req.GetText()), language)
+	if text == "" {
+		return status.Error(codes.FailedPrecondition, "text was dropped by a pipeline hook")
+	}
+
+	orgID := flags.SubjectForUser(userFrom(stream.Context())).OrgID
+	client := s.cfg.TTS.WithPolicy(voicepolicy.For(orgID))
+	if req.GetVoice() != "" {
+		client.Voice = req.GetVoice()
+	}
+	audio, err := client.Synthesize(lexicon.Apply(orgID, language, text), language)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "synthesis failed: %v", err)
+	}
+
+	mimeType := http.DetectContentType(audio)
+	for start := 0; start < len(audio); start += audioChunkSize {
+		chunk := &captionsv1.AudioChunk{Data: audio[start:min(start+audioChunkSize, len(audio))]}
+		if start == 0 {
+			chunk.MimeType = mimeType
+		}
+		if err := stream.Send(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateJob receives a file after its config message and queues it
+func (s *Server) CreateJob(stream grpc.ClientStreamingServer[captionsv1.CreateJobRequest, captionsv1.Job]) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	config := first.GetConfig()
+	if config == nil {
+		return status.Error(codes.InvalidArgument, "the first message must be the job config")
+	}
+	if strings.TrimSpace(config.GetFilename()) == "" {
+		return status.Error(codes.InvalidArgument, "filename is required")
+	}
+
+	job, err := s.cfg.StartJob(userFrom(stream.Context()), Upload{
+		Filename:   config.GetFilename(),
+		SourceLang: langcode.Normalize(config.GetSourceLanguage()),
+		TargetLang: langcode.Normalize(config.GetTargetLanguage()),
+		Dub:        config.GetDub(),
+		Media:      &uploadReader{stream: stream},
+	})
+	if err != nil {
+		if qerr := quotaError(err); qerr != nil {
+			return qerr
+		}
+		if errors.Is(err, ErrUnsupportedMedia) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		log.Printf("[gRPC] Failed to start job: %v", err)
+		return status.Error(codes.Internal, "failed to queue processing")
+	}
+	return stream.SendAndClose(jobMessage(job))
+}
+
+// uploadReader reads the data messages of a CreateJob stream as one file
+type uploadReader struct {
+	stream  grpc.ClientStreamingServer[captionsv1.CreateJobRequest, captionsv1.Job]
+	pending []byte
+	read    int64
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		if req.GetConfig() != nil {
+			return 0, status.Error(codes.InvalidArgument, "config may only be sent first")
+		}
+		r.pending = req.GetData()
+		if r.read += int64(len(r.pending)); r.read > MaxUploadBytes {
+			return 0, status.Errorf(codes.ResourceExhausted, "file exceeds %d MB", MaxUploadBytes>>20)
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// GetJob returns a job the caller started
+func (s *Server) GetJob(ctx context.Context, req *captionsv1.GetJobRequest) (*captionsv1.Job, error) {
+	job, err := ownedJob(ctx, req.GetJobId())
+	if err != nil {
+		return nil, err
+	}
+	return jobMessage(job), nil
+}
+
+// ownedJob loads a job, hiding other users' jobs like GET /api/jobs/{id}
+func ownedJob(ctx context.Context, id int64) (*database.Job, error) {
+	if id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid job_id")
+	}
+	job, err := database.GetJob(int(id))
+	if err != nil {
+		log.Printf("[gRPC] Failed to load job %d: %v", id, err)
+		return nil, status.Error(codes.Internal, "failed to load job")
+	}
+	user := userFrom(ctx)
+	if job == nil || (job.UserID != nil && (user == nil || user.ID != *job.UserID)) {
+		return nil, status.Error(codes.NotFound, "job not found")
+	}
+	return job, nil
+}
+
+func jobMessage(job *database.Job) *captionsv1.Job {
+	return &captionsv1.Job{
+		JobId:     int64(job.ID),
+		SessionId: job.SessionID,
+		Status:    job.Status,
+		Attempts:  int32(job.Attempts),
+		Error:     job.LastError,
+	}
+}
+
+// finished reports whether a job will not run again
+func finished(job *database.Job) bool {
+	return job.Status == database.JobSucceeded || job.Status == database.JobFailed
+}
+
+// finalProgress describes a finished job for callers that missed its
+// last progress update
+func finalProgress(job *database.Job) *captionsv1.JobProgress {
+	update := &captionsv1.JobProgress{JobId: int64(job.ID), Stage: job.Status, Error: job.LastError}
+	if job.Status == database.JobSucceeded {
+		update.Stage = "complete"
+		update.Progress = 100
+	}
+	return update
+}
+
+// WatchJob streams a job's progress updates until it finishes
+func (s *Server) WatchJob(req *captionsv1.GetJobRequest, stream grpc.ServerStreamingServer[captionsv1.JobProgress]) error {
+	ctx := stream.Context()
+	job, err := ownedJob(ctx, req.GetJobId())
+	if err != nil {
+		return err
+	}
+
+	// Subscribe before checking the status so the last update is not lost
+	sub, unsubscribe := s.cfg.Progress.SubscribeChannel(job.SessionID, 32)
+	defer unsubscribe()
+
+	refresh := func() (bool, error) {
+		current, err := database.GetJob(job.ID)
+		if err != nil || current == nil || !finished(current) {
+			return false, nil
+		}
+		return true, stream.Send(finalProgress(current))
+	}
+	if done, err := refresh(); done || err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if done, err := refresh(); done || err != nil {
+				return err
+			}
+		case data, ok := <-sub.Updates():
+			if !ok {
+				return nil
+			}
+			var update progress.Update
+			if err := json.Unmarshal(data, &update); err != nil {
+				continue
+			}
+			message := &captionsv1.JobProgress{
+				JobId:    int64(job.ID),
+				Stage:    update.Stage,
+				Progress: update.Progress,
+				Message:  update.Message,
+				Error:    update.Error,
+			}
+			if update.Results != nil {
+				if results, err := json.Marshal(update.Results); err == nil {
+					message.Results = string(results)
+				}
+			}
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+			if update.Stage == "complete" {
+				return nil
+			}
+			// Failed attempts may be retried; the stream ends once the
+			// job has failed for good
+			if update.Error != "" {
+				if done, err := refresh(); done || err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// StreamCaptions runs a live caption session over the stream, with the
+// same pipeline as /ws
+func (s *Server) StreamCaptions(stream grpc.BidiStreamingServer[captionsv1.StreamCaptionsRequest, captionsv1.Caption]) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	config := first.GetConfig()
+	if config == nil {
+		return status.Error(codes.InvalidArgument, "the first message must be the stream config")
+	}
+	rate := int(config.GetSampleRate())
+	if rate < session.MinSampleRate || rate > session.MaxSampleRate {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("sample_rate must be between %d and %d", session.MinSampleRate, session.MaxSampleRate))
+	}
+	targetLang := langcode.Normalize(config.GetTargetLanguage())
+	if targetLang == "" {
+		return status.Error(codes.InvalidArgument, "target_language is required")
+	}
+
+	conn, err := newCaptionConn(stream, rate, langcode.Normalize(config.GetSourceLanguage()), targetLang)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	// Speech is only offered on /ws, which can carry binary audio frames
+	s.cfg.Live.HandleConn(conn, session.ConnOptions{})
+	return conn.err()
+}
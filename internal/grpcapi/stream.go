@@ -0,0 +1,139 @@
+package grpcapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+
+	"realtime-caption-translator/api/proto/captionsv1"
+)
+
+// captionStream is the server side of StreamCaptions
+type captionStream = grpc.BidiStreamingServer[captionsv1.StreamCaptionsRequest, captionsv1.Caption]
+
+// captionConn adapts a StreamCaptions stream to session.Conn: the config
+// becomes a "start" message and audio becomes binary PCM frames, while the
+// session's caption events become Caption messages
+type captionConn struct {
+	stream captionStream
+
+	// pending are control messages to hand the session before reading
+	// the stream again
+	pending [][]byte
+	closing bool // the client closed its side and "stop" was sent
+
+	mu       sync.Mutex
+	language string // source language captions are tagged with
+	sendErr  error
+}
+
+// controlMessage is the subset of the /ws control messages the adapter sends
+type controlMessage struct {
+	Type       string `json:"type"`
+	SourceLang string `json:"sourceLang,omitempty"`
+	TargetLang string `json:"targetLang,omitempty"`
+	SampleRate int    `json:"sampleRate,omitempty"`
+}
+
+// sessionEvent is the subset of the /ws events that become captions
+type sessionEvent struct {
+	Type     string `json:"type"`
+	ID       int    `json:"id"`
+	Text     string `json:"text"`
+	Language string `json:"language"`
+}
+
+func newCaptionConn(stream captionStream, sampleRate int, sourceLang, targetLang string) (*captionConn, error) {
+	start, err := json.Marshal(controlMessage{Type: "start", SourceLang: sourceLang, TargetLang: targetLang, SampleRate: sampleRate})
+	if err != nil {
+		return nil, err
+	}
+	return &captionConn{stream: stream, pending: [][]byte{start}, language: sourceLang}, nil
+}
+
+// ReadMessage returns the next control message or audio frame. When the
+// client closes its side, a "stop" is returned first so the last partial
+// is finalized before the session ends.
+func (c *captionConn) ReadMessage() (int, []byte, error) {
+	for {
+		if len(c.pending) > 0 {
+			msg := c.pending[0]
+			c.pending = c.pending[1:]
+			return websocket.TextMessage, msg, nil
+		}
+		req, err := c.stream.Recv()
+		if errors.Is(err, io.EOF) && !c.closing {
+			c.closing = true
+			stop, _ := json.Marshal(controlMessage{Type: "stop"})
+			return websocket.TextMessage, stop, nil
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		if audio := req.GetAudio(); len(audio) > 0 {
+			return websocket.BinaryMessage, audio, nil
+		}
+		// A config after the first message is ignored
+	}
+}
+
+// WriteJSON sends the session events that carry captions; the session
+// serializes its writes
+func (c *captionConn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var event sessionEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var caption *captionsv1.Caption
+	switch event.Type {
+	case "language_switch":
+		c.language = event.Language
+	case "partial":
+		caption = &captionsv1.Caption{Text: event.Text}
+	case "partial_translation":
+		caption = &captionsv1.Caption{Translation: event.Text}
+	case "final":
+		caption = &captionsv1.Caption{Id: int32(event.ID), Final: true, Text: event.Text}
+	case "translation":
+		caption = &captionsv1.Caption{Id: int32(event.ID), Final: true, Translation: event.Text}
+	}
+	if caption == nil {
+		return nil
+	}
+	caption.Language = c.language
+	if err := c.stream.Send(caption); err != nil {
+		if c.sendErr == nil {
+			c.sendErr = err
+		}
+		return err
+	}
+	return nil
+}
+
+// WriteMessage drops binary frames; speech is not offered on this transport
+func (c *captionConn) WriteMessage(int, []byte) error {
+	return nil
+}
+
+// Close is a no-op; the stream ends when StreamCaptions returns
+func (c *captionConn) Close() error {
+	return nil
+}
+
+// err is the first failed send, reported as the RPC's result
+func (c *captionConn) err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sendErr
+}
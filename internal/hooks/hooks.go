@@ -0,0 +1,221 @@
+// Package hooks runs deployment-specific code at fixed points of the caption
+// pipeline, so filtering, compliance scanning or analytics can be added
+// without forking it. Hooks are Go code registered with Register (compiled
+// in, or loaded from a Go plugin with LoadPlugins) or HTTP endpoints
+// configured with Configure.
+//
+// A hook sees the text at its point and may replace it, drop it, or leave
+// it as it is. Hooks of a point run in registration order, each seeing the
+// previous one's text. A failing hook is logged and skipped unless the
+// pipeline is configured to fail closed, in which case the text is dropped.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"plugin"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Point is a place in the pipeline where hooks run
+type Point string
+
+// Pipeline points
+const (
+	PostTranscription Point = "post_transcription" // recognized speech, before anything else sees it
+	PreTranslation    Point = "pre_translation"    // source text about to be sent for one target language
+	PostTranslation   Point = "post_translation"   // a translation, before it is shown or stored
+	PreTTS            Point = "pre_tts"            // text about to be spoken
+)
+
+// Points lists every pipeline point
+var Points = []Point{PostTranscription, PreTranslation, PostTranslation, PreTTS}
+
+// Sources of pipeline text
+const (
+	SourceMeeting   = "meeting"
+	SourceStream    = "stream"
+	SourceRecording = "recording"
+	SourceVideo     = "video"
+	SourceAudio     = "audio"
+	SourceAPI       = "api" // gRPC calls
+)
+
+// ErrDrop is returned by a hook to drop the text: a dropped transcription
+// is not captioned, a dropped translation is not shown and dropped speech
+// is not synthesized
+var ErrDrop = errors.New("dropped by pipeline hook")
+
+// Event is the text at a pipeline point with what is known about it
+type Event struct {
+	Point          Point  `json:"point"`
+	Source         string `json:"source"`
+	SessionID      string `json:"sessionId,omitempty"`
+	MeetingID      string `json:"meetingId,omitempty"`
+	Speaker        string `json:"speaker,omitempty"`
+	Text           string `json:"text"`
+	Language       string `json:"language,omitempty"` // language of Text
+	SourceLanguage string `json:"sourceLanguage,omitempty"`
+	TargetLanguage string `json:"targetLanguage,omitempty"`
+	Original       string `json:"original,omitempty"` // source text of a translation
+	Final          bool   `json:"final"`              // false for partial captions
+}
+
+// Hook handles events at a pipeline point. It may change e.Text, return
+// ErrDrop to drop the text, or return another error to report a failure.
+type Hook interface {
+	Handle(ctx context.Context, e *Event) error
+}
+
+// HookFunc adapts a function to a Hook
+type HookFunc func(ctx context.Context, e *Event) error
+
+// Handle calls f
+func (f HookFunc) Handle(ctx context.Context, e *Event) error {
+	return f(ctx, e)
+}
+
+type registered struct {
+	name string
+	hook Hook
+}
+
+var (
+	mu         sync.RWMutex
+	registry   = make(map[Point][]registered)
+	timeout    = 2 * time.Second
+	failClosed bool
+)
+
+// ValidPoint reports whether point is a pipeline point
+func ValidPoint(point Point) bool {
+	for _, p := range Points {
+		if p == point {
+			return true
+		}
+	}
+	return false
+}
+
+// Register adds a hook at a pipeline point. Go plugins call it from their
+// RegisterHooks function; compiled-in hooks from an init function.
+func Register(point Point, name string, hook Hook) error {
+	if !ValidPoint(point) {
+		return fmt.Errorf("unknown pipeline point %q", point)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	registry[point] = append(registry[point], registered{name: name, hook: hook})
+	log.Printf("[Hooks] Registered %s at %s", name, point)
+	return nil
+}
+
+// SetOptions sets how long one hook may run (default 2s) and whether a
+// failing hook drops the text instead of being skipped
+func SetOptions(hookTimeout time.Duration, closed bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	if hookTimeout > 0 {
+		timeout = hookTimeout
+	}
+	failClosed = closed
+}
+
+// Enabled reports whether any hook runs at point
+func Enabled(point Point) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(registry[point]) > 0
+}
+
+// Registered returns the names of the hooks at each point
+func Registered() map[Point][]string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make(map[Point][]string, len(registry))
+	for point, hooks := range registry {
+		for _, h := range hooks {
+			names[point] = append(names[point], h.name)
+		}
+	}
+	return names
+}
+
+// Run passes e through the hooks at point and returns the resulting text,
+// or ok=false when a hook dropped it
+func Run(point Point, e Event) (text string, ok bool) {
+	mu.RLock()
+	hooks := registry[point]
+	hookTimeout, closed := timeout, failClosed
+	mu.RUnlock()
+
+	e.Point = point
+	for _, h := range hooks {
+		ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+		before := e.Text
+		err := h.hook.Handle(ctx, &e)
+		cancel()
+		if errors.Is(err, ErrDrop) {
+			return "", false
+		}
+		if err != nil {
+			log.Printf("[Hooks] %s at %s failed: %v", h.name, point, err)
+			if closed {
+				return "", false
+			}
+			e.Text = before
+		}
+	}
+	return e.Text, true
+}
+
+// Translate runs a translation through the pre- and post-translation hooks.
+// e describes the source text; translate is called with the text the
+// pre-translation hooks leave. ok is false when a hook dropped the text.
+func Translate(e Event, translate func(text string) (string, error)) (translation string, ok bool, err error) {
+	e.Language = e.SourceLanguage
+	text, ok := Run(PreTranslation, e)
+	if !ok {
+		return "", false, nil
+	}
+	translation, err = translate(text)
+	if err != nil {
+		return "", true, err
+	}
+
+	e.Original = text
+	e.Text = translation
+	e.Language = e.TargetLanguage
+	translation, ok = Run(PostTranslation, e)
+	return translation, ok, nil
+}
+
+// LoadPlugins opens Go plugins built with -buildmode=plugin against this
+// server's sources. Each must export "RegisterHooks", a func() that calls
+// Register for its hooks.
+func LoadPlugins(paths []string) error {
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		p, err := plugin.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open hook plugin %s: %w", path, err)
+		}
+		symbol, err := p.Lookup("RegisterHooks")
+		if err != nil {
+			return fmt.Errorf("hook plugin %s: %w", path, err)
+		}
+		register, ok := symbol.(func())
+		if !ok {
+			return fmt.Errorf("hook plugin %s: RegisterHooks must be a func()", path)
+		}
+		register()
+	}
+	return nil
+}
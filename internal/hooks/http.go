@@ -0,0 +1,110 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"realtime-caption-translator/internal/webhook"
+)
+
+// PointHeader names the pipeline point of an HTTP hook request
+const PointHeader = "X-Hook-Point"
+
+// HTTPHook posts each event as JSON to a URL. The receiver answers 204 (or
+// 200 without a body) to keep the text, or 200 with
+// {"text": "..."} to replace it or {"drop": true} to drop it. With a
+// secret, requests are signed like webhooks.
+type HTTPHook struct {
+	URL    string
+	Secret []byte
+	Client *http.Client
+}
+
+type httpHookReply struct {
+	Text *string `json:"text"`
+	Drop bool    `json:"drop"`
+}
+
+// Handle posts e to the hook's URL and applies the reply
+func (h *HTTPHook) Handle(ctx context.Context, e *Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create hook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(PointHeader, string(e.Point))
+	if len(h.Secret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(webhook.TimestampHeader, timestamp)
+		req.Header.Set(webhook.SignatureHeader, "sha256="+webhook.Sign(h.Secret, timestamp, body))
+	}
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("hook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("hook returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read hook reply: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	var reply httpHookReply
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return fmt.Errorf("invalid hook reply: %w", err)
+	}
+	if reply.Drop {
+		return ErrDrop
+	}
+	if reply.Text != nil {
+		e.Text = *reply.Text
+	}
+	return nil
+}
+
+// Configure registers HTTP hooks from a comma-separated list of
+// point=url pairs, e.g. "post_translation=https://scan.example/hook".
+// A point may be listed more than once; its hooks run in list order.
+func Configure(spec, secret string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		point, url, found := strings.Cut(entry, "=")
+		if !found || strings.TrimSpace(url) == "" {
+			return fmt.Errorf("invalid hook %q: expected point=url", entry)
+		}
+		url = strings.TrimSpace(url)
+		hook := &HTTPHook{URL: url, Secret: []byte(secret)}
+		if err := Register(Point(strings.TrimSpace(point)), url, hook); err != nil {
+			return err
+		}
+	}
+	return nil
+}
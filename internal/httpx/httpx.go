@@ -0,0 +1,294 @@
+// Package httpx adds retries and a circuit breaker to calls to the model
+// services. Transient failures (refused connections, resets, 429/502/503/504)
+// are retried with jittered exponential backoff; after enough consecutive
+// failures the breaker opens and calls fail at once until a probe succeeds.
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrCircuitOpen is returned while a service's breaker is open
+var ErrCircuitOpen = errors.New("service unavailable (circuit open)")
+
+// Breaker states
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// Policy configures retries and tripping for one service
+type Policy struct {
+	MaxAttempts      int           // total tries per call, including the first
+	BaseDelay        time.Duration // backoff before the first retry; doubles after
+	MaxDelay         time.Duration // backoff cap
+	FailureThreshold int           // consecutive failures that open the breaker
+	Cooldown         time.Duration // how long the breaker stays open before a probe
+}
+
+// DefaultPolicy retries twice and opens after five failures in a row
+var DefaultPolicy = Policy{
+	MaxAttempts:      3,
+	BaseDelay:        200 * time.Millisecond,
+	MaxDelay:         2 * time.Second,
+	FailureThreshold: 5,
+	Cooldown:         30 * time.Second,
+}
+
+// Breaker guards calls to one downstream service. A nil *Breaker sends every
+// call once without retries.
+type Breaker struct {
+	name   string
+	policy Policy
+
+	mu          sync.Mutex
+	state       string
+	failures    int
+	openedAt    time.Time
+	probing     bool
+	lastError   string
+	lastFailure time.Time
+	trips       int64
+	retries     int64
+	rejected    int64
+}
+
+// Stats is a snapshot of a breaker for /health/services
+type Stats struct {
+	Name                string     `json:"name"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastError           string     `json:"lastError,omitempty"`
+	LastFailureAt       *time.Time `json:"lastFailureAt,omitempty"`
+	RetryAt             *time.Time `json:"retryAt,omitempty"`
+	Trips               int64      `json:"trips"`
+	Retries             int64      `json:"retries"`
+	Rejected            int64      `json:"rejected"`
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]*Breaker)
+)
+
+// NewBreaker creates the breaker for a service and registers it for
+// Snapshot. Zero policy fields fall back to DefaultPolicy.
+func NewBreaker(name string, policy Policy) *Breaker {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultPolicy.MaxAttempts
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = DefaultPolicy.BaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = DefaultPolicy.MaxDelay
+	}
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = DefaultPolicy.FailureThreshold
+	}
+	if policy.Cooldown <= 0 {
+		policy.Cooldown = DefaultPolicy.Cooldown
+	}
+
+	b := &Breaker{name: name, policy: policy, state: StateClosed}
+	registryMu.Lock()
+	registry[name] = b
+	registryMu.Unlock()
+	return b
+}
+
+// Snapshot returns the state of every registered breaker, sorted by name
+func Snapshot() []Stats {
+	registryMu.Lock()
+	breakers := make([]*Breaker, 0, len(registry))
+	for _, b := range registry {
+		breakers = append(breakers, b)
+	}
+	registryMu.Unlock()
+
+	stats := make([]Stats, 0, len(breakers))
+	for _, b := range breakers {
+		stats = append(stats, b.Stats())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// Stats returns a snapshot of the breaker
+func (b *Breaker) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stats := Stats{
+		Name:                b.name,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+		Trips:               b.trips,
+		Retries:             b.retries,
+		Rejected:            b.rejected,
+	}
+	if !b.lastFailure.IsZero() {
+		lastFailure := b.lastFailure
+		stats.LastFailureAt = &lastFailure
+	}
+	if b.state == StateOpen {
+		retryAt := b.openedAt.Add(b.policy.Cooldown)
+		stats.RetryAt = &retryAt
+	}
+	return stats
+}
+
+// Do sends req through send, retrying transient failures while the request's
+// context allows. Requests whose body cannot be replayed are tried once.
+// Retried responses are closed; the last response or error is returned.
+func (b *Breaker) Do(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if b == nil {
+		return send(req)
+	}
+
+	ctx := req.Context()
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	for attempt := 1; ; attempt++ {
+		if err := b.allow(); err != nil {
+			return nil, fmt.Errorf("%s: %w", b.name, err)
+		}
+
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		resp, err := send(attemptReq)
+		if err != nil && ctx.Err() != nil {
+			// The caller gave up; that says nothing about the service
+			b.mu.Lock()
+			b.probing = false
+			b.mu.Unlock()
+			return resp, err
+		}
+		failed, retryable := classify(resp, err)
+		b.record(failed, resp, err)
+
+		if !retryable || !replayable || attempt >= b.policy.MaxAttempts {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		b.mu.Lock()
+		b.retries++
+		b.mu.Unlock()
+
+		select {
+		case <-time.After(b.backoff(attempt)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// allow admits a call unless the breaker is open; after the cooldown one
+// probe call is let through to test the service
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.policy.Cooldown {
+			b.rejected++
+			return ErrCircuitOpen
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			b.rejected++
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of one attempt
+func (b *Breaker) record(failed bool, resp *http.Response, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		b.state = StateClosed
+		return
+	}
+
+	b.failures++
+	b.lastFailure = time.Now()
+	if err != nil {
+		b.lastError = err.Error()
+	} else {
+		b.lastError = resp.Status
+	}
+	if b.state == StateHalfOpen || b.failures >= b.policy.FailureThreshold {
+		if b.state != StateOpen {
+			b.trips++
+		}
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// backoff returns a full-jitter delay for the given attempt
+func (b *Breaker) backoff(attempt int) time.Duration {
+	delay := b.policy.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > b.policy.MaxDelay {
+		delay = b.policy.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+// classify reports whether an attempt counts against the service and whether
+// it is worth retrying. Timeouts count as failures but are not retried, since
+// the service may still be working on a long request.
+func classify(resp *http.Response, err error) (failed, retryable bool) {
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return true, false
+		}
+		return true, errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || isDialError(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return false, true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true, true
+	}
+	return resp.StatusCode >= 500, false
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,336 @@
+package i18n
+
+// catalog maps language -> English message -> translation. Format verbs must
+// be kept; use explicit indexes (%[2]s) where a language reorders arguments.
+var catalog = map[string]map[string]string{
+	"es": {
+		// Meetings
+		"%s joined the meeting":                                                    "%s se unió a la reunión",
+		"%s left the meeting":                                                      "%s salió de la reunión",
+		"%s changed their language to %s":                                          "%s cambió su idioma a %s",
+		"%s agreed to recording":                                                   "%s aceptó la grabación",
+		"%s declined recording":                                                    "%s rechazó la grabación",
+		"The host ended the meeting":                                               "El anfitrión finalizó la reunión",
+		"This meeting will end automatically in 1 minute":                          "La reunión terminará automáticamente en 1 minuto",
+		"This meeting will end automatically in %d minutes":                        "La reunión terminará automáticamente en %d minutos",
+		"This meeting reached its maximum duration and has ended":                  "La reunión alcanzó su duración máxima y ha terminado",
+		"No one has spoken for a while; captioning is paused until someone speaks": "Nadie ha hablado desde hace un rato; los subtítulos están en pausa hasta que alguien hable",
+		"Captioning resumed":                                                       "Subtítulos reanudados",
+		"Failed to transcribe audio":                                               "No se pudo transcribir el audio",
+		"You agreed to recording earlier; your speech is saved to the transcript.": "Ya aceptaste la grabación; lo que digas se guarda en la transcripción.",
+		"You declined recording earlier; your speech is captioned live only.":      "Ya rechazaste la grabación; lo que digas solo se subtitula en directo.",
+		"This meeting is transcribed and translated. With your consent, your speech is also saved to the meeting transcript and its searchable history. If you decline, your speech is still captioned live for other participants but is never stored.": "Esta reunión se transcribe y se traduce. Con tu consentimiento, lo que digas también se guarda en la transcripción de la reunión y en su historial de búsqueda. Si lo rechazas, lo que digas se sigue subtitulando en directo para los demás participantes, pero nunca se guarda.",
+
+		// Upload progress
+		"Received %s (%.2f MB)":                       "Recibido %s (%.2f MB)",
+		"%s, retrying (attempt %d of %d)":             "%s, reintentando (intento %d de %d)",
+		"Saving audio file...":                        "Guardando el archivo de audio...",
+		"Extracting audio from video...":              "Extrayendo el audio del vídeo...",
+		"Audio extracted: %.2f seconds":               "Audio extraído: %.2f segundos",
+		"Cleaning up audio and converting to WAV...":  "Limpiando el audio y convirtiéndolo a WAV...",
+		"Converting audio to WAV format...":           "Convirtiendo el audio a formato WAV...",
+		"Audio converted: %.2f seconds":               "Audio convertido: %.2f segundos",
+		"Detecting language...":                       "Detectando el idioma...",
+		"Language detection failed, using English":    "No se pudo detectar el idioma, se usará inglés",
+		"Detected language: %s":                       "Idioma detectado: %s",
+		"Transcribing audio...":                       "Transcribiendo el audio...",
+		"Transcribing with speaker identification...": "Transcribiendo con identificación de hablantes...",
+		"Transcription complete":                      "Transcripción completada",
+		"Translating from %s to %s...":                "Traduciendo de %s a %s...",
+		"Translating %d segments...":                  "Traduciendo %d segmentos...",
+		"Translation complete":                        "Traducción completada",
+		"Generating TTS audio...":                     "Generando el audio de voz...",
+		"Generating TTS for %d segments...":           "Generando voz para %d segmentos...",
+		"Generating TTS with voice cloning...":        "Generando voz con clonación de voz...",
+		"Voice cloning failed, using standard TTS...": "La clonación de voz falló, se usará la voz estándar...",
+		"Voiced segment %d of %d":                     "Segmento %d de %d con voz",
+		"TTS generation complete":                     "Voz generada",
+		"Replacing audio in video...":                 "Reemplazando el audio del vídeo...",
+		"Placing dubbed segments in video...":         "Colocando los segmentos doblados en el vídeo...",
+		"Video processing complete":                   "Procesamiento del vídeo completado",
+		"Existing upload found":                       "Se encontró una subida existente",
+		"Video processing completed successfully":     "El vídeo se procesó correctamente",
+		"Audio processing completed successfully":     "El audio se procesó correctamente",
+		"Failed to save audio":                        "No se pudo guardar el audio",
+		"Failed to extract audio":                     "No se pudo extraer el audio",
+		"Failed to convert audio":                     "No se pudo convertir el audio",
+		"Failed to translate":                         "No se pudo traducir",
+		"Failed to generate TTS":                      "No se pudo generar la voz",
+		"Failed to replace audio":                     "No se pudo reemplazar el audio",
+	},
+	"fr": {
+		// Meetings
+		"%s joined the meeting":                                                    "%s a rejoint la réunion",
+		"%s left the meeting":                                                      "%s a quitté la réunion",
+		"%s changed their language to %s":                                          "%s a changé sa langue pour %s",
+		"%s agreed to recording":                                                   "%s a accepté l'enregistrement",
+		"%s declined recording":                                                    "%s a refusé l'enregistrement",
+		"The host ended the meeting":                                               "L'organisateur a mis fin à la réunion",
+		"This meeting will end automatically in 1 minute":                          "La réunion se terminera automatiquement dans 1 minute",
+		"This meeting will end automatically in %d minutes":                        "La réunion se terminera automatiquement dans %d minutes",
+		"This meeting reached its maximum duration and has ended":                  "La réunion a atteint sa durée maximale et est terminée",
+		"No one has spoken for a while; captioning is paused until someone speaks": "Personne n'a parlé depuis un moment ; le sous-titrage est en pause jusqu'à ce que quelqu'un parle",
+		"Captioning resumed":                                                       "Sous-titrage repris",
+		"Failed to transcribe audio":                                               "Impossible de transcrire l'audio",
+		"You agreed to recording earlier; your speech is saved to the transcript.": "Vous avez déjà accepté l'enregistrement ; vos propos sont conservés dans la transcription.",
+		"You declined recording earlier; your speech is captioned live only.":      "Vous avez déjà refusé l'enregistrement ; vos propos sont uniquement sous-titrés en direct.",
+		"This meeting is transcribed and translated. With your consent, your speech is also saved to the meeting transcript and its searchable history. If you decline, your speech is still captioned live for other participants but is never stored.": "Cette réunion est transcrite et traduite. Avec votre accord, vos propos sont aussi conservés dans la transcription de la réunion et dans son historique consultable. Si vous refusez, vos propos restent sous-titrés en direct pour les autres participants mais ne sont jamais conservés.",
+
+		// Upload progress
+		"Received %s (%.2f MB)":                       "%s reçu (%.2f Mo)",
+		"%s, retrying (attempt %d of %d)":             "%s, nouvelle tentative (essai %d sur %d)",
+		"Saving audio file...":                        "Enregistrement du fichier audio...",
+		"Extracting audio from video...":              "Extraction de l'audio de la vidéo...",
+		"Audio extracted: %.2f seconds":               "Audio extrait : %.2f secondes",
+		"Cleaning up audio and converting to WAV...":  "Nettoyage de l'audio et conversion en WAV...",
+		"Converting audio to WAV format...":           "Conversion de l'audio au format WAV...",
+		"Audio converted: %.2f seconds":               "Audio converti : %.2f secondes",
+		"Detecting language...":                       "Détection de la langue...",
+		"Language detection failed, using English":    "Échec de la détection de la langue, anglais utilisé",
+		"Detected language: %s":                       "Langue détectée : %s",
+		"Transcribing audio...":                       "Transcription de l'audio...",
+		"Transcribing with speaker identification...": "Transcription avec identification des locuteurs...",
+		"Transcription complete":                      "Transcription terminée",
+		"Translating from %s to %s...":                "Traduction de %s vers %s...",
+		"Translating %d segments...":                  "Traduction de %d segments...",
+		"Translation complete":                        "Traduction terminée",
+		"Generating TTS audio...":                     "Génération de la voix...",
+		"Generating TTS for %d segments...":           "Génération de la voix pour %d segments...",
+		"Generating TTS with voice cloning...":        "Génération de la voix avec clonage vocal...",
+		"Voice cloning failed, using standard TTS...": "Échec du clonage vocal, voix standard utilisée...",
+		"Voiced segment %d of %d":                     "Segment %d sur %d doublé",
+		"TTS generation complete":                     "Voix générée",
+		"Replacing audio in video...":                 "Remplacement de l'audio de la vidéo...",
+		"Placing dubbed segments in video...":         "Placement des segments doublés dans la vidéo...",
+		"Video processing complete":                   "Traitement de la vidéo terminé",
+		"Existing upload found":                       "Un envoi identique existe déjà",
+		"Video processing completed successfully":     "La vidéo a été traitée avec succès",
+		"Audio processing completed successfully":     "L'audio a été traité avec succès",
+		"Failed to save audio":                        "Impossible d'enregistrer l'audio",
+		"Failed to extract audio":                     "Impossible d'extraire l'audio",
+		"Failed to convert audio":                     "Impossible de convertir l'audio",
+		"Failed to translate":                         "Impossible de traduire",
+		"Failed to generate TTS":                      "Impossible de générer la voix",
+		"Failed to replace audio":                     "Impossible de remplacer l'audio",
+	},
+	"de": {
+		// Meetings
+		"%s joined the meeting":                                                    "%s ist dem Meeting beigetreten",
+		"%s left the meeting":                                                      "%s hat das Meeting verlassen",
+		"%s changed their language to %s":                                          "%s hat die Sprache auf %s geändert",
+		"%s agreed to recording":                                                   "%s hat der Aufzeichnung zugestimmt",
+		"%s declined recording":                                                    "%s hat die Aufzeichnung abgelehnt",
+		"The host ended the meeting":                                               "Der Gastgeber hat das Meeting beendet",
+		"This meeting will end automatically in 1 minute":                          "Das Meeting endet automatisch in 1 Minute",
+		"This meeting will end automatically in %d minutes":                        "Das Meeting endet automatisch in %d Minuten",
+		"This meeting reached its maximum duration and has ended":                  "Das Meeting hat seine maximale Dauer erreicht und wurde beendet",
+		"No one has spoken for a while; captioning is paused until someone speaks": "Seit einer Weile hat niemand gesprochen; die Untertitelung ist pausiert, bis jemand spricht",
+		"Captioning resumed":                                                       "Untertitelung fortgesetzt",
+		"Failed to transcribe audio":                                               "Audio konnte nicht transkribiert werden",
+		"You agreed to recording earlier; your speech is saved to the transcript.": "Sie haben der Aufzeichnung bereits zugestimmt; Ihre Beiträge werden im Transkript gespeichert.",
+		"You declined recording earlier; your speech is captioned live only.":      "Sie haben die Aufzeichnung bereits abgelehnt; Ihre Beiträge werden nur live untertitelt.",
+		"This meeting is transcribed and translated. With your consent, your speech is also saved to the meeting transcript and its searchable history. If you decline, your speech is still captioned live for other participants but is never stored.": "Dieses Meeting wird transkribiert und übersetzt. Mit Ihrer Zustimmung werden Ihre Beiträge auch im Transkript und im durchsuchbaren Verlauf des Meetings gespeichert. Wenn Sie ablehnen, werden Ihre Beiträge für die anderen Teilnehmer weiterhin live untertitelt, aber nie gespeichert.",
+
+		// Upload progress
+		"Received %s (%.2f MB)":                       "%s empfangen (%.2f MB)",
+		"%s, retrying (attempt %d of %d)":             "%s, neuer Versuch (Versuch %d von %d)",
+		"Saving audio file...":                        "Audiodatei wird gespeichert...",
+		"Extracting audio from video...":              "Audio wird aus dem Video extrahiert...",
+		"Audio extracted: %.2f seconds":               "Audio extrahiert: %.2f Sekunden",
+		"Cleaning up audio and converting to WAV...":  "Audio wird bereinigt und in WAV umgewandelt...",
+		"Converting audio to WAV format...":           "Audio wird in das WAV-Format umgewandelt...",
+		"Audio converted: %.2f seconds":               "Audio umgewandelt: %.2f Sekunden",
+		"Detecting language...":                       "Sprache wird erkannt...",
+		"Language detection failed, using English":    "Spracherkennung fehlgeschlagen, Englisch wird verwendet",
+		"Detected language: %s":                       "Erkannte Sprache: %s",
+		"Transcribing audio...":                       "Audio wird transkribiert...",
+		"Transcribing with speaker identification...": "Transkription mit Sprechererkennung...",
+		"Transcription complete":                      "Transkription abgeschlossen",
+		"Translating from %s to %s...":                "Übersetzung von %s nach %s...",
+		"Translating %d segments...":                  "%d Segmente werden übersetzt...",
+		"Translation complete":                        "Übersetzung abgeschlossen",
+		"Generating TTS audio...":                     "Sprachausgabe wird erzeugt...",
+		"Generating TTS for %d segments...":           "Sprachausgabe für %d Segmente wird erzeugt...",
+		"Generating TTS with voice cloning...":        "Sprachausgabe mit Stimmklonung wird erzeugt...",
+		"Voice cloning failed, using standard TTS...": "Stimmklonung fehlgeschlagen, Standardstimme wird verwendet...",
+		"Voiced segment %d of %d":                     "Segment %d von %d vertont",
+		"TTS generation complete":                     "Sprachausgabe erzeugt",
+		"Replacing audio in video...":                 "Audio im Video wird ersetzt...",
+		"Placing dubbed segments in video...":         "Synchronisierte Segmente werden im Video platziert...",
+		"Video processing complete":                   "Videoverarbeitung abgeschlossen",
+		"Existing upload found":                       "Vorhandener Upload gefunden",
+		"Video processing completed successfully":     "Video erfolgreich verarbeitet",
+		"Audio processing completed successfully":     "Audio erfolgreich verarbeitet",
+		"Failed to save audio":                        "Audio konnte nicht gespeichert werden",
+		"Failed to extract audio":                     "Audio konnte nicht extrahiert werden",
+		"Failed to convert audio":                     "Audio konnte nicht umgewandelt werden",
+		"Failed to translate":                         "Übersetzung fehlgeschlagen",
+		"Failed to generate TTS":                      "Sprachausgabe konnte nicht erzeugt werden",
+		"Failed to replace audio":                     "Audio konnte nicht ersetzt werden",
+	},
+	"pt": {
+		// Meetings
+		"%s joined the meeting":                                                    "%s entrou na reunião",
+		"%s left the meeting":                                                      "%s saiu da reunião",
+		"%s changed their language to %s":                                          "%s mudou o idioma para %s",
+		"%s agreed to recording":                                                   "%s aceitou a gravação",
+		"%s declined recording":                                                    "%s recusou a gravação",
+		"The host ended the meeting":                                               "O anfitrião encerrou a reunião",
+		"This meeting will end automatically in 1 minute":                          "A reunião será encerrada automaticamente em 1 minuto",
+		"This meeting will end automatically in %d minutes":                        "A reunião será encerrada automaticamente em %d minutos",
+		"This meeting reached its maximum duration and has ended":                  "A reunião atingiu a duração máxima e foi encerrada",
+		"No one has spoken for a while; captioning is paused until someone speaks": "Ninguém fala há algum tempo; as legendas estão em pausa até que alguém fale",
+		"Captioning resumed":                                                       "Legendas retomadas",
+		"Failed to transcribe audio":                                               "Não foi possível transcrever o áudio",
+		"You agreed to recording earlier; your speech is saved to the transcript.": "Você já aceitou a gravação; sua fala é salva na transcrição.",
+		"You declined recording earlier; your speech is captioned live only.":      "Você já recusou a gravação; sua fala é apenas legendada ao vivo.",
+		"This meeting is transcribed and translated. With your consent, your speech is also saved to the meeting transcript and its searchable history. If you decline, your speech is still captioned live for other participants but is never stored.": "Esta reunião é transcrita e traduzida. Com o seu consentimento, sua fala também é salva na transcrição da reunião e no histórico pesquisável. Se você recusar, sua fala continua sendo legendada ao vivo para os outros participantes, mas nunca é armazenada.",
+
+		// Upload progress
+		"Received %s (%.2f MB)":                       "%s recebido (%.2f MB)",
+		"%s, retrying (attempt %d of %d)":             "%s, tentando novamente (tentativa %d de %d)",
+		"Saving audio file...":                        "Salvando o arquivo de áudio...",
+		"Extracting audio from video...":              "Extraindo o áudio do vídeo...",
+		"Audio extracted: %.2f seconds":               "Áudio extraído: %.2f segundos",
+		"Cleaning up audio and converting to WAV...":  "Limpando o áudio e convertendo para WAV...",
+		"Converting audio to WAV format...":           "Convertendo o áudio para o formato WAV...",
+		"Audio converted: %.2f seconds":               "Áudio convertido: %.2f segundos",
+		"Detecting language...":                       "Detectando o idioma...",
+		"Language detection failed, using English":    "Falha na detecção do idioma, usando inglês",
+		"Detected language: %s":                       "Idioma detectado: %s",
+		"Transcribing audio...":                       "Transcrevendo o áudio...",
+		"Transcribing with speaker identification...": "Transcrevendo com identificação de falantes...",
+		"Transcription complete":                      "Transcrição concluída",
+		"Translating from %s to %s...":                "Traduzindo de %s para %s...",
+		"Translating %d segments...":                  "Traduzindo %d segmentos...",
+		"Translation complete":                        "Tradução concluída",
+		"Generating TTS audio...":                     "Gerando o áudio de voz...",
+		"Generating TTS for %d segments...":           "Gerando voz para %d segmentos...",
+		"Generating TTS with voice cloning...":        "Gerando voz com clonagem de voz...",
+		"Voice cloning failed, using standard TTS...": "A clonagem de voz falhou, usando a voz padrão...",
+		"Voiced segment %d of %d":                     "Segmento %d de %d com voz",
+		"TTS generation complete":                     "Voz gerada",
+		"Replacing audio in video...":                 "Substituindo o áudio do vídeo...",
+		"Placing dubbed segments in video...":         "Posicionando os segmentos dublados no vídeo...",
+		"Video processing complete":                   "Processamento do vídeo concluído",
+		"Existing upload found":                       "Envio existente encontrado",
+		"Video processing completed successfully":     "Vídeo processado com sucesso",
+		"Audio processing completed successfully":     "Áudio processado com sucesso",
+		"Failed to save audio":                        "Não foi possível salvar o áudio",
+		"Failed to extract audio":                     "Não foi possível extrair o áudio",
+		"Failed to convert audio":                     "Não foi possível converter o áudio",
+		"Failed to translate":                         "Não foi possível traduzir",
+		"Failed to generate TTS":                      "Não foi possível gerar a voz",
+		"Failed to replace audio":                     "Não foi possível substituir o áudio",
+	},
+	"zh": {
+		// Meetings
+		"%s joined the meeting":                                                    "%s 加入了会议",
+		"%s left the meeting":                                                      "%s 离开了会议",
+		"%s changed their language to %s":                                          "%s 将语言更改为 %s",
+		"%s agreed to recording":                                                   "%s 同意录制",
+		"%s declined recording":                                                    "%s 拒绝录制",
+		"The host ended the meeting":                                               "主持人已结束会议",
+		"This meeting will end automatically in 1 minute":                          "会议将在 1 分钟后自动结束",
+		"This meeting will end automatically in %d minutes":                        "会议将在 %d 分钟后自动结束",
+		"This meeting reached its maximum duration and has ended":                  "会议已达到最长时长，现已结束",
+		"No one has spoken for a while; captioning is paused until someone speaks": "已有一段时间无人发言；字幕已暂停，有人发言后将自动恢复",
+		"Captioning resumed":                                                       "字幕已恢复",
+		"Failed to transcribe audio":                                               "音频转写失败",
+		"You agreed to recording earlier; your speech is saved to the transcript.": "您此前已同意录制，您的发言会保存到会议记录中。",
+		"You declined recording earlier; your speech is captioned live only.":      "您此前已拒绝录制，您的发言仅显示实时字幕。",
+		"This meeting is transcribed and translated. With your consent, your speech is also saved to the meeting transcript and its searchable history. If you decline, your speech is still captioned live for other participants but is never stored.": "本次会议会被转写和翻译。经您同意后，您的发言还会保存到会议记录及其可搜索的历史中。如果您拒绝，您的发言仍会为其他参会者显示实时字幕，但绝不会被保存。",
+
+		// Upload progress
+		"Received %s (%.2f MB)":                       "已接收 %s（%.2f MB）",
+		"%s, retrying (attempt %d of %d)":             "%s，正在重试（第 %d 次，共 %d 次）",
+		"Saving audio file...":                        "正在保存音频文件...",
+		"Extracting audio from video...":              "正在从视频中提取音频...",
+		"Audio extracted: %.2f seconds":               "音频已提取：%.2f 秒",
+		"Cleaning up audio and converting to WAV...":  "正在清理音频并转换为 WAV...",
+		"Converting audio to WAV format...":           "正在将音频转换为 WAV 格式...",
+		"Audio converted: %.2f seconds":               "音频已转换：%.2f 秒",
+		"Detecting language...":                       "正在检测语言...",
+		"Language detection failed, using English":    "语言检测失败，使用英语",
+		"Detected language: %s":                       "检测到的语言：%s",
+		"Transcribing audio...":                       "正在转写音频...",
+		"Transcribing with speaker identification...": "正在转写并识别说话人...",
+		"Transcription complete":                      "转写完成",
+		"Translating from %s to %s...":                "正在从 %s 翻译为 %s...",
+		"Translating %d segments...":                  "正在翻译 %d 个片段...",
+		"Translation complete":                        "翻译完成",
+		"Generating TTS audio...":                     "正在生成语音...",
+		"Generating TTS for %d segments...":           "正在为 %d 个片段生成语音...",
+		"Generating TTS with voice cloning...":        "正在使用声音克隆生成语音...",
+		"Voice cloning failed, using standard TTS...": "声音克隆失败，改用标准语音...",
+		"Voiced segment %d of %d":                     "已配音第 %d 个片段，共 %d 个",
+		"TTS generation complete":                     "语音生成完成",
+		"Replacing audio in video...":                 "正在替换视频中的音频...",
+		"Placing dubbed segments in video...":         "正在将配音片段放入视频...",
+		"Video processing complete":                   "视频处理完成",
+		"Existing upload found":                       "找到已有的上传",
+		"Video processing completed successfully":     "视频处理成功",
+		"Audio processing completed successfully":     "音频处理成功",
+		"Failed to save audio":                        "保存音频失败",
+		"Failed to extract audio":                     "提取音频失败",
+		"Failed to convert audio":                     "转换音频失败",
+		"Failed to translate":                         "翻译失败",
+		"Failed to generate TTS":                      "生成语音失败",
+		"Failed to replace audio":                     "替换音频失败",
+	},
+	"ja": {
+		// Meetings
+		"%s joined the meeting":                                                    "%s が会議に参加しました",
+		"%s left the meeting":                                                      "%s が会議から退出しました",
+		"%s changed their language to %s":                                          "%s が言語を %s に変更しました",
+		"%s agreed to recording":                                                   "%s が録音に同意しました",
+		"%s declined recording":                                                    "%s が録音を拒否しました",
+		"The host ended the meeting":                                               "ホストが会議を終了しました",
+		"This meeting will end automatically in 1 minute":                          "この会議はあと 1 分で自動的に終了します",
+		"This meeting will end automatically in %d minutes":                        "この会議はあと %d 分で自動的に終了します",
+		"This meeting reached its maximum duration and has ended":                  "この会議は最大時間に達したため終了しました",
+		"No one has spoken for a while; captioning is paused until someone speaks": "しばらく発言がないため、字幕を一時停止しています。誰かが話すと再開します",
+		"Captioning resumed":                                                       "字幕を再開しました",
+		"Failed to transcribe audio":                                               "音声を文字起こしできませんでした",
+		"You agreed to recording earlier; your speech is saved to the transcript.": "録音に同意済みです。あなたの発言は議事録に保存されます。",
+		"You declined recording earlier; your speech is captioned live only.":      "録音を拒否済みです。あなたの発言はライブ字幕のみに表示されます。",
+		"This meeting is transcribed and translated. With your consent, your speech is also saved to the meeting transcript and its searchable history. If you decline, your speech is still captioned live for other participants but is never stored.": "この会議は文字起こしと翻訳が行われます。同意いただくと、あなたの発言は会議の議事録と検索可能な履歴にも保存されます。拒否した場合も、他の参加者にはライブ字幕が表示されますが、発言が保存されることはありません。",
+
+		// Upload progress
+		"Received %s (%.2f MB)":                       "%s を受信しました（%.2f MB）",
+		"%s, retrying (attempt %d of %d)":             "%s。再試行しています（%d / %d 回目）",
+		"Saving audio file...":                        "音声ファイルを保存しています...",
+		"Extracting audio from video...":              "動画から音声を抽出しています...",
+		"Audio extracted: %.2f seconds":               "音声を抽出しました：%.2f 秒",
+		"Cleaning up audio and converting to WAV...":  "音声をクリーンアップして WAV に変換しています...",
+		"Converting audio to WAV format...":           "音声を WAV 形式に変換しています...",
+		"Audio converted: %.2f seconds":               "音声を変換しました：%.2f 秒",
+		"Detecting language...":                       "言語を検出しています...",
+		"Language detection failed, using English":    "言語を検出できなかったため英語を使用します",
+		"Detected language: %s":                       "検出された言語：%s",
+		"Transcribing audio...":                       "音声を文字起こししています...",
+		"Transcribing with speaker identification...": "話者を識別しながら文字起こししています...",
+		"Transcription complete":                      "文字起こしが完了しました",
+		"Translating from %s to %s...":                "%s から %s に翻訳しています...",
+		"Translating %d segments...":                  "%d 個のセグメントを翻訳しています...",
+		"Translation complete":                        "翻訳が完了しました",
+		"Generating TTS audio...":                     "音声を生成しています...",
+		"Generating TTS for %d segments...":           "%d 個のセグメントの音声を生成しています...",
+		"Generating TTS with voice cloning...":        "ボイスクローンで音声を生成しています...",
+		"Voice cloning failed, using standard TTS...": "ボイスクローンに失敗したため標準音声を使用します...",
+		"Voiced segment %d of %d":                     "セグメント %d / %d の音声を生成しました",
+		"TTS generation complete":                     "音声の生成が完了しました",
+		"Replacing audio in video...":                 "動画の音声を置き換えています...",
+		"Placing dubbed segments in video...":         "吹き替えセグメントを動画に配置しています...",
+		"Video processing complete":                   "動画の処理が完了しました",
+		"Existing upload found":                       "既存のアップロードが見つかりました",
+		"Video processing completed successfully":     "動画の処理に成功しました",
+		"Audio processing completed successfully":     "音声の処理に成功しました",
+		"Failed to save audio":                        "音声を保存できませんでした",
+		"Failed to extract audio":                     "音声を抽出できませんでした",
+		"Failed to convert audio":                     "音声を変換できませんでした",
+		"Failed to translate":                         "翻訳できませんでした",
+		"Failed to generate TTS":                      "音声を生成できませんでした",
+		"Failed to replace audio":                     "音声を置き換えられませんでした",
+	},
+}
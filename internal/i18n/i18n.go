@@ -0,0 +1,38 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultLanguage is the language server messages are written in
+const DefaultLanguage = "en"
+
+// T returns message localized to language. Messages are keyed by their
+// English text; "pt-BR" falls back to "pt", and anything missing from the
+// catalog is returned in English.
+func T(language, message string) string {
+	for _, lang := range candidates(language) {
+		if translated, ok := catalog[lang][message]; ok {
+			return translated
+		}
+	}
+	return message
+}
+
+// Sprintf localizes format, then formats it like fmt.Sprintf. Arguments are
+// not translated; localize them with T first when they are messages too.
+func Sprintf(language, format string, args ...interface{}) string {
+	return fmt.Sprintf(T(language, format), args...)
+}
+
+func candidates(language string) []string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		return nil
+	}
+	if base, _, found := strings.Cut(language, "-"); found {
+		return []string{language, base}
+	}
+	return []string{language}
+}
@@ -0,0 +1,261 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/failures"
+)
+
+// Handler runs one attempt of a job. Returning an error schedules a retry
+// unless the error is Permanent or the job is on its final attempt.
+type Handler func(ctx context.Context, job *database.Job) error
+
+// Config tunes the worker pool and retry policy
+type Config struct {
+	Workers      int            // concurrent jobs (default 2)
+	PollInterval time.Duration  // how often idle workers check for due jobs (default 5s)
+	MaxAttempts  int            // attempts per job including the first (default 3)
+	RetryBase    time.Duration  // delay before the first retry, doubled per attempt (default 30s)
+	RetryMax     time.Duration  // upper bound on the retry delay (default 10m)
+	Priorities   map[string]int // failure inbox priority per job kind (default normal)
+}
+
+// Queue is a Postgres-backed job queue with an in-process worker pool.
+// Jobs survive restarts: anything left running when the server stopped is
+// requeued by Start.
+type Queue struct {
+	cfg      Config
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	wake     chan struct{}
+}
+
+// New creates a queue; zero Config fields take their defaults
+func New(cfg Config) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 2
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.RetryBase <= 0 {
+		cfg.RetryBase = 30 * time.Second
+	}
+	if cfg.RetryMax < cfg.RetryBase {
+		cfg.RetryMax = 10 * time.Minute
+	}
+	return &Queue{
+		cfg:      cfg,
+		handlers: make(map[string]Handler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register sets the handler for a job kind. Register before Start.
+func (q *Queue) Register(kind string, handler Handler) {
+	q.mu.Lock()
+	q.handlers[kind] = handler
+	q.mu.Unlock()
+}
+
+// Enqueue stores a job; payload is marshaled to JSON for the handler
+func (q *Queue) Enqueue(kind, sessionID string, userID *int, payload interface{}) (*database.Job, error) {
+	job, _, err := q.EnqueueWithin(kind, sessionID, userID, payload, 0)
+	return job, err
+}
+
+// EnqueueWithin is Enqueue for a job that must not take its user over
+// maxActive queued and running jobs (0 for no limit). Over the limit it
+// returns a nil job and the user's active job count; the check and the
+// insert are atomic.
+func (q *Queue) EnqueueWithin(kind, sessionID string, userID *int, payload interface{}, maxActive int) (*database.Job, int, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	job := &database.Job{
+		Kind:        kind,
+		SessionID:   sessionID,
+		UserID:      userID,
+		Payload:     data,
+		MaxAttempts: q.cfg.MaxAttempts,
+	}
+	active, queued, err := database.EnqueueJobWithin(job, maxActive)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !queued {
+		return nil, active, nil
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, active, nil
+}
+
+// Start requeues jobs orphaned by a previous run and launches the workers.
+// Workers stop when ctx is cancelled; a job in progress is left running and
+// picked up again on the next start.
+func (q *Queue) Start(ctx context.Context) {
+	if count, err := database.RequeueStaleJobs(time.Now().UTC()); err != nil {
+		log.Printf("[Jobs] Failed to requeue interrupted jobs: %v", err)
+	} else if count > 0 {
+		log.Printf("[Jobs] Requeued %d job(s) interrupted by the last shutdown", count)
+	}
+
+	for i := 0; i < q.cfg.Workers; i++ {
+		go q.worker(ctx)
+	}
+	log.Printf("[Jobs] Started %d worker(s)", q.cfg.Workers)
+}
+
+// Depth returns the number of jobs waiting to run
+func (q *Queue) Depth() (int, error) {
+	return database.CountQueuedJobs()
+}
+
+func (q *Queue) kinds() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	kinds := make([]string, 0, len(q.handlers))
+	for kind := range q.handlers {
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+func (q *Queue) worker(ctx context.Context) {
+	ticker := time.NewTicker(q.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain everything that is due before sleeping again
+		for ctx.Err() == nil {
+			job, err := database.ClaimJob(q.kinds())
+			if err != nil {
+				log.Printf("[Jobs] %v", err)
+				break
+			}
+			if job == nil {
+				break
+			}
+			q.run(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+func (q *Queue) run(ctx context.Context, job *database.Job) {
+	q.mu.RLock()
+	handler := q.handlers[job.Kind]
+	q.mu.RUnlock()
+
+	log.Printf("[Jobs] Running %s job %d (attempt %d/%d)", job.Kind, job.ID, job.Attempts, job.MaxAttempts)
+	err := safeRun(ctx, handler, job)
+	if err == nil {
+		if err := database.CompleteJob(job.ID); err != nil {
+			log.Printf("[Jobs] %v", err)
+		}
+		return
+	}
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) || job.FinalAttempt() {
+		log.Printf("[Jobs] %s job %d failed: %v", job.Kind, job.ID, err)
+		if err := database.FailJob(job.ID, err.Error()); err != nil {
+			log.Printf("[Jobs] %v", err)
+		}
+		q.recordFailure(job, err, permanent != nil)
+		return
+	}
+
+	delay := q.backoff(job.Attempts)
+	log.Printf("[Jobs] %s job %d attempt %d failed, retrying in %s: %v", job.Kind, job.ID, job.Attempts, delay, err)
+	if err := database.RetryJob(job.ID, err.Error(), time.Now().UTC().Add(delay)); err != nil {
+		log.Printf("[Jobs] %v", err)
+	}
+}
+
+// recordFailure adds a terminally failed job to the failure inbox with its
+// payload as context, so it can be requeued with the same parameters
+func (q *Queue) recordFailure(job *database.Job, err error, permanent bool) {
+	code := failures.Classify(err)
+	if permanent && code == failures.CodeUnknown {
+		code = failures.CodeInvalid
+	}
+	// Meeting jobs name their meeting in the payload
+	var target struct {
+		MeetingID string `json:"meetingId"`
+	}
+	json.Unmarshal(job.Payload, &target)
+
+	jobID := job.ID
+	failures.Record(failures.Failure{
+		Source:    database.FailureSourceJob,
+		Kind:      job.Kind,
+		Priority:  q.cfg.Priorities[job.Kind],
+		Err:       err,
+		Code:      code,
+		Context:   job.Payload,
+		JobID:     &jobID,
+		MeetingID: target.MeetingID,
+		SessionID: job.SessionID,
+		UserID:    job.UserID,
+		Attempts:  job.Attempts,
+	})
+}
+
+// safeRun calls the handler, turning a panic into a permanent failure
+func safeRun(ctx context.Context, handler Handler, job *database.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[Jobs] %s job %d panicked: %v\n%s", job.Kind, job.ID, r, debug.Stack())
+			err = Permanent(fmt.Errorf("panic: %v", r))
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// backoff returns the delay before retrying after the given attempt
+func (q *Queue) backoff(attempt int) time.Duration {
+	delay := q.cfg.RetryBase
+	for i := 1; i < attempt && delay < q.cfg.RetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, q.cfg.RetryMax)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error as not worth retrying, e.g. invalid input
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
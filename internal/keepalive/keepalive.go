@@ -0,0 +1,124 @@
+// Package keepalive pings WebSocket connections and drops those that stop
+// answering. Each connection gets a read deadline that pongs (and any
+// message the handler reports) push forward, so a peer that vanished without
+// closing the socket makes the handler's read fail instead of lingering.
+package keepalive
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// writeWait bounds how long sending a ping may block
+const writeWait = 10 * time.Second
+
+// Config sets how often connections are pinged and how long they may stay
+// silent before they are considered dead
+type Config struct {
+	PingInterval time.Duration // 0 disables pings and deadlines
+	PongWait     time.Duration // should be longer than PingInterval
+}
+
+// DefaultConfig pings every 30 seconds and gives up after a minute
+var DefaultConfig = Config{
+	PingInterval: 30 * time.Second,
+	PongWait:     60 * time.Second,
+}
+
+var (
+	configMu sync.RWMutex
+	config   = DefaultConfig
+)
+
+// Configure replaces the settings for connections started afterwards
+func Configure(cfg Config) {
+	if cfg.PingInterval > 0 && cfg.PongWait <= cfg.PingInterval {
+		cfg.PongWait = 2 * cfg.PingInterval
+	}
+	configMu.Lock()
+	config = cfg
+	configMu.Unlock()
+}
+
+// Current returns the active settings
+func Current() Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return config
+}
+
+// Conn is the keepalive state of one connection. A nil *Conn is inactive.
+type Conn struct {
+	conn     *websocket.Conn
+	pongWait time.Duration
+	lastSeen atomic.Int64 // unix nanoseconds
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// Start pings conn on the configured interval and sets its read deadline.
+// It returns nil when keepalive is disabled. Call Stop when the handler
+// returns. Start must be called before the connection is read from.
+func Start(conn *websocket.Conn) *Conn {
+	cfg := Current()
+	if cfg.PingInterval <= 0 {
+		return nil
+	}
+
+	k := &Conn{conn: conn, pongWait: cfg.PongWait, stop: make(chan struct{})}
+	k.Touch()
+	conn.SetPongHandler(func(string) error {
+		k.Touch()
+		return nil
+	})
+	go k.ping(cfg.PingInterval)
+	return k
+}
+
+// Touch records activity and pushes the read deadline forward. Handlers may
+// call it after each message they read; it must run on the reading goroutine.
+func (k *Conn) Touch() {
+	if k == nil {
+		return
+	}
+	now := time.Now()
+	k.lastSeen.Store(now.UnixNano())
+	_ = k.conn.SetReadDeadline(now.Add(k.pongWait))
+}
+
+// Stale reports whether the peer has been silent for longer than the pong wait
+func (k *Conn) Stale(now time.Time) bool {
+	if k == nil {
+		return false
+	}
+	return now.Sub(time.Unix(0, k.lastSeen.Load())) > k.pongWait
+}
+
+// Stop ends pinging
+func (k *Conn) Stop() {
+	if k == nil {
+		return
+	}
+	k.stopOnce.Do(func() { close(k.stop) })
+}
+
+func (k *Conn) ping(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// WriteControl may be called concurrently with the handler's writes
+			if err := k.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		case <-k.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Package langcode canonicalizes language codes. ASR, translation, the web
+// client and imported data name languages differently ("en", "en-US",
+// "EN_us", "english"); stored rows are keyed by the canonical form so
+// language filters and joins across snapshots, minutes and chunks match.
+package langcode
+
+import "strings"
+
+// Auto is the "detect the language" choice of session and upload forms
+const Auto = "auto"
+
+// names maps language names and ISO 639-2 codes to ISO 639-1 codes
+var names = map[string]string{
+	"english": "en", "eng": "en",
+	"spanish": "es", "español": "es", "espanol": "es", "castilian": "es", "spa": "es",
+	"french": "fr", "français": "fr", "francais": "fr", "fra": "fr", "fre": "fr",
+	"german": "de", "deutsch": "de", "deu": "de", "ger": "de",
+	"chinese": "zh", "mandarin": "zh", "zho": "zh", "chi": "zh", "cmn": "zh",
+	"japanese": "ja", "jpn": "ja",
+	"korean": "ko", "kor": "ko",
+	"arabic": "ar", "ara": "ar",
+	"hindi": "hi", "hin": "hi",
+	"urdu": "ur", "urd": "ur",
+	"bengali": "bn", "bangla": "bn", "ben": "bn",
+	"tamil": "ta", "tam": "ta",
+	"telugu": "te", "tel": "te",
+	"malayalam": "ml", "mal": "ml",
+	"portuguese": "pt", "português": "pt", "portugues": "pt", "por": "pt",
+	"italian": "it", "italiano": "it", "ita": "it",
+	"russian": "ru", "rus": "ru",
+	"dutch": "nl", "flemish": "nl", "nld": "nl", "dut": "nl",
+	"turkish": "tr", "tur": "tr",
+	"polish": "pl", "pol": "pl",
+	"vietnamese": "vi", "vie": "vi",
+	"indonesian": "id", "ind": "id",
+	"thai": "th", "tha": "th",
+	"persian": "fa", "farsi": "fa", "fas": "fa", "per": "fa",
+	"hebrew": "he", "heb": "he", "iw": "he",
+	"ukrainian": "uk", "ukr": "uk",
+	"swedish": "sv", "swe": "sv",
+	"greek": "el", "ell": "el", "gre": "el",
+}
+
+// Normalize returns the canonical form of a language code: the lowercase
+// ISO 639-1 code without region or script, so "en-US", "EN_us" and
+// "english" all become "en". "auto" and "" are returned as is, and unknown
+// codes are lowercased with their region dropped.
+func Normalize(code string) string {
+	tag := clean(code)
+	if tag == "" || tag == Auto {
+		return tag
+	}
+	if canonical, ok := names[tag]; ok {
+		return canonical
+	}
+	base, _, _ := strings.Cut(tag, "-")
+	if canonical, ok := names[base]; ok {
+		return canonical
+	}
+	return base
+}
+
+// NormalizeTag canonicalizes a code but keeps its region, for data that
+// differs between variants such as pronunciation entries: "pt_BR" becomes
+// "pt-br" and "english" becomes "en"
+func NormalizeTag(code string) string {
+	base, region, found := strings.Cut(clean(code), "-")
+	base = Normalize(base)
+	if !found || region == "" {
+		return base
+	}
+	return base + "-" + region
+}
+
+func clean(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"))
+}
@@ -0,0 +1,190 @@
+package lexicon
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/langcode"
+)
+
+// refreshInterval bounds how stale the cached lexicon may be
+const refreshInterval = 30 * time.Second
+
+// rule is a compiled lexicon entry
+type rule struct {
+	entry database.LexiconEntry
+	re    *regexp.Regexp
+}
+
+type scope struct {
+	org      string
+	language string
+}
+
+var (
+	mu       sync.RWMutex
+	loadedAt time.Time
+	rules    map[scope][]rule
+)
+
+// Compile validates an entry and builds its matcher
+func Compile(entry database.LexiconEntry) (*regexp.Regexp, error) {
+	if strings.TrimSpace(entry.Pattern) == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+
+	switch entry.MatchType {
+	case database.LexiconMatchWord:
+		return regexp.Compile(`(?i)` + regexp.QuoteMeta(entry.Pattern))
+	case database.LexiconMatchExact:
+		return regexp.Compile(regexp.QuoteMeta(entry.Pattern))
+	case database.LexiconMatchRegex:
+		re, err := regexp.Compile(entry.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return re, nil
+	default:
+		return nil, fmt.Errorf("matchType must be word, exact or regex")
+	}
+}
+
+// Apply rewrites text with the lexicon for an org and language before it is
+// synthesized. Org entries override global entries with the same pattern;
+// longer patterns are applied first so "New York City" wins over "York".
+func Apply(orgID, language, text string) string {
+	if text == "" || language == "" {
+		return text
+	}
+	for _, r := range rulesFor(orgID, language) {
+		switch r.entry.MatchType {
+		case database.LexiconMatchRegex:
+			text = r.re.ReplaceAllString(text, r.entry.Replacement)
+		case database.LexiconMatchWord:
+			text = replaceWords(r.re, text, r.entry.Replacement)
+		default:
+			text = r.re.ReplaceAllLiteralString(text, r.entry.Replacement)
+		}
+	}
+	return text
+}
+
+// replaceWords replaces matches that are not part of a longer word. Letters
+// and digits count as word characters in every script, so accented and
+// non-Latin names only match whole.
+func replaceWords(re *regexp.Regexp, text, replacement string) string {
+	var builder strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		before, _ := utf8.DecodeLastRuneInString(text[:loc[0]])
+		after, _ := utf8.DecodeRuneInString(text[loc[1]:])
+		if isWordRune(before) || isWordRune(after) {
+			continue
+		}
+		builder.WriteString(text[last:loc[0]])
+		builder.WriteString(replacement)
+		last = loc[1]
+	}
+	if last == 0 {
+		return text
+	}
+	builder.WriteString(text[last:])
+	return builder.String()
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+// Invalidate forces the next Apply to reload the lexicon from the database
+func Invalidate() {
+	mu.Lock()
+	loadedAt = time.Time{}
+	mu.Unlock()
+}
+
+// rulesFor merges the global and org rules for a language, longest pattern first
+func rulesFor(orgID, language string) []rule {
+	refreshIfStale()
+
+	// "pt-BR" also picks up entries stored for "pt"; the more specific
+	// language wins, and org entries win over global ones
+	language = langcode.NormalizeTag(language)
+	languages := []string{language}
+	if base, _, found := strings.Cut(language, "-"); found {
+		languages = []string{base, language}
+	}
+	orgs := []string{""}
+	if orgID != "" {
+		orgs = append(orgs, orgID)
+	}
+
+	byPattern := make(map[string]rule)
+	mu.RLock()
+	for _, org := range orgs {
+		for _, lang := range languages {
+			for _, r := range rules[scope{org, lang}] {
+				byPattern[r.entry.Pattern] = r
+			}
+		}
+	}
+	mu.RUnlock()
+
+	merged := make([]rule, 0, len(byPattern))
+	for _, r := range byPattern {
+		merged = append(merged, r)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		a, b := merged[i].entry.Pattern, merged[j].entry.Pattern
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+	return merged
+}
+
+func refreshIfStale() {
+	mu.RLock()
+	fresh := time.Since(loadedAt) < refreshInterval
+	mu.RUnlock()
+	if fresh || database.DB == nil {
+		return
+	}
+
+	entries, err := database.ListLexiconEntries()
+	if err != nil {
+		// Keep the previous lexicon and back off until the next interval
+		log.Printf("[Lexicon] Failed to load pronunciation lexicon: %v", err)
+		mu.Lock()
+		loadedAt = time.Now()
+		mu.Unlock()
+		return
+	}
+
+	byScope := make(map[scope][]rule)
+	for _, entry := range entries {
+		re, err := Compile(entry)
+		if err != nil {
+			log.Printf("[Lexicon] Skipping entry %d (%q): %v", entry.ID, entry.Pattern, err)
+			continue
+		}
+		key := scope{entry.OrgID, langcode.NormalizeTag(entry.Language)}
+		byScope[key] = append(byScope[key], rule{entry: entry, re: re})
+	}
+
+	mu.Lock()
+	rules, loadedAt = byScope, time.Now()
+	mu.Unlock()
+}
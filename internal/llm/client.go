@@ -2,16 +2,35 @@ package llm
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
+
+	"realtime-caption-translator/internal/cache"
+	"realtime-caption-translator/internal/httpx"
+	"realtime-caption-translator/internal/ratelimit"
 )
 
 // Client is an HTTP client for the LLM service
 type Client struct {
 	BaseURL string
 	HTTP    *http.Client
+
+	// Limiter, when set, bounds request rate and concurrency; Priority
+	// decides how this client's calls queue against other callers
+	Limiter  *ratelimit.Limiter
+	Priority ratelimit.Priority
+
+	// Breaker, when set, retries transient failures and stops calling the
+	// service while it is unhealthy
+	Breaker *httpx.Breaker
+
+	// Cache, when set, memoizes responses keyed by a hash of the full request
+	Cache       *cache.TTL[string, string]
+	bypassCache bool
 }
 
 // New creates a new LLM service client with a longer timeout for generation
@@ -24,6 +43,22 @@ func New(baseURL string) *Client {
 	}
 }
 
+// WithPriority returns a copy of the client that queues at the given priority
+// while sharing the same HTTP client, limiter and breaker
+func (c *Client) WithPriority(priority ratelimit.Priority) *Client {
+	clone := *c
+	clone.Priority = priority
+	return &clone
+}
+
+// WithoutCache returns a copy of the client that skips cache lookups but still
+// refreshes the cache with new results
+func (c *Client) WithoutCache() *Client {
+	clone := *c
+	clone.bypassCache = true
+	return &clone
+}
+
 // GenerateRequest represents a request to generate text from the LLM
 type GenerateRequest struct {
 	Prompt      string  `json:"prompt"`
@@ -59,11 +94,18 @@ func (c *Client) GenerateWithLanguage(prompt, context, language string, maxToken
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.HTTP.Post(
-		c.BaseURL+"/generate",
-		"application/json",
-		bytes.NewReader(jsonData),
-	)
+	sum := sha256.Sum256(jsonData)
+	cacheKey := hex.EncodeToString(sum[:])
+	if !c.bypassCache {
+		if cached, ok := c.Cache.Get(cacheKey); ok {
+			return cached, nil
+		}
+	}
+
+	release := c.Limiter.Acquire(c.Priority)
+	defer release()
+
+	resp, err := c.post("/generate", jsonData)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
 	}
@@ -78,5 +120,16 @@ func (c *Client) GenerateWithLanguage(prompt, context, language string, maxToken
 		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	c.Cache.Set(cacheKey, result.Response)
 	return result.Response, nil
 }
+
+// post sends a JSON body to the service through the breaker
+func (c *Client) post(path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, c.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.Breaker.Do(req, c.HTTP.Do)
+}
@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GenerateStream generates a response like GenerateWithLanguage but reads
+// the service's /generate/stream server-sent events, calling onToken with
+// each piece of text as it is produced. Returns the whole response. A cached
+// response is passed to onToken in one piece. The stream stops when ctx is
+// cancelled or onToken returns an error.
+func (c *Client) GenerateStream(ctx context.Context, prompt, context, language string, maxTokens int, temperature float64, onToken func(string) error) (string, error) {
+	jsonData, err := json.Marshal(GenerateRequest{
+		Prompt:      prompt,
+		Context:     context,
+		MaxTokens:   maxTokens,
+		Temperature: temperature,
+		Language:    language,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Shares cache entries with GenerateWithLanguage, which hashes the same body
+	sum := sha256.Sum256(jsonData)
+	cacheKey := hex.EncodeToString(sum[:])
+	if !c.bypassCache {
+		if cached, ok := c.Cache.Get(cacheKey); ok {
+			if err := onToken(cached); err != nil {
+				return "", err
+			}
+			return cached, nil
+		}
+	}
+
+	release := c.Limiter.Acquire(c.Priority)
+	defer release()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/generate/stream", bytes.NewReader(jsonData))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	// The client's timeout bounds whole responses; a stream may run longer
+	// and is bounded by ctx instead
+	streamClient := *c.HTTP
+	streamClient.Timeout = 0
+	resp, err := c.Breaker.Do(req, streamClient.Do)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("llm service returned status %d", resp.StatusCode)
+	}
+
+	var response strings.Builder
+	err = readEvents(resp, func(event string, data []byte) error {
+		var payload struct {
+			Text  string `json:"text"`
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return fmt.Errorf("failed to decode %s event: %w", event, err)
+		}
+		switch event {
+		case "token":
+			response.WriteString(payload.Text)
+			return onToken(payload.Text)
+		case "error":
+			return fmt.Errorf("llm service error: %s", payload.Error)
+		case "done":
+			return errStreamDone
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStreamDone) {
+		return "", err
+	}
+	if err == nil {
+		return "", fmt.Errorf("llm stream ended before completion")
+	}
+
+	c.Cache.Set(cacheKey, response.String())
+	return response.String(), nil
+}
+
+var errStreamDone = errors.New("stream done")
+
+// readEvents calls handle for each server-sent event of a response until
+// the body ends or handle returns an error
+func readEvents(resp *http.Response, handle func(event string, data []byte) error) error {
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+
+	event := "message"
+	var data [][]byte
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		switch {
+		case len(line) == 0:
+			if len(data) > 0 {
+				if err := handle(event, bytes.Join(data, []byte("\n"))); err != nil {
+					return err
+				}
+			}
+			event, data = "message", nil
+		case bytes.HasPrefix(line, []byte(":")):
+			// comment / keep-alive
+		case bytes.HasPrefix(line, []byte("event:")):
+			event = strings.TrimSpace(string(line[len("event:"):]))
+		case bytes.HasPrefix(line, []byte("data:")):
+			data = append(data, bytes.TrimPrefix(bytes.Clone(line[len("data:"):]), []byte(" ")))
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,80 @@
+package logring
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is a captured log line
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// Ring is an io.Writer that keeps the most recent log lines that look like
+// errors. Install it alongside the normal log output with io.MultiWriter.
+type Ring struct {
+	mu       sync.Mutex
+	entries  []Entry
+	next     int
+	full     bool
+	keywords []string
+}
+
+// New creates a ring holding up to size error lines
+func New(size int) *Ring {
+	if size <= 0 {
+		size = 100
+	}
+	return &Ring{
+		entries:  make([]Entry, size),
+		keywords: []string{"error", "failed", "panic"},
+	}
+}
+
+// Write records each line of p that mentions an error keyword
+func (r *Ring) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		lower := strings.ToLower(line)
+		for _, keyword := range r.keywords {
+			if strings.Contains(lower, keyword) {
+				r.add(line)
+				break
+			}
+		}
+	}
+	return len(p), nil
+}
+
+func (r *Ring) add(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = Entry{Time: time.Now().UTC(), Message: line}
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Recent returns up to limit captured lines, newest first
+func (r *Ring) Recent(limit int) []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := r.next
+	if r.full {
+		count = len(r.entries)
+	}
+	if limit <= 0 || limit > count {
+		limit = count
+	}
+
+	recent := make([]Entry, 0, limit)
+	for i := 1; i <= limit; i++ {
+		idx := (r.next - i + len(r.entries)) % len(r.entries)
+		recent = append(recent, r.entries[idx])
+	}
+	return recent
+}
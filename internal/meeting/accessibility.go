@@ -0,0 +1,114 @@
+package meeting
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"realtime-caption-translator/internal/llm"
+)
+
+// CaptionStyle holds display hints for a participant's captions. Clients
+// apply them; the server only validates and echoes them on each caption.
+type CaptionStyle struct {
+	FontSize     string `json:"fontSize,omitempty"` // "normal", "large" or "x-large"
+	HighContrast bool   `json:"highContrast,omitempty"`
+}
+
+// AccessibilitySettings are a participant's caption accessibility options
+type AccessibilitySettings struct {
+	Simplify bool         `json:"simplify"` // receive a plain-language rewrite of each caption
+	Speak    bool         `json:"speak"`    // hear each final caption spoken in the target language
+	Style    CaptionStyle `json:"style"`
+}
+
+var captionFontSizes = map[string]bool{"normal": true, "large": true, "x-large": true}
+
+// captionStyle returns the hints to attach to captions, or nil for defaults
+func (s AccessibilitySettings) captionStyle() *CaptionStyle {
+	if s.Style == (CaptionStyle{}) || s.Style == (CaptionStyle{FontSize: "normal"}) {
+		return nil
+	}
+	style := s.Style
+	return &style
+}
+
+const simplifyPrompt = "Rewrite this live caption in plain, easy-to-read language for someone with reading or cognitive difficulties. Use short sentences and common words, keep every fact and name, and do not add anything. Reply with the rewritten caption only, in the same language."
+
+// SetCaptionSimplifier sets the LLM used to simplify captions; nil turns
+// simplification off (captions are then delivered unchanged)
+func (rm *RoomManager) SetCaptionSimplifier(client *llm.Client) {
+	rm.mu.Lock()
+	rm.simplifier = client
+	rm.mu.Unlock()
+}
+
+// UpdateParticipantAccessibility applies a participant's accessibility
+// options and returns them as stored; unknown font sizes fall back to normal,
+// and spoken captions stay off unless live dubbing is on for the meeting
+func (rm *RoomManager) UpdateParticipantAccessibility(meetingID string, participantID int, settings AccessibilitySettings) AccessibilitySettings {
+	if !captionFontSizes[settings.Style.FontSize] {
+		settings.Style.FontSize = "normal"
+	}
+	if settings.Speak {
+		if _, enabled := rm.speechSubject(meetingID); !enabled {
+			settings.Speak = false
+		}
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if rm.simplifier == nil {
+		settings.Simplify = false
+	}
+	if rm.synthesizer == nil {
+		settings.Speak = false
+	}
+	if room, exists := rm.activeRooms[meetingID]; exists {
+		if participant, exists := room.Participants[participantID]; exists {
+			participant.Accessibility = settings
+		}
+	}
+	return settings
+}
+
+// deliverSimplified sends a final caption to participants in simplification
+// mode, with a plain-language rewrite per target language. Languages are
+// rewritten concurrently and each group gets its caption as soon as its
+// rewrite is done. A failed rewrite falls back to the caption as spoken or
+// translated.
+func (rm *RoomManager) deliverSimplified(recipients []recipient, message Message) {
+	rm.mu.RLock()
+	simplifier := rm.simplifier
+	rm.mu.RUnlock()
+
+	byLanguage := make(map[string][]recipient)
+	for _, to := range recipients {
+		byLanguage[to.language] = append(byLanguage[to.language], to)
+	}
+
+	var wg sync.WaitGroup
+	for language, group := range byLanguage {
+		wg.Add(1)
+		go func(language string, group []recipient) {
+			defer wg.Done()
+
+			text := message.Translations[language]
+			if text == "" {
+				text = message.OriginalText
+			}
+
+			simplified := message
+			if simplifier != nil && strings.TrimSpace(text) != "" {
+				rewrite, err := simplifier.GenerateWithLanguage(simplifyPrompt, text, language, 200, 0.2)
+				if err != nil {
+					log.Printf("Caption simplification failed (%s): %v", language, err)
+				} else {
+					simplified.Simplified = strings.TrimSpace(rewrite)
+				}
+			}
+			deliver(group, simplified)
+		}(language, group)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,127 @@
+package meeting
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// Source chunk matching for action items
+const (
+	maxActionItemSources = 2    // chunks linked to one action item
+	minActionItemOverlap = 0.34 // share of the item's words a chunk must contain
+)
+
+// actionItemReply is an action item as the LLM returns it: an object, or a
+// plain string from models that ignore the requested shape
+type actionItemReply struct {
+	Task     string `json:"task"`
+	Assignee string `json:"assignee"`
+	DueDate  string `json:"due_date"`
+}
+
+func (a *actionItemReply) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*a = actionItemReply{Task: strings.TrimSpace(text)}
+		return nil
+	}
+	type plain actionItemReply
+	var item plain
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+	*a = actionItemReply{
+		Task:     strings.TrimSpace(item.Task),
+		Assignee: strings.TrimSpace(item.Assignee),
+		DueDate:  strings.TrimSpace(item.DueDate),
+	}
+	return nil
+}
+
+// String renders the item for the minutes' action item list
+func (a actionItemReply) String() string {
+	var details []string
+	if a.Assignee != "" {
+		details = append(details, a.Assignee)
+	}
+	if a.DueDate != "" {
+		details = append(details, "due "+a.DueDate)
+	}
+	if len(details) == 0 {
+		return a.Task
+	}
+	return fmt.Sprintf("%s (%s)", a.Task, strings.Join(details, ", "))
+}
+
+// saveActionItems stores the extracted action items of a meeting/language,
+// each linked to the transcript chunks that mention it
+func saveActionItems(meetingID, language string, items []actionItemReply) error {
+	chunks, err := database.GetChunksByMeeting(meetingID, language)
+	if err != nil {
+		return err
+	}
+
+	records := make([]database.MeetingActionItem, 0, len(items))
+	for _, item := range items {
+		records = append(records, database.MeetingActionItem{
+			Text:           item.Task,
+			Assignee:       item.Assignee,
+			DueDate:        item.DueDate,
+			SourceChunkIDs: matchSourceChunks(item, chunks),
+		})
+	}
+	return database.ReplaceExtractedActionItems(meetingID, language, records)
+}
+
+// matchSourceChunks returns the chunks sharing the most words with an
+// action item, best first
+func matchSourceChunks(item actionItemReply, chunks []database.MeetingChunk) []int {
+	words := significantWords(item.Task + " " + item.Assignee)
+	if len(words) == 0 {
+		return []int{}
+	}
+
+	type match struct {
+		id    int
+		score float64
+	}
+	var matches []match
+	for _, chunk := range chunks {
+		chunkWords := significantWords(chunk.ChunkText)
+		shared := 0
+		for word := range words {
+			if chunkWords[word] {
+				shared++
+			}
+		}
+		if score := float64(shared) / float64(len(words)); score >= minActionItemOverlap {
+			matches = append(matches, match{id: chunk.ID, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	ids := []int{}
+	for i := 0; i < len(matches) && i < maxActionItemSources; i++ {
+		ids = append(ids, matches[i].id)
+	}
+	return ids
+}
+
+// significantWords returns the lowercase words of text longer than three
+// letters, which skips most articles and pronouns in the common languages
+func significantWords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		if len([]rune(word)) > 3 {
+			words[word] = true
+		}
+	}
+	return words
+}
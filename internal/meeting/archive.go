@@ -0,0 +1,59 @@
+package meeting
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"realtime-caption-translator/internal/audioarchive"
+)
+
+// audioArchive keeps the audio of consented speech; nil archives nothing
+var audioArchive *audioarchive.Archive
+
+// SetAudioArchive stores the audio of each transcribed chunk so captions can
+// be audited. Call before rooms are served.
+func SetAudioArchive(archive *audioarchive.Archive) {
+	audioArchive = archive
+}
+
+// chunkAudio is a chunk's archived audio. The upload runs alongside
+// transcription; ref waits for it.
+type chunkAudio struct {
+	done chan struct{}
+	id   int64
+}
+
+// archiveChunk starts archiving a chunk of a speaker who consented to
+// recording, or returns nil
+func (rm *RoomManager) archiveChunk(meetingID string, participantID int, wavData []byte, duration time.Duration) *chunkAudio {
+	if audioArchive == nil || !rm.speakerConsented(meetingID, participantID) {
+		return nil
+	}
+	audio := &chunkAudio{done: make(chan struct{})}
+	go func() {
+		defer close(audio.done)
+		id, err := audioArchive.Store(context.Background(), audioarchive.Chunk{
+			SessionType:   audioarchive.SessionMeeting,
+			SessionID:     meetingID,
+			ParticipantID: participantID,
+			WAV:           wavData,
+			Duration:      duration.Seconds(),
+		})
+		if err != nil {
+			log.Printf("[AudioArchive] Failed to archive chunk of participant %d in %s: %v", participantID, meetingID, err)
+			return
+		}
+		audio.id = id
+	}()
+	return audio
+}
+
+// ref returns the archived chunk's ID once stored, or 0
+func (a *chunkAudio) ref() int64 {
+	if a == nil {
+		return 0
+	}
+	<-a.done
+	return a.id
+}
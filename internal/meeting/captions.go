@@ -0,0 +1,229 @@
+package meeting
+
+import (
+	"sync"
+	"time"
+)
+
+// Caption is a transcript line delivered to caption consumers such as
+// broadcast overlays
+type Caption struct {
+	Text        string    `json:"text"`
+	Language    string    `json:"language"`
+	SpeakerName string    `json:"speakerName,omitempty"`
+	Timestamp   time.Time `json:"timestamp"` // when the speech was captioned
+	ReleaseAt   time.Time `json:"releaseAt"` // when the consumer was allowed to show it
+}
+
+// maxPendingCaptions bounds a consumer's delay buffer; the oldest captions
+// are dropped first if a consumer stops reading
+const maxPendingCaptions = 500
+
+// CaptionConsumer receives a meeting's captions in one language, each held
+// back until its timestamp plus the consumer's delay. This lets an overlay
+// line captions up with a delayed video feed.
+type CaptionConsumer struct {
+	Language string
+
+	captions chan Caption
+	wake     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+
+	mu      sync.Mutex
+	delay   time.Duration
+	pending []Caption
+	ended   bool // meeting ended: close once the buffer drains
+}
+
+func newCaptionConsumer(language string, delay time.Duration) *CaptionConsumer {
+	c := &CaptionConsumer{
+		Language: language,
+		captions: make(chan Caption, 16),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		delay:    delay,
+	}
+	go c.run()
+	return c
+}
+
+// Captions returns the channel captions are released on
+func (c *CaptionConsumer) Captions() <-chan Caption {
+	return c.captions
+}
+
+// Delay returns the consumer's current delay
+func (c *CaptionConsumer) Delay() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.delay
+}
+
+// SetDelay changes the delay. Captions already buffered are rescheduled
+// against the new delay.
+func (c *CaptionConsumer) SetDelay(delay time.Duration) {
+	c.mu.Lock()
+	c.delay = delay
+	c.mu.Unlock()
+	c.signal()
+}
+
+// enqueue buffers a caption until its release time
+func (c *CaptionConsumer) enqueue(caption Caption) {
+	c.mu.Lock()
+	if len(c.pending) >= maxPendingCaptions {
+		c.pending = c.pending[1:]
+	}
+	c.pending = append(c.pending, caption)
+	c.mu.Unlock()
+	c.signal()
+}
+
+func (c *CaptionConsumer) signal() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// finish closes the consumer after its buffered captions have been released
+func (c *CaptionConsumer) finish() {
+	c.mu.Lock()
+	c.ended = true
+	c.mu.Unlock()
+	c.signal()
+}
+
+func (c *CaptionConsumer) close() {
+	c.once.Do(func() { close(c.done) })
+}
+
+// run releases buffered captions in order once timestamp + delay has passed
+func (c *CaptionConsumer) run() {
+	defer close(c.captions)
+
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	for {
+		c.mu.Lock()
+		var next *Caption
+		var wait time.Duration
+		if len(c.pending) > 0 {
+			head := c.pending[0]
+			head.ReleaseAt = head.Timestamp.Add(c.delay)
+			wait = time.Until(head.ReleaseAt)
+			if wait <= 0 {
+				c.pending = c.pending[1:]
+				next = &head
+			}
+		}
+		hasPending := len(c.pending) > 0
+		ended := c.ended
+		c.mu.Unlock()
+
+		if next != nil {
+			select {
+			case c.captions <- *next:
+				continue
+			case <-c.done:
+				return
+			}
+		}
+
+		if !hasPending && ended {
+			return
+		}
+		if hasPending {
+			timer.Reset(wait)
+		}
+		select {
+		case <-timer.C:
+		case <-c.wake:
+			timer.Stop()
+		case <-c.done:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// captionFromMessage extracts the text a consumer in language should see
+func captionFromMessage(message Message, language string) (Caption, bool) {
+	text := message.OriginalText
+	if language != "" && language != message.SourceLanguage {
+		translated, ok := message.Translations[language]
+		if !ok || translated == "" {
+			return Caption{}, false
+		}
+		text = translated
+	}
+	if text == "" {
+		return Caption{}, false
+	}
+
+	lang := language
+	if lang == "" {
+		lang = message.SourceLanguage
+	}
+	return Caption{
+		Text:        text,
+		Language:    lang,
+		SpeakerName: message.SpeakerName,
+		Timestamp:   message.Timestamp,
+	}, true
+}
+
+// SubscribeCaptions registers a caption consumer for a meeting. Language ""
+// follows the spoken (source) language. The returned function unsubscribes
+// and closes the consumer's channel.
+func (rm *RoomManager) SubscribeCaptions(meetingID, language string, delay time.Duration) (*CaptionConsumer, func()) {
+	consumer := newCaptionConsumer(language, delay)
+
+	rm.captionMu.Lock()
+	if rm.captionConsumers[meetingID] == nil {
+		rm.captionConsumers[meetingID] = make(map[*CaptionConsumer]struct{})
+	}
+	rm.captionConsumers[meetingID][consumer] = struct{}{}
+	rm.captionMu.Unlock()
+
+	return consumer, func() {
+		rm.captionMu.Lock()
+		delete(rm.captionConsumers[meetingID], consumer)
+		if len(rm.captionConsumers[meetingID]) == 0 {
+			delete(rm.captionConsumers, meetingID)
+		}
+		rm.captionMu.Unlock()
+		consumer.close()
+	}
+}
+
+// publishCaptions hands a final transcription to the meeting's caption consumers
+func (rm *RoomManager) publishCaptions(meetingID string, message Message) {
+	rm.captionMu.RLock()
+	consumers := make([]*CaptionConsumer, 0, len(rm.captionConsumers[meetingID]))
+	for consumer := range rm.captionConsumers[meetingID] {
+		consumers = append(consumers, consumer)
+	}
+	rm.captionMu.RUnlock()
+
+	for _, consumer := range consumers {
+		if caption, ok := captionFromMessage(message, consumer.Language); ok {
+			consumer.enqueue(caption)
+		}
+	}
+}
+
+// finishCaptions ends every caption consumer of a meeting once their delay
+// buffers drain
+func (rm *RoomManager) finishCaptions(meetingID string) {
+	rm.captionMu.Lock()
+	consumers := rm.captionConsumers[meetingID]
+	delete(rm.captionConsumers, meetingID)
+	rm.captionMu.Unlock()
+
+	for consumer := range consumers {
+		consumer.finish()
+	}
+}
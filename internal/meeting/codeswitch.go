@@ -0,0 +1,31 @@
+package meeting
+
+import "strings"
+
+// LanguageSegment is a part of a caption spoken in one language
+type LanguageSegment struct {
+	Text     string `json:"text"`
+	Language string `json:"language"`
+}
+
+// languageRuns joins consecutive segments in the same language. Returns nil
+// unless the segments switch language, so a single-language chunk is
+// handled as a whole.
+func languageRuns(segments []LanguageSegment) []LanguageSegment {
+	var runs []LanguageSegment
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" || segment.Language == "" {
+			continue
+		}
+		if last := len(runs) - 1; last >= 0 && runs[last].Language == segment.Language {
+			runs[last].Text += " " + text
+			continue
+		}
+		runs = append(runs, LanguageSegment{Text: text, Language: segment.Language})
+	}
+	if len(runs) < 2 {
+		return nil
+	}
+	return runs
+}
@@ -0,0 +1,165 @@
+package meeting
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/i18n"
+)
+
+// consentAnnouncement is shown to every participant when they join, in their
+// target language
+const consentAnnouncement = "This meeting is transcribed and translated. With your consent, your speech is also saved to the meeting transcript and its searchable history. If you decline, your speech is still captioned live for other participants but is never stored."
+
+// ParticipantConsent is the consent status of one participant, as shown to
+// the meeting owner
+type ParticipantConsent struct {
+	ParticipantID   int        `json:"participantId"`
+	ParticipantName string     `json:"participantName"`
+	UserID          *int       `json:"userId,omitempty"`
+	Status          string     `json:"status"` // "granted", "declined" or "pending"
+	Connected       bool       `json:"connected"`
+	RespondedAt     *time.Time `json:"respondedAt,omitempty"`
+}
+
+// Consent statuses reported to the owner
+const (
+	ConsentGranted  = "granted"
+	ConsentDeclined = "declined"
+	ConsentPending  = "pending"
+)
+
+// requestConsent sends the consent prompt to a participant that just joined.
+// A signed-in user who already answered in this meeting keeps their answer
+// across reconnects and is only reminded of it.
+func (rm *RoomManager) requestConsent(meetingID string, participant *Participant, userID *int) {
+	language := participant.TargetLanguage
+	message := Message{
+		Type:         "consent_request",
+		Announcement: i18n.T(language, consentAnnouncement),
+		Timestamp:    time.Now().UTC(),
+	}
+
+	if userID != nil {
+		previous, err := database.GetUserMeetingConsent(meetingID, *userID)
+		if err != nil {
+			log.Printf("Failed to load consent for user %d in meeting %s: %v", *userID, meetingID, err)
+		} else if previous != nil {
+			rm.recordConsent(meetingID, participant, userID, previous.Consented)
+			message.Type = "consent_status"
+			message.Consented = &previous.Consented
+			message.Text = i18n.T(language, "You declined recording earlier; your speech is captioned live only.")
+			if previous.Consented {
+				message.Text = i18n.T(language, "You agreed to recording earlier; your speech is saved to the transcript.")
+			}
+		}
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	participant.outbox.enqueue(payload)
+}
+
+// recordConsent stores a participant's answer and applies it to the live room
+func (rm *RoomManager) recordConsent(meetingID string, participant *Participant, userID *int, granted bool) {
+	if err := database.SaveMeetingConsent(&database.MeetingConsent{
+		MeetingID:       meetingID,
+		ParticipantID:   participant.ID,
+		UserID:          userID,
+		ParticipantName: participant.Name,
+		Consented:       granted,
+	}); err != nil {
+		// Without a stored answer the participant stays live-only
+		log.Printf("Failed to save consent for participant %d: %v", participant.ID, err)
+		return
+	}
+
+	rm.mu.Lock()
+	participant.Consent = &granted
+	rm.mu.Unlock()
+
+	text := "%s declined recording"
+	if granted {
+		text = "%s agreed to recording"
+	}
+	rm.Broadcast(meetingID, Message{
+		Type:            "participant_consent_updated",
+		ParticipantID:   participant.ID,
+		ParticipantName: participant.Name,
+		Consented:       &granted,
+	}.withText(text, participant.Name))
+}
+
+// speakerConsented reports whether a participant's speech may be stored.
+// Participants who have not answered yet, or who already left, are treated
+// as declined.
+func (rm *RoomManager) speakerConsented(meetingID string, participantID int) bool {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	room, exists := rm.activeRooms[meetingID]
+	if !exists {
+		return false
+	}
+	participant, exists := room.Participants[participantID]
+	if !exists || participant.Consent == nil {
+		return false
+	}
+	return *participant.Consent
+}
+
+// GetConsentStatus merges stored answers with the participants currently in
+// the room, so participants who have not answered yet show as pending
+func (rm *RoomManager) GetConsentStatus(meetingID string) ([]ParticipantConsent, error) {
+	stored, err := database.ListMeetingConsents(meetingID)
+	if err != nil {
+		return nil, err
+	}
+
+	byParticipant := make(map[int]*ParticipantConsent)
+	for _, consent := range stored {
+		status := ConsentDeclined
+		if consent.Consented {
+			status = ConsentGranted
+		}
+		respondedAt := consent.RespondedAt
+		byParticipant[consent.ParticipantID] = &ParticipantConsent{
+			ParticipantID:   consent.ParticipantID,
+			ParticipantName: consent.ParticipantName,
+			UserID:          consent.UserID,
+			Status:          status,
+			RespondedAt:     &respondedAt,
+		}
+	}
+
+	rm.mu.RLock()
+	if room, exists := rm.activeRooms[meetingID]; exists {
+		for _, participant := range room.Participants {
+			entry, answered := byParticipant[participant.ID]
+			if !answered {
+				entry = &ParticipantConsent{
+					ParticipantID:   participant.ID,
+					ParticipantName: participant.Name,
+					Status:          ConsentPending,
+				}
+				byParticipant[participant.ID] = entry
+			}
+			entry.Connected = true
+		}
+	}
+	rm.mu.RUnlock()
+
+	statuses := make([]ParticipantConsent, 0, len(byParticipant))
+	for _, entry := range byParticipant {
+		statuses = append(statuses, *entry)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ParticipantID < statuses[j].ParticipantID
+	})
+	return statuses, nil
+}
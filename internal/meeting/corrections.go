@@ -0,0 +1,102 @@
+package meeting
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/hooks"
+)
+
+// ErrCaptionNotFound is returned when a correction names no stored caption
+// of the meeting
+var ErrCaptionNotFound = errors.New("caption not found")
+
+// ErrCaptionDropped is returned when the post-transcription hooks drop a
+// correction or leave no text
+var ErrCaptionDropped = errors.New("correction dropped by a pipeline hook")
+
+// CorrectCaption replaces the text of a finalized caption, identified by
+// its transcript entry ID. The corrected text goes through the
+// post-transcription hooks like recognized speech and is translated again
+// into the languages the caption was stored in and those the room listens
+// in now. The stored entry keeps the text ASR produced, and participants get a
+// "caption_updated" message so they can replace the caption on screen. The
+// message is logged, so reconnecting participants replay it after the
+// caption it applies to. Snapshots of a meeting that is no longer running
+// are rebuilt.
+func (rm *RoomManager) CorrectCaption(meetingID string, entryID int64, text string, editorID *int) (*database.MeetingTranscriptEntry, error) {
+	entry, err := database.GetMeetingTranscriptEntry(meetingID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrCaptionNotFound
+	}
+
+	text, ok := hooks.Run(hooks.PostTranscription, hooks.Event{
+		Source:    hooks.SourceMeeting,
+		MeetingID: meetingID,
+		Speaker:   entry.SpeakerName,
+		Text:      text,
+		Language:  entry.Language,
+		Final:     true,
+	})
+	if text = strings.TrimSpace(text); !ok || text == "" {
+		return nil, ErrCaptionDropped
+	}
+
+	languages := rm.GetUniqueTargetLanguages(meetingID)
+	for lang := range entry.Translations {
+		languages = appendUnique(languages, lang)
+	}
+	translations := translateParallel(hooks.Event{
+		Source:         hooks.SourceMeeting,
+		MeetingID:      meetingID,
+		Speaker:        entry.SpeakerName,
+		Text:           text,
+		SourceLanguage: entry.Language,
+		Final:          true,
+	}, languages)
+
+	corrected, err := database.CorrectMeetingTranscriptEntry(meetingID, entryID, text, translations, editorID)
+	if err != nil {
+		return nil, err
+	}
+	if corrected == nil {
+		return nil, ErrCaptionNotFound
+	}
+
+	if room := rm.GetRoom(meetingID); room != nil {
+		room.CorrectTranscript(entryID, corrected.Text, corrected.Translations)
+		rm.Broadcast(meetingID, Message{
+			Type:                 "caption_updated",
+			EntryID:              corrected.ID,
+			SegmentID:            corrected.SegmentID,
+			SpeakerParticipantID: corrected.ParticipantID,
+			SpeakerID:            corrected.SpeakerID,
+			SpeakerName:          corrected.SpeakerName,
+			OriginalText:         corrected.Text,
+			SourceLanguage:       corrected.Language,
+			Translations:         corrected.Translations,
+		})
+	} else if err := rebuildTranscriptSnapshots(meetingID); err != nil {
+		log.Printf("Failed to rebuild transcript snapshots of meeting %s: %v", meetingID, err)
+	}
+	return corrected, nil
+}
+
+// appendUnique appends lang to langs unless it is empty or already there
+func appendUnique(langs []string, lang string) []string {
+	lang = strings.TrimSpace(lang)
+	if lang == "" {
+		return langs
+	}
+	for _, existing := range langs {
+		if existing == lang {
+			return langs
+		}
+	}
+	return append(langs, lang)
+}
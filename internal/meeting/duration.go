@@ -0,0 +1,121 @@
+package meeting
+
+import (
+	"log"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// durationWarnings are the times before the deadline at which participants
+// are warned, longest first
+var durationWarnings = []time.Duration{10 * time.Minute, time.Minute}
+
+// SetAutoEndHandler sets the finalization that runs after a meeting is ended
+// for reaching its maximum duration (e.g. minutes generation)
+func (rm *RoomManager) SetAutoEndHandler(handler func(meetingID string)) {
+	rm.mu.Lock()
+	rm.onAutoEnd = handler
+	rm.mu.Unlock()
+}
+
+// loadDurationLimit sets a room's deadline from the meeting's stored limit
+// the first time someone joins it
+func (rm *RoomManager) loadDurationLimit(dbMeeting *database.Meeting) {
+	rm.mu.RLock()
+	room, exists := rm.activeRooms[dbMeeting.ID]
+	loaded := exists && room.limitLoaded
+	rm.mu.RUnlock()
+	if !exists || loaded {
+		return
+	}
+
+	minutes, err := database.GetMeetingMaxDuration(dbMeeting.ID)
+	if err != nil {
+		// Try again on the next join
+		log.Printf("Failed to load duration limit for meeting %s: %v", dbMeeting.ID, err)
+		return
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if room.limitLoaded {
+		return
+	}
+	room.limitLoaded = true
+	if minutes > 0 {
+		room.deadline = dbMeeting.CreatedAt.Add(time.Duration(minutes) * time.Minute)
+		room.warned = make(map[time.Duration]bool)
+		log.Printf("Meeting %s ends automatically at %s", dbMeeting.ID, room.deadline.UTC().Format(time.RFC3339))
+	}
+}
+
+type durationWarning struct {
+	meetingID string
+	deadline  time.Time
+	remaining time.Duration
+}
+
+// checkDurations warns participants as meetings approach their maximum
+// duration and ends them when it is reached
+func (rm *RoomManager) checkDurations(now time.Time) {
+	var expired []string
+	var warnings []durationWarning
+
+	rm.mu.Lock()
+	for meetingID, room := range rm.activeRooms {
+		if room.deadline.IsZero() {
+			continue
+		}
+		remaining := room.deadline.Sub(now)
+		if remaining <= 0 {
+			expired = append(expired, meetingID)
+			continue
+		}
+		// Only the closest threshold is sent, so joining late does not
+		// produce a burst of warnings
+		for i := len(durationWarnings) - 1; i >= 0; i-- {
+			threshold := durationWarnings[i]
+			if remaining > threshold {
+				continue
+			}
+			if !room.warned[threshold] {
+				for _, t := range durationWarnings[:i+1] {
+					room.warned[t] = true
+				}
+				warnings = append(warnings, durationWarning{meetingID, room.deadline, remaining})
+			}
+			break
+		}
+	}
+	onAutoEnd := rm.onAutoEnd
+	rm.mu.Unlock()
+
+	for _, w := range warnings {
+		deadline := w.deadline.UTC()
+		minutes := int((w.remaining + time.Minute - 1) / time.Minute)
+		message := Message{
+			Type:             "meeting_ending_soon",
+			EndsAt:           &deadline,
+			MinutesRemaining: minutes,
+		}
+		if minutes == 1 {
+			message = message.withText("This meeting will end automatically in 1 minute")
+		} else {
+			message = message.withText("This meeting will end automatically in %d minutes", minutes)
+		}
+		rm.Broadcast(w.meetingID, message)
+	}
+
+	for _, meetingID := range expired {
+		log.Printf("Meeting %s reached its maximum duration, ending it", meetingID)
+		ended := Message{Type: "meeting_ended"}.withText("This meeting reached its maximum duration and has ended")
+		if err := rm.endMeeting(meetingID, ended); err != nil {
+			log.Printf("Failed to end meeting %s at its duration limit: %v", meetingID, err)
+			continue
+		}
+		if onAutoEnd != nil {
+			go onAutoEnd(meetingID)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package meeting
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/i18n"
+)
+
+// maxReplayEvents bounds how many missed events a reconnecting participant
+// is sent; older ones can be read from the events API
+const maxReplayEvents = 1000
+
+// recordEvent appends a broadcast message to the meeting's event log and sets
+// its sequence number. Partial captions and sentence segments are not
+// logged, since the final caption follows them; captions of speakers
+// without recording consent are logged without their text.
+func recordEvent(meetingID string, message *Message) {
+	if message.Type == "transcription" && !message.IsFinal || message.Type == "translation_segment" ||
+		message.Type == "speaking_started" || message.Type == "speaking_stopped" {
+		return
+	}
+
+	stored := *message
+	if stored.textFormat != "" {
+		stored.Text = i18n.Sprintf(i18n.DefaultLanguage, stored.textFormat, stored.textArgs...)
+	}
+	if stored.LiveOnly {
+		stored.OriginalText = ""
+		stored.Translations = nil
+		stored.Simplified = ""
+	}
+	payload, err := json.Marshal(stored)
+	if err != nil {
+		log.Printf("Failed to encode meeting event %s: %v", message.Type, err)
+		return
+	}
+
+	participantID := message.ParticipantID
+	if participantID == 0 {
+		participantID = message.SpeakerParticipantID
+	}
+	seq, err := database.AppendMeetingEvent(meetingID, message.Type, participantID, payload)
+	if err != nil {
+		log.Printf("Failed to record meeting event %s for %s: %v", message.Type, meetingID, err)
+		return
+	}
+	message.Seq = seq
+}
+
+// replayEvents sends a reconnecting participant the logged events after
+// sinceSeq, marked as replayed, followed by replay_complete with the last
+// sequence number sent. Live messages may arrive in between; clients drop
+// sequence numbers they have already seen.
+func replayEvents(out *outbox, meetingID string, sinceSeq int64) {
+	events, err := database.ListMeetingEvents(meetingID, sinceSeq, nil, maxReplayEvents)
+	if err != nil {
+		log.Printf("Failed to load meeting events for replay: %v", err)
+		return
+	}
+
+	lastSeq := sinceSeq
+	for _, event := range events {
+		var message Message
+		if err := json.Unmarshal(event.Payload, &message); err != nil {
+			continue
+		}
+		message.Seq = event.Seq
+		message.Replayed = true
+		data, err := json.Marshal(message)
+		if err != nil {
+			continue
+		}
+		out.enqueueWait(data)
+		lastSeq = event.Seq
+	}
+
+	data, err := json.Marshal(Message{Type: "replay_complete", Seq: lastSeq, Timestamp: time.Now().UTC()})
+	if err != nil {
+		log.Printf("Error marshaling replay_complete: %v", err)
+		return
+	}
+	out.enqueueWait(data)
+}
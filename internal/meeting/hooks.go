@@ -0,0 +1,71 @@
+package meeting
+
+import (
+	"strings"
+
+	"realtime-caption-translator/internal/hooks"
+)
+
+// transcriptionHooks runs the post-transcription hooks over a caption's
+// text and stores what they leave. Returns false when a hook dropped the
+// caption or left no text.
+func transcriptionHooks(meetingID string, message *Message) bool {
+	if len(message.LanguageSegments) > 0 {
+		return segmentTranscriptionHooks(meetingID, message)
+	}
+	text, ok := hooks.Run(hooks.PostTranscription, hooks.Event{
+		Source:    hooks.SourceMeeting,
+		MeetingID: meetingID,
+		Speaker:   message.SpeakerName,
+		Text:      message.OriginalText,
+		Language:  message.SourceLanguage,
+		Final:     message.IsFinal,
+	})
+	if !ok || strings.TrimSpace(text) == "" {
+		return false
+	}
+	message.OriginalText = text
+	return true
+}
+
+// translationEvent describes a caption's text for the translation hooks
+func translationEvent(meetingID string, message *Message, text string) hooks.Event {
+	return hooks.Event{
+		Source:         hooks.SourceMeeting,
+		MeetingID:      meetingID,
+		Speaker:        message.SpeakerName,
+		Text:           text,
+		SourceLanguage: message.SourceLanguage,
+		Final:          message.IsFinal,
+	}
+}
+
+// segmentTranscriptionHooks runs the post-transcription hooks over each
+// language segment of a code-switched caption, in its own language, and
+// rebuilds the caption's text from what they leave
+func segmentTranscriptionHooks(meetingID string, message *Message) bool {
+	var kept []LanguageSegment
+	var texts []string
+	for _, segment := range message.LanguageSegments {
+		text, ok := hooks.Run(hooks.PostTranscription, hooks.Event{
+			Source:    hooks.SourceMeeting,
+			MeetingID: meetingID,
+			Speaker:   message.SpeakerName,
+			Text:      segment.Text,
+			Language:  segment.Language,
+			Final:     message.IsFinal,
+		})
+		if !ok || strings.TrimSpace(text) == "" {
+			continue
+		}
+		segment.Text = text
+		kept = append(kept, segment)
+		texts = append(texts, text)
+	}
+	if len(kept) == 0 {
+		return false
+	}
+	message.OriginalText = strings.Join(texts, " ")
+	message.LanguageSegments = kept
+	return true
+}
@@ -0,0 +1,72 @@
+package meeting
+
+import (
+	"log"
+	"time"
+)
+
+// SetIdleTimeout sets how long a room may go without voice activity before
+// its processing is suspended; 0 disables suspension
+func (rm *RoomManager) SetIdleTimeout(timeout time.Duration) {
+	rm.mu.Lock()
+	rm.idleTimeout = timeout
+	rm.mu.Unlock()
+}
+
+// markVoiceActivity records that someone in the room is speaking
+func (rm *RoomManager) markVoiceActivity(meetingID string) {
+	rm.mu.Lock()
+	if room, exists := rm.activeRooms[meetingID]; exists {
+		room.lastVoiceAt = time.Now()
+	}
+	rm.mu.Unlock()
+}
+
+// isSuspended reports whether a room's processing is paused for idleness
+func (rm *RoomManager) isSuspended(meetingID string) bool {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	room, exists := rm.activeRooms[meetingID]
+	return exists && room.suspended
+}
+
+// resumeRoom lifts an idle suspension as soon as voice is heard again
+func (rm *RoomManager) resumeRoom(meetingID string) {
+	rm.mu.Lock()
+	room, exists := rm.activeRooms[meetingID]
+	if !exists || !room.suspended {
+		rm.mu.Unlock()
+		return
+	}
+	room.suspended = false
+	room.lastVoiceAt = time.Now()
+	rm.mu.Unlock()
+
+	log.Printf("Meeting room %s resumed on voice activity", meetingID)
+	rm.Broadcast(meetingID, Message{Type: "room_resumed"}.withText("Captioning resumed"))
+}
+
+// checkIdle suspends rooms that have had no voice activity for the idle timeout
+func (rm *RoomManager) checkIdle(now time.Time) {
+	var suspended []*Room
+
+	rm.mu.Lock()
+	timeout := rm.idleTimeout
+	if timeout > 0 {
+		for _, room := range rm.activeRooms {
+			if !room.suspended && now.Sub(room.lastVoiceAt) >= timeout {
+				room.suspended = true
+				suspended = append(suspended, room)
+			}
+		}
+	}
+	rm.mu.Unlock()
+
+	for _, room := range suspended {
+		// Shared-mode audio waiting to be mixed is silence; drop it
+		room.ClearAudioBuffers()
+		log.Printf("Meeting room %s suspended after %s without voice activity", room.MeetingID, timeout)
+		rm.Broadcast(room.MeetingID, Message{Type: "room_suspended"}.
+			withText("No one has spoken for a while; captioning is paused until someone speaks"))
+	}
+}
@@ -0,0 +1,98 @@
+package meeting
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/rag"
+)
+
+// ErrIngestionRunning is returned when a meeting is re-ingested while an
+// ingestion of it is still pending or processing
+var ErrIngestionRunning = errors.New("meeting is already being ingested")
+
+// staleIngestionAfter is how long an ingestion may go without a status
+// update before it is assumed abandoned and a re-ingestion may start
+const staleIngestionAfter = 30 * time.Minute
+
+// IngestTranscripts chunks and embeds transcripts (language -> text) of a
+// meeting in the background, one goroutine per language, recording each
+// language's ingestion status as it goes
+func IngestTranscripts(meetingID string, processor *rag.Processor, transcripts map[string]string) {
+	for language := range transcripts {
+		if err := database.SetRAGIngestionStatus(meetingID, language, database.IngestionPending, ""); err != nil {
+			log.Printf("[RAG] %v", err)
+		}
+	}
+	ingestTranscripts(meetingID, processor, transcripts)
+}
+
+// ingestTranscripts processes transcripts already marked pending
+func ingestTranscripts(meetingID string, processor *rag.Processor, transcripts map[string]string) {
+	for language, transcript := range transcripts {
+		go func(language, transcript string) {
+			if err := database.SetRAGIngestionStatus(meetingID, language, database.IngestionProcessing, ""); err != nil {
+				log.Printf("[RAG] %v", err)
+			}
+
+			// Chunks that failed to embed in a partly successful ingestion are
+			// retried by the retry worker; the ingestion still completes
+			status, errMsg := database.IngestionCompleted, ""
+			var partial *rag.PartialError
+			if err := processor.ProcessMeetingTranscript(meetingID, language, transcript); errors.As(err, &partial) {
+				log.Printf("[RAG] Partial processing of meeting %s (language: %s): %v", meetingID, language, err)
+				errMsg = err.Error()
+			} else if err != nil {
+				log.Printf("[RAG] Processing error for meeting %s (language: %s): %v", meetingID, language, err)
+				status, errMsg = database.IngestionFailed, err.Error()
+			}
+			if err := database.SetRAGIngestionStatus(meetingID, language, status, errMsg); err != nil {
+				log.Printf("[RAG] %v", err)
+			}
+		}(language, transcript)
+	}
+}
+
+// ReingestMeeting ingests a meeting's stored transcript snapshots again,
+// e.g. after an ingestion failed. Chunks whose content did not change keep
+// their embeddings. Returns the languages being ingested, or
+// ErrIngestionRunning while another ingestion is pending or processing.
+func ReingestMeeting(meetingID string, processor *rag.Processor) ([]string, error) {
+	if processor == nil {
+		return nil, fmt.Errorf("RAG processing is not configured")
+	}
+	snapshots, err := database.ListMeetingTranscriptSnapshots(meetingID)
+	if err != nil {
+		return nil, err
+	}
+
+	transcripts := make(map[string]string, len(snapshots))
+	languages := make([]string, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		full, err := database.GetMeetingTranscriptSnapshot(meetingID, snapshot.Language)
+		if err != nil {
+			return nil, err
+		}
+		if full == nil {
+			continue
+		}
+		transcripts[snapshot.Language] = full.Transcript
+		languages = append(languages, snapshot.Language)
+	}
+	if len(transcripts) == 0 {
+		return nil, fmt.Errorf("meeting %s has no transcript snapshots", meetingID)
+	}
+
+	started, err := database.StartRAGIngestions(meetingID, languages, staleIngestionAfter)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, ErrIngestionRunning
+	}
+	ingestTranscripts(meetingID, processor, transcripts)
+	return languages, nil
+}
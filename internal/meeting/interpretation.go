@@ -0,0 +1,267 @@
+package meeting
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"realtime-caption-translator/internal/benchmark"
+	"realtime-caption-translator/internal/database"
+)
+
+// ModeInterpreted is the meeting mode where a human interpreter streams a
+// second audio channel alongside the floor audio
+const ModeInterpreted = "interpreted"
+
+// CanInterpret reports whether a connection may join a meeting as its
+// interpreter channel: moderators (the host token, owners and co-hosts) and
+// users designated as the meeting's interpreters can
+func CanInterpret(meetingID string, userID *int, hostToken string) bool {
+	if canModerate, _ := hostPowers(meetingID, userID, hostToken); canModerate {
+		return true
+	}
+	if userID == nil {
+		return false
+	}
+	designated, err := database.IsMeetingInterpreter(meetingID, *userID)
+	if err != nil {
+		log.Printf("Failed to check interpreter %d in meeting %s: %v", *userID, meetingID, err)
+	}
+	return designated
+}
+
+// interpretLanguage returns the interpretation language when the participant
+// is the meeting's interpreter channel
+func (rm *RoomManager) interpretLanguage(meetingID string, participantID int) string {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	room, exists := rm.activeRooms[meetingID]
+	if !exists {
+		return ""
+	}
+	participant, exists := room.Participants[participantID]
+	if !exists {
+		return ""
+	}
+	return participant.InterpretLanguage
+}
+
+// processInterpretedAudio transcribes one chunk of an interpreted meeting.
+// Floor audio is handled like individual mode; the interpreter's audio is
+// broadcast as an "interpretation" message. Both are stored as timed segments
+// when the speaker has consented to recording.
+func (rm *RoomManager) processInterpretedAudio(meetingID string, participantID int, participantName string, wavData []byte, targetLangs []string, start, end time.Time, audio *chunkAudio) {
+	interpretLang := rm.interpretLanguage(meetingID, participantID)
+	if interpretLang == "" {
+		message := rm.processIndividualAudio(meetingID, participantID, participantName, wavData, targetLangs, start, end, audio)
+		if message == nil || !rm.speakerConsented(meetingID, participantID) {
+			return
+		}
+		saveInterpretationSegment(&database.InterpretationSegment{
+			MeetingID:     meetingID,
+			Channel:       database.ChannelOriginal,
+			ParticipantID: participantID,
+			SpeakerName:   participantName,
+			Language:      message.SourceLanguage,
+			Text:          message.OriginalText,
+			Translations:  message.Translations,
+			StartedAt:     start,
+			EndedAt:       end,
+		})
+		return
+	}
+
+	transcription, detectedLang, _, err := transcribeAudio(wavData)
+	if err != nil {
+		log.Printf("Error transcribing interpreter audio: %v", err)
+		return
+	}
+	transcription = strings.TrimSpace(transcription)
+	if transcription == "" {
+		return
+	}
+	if detectedLang != "" && detectedLang != interpretLang {
+		log.Printf("[Interpretation] Interpreter %d configured for %s but ASR detected %s", participantID, interpretLang, detectedLang)
+	}
+
+	message := Message{
+		Type:                 "interpretation",
+		SpeakerParticipantID: participantID,
+		SpeakerName:          participantName,
+		OriginalText:         transcription,
+		SourceLanguage:       interpretLang,
+		IsFinal:              true,
+		AudioRef:             audio.ref(),
+	}
+	if !transcriptionHooks(meetingID, &message) {
+		return
+	}
+	transcription = message.OriginalText
+	rm.Broadcast(meetingID, message)
+
+	if !rm.speakerConsented(meetingID, participantID) {
+		return
+	}
+	saveInterpretationSegment(&database.InterpretationSegment{
+		MeetingID:     meetingID,
+		Channel:       database.ChannelInterpreter,
+		ParticipantID: participantID,
+		SpeakerName:   participantName,
+		Language:      interpretLang,
+		Text:          transcription,
+		StartedAt:     start,
+		EndedAt:       end,
+	})
+}
+
+func saveInterpretationSegment(segment *database.InterpretationSegment) {
+	if err := database.SaveInterpretationSegment(segment); err != nil {
+		log.Printf("[Interpretation] Failed to store %s segment for meeting %s: %v", segment.Channel, segment.MeetingID, err)
+	}
+}
+
+// AlignedPair is original speech matched with the human interpretation that
+// covered it, plus the machine translation of the same speech
+type AlignedPair struct {
+	StartedAt          time.Time `json:"startedAt"`
+	EndedAt            time.Time `json:"endedAt"`
+	SourceLanguage     string    `json:"sourceLanguage,omitempty"`
+	Original           string    `json:"original"`
+	InterpretLanguage  string    `json:"interpretLanguage,omitempty"`
+	Interpretation     string    `json:"interpretation"`
+	MachineTranslation string    `json:"machineTranslation,omitempty"`
+	ChrF               *float64  `json:"chrf,omitempty"` // machine translation scored against the interpretation
+}
+
+// InterpretationReport is the aligned parallel transcript of a meeting
+type InterpretationReport struct {
+	Lag               float64       `json:"lagSeconds"`
+	Pairs             []AlignedPair `json:"pairs"`
+	MatchedPairs      int           `json:"matchedPairs"`
+	UnmatchedOriginal int           `json:"unmatchedOriginal"`
+	UnmatchedInterp   int           `json:"unmatchedInterpretation"`
+	ChrF              *float64      `json:"chrf,omitempty"` // corpus chrF of machine translation vs interpretation
+}
+
+// AlignInterpretation pairs interpreter segments with the original segments
+// they interpret. Interpreters trail the speaker, so each interpreter segment
+// is shifted back by lag and matched to the original segments it overlaps
+// most; every original segment is assigned to at most one interpretation.
+func AlignInterpretation(segments []database.InterpretationSegment, lag time.Duration) *InterpretationReport {
+	var originals, interpretations []database.InterpretationSegment
+	for _, segment := range segments {
+		if segment.Channel == database.ChannelInterpreter {
+			interpretations = append(interpretations, segment)
+		} else {
+			originals = append(originals, segment)
+		}
+	}
+
+	// original index -> interpretation index with the largest overlap
+	assigned := make(map[int]int)
+	for i, original := range originals {
+		best, bestOverlap := -1, time.Duration(0)
+		for j, interp := range interpretations {
+			overlap := overlapDuration(original.StartedAt, original.EndedAt, interp.StartedAt.Add(-lag), interp.EndedAt.Add(-lag))
+			if overlap > bestOverlap {
+				best, bestOverlap = j, overlap
+			}
+		}
+		if best >= 0 {
+			assigned[i] = best
+		}
+	}
+
+	byInterp := make(map[int][]int)
+	for i, j := range assigned {
+		byInterp[j] = append(byInterp[j], i)
+	}
+
+	report := &InterpretationReport{Lag: lag.Seconds()}
+	var corpus benchmark.ChrF
+	scored := false
+
+	for j, interp := range interpretations {
+		indices := byInterp[j]
+		if len(indices) == 0 {
+			report.UnmatchedInterp++
+			report.Pairs = append(report.Pairs, AlignedPair{
+				StartedAt:         interp.StartedAt.Add(-lag),
+				EndedAt:           interp.EndedAt.Add(-lag),
+				InterpretLanguage: interp.Language,
+				Interpretation:    interp.Text,
+			})
+			continue
+		}
+		sort.Ints(indices)
+
+		pair := AlignedPair{
+			StartedAt:         originals[indices[0]].StartedAt,
+			EndedAt:           originals[indices[len(indices)-1]].EndedAt,
+			SourceLanguage:    originals[indices[0]].Language,
+			InterpretLanguage: interp.Language,
+			Interpretation:    interp.Text,
+		}
+		var originalText, machineText []string
+		for _, i := range indices {
+			originalText = append(originalText, originals[i].Text)
+			if translated := originals[i].Translations[interp.Language]; translated != "" {
+				machineText = append(machineText, translated)
+			}
+		}
+		pair.Original = strings.Join(originalText, " ")
+		pair.MachineTranslation = strings.Join(machineText, " ")
+
+		if pair.MachineTranslation != "" {
+			var sentence benchmark.ChrF
+			sentence.Add(pair.Interpretation, pair.MachineTranslation)
+			score := sentence.Score()
+			pair.ChrF = &score
+			corpus.Add(pair.Interpretation, pair.MachineTranslation)
+			scored = true
+		}
+
+		report.MatchedPairs++
+		report.Pairs = append(report.Pairs, pair)
+	}
+
+	for i, original := range originals {
+		if _, ok := assigned[i]; ok {
+			continue
+		}
+		report.UnmatchedOriginal++
+		report.Pairs = append(report.Pairs, AlignedPair{
+			StartedAt:      original.StartedAt,
+			EndedAt:        original.EndedAt,
+			SourceLanguage: original.Language,
+			Original:       original.Text,
+		})
+	}
+
+	sort.SliceStable(report.Pairs, func(a, b int) bool {
+		return report.Pairs[a].StartedAt.Before(report.Pairs[b].StartedAt)
+	})
+	if scored {
+		score := corpus.Score()
+		report.ChrF = &score
+	}
+
+	return report
+}
+
+func overlapDuration(aStart, aEnd, bStart, bEnd time.Time) time.Duration {
+	start := aStart
+	if bStart.After(start) {
+		start = bStart
+	}
+	end := aEnd
+	if bEnd.Before(end) {
+		end = bEnd
+	}
+	if end.After(start) {
+		return end.Sub(start)
+	}
+	return 0
+}
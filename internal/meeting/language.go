@@ -0,0 +1,101 @@
+package meeting
+
+import (
+	"log"
+)
+
+// languageSmoother keeps a participant's source language from flipping on
+// chunks the language detector gets wrong. Detections below the confidence
+// threshold do not count; the language changes once another one wins a
+// majority of the last window confident detections.
+type languageSmoother struct {
+	window        int
+	minConfidence float64
+	recent        []string // confident detections, oldest first
+	current       string
+}
+
+// observe records a chunk's detected language and its probability and
+// returns the language the chunk should be treated as
+func (s *languageSmoother) observe(language string, probability float64) string {
+	if language == "" {
+		return s.current
+	}
+	if probability < s.minConfidence {
+		if s.current == "" {
+			return language
+		}
+		return s.current
+	}
+
+	s.recent = append(s.recent, language)
+	if len(s.recent) > s.window {
+		s.recent = s.recent[len(s.recent)-s.window:]
+	}
+	if s.current == "" {
+		s.current = language
+		return language
+	}
+	if language != s.current {
+		votes := 0
+		for _, recent := range s.recent {
+			if recent == language {
+				votes++
+			}
+		}
+		if votes > s.window/2 {
+			s.current = language
+		}
+	}
+	return s.current
+}
+
+// SetLanguageSmoothing sets how many recent confident detections vote on a
+// participant's source language in individual mode, and the probability a
+// detection needs to count. A window below 2 disables smoothing.
+func (rm *RoomManager) SetLanguageSmoothing(window int, minConfidence float64) {
+	rm.mu.Lock()
+	rm.languageWindow = window
+	rm.languageMinConfidence = minConfidence
+	rm.mu.Unlock()
+}
+
+// smoothLanguage returns the source language to use for a participant's
+// chunk given the language detected in it
+func (rm *RoomManager) smoothLanguage(meetingID string, participantID int, detected string, probability float64) string {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	room := rm.activeRooms[meetingID]
+	if room == nil || rm.languageWindow < 2 {
+		return detected
+	}
+	smoother := room.languages[participantID]
+	if smoother == nil {
+		smoother = &languageSmoother{window: rm.languageWindow, minConfidence: rm.languageMinConfidence}
+		room.languages[participantID] = smoother
+	}
+
+	previous := smoother.current
+	language := smoother.observe(detected, probability)
+	if previous != "" && language != previous {
+		log.Printf("[Language] Participant %d in meeting %s switched from %s to %s", participantID, meetingID, previous, language)
+	}
+	return language
+}
+
+// currentLanguage returns a participant's smoothed source language without
+// counting a detection, or "" until one is established
+func (rm *RoomManager) currentLanguage(meetingID string, participantID int) string {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	room := rm.activeRooms[meetingID]
+	if room == nil {
+		return ""
+	}
+	if smoother := room.languages[participantID]; smoother != nil {
+		return smoother.current
+	}
+	return ""
+}
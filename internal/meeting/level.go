@@ -0,0 +1,53 @@
+package meeting
+
+import (
+	"time"
+
+	"realtime-caption-translator/internal/audio/vad"
+)
+
+// DefaultChunkThreshold is the RMS (0-1) a whole chunk needs to be sent to
+// ASR; quieter chunks are skipped so Whisper does not hallucinate on them
+const DefaultChunkThreshold = 0.022
+
+// levelInterval is how often a participant is sent their input level
+const levelInterval = 500 * time.Millisecond
+
+// AudioLevel is a participant's input level, sent to them as "audio_level"
+// messages for mic-level feedback
+type AudioLevel struct {
+	vad.Level
+	ChunkThreshold float64 `json:"chunkThreshold"` // RMS a whole chunk needs to be transcribed
+}
+
+// SetVoiceDetection sets the speech detection defaults of meeting audio:
+// frame threshold, noise calibration and margin, and the RMS a chunk needs
+// to be transcribed. Participants can override the thresholds for their
+// own connection.
+func (rm *RoomManager) SetVoiceDetection(cfg vad.Config, chunkThreshold float64) {
+	rm.mu.Lock()
+	rm.vadConfig = cfg
+	rm.chunkThreshold = chunkThreshold
+	rm.mu.Unlock()
+}
+
+// voiceDetection returns the segmenter configuration and chunk threshold
+// for a new connection
+func (rm *RoomManager) voiceDetection() (vad.Config, float64) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	cfg := rm.vadConfig
+	cfg.SampleRate = sampleRate
+	cfg.MaxChunk = windowSeconds * time.Second
+	chunkThreshold := rm.chunkThreshold
+	if chunkThreshold <= 0 {
+		chunkThreshold = DefaultChunkThreshold
+	}
+	return cfg, chunkThreshold
+}
+
+// voiceThreshold returns the RMS a participant's chunk needs: their chunk
+// threshold, raised with the noise floor in noisy rooms
+func voiceThreshold(segmenter *vad.Segmenter, chunkThreshold float64) float64 {
+	return max(chunkThreshold, segmenter.NoiseThreshold())
+}
@@ -52,7 +52,12 @@ func GenerateMeetingMinutes(meetingID, language string, llmClient *llm.Client) e
 		context = context[:maxContextChars] + "\n[Transcript truncated]"
 	}
 
-	prompt := "Create meeting minutes as JSON with keys: participants (array of names), key_points (array), action_items (array), decisions (array), summary (string)."
+	prompt := "Create meeting minutes as JSON with keys: participants (array of names), key_points (array), " +
+		"action_items (array of objects with task, assignee and due_date), decisions (array), summary (string). " +
+		"Set assignee only when someone took the task on, and due_date (YYYY-MM-DD) only when a deadline was mentioned."
+	if mtg, err := database.GetMeetingByID(meetingID); err == nil && mtg != nil {
+		prompt += fmt.Sprintf(" The meeting took place on %s.", mtg.CreatedAt.Format("Monday, 2006-01-02"))
+	}
 	if len(participantNames) > 0 {
 		prompt += fmt.Sprintf(" Use these participants if relevant: %s.", strings.Join(participantNames, ", "))
 	}
@@ -63,9 +68,9 @@ func GenerateMeetingMinutes(meetingID, language string, llmClient *llm.Client) e
 		return fmt.Errorf("minutes generation failed: %w", err)
 	}
 
-	content, err := parseMeetingMinutesJSON(answer)
-	if err != nil {
-		log.Printf("Minutes JSON parse failed for meeting %s: %v", meetingID, err)
+	content, actionItems, parseErr := parseMeetingMinutesJSON(answer)
+	if parseErr != nil {
+		log.Printf("Minutes JSON parse failed for meeting %s: %v", meetingID, parseErr)
 		content = database.MeetingMinutesContent{
 			Participants: participantNames,
 			Summary:      strings.TrimSpace(answer),
@@ -82,11 +87,24 @@ func GenerateMeetingMinutes(meetingID, language string, llmClient *llm.Client) e
 	if err := database.SaveMeetingMinutes(meetingID, language, content); err != nil {
 		return fmt.Errorf("failed to save meeting minutes: %w", err)
 	}
+	// Without parsed minutes there is no item list, so the stored items stay
+	if parseErr == nil {
+		if err := saveActionItems(meetingID, language, actionItems); err != nil {
+			log.Printf("Failed to save action items for meeting %s: %v", meetingID, err)
+		}
+	}
 
 	return nil
 }
 
-func parseMeetingMinutesJSON(raw string) (database.MeetingMinutesContent, error) {
+// minutesReply is the LLM's minutes JSON; action items may come back as
+// plain strings or as objects
+type minutesReply struct {
+	database.MeetingMinutesContent
+	ActionItems []actionItemReply `json:"action_items"`
+}
+
+func parseMeetingMinutesJSON(raw string) (database.MeetingMinutesContent, []actionItemReply, error) {
 	cleaned := strings.TrimSpace(raw)
 	if strings.HasPrefix(cleaned, "```") {
 		cleaned = strings.TrimPrefix(cleaned, "```json")
@@ -98,15 +116,26 @@ func parseMeetingMinutesJSON(raw string) (database.MeetingMinutesContent, error)
 	start := strings.Index(cleaned, "{")
 	end := strings.LastIndex(cleaned, "}")
 	if start == -1 || end == -1 || end <= start {
-		return database.MeetingMinutesContent{}, fmt.Errorf("no JSON object found")
+		return database.MeetingMinutesContent{}, nil, fmt.Errorf("no JSON object found")
 	}
 
 	cleaned = cleaned[start : end+1]
 
-	var content database.MeetingMinutesContent
-	if err := json.Unmarshal([]byte(cleaned), &content); err != nil {
-		return database.MeetingMinutesContent{}, err
+	var reply minutesReply
+	if err := json.Unmarshal([]byte(cleaned), &reply); err != nil {
+		return database.MeetingMinutesContent{}, nil, err
+	}
+
+	content := reply.MeetingMinutesContent
+	content.ActionItems = make([]string, 0, len(reply.ActionItems))
+	items := make([]actionItemReply, 0, len(reply.ActionItems))
+	for _, item := range reply.ActionItems {
+		if item.Task == "" {
+			continue
+		}
+		content.ActionItems = append(content.ActionItems, item.String())
+		items = append(items, item)
 	}
 
-	return content, nil
+	return content, items, nil
 }
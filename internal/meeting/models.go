@@ -6,6 +6,11 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/flags"
+	"realtime-caption-translator/internal/keepalive"
+	"realtime-caption-translator/internal/quota"
 )
 
 // Participant represents an active participant in a meeting room
@@ -18,6 +23,53 @@ type Participant struct {
 	MinSpeakers    int
 	MaxSpeakers    int
 	Strictness     float64
+
+	// InterpretLanguage is set when this connection is the human interpreter
+	// channel of an interpreted meeting
+	InterpretLanguage string
+
+	// Consent is the participant's answer to the recording prompt; nil until
+	// they answer. Speech is only stored once they consent.
+	Consent *bool
+
+	// Accessibility holds caption simplification and styling options
+	Accessibility AccessibilitySettings
+
+	// UserID is the signed-in user behind the participant, if any
+	UserID *int
+
+	// Waiting is set while the participant is in the waiting room: they get
+	// no broadcasts and their audio is dropped. CanAdmit is set for owners,
+	// co-hosts, editors and the host, who decide on join requests.
+	Waiting  bool
+	CanAdmit bool
+
+	// CanModerate is set for owners, co-hosts and the host, who can mute
+	// and kick participants and lock the room
+	CanModerate bool
+
+	// keepalive pings the connection; stale participants are dropped
+	keepalive *keepalive.Conn
+
+	// outbox queues messages for the connection's writer goroutine
+	outbox *outbox
+
+	// resumeToken reattaches a reconnecting client to this seat
+	resumeToken string
+
+	// meter charges the participant's speech to the daily audio quota of
+	// the user it is billed to (nil when nobody is billed); quotaWarned,
+	// guarded by the manager's lock, is set once they were told it is used up
+	meter       *quota.Meter
+	quotaWarned bool
+
+	// speaking follows the participant's voice activity, guarded by the
+	// manager's lock; a mute ends it
+	speaking bool
+
+	// pushToTalk, guarded by the manager's lock, is set while the client
+	// gates its audio with a talk button; a resumed connection keeps it
+	pushToTalk bool
 }
 
 // Message represents a message to be broadcast to meeting participants
@@ -37,12 +89,85 @@ type Message struct {
 	SourceLanguage       string            `json:"sourceLanguage,omitempty"`
 	Translations         map[string]string `json:"translations,omitempty"`
 	IsFinal              bool              `json:"isFinal,omitempty"`
-	Timestamp            time.Time         `json:"timestamp"`
+	EndsAt               *time.Time        `json:"endsAt,omitempty"`
+	MinutesRemaining     int               `json:"minutesRemaining,omitempty"`
+	LiveOnly             bool              `json:"liveOnly,omitempty"` // speaker has not consented; shown live but never stored
+	Consented            *bool             `json:"consented,omitempty"`
+	Announcement         string            `json:"announcement,omitempty"`
+	Text                 string            `json:"text,omitempty"` // system message in the recipient's language
+	Timestamp            time.Time         `json:"timestamp"`      // always UTC; clients format it in their own time zone
 	Error                string            `json:"error,omitempty"`
+
+	// UtteranceID ties the "translation_segment" messages of a long final to
+	// the "transcription" that completes it; Sub numbers the sentences from 1
+	// and SubCount is how many there are
+	UtteranceID string `json:"utteranceId,omitempty"`
+	Sub         int    `json:"sub,omitempty"`
+	SubCount    int    `json:"subCount,omitempty"`
+
+	// SegmentID identifies a final caption; binary "speech" frames carry it
+	// so clients can match synthesized audio to its caption
+	SegmentID string `json:"segmentId,omitempty"`
+
+	// EntryID numbers a stored final caption (its transcript entry ID);
+	// corrections refer to it
+	EntryID int64 `json:"entryId,omitempty"`
+
+	// Reason is the moderator's reason for removing a participant ("kicked")
+	Reason string `json:"reason,omitempty"`
+
+	// Admission is how a join request was decided: admitted or rejected
+	Admission string `json:"admission,omitempty"`
+
+	// SampleRate is the client's declared capture rate ("audio_format" acks)
+	SampleRate int `json:"sampleRate,omitempty"`
+
+	// AudioLevel is the recipient's own input level ("audio_level" messages)
+	AudioLevel *AudioLevel `json:"audioLevel,omitempty"`
+
+	// LanguageSegments tags the parts of a code-switched caption with the
+	// language each was spoken in; empty when all of it is in SourceLanguage
+	LanguageSegments []LanguageSegment `json:"languageSegments,omitempty"`
+
+	// AudioRef identifies the archived audio the caption was transcribed
+	// from (GET /api/meetings/{roomCode}/audio/{audioRef})
+	AudioRef int64 `json:"audioRef,omitempty"`
+
+	// Seq is the message's position in the meeting's event log; Replayed
+	// marks events resent to a reconnecting participant
+	Seq      int64 `json:"seq,omitempty"`
+	Replayed bool  `json:"replayed,omitempty"`
+
+	// Caption accessibility: Simplified is a plain-language rewrite for
+	// participants in simplification mode, Style the recipient's display hints
+	Simplified    string                 `json:"simplified,omitempty"`
+	Style         *CaptionStyle          `json:"style,omitempty"`
+	Accessibility *AccessibilitySettings `json:"accessibility,omitempty"`
+
+	// ResumeToken lets the recipient reclaim their seat after a dropped
+	// connection ("resume_token" and "resumed" messages)
+	ResumeToken string `json:"resumeToken,omitempty"`
+
+	// textFormat and textArgs build Text per recipient; see withText
+	textFormat string
+	textArgs   []interface{}
+
+	// spokenFrom and spokenTo span the speech a final caption was
+	// transcribed from, for the persisted transcript and recordings
+	spokenFrom time.Time
+	spokenTo   time.Time
+}
+
+// withText attaches a system message that is localized to each recipient's
+// target language when the message is sent
+func (m Message) withText(format string, args ...interface{}) Message {
+	m.textFormat, m.textArgs = format, args
+	return m
 }
 
 // TranscriptEntry represents one line in a language-specific transcript
 type TranscriptEntry struct {
+	EntryID     int64     `json:"entryId,omitempty"` // meeting_transcript_entries.id
 	Timestamp   time.Time `json:"timestamp"`
 	SpeakerID   string    `json:"speakerId,omitempty"`
 	SpeakerName string    `json:"speakerName,omitempty"`
@@ -66,6 +191,38 @@ type Room struct {
 	// Transcript storage (per language)
 	transcriptMu sync.RWMutex
 	transcripts  map[string][]TranscriptEntry // language -> entries
+	restored     bool                         // persisted entries from before the room existed were loaded
+	createdAt    time.Time
+
+	// Enrolled users that per-device diarized speakers were matched to, by
+	// device speaker ID; guarded by the RoomManager's lock
+	enrolledSpeakers map[string]*database.VoiceMatch
+
+	// Idle suspension: processing pauses after a stretch without voice
+	lastVoiceAt time.Time
+	suspended   bool
+
+	// Duration limit; deadline is zero when the meeting has none
+	limitLoaded bool
+	deadline    time.Time
+	warned      map[time.Duration]bool // warnings already sent, by time remaining
+
+	// Running recording; nil when the meeting is not being recorded
+	recording *roomRecording
+
+	// Source language smoothing of individual-mode participants; guarded by
+	// the RoomManager's lock
+	languages map[int]*languageSmoother
+
+	// Moderation, guarded by the RoomManager's lock: participants whose
+	// audio is dropped, and whether new participants are kept out
+	muted  map[int]bool
+	locked bool
+
+	// Who spoken captions are gated and voiced for (the meeting's creator),
+	// loaded on first use; guarded by the RoomManager's lock
+	speechSubject       flags.Subject
+	speechSubjectLoaded bool
 }
 
 // NewRoom creates a new room
@@ -78,6 +235,12 @@ func NewRoom(meetingID string) *Room {
 		speakerMap:    make(map[int]string),
 		nextSpeakerID: 0,
 		transcripts:   make(map[string][]TranscriptEntry),
+		createdAt:     time.Now().UTC(),
+		lastVoiceAt:   time.Now(),
+		languages:     make(map[int]*languageSmoother),
+		muted:         make(map[int]bool),
+
+		enrolledSpeakers: make(map[string]*database.VoiceMatch),
 	}
 }
 
@@ -90,6 +253,7 @@ func (r *Room) AddParticipant(p *Participant) {
 // RemoveParticipant removes a participant from the room
 func (r *Room) RemoveParticipant(participantID int) {
 	delete(r.Participants, participantID)
+	delete(r.languages, participantID)
 
 	// Rebuild target languages cache
 	r.targetLangs = make(map[string]bool)
@@ -190,7 +354,7 @@ func formatSpeakerIDFromInt(num int) string {
 
 // AddTranscriptFromMessage stores a transcription message for later download
 func (r *Room) AddTranscriptFromMessage(message Message) {
-	if message.Type != "transcription" || message.OriginalText == "" {
+	if message.Type != "transcription" || message.OriginalText == "" || message.LiveOnly {
 		return
 	}
 
@@ -208,6 +372,7 @@ func (r *Room) AddTranscriptFromMessage(message Message) {
 				text = message.OriginalText
 			}
 			r.transcripts[lang] = append(r.transcripts[lang], TranscriptEntry{
+				EntryID:     message.EntryID,
 				Timestamp:   message.Timestamp,
 				SpeakerID:   message.SpeakerID,
 				SpeakerName: message.SpeakerName,
@@ -217,6 +382,7 @@ func (r *Room) AddTranscriptFromMessage(message Message) {
 		if message.SourceLanguage != "" {
 			if _, exists := message.Translations[message.SourceLanguage]; !exists {
 				r.transcripts[message.SourceLanguage] = append(r.transcripts[message.SourceLanguage], TranscriptEntry{
+					EntryID:     message.EntryID,
 					Timestamp:   message.Timestamp,
 					SpeakerID:   message.SpeakerID,
 					SpeakerName: message.SpeakerName,
@@ -232,6 +398,7 @@ func (r *Room) AddTranscriptFromMessage(message Message) {
 		lang = "und"
 	}
 	r.transcripts[lang] = append(r.transcripts[lang], TranscriptEntry{
+		EntryID:     message.EntryID,
 		Timestamp:   message.Timestamp,
 		SpeakerID:   message.SpeakerID,
 		SpeakerName: message.SpeakerName,
@@ -239,6 +406,39 @@ func (r *Room) AddTranscriptFromMessage(message Message) {
 	})
 }
 
+// CorrectTranscript replaces the text of a stored caption's entries:
+// translations where there is one, text in the source language
+func (r *Room) CorrectTranscript(entryID int64, text string, translations map[string]string) {
+	r.transcriptMu.Lock()
+	defer r.transcriptMu.Unlock()
+
+	for lang, entries := range r.transcripts {
+		corrected := text
+		if translated := translations[lang]; translated != "" {
+			corrected = translated
+		}
+		for i := range entries {
+			if entries[i].EntryID == entryID {
+				entries[i].Text = corrected
+			}
+		}
+	}
+}
+
+// RenameSpeaker sets the name of a speaker's entries in every language
+func (r *Room) RenameSpeaker(speakerID, speakerName string) {
+	r.transcriptMu.Lock()
+	defer r.transcriptMu.Unlock()
+
+	for _, entries := range r.transcripts {
+		for i := range entries {
+			if entries[i].SpeakerID == speakerID {
+				entries[i].SpeakerName = speakerName
+			}
+		}
+	}
+}
+
 // GetTranscript returns the transcript for a specific language
 func (r *Room) GetTranscript(language string) []TranscriptEntry {
 	r.transcriptMu.RLock()
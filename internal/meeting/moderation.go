@@ -0,0 +1,150 @@
+package meeting
+
+import (
+	"fmt"
+	"log"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// moderationTarget returns a connected participant a moderator may act on,
+// or nil after telling the moderator why not. Moderators cannot act on each
+// other.
+func (rm *RoomManager) moderationTarget(meetingID string, moderator *Participant, participantID int) *Participant {
+	if !moderator.CanModerate {
+		sendDirect(moderator, Message{Type: "error", Error: "Only hosts can moderate participants"}.
+			withText("Only hosts can moderate participants"))
+		return nil
+	}
+
+	rm.mu.RLock()
+	var target *Participant
+	if room := rm.activeRooms[meetingID]; room != nil {
+		target = room.Participants[participantID]
+	}
+	rm.mu.RUnlock()
+
+	if target == nil || target.CanModerate {
+		sendDirect(moderator, Message{Type: "error", Error: "That participant cannot be moderated"}.
+			withText("That participant cannot be moderated"))
+		return nil
+	}
+	return target
+}
+
+// muteParticipant starts or stops dropping a participant's audio. The mute
+// lasts across reconnects until the room closes.
+func (rm *RoomManager) muteParticipant(meetingID string, moderator *Participant, participantID int, muted bool) {
+	target := rm.moderationTarget(meetingID, moderator, participantID)
+	if target == nil {
+		return
+	}
+
+	rm.mu.Lock()
+	if room := rm.activeRooms[meetingID]; room != nil {
+		if muted {
+			room.muted[participantID] = true
+		} else {
+			delete(room.muted, participantID)
+		}
+	}
+	rm.mu.Unlock()
+
+	log.Printf("Participant %d in meeting %s muted=%t by participant %d", participantID, meetingID, muted, moderator.ID)
+	if muted {
+		// Their audio is dropped from now on, so the VAD cannot end it
+		rm.setSpeaking(meetingID, target, false)
+	}
+	message := Message{
+		Type:            "participant_muted",
+		ParticipantID:   participantID,
+		ParticipantName: target.Name,
+	}.withText("%s was muted by the host", target.Name)
+	if !muted {
+		message = Message{
+			Type:            "participant_unmuted",
+			ParticipantID:   participantID,
+			ParticipantName: target.Name,
+		}.withText("%s was unmuted by the host", target.Name)
+	}
+	rm.Broadcast(meetingID, message)
+}
+
+// kickParticipant disconnects a participant for good: their participant ID
+// cannot reconnect, and the removal is kept against their user or guest key
+// so they cannot join again until a signed-in user is granted access anew
+func (rm *RoomManager) kickParticipant(meetingID string, moderator *Participant, participantID int, reason string) {
+	target := rm.moderationTarget(meetingID, moderator, participantID)
+	if target == nil {
+		return
+	}
+
+	if err := database.SetParticipantAdmission(participantID, database.AdmissionRejected); err != nil {
+		log.Printf("Failed to block participant %d: %v", participantID, err)
+	}
+	note := fmt.Sprintf("Removed from the live meeting by %s", moderator.Name)
+	if reason != "" {
+		note += ": " + reason
+	}
+	if err := database.NoteMeetingRemoval(meetingID, participantID, note, moderator.UserID); err != nil {
+		log.Printf("Failed to note removal of participant %d from meeting %s: %v", participantID, meetingID, err)
+	}
+	log.Printf("Participant %d kicked from meeting %s by participant %d", participantID, meetingID, moderator.ID)
+
+	sendDirect(target, Message{Type: "kicked", ParticipantID: participantID, Reason: reason}.
+		withText("The host removed you from the meeting"))
+	target.outbox.finish()
+
+	rm.Broadcast(meetingID, Message{
+		Type:            "participant_kicked",
+		ParticipantID:   participantID,
+		ParticipantName: target.Name,
+	}.withText("%s was removed by the host", target.Name))
+}
+
+// lockRoom keeps new participants from joining, or lets them in again
+func (rm *RoomManager) lockRoom(meetingID string, moderator *Participant, locked bool) {
+	if !moderator.CanModerate {
+		sendDirect(moderator, Message{Type: "error", Error: "Only hosts can lock the meeting"}.
+			withText("Only hosts can lock the meeting"))
+		return
+	}
+
+	rm.mu.Lock()
+	if room := rm.activeRooms[meetingID]; room != nil {
+		room.locked = locked
+	}
+	rm.mu.Unlock()
+
+	log.Printf("Meeting %s locked=%t by participant %d", meetingID, locked, moderator.ID)
+	if locked {
+		rm.Broadcast(meetingID, Message{Type: "room_locked"}.withText("The host locked the meeting"))
+	} else {
+		rm.Broadcast(meetingID, Message{Type: "room_unlocked"}.withText("The host unlocked the meeting"))
+	}
+}
+
+// IsLocked reports whether a moderator locked a meeting's room against new
+// participants
+func (rm *RoomManager) IsLocked(meetingID string) bool {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	room := rm.activeRooms[meetingID]
+	return room != nil && room.locked
+}
+
+// dropsAudio reports whether a participant's audio is ignored: they are in
+// the waiting room or muted
+func (rm *RoomManager) dropsAudio(meetingID string, participantID int) bool {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	room := rm.activeRooms[meetingID]
+	if room == nil {
+		return false
+	}
+	if room.muted[participantID] {
+		return true
+	}
+	p := room.Participants[participantID]
+	return p != nil && p.Waiting
+}
@@ -0,0 +1,361 @@
+package meeting
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Outbound queue settings
+const (
+	outboxSize      = 256              // messages queued per participant
+	outboxMaxDrops  = 2 * outboxSize   // drops before a lagging participant is disconnected
+	outboxWriteWait = 10 * time.Second // longest a single write may block
+	outboxAudioSize = 8                // synthesized speech frames queued per participant
+)
+
+// Caption throttling: a participant whose queue backs up or whose writes
+// are slow first gets only the latest partial caption per speaker, then
+// only final captions, and returns to full partials once writes keep up
+const (
+	throttleCoalesceBacklog   = 8                      // queued messages that start coalescing partials
+	throttleFinalsOnlyBacklog = 32                     // queued messages that stop partials
+	throttleSlowWrite         = 500 * time.Millisecond // a write this slow means the link is constrained
+	throttleRecoverWrites     = 20                     // fast writes on an empty queue before easing off a level
+)
+
+// Throttle levels, reported to clients in caption_throttle messages
+const (
+	throttleOff        = "off"
+	throttleCoalesce   = "coalesce"
+	throttleFinalsOnly = "finals_only"
+)
+
+// outbox is a participant's outbound message queue. A dedicated writer
+// goroutine is the only one writing data frames to the connection, so a
+// slow client only holds up its own queue and frames are never interleaved.
+// When the queue is full the oldest message is dropped; a participant that
+// keeps falling behind is disconnected.
+type outbox struct {
+	conn        *websocket.Conn
+	participant int
+	send        chan []byte
+	audio       chan []byte // binary speech frames, sent after queued messages
+
+	mu      sync.Mutex // serializes enqueuers so drop-oldest stays atomic
+	dropped int        // messages dropped since the queue was last empty
+
+	throttle     string         // current throttle level; guarded by mu
+	calmWrites   int            // fast writes on an empty queue in a row; guarded by mu
+	partials     map[int][]byte // latest coalesced partial per speaker; guarded by mu
+	partialOrder []int          // speakers in partials, oldest first; guarded by mu
+	partialReady chan struct{}
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// newOutbox starts the writer for conn. Call close when the handler returns.
+func newOutbox(conn *websocket.Conn, participantID int) *outbox {
+	o := &outbox{
+		conn:         conn,
+		participant:  participantID,
+		send:         make(chan []byte, outboxSize),
+		audio:        make(chan []byte, outboxAudioSize),
+		throttle:     throttleOff,
+		partials:     make(map[int][]byte),
+		partialReady: make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+	}
+	go o.write()
+	return o
+}
+
+// enqueue queues a message without blocking, dropping the oldest queued
+// message when full. It is a no-op on a nil or closed outbox.
+func (o *outbox) enqueue(data []byte) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.push(data)
+}
+
+// push queues data, dropping the oldest queued message when full; callers
+// hold o.mu
+func (o *outbox) push(data []byte) {
+	for {
+		select {
+		case <-o.stop:
+			return
+		case o.send <- data:
+			return
+		default:
+		}
+		select {
+		case <-o.send:
+			o.dropped++
+		default:
+		}
+		if o.dropped > outboxMaxDrops {
+			log.Printf("Disconnecting participant %d: fell %d messages behind", o.participant, o.dropped)
+			o.close()
+			o.conn.Close()
+			return
+		}
+	}
+}
+
+// enqueueAudio queues a binary speech frame without blocking. Speech is
+// dropped rather than queued while the participant is limited to final
+// captions or already has outboxAudioSize frames waiting, so it never
+// crowds out text.
+func (o *outbox) enqueueAudio(frame []byte) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	finalsOnly := o.throttle == throttleFinalsOnly
+	o.mu.Unlock()
+	if finalsOnly {
+		return
+	}
+	select {
+	case <-o.stop:
+	case o.audio <- frame:
+	default:
+		log.Printf("Dropping speech frame for participant %d: audio queue full", o.participant)
+	}
+}
+
+// enqueueCaption queues a transcription of speaker. Partial captions are
+// throttled by the participant's backlog: queued as usual, coalesced to the
+// latest per speaker, or dropped. A final caption discards the speaker's
+// coalesced partial, which it supersedes.
+func (o *outbox) enqueueCaption(speaker int, final bool, data []byte) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.adjustThrottle()
+	if final {
+		o.dropPartial(speaker)
+		o.push(data)
+		return
+	}
+
+	switch o.throttle {
+	case throttleOff:
+		o.push(data)
+	case throttleCoalesce:
+		if _, pending := o.partials[speaker]; !pending {
+			o.partialOrder = append(o.partialOrder, speaker)
+		}
+		o.partials[speaker] = data
+		select {
+		case o.partialReady <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// adjustThrottle raises the throttle level when the queue backs up; the
+// writer lowers it again. Callers hold o.mu.
+func (o *outbox) adjustThrottle() {
+	backlog := len(o.send)
+	switch {
+	case backlog >= throttleFinalsOnlyBacklog && o.throttle != throttleFinalsOnly:
+		o.setThrottle(throttleFinalsOnly)
+	case backlog >= throttleCoalesceBacklog && o.throttle == throttleOff:
+		o.setThrottle(throttleCoalesce)
+	}
+}
+
+// setThrottle changes the level and tells the client, so it can stop
+// expecting live partials; callers hold o.mu
+func (o *outbox) setThrottle(level string) {
+	if level == o.throttle {
+		return
+	}
+	log.Printf("Caption throttling for participant %d: %s -> %s (%d queued)", o.participant, o.throttle, level, len(o.send))
+	o.throttle = level
+	o.calmWrites = 0
+	if level == throttleFinalsOnly {
+		o.partials = make(map[int][]byte)
+		o.partialOrder = nil
+	}
+	notice, err := json.Marshal(map[string]interface{}{
+		"type":      "caption_throttle",
+		"mode":      level,
+		"timestamp": time.Now().UTC(),
+	})
+	if err == nil {
+		o.push(notice)
+	}
+}
+
+// dropPartial discards a speaker's coalesced partial; callers hold o.mu
+func (o *outbox) dropPartial(speaker int) {
+	if _, pending := o.partials[speaker]; !pending {
+		return
+	}
+	delete(o.partials, speaker)
+	for i, id := range o.partialOrder {
+		if id == speaker {
+			o.partialOrder = append(o.partialOrder[:i], o.partialOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+// takePartials removes and returns the coalesced partials, oldest first
+func (o *outbox) takePartials() [][]byte {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	partials := make([][]byte, 0, len(o.partialOrder))
+	for _, speaker := range o.partialOrder {
+		partials = append(partials, o.partials[speaker])
+	}
+	o.partials = make(map[int][]byte)
+	o.partialOrder = nil
+	return partials
+}
+
+// wrote records how long a write took and eases throttling once writes
+// keep up with an empty queue
+func (o *outbox) wrote(took time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	idle := len(o.send) == 0
+	if idle {
+		o.dropped = 0
+	}
+	if took >= throttleSlowWrite {
+		o.calmWrites = 0
+		if o.throttle == throttleOff {
+			o.setThrottle(throttleCoalesce)
+		}
+		return
+	}
+	if !idle {
+		o.calmWrites = 0
+		return
+	}
+	if o.throttle == throttleOff {
+		return
+	}
+	if o.calmWrites++; o.calmWrites >= throttleRecoverWrites {
+		if o.throttle == throttleFinalsOnly {
+			o.setThrottle(throttleCoalesce)
+		} else {
+			o.setThrottle(throttleOff)
+		}
+	}
+}
+
+// enqueueWait queues a message, waiting for room instead of dropping. It is
+// for bursts the handler sends itself, such as event replay.
+func (o *outbox) enqueueWait(data []byte) {
+	if o == nil {
+		return
+	}
+	select {
+	case <-o.stop:
+	case o.send <- data:
+	}
+}
+
+// finish queues a close after the messages already queued, so a final
+// message such as meeting_ended is delivered before the connection goes
+func (o *outbox) finish() {
+	o.enqueueWait(nil)
+}
+
+// close stops the writer; queued messages are discarded
+func (o *outbox) close() {
+	if o == nil {
+		return
+	}
+	o.stopOnce.Do(func() { close(o.stop) })
+}
+
+// write sends queued messages until the outbox is closed, a write fails or
+// finish is reached. Coalesced partials and speech frames are sent after the
+// messages queued before them, so they never hold up final captions.
+func (o *outbox) write() {
+	for {
+		select {
+		case <-o.stop:
+			return
+		case data := <-o.send:
+			if !o.writeFrame(data) {
+				return
+			}
+		case <-o.partialReady:
+			if !o.drain() {
+				return
+			}
+			for _, data := range o.takePartials() {
+				if !o.writeFrame(data) {
+					return
+				}
+			}
+		case frame := <-o.audio:
+			if !o.drain() || !o.writeBinary(frame) {
+				return
+			}
+		}
+	}
+}
+
+// drain sends the messages queued right now; false means the writer stopped
+func (o *outbox) drain() bool {
+	for {
+		select {
+		case data := <-o.send:
+			if !o.writeFrame(data) {
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
+
+// writeFrame sends one message, measuring the write for throttling. A nil
+// message is finish's marker. Failures close the connection so the
+// handler's read loop ends and removes the participant. Returns false once
+// the writer must stop.
+func (o *outbox) writeFrame(data []byte) bool {
+	if data == nil {
+		o.close()
+		o.conn.Close()
+		return false
+	}
+	return o.writeMessage(websocket.TextMessage, data)
+}
+
+// writeBinary sends a speech frame, measuring it like any other write
+func (o *outbox) writeBinary(frame []byte) bool {
+	return o.writeMessage(websocket.BinaryMessage, frame)
+}
+
+func (o *outbox) writeMessage(messageType int, data []byte) bool {
+	started := time.Now()
+	o.conn.SetWriteDeadline(started.Add(outboxWriteWait))
+	if err := o.conn.WriteMessage(messageType, data); err != nil {
+		log.Printf("Error sending message to participant %d: %v", o.participant, err)
+		o.close()
+		o.conn.Close()
+		return false
+	}
+	o.wrote(time.Since(started))
+	return true
+}
@@ -0,0 +1,163 @@
+package meeting
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"realtime-caption-translator/internal/audio/vad"
+	"realtime-caption-translator/internal/ratelimit"
+)
+
+// minPartialSamples is the least audio a partial caption is transcribed from
+const minPartialSamples = sampleRate
+
+// SetPartialCaptions sets how often a speaker's unfinished chunk is previewed
+// as a partial caption and how many of its latest seconds are transcribed
+// for it; an interval of 0 disables partial captions
+func (rm *RoomManager) SetPartialCaptions(interval, window time.Duration) {
+	rm.mu.Lock()
+	rm.partialInterval = interval
+	rm.partialWindow = window
+	rm.mu.Unlock()
+}
+
+// partialCaptioner previews one participant's speech before the segmenter
+// cuts it into a chunk. Partials are broadcast as transcriptions with
+// IsFinal=false; the final caption of the chunk replaces them on clients.
+// They are not stored, logged or sent to caption feeds.
+type partialCaptioner struct {
+	rm              *RoomManager
+	meetingID       string
+	participantID   int
+	participantName string
+	interval        time.Duration
+	window          int // samples
+
+	last time.Time // when the latest partial started; handler goroutine only
+
+	mu         sync.Mutex
+	generation int  // counts finalized chunks; partials of an earlier one are dropped
+	running    bool // one partial per participant at a time
+}
+
+// newPartialCaptioner returns nil when partial captions are disabled or the
+// meeting is interpreted, whose interpreter channel is only captioned from
+// aligned finals
+func (rm *RoomManager) newPartialCaptioner(meetingID string, participantID int, participantName, mode string) *partialCaptioner {
+	rm.mu.RLock()
+	interval, window := rm.partialInterval, rm.partialWindow
+	rm.mu.RUnlock()
+	if interval <= 0 || mode == ModeInterpreted {
+		return nil
+	}
+	return &partialCaptioner{
+		rm:              rm,
+		meetingID:       meetingID,
+		participantID:   participantID,
+		participantName: participantName,
+		interval:        interval,
+		window:          int(window.Seconds() * sampleRate),
+	}
+}
+
+// finalized marks the chunk being previewed as cut; partials still in flight
+// for it are dropped so they cannot follow its final caption
+func (p *partialCaptioner) finalized() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.generation++
+	p.mu.Unlock()
+}
+
+// update starts a partial transcription of the latest window of the
+// segmenter's in-progress chunk when one is due and none is running
+func (p *partialCaptioner) update(segmenter *vad.Segmenter, threshold float64) {
+	if p == nil {
+		return
+	}
+	now := time.Now()
+	if now.Sub(p.last) < p.interval {
+		return
+	}
+	chunk := segmenter.InProgress()
+	if len(chunk) < minPartialSamples {
+		return
+	}
+
+	p.mu.Lock()
+	if p.running {
+		p.mu.Unlock()
+		return
+	}
+	p.running = true
+	generation := p.generation
+	p.mu.Unlock()
+
+	p.last = now
+	if p.window > 0 && len(chunk) > p.window {
+		chunk = chunk[len(chunk)-p.window:]
+	}
+	go p.run(chunk, generation, threshold)
+}
+
+func (p *partialCaptioner) run(samples []int16, generation int, threshold float64) {
+	defer func() {
+		p.mu.Lock()
+		p.running = false
+		p.mu.Unlock()
+	}()
+
+	// Final chunks must not be turned away because partials took the slots
+	if asrLimiter.Stats().Priorities[ratelimit.Interactive.String()].Queued > 0 {
+		return
+	}
+	if !hasVoiceActivity(samples, threshold) {
+		return
+	}
+
+	wavData, err := samplesToWAV(samples, sampleRate)
+	if err != nil {
+		return
+	}
+	// Once finals have settled the speaker's language, partials are heard in
+	// it too instead of following each detection; they do not vote on it
+	var text, sourceLang string
+	if sourceLang = p.rm.currentLanguage(p.meetingID, p.participantID); sourceLang != "" {
+		text, err = transcribeAudioIn(wavData, sourceLang)
+	} else {
+		text, sourceLang, _, err = transcribeAudio(wavData)
+	}
+	if err != nil {
+		log.Printf("Partial transcription failed for participant %d: %v", p.participantID, err)
+		return
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	message := Message{
+		Type:                 "transcription",
+		SpeakerParticipantID: p.participantID,
+		SpeakerName:          p.participantName,
+		OriginalText:         text,
+		SourceLanguage:       sourceLang,
+		IsFinal:              false,
+	}
+	if !transcriptionHooks(p.meetingID, &message) {
+		return
+	}
+	message.Translations = translateParallel(translationEvent(p.meetingID, &message, message.OriginalText), p.rm.GetUniqueTargetLanguages(p.meetingID))
+
+	p.mu.Lock()
+	stale := generation != p.generation
+	p.mu.Unlock()
+	if stale {
+		return
+	}
+
+	p.rm.Broadcast(p.meetingID, message)
+}
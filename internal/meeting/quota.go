@@ -0,0 +1,50 @@
+package meeting
+
+import (
+	"log"
+
+	"realtime-caption-translator/internal/quota"
+)
+
+// billedMeter returns the meter a participant's speech is charged to: the
+// signed-in participant's quota, or else the meeting creator's; nil when
+// neither is known
+func billedMeter(participantUserID, creatorID *int) *quota.Meter {
+	if participantUserID != nil {
+		return quota.NewMeter(*participantUserID)
+	}
+	if creatorID != nil {
+		return quota.NewMeter(*creatorID)
+	}
+	return nil
+}
+
+// chargeSpeech counts seconds of a participant's speech against the daily
+// audio quota of the user it is billed to. Once the quota is used up the
+// speech is dropped and the speaker is told, once per connection.
+func (rm *RoomManager) chargeSpeech(meetingID string, participantID int, seconds float64) bool {
+	rm.mu.RLock()
+	var participant *Participant
+	if room, exists := rm.activeRooms[meetingID]; exists {
+		participant = room.Participants[participantID]
+	}
+	rm.mu.RUnlock()
+	if participant == nil {
+		return true
+	}
+
+	exceeded, ok := quota.Exceeded(participant.meter.Charge(seconds))
+	if !ok {
+		return true
+	}
+	rm.mu.Lock()
+	warn := !participant.quotaWarned
+	participant.quotaWarned = true
+	rm.mu.Unlock()
+	if warn {
+		log.Printf("[Quota] Dropping speech of participant %d in meeting %s: %v", participantID, meetingID, exceeded)
+		sendDirect(participant, Message{Type: "quota_exceeded", Error: exceeded.Error()}.
+			withText("The daily audio quota is used up; speech is not captioned until it resets"))
+	}
+	return false
+}
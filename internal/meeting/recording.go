@@ -0,0 +1,427 @@
+package meeting
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/storage"
+)
+
+// wavHeaderSize is the size of the canonical PCM WAV header reserved at the
+// start of a recording's audio file
+const wavHeaderSize = 44
+
+// maxRecordingDrift is how far a speaker's audio may run ahead of or behind
+// the wall clock before it is realigned, e.g. after a network stall
+const maxRecordingDrift = sampleRate // 1 second
+
+// Recording errors
+var (
+	ErrRecordingDisabled = errors.New("meeting recording requires object storage")
+	ErrRecordingActive   = errors.New("meeting is already being recorded")
+	ErrNotRecording      = errors.New("meeting is not being recorded")
+	ErrRoomNotActive     = errors.New("meeting room is not active")
+)
+
+// recordingStore keeps finished recordings; nil or disabled records nothing
+var (
+	recordingStore *storage.MinioClient
+	recordingDir   string
+)
+
+// SetRecordingStore stores finished recordings in store, buffering their
+// audio under dir meanwhile ("" for the system temp directory). Call before
+// rooms are served.
+func SetRecordingStore(store *storage.MinioClient, dir string) {
+	recordingStore = store
+	recordingDir = dir
+}
+
+// RecordingEntry is a caption of a recording. Offset is when the caption
+// was broadcast, in seconds from the start of the recording's audio.
+type RecordingEntry struct {
+	Offset               float64           `json:"offset"`
+	Timecode             string            `json:"timecode"` // Offset as HH:MM:SS.mmm
+	Timestamp            time.Time         `json:"timestamp"`
+	SpeakerParticipantID int               `json:"speakerParticipantId,omitempty"`
+	SpeakerID            string            `json:"speakerId,omitempty"`
+	SpeakerName          string            `json:"speakerName,omitempty"`
+	SourceLanguage       string            `json:"sourceLanguage,omitempty"`
+	OriginalText         string            `json:"originalText"`
+	Translations         map[string]string `json:"translations,omitempty"`
+	AudioRef             int64             `json:"audioRef,omitempty"`
+}
+
+// RecordingTranscript is the JSON document stored next to a recording's audio
+type RecordingTranscript struct {
+	MeetingID       string           `json:"meetingId"`
+	RecordingID     int              `json:"recordingId"`
+	StartedAt       time.Time        `json:"startedAt"`
+	EndedAt         time.Time        `json:"endedAt"`
+	DurationSeconds float64          `json:"durationSeconds"`
+	SampleRate      int              `json:"sampleRate"`
+	Entries         []RecordingEntry `json:"entries"`
+}
+
+// roomRecording mixes the audio of consenting speakers into one track and
+// collects the final captions broadcast while it runs. Audio is mixed into a
+// temporary file at each speaker's position on the recording's clock, so
+// people talking over each other are summed rather than appended.
+type roomRecording struct {
+	id        int // 0 until the database row exists; set under rm.mu
+	meetingID string
+	startedAt time.Time // set with id
+
+	mu      sync.Mutex
+	file    *os.File
+	cursors map[int]int64 // participantId -> next sample position
+	samples int64         // length of the mixed track
+	entries []RecordingEntry
+	err     error // first write error; the recording fails when stopped
+}
+
+// StartRecording starts recording an active meeting on behalf of userID
+// (nil for a host token)
+func (rm *RoomManager) StartRecording(meetingID string, userID *int) (*database.MeetingRecording, error) {
+	if !recordingStore.Enabled() {
+		return nil, ErrRecordingDisabled
+	}
+	if rm.GetRoom(meetingID) == nil {
+		return nil, ErrRoomNotActive
+	}
+
+	file, err := os.CreateTemp(recordingDir, "recording_*.wav")
+	if err != nil {
+		return nil, fmt.Errorf("create recording file: %w", err)
+	}
+	if _, err := file.Write(make([]byte, wavHeaderSize)); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("write recording header: %w", err)
+	}
+
+	discard := func() {
+		file.Close()
+		os.Remove(file.Name())
+	}
+
+	rm.mu.Lock()
+	room, exists := rm.activeRooms[meetingID]
+	if !exists || room.recording != nil {
+		rm.mu.Unlock()
+		discard()
+		if !exists {
+			return nil, ErrRoomNotActive
+		}
+		return nil, ErrRecordingActive
+	}
+	// Reserve the slot before the row exists so two starts cannot race
+	rec := &roomRecording{meetingID: meetingID, file: file, cursors: make(map[int]int64)}
+	room.recording = rec
+	rm.mu.Unlock()
+
+	record, err := database.CreateMeetingRecording(meetingID, userID)
+	if err != nil {
+		rm.detachRecording(meetingID, rec)
+		discard()
+		return nil, err
+	}
+
+	// The recording goes live once it has an ID; both are set under rm.mu,
+	// which every reader of the slot takes first
+	rm.mu.Lock()
+	room, exists = rm.activeRooms[meetingID]
+	if !exists || room.recording != rec {
+		rm.mu.Unlock()
+		discard()
+		if err := database.FailMeetingRecording(record.ID, "meeting ended before recording started"); err != nil {
+			log.Printf("[Recording] Failed to mark recording %d failed: %v", record.ID, err)
+		}
+		return nil, ErrRoomNotActive
+	}
+	rec.id = record.ID
+	rec.startedAt = time.Now().UTC()
+	rm.mu.Unlock()
+
+	log.Printf("[Recording] Started recording %d of meeting %s", record.ID, meetingID)
+	rm.Broadcast(meetingID, Message{Type: "recording_started"}.withText("This meeting is being recorded"))
+	return record, nil
+}
+
+// StopRecording stops a meeting's recording; its audio and transcript are
+// uploaded in the background
+func (rm *RoomManager) StopRecording(meetingID string) (int, error) {
+	rm.mu.Lock()
+	room, exists := rm.activeRooms[meetingID]
+	if !exists || room.recording == nil || room.recording.id == 0 {
+		rm.mu.Unlock()
+		return 0, ErrNotRecording
+	}
+	rec := room.recording
+	room.recording = nil
+	rm.mu.Unlock()
+
+	rm.Broadcast(meetingID, Message{Type: "recording_stopped"}.withText("Recording stopped"))
+	go rec.finish()
+	return rec.id, nil
+}
+
+// IsRecording reports whether a meeting is being recorded
+func (rm *RoomManager) IsRecording(meetingID string) bool {
+	return rm.activeRecording(meetingID) != nil
+}
+
+// takeRecording removes a closing room's running recording so the caller can
+// finish it; callers hold rm.mu
+func (r *Room) takeRecording() *roomRecording {
+	rec := r.recording
+	r.recording = nil
+	if rec == nil || rec.id == 0 {
+		return nil
+	}
+	return rec
+}
+
+// detachRecording clears a room's recording slot if rec still holds it
+func (rm *RoomManager) detachRecording(meetingID string, rec *roomRecording) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if room, exists := rm.activeRooms[meetingID]; exists && room.recording == rec {
+		room.recording = nil
+	}
+}
+
+// activeRecording returns a meeting's running recording, or nil
+func (rm *RoomManager) activeRecording(meetingID string) *roomRecording {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	room, exists := rm.activeRooms[meetingID]
+	if !exists || room.recording == nil || room.recording.id == 0 {
+		return nil
+	}
+	return room.recording
+}
+
+// recordAudio mixes a frame of a speaker's audio into the meeting's
+// recording, if one is running and the speaker consented
+func (rm *RoomManager) recordAudio(meetingID string, participantID int, samples []int16) {
+	rec := rm.activeRecording(meetingID)
+	if rec == nil || len(samples) == 0 || !rm.speakerConsented(meetingID, participantID) {
+		return
+	}
+	rec.mix(participantID, samples)
+}
+
+// recordCaption adds a final caption to the meeting's recording unless it
+// is live-only
+func (rm *RoomManager) recordCaption(meetingID string, message Message) {
+	if message.LiveOnly {
+		return
+	}
+	if rec := rm.activeRecording(meetingID); rec != nil {
+		rec.addCaption(message)
+	}
+}
+
+// mix adds samples to the track at the speaker's position
+func (r *roomRecording) mix(participantID int, samples []int16) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil || r.file == nil {
+		return
+	}
+
+	now := int64(time.Since(r.startedAt).Seconds() * sampleRate)
+	pos, seen := r.cursors[participantID]
+	if !seen || pos < now-maxRecordingDrift || pos > now+maxRecordingDrift {
+		pos = now - int64(len(samples))
+		if pos < 0 {
+			pos = 0
+		}
+	}
+
+	buf := make([]byte, 2*len(samples))
+	offset := wavHeaderSize + 2*pos
+	if pos < r.samples {
+		if _, err := r.file.ReadAt(buf, offset); err != nil && err != io.EOF {
+			r.err = err
+			return
+		}
+	}
+	for i, sample := range samples {
+		mixed := int32(int16(binary.LittleEndian.Uint16(buf[2*i:]))) + int32(sample)
+		if mixed > 32767 {
+			mixed = 32767
+		} else if mixed < -32768 {
+			mixed = -32768
+		}
+		binary.LittleEndian.PutUint16(buf[2*i:], uint16(int16(mixed)))
+	}
+	if _, err := r.file.WriteAt(buf, offset); err != nil {
+		r.err = err
+		return
+	}
+
+	r.cursors[participantID] = pos + int64(len(samples))
+	if end := pos + int64(len(samples)); end > r.samples {
+		r.samples = end
+	}
+}
+
+// addCaption appends a caption with its offset into the recording. Captions
+// are placed where their speech starts, not where they were broadcast, which
+// is later by the time transcription and translation took.
+func (r *roomRecording) addCaption(message Message) {
+	spokenAt := message.spokenFrom
+	if spokenAt.IsZero() {
+		spokenAt = message.Timestamp
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	offset := spokenAt.Sub(r.startedAt).Seconds()
+	if offset < 0 {
+		offset = 0
+	}
+	r.entries = append(r.entries, RecordingEntry{
+		Offset:               offset,
+		Timecode:             formatTimecode(offset),
+		Timestamp:            spokenAt,
+		SpeakerParticipantID: message.SpeakerParticipantID,
+		SpeakerID:            message.SpeakerID,
+		SpeakerName:          message.SpeakerName,
+		SourceLanguage:       message.SourceLanguage,
+		OriginalText:         message.OriginalText,
+		Translations:         message.Translations,
+		AudioRef:             message.AudioRef,
+	})
+}
+
+// finish closes the track and uploads the audio and transcript
+func (r *roomRecording) finish() {
+	r.mu.Lock()
+	file, samples, writeErr := r.file, r.samples, r.err
+	entries := append([]RecordingEntry{}, r.entries...)
+	r.file = nil
+	r.mu.Unlock()
+	if file == nil {
+		return
+	}
+	defer os.Remove(file.Name())
+
+	endedAt := time.Now().UTC()
+	duration := float64(samples) / sampleRate
+	if err := database.EndMeetingRecording(r.id, duration, len(entries)); err != nil {
+		log.Printf("[Recording] Failed to end recording %d: %v", r.id, err)
+	}
+
+	fail := func(err error) {
+		file.Close()
+		log.Printf("[Recording] Recording %d of meeting %s failed: %v", r.id, r.meetingID, err)
+		if dbErr := database.FailMeetingRecording(r.id, err.Error()); dbErr != nil {
+			log.Printf("[Recording] Failed to mark recording %d failed: %v", r.id, dbErr)
+		}
+	}
+	if writeErr != nil {
+		fail(fmt.Errorf("write audio: %w", writeErr))
+		return
+	}
+	if _, err := file.WriteAt(wavHeader(samples), 0); err != nil {
+		fail(fmt.Errorf("write wav header: %w", err))
+		return
+	}
+	if err := file.Close(); err != nil {
+		fail(fmt.Errorf("close audio: %w", err))
+		return
+	}
+
+	// Captions arrive in the order they were transcribed; replay seeks by
+	// when they were spoken
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Offset < entries[j].Offset })
+	transcript, err := json.MarshalIndent(RecordingTranscript{
+		MeetingID:       r.meetingID,
+		RecordingID:     r.id,
+		StartedAt:       r.startedAt,
+		EndedAt:         endedAt,
+		DurationSeconds: duration,
+		SampleRate:      sampleRate,
+		Entries:         entries,
+	}, "", "  ")
+	if err != nil {
+		fail(fmt.Errorf("encode transcript: %w", err))
+		return
+	}
+
+	ctx := context.Background()
+	prefix := fmt.Sprintf("recording_%d", r.id)
+	audioKey := storage.SafeObjectKey("meetings", r.meetingID, "recordings", prefix+".wav")
+	transcriptKey := storage.SafeObjectKey("meetings", r.meetingID, "recordings", prefix+".json")
+	if _, _, err := recordingStore.UploadFile(ctx, audioKey, file.Name(), "audio/wav"); err != nil {
+		fail(fmt.Errorf("upload audio: %w", err))
+		return
+	}
+	if _, _, err := recordingStore.UploadBytes(ctx, transcriptKey, transcript, "application/json"); err != nil {
+		_ = recordingStore.Remove(ctx, audioKey)
+		fail(fmt.Errorf("upload transcript: %w", err))
+		return
+	}
+	if err := database.CompleteMeetingRecording(r.id, recordingStore.Bucket(), audioKey, transcriptKey); err != nil {
+		log.Printf("[Recording] Failed to complete recording %d: %v", r.id, err)
+		return
+	}
+	log.Printf("[Recording] Stored recording %d of meeting %s (%.1fs, %d captions)", r.id, r.meetingID, duration, len(entries))
+}
+
+// RecordingURLs returns presigned links to a ready recording's audio and
+// transcript
+func RecordingURLs(ctx context.Context, recording *database.MeetingRecording) (string, string, error) {
+	if !recordingStore.Enabled() || recording.BucketName != recordingStore.Bucket() {
+		return "", "", ErrRecordingDisabled
+	}
+	name := fmt.Sprintf("meeting_%s_recording_%d", recording.MeetingID, recording.ID)
+	audioURL, err := recordingStore.PresignedDownloadURL(ctx, recording.AudioKey, name+".wav", 0)
+	if err != nil {
+		return "", "", err
+	}
+	transcriptURL, err := recordingStore.PresignedDownloadURL(ctx, recording.TranscriptKey, name+".json", 0)
+	if err != nil {
+		return "", "", err
+	}
+	return audioURL, transcriptURL, nil
+}
+
+// wavHeader returns the header of a 16-bit mono PCM WAV file of n samples
+func wavHeader(n int64) []byte {
+	dataSize := uint32(2 * n)
+	header := make([]byte, wavHeaderSize)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], 36+dataSize)
+	copy(header[8:], "WAVE")
+	copy(header[12:], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)
+	binary.LittleEndian.PutUint16(header[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(header[22:], 1) // mono
+	binary.LittleEndian.PutUint32(header[24:], sampleRate)
+	binary.LittleEndian.PutUint32(header[28:], sampleRate*2)
+	binary.LittleEndian.PutUint16(header[32:], 2)
+	binary.LittleEndian.PutUint16(header[34:], 16)
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], dataSize)
+	return header
+}
+
+// formatTimecode formats seconds as HH:MM:SS.mmm
+func formatTimecode(seconds float64) string {
+	ms := int64(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
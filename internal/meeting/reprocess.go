@@ -0,0 +1,194 @@
+package meeting
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/llm"
+	"realtime-caption-translator/internal/progress"
+	"realtime-caption-translator/internal/rag"
+)
+
+// ReprocessSummary compares a meeting's knowledge artifacts before and after
+// they were rebuilt from its transcript snapshots
+type ReprocessSummary struct {
+	MeetingID   string            `json:"meetingId"`
+	Chunks      []ChunkDiff       `json:"chunks"`
+	Minutes     []MinutesDiff     `json:"minutes"`
+	ChunkStatus map[string]int    `json:"chunkStatus"` // chunks per processing status afterwards
+	Errors      map[string]string `json:"errors,omitempty"`
+}
+
+// ChunkDiff counts a language's chunks before and after reprocessing,
+// matched by content hash
+type ChunkDiff struct {
+	Language  string `json:"language"`
+	Before    int    `json:"before"`
+	After     int    `json:"after"`
+	Added     int    `json:"added"`
+	Removed   int    `json:"removed"`
+	Unchanged int    `json:"unchanged"`
+}
+
+// MinutesDiff compares a language's minutes before and after reprocessing
+type MinutesDiff struct {
+	Language          string `json:"language"`
+	Regenerated       bool   `json:"regenerated"`
+	KeyPointsBefore   int    `json:"keyPointsBefore"`
+	KeyPointsAfter    int    `json:"keyPointsAfter"`
+	ActionItemsBefore int    `json:"actionItemsBefore"`
+	ActionItemsAfter  int    `json:"actionItemsAfter"`
+	DecisionsBefore   int    `json:"decisionsBefore"`
+	DecisionsAfter    int    `json:"decisionsAfter"`
+	SummaryChanged    bool   `json:"summaryChanged"`
+}
+
+// ReprocessKnowledge rebuilds a meeting's chunks and embeddings, then
+// regenerates its minutes, from the stored transcript snapshots, e.g. after
+// transcripts were edited or the chunking or models changed. Each language's
+// chunks are replaced in one transaction once their embeddings are ready, so
+// chat keeps answering from the old chunks meanwhile; chunks of languages
+// without a snapshot are removed. Minutes are
+// regenerated for every language that had them (English when none did) and
+// need llmClient. Progress is reported on tracker. Failures of single
+// languages are listed in the summary; an error means nothing was rebuilt.
+func ReprocessKnowledge(meetingID string, processor *rag.Processor, llmClient *llm.Client, tracker *progress.Tracker) (*ReprocessSummary, error) {
+	tracker.Update("snapshot", 5, "Reading current knowledge artifacts")
+	snapshots, err := database.ListMeetingTranscriptSnapshots(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("meeting %s has no transcript snapshots", meetingID)
+	}
+	chunksBefore, err := database.GetChunkHashesByLanguage(meetingID)
+	if err != nil {
+		return nil, err
+	}
+
+	minutesLanguages := make([]string, 0, len(snapshots))
+	minutesBefore := make(map[string]*database.MeetingMinutes)
+	for _, snapshot := range snapshots {
+		minutes, err := database.GetMeetingMinutes(meetingID, snapshot.Language)
+		if err != nil {
+			return nil, err
+		}
+		if minutes != nil {
+			minutesBefore[snapshot.Language] = minutes
+			minutesLanguages = append(minutesLanguages, snapshot.Language)
+		}
+	}
+	if len(minutesLanguages) == 0 {
+		minutesLanguages = append(minutesLanguages, "en")
+	}
+
+	summary := &ReprocessSummary{MeetingID: meetingID, Errors: make(map[string]string)}
+
+	tracker.Update("cleanup", 10, "Removing chunks of languages without a transcript")
+	for language := range chunksBefore {
+		if hasSnapshot(snapshots, language) {
+			continue
+		}
+		removed, err := database.DeleteChunksFromIndex(meetingID, language, 0)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("[Reprocess] Removed %d %s chunks of meeting %s", removed, language, meetingID)
+	}
+
+	for i, snapshot := range snapshots {
+		tracker.Updatef("chunks", 15+60*float64(i)/float64(len(snapshots)), "Rebuilding chunks and embeddings (%s)", snapshot.Language)
+		full, err := database.GetMeetingTranscriptSnapshot(meetingID, snapshot.Language)
+		if err != nil || full == nil {
+			summary.Errors["chunks:"+snapshot.Language] = fmt.Sprintf("failed to load transcript: %v", err)
+			continue
+		}
+		if processor == nil {
+			summary.Errors["chunks:"+snapshot.Language] = "RAG processing is not configured"
+			continue
+		}
+		if err := processor.ReprocessTranscript(meetingID, snapshot.Language, full.Transcript); err != nil {
+			summary.Errors["chunks:"+snapshot.Language] = err.Error()
+		}
+	}
+
+	chunksAfter, err := database.GetChunkHashesByLanguage(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	summary.Chunks = diffChunks(chunksBefore, chunksAfter)
+	if summary.ChunkStatus, err = database.GetChunkStatusCounts(meetingID); err != nil {
+		log.Printf("[Reprocess] Failed to count chunk statuses of meeting %s: %v", meetingID, err)
+	}
+
+	for i, language := range minutesLanguages {
+		tracker.Updatef("minutes", 75+20*float64(i)/float64(len(minutesLanguages)), "Regenerating minutes (%s)", language)
+		diff := MinutesDiff{Language: language}
+		before := minutesBefore[language]
+		if before != nil {
+			diff.KeyPointsBefore = len(before.Content.KeyPoints)
+			diff.ActionItemsBefore = len(before.Content.ActionItems)
+			diff.DecisionsBefore = len(before.Content.Decisions)
+		}
+
+		if llmClient == nil {
+			summary.Errors["minutes:"+language] = "LLM is not configured"
+		} else if err := GenerateMeetingMinutes(meetingID, language, llmClient); err != nil {
+			summary.Errors["minutes:"+language] = err.Error()
+		} else if after, err := database.GetMeetingMinutes(meetingID, language); err != nil || after == nil {
+			summary.Errors["minutes:"+language] = fmt.Sprintf("failed to read regenerated minutes: %v", err)
+		} else {
+			diff.Regenerated = true
+			diff.KeyPointsAfter = len(after.Content.KeyPoints)
+			diff.ActionItemsAfter = len(after.Content.ActionItems)
+			diff.DecisionsAfter = len(after.Content.Decisions)
+			diff.SummaryChanged = before == nil || before.Summary != after.Summary
+		}
+		summary.Minutes = append(summary.Minutes, diff)
+	}
+
+	return summary, nil
+}
+
+// hasSnapshot reports whether snapshots include a language
+func hasSnapshot(snapshots []database.TranscriptSnapshot, language string) bool {
+	for _, snapshot := range snapshots {
+		if snapshot.Language == language {
+			return true
+		}
+	}
+	return false
+}
+
+// diffChunks compares chunk content hashes per language
+func diffChunks(before, after map[string][]string) []ChunkDiff {
+	languages := make(map[string]bool)
+	for language := range before {
+		languages[language] = true
+	}
+	for language := range after {
+		languages[language] = true
+	}
+
+	diffs := make([]ChunkDiff, 0, len(languages))
+	for language := range languages {
+		remaining := make(map[string]int)
+		for _, hash := range before[language] {
+			remaining[hash]++
+		}
+		diff := ChunkDiff{Language: language, Before: len(before[language]), After: len(after[language])}
+		for _, hash := range after[language] {
+			if hash != "" && remaining[hash] > 0 {
+				remaining[hash]--
+				diff.Unchanged++
+			}
+		}
+		diff.Added = diff.After - diff.Unchanged
+		diff.Removed = diff.Before - diff.Unchanged
+		diffs = append(diffs, diff)
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Language < diffs[j].Language })
+	return diffs
+}
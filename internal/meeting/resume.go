@@ -0,0 +1,122 @@
+package meeting
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// SetResumeGrace sets how long a participant whose connection dropped keeps
+// their seat: reconnecting with their resume token within it reattaches them
+// without a leave and join. 0 removes them as soon as the connection drops.
+func (rm *RoomManager) SetResumeGrace(grace time.Duration) {
+	rm.mu.Lock()
+	rm.resumeGrace = grace
+	rm.mu.Unlock()
+}
+
+// newResumeToken returns a random token for a participant's seat
+func newResumeToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// reattach returns the participant whose seat a reconnecting client claims
+// with token, stopping its pending leave, or nil when the token does not
+// match or the seat is already gone. The previous participant may also be
+// a connection that has not noticed it dropped yet.
+func (rm *RoomManager) reattach(meetingID string, participantID int, token string) *Participant {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	room := rm.activeRooms[meetingID]
+	if room == nil {
+		return nil
+	}
+	previous := room.Participants[participantID]
+	if previous == nil || previous.resumeToken == "" ||
+		subtle.ConstantTimeCompare([]byte(previous.resumeToken), []byte(token)) != 1 {
+		return nil
+	}
+	if timer := rm.departing[participantID]; timer != nil {
+		if !timer.Stop() {
+			// The grace period ran out and the participant is leaving
+			return nil
+		}
+		delete(rm.departing, participantID)
+	}
+	return previous
+}
+
+// holdSeat decides what happens when a participant's connection ends. It
+// reports true when leave must not run now: either a resumed connection
+// took the seat over, or the participant dropped and leave is deferred by
+// the resume grace period. Waiting and kicked participants leave at once.
+func (rm *RoomManager) holdSeat(meetingID string, participant *Participant, leave func()) bool {
+	admission, err := database.GetParticipantAdmission(participant.ID)
+	if err != nil {
+		log.Printf("Failed to get admission of participant %d: %v", participant.ID, err)
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	room := rm.activeRooms[meetingID]
+	if room == nil {
+		return false
+	}
+	if current := room.Participants[participant.ID]; current != nil && current != participant {
+		return true
+	}
+	if rm.resumeGrace <= 0 || participant.Waiting || admission == database.AdmissionRejected {
+		return false
+	}
+
+	if rm.departing == nil {
+		rm.departing = make(map[int]*time.Timer)
+	}
+	grace := rm.resumeGrace
+	var timer *time.Timer
+	timer = time.AfterFunc(grace, func() {
+		rm.mu.Lock()
+		pending := rm.departing[participant.ID] == timer
+		if pending {
+			delete(rm.departing, participant.ID)
+			// A resume that raced the drop may already hold the seat
+			if room := rm.activeRooms[meetingID]; room == nil || room.Participants[participant.ID] != participant {
+				pending = false
+			}
+		}
+		rm.mu.Unlock()
+		if pending {
+			log.Printf("Participant %d did not resume within %s", participant.ID, grace)
+			leave()
+		}
+	})
+	rm.departing[participant.ID] = timer
+	log.Printf("Participant %d dropped from meeting %s; seat held for %s", participant.ID, meetingID, grace)
+	return true
+}
+
+// resume lets a reattached participant back in: replays what they missed
+// and confirms the resume, without announcing a join or asking for consent
+// again
+func (rm *RoomManager) resume(meetingID string, participant *Participant, sinceSeq int64) {
+	if sinceSeq > 0 {
+		replayEvents(participant.outbox, meetingID, sinceSeq)
+	}
+	sendDirect(participant, Message{
+		Type:          "resumed",
+		ParticipantID: participant.ID,
+		ResumeToken:   participant.resumeToken,
+	})
+	if participant.Consent == nil {
+		rm.requestConsent(meetingID, participant, participant.UserID)
+	}
+	log.Printf("Participant %d resumed in meeting %s", participant.ID, meetingID)
+}
@@ -1,6 +1,7 @@
 package meeting
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -8,10 +9,12 @@ import (
 	"sync"
 	"time"
 
-	"github.com/gorilla/websocket"
-
+	"realtime-caption-translator/internal/audio/vad"
 	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/i18n"
+	"realtime-caption-translator/internal/llm"
 	"realtime-caption-translator/internal/rag"
+	"realtime-caption-translator/internal/tts"
 )
 
 // RoomManager manages active meeting rooms
@@ -20,13 +23,34 @@ type RoomManager struct {
 	mu           sync.RWMutex
 	activeRooms  map[string]*Room // meetingId -> Room
 	ragProcessor *rag.Processor   // RAG processor for chunking and embedding transcripts
+
+	captionMu        sync.RWMutex
+	captionConsumers map[string]map[*CaptionConsumer]struct{} // meetingId -> delayed caption feeds
+
+	onAutoEnd           func(meetingID string) // finalization after a meeting hits its duration limit
+	idleTimeout         time.Duration          // silence after which a room is suspended; 0 disables
+	voiceMatchThreshold float64                // similarity needed to name a speaker by enrolled voice; 0 disables
+	simplifier          *llm.Client            // rewrites captions for participants in simplification mode; nil disables
+	synthesizer         *tts.Client            // speaks final captions to participants who ask for it; nil disables
+	partialInterval     time.Duration          // how often unfinished speech is previewed; 0 disables partial captions
+	partialWindow       time.Duration          // latest audio a partial caption is transcribed from
+
+	languageWindow        int     // confident detections that vote on a participant's language; below 2 disables smoothing
+	languageMinConfidence float64 // probability a language detection needs to vote
+
+	vadConfig      vad.Config // speech detection defaults of new connections
+	chunkThreshold float64    // RMS a chunk needs to be transcribed; 0 uses DefaultChunkThreshold
+
+	resumeGrace time.Duration       // how long a dropped participant's seat is held; 0 disables
+	departing   map[int]*time.Timer // pending leaves of dropped participants, by participant ID
 }
 
 // NewRoomManager creates a new room manager with RAG support
 func NewRoomManager(ragProcessor *rag.Processor) *RoomManager {
 	return &RoomManager{
-		activeRooms:  make(map[string]*Room),
-		ragProcessor: ragProcessor,
+		activeRooms:      make(map[string]*Room),
+		ragProcessor:     ragProcessor,
+		captionConsumers: make(map[string]map[*CaptionConsumer]struct{}),
 	}
 }
 
@@ -54,6 +78,12 @@ func (rm *RoomManager) GetRoom(meetingID string) *Room {
 
 // EndMeeting closes a meeting, saves transcript snapshots, and disconnects participants.
 func (rm *RoomManager) EndMeeting(meetingID string) error {
+	return rm.endMeeting(meetingID, Message{Type: "meeting_ended"}.withText("The host ended the meeting"))
+}
+
+// endMeeting ends a meeting and sends ended to every participant before
+// disconnecting them
+func (rm *RoomManager) endMeeting(meetingID string, ended Message) error {
 	rm.mu.Lock()
 	room, exists := rm.activeRooms[meetingID]
 	if !exists {
@@ -70,14 +100,18 @@ func (rm *RoomManager) EndMeeting(meetingID string) error {
 		transcriptSnapshots[lang] = formatTranscriptEntries(entries)
 	}
 
-	participants := make([]*Participant, 0, len(room.Participants))
-	for _, participant := range room.Participants {
-		participants = append(participants, participant)
-	}
+	recipients := room.allRecipients()
+	recording := room.takeRecording()
 
 	delete(rm.activeRooms, meetingID)
 	rm.mu.Unlock()
 
+	if recording != nil {
+		go recording.finish()
+	}
+
+	rm.finishCaptions(meetingID)
+
 	if err := database.EndMeeting(meetingID); err != nil {
 		return err
 	}
@@ -90,30 +124,15 @@ func (rm *RoomManager) EndMeeting(meetingID string) error {
 
 	// Trigger asynchronous RAG processing for each language
 	if rm.ragProcessor != nil {
-		for lang, transcript := range transcriptSnapshots {
-			// Launch goroutine for each language to process in parallel
-			go func(meetingID, language, transcriptText string) {
-				if err := rm.ragProcessor.ProcessMeetingTranscript(meetingID, language, transcriptText); err != nil {
-					log.Printf("[RAG] Processing error for meeting %s (language: %s): %v", meetingID, language, err)
-				}
-			}(meetingID, lang, transcript)
-		}
+		IngestTranscripts(meetingID, rm.ragProcessor, transcriptSnapshots)
 	}
 
-	message := Message{
-		Type:      "meeting_ended",
-		Timestamp: time.Now(),
-	}
-	payload, err := json.Marshal(message)
-	if err != nil {
-		return nil
-	}
+	ended.Timestamp = time.Now().UTC()
+	recordEvent(meetingID, &ended)
+	deliver(recipients, ended)
 
-	for _, participant := range participants {
-		if participant.Connection != nil {
-			_ = participant.Connection.WriteMessage(websocket.TextMessage, payload)
-			participant.Connection.Close()
-		}
+	for _, recipient := range recipients {
+		recipient.out.finish()
 	}
 
 	return nil
@@ -201,10 +220,15 @@ func (rm *RoomManager) RemoveParticipant(meetingID string, participantID int) {
 			}
 			transcriptSnapshots[lang] = formatTranscriptEntries(entries)
 		}
+		recording := room.takeRecording()
 		delete(rm.activeRooms, meetingID)
 		log.Printf("Meeting room %s is empty - removed", meetingID)
 		rm.mu.Unlock()
 
+		if recording != nil {
+			go recording.finish()
+		}
+
 		clearSpeakerProfile(meetingID, participantID)
 
 		if err := database.EndMeeting(meetingID); err != nil {
@@ -216,6 +240,10 @@ func (rm *RoomManager) RemoveParticipant(meetingID string, participantID int) {
 				log.Printf("Failed to save meeting transcript snapshot %s/%s: %v", meetingID, lang, err)
 			}
 		}
+
+		if rm.ragProcessor != nil {
+			IngestTranscripts(meetingID, rm.ragProcessor, transcriptSnapshots)
+		}
 		return
 	}
 
@@ -224,11 +252,25 @@ func (rm *RoomManager) RemoveParticipant(meetingID string, participantID int) {
 	clearSpeakerProfile(meetingID, participantID)
 }
 
-// Broadcast sends a message to all participants in a room
-// Pattern from progress.Manager - thread-safe broadcasting
+// Broadcast queues a message for all participants in a room. Each
+// participant's writer goroutine sends it, so a slow connection never stalls
+// the others.
 func (rm *RoomManager) Broadcast(meetingID string, message Message) {
 	// Add timestamp
-	message.Timestamp = time.Now()
+	message.Timestamp = time.Now().UTC()
+
+	if message.Type == "transcription" {
+		message.LiveOnly = !rm.speakerConsented(meetingID, message.SpeakerParticipantID)
+	}
+	recordEvent(meetingID, &message)
+	if message.Type == "transcription" && message.IsFinal {
+		if message.SegmentID = message.UtteranceID; message.SegmentID == "" {
+			message.SegmentID = newSegmentID()
+		}
+		message.EntryID = persistTranscript(meetingID, message)
+		rm.publishCaptions(meetingID, message)
+		rm.recordCaption(meetingID, message)
+	}
 
 	rm.mu.RLock()
 	room, exists := rm.activeRooms[meetingID]
@@ -238,33 +280,123 @@ func (rm *RoomManager) Broadcast(meetingID string, message Message) {
 		return
 	}
 
-	if message.Type == "transcription" {
+	if message.Type == "transcription" && message.IsFinal {
 		room.AddTranscriptFromMessage(message)
 	}
 
-	data, err := json.Marshal(message)
-	if err != nil {
-		log.Printf("Error marshaling meeting message: %v", err)
+	// Create a copy of participants to avoid holding lock during send
+	rm.mu.RLock()
+	recipients := room.recipients()
+	rm.mu.RUnlock()
+
+	if message.Type != "transcription" || !message.IsFinal {
+		deliver(recipients, message)
 		return
 	}
 
-	// Create a copy of participants to avoid holding lock during send
-	rm.mu.RLock()
-	participants := make([]*Participant, 0, len(room.Participants))
-	for _, p := range room.Participants {
-		participants = append(participants, p)
+	// Participants in simplification mode get the caption once it is
+	// rewritten, off the broadcast path so the LLM never holds up the room
+	var plain, simplifying []recipient
+	for _, to := range recipients {
+		if to.accessibility.Simplify {
+			simplifying = append(simplifying, to)
+		} else {
+			plain = append(plain, to)
+		}
 	}
-	rm.mu.RUnlock()
+	deliver(plain, message)
+	if len(simplifying) > 0 {
+		go rm.deliverSimplified(simplifying, message)
+	}
+
+	var listening []recipient
+	for _, to := range recipients {
+		if to.accessibility.Speak {
+			listening = append(listening, to)
+		}
+	}
+	if len(listening) > 0 {
+		go rm.deliverSpeech(meetingID, listening, message)
+	}
+}
+
+// recipient is a snapshot of a participant's outbox, language and caption
+// options, taken under the room lock so sending does not need it
+type recipient struct {
+	participantID int
+	language      string
+	accessibility AccessibilitySettings
+	out           *outbox
+}
 
-	// Broadcast to all participants
-	for _, participant := range participants {
-		if participant.Connection == nil {
+// recipients lists the connected participants, leaving out those in the
+// waiting room; callers hold rm.mu
+func (r *Room) recipients() []recipient {
+	return r.connected(false)
+}
+
+// allRecipients lists the connected participants, including those in the
+// waiting room; callers hold rm.mu
+func (r *Room) allRecipients() []recipient {
+	return r.connected(true)
+}
+
+func (r *Room) connected(waiting bool) []recipient {
+	recipients := make([]recipient, 0, len(r.Participants))
+	for _, p := range r.Participants {
+		if p.Connection == nil || (p.Waiting && !waiting) {
 			continue
 		}
+		recipients = append(recipients, recipient{
+			participantID: p.ID,
+			language:      p.TargetLanguage,
+			accessibility: p.Accessibility,
+			out:           p.outbox,
+		})
+	}
+	return recipients
+}
 
-		if err := participant.Connection.WriteMessage(websocket.TextMessage, data); err != nil {
-			log.Printf("Error sending message to participant %d: %v", participant.ID, err)
-			// Note: Connection cleanup should be handled by the WebSocket handler
+// deliver queues a message for each recipient. Messages with localized text are
+// marshaled once per target language, and captions once per caption style.
+func deliver(recipients []recipient, message Message) {
+	type variant struct {
+		language string
+		style    CaptionStyle
+	}
+	payloads := make(map[variant][]byte)
+	for _, to := range recipients {
+		var key variant
+		if message.textFormat != "" {
+			key.language = to.language
+		}
+		var style *CaptionStyle
+		if message.Type == "transcription" {
+			if style = to.accessibility.captionStyle(); style != nil {
+				key.style = *style
+			}
+		}
+
+		data, ok := payloads[key]
+		if !ok {
+			localized := message
+			if message.textFormat != "" {
+				localized.Text = i18n.Sprintf(key.language, message.textFormat, message.textArgs...)
+			}
+			localized.Style = style
+			var err error
+			data, err = json.Marshal(localized)
+			if err != nil {
+				log.Printf("Error marshaling meeting message: %v", err)
+				return
+			}
+			payloads[key] = data
+		}
+
+		if message.Type == "transcription" {
+			to.out.enqueueCaption(message.SpeakerParticipantID, message.IsFinal, data)
+		} else {
+			to.out.enqueue(data)
 		}
 	}
 }
@@ -328,6 +460,28 @@ func (rm *RoomManager) GetTranscriptLanguages(meetingID string) []string {
 	return room.GetTranscriptLanguages()
 }
 
+// roomCheckInterval is how often rooms are checked for duration limits,
+// idleness and stale connections
+const roomCheckInterval = 15 * time.Second
+
+// WatchRooms enforces meeting duration limits, suspends idle rooms and drops
+// unresponsive participants until ctx is cancelled
+func (rm *RoomManager) WatchRooms(ctx context.Context) {
+	ticker := time.NewTicker(roomCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			rm.checkDurations(now)
+			rm.checkIdle(now)
+			rm.dropStaleParticipants(now)
+		}
+	}
+}
+
 // GetActiveRoomCount returns the number of active rooms
 func (rm *RoomManager) GetActiveRoomCount() int {
 	rm.mu.RLock()
@@ -335,6 +489,18 @@ func (rm *RoomManager) GetActiveRoomCount() int {
 	return len(rm.activeRooms)
 }
 
+// GetActiveParticipantCount returns the number of participants across all active rooms
+func (rm *RoomManager) GetActiveParticipantCount() int {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	count := 0
+	for _, room := range rm.activeRooms {
+		count += len(room.Participants)
+	}
+	return count
+}
+
 func formatTranscriptEntries(entries []TranscriptEntry) string {
 	var b strings.Builder
 	for _, entry := range entries {
@@ -345,7 +511,8 @@ func formatTranscriptEntries(entries []TranscriptEntry) string {
 		if speaker == "" {
 			speaker = "Speaker"
 		}
-		ts := entry.Timestamp.Format("15:04:05")
+		// Stored snapshots are always in UTC
+		ts := entry.Timestamp.UTC().Format("15:04:05")
 		b.WriteString(fmt.Sprintf("[%s] %s: %s\n", ts, speaker, entry.Text))
 	}
 	return b.String()
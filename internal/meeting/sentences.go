@@ -0,0 +1,55 @@
+package meeting
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"realtime-caption-translator/internal/translate"
+)
+
+// utteranceSeq numbers finals that are translated sentence by sentence
+var utteranceSeq atomic.Int64
+
+// translateBySentence fills in the translations of a final caption. A final
+// of several sentences is translated one sentence at a time, and each is
+// broadcast as a "translation_segment" as soon as it is ready, so long
+// utterances start showing before the whole text is translated. The final
+// carries the same UtteranceID and the joined translations. A code-switched
+// final is translated one language segment at a time instead, each from the
+// language it was spoken in.
+func (rm *RoomManager) translateBySentence(meetingID string, final *Message, targetLangs []string) {
+	parts := final.LanguageSegments
+	if len(parts) == 0 {
+		for _, sentence := range translate.SplitSentences(final.OriginalText, final.SourceLanguage) {
+			parts = append(parts, LanguageSegment{Text: sentence, Language: final.SourceLanguage})
+		}
+	}
+	if len(parts) < 2 {
+		final.Translations = translateParallel(translationEvent(meetingID, final, final.OriginalText), targetLangs)
+		return
+	}
+
+	final.UtteranceID = fmt.Sprintf("u%d", utteranceSeq.Add(1))
+	translated := make(map[string][]string, len(targetLangs))
+	for i, part := range parts {
+		segment := *final
+		segment.SourceLanguage = part.Language
+		translations := translateParallel(translationEvent(meetingID, &segment, part.Text), targetLangs)
+		for lang, text := range translations {
+			translated[lang] = append(translated[lang], text)
+		}
+
+		segment.Type = "translation_segment"
+		segment.OriginalText = part.Text
+		segment.Translations = translations
+		segment.LanguageSegments = nil
+		segment.Sub = i + 1
+		segment.SubCount = len(parts)
+		rm.Broadcast(meetingID, segment)
+	}
+
+	final.Translations = make(map[string]string, len(translated))
+	for lang, texts := range translated {
+		final.Translations[lang] = translate.JoinSentences(texts, lang)
+	}
+}
@@ -0,0 +1,27 @@
+package meeting
+
+import (
+	"realtime-caption-translator/internal/database"
+)
+
+// RenameSpeaker names a diarized speaker, rewriting the name into the
+// meeting's stored transcript and RAG chunks and the live room's transcript,
+// and tells participants with a "speaker_renamed" message so they can
+// relabel captions already on screen. The message is logged, so
+// reconnecting participants replay it after the captions it applies to.
+func (rm *RoomManager) RenameSpeaker(meetingID, speakerID, speakerName string) (*database.SpeakerRename, error) {
+	rename, err := database.RenameMeetingSpeaker(meetingID, speakerID, speakerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if room := rm.GetRoom(meetingID); room != nil {
+		room.RenameSpeaker(speakerID, speakerName)
+	}
+	rm.Broadcast(meetingID, Message{
+		Type:        "speaker_renamed",
+		SpeakerID:   speakerID,
+		SpeakerName: speakerName,
+	})
+	return rename, nil
+}
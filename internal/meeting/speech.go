@@ -0,0 +1,157 @@
+package meeting
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"realtime-caption-translator/internal/flags"
+	"realtime-caption-translator/internal/hooks"
+	"realtime-caption-translator/internal/lexicon"
+	"realtime-caption-translator/internal/provenance"
+	"realtime-caption-translator/internal/tts"
+	"realtime-caption-translator/internal/voicepolicy"
+)
+
+// SetSpeechSynthesizer sets the TTS client that speaks final captions to
+// participants who ask for it; nil turns spoken captions off. Spoken captions
+// are live dubbing: they also need the live_dubbing flag for the meeting's
+// creator.
+func (rm *RoomManager) SetSpeechSynthesizer(client *tts.Client) {
+	rm.mu.Lock()
+	rm.synthesizer = client
+	rm.mu.Unlock()
+}
+
+// speechHeader describes the audio of a binary "speech" frame
+type speechHeader struct {
+	Type                 string `json:"type"`
+	SegmentID            string `json:"segmentId"`
+	Language             string `json:"language"`
+	SpeakerParticipantID int    `json:"speakerParticipantId,omitempty"`
+
+	// Provenance labels the audio as machine-generated speech
+	Provenance provenance.Info `json:"provenance"`
+}
+
+// speechFrame builds a binary frame: a 4-byte big-endian header length, the
+// JSON header, then the synthesized audio
+func speechFrame(header speechHeader, audio []byte) ([]byte, error) {
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	frame := make([]byte, 4, 4+len(encoded)+len(audio))
+	binary.BigEndian.PutUint32(frame, uint32(len(encoded)))
+	frame = append(frame, encoded...)
+	return append(frame, audio...), nil
+}
+
+// speechSubject returns who spoken captions in a meeting are gated and
+// voiced for, loading it on first use; ok is false when live dubbing is off
+// for them
+func (rm *RoomManager) speechSubject(meetingID string) (subject flags.Subject, ok bool) {
+	rm.mu.RLock()
+	room, exists := rm.activeRooms[meetingID]
+	loaded := exists && room.speechSubjectLoaded
+	if loaded {
+		subject = room.speechSubject
+	}
+	rm.mu.RUnlock()
+	if !exists {
+		return flags.Subject{}, false
+	}
+
+	if !loaded {
+		subject = flags.SubjectForMeeting(meetingID)
+		rm.mu.Lock()
+		room.speechSubject, room.speechSubjectLoaded = subject, true
+		rm.mu.Unlock()
+	}
+	return subject, flags.Enabled(flags.LiveDubbing, subject)
+}
+
+// deliverSpeech synthesizes a final caption once per target language of
+// the recipients who asked for spoken captions and sends each of them the
+// audio in their language, tagged with the caption's segment ID. It runs
+// after the caption is delivered, so speech never holds up text. The text
+// goes through the pre-TTS hooks and the org's lexicon, and is voiced under
+// the org's voice policy, like dubbed uploads.
+func (rm *RoomManager) deliverSpeech(meetingID string, recipients []recipient, message Message) {
+	rm.mu.RLock()
+	synthesizer := rm.synthesizer
+	rm.mu.RUnlock()
+	if synthesizer == nil {
+		return
+	}
+	subject, enabled := rm.speechSubject(meetingID)
+	if !enabled {
+		return
+	}
+	client := synthesizer.WithPolicy(voicepolicy.For(subject.OrgID))
+
+	byLanguage := make(map[string][]recipient)
+	for _, to := range recipients {
+		// A speaker does not need to hear their own words read back
+		if to.participantID == message.SpeakerParticipantID {
+			continue
+		}
+		byLanguage[to.language] = append(byLanguage[to.language], to)
+	}
+
+	for language, group := range byLanguage {
+		text := message.Translations[language]
+		if text == "" {
+			text = message.OriginalText
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		text, ok := hooks.Run(hooks.PreTTS, hooks.Event{
+			Source:    hooks.SourceMeeting,
+			MeetingID: meetingID,
+			Speaker:   message.SpeakerName,
+			Text:      text,
+			Language:  language,
+			Final:     true,
+		})
+		if !ok || strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		audio, err := client.Synthesize(lexicon.Apply(subject.OrgID, language, text), language)
+		if err != nil {
+			log.Printf("Caption speech synthesis failed (%s, segment %s): %v", language, message.SegmentID, err)
+			continue
+		}
+		frame, err := speechFrame(speechHeader{
+			Type:                 "speech",
+			SegmentID:            message.SegmentID,
+			Language:             language,
+			SpeakerParticipantID: message.SpeakerParticipantID,
+			Provenance: provenance.Info{
+				SessionID:  meetingID,
+				SourceLang: message.SourceLanguage,
+				TargetLang: language,
+				Voice:      provenance.VoiceStandard,
+				CreatedAt:  time.Now().UTC(),
+			},
+		}, audio)
+		if err != nil {
+			log.Printf("Error encoding caption speech: %v", err)
+			continue
+		}
+		for _, to := range group {
+			to.out.enqueueAudio(frame)
+		}
+	}
+}
+
+// newSegmentID names a final caption that was not split into sentences;
+// split finals keep their utterance ID
+func newSegmentID() string {
+	return fmt.Sprintf("u%d", utteranceSeq.Add(1))
+}
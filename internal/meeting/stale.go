@@ -0,0 +1,36 @@
+package meeting
+
+import (
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// dropStaleParticipants closes the connections of participants that stopped
+// answering pings. The read deadline normally ends their handler first; this
+// catches any that outlive it. Closing makes the handler's read fail, which
+// removes the participant and tells the room.
+func (rm *RoomManager) dropStaleParticipants(now time.Time) {
+	type stale struct {
+		meetingID string
+		id        int
+		conn      *websocket.Conn
+	}
+	var dropped []stale
+
+	rm.mu.RLock()
+	for meetingID, room := range rm.activeRooms {
+		for id, participant := range room.Participants {
+			if participant.Connection != nil && participant.keepalive.Stale(now) {
+				dropped = append(dropped, stale{meetingID, id, participant.Connection})
+			}
+		}
+	}
+	rm.mu.RUnlock()
+
+	for _, p := range dropped {
+		log.Printf("Dropping unresponsive participant %d from meeting %s", p.id, p.meetingID)
+		p.conn.Close()
+	}
+}
@@ -0,0 +1,145 @@
+package meeting
+
+import (
+	"log"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// restorePageSize is how many persisted entries are read per query when a
+// room's transcript is restored
+const restorePageSize = 1000
+
+// persistTranscript stores a final caption as it is broadcast, so the
+// meeting's transcript survives a crash and can be read while it runs.
+// Captions of speakers who have not consented are not stored. Returns the
+// stored entry's ID, or 0.
+func persistTranscript(meetingID string, message Message) int64 {
+	if message.OriginalText == "" || message.LiveOnly {
+		return 0
+	}
+	from, to := message.spokenFrom, message.spokenTo
+	if from.IsZero() {
+		from = message.Timestamp
+	}
+	if to.IsZero() {
+		to = from
+	}
+	entry := &database.MeetingTranscriptEntry{
+		MeetingID:     meetingID,
+		EventSeq:      message.Seq,
+		ParticipantID: message.SpeakerParticipantID,
+		SpeakerID:     message.SpeakerID,
+		SpeakerName:   message.SpeakerName,
+		Language:      message.SourceLanguage,
+		Text:          message.OriginalText,
+		Translations:  message.Translations,
+		SpokenAt:      from,
+		AudioRef:      message.AudioRef,
+		SegmentID:     message.SegmentID,
+	}
+	if err := database.SaveMeetingTranscriptEntry(entry, to); err != nil {
+		log.Printf("Failed to persist transcript entry for meeting %s: %v", meetingID, err)
+		return 0
+	}
+	return entry.ID
+}
+
+// restoreTranscript loads the entries persisted before the room was created,
+// e.g. by a server that crashed mid-meeting, so snapshots taken when the
+// meeting ends are complete. It runs once per room.
+func (rm *RoomManager) restoreTranscript(meetingID string) {
+	room := rm.GetRoom(meetingID)
+	if room == nil {
+		return
+	}
+	room.transcriptMu.Lock()
+	if room.restored {
+		room.transcriptMu.Unlock()
+		return
+	}
+	room.restored = true
+	room.transcriptMu.Unlock()
+
+	entries, err := listTranscriptEntries(meetingID)
+	if err != nil {
+		log.Printf("Failed to restore transcript of meeting %s: %v", meetingID, err)
+		return
+	}
+	var earlier []database.MeetingTranscriptEntry
+	for _, entry := range entries {
+		if entry.SpokenAt.Before(room.createdAt) {
+			earlier = append(earlier, entry)
+		}
+	}
+	if len(earlier) == 0 {
+		return
+	}
+
+	// Build the earlier transcript apart, then put it before the captions
+	// broadcast since the room was created
+	restored := transcriptRoom(meetingID, earlier)
+	room.transcriptMu.Lock()
+	for lang, entries := range restored.transcripts {
+		room.transcripts[lang] = append(entries, room.transcripts[lang]...)
+	}
+	room.transcriptMu.Unlock()
+	log.Printf("Restored %d transcript entries of meeting %s", len(earlier), meetingID)
+}
+
+// rebuildTranscriptSnapshots rewrites the transcript snapshots of a meeting
+// that is not running from its persisted entries, so a correction made after
+// the meeting ended reaches the snapshots
+func rebuildTranscriptSnapshots(meetingID string) error {
+	entries, err := listTranscriptEntries(meetingID)
+	if err != nil {
+		return err
+	}
+	room := transcriptRoom(meetingID, entries)
+	for _, lang := range room.GetTranscriptLanguages() {
+		transcript := formatTranscriptEntries(room.GetTranscript(lang))
+		if transcript == "" {
+			continue
+		}
+		if err := database.SaveMeetingTranscriptSnapshot(meetingID, lang, transcript); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listTranscriptEntries reads all persisted entries of a meeting, a page at
+// a time
+func listTranscriptEntries(meetingID string) ([]database.MeetingTranscriptEntry, error) {
+	var entries []database.MeetingTranscriptEntry
+	filter := database.TranscriptEntryFilter{Limit: restorePageSize}
+	for {
+		page, err := database.ListMeetingTranscriptEntries(meetingID, filter)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, page...)
+		if len(page) < filter.Limit {
+			return entries, nil
+		}
+		filter.AfterID = page[len(page)-1].ID
+	}
+}
+
+// transcriptRoom builds a detached room holding the transcript of entries
+func transcriptRoom(meetingID string, entries []database.MeetingTranscriptEntry) *Room {
+	room := NewRoom(meetingID)
+	for _, entry := range entries {
+		room.AddTranscriptFromMessage(Message{
+			Type:           "transcription",
+			EntryID:        entry.ID,
+			SpeakerID:      entry.SpeakerID,
+			SpeakerName:    entry.SpeakerName,
+			OriginalText:   entry.Text,
+			SourceLanguage: entry.Language,
+			Translations:   entry.Translations,
+			Timestamp:      entry.SpokenAt,
+		})
+	}
+	return room
+}
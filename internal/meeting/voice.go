@@ -0,0 +1,66 @@
+package meeting
+
+import (
+	"fmt"
+	"log"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// SetVoiceMatchThreshold sets the cosine similarity at which a diarized
+// speaker is named after an enrolled voice print; 0 disables matching
+func (rm *RoomManager) SetVoiceMatchThreshold(threshold float64) {
+	rm.mu.Lock()
+	rm.voiceMatchThreshold = threshold
+	rm.mu.Unlock()
+}
+
+func (rm *RoomManager) voiceMatchingEnabled() bool {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.voiceMatchThreshold > 0
+}
+
+// EnrolledSpeakerID is the speaker ID of captions matched to a user's
+// enrolled voice. Unlike the per-device IDs of shared-room diarization
+// ("P1_SPEAKER_00") it is the same on every device and in every meeting.
+func EnrolledSpeakerID(userID int) string {
+	return fmt.Sprintf("USER_%d", userID)
+}
+
+// identifySpeaker returns the enrolled user a device's diarized speaker
+// was matched to. Unmatched speakers are tried again on each segment, since
+// early segments may be too short to match; a match holds for the rest of
+// the room's life.
+func (rm *RoomManager) identifySpeaker(meetingID, deviceSpeakerID string, embedding []float32) *database.VoiceMatch {
+	rm.mu.RLock()
+	threshold := rm.voiceMatchThreshold
+	room := rm.activeRooms[meetingID]
+	var known *database.VoiceMatch
+	if room != nil {
+		known = room.enrolledSpeakers[deviceSpeakerID]
+	}
+	rm.mu.RUnlock()
+	if known != nil {
+		return known
+	}
+	if room == nil || threshold <= 0 || len(embedding) == 0 || database.DB == nil {
+		return nil
+	}
+
+	match, err := database.MatchMeetingVoice(meetingID, embedding, threshold)
+	if err != nil {
+		log.Printf("Failed to match speaker %s in meeting %s: %v", deviceSpeakerID, meetingID, err)
+		return nil
+	}
+	if match == nil {
+		return nil
+	}
+	log.Printf("[DIARIZATION] Matched %s to enrolled voice of user %d %q (similarity %.2f)",
+		deviceSpeakerID, match.UserID, match.Name, match.Similarity)
+
+	rm.mu.Lock()
+	room.enrolledSpeakers[deviceSpeakerID] = match
+	rm.mu.Unlock()
+	return match
+}
@@ -0,0 +1,210 @@
+package meeting
+
+import (
+	"log"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// hostPowers returns what a participant may do to others: moderate (mute,
+// kick, lock) takes a signed-in owner or co-host or the host token; admitting
+// from the waiting room also takes editors
+func hostPowers(meetingID string, userID *int, hostToken string) (canModerate, canAdmit bool) {
+	if hostToken != "" {
+		valid, err := database.ValidateMeetingHostToken(meetingID, hostToken)
+		if err != nil {
+			log.Printf("Failed to validate host token for meeting %s: %v", meetingID, err)
+		}
+		if valid {
+			return true, true
+		}
+	}
+	if userID == nil {
+		return false, false
+	}
+	role, err := database.GetUserMeetingRole(*userID, meetingID)
+	if err != nil {
+		log.Printf("Failed to get role of user %d in meeting %s: %v", *userID, meetingID, err)
+		return false, false
+	}
+	return database.IsModeratorRole(role), database.IsModeratorRole(role) || role == database.RoleEditor
+}
+
+// welcome lets a participant into the meeting: replays what they missed,
+// announces them and asks for recording consent
+func (rm *RoomManager) welcome(meetingID string, participant *Participant, sinceSeq int64) {
+	if sinceSeq > 0 {
+		replayEvents(participant.outbox, meetingID, sinceSeq)
+	}
+
+	// Broadcast participant joined
+	rm.Broadcast(meetingID, Message{
+		Type:            "participant_joined",
+		ParticipantID:   participant.ID,
+		ParticipantName: participant.Name,
+		TargetLanguage:  participant.TargetLanguage,
+	}.withText("%s joined the meeting", participant.Name))
+
+	// Ask for recording consent; until answered, speech is live-only
+	rm.requestConsent(meetingID, participant, participant.UserID)
+
+	// The token reclaims the seat if the connection drops
+	sendDirect(participant, Message{Type: "resume_token", ResumeToken: participant.resumeToken})
+
+	// A muted participant who reconnects stays muted
+	rm.mu.RLock()
+	muted := false
+	if room := rm.activeRooms[meetingID]; room != nil {
+		muted = room.muted[participant.ID]
+	}
+	rm.mu.RUnlock()
+	if muted {
+		sendDirect(participant, Message{
+			Type:            "participant_muted",
+			ParticipantID:   participant.ID,
+			ParticipantName: participant.Name,
+		}.withText("%s was muted by the host", participant.Name))
+	}
+
+	// Hosts joining late see who is already waiting
+	if participant.CanAdmit {
+		for _, waiting := range rm.WaitingParticipants(meetingID) {
+			sendDirect(participant, joinRequest(waiting))
+		}
+	}
+}
+
+// joinRequest is the message asking hosts to admit a participant
+func joinRequest(participant Participant) Message {
+	return Message{
+		Type:            "join_request",
+		ParticipantID:   participant.ID,
+		ParticipantName: participant.Name,
+		TargetLanguage:  participant.TargetLanguage,
+	}.withText("%s is waiting to join", participant.Name)
+}
+
+// requestAdmission tells a participant they are waiting and asks the hosts
+// in the room to admit them
+func (rm *RoomManager) requestAdmission(meetingID string, participant *Participant) {
+	log.Printf("Participant %d (%s) is waiting to join meeting %s", participant.ID, participant.Name, meetingID)
+	sendDirect(participant, Message{
+		Type:          "waiting",
+		ParticipantID: participant.ID,
+	}.withText("Waiting for the host to let you in"))
+	rm.notifyAdmitters(meetingID, joinRequest(*participant))
+}
+
+// notifyAdmitters sends a message to the admitted participants who decide on
+// join requests
+func (rm *RoomManager) notifyAdmitters(meetingID string, message Message) {
+	rm.mu.RLock()
+	var admitters []recipient
+	if room := rm.activeRooms[meetingID]; room != nil {
+		for _, to := range room.recipients() {
+			if p := room.Participants[to.participantID]; p != nil && p.CanAdmit {
+				admitters = append(admitters, to)
+			}
+		}
+	}
+	rm.mu.RUnlock()
+
+	message.Timestamp = time.Now().UTC()
+	deliver(admitters, message)
+}
+
+// decideAdmission admits or rejects a participant in the waiting room on
+// behalf of a host
+func (rm *RoomManager) decideAdmission(meetingID string, host *Participant, participantID int, admit bool) {
+	if !host.CanAdmit {
+		sendDirect(host, Message{Type: "error", Error: "Only hosts can admit participants"}.
+			withText("Only hosts can admit participants"))
+		return
+	}
+
+	rm.mu.Lock()
+	var waiting *Participant
+	if room := rm.activeRooms[meetingID]; room != nil {
+		if p := room.Participants[participantID]; p != nil && p.Waiting {
+			waiting = p
+			if admit {
+				p.Waiting = false
+			}
+		}
+	}
+	rm.mu.Unlock()
+	if waiting == nil {
+		return
+	}
+
+	admission := database.AdmissionRejected
+	if admit {
+		admission = database.AdmissionAdmitted
+	}
+	if err := database.SetParticipantAdmission(participantID, admission); err != nil {
+		log.Printf("Failed to store admission of participant %d: %v", participantID, err)
+	}
+	log.Printf("Participant %d in meeting %s %s by participant %d", participantID, meetingID, admission, host.ID)
+
+	rm.notifyAdmitters(meetingID, Message{
+		Type:            "join_request_resolved",
+		ParticipantID:   participantID,
+		ParticipantName: waiting.Name,
+		Admission:       admission,
+	})
+
+	if admit {
+		sendDirect(waiting, Message{Type: "admitted", ParticipantID: participantID}.
+			withText("The host let you in"))
+		rm.welcome(meetingID, waiting, 0)
+		return
+	}
+	sendDirect(waiting, Message{Type: "join_rejected", ParticipantID: participantID}.
+		withText("The host declined your request to join"))
+	waiting.outbox.finish()
+}
+
+// isWaiting reports whether a participant is in the waiting room
+func (rm *RoomManager) isWaiting(meetingID string, participantID int) bool {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	if room := rm.activeRooms[meetingID]; room != nil {
+		if p := room.Participants[participantID]; p != nil {
+			return p.Waiting
+		}
+	}
+	return false
+}
+
+// WaitingParticipants lists the participants waiting to be admitted
+func (rm *RoomManager) WaitingParticipants(meetingID string) []Participant {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	waiting := []Participant{}
+	if room := rm.activeRooms[meetingID]; room != nil {
+		for _, p := range room.Participants {
+			if p.Waiting {
+				waiting = append(waiting, Participant{
+					ID:             p.ID,
+					Name:           p.Name,
+					TargetLanguage: p.TargetLanguage,
+					JoinedAt:       p.JoinedAt,
+				})
+			}
+		}
+	}
+	return waiting
+}
+
+// sendDirect queues a message for one participant, localizing its text to
+// their language
+func sendDirect(participant *Participant, message Message) {
+	message.Timestamp = time.Now().UTC()
+	deliver([]recipient{{
+		participantID: participant.ID,
+		language:      participant.TargetLanguage,
+		out:           participant.outbox,
+	}}, message)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -16,24 +17,76 @@ import (
 
 	"github.com/gorilla/websocket"
 
+	"realtime-caption-translator/internal/audio"
+	"realtime-caption-translator/internal/audio/vad"
 	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/hooks"
+	"realtime-caption-translator/internal/httpx"
+	"realtime-caption-translator/internal/keepalive"
+	"realtime-caption-translator/internal/langcode"
+	"realtime-caption-translator/internal/metrics"
+	"realtime-caption-translator/internal/ratelimit"
+	"realtime-caption-translator/internal/replica"
 )
 
 const (
 	// Audio buffer configuration
 	sampleRate    = 16000
-	windowSeconds = 12                         // Increased from 8 to give diarization more context
-	bufferSize    = sampleRate * windowSeconds // 192,000 samples
+	windowSeconds = 12 // Longest chunk; chunks normally end at pauses in speech
+
+	// Client sample rates accepted in audio_format messages
+	minClientSampleRate = 8000
+	maxClientSampleRate = 192000
 )
 
 var (
-	// ASR and Translation service URLs
-	asrBaseURL         = getEnv("ASR_BASE_URL", "http://127.0.0.1:8003")
+	// ASR and Translation service URLs; ASR_BASE_URL may list several
+	// replicas, of which the first is used until a pool is set
+	asrBaseURL         = strings.TrimSpace(strings.Split(getEnv("ASR_BASE_URL", "http://127.0.0.1:8003"), ",")[0])
 	translationBaseURL = getEnv("TRANSLATION_BASE_URL", "http://127.0.0.1:8004")
+
+	// Limiters shared with the rest of the server; nil leaves a service unlimited
+	asrLimiter         *ratelimit.Limiter
+	translationLimiter *ratelimit.Limiter
+
+	// asrReplicas spreads ASR calls over registered replicas; nil uses asrBaseURL
+	asrReplicas *replica.Pool
+
+	// Retry and circuit breakers shared with the rest of the server; nil
+	// sends each call once
+	asrBreaker         *httpx.Breaker
+	translationBreaker *httpx.Breaker
 )
 
+// SetServiceLimiters bounds meeting ASR and translation calls. Meetings queue
+// at interactive priority; chunks arriving while a queue is full are dropped
+// instead of piling up behind the service. Call before rooms are served.
+func SetServiceLimiters(asr, translation *ratelimit.Limiter) {
+	asrLimiter = asr
+	translationLimiter = translation
+}
+
+// SetServiceBreakers retries meeting ASR and translation calls and stops
+// them while a service is down. Call before rooms are served.
+func SetServiceBreakers(asr, translation *httpx.Breaker) {
+	asrBreaker = asr
+	translationBreaker = translation
+}
+
+// SetASRReplicas spreads meeting ASR calls over the pool's replicas. Call
+// before rooms are served.
+func SetASRReplicas(pool *replica.Pool) {
+	asrReplicas = pool
+}
+
 // HandleMeetingWebSocket handles WebSocket connections for meeting rooms
-func (rm *RoomManager) HandleMeetingWebSocket(conn *websocket.Conn, meetingID string, participantID int, participantName, targetLang string, minSpeakers int, maxSpeakers int, strictness float64) {
+// userID is the user the connection authenticated as, which host powers
+// come from; the caller has checked the participant belongs to them;
+// interpretLang is non-empty when the participant is the interpreter channel;
+// sinceSeq, when positive, replays the logged events after it on reconnect;
+// hostToken, when valid, lets the participant admit others from the waiting room;
+// resumeToken, when it matches a held seat, reattaches the participant to it
+func (rm *RoomManager) HandleMeetingWebSocket(conn *websocket.Conn, meetingID string, participantID int, userID *int, participantName, targetLang string, minSpeakers int, maxSpeakers int, strictness float64, interpretLang string, sinceSeq int64, hostToken, resumeToken string) {
 	log.Printf("Meeting WebSocket connected: participant %d (%s) in meeting %s", participantID, participantName, meetingID)
 
 	// Get meeting to check mode
@@ -46,49 +99,120 @@ func (rm *RoomManager) HandleMeetingWebSocket(conn *websocket.Conn, meetingID st
 
 	// Get participant from database to ensure it exists
 	dbParticipant, err := database.GetParticipantByID(participantID)
-	if err != nil || dbParticipant == nil {
-		log.Printf("Invalid participant ID %d: %v", participantID, err)
+	if err != nil || dbParticipant == nil || dbParticipant.MeetingID != dbMeeting.ID {
+		log.Printf("Invalid participant ID %d for meeting %s: %v", participantID, meetingID, err)
 		conn.Close()
 		return
 	}
 
+	admission, err := database.GetParticipantAdmission(participantID)
+	if err != nil {
+		log.Printf("Failed to get admission of participant %d: %v", participantID, err)
+	}
+	if admission == database.AdmissionRejected {
+		log.Printf("Rejected participant %d tried to connect to meeting %s", participantID, meetingID)
+		conn.Close()
+		return
+	}
+	canModerate, canAdmit := hostPowers(dbMeeting.ID, userID, hostToken)
+
 	// Create participant object
 	participant := &Participant{
 		ID:             participantID,
 		Name:           participantName,
 		TargetLanguage: targetLang,
-		JoinedAt:       time.Now(),
+		JoinedAt:       time.Now().UTC(),
 		Connection:     conn,
 		MinSpeakers:    minSpeakers,
 		MaxSpeakers:    maxSpeakers,
 		Strictness:     strictness,
+		UserID:         dbParticipant.UserID,
+		Waiting:        admission == database.AdmissionWaiting && !canAdmit,
+		CanAdmit:       canAdmit,
+		CanModerate:    canModerate,
+		keepalive:      keepalive.Start(conn),
+		outbox:         newOutbox(conn, participantID),
+		resumeToken:    newResumeToken(),
+		meter:          billedMeter(dbParticipant.UserID, dbMeeting.CreatedBy),
+	}
+	defer participant.keepalive.Stop()
+	defer participant.meter.Flush()
+	defer participant.outbox.close()
+	if dbMeeting.Mode == ModeInterpreted {
+		participant.InterpretLanguage = interpretLang
+	}
+
+	// A reconnecting client takes its held seat back with the settings and
+	// consent it had; a connection that has not noticed the drop is closed
+	var previous *Participant
+	if resumeToken != "" {
+		previous = rm.reattach(meetingID, participantID, resumeToken)
+	}
+	if previous != nil {
+		participant.resumeToken = previous.resumeToken
+		participant.Consent = previous.Consent
+		participant.Accessibility = previous.Accessibility
+		rm.mu.RLock()
+		participant.pushToTalk = previous.pushToTalk
+		rm.mu.RUnlock()
+		previous.Connection.Close()
 	}
 
 	// Add participant to room
 	rm.AddParticipant(meetingID, participant)
+	rm.loadDurationLimit(dbMeeting)
+	rm.restoreTranscript(meetingID)
+
+	// Participants in the waiting room are welcomed once admitted
+	switch {
+	case participant.Waiting:
+		rm.requestAdmission(meetingID, participant)
+	case previous != nil:
+		rm.resume(meetingID, participant, sinceSeq)
+	default:
+		rm.welcome(meetingID, participant, sinceSeq)
+	}
 
-	// Broadcast participant joined
-	rm.Broadcast(meetingID, Message{
-		Type:            "participant_joined",
-		ParticipantID:   participantID,
-		ParticipantName: participantName,
-		TargetLanguage:  targetLang,
-	})
-
-	// Audio buffer for streaming
-	audioBuffer := make([]int16, 0, bufferSize)
-	var bufferMu sync.Mutex
-
-	// Cleanup on disconnect
-	defer func() {
+	// Segment streamed audio at pauses so words are not split across chunks
+	vadConfig, chunkThreshold := rm.voiceDetection()
+	segmenter := vad.New(vadConfig)
+	partials := rm.newPartialCaptioner(meetingID, participantID, participantName, dbMeeting.Mode)
+	var levelSentAt time.Time
+	// Audio is 16kHz unless the client declares another rate with an
+	// audio_format message
+	var resampler *audio.Resampler
+	// Speaking indicators follow the VAD; with push-to-talk on, audio is only
+	// accepted while the talk button is held, which a resumed client presses
+	// again
+	pushToTalk, talking := participant.pushToTalk, false
+
+	metrics.WebSocketSessions.Inc("meeting")
+
+	// Cleanup on disconnect, unless the seat is held for a resume
+	leave := func() {
+		waiting := rm.isWaiting(meetingID, participantID)
 		rm.RemoveParticipant(meetingID, participantID)
 		database.RemoveParticipant(participantID) // Mark as inactive in database
-		rm.Broadcast(meetingID, Message{
-			Type:            "participant_left",
-			ParticipantID:   participantID,
-			ParticipantName: participantName,
-		})
+		if waiting {
+			rm.notifyAdmitters(meetingID, Message{
+				Type:            "join_request_withdrawn",
+				ParticipantID:   participantID,
+				ParticipantName: participantName,
+			}.withText("%s stopped waiting to join", participantName))
+		} else {
+			rm.Broadcast(meetingID, Message{
+				Type:            "participant_left",
+				ParticipantID:   participantID,
+				ParticipantName: participantName,
+			}.withText("%s left the meeting", participantName))
+		}
 		log.Printf("Participant %d (%s) disconnected from meeting %s", participantID, participantName, meetingID)
+	}
+	defer func() {
+		metrics.WebSocketSessions.Dec("meeting")
+		if !rm.holdSeat(meetingID, participant, leave) {
+			leave()
+		}
 	}()
 
 	// Read audio data from WebSocket
@@ -100,26 +224,45 @@ func (rm *RoomManager) HandleMeetingWebSocket(conn *websocket.Conn, meetingID st
 			}
 			break
 		}
+		participant.keepalive.Touch()
 
 		// Handle binary audio data
 		if messageType == websocket.BinaryMessage {
+			// Audio is not accepted from the waiting room or from
+			// participants a moderator muted
+			if rm.dropsAudio(meetingID, participantID) || (pushToTalk && !talking) {
+				continue
+			}
+
 			// Convert bytes to int16 samples
 			samples := bytesToInt16(data)
+			if resampler != nil {
+				samples = resampler.Process(samples)
+			}
+			rm.recordAudio(meetingID, participantID, samples)
 
-			bufferMu.Lock()
-			audioBuffer = append(audioBuffer, samples...)
-
-			// Process chunk when buffer is full
-			if len(audioBuffer) >= bufferSize {
-				chunk := make([]int16, bufferSize)
-				copy(chunk, audioBuffer[:bufferSize])
-				audioBuffer = audioBuffer[bufferSize:]
-				bufferMu.Unlock()
+			// A suspended room drops silent audio without buffering it;
+			// the first voiced frame resumes it
+			if rm.isSuspended(meetingID) {
+				if vad.RMS(samples) <= segmenter.Threshold() {
+					continue
+				}
+				rm.resumeRoom(meetingID)
+			}
 
+			threshold := voiceThreshold(segmenter, chunkThreshold)
+			for _, chunk := range segmenter.Push(samples) {
+				partials.finalized()
 				// Process chunk asynchronously
-				go rm.processAudioChunk(meetingID, participantID, participantName, chunk, dbMeeting.Mode)
-			} else {
-				bufferMu.Unlock()
+				go rm.processAudioChunk(meetingID, participantID, participantName, chunk, dbMeeting.Mode, threshold)
+			}
+			partials.update(segmenter, threshold)
+
+			rm.setSpeaking(meetingID, participant, segmenter.Speaking())
+
+			if time.Since(levelSentAt) >= levelInterval {
+				levelSentAt = time.Now()
+				sendAudioLevel(participant, segmenter, chunkThreshold)
 			}
 		}
 
@@ -129,31 +272,209 @@ func (rm *RoomManager) HandleMeetingWebSocket(conn *websocket.Conn, meetingID st
 			if err := json.Unmarshal(data, &controlMsg); err == nil {
 				log.Printf("Control message from participant %d: %v", participantID, controlMsg)
 				if msgType, ok := controlMsg["type"].(string); ok && msgType == "update_language" {
-					if lang, ok := controlMsg["targetLanguage"].(string); ok && lang != "" {
+					if lang, ok := controlMsg["targetLanguage"].(string); ok && langcode.Normalize(lang) != "" {
+						lang = langcode.Normalize(lang)
 						if err := database.UpdateParticipantLanguage(participantID, lang); err != nil {
 							log.Printf("Failed to update participant language: %v", err)
 						} else {
 							rm.UpdateParticipantLanguage(meetingID, participantID, lang)
 							rm.Broadcast(meetingID, Message{
-								Type:           "participant_language_updated",
-								ParticipantID:  participantID,
-								TargetLanguage: lang,
+								Type:            "participant_language_updated",
+								ParticipantID:   participantID,
+								ParticipantName: participantName,
+								TargetLanguage:  lang,
+							}.withText("%s changed their language to %s", participantName, lang))
+						}
+					}
+				}
+				if msgType, ok := controlMsg["type"].(string); ok && msgType == "admission" {
+					// A host's answer to a join request
+					var decision struct {
+						ParticipantID int  `json:"participantId"`
+						Admit         bool `json:"admit"`
+					}
+					if err := json.Unmarshal(data, &decision); err == nil && decision.ParticipantID > 0 {
+						rm.decideAdmission(meetingID, participant, decision.ParticipantID, decision.Admit)
+					}
+				}
+				if msgType, ok := controlMsg["type"].(string); ok && (msgType == "mute" || msgType == "kick" || msgType == "lock") {
+					// Moderation by the owner, a co-host or the host
+					var action struct {
+						ParticipantID int    `json:"participantId"`
+						Muted         bool   `json:"muted"`
+						Locked        bool   `json:"locked"`
+						Reason        string `json:"reason"`
+					}
+					if err := json.Unmarshal(data, &action); err == nil {
+						switch msgType {
+						case "mute":
+							rm.muteParticipant(meetingID, participant, action.ParticipantID, action.Muted)
+						case "kick":
+							rm.kickParticipant(meetingID, participant, action.ParticipantID, action.Reason)
+						case "lock":
+							rm.lockRoom(meetingID, participant, action.Locked)
+						}
+					}
+				}
+				if msgType, ok := controlMsg["type"].(string); ok && msgType == "consent" {
+					if granted, ok := controlMsg["granted"].(bool); ok {
+						rm.recordConsent(meetingID, participant, dbParticipant.UserID, granted)
+					}
+				}
+				if msgType, ok := controlMsg["type"].(string); ok && msgType == "audio_format" {
+					// The rate the client captures at; audio that follows is
+					// resampled to 16kHz before buffering
+					if rate, ok := controlMsg["sampleRate"].(float64); ok {
+						if rate < minClientSampleRate || rate > maxClientSampleRate {
+							message := fmt.Sprintf("Unsupported sample rate %v Hz", rate)
+							if data, err := json.Marshal(Message{Type: "error", Error: message}.withText(message)); err == nil {
+								participant.outbox.enqueue(data)
+							}
+						} else {
+							resampler = nil
+							if int(rate) != sampleRate {
+								resampler = audio.NewResampler(int(rate), sampleRate)
+							}
+							log.Printf("Participant %d streams audio at %d Hz", participantID, int(rate))
+							ack, err := json.Marshal(Message{
+								Type:          "audio_format",
+								ParticipantID: participantID,
+								SampleRate:    int(rate),
+								Timestamp:     time.Now().UTC(),
 							})
+							if err == nil {
+								participant.outbox.enqueue(ack)
+							}
+						}
+					}
+				}
+				if msgType, ok := controlMsg["type"].(string); ok && (msgType == "push_to_talk" || msgType == "talk") {
+					var ptt struct {
+						Enabled bool `json:"enabled"`
+						Pressed bool `json:"pressed"`
+					}
+					if err := json.Unmarshal(data, &ptt); err == nil {
+						if msgType == "push_to_talk" {
+							pushToTalk = ptt.Enabled
+							rm.mu.Lock()
+							participant.pushToTalk = pushToTalk
+							rm.mu.Unlock()
+						} else {
+							talking = ptt.Pressed
+						}
+						// Releasing the talk button ends the utterance at once
+						// instead of waiting for the pause
+						if pushToTalk && !talking {
+							if chunk := segmenter.Flush(); len(chunk) > 0 {
+								partials.finalized()
+								go rm.processAudioChunk(meetingID, participantID, participantName, chunk, dbMeeting.Mode, voiceThreshold(segmenter, chunkThreshold))
+							}
+							rm.setSpeaking(meetingID, participant, false)
+						}
+					}
+				}
+				if msgType, ok := controlMsg["type"].(string); ok && msgType == "update_vad" {
+					// Per-connection thresholds; 0 restores the server default
+					var update struct {
+						Threshold      *float64 `json:"threshold"`
+						ChunkThreshold *float64 `json:"chunkThreshold"`
+						Recalibrate    bool     `json:"recalibrate"`
+					}
+					if err := json.Unmarshal(data, &update); err == nil {
+						if update.Threshold != nil && *update.Threshold >= 0 && *update.Threshold < 1 {
+							segmenter.SetThreshold(*update.Threshold)
+						}
+						if update.ChunkThreshold != nil && *update.ChunkThreshold >= 0 && *update.ChunkThreshold < 1 {
+							chunkThreshold = *update.ChunkThreshold
+							if chunkThreshold == 0 {
+								_, chunkThreshold = rm.voiceDetection()
+							}
+						}
+						if update.Recalibrate {
+							segmenter.Recalibrate()
+						}
+						sendAudioLevel(participant, segmenter, chunkThreshold)
+					}
+				}
+				if msgType, ok := controlMsg["type"].(string); ok && msgType == "update_accessibility" {
+					var update struct {
+						Accessibility AccessibilitySettings `json:"accessibility"`
+					}
+					if err := json.Unmarshal(data, &update); err == nil {
+						settings := rm.UpdateParticipantAccessibility(meetingID, participantID, update.Accessibility)
+						ack, err := json.Marshal(Message{
+							Type:          "accessibility_updated",
+							ParticipantID: participantID,
+							Accessibility: &settings,
+							Timestamp:     time.Now().UTC(),
+						})
+						if err == nil {
+							participant.outbox.enqueue(ack)
 						}
 					}
 				}
 			}
 		}
 	}
+
+	rm.setSpeaking(meetingID, participant, false)
+
+	// Transcribe the speech still buffered when the participant disconnects
+	if chunk := segmenter.Flush(); len(chunk) > 0 {
+		go rm.processAudioChunk(meetingID, participantID, participantName, chunk, dbMeeting.Mode, voiceThreshold(segmenter, chunkThreshold))
+	}
+}
+
+// setSpeaking records whether a participant is talking and tells the room
+// when that changes, ahead of their captions
+func (rm *RoomManager) setSpeaking(meetingID string, participant *Participant, speaking bool) {
+	rm.mu.Lock()
+	changed := participant.speaking != speaking
+	participant.speaking = speaking
+	rm.mu.Unlock()
+	if !changed {
+		return
+	}
+	messageType := "speaking_stopped"
+	if speaking {
+		messageType = "speaking_started"
+	}
+	rm.Broadcast(meetingID, Message{
+		Type:            messageType,
+		ParticipantID:   participant.ID,
+		ParticipantName: participant.Name,
+	})
+}
+
+// sendAudioLevel sends a participant their input level, noise floor and
+// thresholds
+func sendAudioLevel(participant *Participant, segmenter *vad.Segmenter, chunkThreshold float64) {
+	data, err := json.Marshal(Message{
+		Type:       "audio_level",
+		AudioLevel: &AudioLevel{Level: segmenter.Level(), ChunkThreshold: chunkThreshold},
+		Timestamp:  time.Now().UTC(),
+	})
+	if err == nil {
+		participant.outbox.enqueue(data)
+	}
 }
 
-// processAudioChunk transcribes audio and broadcasts translations
-func (rm *RoomManager) processAudioChunk(meetingID string, participantID int, participantName string, audioSamples []int16, mode string) {
+// processAudioChunk transcribes audio and broadcasts translations. threshold
+// is the RMS the chunk needs to be transcribed.
+func (rm *RoomManager) processAudioChunk(meetingID string, participantID int, participantName string, audioSamples []int16, mode string, threshold float64) {
+	// The chunk ends now; remember its span for interpreter alignment
+	chunkEnd := time.Now().UTC()
+	chunkStart := chunkEnd.Add(-time.Duration(len(audioSamples)) * time.Second / sampleRate)
+
 	// Voice Activity Detection - check if chunk has sufficient audio level
-	if !hasVoiceActivity(audioSamples) {
+	if !hasVoiceActivity(audioSamples, threshold) {
 		// Skip silent or very quiet chunks to avoid hallucination
 		return
 	}
+	rm.markVoiceActivity(meetingID)
+	if !rm.chargeSpeech(meetingID, participantID, float64(len(audioSamples))/sampleRate) {
+		return
+	}
 
 	// Convert audio samples to WAV format
 	wavData, err := samplesToWAV(audioSamples, sampleRate)
@@ -169,56 +490,91 @@ func (rm *RoomManager) processAudioChunk(meetingID string, participantID int, pa
 		return
 	}
 
+	// Keep what ASR heard so captions can be checked against it
+	audio := rm.archiveChunk(meetingID, participantID, wavData, chunkEnd.Sub(chunkStart))
+
 	log.Printf("[DEBUG] Processing audio chunk for participant %d (%s) in mode %s with %d target languages", participantID, participantName, mode, len(targetLangs))
 
 	// Process based on meeting mode
 	if mode == "shared" {
 		// Use diarization for shared room mode (per-device)
-		rm.processSharedRoomAudio(meetingID, participantID, participantName, wavData, targetLangs)
+		rm.processSharedRoomAudio(meetingID, participantID, participantName, wavData, targetLangs, chunkStart, chunkEnd, audio)
+	} else if mode == ModeInterpreted {
+		// Floor audio and the interpreter's channel are stored for alignment
+		rm.processInterpretedAudio(meetingID, participantID, participantName, wavData, targetLangs, chunkStart, chunkEnd, audio)
 	} else {
 		// Individual mode - use simple transcription
-		rm.processIndividualAudio(meetingID, participantID, participantName, wavData, targetLangs)
+		rm.processIndividualAudio(meetingID, participantID, participantName, wavData, targetLangs, chunkStart, chunkEnd, audio)
 	}
 }
 
-// processIndividualAudio handles individual device mode
-func (rm *RoomManager) processIndividualAudio(meetingID string, participantID int, participantName string, wavData []byte, targetLangs []string) {
+// processIndividualAudio handles individual device mode. Returns the broadcast
+// transcription, or nil when nothing was transcribed.
+func (rm *RoomManager) processIndividualAudio(meetingID string, participantID int, participantName string, wavData []byte, targetLangs []string, start, end time.Time, audio *chunkAudio) *Message {
 	// Transcribe audio
-	transcription, sourceLang, err := transcribeAudio(wavData)
+	detected, err := detectAndTranscribe(wavData, true)
 	if err != nil {
 		log.Printf("Error transcribing audio: %v", err)
+		message := "Failed to transcribe audio"
+		if errors.Is(err, ratelimit.ErrOverloaded) {
+			message = "Speech recognition is busy; some speech was not transcribed"
+		}
 		rm.Broadcast(meetingID, Message{
 			Type:  "error",
-			Error: "Failed to transcribe audio",
-		})
-		return
+			Error: message,
+		}.withText(message))
+		return nil
 	}
 
+	transcription, detectedLang := detected.Text, detected.Language
 	if transcription == "" {
 		// No speech detected
-		return
+		return nil
 	}
 
-	log.Printf("Transcribed from participant %d: %s (lang: %s)", participantID, transcription, sourceLang)
+	// A chunk detected as another language than the participant has been
+	// speaking is most likely misdetected; transcribe it again in theirs.
+	// A code-switched chunk is kept as it was heard, part by part.
+	languageSegments := languageRuns(detected.Segments)
+	sourceLang := rm.smoothLanguage(meetingID, participantID, detectedLang, detected.Probability)
+	if sourceLang != detectedLang && len(languageSegments) == 0 {
+		text, err := transcribeAudioIn(wavData, sourceLang)
+		if err != nil {
+			log.Printf("Error transcribing in %s, keeping detected %s: %v", sourceLang, detectedLang, err)
+			sourceLang = detectedLang
+		} else if text == "" {
+			return nil
+		} else {
+			transcription = text
+		}
+	}
 
-	// Translate to all target languages in parallel
-	translations := translateParallel(transcription, sourceLang, targetLangs)
+	log.Printf("Transcribed from participant %d: %s (lang: %s)", participantID, transcription, sourceLang)
 
 	// Broadcast transcription with translations to all participants
-	rm.Broadcast(meetingID, Message{
+	message := Message{
 		Type:                 "transcription",
 		SpeakerParticipantID: participantID,
 		SpeakerName:          participantName,
 		OriginalText:         transcription,
 		SourceLanguage:       sourceLang,
-		Translations:         translations,
+		LanguageSegments:     languageSegments,
 		IsFinal:              true,
-	})
+		spokenFrom:           start,
+		spokenTo:             end,
+	}
+	if !transcriptionHooks(meetingID, &message) {
+		return nil
+	}
+	rm.translateBySentence(meetingID, &message, targetLangs)
+	message.AudioRef = audio.ref()
+	rm.Broadcast(meetingID, message)
+	return &message
 }
 
 // processSharedRoomAudio handles shared room mode with speaker diarization
 // Each device's audio is diarized separately to detect multiple speakers on that device
-func (rm *RoomManager) processSharedRoomAudio(meetingID string, participantID int, participantName string, wavData []byte, targetLangs []string) {
+func (rm *RoomManager) processSharedRoomAudio(meetingID string, participantID int, participantName string, wavData []byte, targetLangs []string, start, end time.Time, audio *chunkAudio) {
 	log.Printf("[DEBUG] Processing shared room audio for participant %d (%s)", participantID, participantName)
 
 	minSpeakers, maxSpeakers, strictness := rm.GetParticipantDiarizationSettings(meetingID, participantID)
@@ -229,13 +585,16 @@ func (rm *RoomManager) processSharedRoomAudio(meetingID string, participantID in
 	log.Printf("[DIARIZATION] Settings: minSpeakers=%d, maxSpeakers=%d, strictness=%.2f", minSpeakers, maxSpeakers, strictness)
 
 	// Use diarization endpoint on this device's audio
-	result, err := transcribeWithDiarization(wavData, meetingID, participantID, minSpeakers, maxSpeakers, strictness)
+	result, err := transcribeWithDiarization(wavData, meetingID, participantID, minSpeakers, maxSpeakers, strictness, rm.voiceMatchingEnabled())
 	if err != nil {
 		log.Printf("Error transcribing with diarization: %v", err)
+		if errors.Is(err, ratelimit.ErrOverloaded) {
+			return // a fallback call would only add to the queue
+		}
 		log.Printf("[FALLBACK] Falling back to simple transcription without diarization")
 
 		// Fallback to simple transcription if diarization fails
-		rm.processIndividualAudio(meetingID, participantID, participantName, wavData, targetLangs)
+		rm.processIndividualAudio(meetingID, participantID, participantName, wavData, targetLangs, start, end, audio)
 		return
 	}
 
@@ -250,6 +609,9 @@ func (rm *RoomManager) processSharedRoomAudio(meetingID string, participantID in
 
 	// Get speaker name mappings from database
 	speakerMappings, _ := database.GetSpeakerMappings(meetingID)
+	if speakerMappings == nil {
+		speakerMappings = make(map[string]string)
+	}
 
 	// Process each segment
 	for i, segment := range result.Segments {
@@ -264,25 +626,35 @@ func (rm *RoomManager) processSharedRoomAudio(meetingID string, participantID in
 		deviceSpeakerID := fmt.Sprintf("P%d_%s", participantID, segment.Speaker)
 
 		// Get speaker name (use mapping if exists, otherwise create descriptive name)
+		speakerID := deviceSpeakerID
 		speakerName := speakerMappings[deviceSpeakerID]
-		if speakerName == "" {
-			// Create a name like "Device A - Speaker 1"
-			speakerNum := extractSpeakerNumber(segment.Speaker) + 1
-			speakerName = fmt.Sprintf("%s - Speaker %d", participantName, speakerNum)
-			// Save to database for future reference
-			database.SetSpeakerName(meetingID, deviceSpeakerID, speakerName)
+		// A name like "Device A - Speaker 1"
+		genericName := fmt.Sprintf("%s - Speaker %d", participantName, extractSpeakerNumber(segment.Speaker)+1)
+		if speakerName == "" || speakerName == genericName {
+			// A speaker matching an enrolled voice print becomes that user's
+			// speaker, the same on every device and in every meeting
+			if voice := rm.identifySpeaker(meetingID, deviceSpeakerID, result.SpeakerEmbeddings[segment.Speaker]); voice != nil {
+				speakerID = EnrolledSpeakerID(voice.UserID)
+				if speakerName = speakerMappings[speakerID]; speakerName == "" {
+					speakerName = voice.Name
+					speakerMappings[speakerID] = speakerName
+					database.SetSpeakerName(meetingID, speakerID, speakerName)
+				}
+			} else if speakerName == "" {
+				speakerName = genericName
+				speakerMappings[deviceSpeakerID] = speakerName
+				// Save to database for future reference
+				database.SetSpeakerName(meetingID, deviceSpeakerID, speakerName)
+			}
 		}
 
-		log.Printf("[DIARIZATION] Broadcasting: deviceSpeakerID=%s, speakerName=%s", deviceSpeakerID, speakerName)
-
-		// Translate segment
-		translations := translateParallel(segment.Text, result.Language, targetLangs)
+		log.Printf("[DIARIZATION] Broadcasting: speakerID=%s (device %s), speakerName=%s", speakerID, deviceSpeakerID, speakerName)
 
 		// Broadcast segment with speaker info
-		rm.Broadcast(meetingID, Message{
+		message := Message{
 			Type:                 "transcription",
 			SpeakerParticipantID: participantID,
-			SpeakerID:            deviceSpeakerID,
+			SpeakerID:            speakerID,
 			SpeakerName:          speakerName,
 			SpeakerConfidence:    segment.SpeakerConfidence,
 			SpeakerOverlap:       segment.SpeakerOverlap,
@@ -290,44 +662,126 @@ func (rm *RoomManager) processSharedRoomAudio(meetingID string, participantID in
 			SpeakerLowConfidence: segment.SpeakerLowConfidence,
 			OriginalText:         segment.Text,
 			SourceLanguage:       result.Language,
-			Translations:         translations,
 			IsFinal:              true,
-		})
+			spokenFrom:           start.Add(time.Duration(segment.Start * float64(time.Second))),
+			spokenTo:             start.Add(time.Duration(segment.End * float64(time.Second))),
+		}
+		if !transcriptionHooks(meetingID, &message) {
+			continue
+		}
+		rm.translateBySentence(meetingID, &message, targetLangs)
+		message.AudioRef = audio.ref()
+		rm.Broadcast(meetingID, message)
+	}
+}
+
+// detectedTranscript is a chunk transcribed in its detected language, with
+// the detector's probability for that language. Segments are only filled
+// in when asked for and tag each part with its own language.
+type detectedTranscript struct {
+	Text        string            `json:"text"`
+	Language    string            `json:"language"`
+	Probability float64           `json:"probability"`
+	Segments    []LanguageSegment `json:"segments"`
+}
+
+// transcribeAudio sends audio to ASR service and returns the transcription,
+// the detected language and the detector's probability for it
+func transcribeAudio(wavData []byte) (string, string, float64, error) {
+	result, err := detectAndTranscribe(wavData, false)
+	if err != nil {
+		return "", "", 0, err
 	}
+	return result.Text, result.Language, result.Probability, nil
 }
 
-// transcribeAudio sends audio to ASR service and returns transcription + detected language
-func transcribeAudio(wavData []byte) (string, string, error) {
+// detectAndTranscribe transcribes a chunk in the language detected in it;
+// with segmentLanguages each segment's language is detected as well, for
+// speakers who switch language mid-chunk
+func detectAndTranscribe(wavData []byte, segmentLanguages bool) (*detectedTranscript, error) {
 	// Send WAV data directly (not multipart) - same pattern as asr.Client
 	url := fmt.Sprintf("%s/detect-language", asrBaseURL)
 	req, err := http.NewRequest("POST", url, bytes.NewReader(wavData))
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "audio/wav")
+	if segmentLanguages {
+		req.Header.Set("x-segment-languages", "true")
+	}
+
+	release, err := asrLimiter.TryAcquire(ratelimit.Interactive)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
 	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	start := time.Now()
+	resp, err := asrBreaker.Do(req, func(req *http.Request) (*http.Response, error) {
+		return asrReplicas.Do(client, asrBaseURL, req)
+	})
+	metrics.ObserveRequest(metrics.ASRLatency, metrics.StageASR, "detect_language", start, resp, err)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", "", fmt.Errorf("ASR service error: %s", string(bodyBytes))
+		return nil, fmt.Errorf("ASR service error: %s", string(bodyBytes))
 	}
 
 	// Parse response from detect-language endpoint (includes both text and language)
+	var result detectedTranscript
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	result.Language = langcode.Normalize(result.Language)
+	for i := range result.Segments {
+		result.Segments[i].Language = langcode.Normalize(result.Segments[i].Language)
+	}
+	return &result, nil
+}
+
+// transcribeAudioIn transcribes audio in a known language
+func transcribeAudioIn(wavData []byte, language string) (string, error) {
+	req, err := http.NewRequest("POST", asrBaseURL+"/transcribe", bytes.NewReader(wavData))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "audio/wav")
+	req.Header.Set("x-language", language)
+
+	release, err := asrLimiter.TryAcquire(ratelimit.Interactive)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	start := time.Now()
+	resp, err := asrBreaker.Do(req, func(req *http.Request) (*http.Response, error) {
+		return asrReplicas.Do(client, asrBaseURL, req)
+	})
+	metrics.ObserveRequest(metrics.ASRLatency, metrics.StageASR, "transcribe", start, resp, err)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("ASR service error: %s", string(bodyBytes))
+	}
+
 	var result struct {
-		Text     string `json:"text"`
-		Language string `json:"language"`
+		Text string `json:"text"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", "", err
+		return "", err
 	}
-
-	return result.Text, result.Language, nil
+	return strings.TrimSpace(result.Text), nil
 }
 
 // DiarizationResult represents the response from speaker diarization
@@ -345,10 +799,11 @@ type DiarizationResult struct {
 		SpeakerOverlapRatio  float64 `json:"speaker_overlap_ratio"`
 		SpeakerLowConfidence bool    `json:"speaker_low_confidence"`
 	} `json:"segments"`
+	SpeakerEmbeddings map[string][]float32 `json:"speaker_embeddings,omitempty"` // speaker -> voice profile, when requested
 }
 
 // transcribeWithDiarization sends audio to ASR service with speaker diarization
-func transcribeWithDiarization(wavData []byte, meetingID string, participantID int, minSpeakers int, maxSpeakers int, strictness float64) (*DiarizationResult, error) {
+func transcribeWithDiarization(wavData []byte, meetingID string, participantID int, minSpeakers int, maxSpeakers int, strictness float64, includeEmbeddings bool) (*DiarizationResult, error) {
 	sessionID := fmt.Sprintf("meeting_%s_p%d", meetingID, participantID)
 	query := url.Values{}
 	query.Set("session_id", sessionID)
@@ -361,6 +816,9 @@ func transcribeWithDiarization(wavData []byte, meetingID string, participantID i
 	if strictness > 0 {
 		query.Set("strictness", fmt.Sprintf("%.2f", strictness))
 	}
+	if includeEmbeddings {
+		query.Set("include_embeddings", "true")
+	}
 	url := fmt.Sprintf("%s/transcribe-with-diarization?%s", asrBaseURL, query.Encode())
 	req, err := http.NewRequest("POST", url, bytes.NewReader(wavData))
 	if err != nil {
@@ -368,8 +826,18 @@ func transcribeWithDiarization(wavData []byte, meetingID string, participantID i
 	}
 	req.Header.Set("Content-Type", "audio/wav")
 
+	release, err := asrLimiter.TryAcquire(ratelimit.Interactive)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	client := &http.Client{Timeout: 60 * time.Second} // Longer timeout for diarization
-	resp, err := client.Do(req)
+	start := time.Now()
+	resp, err := asrBreaker.Do(req, func(req *http.Request) (*http.Response, error) {
+		return asrReplicas.Do(client, asrBaseURL, req)
+	})
+	metrics.ObserveRequest(metrics.ASRLatency, metrics.StageASR, "diarize", start, resp, err)
 	if err != nil {
 		return nil, err
 	}
@@ -384,6 +852,7 @@ func transcribeWithDiarization(wavData []byte, meetingID string, participantID i
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, err
 	}
+	result.Language = langcode.Normalize(result.Language)
 
 	return &result, nil
 }
@@ -393,7 +862,19 @@ func clearSpeakerProfile(meetingID string, participantID int) {
 	if err := database.DeleteSpeakerProfiles(sessionID); err != nil {
 		log.Printf("Failed to delete speaker profiles from DB: %v", err)
 	}
-	url := fmt.Sprintf("%s/speaker-profiles/%s", asrBaseURL, sessionID)
+
+	// Profiles live in the memory of whichever replicas diarized the participant
+	baseURLs := asrReplicas.URLs()
+	if len(baseURLs) == 0 {
+		baseURLs = []string{asrBaseURL}
+	}
+	for _, baseURL := range baseURLs {
+		clearReplicaSpeakerProfile(baseURL, sessionID)
+	}
+}
+
+func clearReplicaSpeakerProfile(baseURL, sessionID string) {
+	url := fmt.Sprintf("%s/speaker-profiles/%s", baseURL, sessionID)
 	req, err := http.NewRequest(http.MethodDelete, url, nil)
 	if err != nil {
 		log.Printf("Failed to build speaker profile cleanup request: %v", err)
@@ -403,14 +884,14 @@ func clearSpeakerProfile(meetingID string, participantID int) {
 	client := &http.Client{Timeout: 5 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("Failed to cleanup speaker profile %s: %v", sessionID, err)
+		log.Printf("Failed to cleanup speaker profile %s on %s: %v", sessionID, baseURL, err)
 		return
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= http.StatusBadRequest {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		log.Printf("Speaker profile cleanup failed (%s): %s", sessionID, string(bodyBytes))
+		log.Printf("Speaker profile cleanup failed (%s on %s): %s", sessionID, baseURL, string(bodyBytes))
 	}
 }
 
@@ -439,8 +920,11 @@ func extractSpeakerNumber(speakerID string) int {
 	return 0
 }
 
-// translateParallel translates text to multiple languages concurrently
-func translateParallel(text, sourceLang string, targetLangs []string) map[string]string {
+// translateParallel translates the text of event to multiple languages
+// concurrently through the translation hooks. Languages whose text a hook
+// dropped are left out.
+func translateParallel(event hooks.Event, targetLangs []string) map[string]string {
+	text, sourceLang := event.Text, event.SourceLanguage
 	results := make(map[string]string)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -459,10 +943,16 @@ func translateParallel(text, sourceLang string, targetLangs []string) map[string
 			}
 
 			// Translate
-			translation, err := translateText(text, sourceLang, lang)
+			e := event
+			e.TargetLanguage = lang
+			translation, ok, err := hooks.Translate(e, func(text string) (string, error) {
+				return translateText(text, sourceLang, lang)
+			})
 			if err != nil {
 				log.Printf("Error translating to %s: %v", lang, err)
 				translation = text // Fallback to original
+			} else if !ok {
+				return
 			}
 
 			mu.Lock()
@@ -490,7 +980,21 @@ func translateText(text, sourceLang, targetLang string) (string, error) {
 		return "", err
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	release, err := translationLimiter.TryAcquire(ratelimit.Interactive)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(jsonData))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := translationBreaker.Do(req, http.DefaultClient.Do)
+	metrics.ObserveRequest(metrics.TranslationLatency, metrics.StageTranslation, "translate", start, resp, err)
 	if err != nil {
 		return "", err
 	}
@@ -521,32 +1025,19 @@ func min(a, b int) int {
 	return b
 }
 
-// hasVoiceActivity checks if audio chunk has sufficient energy to contain speech
-func hasVoiceActivity(samples []int16) bool {
+// hasVoiceActivity checks if audio chunk is loud enough to contain speech
+func hasVoiceActivity(samples []int16, threshold float64) bool {
 	if len(samples) == 0 {
 		return false
 	}
 
-	// Calculate RMS (Root Mean Square) energy
-	var sum float64
-	for _, sample := range samples {
-		normalized := float64(sample) / 32768.0 // Normalize to -1.0 to 1.0
-		sum += normalized * normalized
-	}
-	rms := sum / float64(len(samples))
-	energy := rms * 1000 // Scale for easier threshold
-
-	// Threshold for voice activity (tune this value based on testing)
-	// Lower = more sensitive (may include background noise)
-	// Higher = less sensitive (may miss quiet speech)
-	const energyThreshold = 0.5
-
-	hasVoice := energy > energyThreshold
+	rms := vad.RMS(samples)
+	hasVoice := rms > threshold
 
 	if !hasVoice {
-		log.Printf("Skipping chunk - low energy: %.3f (threshold: %.1f)", energy, energyThreshold)
+		log.Printf("Skipping chunk - low level: RMS %.4f (threshold: %.4f)", rms, threshold)
 	} else {
-		log.Printf("Processing chunk - energy: %.3f", energy)
+		log.Printf("Processing chunk - RMS %.4f", rms)
 	}
 
 	return hasVoice
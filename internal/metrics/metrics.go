@@ -0,0 +1,340 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are histogram bounds in seconds for calls to the model services
+var LatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// ProcessingBuckets are histogram bounds in seconds for media and upload processing
+var ProcessingBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 3600}
+
+// Pipeline metrics. Latency histograms are labelled by operation so the same
+// series covers streaming sessions, meetings and uploads.
+var (
+	ASRLatency = NewHistogram("asr_request_duration_seconds",
+		"Latency of ASR service requests", LatencyBuckets, "operation")
+	TranslationLatency = NewHistogram("translation_request_duration_seconds",
+		"Latency of translation service requests", LatencyBuckets, "operation")
+	TTSLatency = NewHistogram("tts_request_duration_seconds",
+		"Latency of TTS service requests", LatencyBuckets, "operation")
+	FFmpegDuration = NewHistogram("ffmpeg_duration_seconds",
+		"Wall time of ffmpeg invocations", ProcessingBuckets, "operation")
+	UploadDuration = NewHistogram("upload_processing_duration_seconds",
+		"Time to fully process an uploaded file", ProcessingBuckets, "kind")
+
+	StageErrors = NewCounter("pipeline_errors_total",
+		"Failed pipeline stage calls", "stage")
+	UploadsFailed = NewCounter("upload_failures_total",
+		"Uploads whose processing failed", "kind")
+
+	WebSocketSessions = NewGauge("websocket_sessions_active",
+		"Open WebSocket connections", "endpoint")
+
+	// Temp dir janitor (internal/video)
+	TempFilesReclaimed = NewCounter("temp_files_reclaimed_total",
+		"Expired temp files deleted by the janitor")
+	TempBytesReclaimed = NewCounter("temp_bytes_reclaimed_total",
+		"Bytes freed by deleting expired temp files")
+
+	// Backend service limiters (internal/ratelimit), by service and priority
+	ServiceQueueWait = NewHistogram("service_queue_wait_seconds",
+		"Time calls waited for a backend service slot", LatencyBuckets, "service", "priority")
+	ServiceQueued = NewGauge("service_requests_queued",
+		"Calls waiting for a backend service slot", "service", "priority")
+	ServiceRejected = NewCounter("service_requests_rejected_total",
+		"Calls rejected because the backend service queue was full", "service", "priority")
+)
+
+// Stage names for StageErrors
+const (
+	StageASR         = "asr"
+	StageTranslation = "translation"
+	StageTTS         = "tts"
+	StageFFmpeg      = "ffmpeg"
+)
+
+// ObserveRequest records the latency of an HTTP call to a pipeline service
+// and counts a stage error when it failed or returned a non-2xx status
+func ObserveRequest(h *Histogram, stage, operation string, start time.Time, resp *http.Response, err error) {
+	h.ObserveSince(start, operation)
+	if err != nil || resp.StatusCode >= 300 {
+		StageErrors.Inc(stage)
+	}
+}
+
+type metric interface {
+	write(b *strings.Builder)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]metric{}
+)
+
+func register(name string, m metric) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[name]; exists {
+		panic("metrics: duplicate metric " + name)
+	}
+	registry[name] = m
+}
+
+// Handler serves all registered metrics in the Prometheus text format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		registryMu.Lock()
+		names := make([]string, 0, len(registry))
+		for name := range registry {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		metrics := make([]metric, len(names))
+		for i, name := range names {
+			metrics[i] = registry[name]
+		}
+		registryMu.Unlock()
+
+		var b strings.Builder
+		for _, m := range metrics {
+			m.write(&b)
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write([]byte(b.String()))
+	})
+}
+
+// series holds the per-label-set state of a metric, keyed by label values
+type series[T any] struct {
+	mu     sync.Mutex
+	labels []string
+	values map[string]*T
+	order  map[string][]string
+}
+
+func newSeries[T any](labels []string) series[T] {
+	return series[T]{labels: labels, values: map[string]*T{}, order: map[string][]string{}}
+}
+
+// get returns the state for the label values, creating it with init. Call with mu held.
+func (s *series[T]) get(values []string, init func() *T) *T {
+	if len(values) != len(s.labels) {
+		panic(fmt.Sprintf("metrics: got %d label values, want %d", len(values), len(s.labels)))
+	}
+	key := strings.Join(values, "\xff")
+	v, exists := s.values[key]
+	if !exists {
+		v = init()
+		s.values[key] = v
+		s.order[key] = append([]string(nil), values...)
+	}
+	return v
+}
+
+// each visits every label set in a stable order. Call with mu held.
+func (s *series[T]) each(fn func(labels string, v *T)) {
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fn(formatLabels(s.labels, s.order[key]), s.values[key])
+	}
+}
+
+// Counter is a monotonically increasing count per label set
+type Counter struct {
+	name, help string
+	series     series[float64]
+}
+
+// NewCounter registers a counter with the given label names
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{name: name, help: help, series: newSeries[float64](labels)}
+	register(name, c)
+	return c
+}
+
+// Inc adds one to the counter for the label values
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta (which must not be negative) to the counter for the label values
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	c.series.mu.Lock()
+	*c.series.get(labelValues, func() *float64 { return new(float64) }) += delta
+	c.series.mu.Unlock()
+}
+
+func (c *Counter) write(b *strings.Builder) {
+	writeHeader(b, c.name, c.help, "counter")
+	c.series.mu.Lock()
+	defer c.series.mu.Unlock()
+	c.series.each(func(labels string, v *float64) {
+		writeSample(b, c.name, labels, *v)
+	})
+}
+
+// Gauge is a value per label set that can go up and down
+type Gauge struct {
+	name, help string
+	series     series[float64]
+}
+
+// NewGauge registers a gauge with the given label names
+func NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{name: name, help: help, series: newSeries[float64](labels)}
+	register(name, g)
+	return g
+}
+
+// Add changes the gauge for the label values by delta
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.series.mu.Lock()
+	*g.series.get(labelValues, func() *float64 { return new(float64) }) += delta
+	g.series.mu.Unlock()
+}
+
+// Inc adds one to the gauge for the label values
+func (g *Gauge) Inc(labelValues ...string) { g.Add(1, labelValues...) }
+
+// Dec subtracts one from the gauge for the label values
+func (g *Gauge) Dec(labelValues ...string) { g.Add(-1, labelValues...) }
+
+func (g *Gauge) write(b *strings.Builder) {
+	writeHeader(b, g.name, g.help, "gauge")
+	g.series.mu.Lock()
+	defer g.series.mu.Unlock()
+	g.series.each(func(labels string, v *float64) {
+		writeSample(b, g.name, labels, *v)
+	})
+}
+
+// gaugeFunc is an unlabelled gauge whose value is read at scrape time
+type gaugeFunc struct {
+	name, help string
+	fn         func() float64
+}
+
+// NewGaugeFunc registers a gauge that calls fn on every scrape
+func NewGaugeFunc(name, help string, fn func() float64) {
+	register(name, &gaugeFunc{name: name, help: help, fn: fn})
+}
+
+func (g *gaugeFunc) write(b *strings.Builder) {
+	writeHeader(b, g.name, g.help, "gauge")
+	writeSample(b, g.name, "", g.fn())
+}
+
+// Histogram counts observations into cumulative buckets per label set
+type Histogram struct {
+	name, help string
+	buckets    []float64
+	series     series[histogramState]
+}
+
+type histogramState struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram registers a histogram with ascending bucket upper bounds
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{name: name, help: help, buckets: buckets, series: newSeries[histogramState](labels)}
+	register(name, h)
+	return h
+}
+
+// Observe records a value for the label values
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.series.mu.Lock()
+	defer h.series.mu.Unlock()
+	state := h.series.get(labelValues, func() *histogramState {
+		return &histogramState{counts: make([]uint64, len(h.buckets))}
+	})
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		state.counts[i]++
+	}
+	state.count++
+	state.sum += value
+}
+
+// ObserveSince records the time elapsed since start, in seconds
+func (h *Histogram) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+func (h *Histogram) write(b *strings.Builder) {
+	writeHeader(b, h.name, h.help, "histogram")
+	h.series.mu.Lock()
+	defer h.series.mu.Unlock()
+	h.series.each(func(labels string, state *histogramState) {
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += state.counts[i]
+			writeSample(b, h.name+"_bucket", withLabel(labels, "le", formatFloat(bound)), float64(cumulative))
+		}
+		writeSample(b, h.name+"_bucket", withLabel(labels, "le", "+Inf"), float64(state.count))
+		writeSample(b, h.name+"_sum", labels, state.sum)
+		writeSample(b, h.name+"_count", labels, float64(state.count))
+	})
+}
+
+func writeHeader(b *strings.Builder, name, help, kind string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeSample(b *strings.Builder, name, labels string, value float64) {
+	b.WriteString(name)
+	if labels != "" {
+		b.WriteString("{" + labels + "}")
+	}
+	b.WriteString(" " + formatFloat(value) + "\n")
+}
+
+// formatLabels renders name="value" pairs without the surrounding braces
+func formatLabels(names, values []string) string {
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escapeLabel(values[i]) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+func withLabel(labels, name, value string) string {
+	pair := name + `="` + value + `"`
+	if labels == "" {
+		return pair
+	}
+	return labels + "," + pair
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
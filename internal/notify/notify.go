@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+
+	"realtime-caption-translator/internal/database"
+
+	"github.com/gorilla/websocket"
+)
+
+// Notification types
+const (
+	TypeMinutesReady     = "minutes_ready"
+	TypeProcessingFailed = "processing_failed"
+	TypeAccessGranted    = "access_granted"
+)
+
+// Types lists every notification type users can opt out of
+var Types = []string{TypeMinutesReady, TypeProcessingFailed, TypeAccessGranted}
+
+// IsKnownType reports whether t is a supported notification type
+func IsKnownType(t string) bool {
+	for _, known := range Types {
+		if known == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Message is pushed to a user's notification WebSocket
+type Message struct {
+	Type         string                 `json:"type"` // "notification"
+	Notification *database.Notification `json:"notification"`
+	UnreadCount  int                    `json:"unreadCount"`
+}
+
+type subscriber struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+var (
+	mu          sync.RWMutex
+	subscribers = make(map[int][]*subscriber)
+)
+
+// Subscribe registers a WebSocket to receive the user's notifications and
+// returns the function that unregisters it
+func Subscribe(userID int, conn *websocket.Conn) func() {
+	sub := &subscriber{conn: conn}
+
+	mu.Lock()
+	subscribers[userID] = append(subscribers[userID], sub)
+	mu.Unlock()
+
+	return func() { unsubscribe(userID, sub) }
+}
+
+func unsubscribe(userID int, sub *subscriber) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	subs := subscribers[userID]
+	for i, s := range subs {
+		if s == sub {
+			subscribers[userID] = append(subs[:i], subs[i+1:]...)
+			break
+		}
+	}
+	if len(subscribers[userID]) == 0 {
+		delete(subscribers, userID)
+	}
+}
+
+// Send stores a notification for the user, unless they disabled its type,
+// and pushes it to their open notification sockets. Failures are logged,
+// never returned: notifications must not break the operation that raised them.
+func Send(userID int, notificationType, title, body, link string, data map[string]interface{}) {
+	if database.DB == nil || userID == 0 {
+		return
+	}
+
+	enabled, err := database.NotificationTypeEnabled(userID, notificationType)
+	if err != nil {
+		log.Printf("[Notify] Failed to check preferences for user %d: %v", userID, err)
+		return
+	}
+	if !enabled {
+		return
+	}
+
+	n := &database.Notification{
+		UserID: userID,
+		Type:   notificationType,
+		Title:  title,
+		Body:   body,
+		Link:   link,
+	}
+	if len(data) > 0 {
+		if raw, err := json.Marshal(data); err == nil {
+			n.Data = raw
+		}
+	}
+	if err := database.CreateNotification(n); err != nil {
+		log.Printf("[Notify] Failed to store %s notification for user %d: %v", notificationType, userID, err)
+		return
+	}
+
+	push(userID, n)
+}
+
+func push(userID int, n *database.Notification) {
+	mu.RLock()
+	subs := make([]*subscriber, len(subscribers[userID]))
+	copy(subs, subscribers[userID])
+	mu.RUnlock()
+
+	if len(subs) == 0 {
+		return
+	}
+
+	unread, err := database.CountUnreadNotifications(userID)
+	if err != nil {
+		log.Printf("[Notify] Failed to count unread notifications: %v", err)
+	}
+	payload, err := json.Marshal(Message{Type: "notification", Notification: n, UnreadCount: unread})
+	if err != nil {
+		log.Printf("[Notify] Failed to marshal notification: %v", err)
+		return
+	}
+
+	for _, sub := range subs {
+		sub.writeMu.Lock()
+		err := sub.conn.WriteMessage(websocket.TextMessage, payload)
+		sub.writeMu.Unlock()
+		if err != nil {
+			log.Printf("[Notify] Failed to push notification to user %d: %v", userID, err)
+			unsubscribe(userID, sub)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+// Package parquet writes small Parquet files: a single row group of
+// uncompressed, PLAIN-encoded columns. It covers the flat and float-list
+// columns used by data exports, not the full format.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// ColumnType is the logical type of a column
+type ColumnType int
+
+const (
+	String    ColumnType = iota // UTF-8 string
+	Int32                       // int or int32
+	Int64                       // int64
+	Double                      // float64
+	Timestamp                   // time.Time, stored as milliseconds since the epoch (UTC)
+	FloatList                   // []float32, stored as a LIST of FLOAT
+)
+
+// Field describes one column. Optional columns accept nil values.
+type Field struct {
+	Name     string
+	Type     ColumnType
+	Optional bool
+}
+
+// Physical types, repetitions, converted types and encodings from parquet.thrift
+const (
+	typeInt32     = 1
+	typeInt64     = 2
+	typeFloat     = 4
+	typeDouble    = 5
+	typeByteArray = 6
+
+	repRequired = 0
+	repOptional = 1
+	repRepeated = 2
+
+	convertedUTF8            = 0
+	convertedList            = 3
+	convertedTimestampMillis = 9
+
+	encodingPlain = 0
+	encodingRLE   = 3
+)
+
+var magic = []byte("PAR1")
+
+// Writer buffers rows in memory and writes them as one row group
+type Writer struct {
+	columns []*column
+	rows    int
+}
+
+type column struct {
+	field     Field
+	maxDef    int
+	maxRep    int
+	defLevels []int
+	repLevels []int
+	values    bytes.Buffer
+}
+
+// NewWriter creates a writer for the given columns
+func NewWriter(fields []Field) *Writer {
+	w := &Writer{}
+	for _, field := range fields {
+		c := &column{field: field}
+		if field.Optional {
+			c.maxDef = 1
+		}
+		if field.Type == FloatList {
+			c.maxDef++ // the repeated list level
+			c.maxRep = 1
+		}
+		w.columns = append(w.columns, c)
+	}
+	return w
+}
+
+// Append adds a row with one value per field, in field order
+func (w *Writer) Append(values ...interface{}) error {
+	if len(values) != len(w.columns) {
+		return fmt.Errorf("parquet: got %d values, want %d", len(values), len(w.columns))
+	}
+	for i, c := range w.columns {
+		if err := c.append(values[i]); err != nil {
+			return fmt.Errorf("parquet: column %s: %w", c.field.Name, err)
+		}
+	}
+	w.rows++
+	return nil
+}
+
+func (c *column) append(value interface{}) error {
+	if c.field.Type == FloatList {
+		return c.appendList(value)
+	}
+	if value == nil {
+		if !c.field.Optional {
+			return fmt.Errorf("nil value in required column")
+		}
+		c.defLevels = append(c.defLevels, 0)
+		return nil
+	}
+
+	var encoded []byte
+	switch c.field.Type {
+	case String:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %T", value)
+		}
+		encoded = append(binary.LittleEndian.AppendUint32(nil, uint32(len(s))), s...)
+	case Int32:
+		switch v := value.(type) {
+		case int:
+			encoded = binary.LittleEndian.AppendUint32(nil, uint32(int32(v)))
+		case int32:
+			encoded = binary.LittleEndian.AppendUint32(nil, uint32(v))
+		default:
+			return fmt.Errorf("expected int32, got %T", value)
+		}
+	case Int64:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("expected int64, got %T", value)
+		}
+		encoded = binary.LittleEndian.AppendUint64(nil, uint64(v))
+	case Double:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("expected float64, got %T", value)
+		}
+		encoded = binary.LittleEndian.AppendUint64(nil, math.Float64bits(v))
+	case Timestamp:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("expected time.Time, got %T", value)
+		}
+		encoded = binary.LittleEndian.AppendUint64(nil, uint64(v.UnixMilli()))
+	}
+	c.values.Write(encoded)
+	c.defLevels = append(c.defLevels, c.maxDef)
+	return nil
+}
+
+// appendList records one list value: a null list, an empty list, or one
+// level pair per element
+func (c *column) appendList(value interface{}) error {
+	if value == nil {
+		if !c.field.Optional {
+			return fmt.Errorf("nil value in required column")
+		}
+		c.defLevels = append(c.defLevels, 0)
+		c.repLevels = append(c.repLevels, 0)
+		return nil
+	}
+	list, ok := value.([]float32)
+	if !ok {
+		return fmt.Errorf("expected []float32, got %T", value)
+	}
+	if len(list) == 0 {
+		c.defLevels = append(c.defLevels, c.maxDef-1)
+		c.repLevels = append(c.repLevels, 0)
+		return nil
+	}
+	for i, f := range list {
+		rep := 1
+		if i == 0 {
+			rep = 0
+		}
+		c.defLevels = append(c.defLevels, c.maxDef)
+		c.repLevels = append(c.repLevels, rep)
+		c.values.Write(binary.LittleEndian.AppendUint32(nil, math.Float32bits(f)))
+	}
+	return nil
+}
+
+// WriteTo writes the complete file
+func (w *Writer) WriteTo(out io.Writer) (int64, error) {
+	var file bytes.Buffer
+	file.Write(magic)
+
+	chunks := make([][]byte, len(w.columns))
+	var totalSize int64
+	for i, c := range w.columns {
+		offset := int64(file.Len())
+		page := c.page()
+		file.Write(page)
+		chunks[i] = c.chunkMetadata(offset, int64(len(page)))
+		totalSize += int64(len(page))
+	}
+
+	footer := w.fileMetadata(chunks, totalSize)
+	file.Write(footer)
+	file.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	file.Write(magic)
+
+	n, err := out.Write(file.Bytes())
+	return int64(n), err
+}
+
+// page encodes the column as a single data page (v1) with its header
+func (c *column) page() []byte {
+	var body bytes.Buffer
+	if c.maxRep > 0 {
+		writeLevels(&body, c.repLevels, c.maxRep)
+	}
+	if c.maxDef > 0 {
+		writeLevels(&body, c.defLevels, c.maxDef)
+	}
+	body.Write(c.values.Bytes())
+
+	var t thriftWriter
+	t.i32(1, 0) // DATA_PAGE
+	t.i32(2, int32(body.Len()))
+	t.i32(3, int32(body.Len()))
+	t.beginStruct(5)
+	t.i32(1, int32(len(c.defLevels)))
+	t.i32(2, encodingPlain)
+	t.i32(3, encodingRLE)
+	t.i32(4, encodingRLE)
+	t.endStruct()
+	t.stop()
+
+	return append(t.buf.Bytes(), body.Bytes()...)
+}
+
+// writeLevels writes levels with the RLE/bit-packing hybrid encoding (as
+// RLE runs only), prefixed by the encoded length
+func writeLevels(buf *bytes.Buffer, levels []int, maxLevel int) {
+	width := 0
+	for maxLevel>>width > 0 {
+		width++
+	}
+	valueBytes := (width + 7) / 8
+
+	var runs bytes.Buffer
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		writeUvarint(&runs, uint64(j-i)<<1)
+		for b := 0; b < valueBytes; b++ {
+			runs.WriteByte(byte(levels[i] >> (8 * b)))
+		}
+		i = j
+	}
+
+	buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(runs.Len())))
+	buf.Write(runs.Bytes())
+}
+
+func (c *column) physicalType() int32 {
+	switch c.field.Type {
+	case String:
+		return typeByteArray
+	case Int32:
+		return typeInt32
+	case Int64, Timestamp:
+		return typeInt64
+	case Double:
+		return typeDouble
+	default:
+		return typeFloat
+	}
+}
+
+func (c *column) path() []string {
+	if c.field.Type == FloatList {
+		return []string{c.field.Name, "list", "element"}
+	}
+	return []string{c.field.Name}
+}
+
+// chunkMetadata encodes the ColumnChunk struct for the footer
+func (c *column) chunkMetadata(offset, size int64) []byte {
+	var t thriftWriter
+	t.i64(2, offset)
+	t.beginStruct(3)
+	t.i32(1, c.physicalType())
+	t.beginList(2, thriftI32, 2)
+	t.listI32(encodingPlain)
+	t.listI32(encodingRLE)
+	path := c.path()
+	t.beginList(3, thriftBinary, len(path))
+	for _, name := range path {
+		t.listString(name)
+	}
+	t.i32(4, 0) // UNCOMPRESSED
+	t.i64(5, int64(len(c.defLevels)))
+	t.i64(6, size)
+	t.i64(7, size)
+	t.i64(9, offset)
+	t.endStruct()
+	t.stop()
+	return t.buf.Bytes()
+}
+
+// fileMetadata encodes the FileMetaData footer
+func (w *Writer) fileMetadata(chunks [][]byte, totalSize int64) []byte {
+	var t thriftWriter
+	t.i32(1, 1)
+
+	elements := 1
+	for _, c := range w.columns {
+		elements++
+		if c.field.Type == FloatList {
+			elements += 2
+		}
+	}
+	t.beginList(2, thriftStruct, elements)
+	t.listStruct(func() {
+		t.string(4, "schema")
+		t.i32(5, int32(len(w.columns)))
+	})
+	for _, c := range w.columns {
+		repetition := int32(repRequired)
+		if c.field.Optional {
+			repetition = repOptional
+		}
+		if c.field.Type == FloatList {
+			t.listStruct(func() {
+				t.i32(3, repetition)
+				t.string(4, c.field.Name)
+				t.i32(5, 1)
+				t.i32(6, convertedList)
+			})
+			t.listStruct(func() {
+				t.i32(3, repRepeated)
+				t.string(4, "list")
+				t.i32(5, 1)
+			})
+			t.listStruct(func() {
+				t.i32(1, typeFloat)
+				t.i32(3, repRequired)
+				t.string(4, "element")
+			})
+			continue
+		}
+		t.listStruct(func() {
+			t.i32(1, c.physicalType())
+			t.i32(3, repetition)
+			t.string(4, c.field.Name)
+			switch c.field.Type {
+			case String:
+				t.i32(6, convertedUTF8)
+			case Timestamp:
+				t.i32(6, convertedTimestampMillis)
+			}
+		})
+	}
+
+	t.i64(3, int64(w.rows))
+	t.beginList(4, thriftStruct, 1)
+	t.listStruct(func() {
+		t.beginList(1, thriftStruct, len(chunks))
+		for _, chunk := range chunks {
+			t.listRaw(chunk)
+		}
+		t.i64(2, totalSize)
+		t.i64(3, int64(w.rows))
+	})
+	t.string(6, "realtime-caption-translator")
+	t.stop()
+	return t.buf.Bytes()
+}
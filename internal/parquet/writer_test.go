@@ -0,0 +1,270 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestRoundTrip writes a file and reads it back with an independent decoder
+// of the footer, page headers, levels and PLAIN values
+func TestRoundTrip(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	fields := []Field{
+		{Name: "text", Type: String},
+		{Name: "index", Type: Int32, Optional: true},
+		{Name: "offset", Type: Int64},
+		{Name: "score", Type: Double, Optional: true},
+		{Name: "created_at", Type: Timestamp},
+		{Name: "embedding", Type: FloatList, Optional: true},
+	}
+	rows := [][]interface{}{
+		{"hello", 1, int64(10), 0.5, at, []float32{0.25, -1.5, 3}},
+		{"", nil, int64(-20), nil, at.Add(time.Second), nil},
+		{"naïve café", int32(3), int64(1) << 40, 2.75, at.Add(time.Hour), []float32{}},
+	}
+
+	w := NewWriter(fields)
+	for _, row := range rows {
+		if err := w.Append(row...); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	data := buf.Bytes()
+
+	if !bytes.HasPrefix(data, magic) || !bytes.HasSuffix(data, magic) {
+		t.Fatalf("missing PAR1 magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerStart := len(data) - 8 - footerLen
+	meta, n := readThriftStruct(t, data[footerStart:])
+	if n != footerLen {
+		t.Fatalf("footer decoded %d bytes, length says %d", n, footerLen)
+	}
+
+	if got := meta[3].(int64); got != int64(len(rows)) {
+		t.Fatalf("num_rows = %d, want %d", got, len(rows))
+	}
+	var names []string
+	for _, element := range meta[2].([]interface{}) {
+		names = append(names, string(element.(decodedStruct)[4].([]byte)))
+	}
+	wantNames := []string{"schema", "text", "index", "offset", "score", "created_at", "embedding", "list", "element"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("schema = %v, want %v", names, wantNames)
+	}
+
+	rowGroup := meta[4].([]interface{})[0].(decodedStruct)
+	chunks := rowGroup[1].([]interface{})
+	if len(chunks) != len(fields) {
+		t.Fatalf("got %d column chunks, want %d", len(chunks), len(fields))
+	}
+
+	for i, field := range fields {
+		chunkMeta := chunks[i].(decodedStruct)[3].(decodedStruct)
+		offset := int(chunkMeta[9].(int64))
+		header, headerLen := readThriftStruct(t, data[offset:])
+		size := int(header[3].(int32))
+		body := data[offset+headerLen : offset+headerLen+size]
+		numValues := int(header[5].(decodedStruct)[1].(int32))
+
+		got := decodeColumn(t, field, body, numValues)
+		for r, row := range rows {
+			if want := normalize(row[i]); !reflect.DeepEqual(got[r], want) {
+				t.Errorf("%s row %d = %#v, want %#v", field.Name, r, got[r], want)
+			}
+		}
+	}
+}
+
+// normalize converts appended values to what decodeColumn returns
+func normalize(v interface{}) interface{} {
+	switch x := v.(type) {
+	case int:
+		return int32(x)
+	case time.Time:
+		return x.UnixMilli()
+	}
+	return v
+}
+
+// decodeColumn splits a data page body into one value per row
+func decodeColumn(t *testing.T, field Field, body []byte, numValues int) []interface{} {
+	maxDef, maxRep := 0, 0
+	if field.Optional {
+		maxDef = 1
+	}
+	if field.Type == FloatList {
+		maxDef++
+		maxRep = 1
+	}
+
+	var repLevels, defLevels []int
+	if maxRep > 0 {
+		repLevels, body = readLevels(t, body, maxRep, numValues)
+	}
+	if maxDef > 0 {
+		defLevels, body = readLevels(t, body, maxDef, numValues)
+	} else {
+		defLevels = make([]int, numValues)
+	}
+
+	var rows []interface{}
+	for i := 0; i < numValues; i++ {
+		if defLevels[i] < maxDef {
+			if field.Type == FloatList && defLevels[i] == maxDef-1 {
+				rows = append(rows, []float32{}) // empty list
+			} else {
+				rows = append(rows, nil)
+			}
+			continue
+		}
+		var value interface{}
+		switch field.Type {
+		case String:
+			n := int(binary.LittleEndian.Uint32(body))
+			value, body = string(body[4:4+n]), body[4+n:]
+		case Int32:
+			value, body = int32(binary.LittleEndian.Uint32(body)), body[4:]
+		case Int64, Timestamp:
+			value, body = int64(binary.LittleEndian.Uint64(body)), body[8:]
+		case Double:
+			value, body = math.Float64frombits(binary.LittleEndian.Uint64(body)), body[8:]
+		case FloatList:
+			f := math.Float32frombits(binary.LittleEndian.Uint32(body))
+			body = body[4:]
+			if repLevels[i] == 1 {
+				last := rows[len(rows)-1].([]float32)
+				rows[len(rows)-1] = append(last, f)
+				continue
+			}
+			value = []float32{f}
+		}
+		rows = append(rows, value)
+	}
+	if len(body) != 0 {
+		t.Errorf("%s: %d trailing bytes after values", field.Name, len(body))
+	}
+	return rows
+}
+
+// readLevels decodes length-prefixed RLE/bit-packed hybrid levels
+func readLevels(t *testing.T, body []byte, maxLevel, count int) ([]int, []byte) {
+	width := 0
+	for maxLevel>>width > 0 {
+		width++
+	}
+	n := int(binary.LittleEndian.Uint32(body))
+	runs, rest := body[4:4+n], body[4+n:]
+
+	var levels []int
+	for len(runs) > 0 {
+		header, k := binary.Uvarint(runs)
+		runs = runs[k:]
+		if header&1 == 1 {
+			t.Fatalf("unexpected bit-packed run")
+		}
+		value := 0
+		for b := 0; b < (width+7)/8; b++ {
+			value |= int(runs[b]) << (8 * b)
+		}
+		runs = runs[(width+7)/8:]
+		for i := 0; i < int(header>>1); i++ {
+			levels = append(levels, value)
+		}
+	}
+	if len(levels) != count {
+		t.Fatalf("decoded %d levels, want %d", len(levels), count)
+	}
+	return levels, rest
+}
+
+// decodedStruct is a decoded Thrift struct keyed by field ID
+type decodedStruct map[int16]interface{}
+
+// readThriftStruct decodes a compact protocol struct and returns it with the
+// number of bytes consumed
+func readThriftStruct(t *testing.T, data []byte) (decodedStruct, int) {
+	r := &thriftReader{t: t, data: data}
+	s := r.structValue()
+	return s, r.pos
+}
+
+type thriftReader struct {
+	t    *testing.T
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.t.Fatalf("bad varint at %d", r.pos)
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) structValue() decodedStruct {
+	s := decodedStruct{}
+	var last int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return s
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.varint())
+		}
+		s[id] = r.value(header & 0x0F)
+		last = id
+	}
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case thriftI32:
+		return int32(r.varint())
+	case thriftI64:
+		return r.varint()
+	case thriftBinary:
+		n := int(r.uvarint())
+		b := r.data[r.pos : r.pos+n]
+		r.pos += n
+		return b
+	case thriftList:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0F)
+		}
+		return list
+	case thriftStruct:
+		return r.structValue()
+	}
+	r.t.Fatalf("unsupported thrift type %d at %d", typ, r.pos)
+	return nil
+}
@@ -0,0 +1,109 @@
+// Package profanity removes offensive words from text before it is spoken.
+// Matching is by whole word and case-insensitive; the lists cover English
+// only, so other languages pass through unchanged.
+package profanity
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Filter levels
+const (
+	LevelOff      = "off"
+	LevelStandard = "standard" // strong profanity and slurs
+	LevelKidSafe  = "kid_safe" // also mild swearing and crude words
+)
+
+// strongWords are removed at every level other than off
+var strongWords = words(`
+	fuck fucks fucked fucker fuckers fucking motherfucker motherfuckers motherfucking
+	shit shits shitty shitting bullshit
+	cunt cunts
+	bitch bitches bitching
+	asshole assholes
+	bastard bastards
+	dick dicks dickhead
+	cock cocks cocksucker
+	pussy
+	whore whores slut sluts
+	wanker wankers twat twats
+	nigger niggers nigga faggot faggots fag fags retard retards
+`)
+
+// mildWords are also removed for kid-safe voices
+var mildWords = words(`
+	damn damned dammit goddamn goddamned
+	hell
+	crap crappy
+	ass asses arse
+	piss pissed
+	bloody bugger
+	sucks
+	boobs tits
+	sexy sex
+	stupid idiot idiots moron morons
+`)
+
+func words(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(list) {
+		set[word] = true
+	}
+	return set
+}
+
+// Valid reports whether level is a known filter level
+func Valid(level string) bool {
+	return level == LevelOff || level == LevelStandard || level == LevelKidSafe
+}
+
+// Blocked reports whether a word (in any case) is blocked at level
+func Blocked(word, level string) bool {
+	if level != LevelStandard && level != LevelKidSafe {
+		return false
+	}
+	lower := strings.ToLower(word)
+	return strongWords[lower] || (level == LevelKidSafe && mildWords[lower])
+}
+
+// Filter drops the words blocked at level and tidies the spacing left
+// behind. Unknown levels and LevelOff return text unchanged.
+func Filter(text, level string) string {
+	if level != LevelStandard && level != LevelKidSafe {
+		return text
+	}
+
+	var builder strings.Builder
+	removed := false
+	start := -1
+	flush := func(end int) {
+		word := text[start:end]
+		if Blocked(word, level) {
+			removed = true
+		} else {
+			builder.WriteString(word)
+		}
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || r == '\'' {
+			if start == -1 {
+				start = i
+			}
+			continue
+		}
+		if start != -1 {
+			flush(i)
+		}
+		builder.WriteRune(r)
+	}
+	if start != -1 {
+		flush(len(text))
+	}
+
+	if !removed {
+		return text
+	}
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
@@ -0,0 +1,143 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Child is one session whose progress rolls up into a parent session
+type Child struct {
+	SessionID string
+	Name      string // shown in the parent's messages, e.g. the filename
+}
+
+// aggregator turns child updates into parent updates: progress is the mean
+// over the children, and the parent completes once every child has
+// completed or failed
+type aggregator struct {
+	manager  *Manager
+	parentID string
+	children []Child
+
+	mu       sync.Mutex
+	progress []float64
+	done     []bool
+	failed   int
+	finished bool
+}
+
+// childSubscriber feeds one child's updates to its aggregator
+type childSubscriber struct {
+	agg   *aggregator
+	index int
+}
+
+func (s *childSubscriber) Send(data []byte) error {
+	var update Update
+	if err := json.Unmarshal(data, &update); err != nil {
+		return err
+	}
+	s.agg.observe(s.index, update)
+	return nil
+}
+
+// Aggregate reports the progress of child sessions on a parent session.
+// Subscribe before the children start so no update is missed. Each parent
+// update carries the child it came from in Results["file"]; the final
+// update has stage "complete" with completed and failed counts.
+func (m *Manager) Aggregate(parentID string, children []Child) {
+	agg := &aggregator{
+		manager:  m,
+		parentID: parentID,
+		children: children,
+		progress: make([]float64, len(children)),
+		done:     make([]bool, len(children)),
+	}
+	for i, child := range children {
+		m.add(child.SessionID, &childSubscriber{agg: agg, index: i})
+	}
+}
+
+func (a *aggregator) observe(index int, update Update) {
+	a.mu.Lock()
+	if a.finished || a.done[index] {
+		a.mu.Unlock()
+		return
+	}
+	failed := update.Error != ""
+	switch {
+	case failed:
+		a.done[index] = true
+		a.progress[index] = 100
+		a.failed++
+	case update.Stage == "complete":
+		a.done[index] = true
+		a.progress[index] = 100
+	case update.Progress > a.progress[index]:
+		a.progress[index] = update.Progress
+	}
+
+	var total float64
+	remaining := 0
+	for i, p := range a.progress {
+		total += p
+		if !a.done[i] {
+			remaining++
+		}
+	}
+	count := len(a.children)
+	overall := total / float64(count)
+	a.finished = remaining == 0
+	failedCount := a.failed
+	a.mu.Unlock()
+
+	child := a.children[index]
+	file := map[string]interface{}{
+		"index":     index + 1, // same numbering as the batch manifest
+		"sessionId": child.SessionID,
+		"name":      child.Name,
+		"stage":     update.Stage,
+		"progress":  update.Progress,
+	}
+	if failed {
+		file["error"] = update.Error
+	}
+	if update.Stage == "complete" {
+		file["results"] = update.Results
+	}
+
+	stage := "processing"
+	if failed {
+		stage = "file_failed"
+	} else if update.Stage == "complete" {
+		stage = "file_complete"
+	}
+	a.manager.SendUpdate(Update{
+		SessionID: a.parentID,
+		Stage:     stage,
+		Progress:  overall,
+		Message:   fmt.Sprintf("[%d/%d] %s: %s", index+1, count, child.Name, update.Message),
+		Results:   map[string]interface{}{"file": file},
+	})
+
+	if a.finished {
+		a.manager.SendUpdate(Update{
+			SessionID: a.parentID,
+			Stage:     "complete",
+			Progress:  100,
+			Message:   fmt.Sprintf("Batch finished: %d of %d files processed", count-failedCount, count),
+			Results: map[string]interface{}{
+				"total":     count,
+				"completed": count - failedCount,
+				"failed":    failedCount,
+			},
+		})
+		for i, c := range a.children {
+			a.manager.remove(c.SessionID, func(s Subscriber) bool {
+				cs, ok := s.(*childSubscriber)
+				return ok && cs.agg == a && cs.index == i
+			})
+		}
+	}
+}
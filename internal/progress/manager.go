@@ -2,10 +2,13 @@ package progress
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"sync"
 
 	"github.com/gorilla/websocket"
+
+	"realtime-caption-translator/internal/i18n"
 )
 
 // Update represents a progress update message
@@ -21,43 +24,125 @@ type Update struct {
 // Tracker tracks progress for a single upload session
 type Tracker struct {
 	SessionID string
+	Language  string // messages are localized to this language; "" keeps English
 	manager   *Manager
 }
 
+// Subscriber receives the JSON-encoded progress updates of a session. A Send
+// error removes the subscriber.
+type Subscriber interface {
+	Send(data []byte) error
+}
+
 // Manager manages progress tracking for multiple upload sessions
 type Manager struct {
 	mu          sync.RWMutex
-	subscribers map[string][]*websocket.Conn
+	subscribers map[string][]Subscriber
 }
 
 // NewManager creates a new progress manager
 func NewManager() *Manager {
 	return &Manager{
-		subscribers: make(map[string][]*websocket.Conn),
+		subscribers: make(map[string][]Subscriber),
+	}
+}
+
+// wsSubscriber writes updates to a WebSocket connection
+type wsSubscriber struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (s *wsSubscriber) Send(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// ChannelSubscriber buffers updates on a channel, for transports such as
+// server-sent events that write from their own goroutine
+type ChannelSubscriber struct {
+	mu     sync.Mutex
+	ch     chan []byte
+	closed bool
+}
+
+var errSubscriberClosed = errors.New("progress subscriber closed")
+
+// Updates returns the channel of JSON-encoded updates. It is closed when the
+// subscriber is removed or falls more than its buffer behind.
+func (s *ChannelSubscriber) Updates() <-chan []byte {
+	return s.ch
+}
+
+// Send queues an update without blocking; a full buffer closes the subscriber
+// so a stalled client cannot hold up the upload it is watching
+func (s *ChannelSubscriber) Send(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errSubscriberClosed
+	}
+	select {
+	case s.ch <- data:
+		return nil
+	default:
+		s.closed = true
+		close(s.ch)
+		return errors.New("progress subscriber too slow")
+	}
+}
+
+func (s *ChannelSubscriber) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
 	}
 }
 
 // Subscribe adds a WebSocket connection to receive progress updates for a session
 func (m *Manager) Subscribe(sessionID string, conn *websocket.Conn) {
+	m.add(sessionID, &wsSubscriber{conn: conn})
+}
+
+// Unsubscribe removes a WebSocket connection from receiving updates
+func (m *Manager) Unsubscribe(sessionID string, conn *websocket.Conn) {
+	m.remove(sessionID, func(sub Subscriber) bool {
+		ws, ok := sub.(*wsSubscriber)
+		return ok && ws.conn == conn
+	})
+}
+
+// SubscribeChannel adds a channel subscriber holding up to buffer pending
+// updates. The returned function removes it and closes its channel.
+func (m *Manager) SubscribeChannel(sessionID string, buffer int) (*ChannelSubscriber, func()) {
+	sub := &ChannelSubscriber{ch: make(chan []byte, buffer)}
+	m.add(sessionID, sub)
+	return sub, func() {
+		m.remove(sessionID, func(s Subscriber) bool { return s == sub })
+		sub.close()
+	}
+}
+
+func (m *Manager) add(sessionID string, sub Subscriber) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if m.subscribers[sessionID] == nil {
-		m.subscribers[sessionID] = make([]*websocket.Conn, 0)
-	}
-	m.subscribers[sessionID] = append(m.subscribers[sessionID], conn)
+	m.subscribers[sessionID] = append(m.subscribers[sessionID], sub)
 	log.Printf("Progress subscriber added for session %s (total: %d)", sessionID, len(m.subscribers[sessionID]))
 }
 
-// Unsubscribe removes a WebSocket connection from receiving updates
-func (m *Manager) Unsubscribe(sessionID string, conn *websocket.Conn) {
+// remove drops the first subscriber of a session that matches
+func (m *Manager) remove(sessionID string, match func(Subscriber) bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	subscribers := m.subscribers[sessionID]
 	for i, sub := range subscribers {
-		if sub == conn {
-			m.subscribers[sessionID] = append(subscribers[:i], subscribers[i+1:]...)
+		if match(sub) {
+			m.subscribers[sessionID] = append(subscribers[:i:i], subscribers[i+1:]...)
 			log.Printf("Progress subscriber removed for session %s", sessionID)
 			break
 		}
@@ -71,11 +156,13 @@ func (m *Manager) Unsubscribe(sessionID string, conn *websocket.Conn) {
 
 // SendUpdate sends a progress update to all subscribers of a session
 func (m *Manager) SendUpdate(update Update) {
+	// Copy the subscribers so sends happen without holding the lock
 	m.mu.RLock()
-	subscribers := m.subscribers[update.SessionID]
+	subs := make([]Subscriber, len(m.subscribers[update.SessionID]))
+	copy(subs, m.subscribers[update.SessionID])
 	m.mu.RUnlock()
 
-	if len(subscribers) == 0 {
+	if len(subs) == 0 {
 		return
 	}
 
@@ -85,17 +172,11 @@ func (m *Manager) SendUpdate(update Update) {
 		return
 	}
 
-	// Send to all subscribers (create copy to avoid holding lock)
-	m.mu.RLock()
-	subs := make([]*websocket.Conn, len(subscribers))
-	copy(subs, subscribers)
-	m.mu.RUnlock()
-
-	for _, conn := range subs {
-		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+	for _, sub := range subs {
+		if err := sub.Send(data); err != nil {
 			log.Printf("Error sending progress update: %v", err)
-			// Remove failed connection
-			m.Unsubscribe(update.SessionID, conn)
+			// Remove failed subscriber
+			m.remove(update.SessionID, func(s Subscriber) bool { return s == sub })
 		}
 	}
 }
@@ -114,10 +195,26 @@ func (t *Tracker) Update(stage string, progress float64, message string) {
 		SessionID: t.SessionID,
 		Stage:     stage,
 		Progress:  progress,
-		Message:   message,
+		Message:   t.T(message),
 	})
 }
 
+// Updatef sends a progress update with a formatted message; the format is
+// localized before the arguments are applied
+func (t *Tracker) Updatef(stage string, progress float64, format string, args ...interface{}) {
+	t.manager.SendUpdate(Update{
+		SessionID: t.SessionID,
+		Stage:     stage,
+		Progress:  progress,
+		Message:   i18n.Sprintf(t.Language, format, args...),
+	})
+}
+
+// T localizes a message to the tracker's language
+func (t *Tracker) T(message string) string {
+	return i18n.T(t.Language, message)
+}
+
 // Error sends an error update
 func (t *Tracker) Error(stage string, message string, err error) {
 	errMsg := ""
@@ -128,7 +225,7 @@ func (t *Tracker) Error(stage string, message string, err error) {
 		SessionID: t.SessionID,
 		Stage:     stage,
 		Progress:  0,
-		Message:   message,
+		Message:   t.T(message),
 		Error:     errMsg,
 	})
 }
@@ -139,7 +236,7 @@ func (t *Tracker) Complete(message string) {
 		SessionID: t.SessionID,
 		Stage:     "complete",
 		Progress:  100,
-		Message:   message,
+		Message:   t.T(message),
 	})
 }
 
@@ -149,7 +246,7 @@ func (t *Tracker) CompleteWithResults(message string, results map[string]interfa
 		SessionID: t.SessionID,
 		Stage:     "complete",
 		Progress:  100,
-		Message:   message,
+		Message:   t.T(message),
 		Results:   results,
 	})
 }
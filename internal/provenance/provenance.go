@@ -0,0 +1,79 @@
+package provenance
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Generator identifies this system in provenance tags
+const Generator = "realtime-caption-translator"
+
+// Container metadata keys written into generated media. MP4 keeps custom
+// keys only when ffmpeg is run with -movflags use_metadata_tags.
+const (
+	TagComment     = "comment"
+	TagAIGenerated = "ai_generated"
+	TagGenerator   = "provenance_generator"
+	TagSessionID   = "provenance_session_id"
+	TagSourceLang  = "provenance_source_lang"
+	TagTargetLang  = "provenance_target_lang"
+	TagVoice       = "provenance_voice"
+	TagCreatedAt   = "provenance_created_at"
+)
+
+// Voice describes how the synthetic speech was produced
+const (
+	VoiceStandard = "standard"
+	VoiceCloned   = "cloned"
+)
+
+// Info identifies a piece of machine-generated audio
+type Info struct {
+	SessionID  string    `json:"sessionId"`
+	SourceLang string    `json:"sourceLang,omitempty"`
+	TargetLang string    `json:"targetLang,omitempty"`
+	Voice      string    `json:"voice,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Tags returns the metadata tags that label media as machine-generated
+func (i Info) Tags() map[string]string {
+	tags := map[string]string{
+		TagComment:     fmt.Sprintf("Machine-generated translated speech (session %s)", i.SessionID),
+		TagAIGenerated: "true",
+		TagGenerator:   Generator,
+		TagSessionID:   i.SessionID,
+		TagCreatedAt:   i.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if i.SourceLang != "" {
+		tags[TagSourceLang] = i.SourceLang
+	}
+	if i.TargetLang != "" {
+		tags[TagTargetLang] = i.TargetLang
+	}
+	if i.Voice != "" {
+		tags[TagVoice] = i.Voice
+	}
+	return tags
+}
+
+// Parse reads provenance back from container tags. ok is false when the
+// media carries no machine-generated label.
+func Parse(tags map[string]string) (info Info, ok bool) {
+	generated, _ := strconv.ParseBool(tags[TagAIGenerated])
+	if !generated || tags[TagSessionID] == "" {
+		return Info{}, false
+	}
+
+	info = Info{
+		SessionID:  tags[TagSessionID],
+		SourceLang: tags[TagSourceLang],
+		TargetLang: tags[TagTargetLang],
+		Voice:      tags[TagVoice],
+	}
+	if createdAt, err := time.Parse(time.RFC3339, tags[TagCreatedAt]); err == nil {
+		info.CreatedAt = createdAt
+	}
+	return info, true
+}
@@ -0,0 +1,332 @@
+// Package quota enforces per-user limits on minutes of audio processed per
+// UTC day, concurrent processing jobs and stored bytes. Server-wide defaults
+// apply unless a user has overrides in user_quotas; a limit of 0 means
+// unlimited. Lookups fail open: when the database cannot be read, requests
+// are let through.
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// Resources a quota limits
+const (
+	AudioMinutes   = "audio_minutes"
+	ConcurrentJobs = "concurrent_jobs"
+	Storage        = "storage"
+)
+
+// Limits are a user's quotas. 0 means unlimited.
+type Limits struct {
+	AudioMinutesPerDay int   `json:"audioMinutesPerDay"`
+	ConcurrentJobs     int   `json:"concurrentJobs"`
+	StorageBytes       int64 `json:"storageBytes"`
+}
+
+var (
+	mu       sync.RWMutex
+	defaults Limits
+)
+
+// SetDefaults sets the limits of users without overrides
+func SetDefaults(limits Limits) {
+	mu.Lock()
+	defaults = limits
+	mu.Unlock()
+}
+
+// Defaults returns the limits of users without overrides
+func Defaults() Limits {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaults
+}
+
+// Request is what an operation is about to use
+type Request struct {
+	AudioSeconds float64 // audio it will process
+	Jobs         int     // processing jobs it will queue
+	StorageBytes int64   // bytes it will store
+}
+
+// Status is a user's limits and what counts against them
+type Status struct {
+	Limits  Limits                  `json:"limits"`
+	Usage   database.UserQuotaUsage `json:"usage"`
+	ResetAt time.Time               `json:"resetAt"` // when the daily audio allowance starts over
+}
+
+// Remaining is what a user has left; nil fields are unlimited
+type Remaining struct {
+	AudioMinutes   *float64 `json:"audioMinutes,omitempty"`
+	ConcurrentJobs *int     `json:"concurrentJobs,omitempty"`
+	StorageBytes   *int64   `json:"storageBytes,omitempty"`
+}
+
+// Remaining returns what the user has left of each limited resource
+func (s *Status) Remaining() Remaining {
+	var remaining Remaining
+	if s.Limits.AudioMinutesPerDay > 0 {
+		minutes := math.Max(float64(s.Limits.AudioMinutesPerDay)-s.Usage.AudioSecondsToday/60, 0)
+		remaining.AudioMinutes = &minutes
+	}
+	if s.Limits.ConcurrentJobs > 0 {
+		jobs := max(s.Limits.ConcurrentJobs-s.Usage.ActiveJobs, 0)
+		remaining.ConcurrentJobs = &jobs
+	}
+	if s.Limits.StorageBytes > 0 {
+		bytes := max(s.Limits.StorageBytes-s.Usage.StorageBytes, 0)
+		remaining.StorageBytes = &bytes
+	}
+	return remaining
+}
+
+// SetHeaders reports the remaining allowance of each limited resource
+func (s *Status) SetHeaders(h http.Header) {
+	remaining := s.Remaining()
+	if remaining.AudioMinutes != nil {
+		h.Set("X-Quota-Audio-Minutes-Limit", strconv.Itoa(s.Limits.AudioMinutesPerDay))
+		h.Set("X-Quota-Audio-Minutes-Remaining", strconv.FormatFloat(*remaining.AudioMinutes, 'f', 1, 64))
+		h.Set("X-Quota-Audio-Minutes-Reset", s.ResetAt.Format(time.RFC3339))
+	}
+	if remaining.ConcurrentJobs != nil {
+		h.Set("X-Quota-Concurrent-Jobs-Limit", strconv.Itoa(s.Limits.ConcurrentJobs))
+		h.Set("X-Quota-Concurrent-Jobs-Remaining", strconv.Itoa(*remaining.ConcurrentJobs))
+	}
+	if remaining.StorageBytes != nil {
+		h.Set("X-Quota-Storage-Bytes-Limit", strconv.FormatInt(s.Limits.StorageBytes, 10))
+		h.Set("X-Quota-Storage-Bytes-Remaining", strconv.FormatInt(*remaining.StorageBytes, 10))
+	}
+}
+
+// ExceededError reports a request that would go over a quota
+type ExceededError struct {
+	Resource  string     `json:"resource"`
+	Limit     float64    `json:"limit"`
+	Used      float64    `json:"used"`
+	Requested float64    `json:"requested"`
+	Remaining float64    `json:"remaining"`
+	ResetAt   *time.Time `json:"resetAt,omitempty"`
+}
+
+func (e *ExceededError) Error() string {
+	switch e.Resource {
+	case AudioMinutes:
+		return fmt.Sprintf("daily audio quota of %g minutes exceeded", e.Limit)
+	case ConcurrentJobs:
+		return fmt.Sprintf("limit of %g concurrent jobs reached", e.Limit)
+	default:
+		return fmt.Sprintf("storage quota of %g bytes exceeded", e.Limit)
+	}
+}
+
+// StatusCode is 429 for quotas that free up with time (the daily audio
+// allowance, running jobs) and 403 for storage, which only frees up when
+// the user deletes files
+func (e *ExceededError) StatusCode() int {
+	if e.Resource == Storage {
+		return http.StatusForbidden
+	}
+	return http.StatusTooManyRequests
+}
+
+// RetryAfter is how long until the daily audio allowance starts over, or 0
+// when retrying later is not bound to a time
+func (e *ExceededError) RetryAfter() time.Duration {
+	if e.ResetAt == nil {
+		return 0
+	}
+	return time.Until(*e.ResetAt)
+}
+
+// Load returns a user's limits and usage
+func Load(userID int) (*Status, error) {
+	limits := Defaults()
+	override, err := database.GetUserQuota(userID)
+	if err != nil {
+		return nil, err
+	}
+	if override != nil {
+		if override.AudioMinutesPerDay != nil {
+			limits.AudioMinutesPerDay = *override.AudioMinutesPerDay
+		}
+		if override.ConcurrentJobs != nil {
+			limits.ConcurrentJobs = *override.ConcurrentJobs
+		}
+		if override.StorageBytes != nil {
+			limits.StorageBytes = *override.StorageBytes
+		}
+	}
+
+	status := &Status{Limits: limits, ResetAt: nextReset(time.Now())}
+	if limits == (Limits{}) {
+		// Nothing is limited, so usage does not matter
+		return status, nil
+	}
+	usage, err := database.GetUserQuotaUsage(userID)
+	if err != nil {
+		return nil, err
+	}
+	status.Usage = *usage
+	return status, nil
+}
+
+// Check loads a user's quotas and returns an *ExceededError when req would
+// go over one. A request for no audio or storage fails once the allowance
+// is used up. The status is returned with the error, for headers.
+func Check(userID int, req Request) (*Status, error) {
+	if database.DB == nil {
+		return nil, nil
+	}
+	status, err := Load(userID)
+	if err != nil {
+		return nil, err
+	}
+	limits, usage := status.Limits, status.Usage
+
+	if exceeded := checkAudio(status, usage.AudioSecondsToday, req.AudioSeconds); exceeded != nil {
+		return status, exceeded
+	}
+	if limits.ConcurrentJobs > 0 && req.Jobs > 0 && usage.ActiveJobs+req.Jobs > limits.ConcurrentJobs {
+		return status, JobsExceeded(limits.ConcurrentJobs, usage.ActiveJobs, req.Jobs)
+	}
+	if limits.StorageBytes > 0 && (usage.StorageBytes >= limits.StorageBytes || usage.StorageBytes+req.StorageBytes > limits.StorageBytes) {
+		return status, &ExceededError{Resource: Storage, Limit: float64(limits.StorageBytes),
+			Used: float64(usage.StorageBytes), Requested: float64(req.StorageBytes),
+			Remaining: float64(max(limits.StorageBytes-usage.StorageBytes, 0))}
+	}
+	return status, nil
+}
+
+// JobsExceeded describes requested jobs refused because the user already
+// has active of their limit of concurrent jobs
+func JobsExceeded(limit, active, requested int) *ExceededError {
+	return &ExceededError{Resource: ConcurrentJobs, Limit: float64(limit),
+		Used: float64(active), Requested: float64(requested),
+		Remaining: float64(max(limit-active, 0))}
+}
+
+// checkAudio returns an *ExceededError when seconds more audio would go
+// over the daily allowance, with usedSeconds already used today
+func checkAudio(status *Status, usedSeconds, seconds float64) *ExceededError {
+	if status.Limits.AudioMinutesPerDay <= 0 {
+		return nil
+	}
+	limit := float64(status.Limits.AudioMinutesPerDay)
+	used := usedSeconds / 60
+	requested := seconds / 60
+	if used < limit && used+requested <= limit {
+		return nil
+	}
+	resetAt := status.ResetAt
+	return &ExceededError{Resource: AudioMinutes, Limit: limit, Used: used,
+		Requested: requested, Remaining: math.Max(limit-used, 0), ResetAt: &resetAt}
+}
+
+// Exceeded returns the *ExceededError in err's chain, if any
+func Exceeded(err error) (*ExceededError, bool) {
+	var exceeded *ExceededError
+	ok := errors.As(err, &exceeded)
+	return exceeded, ok
+}
+
+// RecordAudio counts seconds of processed audio against the user's day
+func RecordAudio(userID int, seconds float64) {
+	if seconds <= 0 || database.DB == nil {
+		return
+	}
+	if err := database.AddUserAudioUsage(userID, seconds); err != nil {
+		log.Printf("[Quota] Failed to record %.1fs of audio for user %d: %v", seconds, userID, err)
+	}
+}
+
+// meterRefresh bounds how stale a Meter's limits and usage may get, and how
+// long charged audio waits before it is recorded
+const meterRefresh = 30 * time.Second
+
+// Meter charges a stream of audio, such as a meeting participant's speech
+// or a recording, against a user's daily allowance chunk by chunk. Limits
+// and usage are loaded once and reloaded every meterRefresh or when the day
+// starts over, and charged seconds are recorded in batches, so a chunk does
+// not cost a database round trip. A nil Meter charges nothing.
+type Meter struct {
+	userID int
+
+	mu       sync.Mutex
+	status   *Status // nil until loaded, or while loading fails
+	loadedAt time.Time
+	pending  float64 // seconds charged since the last record
+}
+
+// NewMeter returns a meter charging the user's daily allowance
+func NewMeter(userID int) *Meter {
+	return &Meter{userID: userID}
+}
+
+// Charge counts seconds of audio against the allowance, or returns an
+// *ExceededError without counting them once the allowance is used up.
+// Lookup failures let the audio through.
+func (m *Meter) Charge(seconds float64) error {
+	if m == nil || database.DB == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if m.status == nil || now.Sub(m.loadedAt) >= meterRefresh || !now.Before(m.status.ResetAt) {
+		// Record first so the reloaded usage includes what was charged
+		m.flush()
+		status, err := Load(m.userID)
+		if err != nil {
+			log.Printf("[Quota] Failed to load quota for user %d: %v", m.userID, err)
+		}
+		m.status, m.loadedAt = status, now
+	}
+
+	if m.status != nil {
+		if exceeded := checkAudio(m.status, m.status.Usage.AudioSecondsToday+m.pending, seconds); exceeded != nil {
+			return exceeded
+		}
+	}
+	m.pending += seconds
+	return nil
+}
+
+// Flush records the audio charged since the last record; call it when the
+// stream ends
+func (m *Meter) Flush() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.flush()
+	m.mu.Unlock()
+}
+
+// flush records pending seconds; callers hold m.mu
+func (m *Meter) flush() {
+	if m.pending <= 0 {
+		return
+	}
+	RecordAudio(m.userID, m.pending)
+	if m.status != nil {
+		m.status.Usage.AudioSecondsToday += m.pending
+	}
+	m.pending = 0
+}
+
+// nextReset returns the next UTC midnight after now
+func nextReset(now time.Time) time.Time {
+	year, month, day := now.UTC().Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+}
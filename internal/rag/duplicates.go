@@ -0,0 +1,135 @@
+package rag
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// Duplicate detection samples chunks of an uploaded transcript, embeds them
+// and looks up the nearest meeting chunks. A meeting is reported when enough
+// samples have a close match in it.
+const (
+	maxDuplicateSamples      = 12
+	duplicateNeighbours      = 8
+	DuplicateMatchSimilarity = 0.80 // a sample "matches" a meeting at this cosine similarity
+	DuplicateMinOverlap      = 0.5  // fraction of samples that must match
+)
+
+// DuplicateMeeting is a meeting whose transcript overlaps an uploaded source
+type DuplicateMeeting struct {
+	MeetingID      string     `json:"meetingId"`
+	RoomCode       string     `json:"roomCode,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	EndedAt        *time.Time `json:"endedAt,omitempty"`
+	Similarity     float64    `json:"similarity"` // mean best similarity of the matching samples
+	Overlap        float64    `json:"overlap"`    // fraction of sampled chunks that matched
+	MatchedSamples int        `json:"matchedSamples"`
+	Samples        int        `json:"samples"`
+}
+
+// FindDuplicateMeetings compares a source transcript with the meetings the
+// user can access and returns likely duplicates, best match first
+func (p *Processor) FindDuplicateMeetings(userID int, sourceID, language, transcript string) ([]DuplicateMeeting, error) {
+	chunks, err := p.chunkTranscript(sourceID, language, splitSentences(transcript))
+	if err != nil {
+		return nil, fmt.Errorf("failed to chunk transcript: %w", err)
+	}
+	if len(chunks) == 0 {
+		return []DuplicateMeeting{}, nil
+	}
+
+	samples := sampleChunks(chunks, maxDuplicateSamples)
+	texts := make([]string, len(samples))
+	for i, chunk := range samples {
+		texts[i] = chunk.ChunkText
+	}
+	embeddings, err := p.EmbeddingClient.EmbedBatch(texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed transcript samples: %w", err)
+	}
+	if len(embeddings) != len(samples) {
+		return nil, fmt.Errorf("embedding service returned %d embeddings for %d samples", len(embeddings), len(samples))
+	}
+
+	type score struct {
+		matched int
+		sum     float64
+	}
+	scores := make(map[string]*score)
+	for _, embeddingVec := range embeddings {
+		neighbours, err := database.SearchSimilarMeetingChunks(userID, language, embeddingVec, duplicateNeighbours)
+		if err != nil {
+			return nil, err
+		}
+
+		// Neighbours are ordered by similarity, so the first chunk seen for a
+		// meeting is its best match for this sample
+		best := make(map[string]float64)
+		for _, neighbour := range neighbours {
+			if _, seen := best[neighbour.MeetingID]; !seen {
+				best[neighbour.MeetingID] = neighbour.Similarity
+			}
+		}
+		for meetingID, similarity := range best {
+			if similarity < DuplicateMatchSimilarity {
+				continue
+			}
+			s, ok := scores[meetingID]
+			if !ok {
+				s = &score{}
+				scores[meetingID] = s
+			}
+			s.matched++
+			s.sum += similarity
+		}
+	}
+
+	duplicates := []DuplicateMeeting{}
+	for meetingID, s := range scores {
+		overlap := float64(s.matched) / float64(len(samples))
+		if overlap < DuplicateMinOverlap {
+			continue
+		}
+		duplicate := DuplicateMeeting{
+			MeetingID:      meetingID,
+			Similarity:     s.sum / float64(s.matched),
+			Overlap:        overlap,
+			MatchedSamples: s.matched,
+			Samples:        len(samples),
+		}
+		if meeting, err := database.GetMeetingByID(meetingID); err != nil {
+			log.Printf("[RAG] Failed to load meeting %s for duplicate check: %v", meetingID, err)
+		} else if meeting != nil {
+			duplicate.RoomCode = meeting.RoomCode
+			duplicate.CreatedAt = meeting.CreatedAt
+			duplicate.EndedAt = meeting.EndedAt
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Overlap != duplicates[j].Overlap {
+			return duplicates[i].Overlap > duplicates[j].Overlap
+		}
+		return duplicates[i].Similarity > duplicates[j].Similarity
+	})
+
+	log.Printf("[RAG] Duplicate check for source %s (%s): %d samples, %d candidate meetings", sourceID, language, len(samples), len(duplicates))
+	return duplicates, nil
+}
+
+// sampleChunks picks up to n chunks spread evenly across the transcript
+func sampleChunks(chunks []*database.MeetingChunk, n int) []*database.MeetingChunk {
+	if len(chunks) <= n {
+		return chunks
+	}
+	samples := make([]*database.MeetingChunk, n)
+	for i := range samples {
+		samples[i] = chunks[i*len(chunks)/n]
+	}
+	return samples
+}
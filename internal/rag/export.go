@@ -0,0 +1,116 @@
+package rag
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// ChatExport is a chat session with its messages and resolved citations
+type ChatExport struct {
+	Session    *database.ChatSession `json:"session"`
+	Messages   []ExportedMessage     `json:"messages"`
+	ExportedAt time.Time             `json:"exportedAt"`
+}
+
+// ExportedMessage is a chat message with citation details for its context chunks
+type ExportedMessage struct {
+	Role      string                   `json:"role"`
+	Content   string                   `json:"content"`
+	CreatedAt time.Time                `json:"createdAt"`
+	Citations []database.ChunkCitation `json:"citations,omitempty"`
+}
+
+// maxExportMessages bounds the history loaded for an export
+const maxExportMessages = 1000
+
+// BuildChatExport loads a session's messages and resolves the chunks each
+// assistant answer cited
+func BuildChatExport(session *database.ChatSession) (*ChatExport, error) {
+	messages, err := database.GetChatHistory(session.SessionID, maxExportMessages)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunkIDs []int
+	for _, msg := range messages {
+		chunkIDs = append(chunkIDs, msg.ContextChunkIDs...)
+	}
+	citations, err := database.GetChunkCitations(chunkIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &ChatExport{
+		Session:    session,
+		Messages:   make([]ExportedMessage, 0, len(messages)),
+		ExportedAt: time.Now().UTC(),
+	}
+	for _, msg := range messages {
+		exported := ExportedMessage{
+			Role:      msg.Role,
+			Content:   msg.Content,
+			CreatedAt: msg.CreatedAt,
+		}
+		for _, id := range msg.ContextChunkIDs {
+			if citation, ok := citations[id]; ok {
+				exported.Citations = append(exported.Citations, citation)
+			}
+		}
+		export.Messages = append(export.Messages, exported)
+	}
+
+	return export, nil
+}
+
+// Markdown renders the export as a Markdown document
+func (e *ChatExport) Markdown() string {
+	var builder strings.Builder
+
+	builder.WriteString("# Chat Session Export\n\n")
+	builder.WriteString(fmt.Sprintf("- Source: %s\n", e.Session.MeetingID))
+	builder.WriteString(fmt.Sprintf("- Transcript language: %s\n", e.Session.Language))
+	builder.WriteString(fmt.Sprintf("- Started: %s\n", e.Session.CreatedAt.UTC().Format(time.RFC3339)))
+	builder.WriteString(fmt.Sprintf("- Exported: %s\n\n", e.ExportedAt.Format(time.RFC3339)))
+
+	for _, msg := range e.Messages {
+		if msg.Role == "user" {
+			builder.WriteString("## Q: ")
+			builder.WriteString(strings.TrimSpace(msg.Content))
+			builder.WriteString("\n\n")
+			continue
+		}
+
+		builder.WriteString(strings.TrimSpace(msg.Content))
+		builder.WriteString("\n\n")
+
+		if len(msg.Citations) == 0 {
+			continue
+		}
+		builder.WriteString("**Sources:**\n\n")
+		for i, citation := range msg.Citations {
+			builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, citationLabel(citation)))
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// citationLabel formats a citation as "label [mm:ss] (speaker)"
+func citationLabel(citation database.ChunkCitation) string {
+	label := citation.DocumentTitle
+	if label == "" {
+		label = transcriptLabel(citation.SourceType)
+	}
+	if citation.StartOffsetSeconds != nil {
+		start := int(*citation.StartOffsetSeconds)
+		label += fmt.Sprintf(" [%02d:%02d]", start/60, start%60)
+	}
+	if citation.SpeakerName != nil && *citation.SpeakerName != "" {
+		label += fmt.Sprintf(" (%s)", *citation.SpeakerName)
+	}
+	return label
+}
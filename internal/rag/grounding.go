@@ -0,0 +1,217 @@
+package rag
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Grounding modes control what happens to answer sentences that are not
+// supported by the retrieved context
+const (
+	GroundingOff   = "off"   // skip verification
+	GroundingFlag  = "flag"  // keep the answer, report unsupported claims
+	GroundingStrip = "strip" // remove unsupported claims from the answer
+)
+
+// Grounding methods
+const (
+	GroundingMethodEmbedding = "embedding" // cosine similarity against context chunks
+	GroundingMethodLLM       = "llm"       // entailment judged by the LLM
+)
+
+// minClaimWords skips short sentences ("Sure.", "In summary:") that carry no claim
+const minClaimWords = 4
+
+// ClaimCheck is the verification result for one sentence of an answer
+type ClaimCheck struct {
+	Claim     string  `json:"claim"`
+	Supported bool    `json:"supported"`
+	Score     float64 `json:"score,omitempty"`
+	ChunkID   int     `json:"chunkId,omitempty"`
+}
+
+// GroundingReport summarizes how well an answer is supported by its context
+type GroundingReport struct {
+	Mode        string       `json:"mode"`
+	Method      string       `json:"method"`
+	Claims      []ClaimCheck `json:"claims"`
+	Unsupported int          `json:"unsupported"`
+	Stripped    bool         `json:"stripped,omitempty"`
+}
+
+// verifyGrounding checks every claim in the answer against the retrieved chunks.
+// Returns the (possibly stripped) answer and a report.
+func (q *QueryEngine) verifyGrounding(answer string, retrieved []retrievedChunk) (string, *GroundingReport, error) {
+	mode := q.GroundingMode
+	if mode == "" || mode == GroundingOff || len(retrieved) == 0 {
+		return answer, nil, nil
+	}
+
+	method := q.GroundingMethod
+	if method == "" {
+		method = GroundingMethodEmbedding
+	}
+
+	spans := sentenceSpans(answer)
+	var claims []string
+	for _, span := range spans {
+		if sentence := strings.TrimSpace(span); len(strings.Fields(sentence)) >= minClaimWords {
+			claims = append(claims, sentence)
+		}
+	}
+
+	report := &GroundingReport{Mode: mode, Method: method, Claims: []ClaimCheck{}}
+	if len(claims) == 0 {
+		return answer, report, nil
+	}
+
+	var (
+		checks []ClaimCheck
+		err    error
+	)
+	switch method {
+	case GroundingMethodLLM:
+		checks, err = q.checkClaimsWithLLM(claims, retrieved)
+	default:
+		checks, err = q.checkClaimsWithEmbeddings(claims, retrieved)
+	}
+	if err != nil {
+		return answer, nil, err
+	}
+
+	unsupported := make(map[string]bool)
+	for _, check := range checks {
+		if !check.Supported {
+			report.Unsupported++
+			unsupported[check.Claim] = true
+		}
+	}
+	report.Claims = checks
+
+	if mode != GroundingStrip || report.Unsupported == 0 {
+		return answer, report, nil
+	}
+
+	// Kept sentences keep their original separators; a removed sentence
+	// leaves its line break behind so paragraphs and lists stay intact
+	var stripped strings.Builder
+	for _, span := range spans {
+		if !unsupported[strings.TrimSpace(span)] {
+			stripped.WriteString(span)
+			continue
+		}
+		trailing := span[len(strings.TrimRightFunc(span, unicode.IsSpace)):]
+		if strings.Contains(trailing, "\n") {
+			stripped.WriteString(trailing)
+		}
+	}
+	report.Stripped = true
+	result := strings.TrimSpace(stripped.String())
+	if result == "" {
+		return "I could not find support for an answer to this question in the available transcript and documents.", report, nil
+	}
+
+	return result, report, nil
+}
+
+// checkClaimsWithEmbeddings marks a claim supported when its best cosine
+// similarity against a context chunk reaches the grounding threshold
+func (q *QueryEngine) checkClaimsWithEmbeddings(claims []string, retrieved []retrievedChunk) ([]ClaimCheck, error) {
+	texts := make([]string, 0, len(claims)+len(retrieved))
+	texts = append(texts, claims...)
+	for _, r := range retrieved {
+		texts = append(texts, r.chunk.ChunkText)
+	}
+
+	embeddings, err := q.EmbeddingClient.EmbedBatch(texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed claims: %w", err)
+	}
+	if len(embeddings) != len(texts) {
+		return nil, fmt.Errorf("embedding count mismatch: got %d, want %d", len(embeddings), len(texts))
+	}
+
+	threshold := q.GroundingThreshold
+	if threshold <= 0 {
+		threshold = 0.5
+	}
+
+	checks := make([]ClaimCheck, len(claims))
+	for i, claim := range claims {
+		best, bestChunk := -1.0, 0
+		for j, r := range retrieved {
+			if score := cosineSimilarity(embeddings[i], embeddings[len(claims)+j]); score > best {
+				best, bestChunk = score, r.chunk.ID
+			}
+		}
+		checks[i] = ClaimCheck{
+			Claim:     claim,
+			Supported: best >= threshold,
+			Score:     math.Round(best*1000) / 1000,
+			ChunkID:   bestChunk,
+		}
+	}
+
+	return checks, nil
+}
+
+// checkClaimsWithLLM asks the LLM which numbered claims the excerpts entail
+func (q *QueryEngine) checkClaimsWithLLM(claims []string, retrieved []retrievedChunk) ([]ClaimCheck, error) {
+	var prompt strings.Builder
+	prompt.WriteString("For each numbered claim, decide whether it is directly supported by the excerpts. ")
+	prompt.WriteString("Return only a JSON array of objects with keys: claim (number) and supported (true or false).\n\n")
+	for i, claim := range claims {
+		prompt.WriteString(fmt.Sprintf("%d. %s\n", i+1, claim))
+	}
+
+	raw, err := q.LLMClient.Generate(prompt.String(), q.buildContext(retrieved), 300, 0)
+	if err != nil {
+		return nil, fmt.Errorf("grounding check failed: %w", err)
+	}
+
+	start := strings.Index(raw, "[")
+	end := strings.LastIndex(raw, "]")
+	if start == -1 || end <= start {
+		return nil, fmt.Errorf("grounding check returned no JSON array")
+	}
+
+	var verdicts []struct {
+		Claim     int  `json:"claim"`
+		Supported bool `json:"supported"`
+	}
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &verdicts); err != nil {
+		return nil, fmt.Errorf("failed to parse grounding verdicts: %w", err)
+	}
+
+	// Claims the LLM did not rule on are treated as supported
+	checks := make([]ClaimCheck, len(claims))
+	for i, claim := range claims {
+		checks[i] = ClaimCheck{Claim: claim, Supported: true}
+	}
+	for _, verdict := range verdicts {
+		if verdict.Claim >= 1 && verdict.Claim <= len(claims) {
+			checks[verdict.Claim-1].Supported = verdict.Supported
+		}
+	}
+
+	return checks, nil
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
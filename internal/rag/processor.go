@@ -1,31 +1,95 @@
 package rag
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"regexp"
 	"strings"
 	"time"
+	"unicode"
 
 	"realtime-caption-translator/internal/database"
 	"realtime-caption-translator/internal/embedding"
+	"realtime-caption-translator/internal/failures"
 )
 
+// ChunkerVersion identifies the chunking rules and embedding model. Bump it
+// when either changes: re-processing then re-embeds chunks built by an
+// older version even when their text is unchanged.
+const ChunkerVersion = "2"
+
+// ChunkOptions sizes transcript chunks, in characters
+type ChunkOptions struct {
+	MaxChars     int // a chunk ends before the sentence that would exceed it
+	MinChars     int // a chunk ends at a speaker turn once it is this long
+	OverlapChars int // trailing sentences repeated at the start of the next chunk
+}
+
+// DefaultChunkOptions are the chunk options of NewProcessor; a zero
+// MaxChars or MinChars falls back to them
+var DefaultChunkOptions = ChunkOptions{
+	MaxChars:     2000, // ~300 tokens, good for semantic coherence
+	MinChars:     600,
+	OverlapChars: 200,
+}
+
 // Processor handles chunking and embedding of meeting transcripts
 type Processor struct {
 	EmbeddingClient *embedding.Client
+	Chunking        ChunkOptions
+}
+
+// chunkOptions returns the processor's chunk options with defaults filled in
+func (p *Processor) chunkOptions() ChunkOptions {
+	opts := p.Chunking
+	if opts.MaxChars <= 0 {
+		opts.MaxChars = DefaultChunkOptions.MaxChars
+	}
+	if opts.MinChars <= 0 {
+		opts.MinChars = DefaultChunkOptions.MinChars
+	}
+	if opts.OverlapChars < 0 {
+		opts.OverlapChars = 0
+	}
+	if opts.OverlapChars >= opts.MaxChars {
+		opts.OverlapChars = opts.MaxChars / 2
+	}
+	return opts
+}
+
+// ChunkVersion is the version recorded on the chunks the processor builds:
+// the chunker version and the chunk options, so changing either re-embeds
+// chunks on the next processing
+func (p *Processor) ChunkVersion() string {
+	opts := p.chunkOptions()
+	return fmt.Sprintf("%s:max=%d,min=%d,overlap=%d", ChunkerVersion, opts.MaxChars, opts.MinChars, opts.OverlapChars)
 }
 
 // NewProcessor creates a new RAG processor
 func NewProcessor(embeddingClient *embedding.Client) *Processor {
 	return &Processor{
 		EmbeddingClient: embeddingClient,
+		Chunking:        DefaultChunkOptions,
 	}
 }
 
 // ProcessMeetingTranscript chunks and embeds a meeting transcript
 func (p *Processor) ProcessMeetingTranscript(meetingID, language, transcript string) error {
-	log.Printf("[RAG] Starting processing for meeting %s (language: %s)", meetingID, language)
+	return p.ProcessTranscript(meetingID, language, transcript)
+}
+
+// ProcessTranscript chunks and embeds the transcript of any RAG source. The
+// source ID is a meeting ID or a prefixed ID from database.RAGSourceID.
+func (p *Processor) ProcessTranscript(meetingID, language, transcript string) error {
+	log.Printf("[RAG] Starting processing for source %s (language: %s)", meetingID, language)
+
+	// Uploaded videos and recordings have plain-text transcripts without
+	// per-line timestamps; split them into sentences so they chunk evenly
+	if database.RAGSourceType(meetingID) != database.SourceTypeMeeting {
+		transcript = splitSentences(transcript)
+	}
 
 	// Step 1: Parse and chunk transcript
 	chunks, err := p.chunkTranscript(meetingID, language, transcript)
@@ -40,173 +104,374 @@ func (p *Processor) ProcessMeetingTranscript(meetingID, language, transcript str
 
 	log.Printf("[RAG] Generated %d chunks for meeting %s", len(chunks), meetingID)
 
-	// Step 2: Extract text from chunks for embedding
-	texts := make([]string, len(chunks))
-	for i, chunk := range chunks {
-		texts[i] = chunk.ChunkText
-	}
-
-	// Step 3: Generate embeddings for all chunks in batch mode (more efficient)
-	embeddings, err := p.EmbeddingClient.EmbedBatch(texts)
+	// Step 2: Compare against previously stored chunks so re-processing only
+	// touches chunks whose content changed
+	existing, err := database.GetChunkFingerprints(meetingID, language)
 	if err != nil {
-		log.Printf("[RAG] Failed to generate embeddings for meeting %s: %v", meetingID, err)
-		// Mark chunks as failed
-		database.UpdateChunkProcessingStatus(meetingID, language, "failed")
-		return fmt.Errorf("failed to generate embeddings: %w", err)
+		return fmt.Errorf("failed to load existing chunks: %w", err)
 	}
 
-	log.Printf("[RAG] Generated %d embeddings for meeting %s", len(embeddings), meetingID)
-
-	// Step 4: Store chunks with embeddings in database
-	successCount := 0
+	stored := make([]*database.MeetingChunk, 0, len(chunks))
+	unchanged := 0
 	for i, chunk := range chunks {
-		chunk.Embedding = embeddings[i]
-		chunk.ProcessingStatus = "completed"
+		if fp, ok := existing[chunk.ChunkIndex]; ok && fp.ContentHash == chunk.ContentHash && fp.ChunkVersion == chunk.ChunkVersion {
+			if fp.ProcessingStatus == database.ChunkStatusCompleted {
+				unchanged++
+				continue
+			}
+			// Same content but never embedded successfully; embed it in place
+			chunk.ID = fp.ID
+			stored = append(stored, chunk)
+			continue
+		}
 
-		if err := database.CreateMeetingChunk(chunk); err != nil {
+		if err := database.UpsertMeetingChunk(chunk); err != nil {
 			log.Printf("[RAG] Failed to save chunk %d for meeting %s: %v", i, meetingID, err)
 			continue
 		}
-		successCount++
+		stored = append(stored, chunk)
 	}
 
-	log.Printf("[RAG] Successfully processed meeting %s: %d/%d chunks saved", meetingID, successCount, len(chunks))
+	if removed, err := database.DeleteChunksFromIndex(meetingID, language, len(chunks)); err != nil {
+		log.Printf("[RAG] Failed to remove stale chunks for meeting %s: %v", meetingID, err)
+	} else if removed > 0 {
+		log.Printf("[RAG] Removed %d stale chunks for meeting %s", removed, meetingID)
+	}
 
-	if successCount == 0 {
+	if len(stored) == 0 {
+		if unchanged > 0 {
+			log.Printf("[RAG] Meeting %s unchanged (%d chunks already embedded), skipping", meetingID, unchanged)
+			return nil
+		}
 		return fmt.Errorf("failed to save any chunks for meeting %s", meetingID)
 	}
 
-	return nil
+	// Step 3: Embed and finalize each chunk
+	completed := p.embedChunks(stored)
+
+	log.Printf("[RAG] Processed meeting %s: %d/%d chunks completed, %d unchanged", meetingID, completed, len(stored), unchanged)
+
+	return embeddingResult(meetingID, completed, len(stored))
 }
 
-// chunkTranscript splits transcript into semantic chunks
-// Transcript format: "[HH:MM:SS] SpeakerName: Text\n"
-func (p *Processor) chunkTranscript(meetingID, language, transcript string) ([]*database.MeetingChunk, error) {
-	lines := strings.Split(transcript, "\n")
+// ReprocessTranscript rebuilds all chunks of a source/language from
+// transcript and re-embeds every one of them, e.g. after the chunking
+// parameters or the embedding model changed. Embeddings are computed first
+// and the old chunks are swapped for the new ones in one transaction, so
+// queries never see a half-rebuilt language. Chunks whose embedding failed
+// are left to the retry worker.
+func (p *Processor) ReprocessTranscript(meetingID, language, transcript string) error {
+	if database.RAGSourceType(meetingID) != database.SourceTypeMeeting {
+		transcript = splitSentences(transcript)
+	}
 
-	var chunks []*database.MeetingChunk
-	var currentChunk strings.Builder
-	var chunkStartOffset *float64
-	var chunkSpeakers []string
-	chunkIndex := 0
+	chunks, err := p.chunkTranscript(meetingID, language, transcript)
+	if err != nil {
+		return fmt.Errorf("failed to chunk transcript: %w", err)
+	}
+	if len(chunks) == 0 {
+		if _, err := database.DeleteChunksFromIndex(meetingID, language, 0); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.ChunkText
+	}
+	embeddings, err := p.EmbeddingClient.WithoutCache().EmbedBatch(texts)
+	if err != nil || len(embeddings) != len(chunks) {
+		log.Printf("[RAG] Batch embedding failed for %s/%s, embedding %d chunks after the swap: %v", meetingID, language, len(chunks), err)
+		embeddings = make([][]float32, len(chunks))
+	}
+	for i, chunk := range chunks {
+		chunk.Embedding = embeddings[i]
+	}
 
-	const maxChunkChars = 2000 // ~300 tokens, good for semantic coherence
+	if err := database.ReplaceMeetingChunks(meetingID, chunks); err != nil {
+		return err
+	}
 
-	// Regex to parse: [HH:MM:SS] SpeakerName: Text
-	lineRegex := regexp.MustCompile(`^\[(\d{2}):(\d{2}):(\d{2})\]\s+([^:]+):\s+(.+)$`)
+	var pending []*database.MeetingChunk
+	for _, chunk := range chunks {
+		if chunk.ProcessingStatus != database.ChunkStatusCompleted {
+			pending = append(pending, chunk)
+		}
+	}
+	completed := len(chunks) - len(pending)
+	if len(pending) > 0 {
+		completed += p.embedChunks(pending)
+	}
 
-	var lastOffset *float64
+	log.Printf("[RAG] Reprocessed %s/%s: %d/%d chunks embedded (version %s)", meetingID, language, completed, len(chunks), p.ChunkVersion())
+	return embeddingResult(meetingID, completed, len(chunks))
+}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+// embedBatchAttempts is how often a batch request is tried before its
+// chunks are embedded one by one
+const embedBatchAttempts = 2
+
+// PartialError reports an ingestion in which some chunks failed to embed.
+// The failed chunks are left to the retry worker.
+type PartialError struct {
+	Failed int
+	Total  int
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%d of %d chunks failed to embed and will be retried", e.Failed, e.Total)
+}
+
+// embeddingResult returns nil when every chunk embedded, a *PartialError
+// when some did, and an error when none did
+func embeddingResult(meetingID string, completed, total int) error {
+	switch {
+	case completed == total:
+		return nil
+	case completed == 0:
+		return fmt.Errorf("failed to embed any chunks for meeting %s", meetingID)
+	default:
+		return &PartialError{Failed: total - completed, Total: total}
+	}
+}
+
+// embedChunks moves stored chunks through processing -> completed/failed and
+// returns the number of chunks that completed. Chunks are embedded in
+// batches of the embedding client's batch size; a batch whose request keeps
+// failing falls back to embedding its chunks one by one, so a single bad
+// chunk only fails itself and a bad batch only delays its own chunks.
+func (p *Processor) embedChunks(chunks []*database.MeetingChunk) int {
+	ids := make([]int, len(chunks))
+	for i, chunk := range chunks {
+		ids[i] = chunk.ID
+	}
+
+	if err := database.MarkChunksProcessing(ids); err != nil {
+		log.Printf("[RAG] Failed to mark chunks processing: %v", err)
+	}
+
+	completed, failedBatches := 0, 0
+	size := p.EmbeddingClient.BatchSize()
+	for start := 0; start < len(chunks); start += size {
+		batch := chunks[start:min(start+size, len(chunks))]
+		embeddings, err := p.embedBatch(batch)
+		if err != nil {
+			failedBatches++
+			log.Printf("[RAG] Batch %d embedding failed, retrying %d chunks individually: %v", start/size+1, len(batch), err)
+			embeddings = make([][]float32, len(batch))
 		}
 
-		matches := lineRegex.FindStringSubmatch(line)
-		if len(matches) != 6 {
-			// Line doesn't match expected format, append to current chunk
-			if currentChunk.Len() > 0 {
-				currentChunk.WriteString(" ")
+		for i, chunk := range batch {
+			embeddingVec := embeddings[i]
+			if len(embeddingVec) == 0 {
+				embeddingVec, err = p.EmbeddingClient.Embed(chunk.ChunkText)
+				if err != nil {
+					p.failChunk(chunk, chunk.AttemptCount+1, err)
+					continue
+				}
 			}
-			currentChunk.WriteString(line)
-			continue
+
+			if err := database.CompleteChunk(chunk.ID, embeddingVec); err != nil {
+				p.failChunk(chunk, chunk.AttemptCount+1, err)
+				continue
+			}
+			chunk.Embedding = embeddingVec
+			chunk.ProcessingStatus = database.ChunkStatusCompleted
+			completed++
 		}
+	}
 
-		// Parse timestamp components
-		hours := matches[1]
-		mins := matches[2]
-		secs := matches[3]
-		speaker := strings.TrimSpace(matches[4])
-		text := strings.TrimSpace(matches[5])
+	if failedBatches > 0 {
+		log.Printf("[RAG] %d of %d embedding batches failed; %d/%d chunks completed", failedBatches, (len(chunks)+size-1)/size, completed, len(chunks))
+	}
+	return completed
+}
 
-		// Calculate offset in seconds
-		var h, m, s int
-		fmt.Sscanf(hours, "%d", &h)
-		fmt.Sscanf(mins, "%d", &m)
-		fmt.Sscanf(secs, "%d", &s)
-		offsetSeconds := float64(h*3600 + m*60 + s)
-		lastOffset = &offsetSeconds
+// embedBatch embeds the texts of one batch of chunks, trying the request
+// up to embedBatchAttempts times
+func (p *Processor) embedBatch(batch []*database.MeetingChunk) ([][]float32, error) {
+	texts := make([]string, len(batch))
+	for i, chunk := range batch {
+		texts[i] = chunk.ChunkText
+	}
 
-		// Set chunk start time if this is first entry
-		if chunkStartOffset == nil {
-			chunkStartOffset = &offsetSeconds
+	var err error
+	for attempt := 1; attempt <= embedBatchAttempts; attempt++ {
+		var embeddings [][]float32
+		embeddings, err = p.EmbeddingClient.EmbedBatch(texts)
+		if err == nil && len(embeddings) != len(batch) {
+			err = fmt.Errorf("embedding service returned %d embeddings for %d chunks", len(embeddings), len(batch))
 		}
-
-		// Add to current chunk
-		if currentChunk.Len() > 0 {
-			currentChunk.WriteString(" ")
+		if err == nil {
+			return embeddings, nil
 		}
-		currentChunk.WriteString(fmt.Sprintf("%s: %s", speaker, text))
+	}
+	return nil, err
+}
 
-		// Track unique speakers in this chunk
-		if !contains(chunkSpeakers, speaker) {
-			chunkSpeakers = append(chunkSpeakers, speaker)
-		}
+// failChunk marks a chunk failed and schedules a retry unless attempts are exhausted
+func (p *Processor) failChunk(chunk *database.MeetingChunk, attempt int, cause error) {
+	var nextRetryAt *time.Time
+	if attempt < MaxChunkAttempts {
+		retryAt := time.Now().UTC().Add(retryBackoff(attempt))
+		nextRetryAt = &retryAt
+	}
+
+	log.Printf("[RAG] Chunk %d (meeting %s, index %d) failed on attempt %d: %v", chunk.ID, chunk.MeetingID, chunk.ChunkIndex, attempt, cause)
 
-		// Check if we should finalize this chunk
-		shouldFinalize := false
+	if err := database.FailChunk(chunk.ID, cause.Error(), nextRetryAt); err != nil {
+		log.Printf("[RAG] Failed to record chunk failure for chunk %d: %v", chunk.ID, err)
+	}
+	chunk.ProcessingStatus = database.ChunkStatusFailed
+
+	if nextRetryAt == nil {
+		chunkID := chunk.ID
+		failures.Record(failures.Failure{
+			Source:   database.FailureSourceChunk,
+			Kind:     "embedding",
+			Priority: failures.PriorityLow,
+			Err:      cause,
+			Context: map[string]interface{}{
+				"language":   chunk.Language,
+				"chunkIndex": chunk.ChunkIndex,
+			},
+			ChunkID:   &chunkID,
+			MeetingID: chunk.MeetingID,
+			Attempts:  attempt,
+		})
+	}
+}
+
+// transcriptLineRegex parses "[HH:MM:SS] SpeakerName: Text"
+var transcriptLineRegex = regexp.MustCompile(`^\[(\d{2}):(\d{2}):(\d{2})\]\s+([^:]+):\s+(.+)$`)
+
+// chunkUnit is one sentence of a transcript with its speaker and time
+type chunkUnit struct {
+	speaker string
+	offset  *float64
+	text    string
+}
 
-		// Finalize if chunk exceeds max size
-		if currentChunk.Len() > maxChunkChars {
-			shouldFinalize = true
+// transcriptUnits splits a transcript into sentences, each carrying the
+// speaker and time of its line. Lines without the timestamped speaker
+// format carry neither.
+func transcriptUnits(transcript string) []chunkUnit {
+	var units []chunkUnit
+	for _, line := range strings.Split(transcript, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
 		}
 
-		if shouldFinalize && currentChunk.Len() > 0 {
-			chunk := p.createChunk(
-				meetingID,
-				language,
-				chunkIndex,
-				currentChunk.String(),
-				chunkStartOffset,
-				&offsetSeconds,
-				chunkSpeakers,
-			)
+		unit := chunkUnit{text: line}
+		if matches := transcriptLineRegex.FindStringSubmatch(line); len(matches) == 6 {
+			var h, m, sec int
+			fmt.Sscanf(matches[1], "%d", &h)
+			fmt.Sscanf(matches[2], "%d", &m)
+			fmt.Sscanf(matches[3], "%d", &sec)
+			offsetSeconds := float64(h*3600 + m*60 + sec)
+			unit = chunkUnit{speaker: strings.TrimSpace(matches[4]), offset: &offsetSeconds, text: strings.TrimSpace(matches[5])}
+		}
 
-			chunks = append(chunks, chunk)
-			chunkIndex++
+		for _, span := range sentenceSpans(unit.text) {
+			if sentence := strings.TrimSpace(span); sentence != "" {
+				units = append(units, chunkUnit{speaker: unit.speaker, offset: unit.offset, text: sentence})
+			}
+		}
+	}
+	return units
+}
 
-			// Reset for next chunk
-			currentChunk.Reset()
-			chunkStartOffset = nil
-			chunkSpeakers = []string{}
+// chunkTranscript splits a transcript into chunks of whole sentences.
+// A chunk ends before the sentence that would take it past MaxChars, and at
+// a change of speaker once it holds MinChars. Each chunk after the first
+// starts with the last sentences of the previous one, up to OverlapChars.
+// Transcript format: "[HH:MM:SS] SpeakerName: Text\n"
+func (p *Processor) chunkTranscript(meetingID, language, transcript string) ([]*database.MeetingChunk, error) {
+	opts := p.chunkOptions()
+	units := transcriptUnits(transcript)
+
+	var chunks []*database.MeetingChunk
+	var current []chunkUnit
+	currentLen := 0
+	fresh := 0 // units in current not carried over from the previous chunk
+
+	finalize := func() {
+		chunks = append(chunks, p.createChunk(meetingID, language, len(chunks), current))
+
+		// Carry the trailing sentences that fit the overlap into the next chunk
+		carried := 0
+		kept := 0
+		for i := len(current) - 1; i > 0; i-- {
+			if kept+len(current[i].text) > opts.OverlapChars {
+				break
+			}
+			kept += len(current[i].text) + 1
+			carried++
 		}
+		current = append([]chunkUnit(nil), current[len(current)-carried:]...)
+		currentLen = kept
+		fresh = 0
 	}
 
-	// Add remaining content as final chunk
-	if currentChunk.Len() > 0 {
-		chunk := p.createChunk(
-			meetingID,
-			language,
-			chunkIndex,
-			currentChunk.String(),
-			chunkStartOffset,
-			lastOffset,
-			chunkSpeakers,
-		)
-		chunks = append(chunks, chunk)
+	for _, unit := range units {
+		if fresh > 0 {
+			turn := unit.speaker != current[len(current)-1].speaker
+			if currentLen+len(unit.text) > opts.MaxChars || (turn && currentLen >= opts.MinChars) {
+				finalize()
+			}
+		}
+		current = append(current, unit)
+		currentLen += len(unit.text) + 1
+		fresh++
+	}
+	if fresh > 0 {
+		chunks = append(chunks, p.createChunk(meetingID, language, len(chunks), current))
 	}
 
 	return chunks, nil
 }
 
-// createChunk creates a MeetingChunk struct from chunk data
-func (p *Processor) createChunk(
-	meetingID, language string,
-	chunkIndex int,
-	chunkText string,
-	startOffset, endOffset *float64,
-	speakers []string,
-) *database.MeetingChunk {
+// createChunk creates a MeetingChunk from its sentences. Each speaker turn
+// is prefixed with the speaker's name; every speaker is recorded, and
+// SpeakerName is set when there is only one.
+func (p *Processor) createChunk(meetingID, language string, chunkIndex int, units []chunkUnit) *database.MeetingChunk {
+	var text strings.Builder
+	var speakers []string
+	var startOffset, endOffset *float64
+	for i, unit := range units {
+		if i > 0 {
+			text.WriteString(" ")
+		}
+		if unit.speaker != "" && (i == 0 || units[i-1].speaker != unit.speaker) {
+			text.WriteString(unit.speaker + ": ")
+		}
+		text.WriteString(unit.text)
+
+		if unit.speaker != "" && !contains(speakers, unit.speaker) {
+			speakers = append(speakers, unit.speaker)
+		}
+		if unit.offset != nil {
+			if startOffset == nil {
+				startOffset = unit.offset
+			}
+			endOffset = unit.offset
+		}
+	}
+
 	chunk := &database.MeetingChunk{
 		MeetingID:          meetingID,
 		Language:           language,
 		ChunkIndex:         chunkIndex,
-		ChunkText:          strings.TrimSpace(chunkText),
+		ChunkText:          strings.TrimSpace(text.String()),
+		SpeakerNames:       speakers,
 		StartOffsetSeconds: startOffset,
 		EndOffsetSeconds:   endOffset,
-		ProcessingStatus:   "pending",
+		ProcessingStatus:   database.ChunkStatusPending,
+		ChunkVersion:       p.ChunkVersion(),
 	}
+	chunk.ContentHash = chunkContentHash(chunk.ChunkText, startOffset, endOffset)
 
 	// If only one speaker in chunk, add speaker info
 	if len(speakers) == 1 {
@@ -227,6 +492,66 @@ func (p *Processor) createChunk(
 	return chunk
 }
 
+// splitSentences puts each sentence of a plain-text transcript on its own line
+func splitSentences(text string) string {
+	var builder strings.Builder
+	for _, span := range sentenceSpans(text) {
+		if sentence := strings.TrimSpace(span); sentence != "" {
+			if builder.Len() > 0 {
+				builder.WriteString("\n")
+			}
+			builder.WriteString(sentence)
+		}
+	}
+	return builder.String()
+}
+
+// sentenceSpans cuts text after each sentence end and its trailing
+// whitespace, so the spans concatenate back to text. A sentence ends at a
+// newline, or at '.', '!' or '?' followed by whitespace or the end of the
+// text; decimals ("3.5") and dotted abbreviations ("e.g.") stay whole.
+func sentenceSpans(text string) []string {
+	var spans []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '.', '!', '?':
+			if i+1 < len(text) && !unicode.IsSpace(rune(text[i+1])) {
+				continue
+			}
+		case '\n':
+		default:
+			continue
+		}
+		end := i + 1
+		for end < len(text) && unicode.IsSpace(rune(text[end])) {
+			end++
+		}
+		spans = append(spans, text[start:end])
+		start = end
+		i = end - 1
+	}
+	if start < len(text) {
+		spans = append(spans, text[start:])
+	}
+	return spans
+}
+
+// chunkContentHash fingerprints chunk text and offsets so re-processing can
+// detect unchanged chunks
+func chunkContentHash(text string, startOffset, endOffset *float64) string {
+	h := sha256.New()
+	h.Write([]byte(text))
+	for _, offset := range []*float64{startOffset, endOffset} {
+		if offset != nil {
+			fmt.Fprintf(h, "|%.3f", *offset)
+		} else {
+			h.Write([]byte("|-"))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // contains checks if a string slice contains a string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
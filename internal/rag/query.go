@@ -1,19 +1,40 @@
 package rag
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 
 	"realtime-caption-translator/internal/database"
 	"realtime-caption-translator/internal/embedding"
+	"realtime-caption-translator/internal/flags"
 	"realtime-caption-translator/internal/llm"
+	"realtime-caption-translator/internal/rerank"
 )
 
 // QueryEngine handles RAG queries: retrieve context + generate answers
 type QueryEngine struct {
 	EmbeddingClient *embedding.Client
 	LLMClient       *llm.Client
+
+	// MinSimilarity drops retrieved chunks whose cosine similarity to the
+	// question is below the cutoff (0 disables the cutoff)
+	MinSimilarity float64
+
+	// Hybrid blends keyword rank into transcript retrieval
+	Hybrid bool
+
+	// Reranker, when set, reorders RerankCandidates retrieved chunks with a
+	// cross-encoder and passes only the top-k of them to the LLM
+	Reranker         *rerank.Client
+	RerankCandidates int
+
+	// Post-generation grounding verification (see grounding.go)
+	GroundingMode      string
+	GroundingMethod    string
+	GroundingThreshold float64
 }
 
 // NewQueryEngine creates a new RAG query engine
@@ -24,70 +45,286 @@ func NewQueryEngine(embeddingClient *embedding.Client, llmClient *llm.Client) *Q
 	}
 }
 
+// WithoutCache returns a copy of the engine whose embedding and LLM calls skip
+// cached results (fresh results are still written back to the caches)
+func (q *QueryEngine) WithoutCache() *QueryEngine {
+	clone := *q
+	clone.EmbeddingClient = q.EmbeddingClient.WithoutCache()
+	clone.LLMClient = q.LLMClient.WithoutCache()
+	return &clone
+}
+
+// WithHybrid returns a copy of the engine with hybrid retrieval on or off
+func (q *QueryEngine) WithHybrid(enabled bool) *QueryEngine {
+	clone := *q
+	clone.Hybrid = enabled
+	return &clone
+}
+
 // Query performs RAG query: retrieve relevant chunks and generate answer (default English)
 func (q *QueryEngine) Query(meetingID, language, question string, topK int) (string, []int, error) {
 	return q.QueryWithLanguage(meetingID, language, "en", question, topK)
 }
 
+// Citation labels a chunk that was used as context for an answer
+type Citation struct {
+	ChunkID            int      `json:"chunkId"`
+	SourceType         string   `json:"sourceType"`
+	SourceLabel        string   `json:"sourceLabel"`
+	DocumentID         int      `json:"documentId,omitempty"`
+	Similarity         float64  `json:"similarity"`
+	SpeakerName        *string  `json:"speakerName,omitempty"`
+	SpeakerNames       []string `json:"speakerNames,omitempty"`
+	StartOffsetSeconds *float64 `json:"startOffsetSeconds,omitempty"`
+	RerankScore        *float64 `json:"rerankScore,omitempty"`
+}
+
+// retrievedChunk pairs a retrieved chunk with its citation label
+type retrievedChunk struct {
+	chunk    database.MeetingChunk
+	citation Citation
+}
+
+// QueryResult is the answer to a RAG query with its citations and grounding report
+type QueryResult struct {
+	Answer    string
+	Citations []Citation
+	Grounding *GroundingReport
+}
+
+// ChunkIDs returns the IDs of the chunks cited by the answer
+func (r *QueryResult) ChunkIDs() []int {
+	chunkIDs := make([]int, len(r.Citations))
+	for i, citation := range r.Citations {
+		chunkIDs[i] = citation.ChunkID
+	}
+	return chunkIDs
+}
+
 // QueryWithLanguage performs RAG query with specified response language
 func (q *QueryEngine) QueryWithLanguage(meetingID, transcriptLanguage, chatLanguage, question string, topK int) (string, []int, error) {
+	result, err := q.QueryDetailed(meetingID, transcriptLanguage, chatLanguage, question, topK, q.MinSimilarity)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return result.Answer, result.ChunkIDs(), nil
+}
+
+// QueryDetailed performs a RAG query over the source transcript and, for
+// meetings, the reference documents pinned to it or shared globally. Returns
+// the answer with a labelled citation per context chunk and, when enabled,
+// a grounding report for the answer's claims. Chunks less similar to the
+// question than minSimilarity are excluded from the context.
+func (q *QueryEngine) QueryDetailed(meetingID, transcriptLanguage, chatLanguage, question string, topK int, minSimilarity float64) (*QueryResult, error) {
+	return q.QueryDetailedStream(context.Background(), meetingID, transcriptLanguage, chatLanguage, question, topK, minSimilarity, nil)
+}
+
+// QueryDetailedStream is QueryDetailed with the answer streamed: onToken,
+// when set, receives the answer text as the LLM produces it. The result
+// still carries the final answer, which grounding verification may have
+// changed from the streamed text.
+func (q *QueryEngine) QueryDetailedStream(ctx context.Context, meetingID, transcriptLanguage, chatLanguage, question string, topK int, minSimilarity float64, onToken func(string) error) (*QueryResult, error) {
+	return q.query(ctx, meetingID, transcriptLanguage, chatLanguage, question, question, topK, minSimilarity, onToken)
+}
+
+// QueryDetailedWithHistory is QueryDetailedStream for a question asked in
+// a chat session: chunks are retrieved for the question alone, and the
+// LLM also sees the session's recent messages so follow-up questions
+// resolve. Call it before the question itself is saved to the session.
+func (q *QueryEngine) QueryDetailedWithHistory(ctx context.Context, meetingID, transcriptLanguage, chatLanguage, sessionID, question string, topK int, minSimilarity float64, onToken func(string) error) (*QueryResult, error) {
+	return q.query(ctx, meetingID, transcriptLanguage, chatLanguage, question, historyPrompt(sessionID, question), topK, minSimilarity, onToken)
+}
+
+// query retrieves chunks for question and asks the LLM prompt over them
+func (q *QueryEngine) query(ctx context.Context, meetingID, transcriptLanguage, chatLanguage, question, prompt string, topK int, minSimilarity float64, onToken func(string) error) (*QueryResult, error) {
 	log.Printf("[RAG Query] Processing question for meeting %s (transcript: %s, response: %s)", meetingID, transcriptLanguage, chatLanguage)
 
 	// Step 1: Generate embedding for the question
 	questionEmbedding, err := q.EmbeddingClient.Embed(question)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to embed question: %w", err)
+		return nil, fmt.Errorf("failed to embed question: %w", err)
 	}
 
 	log.Printf("[RAG Query] Generated question embedding (%d dims)", len(questionEmbedding))
 
-	// Step 2: Retrieve top-k similar chunks using vector similarity search
-	chunks, err := database.SearchSimilarChunks(meetingID, transcriptLanguage, questionEmbedding, topK)
+	// With a reranker, retrieve a wider candidate set and narrow it to top-k
+	// after reranking
+	candidates := topK
+	if q.Reranker != nil && q.RerankCandidates > candidates {
+		candidates = q.RerankCandidates
+	}
+
+	// Step 2: Retrieve similar chunks using vector similarity search,
+	// blended with keyword rank in hybrid mode
+	var chunks []database.MeetingChunk
+	if q.Hybrid {
+		chunks, err = database.SearchHybridChunks(meetingID, transcriptLanguage, question, questionEmbedding, candidates)
+	} else {
+		chunks, err = database.SearchSimilarChunks(meetingID, transcriptLanguage, questionEmbedding, candidates)
+	}
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to search chunks: %w", err)
+		return nil, fmt.Errorf("failed to search chunks: %w", err)
+	}
+
+	sourceType := database.RAGSourceType(meetingID)
+	retrieved := make([]retrievedChunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		retrieved = append(retrieved, retrievedChunk{
+			chunk: chunk,
+			citation: Citation{
+				ChunkID:            chunk.ID,
+				SourceType:         sourceType,
+				SourceLabel:        transcriptLabel(sourceType),
+				Similarity:         chunk.Similarity,
+				SpeakerName:        chunk.SpeakerName,
+				SpeakerNames:       chunk.SpeakerNames,
+				StartOffsetSeconds: chunk.StartOffsetSeconds,
+			},
+		})
+	}
+
+	// Step 3: Blend in reference documents, keeping the overall best
+	// candidates by similarity
+	if sourceType == database.SourceTypeMeeting {
+		docChunks, err := database.SearchSimilarDocumentChunks(meetingID, flags.SubjectForMeeting(meetingID).OrgID, questionEmbedding, candidates)
+		if err != nil {
+			log.Printf("[RAG Query] Warning: document search failed: %v", err)
+		}
+		for _, match := range docChunks {
+			retrieved = append(retrieved, retrievedChunk{
+				chunk: match.MeetingChunk,
+				citation: Citation{
+					ChunkID:     match.ID,
+					SourceType:  database.SourceTypeDocument,
+					SourceLabel: match.DocumentTitle,
+					DocumentID:  match.DocumentID,
+					Similarity:  match.Similarity,
+				},
+			})
+		}
+		sort.SliceStable(retrieved, func(i, j int) bool {
+			return retrieved[i].chunk.Similarity > retrieved[j].chunk.Similarity
+		})
+		if len(retrieved) > candidates {
+			retrieved = retrieved[:candidates]
+		}
+	}
+
+	if minSimilarity > 0 {
+		relevant := retrieved[:0]
+		for _, r := range retrieved {
+			if r.chunk.Similarity >= minSimilarity {
+				relevant = append(relevant, r)
+			}
+		}
+		if dropped := len(retrieved) - len(relevant); dropped > 0 {
+			log.Printf("[RAG Query] Dropped %d chunks below similarity %.2f", dropped, minSimilarity)
+		}
+		retrieved = relevant
+	}
+
+	if len(retrieved) > topK {
+		retrieved = q.rerank(question, retrieved, topK)
 	}
 
-	if len(chunks) == 0 {
+	if len(retrieved) == 0 {
 		log.Printf("[RAG Query] No chunks found for meeting %s", meetingID)
-		return "No relevant information found in the meeting transcript. The meeting may not have been processed yet or the transcript may be empty.", nil, nil
+		return &QueryResult{Answer: "No relevant information found in the meeting transcript. The meeting may not have been processed yet or the transcript may be empty."}, nil
 	}
 
-	log.Printf("[RAG Query] Retrieved %d relevant chunks", len(chunks))
+	log.Printf("[RAG Query] Retrieved %d relevant chunks", len(retrieved))
 
-	// Step 3: Build context from retrieved chunks
-	context := q.buildContext(chunks)
+	// Step 4: Build context from retrieved chunks
+	context := q.buildContext(retrieved)
 
 	log.Printf("[RAG Query] Built context (%d chars)", len(context))
 
-	// Step 4: Generate answer using LLM with specified chat language
-	answer, err := q.LLMClient.GenerateWithLanguage(question, context, chatLanguage, 500, 0.7)
+	// Step 5: Generate answer using LLM with specified chat language
+	var answer string
+	if onToken != nil {
+		answer, err = q.LLMClient.GenerateStream(ctx, prompt, context, chatLanguage, 500, 0.7, onToken)
+	} else {
+		answer, err = q.LLMClient.GenerateWithLanguage(prompt, context, chatLanguage, 500, 0.7)
+	}
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to generate answer: %w", err)
+		return nil, fmt.Errorf("failed to generate answer: %w", err)
 	}
 
 	log.Printf("[RAG Query] Generated answer (%d chars)", len(answer))
 
-	// Collect chunk IDs for citation
-	chunkIDs := make([]int, len(chunks))
-	for i, chunk := range chunks {
-		chunkIDs[i] = chunk.ID
+	// Step 6: Verify the answer's claims against the retrieved context
+	answer, grounding, err := q.verifyGrounding(answer, retrieved)
+	if err != nil {
+		log.Printf("[RAG Query] Warning: grounding verification failed: %v", err)
+	} else if grounding != nil && grounding.Unsupported > 0 {
+		log.Printf("[RAG Query] %d/%d claims unsupported (mode: %s)", grounding.Unsupported, len(grounding.Claims), grounding.Mode)
+	}
+
+	citations := make([]Citation, len(retrieved))
+	for i, r := range retrieved {
+		citations[i] = r.citation
 	}
 
-	return answer, chunkIDs, nil
+	return &QueryResult{Answer: answer, Citations: citations, Grounding: grounding}, nil
+}
+
+// rerank orders retrieved chunks by cross-encoder relevance to question and
+// keeps the top-k. Without a reranker, or if it fails, the top-k by
+// similarity are kept.
+func (q *QueryEngine) rerank(question string, retrieved []retrievedChunk, topK int) []retrievedChunk {
+	if q.Reranker == nil {
+		return retrieved[:topK]
+	}
+	texts := make([]string, len(retrieved))
+	for i, r := range retrieved {
+		texts[i] = r.chunk.ChunkText
+	}
+	scores, err := q.Reranker.Rerank(question, texts)
+	if err != nil {
+		log.Printf("[RAG Query] Warning: reranking failed, using similarity order: %v", err)
+		return retrieved[:topK]
+	}
+
+	for i := range retrieved {
+		score := scores[i]
+		retrieved[i].citation.RerankScore = &score
+	}
+	sort.SliceStable(retrieved, func(i, j int) bool {
+		return *retrieved[i].citation.RerankScore > *retrieved[j].citation.RerankScore
+	})
+	log.Printf("[RAG Query] Reranked %d candidates, keeping %d", len(retrieved), topK)
+	return retrieved[:topK]
+}
+
+// transcriptLabel names the transcript of a source type in citations
+func transcriptLabel(sourceType string) string {
+	switch sourceType {
+	case database.SourceTypeVideo:
+		return "Video transcript"
+	case database.SourceTypeRecording:
+		return "Recording transcript"
+	default:
+		return "Meeting transcript"
+	}
 }
 
 // buildContext creates a formatted context string from retrieved chunks
-func (q *QueryEngine) buildContext(chunks []database.MeetingChunk) string {
+func (q *QueryEngine) buildContext(retrieved []retrievedChunk) string {
 	var builder strings.Builder
 
-	builder.WriteString("Meeting Transcript Excerpts:\n\n")
+	builder.WriteString("Meeting Transcript and Reference Excerpts:\n\n")
 
-	for i, chunk := range chunks {
+	for i, r := range retrieved {
+		chunk := r.chunk
 		builder.WriteString(fmt.Sprintf("--- Excerpt %d ---\n", i+1))
+		builder.WriteString(fmt.Sprintf("Source: %s\n", r.citation.SourceLabel))
 
 		// Add speaker information if available
 		if chunk.SpeakerName != nil {
 			builder.WriteString(fmt.Sprintf("Speaker: %s\n", *chunk.SpeakerName))
+		} else if len(chunk.SpeakerNames) > 1 {
+			builder.WriteString(fmt.Sprintf("Speakers: %s\n", strings.Join(chunk.SpeakerNames, ", ")))
 		}
 
 		// Add timestamp information if available
@@ -106,12 +343,21 @@ func (q *QueryEngine) buildContext(chunks []database.MeetingChunk) string {
 
 // QueryWithHistory performs RAG query with conversation history for context
 func (q *QueryEngine) QueryWithHistory(meetingID, language, sessionID, question string, topK int) (string, []int, error) {
-	// Get chat history
+	result, err := q.QueryDetailedWithHistory(context.Background(), meetingID, language, "en", sessionID, question, topK, q.MinSimilarity, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return result.Answer, result.ChunkIDs(), nil
+}
+
+// historyPrompt prefixes a question with the last messages of its chat
+// session; without history the question is used as is
+func historyPrompt(sessionID, question string) string {
 	history, err := database.GetChatHistory(sessionID, 5) // Last 5 messages
 	if err != nil {
 		log.Printf("[RAG Query] Warning: Could not retrieve chat history: %v", err)
 		// Continue without history
-		return q.Query(meetingID, language, question, topK)
+		return question
 	}
 
 	// Build question with conversation context
@@ -124,6 +370,5 @@ func (q *QueryEngine) QueryWithHistory(meetingID, language, sessionID, question
 		contextualQuestion.WriteString("\nCurrent question: ")
 	}
 	contextualQuestion.WriteString(question)
-
-	return q.Query(meetingID, language, contextualQuestion.String(), topK)
+	return contextualQuestion.String()
 }
@@ -0,0 +1,76 @@
+package rag
+
+import (
+	"log"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+)
+
+const (
+	// MaxChunkAttempts is the number of embedding attempts before a chunk is left failed
+	MaxChunkAttempts = 5
+
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = 30 * time.Minute
+
+	// staleProcessingAfter is how long a chunk may stay processing before it
+	// is assumed abandoned by a crashed or restarted server
+	staleProcessingAfter = 10 * time.Minute
+)
+
+// retryBackoff returns the delay before the next attempt, doubling per attempt
+func retryBackoff(attempt int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
+
+// RetryFailedChunks re-embeds failed chunks whose backoff has elapsed and
+// chunks stuck in processing. Returns the number of chunks that completed on
+// this pass.
+func (p *Processor) RetryFailedChunks(limit int) (int, error) {
+	claimed, err := database.ClaimRetryableChunks(limit, staleProcessingAfter)
+	if err != nil {
+		return 0, err
+	}
+	if len(claimed) == 0 {
+		return 0, nil
+	}
+
+	log.Printf("[RAG] Retrying %d failed or stalled chunks", len(claimed))
+
+	chunks := make([]*database.MeetingChunk, len(claimed))
+	for i := range claimed {
+		chunks[i] = &claimed[i]
+	}
+
+	return p.embedChunks(chunks), nil
+}
+
+// StartRetryWorker runs RetryFailedChunks on an interval until stop is closed
+func (p *Processor) StartRetryWorker(interval time.Duration, batchLimit int, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			completed, err := p.RetryFailedChunks(batchLimit)
+			if err != nil {
+				log.Printf("[RAG] Chunk retry pass failed: %v", err)
+				continue
+			}
+			if completed > 0 {
+				log.Printf("[RAG] Chunk retry pass completed %d chunks", completed)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
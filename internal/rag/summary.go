@@ -0,0 +1,62 @@
+package rag
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/llm"
+)
+
+// GenerateSourceSummary builds and stores a summary with chapters for an
+// uploaded video or recording transcript.
+func GenerateSourceSummary(sourceID, language, transcript string, llmClient *llm.Client) error {
+	if llmClient == nil {
+		return fmt.Errorf("llm client is nil")
+	}
+	if strings.TrimSpace(transcript) == "" {
+		return fmt.Errorf("empty transcript")
+	}
+
+	context := transcript
+	const maxContextChars = 12000
+	if len(context) > maxContextChars {
+		context = context[:maxContextChars] + "\n[Transcript truncated]"
+	}
+
+	prompt := "Summarize this transcript as JSON with keys: summary (string) and chapters (array of objects with title and summary, in order). Return JSON only."
+
+	answer, err := llmClient.Generate(prompt, context, 700, 0.3)
+	if err != nil {
+		return fmt.Errorf("summary generation failed: %w", err)
+	}
+
+	var parsed struct {
+		Summary  string                   `json:"summary"`
+		Chapters []database.SourceChapter `json:"chapters"`
+	}
+	if raw := extractJSONObject(answer); raw == "" || json.Unmarshal([]byte(raw), &parsed) != nil {
+		parsed.Summary = strings.TrimSpace(answer)
+		parsed.Chapters = nil
+	}
+	if strings.TrimSpace(parsed.Summary) == "" {
+		parsed.Summary = strings.TrimSpace(answer)
+	}
+
+	if err := database.SaveSourceSummary(sourceID, language, parsed.Summary, parsed.Chapters); err != nil {
+		return fmt.Errorf("failed to save source summary: %w", err)
+	}
+
+	return nil
+}
+
+// extractJSONObject returns the outermost JSON object in an LLM response
+func extractJSONObject(raw string) string {
+	start := strings.Index(raw, "{")
+	end := strings.LastIndex(raw, "}")
+	if start == -1 || end <= start {
+		return ""
+	}
+	return raw[start : end+1]
+}
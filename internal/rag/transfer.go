@@ -0,0 +1,249 @@
+package rag
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/parquet"
+)
+
+// ChunkRecord is one chunk in an export. JSONL exports hold one record per
+// line and can be imported into another meeting or environment.
+type ChunkRecord struct {
+	MeetingID          string     `json:"meetingId,omitempty"`
+	Language           string     `json:"language"`
+	ChunkIndex         int        `json:"chunkIndex"`
+	ChunkText          string     `json:"chunkText"`
+	ContentHash        string     `json:"contentHash,omitempty"`
+	SpeakerID          *string    `json:"speakerId,omitempty"`
+	SpeakerName        *string    `json:"speakerName,omitempty"`
+	StartTimestamp     *time.Time `json:"startTimestamp,omitempty"`
+	EndTimestamp       *time.Time `json:"endTimestamp,omitempty"`
+	StartOffsetSeconds *float64   `json:"startOffsetSeconds,omitempty"`
+	EndOffsetSeconds   *float64   `json:"endOffsetSeconds,omitempty"`
+	Embedding          []float32  `json:"embedding,omitempty"`
+}
+
+// ChunkImportResult summarizes an import
+type ChunkImportResult struct {
+	Imported  int      `json:"imported"`
+	Embedded  int      `json:"embedded"`  // stored with the embedding from the file
+	Pending   int      `json:"pending"`   // embedded in the background
+	Languages []string `json:"languages"` // languages whose chunks were replaced
+}
+
+// maxImportLineBytes bounds one JSONL line (a long chunk plus its embedding)
+const maxImportLineBytes = 4 << 20
+
+// ExportChunks loads a source's chunks with embeddings (all languages when
+// language is empty)
+func ExportChunks(meetingID, language string) ([]ChunkRecord, error) {
+	chunks, err := database.GetChunksForExport(meetingID, language)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]ChunkRecord, len(chunks))
+	for i, chunk := range chunks {
+		records[i] = ChunkRecord{
+			MeetingID:          chunk.MeetingID,
+			Language:           chunk.Language,
+			ChunkIndex:         chunk.ChunkIndex,
+			ChunkText:          chunk.ChunkText,
+			ContentHash:        chunk.ContentHash,
+			SpeakerID:          chunk.SpeakerID,
+			SpeakerName:        chunk.SpeakerName,
+			StartTimestamp:     chunk.StartTimestamp,
+			EndTimestamp:       chunk.EndTimestamp,
+			StartOffsetSeconds: chunk.StartOffsetSeconds,
+			EndOffsetSeconds:   chunk.EndOffsetSeconds,
+			Embedding:          chunk.Embedding,
+		}
+	}
+	return records, nil
+}
+
+// WriteChunksJSONL writes one JSON record per line
+func WriteChunksJSONL(w io.Writer, records []ChunkRecord) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteChunksParquet writes the records as a Parquet file for analytics tools
+func WriteChunksParquet(w io.Writer, records []ChunkRecord) error {
+	writer := parquet.NewWriter([]parquet.Field{
+		{Name: "meeting_id", Type: parquet.String},
+		{Name: "language", Type: parquet.String},
+		{Name: "chunk_index", Type: parquet.Int32},
+		{Name: "chunk_text", Type: parquet.String},
+		{Name: "content_hash", Type: parquet.String, Optional: true},
+		{Name: "speaker_id", Type: parquet.String, Optional: true},
+		{Name: "speaker_name", Type: parquet.String, Optional: true},
+		{Name: "start_timestamp", Type: parquet.Timestamp, Optional: true},
+		{Name: "end_timestamp", Type: parquet.Timestamp, Optional: true},
+		{Name: "start_offset_seconds", Type: parquet.Double, Optional: true},
+		{Name: "end_offset_seconds", Type: parquet.Double, Optional: true},
+		{Name: "embedding", Type: parquet.FloatList, Optional: true},
+	})
+
+	for _, record := range records {
+		var contentHash, embedding interface{}
+		if record.ContentHash != "" {
+			contentHash = record.ContentHash
+		}
+		if len(record.Embedding) > 0 {
+			embedding = record.Embedding
+		}
+		err := writer.Append(
+			record.MeetingID,
+			record.Language,
+			record.ChunkIndex,
+			record.ChunkText,
+			contentHash,
+			optionalValue(record.SpeakerID),
+			optionalValue(record.SpeakerName),
+			optionalValue(record.StartTimestamp),
+			optionalValue(record.EndTimestamp),
+			optionalValue(record.StartOffsetSeconds),
+			optionalValue(record.EndOffsetSeconds),
+			embedding,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := writer.WriteTo(w)
+	return err
+}
+
+// optionalValue dereferences a nullable field for the Parquet writer
+func optionalValue[T any](value *T) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// ReadChunksJSONL parses a JSONL export, reporting the line of the first bad record
+func ReadChunksJSONL(r io.Reader) ([]ChunkRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLineBytes)
+
+	var records []ChunkRecord
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var record ChunkRecord
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := validateChunkRecord(record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", line+1, err)
+	}
+	return records, nil
+}
+
+func validateChunkRecord(record ChunkRecord) error {
+	if strings.TrimSpace(record.Language) == "" {
+		return fmt.Errorf("language is required")
+	}
+	if record.ChunkIndex < 0 {
+		return fmt.Errorf("chunkIndex must not be negative")
+	}
+	if strings.TrimSpace(record.ChunkText) == "" {
+		return fmt.Errorf("chunkText is required")
+	}
+	if n := len(record.Embedding); n > 0 && n != database.EmbeddingDimensions {
+		return fmt.Errorf("embedding has %d dimensions, expected %d", n, database.EmbeddingDimensions)
+	}
+	return nil
+}
+
+// ImportChunks replaces the meeting's chunks for every language in records.
+// Records without an embedding are embedded in the background.
+func (p *Processor) ImportChunks(meetingID string, records []ChunkRecord) (*ChunkImportResult, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no chunks to import")
+	}
+
+	type key struct {
+		language string
+		index    int
+	}
+	seen := make(map[key]bool, len(records))
+	languages := make(map[string]bool)
+	chunks := make([]*database.MeetingChunk, len(records))
+	result := &ChunkImportResult{Imported: len(records)}
+	for i, record := range records {
+		if err := validateChunkRecord(record); err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", i+1, err)
+		}
+		k := key{record.Language, record.ChunkIndex}
+		if seen[k] {
+			return nil, fmt.Errorf("duplicate chunk %d for language %s", record.ChunkIndex, record.Language)
+		}
+		seen[k] = true
+		if !languages[record.Language] {
+			languages[record.Language] = true
+			result.Languages = append(result.Languages, record.Language)
+		}
+
+		chunks[i] = &database.MeetingChunk{
+			Language:           record.Language,
+			ChunkIndex:         record.ChunkIndex,
+			ChunkText:          record.ChunkText,
+			ContentHash:        record.ContentHash,
+			SpeakerID:          record.SpeakerID,
+			SpeakerName:        record.SpeakerName,
+			StartTimestamp:     record.StartTimestamp,
+			EndTimestamp:       record.EndTimestamp,
+			StartOffsetSeconds: record.StartOffsetSeconds,
+			EndOffsetSeconds:   record.EndOffsetSeconds,
+			Embedding:          record.Embedding,
+		}
+	}
+
+	if err := database.ReplaceMeetingChunks(meetingID, chunks); err != nil {
+		return nil, err
+	}
+
+	var pending []*database.MeetingChunk
+	for _, chunk := range chunks {
+		if chunk.ProcessingStatus == database.ChunkStatusPending {
+			pending = append(pending, chunk)
+		}
+	}
+	result.Pending = len(pending)
+	result.Embedded = result.Imported - result.Pending
+
+	log.Printf("[RAG] Imported %d chunks for source %s (%d to embed)", result.Imported, meetingID, result.Pending)
+	if len(pending) > 0 {
+		go func() {
+			completed := p.embedChunks(pending)
+			log.Printf("[RAG] Embedded %d/%d imported chunks for source %s", completed, len(pending), meetingID)
+		}()
+	}
+
+	return result, nil
+}
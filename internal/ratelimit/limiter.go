@@ -0,0 +1,284 @@
+package ratelimit
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"realtime-caption-translator/internal/metrics"
+)
+
+// ErrOverloaded is returned by TryAcquire when the caller's queue is full
+var ErrOverloaded = errors.New("service overloaded")
+
+// Priority orders waiting callers. Interactive callers are always dispatched
+// before batch callers.
+type Priority int
+
+const (
+	Interactive Priority = iota // user-facing requests such as RAG chat
+	Batch                       // backfills, minutes, summaries
+)
+
+func (p Priority) String() string {
+	if p == Batch {
+		return "batch"
+	}
+	return "interactive"
+}
+
+// Limiter bounds the request rate (token bucket) and the number of in-flight
+// calls to a backend service. Callers queue by priority; when MaxInFlight > 1
+// one slot is reserved for interactive callers so batch work cannot starve them.
+// A nil *Limiter imposes no limits.
+type Limiter struct {
+	name        string
+	maxInFlight int
+	maxQueued   int // per priority, for TryAcquire; 0 = unbounded
+	qps         float64
+	burst       float64
+
+	mu         sync.Mutex
+	tokens     float64
+	lastRefill time.Time
+	inFlight   int
+	inFlightBy [2]int
+	queues     [2][]chan struct{}
+	timerSet   bool
+
+	completed [2]int64
+	rejected  [2]int64
+	totalWait [2]time.Duration
+	maxWait   [2]time.Duration
+}
+
+// Stats is a snapshot of limiter metrics
+type Stats struct {
+	Name        string                   `json:"name"`
+	MaxInFlight int                      `json:"maxInFlight"`
+	MaxQueued   int                      `json:"maxQueued,omitempty"`
+	QPS         float64                  `json:"qps"`
+	InFlight    int                      `json:"inFlight"`
+	Priorities  map[string]PriorityStats `json:"priorities"`
+}
+
+// PriorityStats reports queueing for one priority lane
+type PriorityStats struct {
+	InFlight  int     `json:"inFlight"`
+	Queued    int     `json:"queued"`
+	Completed int64   `json:"completed"`
+	Rejected  int64   `json:"rejected"`
+	AvgWaitMs float64 `json:"avgWaitMs"`
+	MaxWaitMs float64 `json:"maxWaitMs"`
+}
+
+// New creates a limiter. maxInFlight <= 0 means unbounded concurrency and
+// qps <= 0 means no rate limit.
+func New(name string, maxInFlight int, qps float64) *Limiter {
+	burst := qps
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		name:        name,
+		maxInFlight: maxInFlight,
+		qps:         qps,
+		burst:       burst,
+		tokens:      burst,
+		lastRefill:  time.Now(),
+	}
+}
+
+// WithMaxQueued sets how many callers of each priority TryAcquire lets wait
+// before rejecting new ones (0 = unbounded) and returns the limiter
+func (l *Limiter) WithMaxQueued(maxQueued int) *Limiter {
+	l.mu.Lock()
+	l.maxQueued = maxQueued
+	l.mu.Unlock()
+	return l
+}
+
+// Acquire blocks until a call at the given priority may proceed and returns
+// the function that releases its slot.
+func (l *Limiter) Acquire(priority Priority) func() {
+	release, _ := l.acquire(priority, false)
+	return release
+}
+
+// TryAcquire is Acquire with backpressure: when the priority's queue already
+// holds MaxQueued callers it returns ErrOverloaded instead of waiting.
+func (l *Limiter) TryAcquire(priority Priority) (func(), error) {
+	return l.acquire(priority, true)
+}
+
+func (l *Limiter) acquire(priority Priority, bounded bool) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	if priority != Batch {
+		priority = Interactive
+	}
+
+	start := time.Now()
+	ready := make(chan struct{})
+
+	l.mu.Lock()
+	if bounded && l.maxQueued > 0 && len(l.queues[priority]) >= l.maxQueued {
+		l.rejected[priority]++
+		l.mu.Unlock()
+		metrics.ServiceRejected.Inc(l.name, priority.String())
+		return nil, ErrOverloaded
+	}
+	l.queues[priority] = append(l.queues[priority], ready)
+	l.dispatchLocked()
+	l.mu.Unlock()
+
+	metrics.ServiceQueued.Inc(l.name, priority.String())
+	<-ready
+	metrics.ServiceQueued.Dec(l.name, priority.String())
+
+	wait := time.Since(start)
+	metrics.ServiceQueueWait.Observe(wait.Seconds(), l.name, priority.String())
+	l.mu.Lock()
+	l.totalWait[priority] += wait
+	if wait > l.maxWait[priority] {
+		l.maxWait[priority] = wait
+	}
+	l.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.inFlight--
+			l.inFlightBy[priority]--
+			l.completed[priority]++
+			l.dispatchLocked()
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// Stats returns a snapshot of the limiter's metrics
+func (l *Limiter) Stats() Stats {
+	if l == nil {
+		return Stats{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := Stats{
+		Name:        l.name,
+		MaxInFlight: l.maxInFlight,
+		MaxQueued:   l.maxQueued,
+		QPS:         l.qps,
+		InFlight:    l.inFlight,
+		Priorities:  make(map[string]PriorityStats, 2),
+	}
+	for _, p := range []Priority{Interactive, Batch} {
+		ps := PriorityStats{
+			InFlight:  l.inFlightBy[p],
+			Queued:    len(l.queues[p]),
+			Completed: l.completed[p],
+			Rejected:  l.rejected[p],
+			MaxWaitMs: float64(l.maxWait[p].Microseconds()) / 1000,
+		}
+		if started := l.completed[p] + int64(l.inFlightBy[p]); started > 0 {
+			ps.AvgWaitMs = float64(l.totalWait[p].Microseconds()) / 1000 / float64(started)
+		}
+		stats.Priorities[p.String()] = ps
+	}
+	return stats
+}
+
+// dispatchLocked admits queued callers while slots and tokens allow,
+// interactive first. Must be called with l.mu held.
+func (l *Limiter) dispatchLocked() {
+	l.refillLocked()
+
+	for {
+		priority, ok := l.nextLocked()
+		if !ok {
+			return
+		}
+
+		if l.qps > 0 && l.tokens < 1 {
+			l.scheduleRefillLocked()
+			return
+		}
+		if l.qps > 0 {
+			l.tokens--
+		}
+
+		ready := l.queues[priority][0]
+		l.queues[priority] = l.queues[priority][1:]
+		l.inFlight++
+		l.inFlightBy[priority]++
+		close(ready)
+	}
+}
+
+// nextLocked picks the priority lane allowed to run next, if any
+func (l *Limiter) nextLocked() (Priority, bool) {
+	if len(l.queues[Interactive]) > 0 && (l.maxInFlight <= 0 || l.inFlight < l.maxInFlight) {
+		return Interactive, true
+	}
+	if len(l.queues[Batch]) == 0 {
+		return 0, false
+	}
+
+	batchLimit := l.maxInFlight
+	if batchLimit > 1 {
+		batchLimit-- // keep one slot free for interactive callers
+	}
+	if batchLimit <= 0 || l.inFlight < batchLimit {
+		return Batch, true
+	}
+	return 0, false
+}
+
+func (l *Limiter) refillLocked() {
+	if l.qps <= 0 {
+		return
+	}
+	now := time.Now()
+	l.tokens += now.Sub(l.lastRefill).Seconds() * l.qps
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.lastRefill = now
+}
+
+func (l *Limiter) scheduleRefillLocked() {
+	if l.timerSet {
+		return
+	}
+	l.timerSet = true
+
+	delay := time.Duration((1 - l.tokens) / l.qps * float64(time.Second))
+	time.AfterFunc(delay, func() {
+		l.mu.Lock()
+		l.timerSet = false
+		l.dispatchLocked()
+		l.mu.Unlock()
+	})
+}
+
+// ReleaseOnClose returns body with release called when it is closed, so a
+// slot is held until a streamed response has been read
+func ReleaseOnClose(body io.ReadCloser, release func()) io.ReadCloser {
+	return &releasingBody{ReadCloser: body, release: release}
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,250 @@
+// Package replica spreads calls to a model service across its replicas.
+// Replicas can be registered and removed while the server runs (typically by
+// an autoscaler), so a long upload job picks up new capacity on its next call
+// and a replica that is still starting or is being drained is skipped.
+package replica
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// downCooldown is how long a replica that refused a call is skipped
+const downCooldown = 15 * time.Second
+
+// Pool is a round-robin set of base URLs for one service. A nil *Pool sends
+// every request to the client's own base URL.
+type Pool struct {
+	name string
+
+	mu        sync.Mutex
+	urls      []string
+	next      int
+	downUntil map[string]time.Time
+	calls     map[string]int64
+	failovers int64
+}
+
+// Stats is a snapshot of a pool
+type Stats struct {
+	Name      string         `json:"name"`
+	Replicas  []ReplicaStats `json:"replicas"`
+	Failovers int64          `json:"failovers"`
+}
+
+// ReplicaStats reports one replica
+type ReplicaStats struct {
+	URL   string `json:"url"`
+	Up    bool   `json:"up"`
+	Calls int64  `json:"calls"`
+}
+
+// NewPool creates a pool with the given base URLs
+func NewPool(name string, urls ...string) *Pool {
+	p := &Pool{
+		name:      name,
+		downUntil: make(map[string]time.Time),
+		calls:     make(map[string]int64),
+	}
+	for _, u := range urls {
+		p.Register(u)
+	}
+	return p
+}
+
+// ParseURLs splits a comma-separated list of base URLs
+func ParseURLs(list string) []string {
+	var urls []string
+	for _, u := range strings.Split(list, ",") {
+		if u = normalize(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// Register adds a replica; it reports false if it was already present
+func (p *Pool) Register(baseURL string) bool {
+	baseURL = normalize(baseURL)
+	if baseURL == "" {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, existing := range p.urls {
+		if existing == baseURL {
+			delete(p.downUntil, baseURL) // re-registering marks it ready again
+			return false
+		}
+	}
+	p.urls = append(p.urls, baseURL)
+	return true
+}
+
+// Deregister removes a replica; it reports false if it was not present.
+// Calls already sent to it finish normally.
+func (p *Pool) Deregister(baseURL string) bool {
+	baseURL = normalize(baseURL)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, existing := range p.urls {
+		if existing == baseURL {
+			p.urls = append(p.urls[:i:i], p.urls[i+1:]...)
+			delete(p.downUntil, baseURL)
+			delete(p.calls, baseURL)
+			return true
+		}
+	}
+	return false
+}
+
+// Size returns the number of registered replicas
+func (p *Pool) Size() int {
+	if p == nil {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.urls)
+}
+
+// URLs returns the registered replicas
+func (p *Pool) URLs() []string {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.urls...)
+}
+
+// Stats returns a snapshot of the pool
+func (p *Pool) Stats() Stats {
+	if p == nil {
+		return Stats{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := Stats{Name: p.name, Replicas: make([]ReplicaStats, 0, len(p.urls)), Failovers: p.failovers}
+	now := time.Now()
+	for _, u := range p.urls {
+		stats.Replicas = append(stats.Replicas, ReplicaStats{
+			URL:   u,
+			Up:    !now.Before(p.downUntil[u]),
+			Calls: p.calls[u],
+		})
+	}
+	return stats
+}
+
+// pick returns the next replica not in tried, preferring replicas that are up
+func (p *Pool) pick(tried map[string]bool) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	fallback := ""
+	for i := 0; i < len(p.urls); i++ {
+		u := p.urls[(p.next+i)%len(p.urls)]
+		if tried[u] {
+			continue
+		}
+		if now.Before(p.downUntil[u]) {
+			if fallback == "" {
+				fallback = u
+			}
+			continue
+		}
+		p.next = (p.next + i + 1) % len(p.urls)
+		p.calls[u]++
+		return u, true
+	}
+	if fallback != "" {
+		p.calls[fallback]++
+		return fallback, true
+	}
+	return "", false
+}
+
+func (p *Pool) markDown(baseURL string) {
+	p.mu.Lock()
+	p.downUntil[baseURL] = time.Now().Add(downCooldown)
+	p.failovers++
+	p.mu.Unlock()
+}
+
+// Do sends req, which was built against baseURL, to a replica. When a
+// replica refuses the connection or answers 502/503 (starting up or being
+// drained), the request is retried on another replica if its body can be
+// replayed. Other errors, including timeouts, are returned as they are since
+// the replica may still be working on the request.
+func (p *Pool) Do(client *http.Client, baseURL string, req *http.Request) (*http.Response, error) {
+	if p == nil || p.Size() == 0 {
+		return client.Do(req)
+	}
+
+	suffix := strings.TrimPrefix(req.URL.String(), strings.TrimRight(baseURL, "/"))
+	tried := make(map[string]bool)
+	for {
+		replicaURL, ok := p.pick(tried)
+		if !ok {
+			return client.Do(req)
+		}
+		tried[replicaURL] = true
+
+		attempt, err := withTarget(req, replicaURL+suffix)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(attempt)
+		if !unavailable(resp, err) {
+			return resp, err
+		}
+
+		p.markDown(replicaURL)
+		if req.GetBody == nil || len(tried) >= p.Size() {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+}
+
+// withTarget returns a copy of req aimed at target with a fresh body
+func withTarget(req *http.Request, target string) (*http.Request, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	attempt := req.Clone(req.Context())
+	attempt.URL = u
+	attempt.Host = ""
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		attempt.Body = body
+	}
+	return attempt, nil
+}
+
+// unavailable reports whether a call failed before the replica took it on
+func unavailable(resp *http.Response, err error) bool {
+	if err != nil {
+		var opErr *net.OpError
+		return errors.Is(err, syscall.ECONNREFUSED) || (errors.As(err, &opErr) && opErr.Op == "dial")
+	}
+	return resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable
+}
+
+func normalize(baseURL string) string {
+	return strings.TrimRight(strings.TrimSpace(baseURL), "/")
+}
@@ -0,0 +1,86 @@
+// Package rerank is a client for the cross-encoder reranking endpoint of the
+// embedding service. A cross-encoder scores a question against each passage
+// together, which orders retrieved chunks more precisely than the cosine
+// similarity of separately computed embeddings.
+package rerank
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"realtime-caption-translator/internal/httpx"
+)
+
+// Client is an HTTP client for the reranking service
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+
+	// Breaker, when set, retries transient failures and stops calling the
+	// service while it is unhealthy
+	Breaker *httpx.Breaker
+}
+
+// New creates a new reranking service client
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL: baseURL,
+		HTTP: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Request is a question with the passages to score against it
+type Request struct {
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+}
+
+// Response holds one relevance score per passage, in request order
+type Response struct {
+	Scores []float64 `json:"scores"`
+	Model  string    `json:"model"`
+}
+
+// Rerank scores each document's relevance to query; higher is more relevant.
+// Scores are returned in the order of documents.
+func (c *Client) Rerank(query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+
+	jsonData, err := json.Marshal(Request{Query: query, Documents: documents})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.BaseURL+"/rerank", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.Breaker.Do(req, c.HTTP.Do)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rerank service returned status %d", resp.StatusCode)
+	}
+
+	var result Response
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(result.Scores) != len(documents) {
+		return nil, fmt.Errorf("rerank service returned %d scores for %d documents", len(result.Scores), len(documents))
+	}
+
+	return result.Scores, nil
+}
@@ -0,0 +1,156 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// cueSessionTTL is how long a logical session's cue counter survives
+// after its last connection closed
+const cueSessionTTL = 30 * time.Minute
+
+// cueHistory is how many recent finals a logical session keeps to replay
+// to a client that resumes it
+const cueHistory = 100
+
+// cueFinal is a final caption kept for replay
+type cueFinal struct {
+	ID          int
+	Text        string
+	Translation string
+}
+
+// cueCounter numbers the final captions of one logical session. It outlives
+// individual /ws connections so IDs keep increasing across reconnects and can
+// be used as stable cue identifiers.
+type cueCounter struct {
+	mu       sync.Mutex
+	next     int
+	lastUsed time.Time
+	holders  int        // connections currently numbering with this counter
+	finals   []cueFinal // the latest finals, oldest first
+}
+
+// Next returns the next cue ID
+func (c *cueCounter) Next() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.next
+	c.next++
+	c.lastUsed = time.Now()
+	return id
+}
+
+// Last returns the most recently issued cue ID, or 0 if none was issued
+func (c *cueCounter) Last() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.next - 1
+}
+
+// Remember keeps a final caption, or its translation once the final is
+// kept, for replay to a resuming client
+func (c *cueCounter) Remember(id int, text, translation string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := len(c.finals) - 1; i >= 0; i-- {
+		if c.finals[i].ID == id {
+			if text != "" {
+				c.finals[i].Text = text
+			}
+			if translation != "" {
+				c.finals[i].Translation = translation
+			}
+			return
+		}
+	}
+	c.finals = append(c.finals, cueFinal{ID: id, Text: text, Translation: translation})
+	if len(c.finals) > cueHistory {
+		c.finals = c.finals[len(c.finals)-cueHistory:]
+	}
+}
+
+// Since returns the kept finals after cue ID since, oldest first
+func (c *cueCounter) Since(since int) []cueFinal {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var finals []cueFinal
+	for _, final := range c.finals {
+		if final.ID > since {
+			finals = append(finals, final)
+		}
+	}
+	return finals
+}
+
+func (c *cueCounter) acquire() {
+	c.mu.Lock()
+	c.holders++
+	c.lastUsed = time.Now()
+	c.mu.Unlock()
+}
+
+// Release marks the end of a connection's use of the counter; the expiry
+// clock starts once no connection holds it
+func (c *cueCounter) Release() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.holders--
+	c.lastUsed = time.Now()
+	c.mu.Unlock()
+}
+
+// expired reports whether no connection holds the counter and it has been
+// released for longer than the TTL
+func (c *cueCounter) expired(now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.holders <= 0 && now.Sub(c.lastUsed) > cueSessionTTL
+}
+
+// cueStore holds the cue counters of recent logical sessions by resume token
+type cueStore struct {
+	mu       sync.Mutex
+	counters map[string]*cueCounter
+}
+
+func newCueStore() *cueStore {
+	return &cueStore{counters: make(map[string]*cueCounter)}
+}
+
+// Resume returns the counter for token and holds it until Release. An
+// unknown or expired token starts a new logical session under a fresh token.
+func (s *cueStore) Resume(token string) (string, *cueCounter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for t, counter := range s.counters {
+		if counter.expired(now) {
+			delete(s.counters, t)
+		}
+	}
+
+	if counter, ok := s.counters[token]; ok && token != "" {
+		counter.acquire()
+		return token, counter
+	}
+
+	token = newResumeToken()
+	counter := &cueCounter{next: 1, lastUsed: now, holders: 1}
+	s.counters[token] = counter
+	return token, counter
+}
+
+func newResumeToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
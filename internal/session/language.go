@@ -0,0 +1,100 @@
+package session
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// languageSwitchConfirmations is how many detections in a row must agree on
+// a new language before the session switches to it
+const languageSwitchConfirmations = 2
+
+// languageWatcher re-detects the spoken language of a session that was
+// started with a fixed source language. ASR is told that language, so when
+// the speaker changes language the transcript degrades instead of following;
+// the watcher notices the change and the session switches its source
+// language. Detections below the confidence threshold are ignored.
+type languageWatcher struct {
+	interval   time.Duration
+	confidence float64
+
+	mu        sync.Mutex
+	checking  bool
+	lastCheck time.Time
+	candidate string
+	streak    int
+}
+
+func newLanguageWatcher(interval time.Duration, confidence float64) *languageWatcher {
+	return &languageWatcher{interval: interval, confidence: confidence}
+}
+
+// due reports whether a detection should run now and, if so, marks one as
+// in flight; finish must be called when it completes
+func (w *languageWatcher) due(now time.Time, sourceLang string) bool {
+	if w.interval <= 0 || !fixedLanguage(sourceLang) {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.checking || now.Sub(w.lastCheck) < w.interval {
+		return false
+	}
+	w.checking = true
+	w.lastCheck = now
+	return true
+}
+
+func (w *languageWatcher) finish() {
+	w.mu.Lock()
+	w.checking = false
+	w.mu.Unlock()
+}
+
+// observe records a detection and returns the language to switch to, or ""
+// to keep the current one
+func (w *languageWatcher) observe(current, detected string, probability float64) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	detected = strings.ToLower(strings.TrimSpace(detected))
+	if detected == "" || detected == "unknown" || probability < w.confidence {
+		return ""
+	}
+	if detected == baseLanguageCode(current) {
+		w.candidate, w.streak = "", 0
+		return ""
+	}
+	if detected != w.candidate {
+		w.candidate, w.streak = detected, 0
+	}
+	w.streak++
+	if w.streak < languageSwitchConfirmations {
+		return ""
+	}
+	w.candidate, w.streak = "", 0
+	return detected
+}
+
+// reset forgets pending detections, e.g. when the client sets a language
+func (w *languageWatcher) reset() {
+	w.mu.Lock()
+	w.candidate, w.streak = "", 0
+	w.mu.Unlock()
+}
+
+// fixedLanguage reports whether the session pins ASR to a language; with
+// auto-detection ASR already follows the speaker
+func fixedLanguage(lang string) bool {
+	return lang != "" && lang != "auto"
+}
+
+// baseLanguageCode reduces a tag such as "en-US" to Whisper's language code
+func baseLanguageCode(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
@@ -0,0 +1,69 @@
+package session
+
+import (
+	"fmt"
+	"time"
+)
+
+// Latency holds the knobs that trade caption accuracy for speed. A longer
+// window gives ASR more context; a shorter poll interval and finalize delay
+// show captions sooner at the cost of more ASR calls and less settled
+// finals. Zero fields keep the server defaults.
+type Latency struct {
+	WindowSeconds   int `json:"windowSeconds,omitempty"`   // audio transcribed per poll, or the longest recording chunk
+	PollIntervalMs  int `json:"pollIntervalMs,omitempty"`  // how often the window is transcribed or chunks are picked up
+	FinalizeAfterMs int `json:"finalizeAfterMs,omitempty"` // unchanged text, or silence when recording, that ends a caption
+}
+
+// Bounds of the latency knobs a client may set
+const (
+	MinWindowSeconds   = 2
+	MaxWindowSeconds   = 30
+	MinPollIntervalMs  = 200
+	MaxPollIntervalMs  = 5000
+	MinFinalizeAfterMs = 200
+	MaxFinalizeAfterMs = 10000
+)
+
+// Validate reports the first knob outside its bounds
+func (l Latency) Validate() error {
+	if l.WindowSeconds != 0 && (l.WindowSeconds < MinWindowSeconds || l.WindowSeconds > MaxWindowSeconds) {
+		return fmt.Errorf("windowSeconds must be between %d and %d", MinWindowSeconds, MaxWindowSeconds)
+	}
+	if l.PollIntervalMs != 0 && (l.PollIntervalMs < MinPollIntervalMs || l.PollIntervalMs > MaxPollIntervalMs) {
+		return fmt.Errorf("pollIntervalMs must be between %d and %d", MinPollIntervalMs, MaxPollIntervalMs)
+	}
+	if l.FinalizeAfterMs != 0 && (l.FinalizeAfterMs < MinFinalizeAfterMs || l.FinalizeAfterMs > MaxFinalizeAfterMs) {
+		return fmt.Errorf("finalizeAfterMs must be between %d and %d", MinFinalizeAfterMs, MaxFinalizeAfterMs)
+	}
+	return nil
+}
+
+// IsZero reports whether no knob is set
+func (l Latency) IsZero() bool {
+	return l == Latency{}
+}
+
+// Over returns base with the knobs set in l, clamped to their bounds
+func (l Latency) Over(base Latency) Latency {
+	if l.WindowSeconds != 0 {
+		base.WindowSeconds = min(max(l.WindowSeconds, MinWindowSeconds), MaxWindowSeconds)
+	}
+	if l.PollIntervalMs != 0 {
+		base.PollIntervalMs = min(max(l.PollIntervalMs, MinPollIntervalMs), MaxPollIntervalMs)
+	}
+	if l.FinalizeAfterMs != 0 {
+		base.FinalizeAfterMs = min(max(l.FinalizeAfterMs, MinFinalizeAfterMs), MaxFinalizeAfterMs)
+	}
+	return base
+}
+
+// PollInterval returns the poll interval as a duration
+func (l Latency) PollInterval() time.Duration {
+	return time.Duration(l.PollIntervalMs) * time.Millisecond
+}
+
+// FinalizeAfter returns the finalize delay as a duration
+func (l Latency) FinalizeAfter() time.Duration {
+	return time.Duration(l.FinalizeAfterMs) * time.Millisecond
+}
@@ -0,0 +1,179 @@
+package session
+
+import (
+	"strings"
+
+	"realtime-caption-translator/internal/asr"
+)
+
+// Merge settings
+const (
+	mergeKeepNew = 0.4 // a word only the newer window heard needs this probability
+	mergeKeepOld = 0.8 // a word only the older window heard needs this probability
+)
+
+// mergedWord is a word of the running hypothesis with the combined
+// probability of every window that agreed on it
+type mergedWord struct {
+	text        string // as recognized, with Whisper's leading space
+	norm        string
+	probability float64
+}
+
+// hypothesisMerger combines successive transcriptions of the rolling window.
+// Each poll re-transcribes mostly the same audio, and the latest text is not
+// always the best: a word the model was sure of last time can come back as a
+// low-confidence guess. The new words are aligned with the running
+// hypothesis and, where the two disagree, the more confident word wins.
+// Words both windows agree on grow more confident with every poll.
+type hypothesisMerger struct {
+	words []mergedWord
+}
+
+// Merge folds a new window transcription into the running hypothesis and
+// returns the merged text. Transcripts without word probabilities replace
+// the hypothesis.
+func (m *hypothesisMerger) Merge(result *asr.WordTranscript) string {
+	if len(result.Words) == 0 {
+		m.words = nil
+		return strings.TrimSpace(result.Text)
+	}
+	next := make([]mergedWord, 0, len(result.Words))
+	for _, word := range result.Words {
+		if norm := normalizeWord(strings.TrimSpace(word.Text)); norm != "" {
+			next = append(next, mergedWord{text: word.Text, norm: norm, probability: word.Probability})
+		}
+	}
+	m.words = mergeWords(m.words, next)
+	return m.Text()
+}
+
+// Text returns the running hypothesis
+func (m *hypothesisMerger) Text() string {
+	var b strings.Builder
+	for _, word := range m.words {
+		b.WriteString(word.text)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Reset forgets the hypothesis, e.g. once it is finalized and the window
+// cleared
+func (m *hypothesisMerger) Reset() {
+	m.words = nil
+}
+
+// alignment operations, as seen from the merge of old into new
+const (
+	alignMatch = iota
+	alignSubstitute
+	alignOld // word only in the old hypothesis
+	alignNew // word only in the new transcription
+)
+
+// mergeWords aligns old and next by edit distance over normalized words and
+// builds the merged hypothesis. Old words before the first agreement have
+// rolled out of the window and old words after the last one are not
+// confirmed by the longer audio, so both are dropped; next decides the span.
+func mergeWords(old, next []mergedWord) []mergedWord {
+	if len(old) == 0 || len(next) == 0 {
+		return next
+	}
+	ops := alignWords(old, next)
+
+	first, last := -1, -1
+	for k, op := range ops {
+		if op.kind == alignMatch {
+			if first < 0 {
+				first = k
+			}
+			last = k
+		}
+	}
+	if first < 0 {
+		return next // nothing in common: the window moved on
+	}
+
+	merged := make([]mergedWord, 0, len(next))
+	for k, op := range ops {
+		inside := k > first && k < last
+		switch op.kind {
+		case alignMatch:
+			word := next[op.j]
+			word.probability = combineProbability(old[op.i].probability, word.probability)
+			merged = append(merged, word)
+		case alignSubstitute:
+			if inside && old[op.i].probability > next[op.j].probability {
+				merged = append(merged, old[op.i])
+			} else {
+				merged = append(merged, next[op.j])
+			}
+		case alignNew:
+			if !inside || next[op.j].probability >= mergeKeepNew {
+				merged = append(merged, next[op.j])
+			}
+		case alignOld:
+			if inside && old[op.i].probability >= mergeKeepOld {
+				merged = append(merged, old[op.i])
+			}
+		}
+	}
+	return merged
+}
+
+// combineProbability is the chance that at least one of two independent
+// recognitions of the same word is right
+func combineProbability(a, b float64) float64 {
+	return 1 - (1-a)*(1-b)
+}
+
+type alignOp struct {
+	kind int
+	i, j int // indexes into old and next; unused side is -1
+}
+
+// alignWords returns the operations of a minimum edit distance alignment of
+// old and next, in order
+func alignWords(old, next []mergedWord) []alignOp {
+	n, m := len(old), len(next)
+	cost := make([][]int, n+1)
+	for i := range cost {
+		cost[i] = make([]int, m+1)
+		cost[i][0] = i
+	}
+	for j := 0; j <= m; j++ {
+		cost[0][j] = j
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			sub := cost[i-1][j-1]
+			if old[i-1].norm != next[j-1].norm {
+				sub++
+			}
+			cost[i][j] = min(sub, cost[i-1][j]+1, cost[i][j-1]+1)
+		}
+	}
+
+	var ops []alignOp
+	i, j := n, m
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && old[i-1].norm == next[j-1].norm && cost[i][j] == cost[i-1][j-1]:
+			ops = append(ops, alignOp{kind: alignMatch, i: i - 1, j: j - 1})
+			i, j = i-1, j-1
+		case i > 0 && j > 0 && cost[i][j] == cost[i-1][j-1]+1:
+			ops = append(ops, alignOp{kind: alignSubstitute, i: i - 1, j: j - 1})
+			i, j = i-1, j-1
+		case j > 0 && cost[i][j] == cost[i][j-1]+1:
+			ops = append(ops, alignOp{kind: alignNew, i: -1, j: j - 1})
+			j--
+		default:
+			ops = append(ops, alignOp{kind: alignOld, i: i - 1, j: -1})
+			i--
+		}
+	}
+	for l, r := 0, len(ops)-1; l < r; l, r = l+1, r-1 {
+		ops[l], ops[r] = ops[r], ops[l]
+	}
+	return ops
+}
@@ -2,7 +2,9 @@ package session
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"log"
 	"math"
@@ -12,8 +14,12 @@ import (
 	"github.com/gorilla/websocket"
 
 	"realtime-caption-translator/internal/asr"
-	"realtime-caption-translator/internal/audio"
+	"realtime-caption-translator/internal/audio/vad"
+	"realtime-caption-translator/internal/audioarchive"
+	"realtime-caption-translator/internal/hooks"
+	"realtime-caption-translator/internal/keepalive"
 	"realtime-caption-translator/internal/progress"
+	"realtime-caption-translator/internal/quota"
 	"realtime-caption-translator/internal/translate"
 )
 
@@ -23,30 +29,54 @@ type RecordingSession struct {
 	SourceLang string
 	TargetLang string
 	SampleRate int
-	WindowSize int // samples per chunk
+	WindowSize int     // maximum samples per chunk; chunks normally end at pauses
+	MinRMS     float64 // RMS a chunk needs to be transcribed
+
+	pollInterval time.Duration // how often queued chunks are picked up
+	resumeToken  string        // lets a reconnecting client reattach
+	resumeGrace  time.Duration // how long a dropped connection may reattach; 0 finishes at once
 
 	asrClient   *asr.Client
 	translator  translate.Translator
 	progressMgr *progress.Manager
+	archive     *audioarchive.Archive
+	meter       *quota.Meter
 
 	mu           sync.Mutex
 	isRecording  bool
 	isStopped    bool
-	ring         *audio.Ring
+	segmenter    *vad.Segmenter
+	levelSentAt  time.Time
 	chunks       [][]int16 // queued audio chunks
 	results      []TranscriptItem
 	processedIdx int
 	totalChunks  int
-
-	wg sync.WaitGroup
+	quotaWarned  bool // the client was told the audio quota is used up
+
+	// While the connection is dropped, detached is the timer that finishes
+	// the recording unless the client resumes; connGen tells the current
+	// connection from one that was replaced
+	detached *time.Timer
+	connGen  int
+	finished bool
+	done     chan struct{} // closed once the recording is finished
+
+	wg      sync.WaitGroup
+	writeMu sync.Mutex      // serializes writes to the WebSocket
+	conn    *websocket.Conn // the attached connection, nil while dropped; guarded by writeMu
 }
 
+// ErrResumeRejected is returned when a recording cannot be resumed: the
+// token does not match or the recording already finished
+var ErrResumeRejected = errors.New("recording cannot be resumed")
+
 // TranscriptItem represents a processed audio segment
 type TranscriptItem struct {
 	Index       int       `json:"index"`
 	Original    string    `json:"original"`
 	Translation string    `json:"translation"`
 	Timestamp   time.Time `json:"timestamp"`
+	AudioRef    int64     `json:"audioRef,omitempty"` // archived chunk audio, when archiving is on
 }
 
 // RecordingConfig for creating new recording sessions
@@ -59,31 +89,89 @@ type RecordingConfig struct {
 	ProgressMgr   *progress.Manager
 	SampleRate    int
 	WindowSeconds int
+
+	// PollInterval is how often queued chunks are picked up; 0 uses
+	// DefaultRecordingPollInterval
+	PollInterval time.Duration
+
+	// ResumeGrace is how long a dropped connection may be resumed before
+	// the recording is finished; 0 finishes it when the connection drops
+	ResumeGrace time.Duration
+
+	// Archive, when set, keeps each transcribed chunk's audio
+	Archive *audioarchive.Archive
+
+	// Meter, when set, charges each chunk sent for transcription to a
+	// user's daily audio quota; chunks over the quota are skipped
+	Meter *quota.Meter
+
+	// VAD sets speech detection; SampleRate and MaxChunk come from the
+	// fields above
+	VAD vad.Config
+	// MinRMS is the RMS a chunk needs to be transcribed, raised with the
+	// noise floor; 0 uses DefaultMinRMS
+	MinRMS float64
 }
 
+// DefaultMinRMS is the RMS a recording chunk needs to be transcribed
+const DefaultMinRMS = 0.01
+
+// DefaultRecordingPollInterval is how often a recording picks up queued chunks
+const DefaultRecordingPollInterval = 500 * time.Millisecond
+
 // NewRecordingSession creates a new recording session
 func NewRecordingSession(cfg RecordingConfig) *RecordingSession {
 	windowSize := cfg.SampleRate * cfg.WindowSeconds
 
+	// Chunks end at pauses in speech, with the window as the longest chunk
+	vadConfig := cfg.VAD
+	vadConfig.SampleRate = cfg.SampleRate
+	vadConfig.MaxChunk = time.Duration(cfg.WindowSeconds) * time.Second
+	segmenter := vad.New(vadConfig)
+
+	minRMS := cfg.MinRMS
+	if minRMS <= 0 {
+		minRMS = DefaultMinRMS
+	}
+	pollInterval := cfg.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultRecordingPollInterval
+	}
+
 	return &RecordingSession{
-		ID:          cfg.SessionID,
-		SourceLang:  cfg.SourceLang,
-		TargetLang:  cfg.TargetLang,
-		SampleRate:  cfg.SampleRate,
-		WindowSize:  windowSize,
-		asrClient:   cfg.ASRClient,
-		translator:  cfg.Translator,
-		progressMgr: cfg.ProgressMgr,
-		ring:        audio.NewRing(windowSize),
-		chunks:      make([][]int16, 0),
-		results:     make([]TranscriptItem, 0),
+		ID:           cfg.SessionID,
+		SourceLang:   cfg.SourceLang,
+		TargetLang:   cfg.TargetLang,
+		SampleRate:   cfg.SampleRate,
+		WindowSize:   windowSize,
+		MinRMS:       minRMS,
+		pollInterval: pollInterval,
+		resumeToken:  newResumeToken(),
+		resumeGrace:  cfg.ResumeGrace,
+		done:         make(chan struct{}),
+		asrClient:    cfg.ASRClient,
+		translator:   cfg.Translator,
+		progressMgr:  cfg.ProgressMgr,
+		archive:      cfg.Archive,
+		meter:        cfg.Meter,
+		segmenter:    segmenter,
+		chunks:       make([][]int16, 0),
+		results:      make([]TranscriptItem, 0),
 	}
 }
 
+// ResumeToken returns the token a client sends to reattach to the recording
+func (rs *RecordingSession) ResumeToken() string {
+	return rs.resumeToken
+}
+
+// Done is closed once the recording is finished and its chunks processed
+func (rs *RecordingSession) Done() <-chan struct{} {
+	return rs.done
+}
+
 // HandleWebSocket handles the WebSocket connection for live audio streaming
 func (rs *RecordingSession) HandleWebSocket(conn *websocket.Conn) {
-	defer conn.Close()
-
 	rs.mu.Lock()
 	rs.isRecording = true
 	rs.mu.Unlock()
@@ -92,15 +180,91 @@ func (rs *RecordingSession) HandleWebSocket(conn *websocket.Conn) {
 
 	// Start async processor
 	rs.wg.Add(1)
-	go rs.processQueue(conn)
+	go rs.processQueue()
+
+	rs.serve(conn)
+}
+
+// Resume reattaches a reconnecting client to a recording whose connection
+// dropped, or replaces a connection that has not noticed it is dead yet.
+// Results after index since that the client missed are sent again, marked
+// "replayed", before streaming continues.
+func (rs *RecordingSession) Resume(conn *websocket.Conn, token string, since int) error {
+	rs.mu.Lock()
+	if token == "" || token != rs.resumeToken || !rs.isRecording || rs.finished {
+		rs.mu.Unlock()
+		return ErrResumeRejected
+	}
+	if rs.detached != nil {
+		if !rs.detached.Stop() {
+			// The grace period ran out and the recording is finishing
+			rs.mu.Unlock()
+			return ErrResumeRejected
+		}
+		rs.detached = nil
+	}
+	rs.connGen++
+	rs.mu.Unlock()
+
+	// Results are stored and sent under writeMu, so none is missed or sent
+	// twice between the replay and the switch to the new connection
+	rs.writeMu.Lock()
+	if rs.conn != nil {
+		rs.conn.Close()
+	}
+	rs.conn = conn
+	rs.mu.Lock()
+	var missed []TranscriptItem
+	for _, item := range rs.results {
+		if item.Index > since {
+			missed = append(missed, item)
+		}
+	}
+	processed := rs.processedIdx
+	rs.mu.Unlock()
+	_ = conn.WriteJSON(map[string]interface{}{
+		"type":      "resumed",
+		"processed": processed,
+		"missed":    len(missed),
+	})
+	for _, item := range missed {
+		msg := item.message()
+		msg["replayed"] = true
+		_ = conn.WriteJSON(msg)
+	}
+	rs.writeMu.Unlock()
+
+	log.Printf("[Recording %s] WebSocket resumed (%d missed results)", rs.ID, len(missed))
+	rs.serve(conn)
+	return nil
+}
+
+// serve reads audio from conn until it closes. A recording that was stopped
+// or closed normally is finished; a dropped one waits resumeGrace for the
+// client to resume before it is finished.
+func (rs *RecordingSession) serve(conn *websocket.Conn) {
+	defer conn.Close()
+
+	rs.mu.Lock()
+	gen := rs.connGen
+	rs.mu.Unlock()
+	rs.writeMu.Lock()
+	rs.conn = conn
+	rs.writeMu.Unlock()
+
+	alive := keepalive.Start(conn)
+	defer alive.Stop()
 
 	// Read audio data from WebSocket
+	var readErr error
 	for {
 		_, data, err := conn.ReadMessage()
 		if err != nil {
 			log.Printf("[Recording %s] WebSocket read error: %v", rs.ID, err)
+			readErr = err
 			break
 		}
+		alive.Touch()
 
 		if len(data) == 0 {
 			continue
@@ -112,40 +276,80 @@ func (rs *RecordingSession) HandleWebSocket(conn *websocket.Conn) {
 			pcm[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
 		}
 
-		// Add to ring buffer
+		// Queue the chunks that end at a pause in speech
 		rs.mu.Lock()
-		for _, sample := range pcm {
-			rs.ring.Write([]int16{sample})
-		}
-
-		// Check if we have a complete chunk
-		available := rs.ring.ReadLast(rs.WindowSize)
-		if len(available) >= rs.WindowSize {
-			chunk := make([]int16, len(available))
-			copy(chunk, available)
+		for _, chunk := range rs.segmenter.Push(pcm) {
 			rs.chunks = append(rs.chunks, chunk)
 			log.Printf("[Recording %s] Queued chunk %d (%d samples)", rs.ID, len(rs.chunks), len(chunk))
-			// Reset ring for next chunk
-			rs.ring = audio.NewRing(rs.WindowSize)
+		}
+		var level map[string]interface{}
+		if time.Since(rs.levelSentAt) >= 500*time.Millisecond {
+			rs.levelSentAt = time.Now()
+			level = map[string]interface{}{
+				"type":   "audio_level",
+				"level":  rs.segmenter.Level(),
+				"minRms": rs.minRMS(),
+			}
 		}
 		rs.mu.Unlock()
+
+		// Mic-level feedback for the client
+		if level != nil {
+			if err := rs.writeJSON(level); err != nil {
+				log.Printf("[Recording %s] Failed to send audio level: %v", rs.ID, err)
+			}
+		}
 	}
 
-	// Connection closed, finalize recording
 	rs.mu.Lock()
+	if gen != rs.connGen {
+		// A resumed connection took over
+		rs.mu.Unlock()
+		return
+	}
+	dropped := !rs.isStopped && rs.resumeGrace > 0 && !websocket.IsCloseError(readErr, websocket.CloseNormalClosure)
+	if dropped {
+		rs.detached = time.AfterFunc(rs.resumeGrace, func() {
+			log.Printf("[Recording %s] Not resumed within %s", rs.ID, rs.resumeGrace)
+			rs.finish()
+		})
+	}
+	rs.mu.Unlock()
+
+	rs.writeMu.Lock()
+	if rs.conn == conn {
+		rs.conn = nil
+	}
+	rs.writeMu.Unlock()
+
+	if dropped {
+		log.Printf("[Recording %s] Connection dropped; resumable for %s", rs.ID, rs.resumeGrace)
+		return
+	}
+	rs.finish()
+}
+
+// finish ends the recording once: the last partial chunk is queued, the
+// queue is drained, and completion is reported
+func (rs *RecordingSession) finish() {
+	rs.mu.Lock()
+	if rs.finished {
+		rs.mu.Unlock()
+		return
+	}
+	rs.finished = true
 	rs.isRecording = false
+	rs.detached = nil
 
 	// Add final partial chunk if any
-	finalChunk := rs.ring.ReadLast(rs.WindowSize)
-	if len(finalChunk) > 0 {
-		chunk := make([]int16, len(finalChunk))
-		copy(chunk, finalChunk)
+	if chunk := rs.segmenter.Flush(); len(chunk) > 0 {
 		rs.chunks = append(rs.chunks, chunk)
 		log.Printf("[Recording %s] Added final chunk %d (%d samples)", rs.ID, len(rs.chunks), len(chunk))
 	}
 
 	rs.totalChunks = len(rs.chunks)
 	rs.mu.Unlock()
+	defer close(rs.done)
 
 	log.Printf("[Recording %s] Recording stopped, total chunks: %d", rs.ID, rs.totalChunks)
 
@@ -157,7 +361,7 @@ func (rs *RecordingSession) HandleWebSocket(conn *websocket.Conn) {
 		"type":    "complete",
 		"message": "All translations complete",
 	}
-	if err := conn.WriteJSON(completionMsg); err != nil {
+	if err := rs.writeJSON(completionMsg); err != nil {
 		log.Printf("[Recording %s] Failed to send completion message via WS: %v", rs.ID, err)
 	} else {
 		log.Printf("[Recording %s] Sent completion message via WebSocket", rs.ID)
@@ -177,11 +381,36 @@ func (rs *RecordingSession) HandleWebSocket(conn *websocket.Conn) {
 	log.Printf("[Recording %s] Processing complete", rs.ID)
 }
 
+// errDetached is returned when there is no connection to write to
+var errDetached = errors.New("no connection attached")
+
+// writeJSON sends a message on the attached recording WebSocket
+func (rs *RecordingSession) writeJSON(v interface{}) error {
+	rs.writeMu.Lock()
+	defer rs.writeMu.Unlock()
+	return rs.writeLocked(v)
+}
+
+// writeLocked sends a message on the attached WebSocket; callers hold
+// rs.writeMu
+func (rs *RecordingSession) writeLocked(v interface{}) error {
+	if rs.conn == nil {
+		return errDetached
+	}
+	return rs.conn.WriteJSON(v)
+}
+
+// minRMS returns the RMS a chunk needs: the configured minimum, raised with
+// the noise floor in noisy rooms. Callers hold rs.mu.
+func (rs *RecordingSession) minRMS() float64 {
+	return max(rs.MinRMS, rs.segmenter.NoiseThreshold())
+}
+
 // processQueue continuously processes queued audio chunks
-func (rs *RecordingSession) processQueue(conn *websocket.Conn) {
+func (rs *RecordingSession) processQueue() {
 	defer rs.wg.Done()
 
-	ticker := time.NewTicker(500 * time.Millisecond)
+	ticker := time.NewTicker(rs.pollInterval)
 	defer ticker.Stop()
 
 	for {
@@ -204,12 +433,11 @@ func (rs *RecordingSession) processQueue(conn *websocket.Conn) {
 					rs.mu.Unlock()
 					continue
 				}
-				// totalChunks not set yet, but recording stopped and queue empty
-				// Give it one more cycle to let HandleWebSocket set totalChunks
-				if rs.processedIdx > 0 {
-					rs.mu.Unlock()
-					continue
-				}
+				// Recording stopped without any chunk (only silence was
+				// streamed); totalChunks is set together with isRecording
+				rs.mu.Unlock()
+				log.Printf("[Recording %s] No speech recorded, exiting", rs.ID)
+				return
 			}
 			rs.mu.Unlock()
 			continue
@@ -222,7 +450,7 @@ func (rs *RecordingSession) processQueue(conn *websocket.Conn) {
 		rs.mu.Unlock()
 
 		// Process this chunk (transcribe + translate)
-		rs.processChunk(chunk, currentIdx, conn)
+		rs.processChunk(chunk, currentIdx)
 
 		rs.mu.Lock()
 		rs.processedIdx = currentIdx
@@ -249,7 +477,7 @@ func (rs *RecordingSession) processQueue(conn *websocket.Conn) {
 }
 
 // processChunk transcribes and translates a single audio chunk
-func (rs *RecordingSession) processChunk(pcm []int16, index int, conn *websocket.Conn) {
+func (rs *RecordingSession) processChunk(pcm []int16, index int) {
 	log.Printf("[Recording %s] Processing chunk %d (%d samples)", rs.ID, index, len(pcm))
 
 	// Check if audio has sufficient volume (RMS check)
@@ -261,11 +489,31 @@ func (rs *RecordingSession) processChunk(pcm []int16, index int, conn *websocket
 	rms := math.Sqrt(sum / float64(len(pcm)))
 	log.Printf("[Recording %s] Chunk %d RMS: %.6f", rs.ID, index, rms)
 
-	if rms < 0.01 {
+	rs.mu.Lock()
+	minRMS := rs.minRMS()
+	rs.mu.Unlock()
+	if rms < minRMS {
 		log.Printf("[Recording %s] Chunk %d too quiet (RMS %.6f), skipping", rs.ID, index, rms)
 		return
 	}
 
+	if exceeded, ok := quota.Exceeded(rs.meter.Charge(float64(len(pcm)) / float64(rs.SampleRate))); ok {
+		log.Printf("[Recording %s] Skipping chunk %d: %v", rs.ID, index, exceeded)
+		rs.mu.Lock()
+		warn := !rs.quotaWarned
+		rs.quotaWarned = true
+		rs.mu.Unlock()
+		if warn && rs.progressMgr != nil {
+			rs.progressMgr.SendUpdate(progress.Update{
+				SessionID: rs.ID,
+				Stage:     "quota_exceeded",
+				Message:   "The daily audio quota is used up; speech is not transcribed until it resets",
+				Error:     exceeded.Error(),
+			})
+		}
+		return
+	}
+
 	// Convert to WAV bytes
 	wavBytes := pcmToWav(pcm, rs.SampleRate)
 
@@ -294,11 +542,35 @@ func (rs *RecordingSession) processChunk(pcm []int16, index int, conn *websocket
 		// return
 	}
 
+	event := hooks.Event{Source: hooks.SourceRecording, SessionID: rs.ID, Text: transcription, Language: sourceLang, Final: true}
+	transcription, ok := hooks.Run(hooks.PostTranscription, event)
+	if !ok || transcription == "" {
+		log.Printf("[Recording %s] Chunk %d dropped by pipeline hooks", rs.ID, index)
+		return
+	}
+
 	// Translate using Translate method (2 params: text, targetLang)
-	translation, err := rs.translator.Translate(transcription, rs.TargetLang)
+	event.Text, event.SourceLanguage, event.TargetLanguage = transcription, sourceLang, rs.TargetLang
+	translation, ok, err := hooks.Translate(event, func(text string) (string, error) {
+		return rs.translator.Translate(text, rs.TargetLang)
+	})
 	if err != nil {
 		log.Printf("[Recording %s] Translation error for chunk %d: %v", rs.ID, index, err)
 		translation = transcription // fallback to original
+	} else if !ok {
+		translation = ""
+	}
+
+	// Keep the audio this result came from
+	audioRef, err := rs.archive.Store(context.Background(), audioarchive.Chunk{
+		SessionType: audioarchive.SessionRecording,
+		SessionID:   rs.ID,
+		Index:       index,
+		WAV:         wavBytes,
+		Duration:    float64(len(pcm)) / float64(rs.SampleRate),
+	})
+	if err != nil {
+		log.Printf("[Recording %s] Failed to archive chunk %d: %v", rs.ID, index, err)
 	}
 
 	// Store result
@@ -306,24 +578,21 @@ func (rs *RecordingSession) processChunk(pcm []int16, index int, conn *websocket
 		Index:       index,
 		Original:    transcription,
 		Translation: translation,
-		Timestamp:   time.Now(),
+		Timestamp:   time.Now().UTC(),
+		AudioRef:    audioRef,
 	}
 
+	msg := item.message()
+
+	// Store and send together so a resuming client's replay neither misses
+	// nor repeats this result
+	rs.writeMu.Lock()
 	rs.mu.Lock()
 	rs.results = append(rs.results, item)
 	rs.mu.Unlock()
-
-	// Prepare translation message
-	msg := map[string]interface{}{
-		"type":        "translation",
-		"index":       index,
-		"original":    transcription,
-		"translation": translation,
-		"timestamp":   item.Timestamp.Format(time.RFC3339),
-	}
-
-	// Send to recording WebSocket if still connected
-	if err := conn.WriteJSON(msg); err != nil {
+	err = rs.writeLocked(msg)
+	rs.writeMu.Unlock()
+	if err != nil {
 		log.Printf("[Recording %s] Recording WS closed, cannot send translation: %v", rs.ID, err)
 	} else {
 		log.Printf("[Recording %s] Sent translation via recording WS", rs.ID)
@@ -358,6 +627,21 @@ func (rs *RecordingSession) Stop() (int, error) {
 	return len(rs.chunks), nil
 }
 
+// message is the "translation" message of a result
+func (item TranscriptItem) message() map[string]interface{} {
+	msg := map[string]interface{}{
+		"type":        "translation",
+		"index":       item.Index,
+		"original":    item.Original,
+		"translation": item.Translation,
+		"timestamp":   item.Timestamp.Format(time.RFC3339),
+	}
+	if item.AudioRef != 0 {
+		msg["audioRef"] = item.AudioRef
+	}
+	return msg
+}
+
 // GetResults returns all processed results
 func (rs *RecordingSession) GetResults() []TranscriptItem {
 	rs.mu.Lock()
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"log"
+	"math"
 	"strings"
 	"sync"
 	"time"
@@ -13,48 +15,166 @@ import (
 
 	"realtime-caption-translator/internal/asr"
 	"realtime-caption-translator/internal/audio"
+	"realtime-caption-translator/internal/experiment"
+	"realtime-caption-translator/internal/hooks"
+	"realtime-caption-translator/internal/httpx"
+	"realtime-caption-translator/internal/keepalive"
+	"realtime-caption-translator/internal/metrics"
+	"realtime-caption-translator/internal/ratelimit"
+	"realtime-caption-translator/internal/replica"
 	"realtime-caption-translator/internal/translate"
+	"realtime-caption-translator/internal/tts"
 )
 
 type Config struct {
 	ASRBaseURL       string
 	TranslateBaseURL string
+	TTSBaseURL       string // enables speakTranslations; empty disables live speech
 	PollInterval     time.Duration
 	WindowSeconds    int
 	FinalizeAfter    time.Duration
+	VADThreshold     float64 // RMS (0-1) below which a window skips ASR; 0 disables
+
+	// LanguageRedetectInterval is how often a session started with a fixed
+	// source language checks whether the speaker switched language; 0
+	// disables. A switch needs detections at LanguageSwitchConfidence or
+	// above.
+	LanguageRedetectInterval time.Duration
+	LanguageSwitchConfidence float64
+
+	// Experiment, when set, assigns each connection to a parameter variant
+	// and records per-variant metrics
+	Experiment *experiment.Experiment
+
+	// Service limiters shared with uploads and meetings; live calls queue at
+	// interactive priority. Nil leaves a service unlimited.
+	ASRLimiter       *ratelimit.Limiter
+	TranslateLimiter *ratelimit.Limiter
+	TTSLimiter       *ratelimit.Limiter
+
+	// Replica pools shared with the rest of the server; nil sends every
+	// call to the base URL
+	ASRReplicas *replica.Pool
+	TTSReplicas *replica.Pool
+
+	// Retry and circuit breakers shared with the rest of the server; nil
+	// sends each call once
+	ASRBreaker       *httpx.Breaker
+	TranslateBreaker *httpx.Breaker
+	TTSBreaker       *httpx.Breaker
 }
 
 type Server struct {
-	cfg Config
-	asr *asr.Client
-	tr  translate.Translator
+	cfg  Config
+	asr  *asr.Client
+	tr   translate.Translator
+	tts  *tts.Client
+	cues *cueStore
+
+	streams streamOwners // who started each /ws/stream session
 }
 
 func NewServer(cfg Config) *Server {
 	translator := &translate.HTTPTranslator{
 		BaseURL: cfg.TranslateBaseURL,
+		Limiter: cfg.TranslateLimiter,
+		Breaker: cfg.TranslateBreaker,
+	}
+	asrClient := asr.New(cfg.ASRBaseURL)
+	asrClient.Limiter = cfg.ASRLimiter
+	asrClient.Replicas = cfg.ASRReplicas
+	asrClient.Breaker = cfg.ASRBreaker
+	server := &Server{
+		cfg:  cfg,
+		asr:  asrClient,
+		tr:   translator,
+		cues: newCueStore(),
 	}
-	return &Server{
-		cfg: cfg,
-		asr: asr.New(cfg.ASRBaseURL),
-		tr:  translator,
+	if cfg.TTSBaseURL != "" {
+		server.tts = tts.New(cfg.TTSBaseURL)
+		server.tts.Limiter = cfg.TTSLimiter
+		server.tts.Replicas = cfg.TTSReplicas
+		server.tts.Breaker = cfg.TTSBreaker
 	}
+	return server
 }
 
+// Client sample rates accepted on "start"
+const (
+	MinSampleRate = 8000
+	MaxSampleRate = 192000
+)
+
 type controlMsg struct {
 	Type       string `json:"type"`
 	TargetLang string `json:"targetLang"`
 	SourceLang string `json:"sourceLang"`
 	SampleRate int    `json:"sampleRate"`
+
+	// ResumeToken on "start" continues a logical session from an earlier
+	// connection so final caption IDs carry on from where it stopped
+	ResumeToken string `json:"resumeToken,omitempty"`
+
+	// Since, with ResumeToken, is the last final ID the client received;
+	// the session's later finals are sent again
+	Since int `json:"since,omitempty"`
+
+	// SpeakTranslations, when present on any control message, turns spoken
+	// playback of finalized translations on or off
+	SpeakTranslations *bool `json:"speakTranslations,omitempty"`
+
+	// Latency knobs on "start" override the server defaults for the session
+	Latency
 }
 
 type wsEvent struct {
-	Type string `json:"type"`
-	ID   int    `json:"id,omitempty"`
-	Text string `json:"text,omitempty"`
+	Type  string `json:"type"`
+	ID    int    `json:"id,omitempty"`
+	Text  string `json:"text,omitempty"`
+	Token string `json:"token,omitempty"`
+
+	// Language switches: the new source language, the one it replaced and
+	// the detection probability
+	Language   string  `json:"language,omitempty"`
+	Previous   string  `json:"previous,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+
+	// Sub numbers the sentences of a "translation_segment" from 1; Count is
+	// how many sentences the final has
+	Sub   int `json:"sub,omitempty"`
+	Count int `json:"count,omitempty"`
+
+	// Latency is the session's effective latency knobs ("session" events)
+	Latency *Latency `json:"latency,omitempty"`
+
+	// SessionID names a /ws/stream session's transcript ("session" events)
+	SessionID string `json:"sessionId,omitempty"`
+
+	// Replayed marks finals and translations sent again on resume
+	Replayed bool `json:"replayed,omitempty"`
 }
 
-func (s *Server) HandleConn(conn *websocket.Conn) {
+// Conn is the client side of a live session: a /ws WebSocket, or an adapter
+// that speaks the same control messages, PCM frames and events over another
+// transport
+type Conn interface {
+	ReadMessage() (messageType int, p []byte, err error)
+	WriteJSON(v interface{}) error
+	WriteMessage(messageType int, data []byte) error
+	Close() error
+}
+
+// ConnOptions are the per-connection capabilities of a /ws client
+type ConnOptions struct {
+	// LiveDubbing allows speakTranslations; without it the client is told
+	// speech is unavailable
+	LiveDubbing bool
+	// OrgID is the org of the signed-in user; its voice policy and lexicon
+	// apply to speech. Empty uses the server-wide ones.
+	OrgID string
+}
+
+func (s *Server) HandleConn(conn Conn, opts ConnOptions) {
 	defer func() {
 		if r := recover(); r != nil {
 			// Log panic and close gracefully
@@ -63,68 +183,249 @@ func (s *Server) HandleConn(conn *websocket.Conn) {
 		conn.Close()
 	}()
 
+	metrics.WebSocketSessions.Inc("stream")
+	defer metrics.WebSocketSessions.Dec("stream")
+
+	// Only WebSockets are pinged; other transports have their own keepalive
+	var alive *keepalive.Conn
+	if ws, ok := conn.(*websocket.Conn); ok {
+		alive = keepalive.Start(ws)
+	}
+	defer alive.Stop()
+
+	params := experiment.Params{
+		WindowSeconds:   s.cfg.WindowSeconds,
+		FinalizeAfterMs: int(s.cfg.FinalizeAfter.Milliseconds()),
+		VADThreshold:    s.cfg.VADThreshold,
+	}
+	variant := s.cfg.Experiment.Assign()
+	if variant != nil {
+		if variant.WindowSeconds > 0 {
+			params.WindowSeconds = variant.WindowSeconds
+		}
+		if variant.FinalizeAfterMs > 0 {
+			params.FinalizeAfterMs = variant.FinalizeAfterMs
+		}
+		if variant.VADThreshold > 0 {
+			params.VADThreshold = variant.VADThreshold
+		}
+		log.Printf("[Experiment] Session assigned to %s/%s (%+v)", s.cfg.Experiment.Name, variant.Name, params)
+	}
+	recorder := s.cfg.Experiment.NewRecorder(variant, params)
+	defer recorder.Finish()
+
 	var (
 		targetLang = "en"
 		sourceLang = ""
-		sampleRate = 16000
-		ring       = audio.NewRing(sampleRate * s.cfg.WindowSeconds) // samples
 		started    = false
 
+		// The client's rate and latency knobs and the window ring sized
+		// for them can change on each start; guarded by mu
+		latency = Latency{
+			WindowSeconds:   params.WindowSeconds,
+			PollIntervalMs:  int(s.cfg.PollInterval.Milliseconds()),
+			FinalizeAfterMs: params.FinalizeAfterMs,
+		}
+		sampleRate = 16000
+		ring       = audio.NewRing(sampleRate * latency.WindowSeconds) // samples
+		retune     = make(chan time.Duration, 1)                       // new poll intervals for the poll loop
+
 		mu          sync.Mutex
 		lastPartial string
 		stableSince = time.Time{}
-		nextID      = 1
+		cues        *cueCounter        // set on start; guarded by mu
+		stitcher    transcriptStitcher // guarded by mu
+		merger      hypothesisMerger   // guarded by mu
+		resumeToken string             // guarded by mu
+		languages   = newLanguageWatcher(s.cfg.LanguageRedetectInterval, s.cfg.LanguageSwitchConfidence)
 	)
 
+	defer func() {
+		mu.Lock()
+		cues.Release()
+		mu.Unlock()
+	}()
+
+	// The poll loop, read loop and speaker all write; gorilla allows one writer at a time
+	var writeMu sync.Mutex
 	sendJSON := func(v any) {
 		log.Printf("Sending to client: %+v", v)
+		writeMu.Lock()
 		_ = conn.WriteJSON(v)
+		writeMu.Unlock()
+	}
+	sendBinary := func(data []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return conn.WriteMessage(websocket.BinaryMessage, data)
+	}
+
+	speechClient := s.tts
+	if !opts.LiveDubbing {
+		speechClient = nil
+	}
+	speech := newSpeaker(speechClient, opts.OrgID, sendJSON, sendBinary)
+	defer speech.close()
+
+	// emitFinal sends a final caption and its translation. Finals of several
+	// sentences are translated and spoken sentence by sentence, each sent as
+	// a "translation_segment" with the final's ID, before the joined
+	// "translation".
+	emitFinal := func(id int, finalText string) {
+		mu.Lock()
+		lang, counter := sourceLang, cues
+		mu.Unlock()
+
+		finalText, ok := hooks.Run(hooks.PostTranscription, hooks.Event{Source: hooks.SourceStream, Text: finalText, Language: lang, Final: true})
+		if !ok || strings.TrimSpace(finalText) == "" {
+			return
+		}
+		counter.Remember(id, finalText, "")
+		sendJSON(wsEvent{Type: "final", ID: id, Text: finalText})
+
+		sentences := translate.SplitSentences(finalText, lang)
+		if len(sentences) < 2 {
+			tr, ok, _ := translateHooked(s.tr, finalText, lang, targetLang, true)
+			if !ok {
+				return
+			}
+			counter.Remember(id, "", tr)
+			sendJSON(wsEvent{Type: "translation", ID: id, Text: tr})
+			speech.enqueue(id, tr, targetLang)
+			return
+		}
+
+		parts := make([]string, 0, len(sentences))
+		for i, sentence := range sentences {
+			tr, ok, err := translateHooked(s.tr, sentence, lang, targetLang, true)
+			if err != nil || !ok || tr == "" {
+				continue
+			}
+			parts = append(parts, tr)
+			sendJSON(wsEvent{Type: "translation_segment", ID: id, Sub: i + 1, Count: len(sentences), Text: tr})
+			speech.enqueue(id, tr, targetLang)
+		}
+		joined := translate.JoinSentences(parts, targetLang)
+		counter.Remember(id, "", joined)
+		sendJSON(wsEvent{Type: "translation", ID: id, Text: joined})
+	}
+
+	// detectLanguage checks a window of speech for a change of language and
+	// switches the session's source language once it is confirmed
+	detectLanguage := func(pcm []int16, rate int) {
+		defer languages.finish()
+		detected, probability, err := s.asr.DetectLanguagePCM16(pcm, rate)
+		if err != nil {
+			log.Printf("Language re-detection failed: %v", err)
+			return
+		}
+
+		mu.Lock()
+		previous := sourceLang
+		switchTo := ""
+		if fixedLanguage(previous) {
+			switchTo = languages.observe(previous, detected, probability)
+		}
+		if switchTo != "" {
+			sourceLang = switchTo
+		}
+		mu.Unlock()
+
+		if switchTo != "" {
+			log.Printf("Source language switched: %s -> %s (p=%.2f)", previous, switchTo, probability)
+			sendJSON(wsEvent{Type: "language_switch", Language: switchTo, Previous: previous, Confidence: probability})
+		}
 	}
 
 	sendJSON(wsEvent{Type: "info", Text: "connected"})
 
 	// Poll loop: ask ASR for rolling window transcript
 	stopPoll := make(chan struct{})
+	pollInterval := latency.PollInterval()
 	go func() {
-		t := time.NewTicker(s.cfg.PollInterval)
+		t := time.NewTicker(pollInterval)
 		defer t.Stop()
 		for {
 			select {
+			case interval := <-retune:
+				t.Reset(interval)
 			case <-t.C:
 				if !started {
 					continue
 				}
+				mu.Lock()
+				window, rate, buf, finalizeAfter := latency.WindowSeconds, sampleRate, ring, latency.FinalizeAfter()
+				mu.Unlock()
+
 				// read last N seconds
-				pcm := ring.ReadLast(sampleRate * s.cfg.WindowSeconds)
-				if len(pcm) < sampleRate { // too little
+				pcm := buf.ReadLast(rate * window)
+				if len(pcm) < rate { // too little
 					continue
 				}
 
 				// Calculate audio level
-				var sum float64
-				for _, sample := range pcm {
-					sum += float64(sample * sample)
-				}
-				rms := sum / float64(len(pcm))
-				log.Printf("Transcribing %d samples (%.1fs), RMS level: %.0f", len(pcm), float64(len(pcm))/float64(sampleRate), rms)
+				rms := windowRMS(pcm)
 
-				text, err := s.asr.TranscribePCM16WithLang(pcm, sampleRate, sourceLang)
-				if err != nil {
-					sendJSON(wsEvent{Type: "info", Text: "ASR error: " + err.Error()})
-					continue
+				// Windows below the VAD threshold are treated as silence
+				// without calling ASR
+				var result *asr.WordTranscript
+				if params.VADThreshold > 0 && rms < params.VADThreshold {
+					recorder.SilentWindow()
+				} else {
+					log.Printf("Transcribing %d samples (%.1fs), RMS level: %.4f", len(pcm), float64(len(pcm))/float64(rate), rms)
+
+					mu.Lock()
+					lang := sourceLang
+					mu.Unlock()
+
+					asrStart := time.Now()
+					var err error
+					result, err = s.asr.TranscribePCM16Words(pcm, rate, lang)
+					recorder.ASRCall(time.Since(asrStart), err)
+					if err != nil {
+						sendJSON(wsEvent{Type: "info", Text: "ASR error: " + err.Error()})
+						continue
+					}
+					log.Printf("ASR result: '%s'", strings.TrimSpace(result.Text))
+
+					if strings.TrimSpace(result.Text) != "" && languages.due(time.Now(), lang) {
+						go detectLanguage(pcm, rate)
+					}
 				}
-				text = strings.TrimSpace(text)
-				log.Printf("ASR result: '%s'", text)
 
 				mu.Lock()
 
-				// Emit partial (source)
+				// Silence ends the hypothesis; speech is merged into it
+				var text string
+				if result == nil || strings.TrimSpace(result.Text) == "" {
+					merger.Reset()
+				} else {
+					text = merger.Merge(result)
+				}
+
+				// Only the words after what earlier finals already covered are new
+				if text != "" {
+					if trimmed := stitcher.Trim(text); trimmed != text {
+						log.Printf("Dropped overlap with finalized text: '%s' -> '%s'", text, trimmed)
+						text = trimmed
+					}
+				}
+
+				// Emit partial (source); hooks see the shown text, while the
+				// merger keeps what ASR heard
+				shown := text
 				if text != "" {
-					sendJSON(wsEvent{Type: "partial", Text: text})
+					var ok bool
+					if shown, ok = hooks.Run(hooks.PostTranscription, hooks.Event{Source: hooks.SourceStream, Text: text, Language: sourceLang}); !ok {
+						shown = ""
+					}
+				}
+				if shown != "" {
+					sendJSON(wsEvent{Type: "partial", Text: shown})
 
 					// 🔹 OPTION A: translate partial immediately
-					trText, err := s.tr.Translate(text, targetLang)
-					if err == nil {
+					trText, ok, err := translateHooked(s.tr, shown, sourceLang, targetLang, false)
+					if err == nil && ok {
 						sendJSON(wsEvent{
 							Type: "partial_translation",
 							Text: trText,
@@ -141,18 +442,18 @@ func (s *Server) HandleConn(conn *websocket.Conn) {
 					// if we had stable partial and now silence, finalize it
 					if lastPartial != "" {
 						finalText := lastPartial
-						id := nextID
-						nextID++
+						id := cues.Next()
 						lastPartial = ""
 						stableSince = time.Time{}
+						stitcher.Commit(finalText)
+						merger.Reset()
 						mu.Unlock()
 
-						sendJSON(wsEvent{Type: "final", ID: id, Text: finalText})
-						tr, _ := s.tr.Translate(finalText, targetLang)
-						sendJSON(wsEvent{Type: "translation", ID: id, Text: tr})
+						recorder.Final()
+						emitFinal(id, finalText)
 
 						// Clear ring buffer to avoid re-transcribing finalized audio
-						ring.Clear()
+						buf.Clear()
 					} else {
 						mu.Unlock()
 					}
@@ -163,24 +464,25 @@ func (s *Server) HandleConn(conn *websocket.Conn) {
 					lastPartial = text
 					stableSince = now
 					mu.Unlock()
+					recorder.Partial()
 					continue
 				}
 
 				// unchanged text
-				if !stableSince.IsZero() && now.Sub(stableSince) >= s.cfg.FinalizeAfter {
+				if !stableSince.IsZero() && now.Sub(stableSince) >= finalizeAfter {
 					finalText := lastPartial
-					id := nextID
-					nextID++
+					id := cues.Next()
 					lastPartial = ""
 					stableSince = time.Time{}
+					stitcher.Commit(finalText)
+					merger.Reset()
 					mu.Unlock()
 
-					sendJSON(wsEvent{Type: "final", ID: id, Text: finalText})
-					tr, _ := s.tr.Translate(finalText, targetLang)
-					sendJSON(wsEvent{Type: "translation", ID: id, Text: tr})
+					recorder.Final()
+					emitFinal(id, finalText)
 
 					// Clear ring buffer to avoid re-transcribing finalized audio
-					ring.Clear()
+					buf.Clear()
 				} else {
 					mu.Unlock()
 				}
@@ -197,40 +499,88 @@ func (s *Server) HandleConn(conn *websocket.Conn) {
 			close(stopPoll)
 			return
 		}
+		alive.Touch()
 
 		if mt == websocket.TextMessage {
 			var msg controlMsg
 			if err := json.Unmarshal(data, &msg); err != nil {
 				continue
 			}
+			if msg.SpeakTranslations != nil {
+				speech.setEnabled(*msg.SpeakTranslations)
+			}
 			switch msg.Type {
 			case "start":
+				// The rate sizes the audio buffer, so it is checked first
+				if msg.SampleRate != 0 && (msg.SampleRate < MinSampleRate || msg.SampleRate > MaxSampleRate) {
+					sendJSON(wsEvent{Type: "info", Text: fmt.Sprintf("sampleRate must be between %d and %d", MinSampleRate, MaxSampleRate)})
+					continue
+				}
+				mu.Lock()
+				stitcher.Reset()
+				merger.Reset()
+				// A restart on the same connection keeps numbering unless
+				// another session is named
+				var missed []cueFinal
+				if cues == nil || msg.ResumeToken != "" && msg.ResumeToken != resumeToken {
+					cues.Release()
+					resumeToken, cues = s.cues.Resume(msg.ResumeToken)
+					if resumeToken == msg.ResumeToken && msg.Since > 0 {
+						missed = cues.Since(msg.Since)
+					}
+				}
+				lastID := cues.Last()
+				if msg.SampleRate > 0 {
+					sampleRate = msg.SampleRate
+				}
+				// Client knobs replace the defaults or the experiment's
+				// variant, so such sessions are left out of the experiment
+				if !msg.Latency.IsZero() {
+					latency = msg.Latency.Over(latency)
+					recorder.Discard()
+				}
+				ring = audio.NewRing(sampleRate * latency.WindowSeconds)
+				tuned, rate := latency, sampleRate
+				mu.Unlock()
+				select {
+				case <-retune:
+				default:
+				}
+				retune <- tuned.PollInterval()
+				sendJSON(wsEvent{Type: "session", ID: lastID, Token: resumeToken, Latency: &tuned})
+				for _, final := range missed {
+					sendJSON(wsEvent{Type: "final", ID: final.ID, Text: final.Text, Replayed: true})
+					if final.Translation != "" {
+						sendJSON(wsEvent{Type: "translation", ID: final.ID, Text: final.Translation, Replayed: true})
+					}
+				}
 				started = true
+				recorder.AudioStarted()
 				if msg.TargetLang != "" {
 					targetLang = msg.TargetLang
 				}
 				if msg.SourceLang != "" {
+					mu.Lock()
 					sourceLang = msg.SourceLang
+					mu.Unlock()
+					languages.reset()
 				}
-				if msg.SampleRate > 0 {
-					sampleRate = msg.SampleRate
-				}
-				log.Printf("Started: targetLang=%s, sourceLang=%s, sampleRate=%d", targetLang, sourceLang, sampleRate)
+				log.Printf("Started: targetLang=%s, sourceLang=%s, sampleRate=%d, latency=%+v", targetLang, sourceLang, rate, tuned)
 				sendJSON(wsEvent{Type: "info", Text: "started"})
 			case "stop":
 				// Finalize any pending partial before stopping
 				mu.Lock()
 				if lastPartial != "" {
 					finalText := lastPartial
-					id := nextID
-					nextID++
+					id := cues.Next()
 					lastPartial = ""
 					stableSince = time.Time{}
+					stitcher.Commit(finalText)
+					merger.Reset()
 					mu.Unlock()
 
-					sendJSON(wsEvent{Type: "final", ID: id, Text: finalText})
-					tr, _ := s.tr.Translate(finalText, targetLang)
-					sendJSON(wsEvent{Type: "translation", ID: id, Text: tr})
+					recorder.Final()
+					emitFinal(id, finalText)
 				} else {
 					mu.Unlock()
 				}
@@ -249,7 +599,37 @@ func (s *Server) HandleConn(conn *websocket.Conn) {
 			samples := make([]int16, len(data)/2)
 			_ = binary.Read(bytes.NewReader(data), binary.LittleEndian, &samples)
 			log.Printf("Received %d samples (%d bytes) from browser", len(samples), len(data))
-			ring.Write(samples)
+			mu.Lock()
+			buf := ring
+			mu.Unlock()
+			buf.Write(samples)
 		}
 	}
 }
+
+// windowRMS returns the root mean square level of a PCM16 window, scaled to 0-1
+func windowRMS(pcm []int16) float64 {
+	if len(pcm) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, sample := range pcm {
+		val := float64(sample) / 32768.0
+		sum += val * val
+	}
+	return math.Sqrt(sum / float64(len(pcm)))
+}
+
+// translateHooked translates text of a stream through the translation hooks;
+// ok is false when a hook dropped it
+func translateHooked(tr translate.Translator, text, sourceLang, targetLang string, final bool) (string, bool, error) {
+	return hooks.Translate(hooks.Event{
+		Source:         hooks.SourceStream,
+		Text:           text,
+		SourceLanguage: sourceLang,
+		TargetLanguage: targetLang,
+		Final:          final,
+	}, func(text string) (string, error) {
+		return tr.Translate(text, targetLang)
+	})
+}
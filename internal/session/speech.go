@@ -0,0 +1,125 @@
+package session
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"realtime-caption-translator/internal/hooks"
+	"realtime-caption-translator/internal/lexicon"
+	"realtime-caption-translator/internal/tts"
+	"realtime-caption-translator/internal/voicepolicy"
+)
+
+// speechFrameSize bounds each binary frame of synthesized audio
+const speechFrameSize = 32 * 1024
+
+// speechQueueSize is how many finalized translations, or sentences of them,
+// may wait for synthesis. Beyond it new ones are dropped so speech does not
+// fall far behind captions.
+const speechQueueSize = 8
+
+type utterance struct {
+	id       int
+	text     string
+	language string
+}
+
+// speaker synthesizes finalized translations one at a time and streams the
+// audio to the client as "audio_start", binary frames, then "audio_end"
+type speaker struct {
+	tts        *tts.Client
+	orgID      string // whose voice policy and lexicon apply
+	sendJSON   func(v any)
+	sendBinary func(data []byte) error
+
+	mu      sync.Mutex
+	enabled bool
+
+	queue chan utterance
+	done  chan struct{}
+}
+
+func newSpeaker(client *tts.Client, orgID string, sendJSON func(v any), sendBinary func(data []byte) error) *speaker {
+	sp := &speaker{
+		tts:        client,
+		orgID:      orgID,
+		sendJSON:   sendJSON,
+		sendBinary: sendBinary,
+		queue:      make(chan utterance, speechQueueSize),
+		done:       make(chan struct{}),
+	}
+	if client != nil {
+		go sp.run()
+	}
+	return sp
+}
+
+// setEnabled turns speech on or off; turning it on without a TTS service
+// only tells the client it is unavailable
+func (sp *speaker) setEnabled(enabled bool) {
+	if enabled && sp.tts == nil {
+		sp.sendJSON(wsEvent{Type: "info", Text: "speech unavailable"})
+		return
+	}
+	sp.mu.Lock()
+	sp.enabled = enabled
+	sp.mu.Unlock()
+}
+
+// enqueue schedules a finalized translation for synthesis when speech is on
+func (sp *speaker) enqueue(id int, text, language string) {
+	sp.mu.Lock()
+	enabled := sp.enabled
+	sp.mu.Unlock()
+	if !enabled || text == "" {
+		return
+	}
+
+	select {
+	case sp.queue <- utterance{id: id, text: text, language: language}:
+	default:
+		log.Printf("[Speech] Queue full, skipping translation %d", id)
+	}
+}
+
+// close stops synthesis; utterances still queued are discarded
+func (sp *speaker) close() {
+	close(sp.done)
+}
+
+func (sp *speaker) run() {
+	for {
+		select {
+		case <-sp.done:
+			return
+		case u := <-sp.queue:
+			sp.speak(u)
+		}
+	}
+}
+
+func (sp *speaker) speak(u utterance) {
+	text, ok := hooks.Run(hooks.PreTTS, hooks.Event{Source: hooks.SourceStream, Text: u.text, Language: u.language, Final: true})
+	if !ok || strings.TrimSpace(text) == "" {
+		return
+	}
+	client := sp.tts.WithPolicy(voicepolicy.For(sp.orgID))
+	audio, err := client.Synthesize(lexicon.Apply(sp.orgID, u.language, text), u.language)
+	if err != nil {
+		log.Printf("[Speech] Synthesis failed for translation %d: %v", u.id, err)
+		sp.sendJSON(wsEvent{Type: "info", Text: "TTS error: " + err.Error()})
+		return
+	}
+
+	// Text carries the audio MIME type so the client can decode the frames
+	sp.sendJSON(wsEvent{Type: "audio_start", ID: u.id, Text: http.DetectContentType(audio)})
+	for start := 0; start < len(audio); start += speechFrameSize {
+		end := min(start+speechFrameSize, len(audio))
+		if err := sp.sendBinary(audio[start:end]); err != nil {
+			return
+		}
+	}
+	sp.sendJSON(wsEvent{Type: "audio_end", ID: u.id})
+}
@@ -0,0 +1,91 @@
+package session
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Overlap matching settings
+const (
+	stitchContextWords = 64 // finalized words kept to match against
+	stitchMinOverlap   = 2  // shorter overlaps are too likely to be coincidence
+	stitchMaxSkip      = 2  // leading words of a window that may be a cut-off fragment
+)
+
+// transcriptStitcher drops text that a rolling-window transcript repeats
+// from what has already been finalized. The ASR window can still hold audio
+// that was transcribed in an earlier final, so a new window often starts
+// with the tail of the previous final; only the words after that overlap
+// are new.
+type transcriptStitcher struct {
+	committed []string // normalized words of recent finals, oldest first
+}
+
+// Trim returns text without its leading overlap with the finalized tail. The
+// overlap is the longest run of words that is both a suffix of the finalized
+// text and a prefix of text, allowing for a cut-off first word or two.
+func (s *transcriptStitcher) Trim(text string) string {
+	words := strings.Fields(text)
+	if len(words) == 0 || len(s.committed) == 0 {
+		return text
+	}
+	normalized := make([]string, len(words))
+	for i, word := range words {
+		normalized[i] = normalizeWord(word)
+	}
+
+	bestEnd := 0
+	for skip := 0; skip <= stitchMaxSkip && skip < len(words); skip++ {
+		if n := suffixPrefixOverlap(s.committed, normalized[skip:]); n >= stitchMinOverlap && skip+n > bestEnd {
+			bestEnd = skip + n
+		}
+	}
+	if bestEnd == 0 {
+		return text
+	}
+	return strings.Join(words[bestEnd:], " ")
+}
+
+// Commit adds finalized text to the tail that later windows are matched against
+func (s *transcriptStitcher) Commit(text string) {
+	for _, word := range strings.Fields(text) {
+		s.committed = append(s.committed, normalizeWord(word))
+	}
+	if excess := len(s.committed) - stitchContextWords; excess > 0 {
+		s.committed = append(s.committed[:0], s.committed[excess:]...)
+	}
+}
+
+// Reset forgets finalized text, e.g. when a new recording starts
+func (s *transcriptStitcher) Reset() {
+	s.committed = nil
+}
+
+// suffixPrefixOverlap returns the length of the longest suffix of tail that
+// is also a prefix of words
+func suffixPrefixOverlap(tail, words []string) int {
+	for n := min(len(tail), len(words)); n > 0; n-- {
+		match := true
+		for i := 0; i < n; i++ {
+			if tail[len(tail)-n+i] != words[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return n
+		}
+	}
+	return 0
+}
+
+// normalizeWord lowercases a word and strips punctuation, which ASR varies
+// between overlapping windows
+func normalizeWord(word string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, word)
+}
@@ -0,0 +1,473 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"realtime-caption-translator/internal/hooks"
+	"realtime-caption-translator/internal/keepalive"
+	"realtime-caption-translator/internal/metrics"
+)
+
+// Streaming ASR proxy timing
+const (
+	streamDialTimeout  = 5 * time.Second
+	streamWriteTimeout = 5 * time.Second
+	streamRetryMin     = 250 * time.Millisecond
+	streamRetryMax     = 5 * time.Second
+	streamMaxRetries   = 8 // reconnect attempts in a row before the client is dropped
+
+	// streamBacklogBytes is how much audio (16 kHz int16) is held while the
+	// ASR connection is re-established and replayed once it is back
+	streamBacklogBytes = 10 * 16000 * 2
+
+	// streamOwnerTTL is how long a session's transcript can be fetched
+	streamOwnerTTL = 24 * time.Hour
+
+	// streamTranscriptTimeout bounds a transcript request to the ASR service
+	streamTranscriptTimeout = 30 * time.Second
+)
+
+// streamSessionID is the form of the session IDs the server hands out; they
+// name the session's transcript at the ASR service
+var streamSessionID = regexp.MustCompile(`^stream_[0-9a-f]{32}$`)
+
+// transcriptClient fetches finished transcripts from the ASR service
+var transcriptClient = &http.Client{Timeout: streamTranscriptTimeout}
+
+// StreamOptions are what a /ws/stream client asks for
+type StreamOptions struct {
+	Language   string // source language, or "auto" to detect
+	TargetLang string // translation language; empty sends transcripts only
+	UserID     *int   // who starts the session; only they can fetch its transcript
+}
+
+// streamOwner is who started a streaming session, nil for anonymous ones
+type streamOwner struct {
+	userID  *int
+	started time.Time
+}
+
+// streamOwners remembers who started each session the server handed out
+type streamOwners struct {
+	mu     sync.Mutex
+	owners map[string]streamOwner
+}
+
+// add records a new session's owner, dropping sessions past streamOwnerTTL
+func (o *streamOwners) add(sessionID string, userID *int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.owners == nil {
+		o.owners = make(map[string]streamOwner)
+	}
+	for id, owner := range o.owners {
+		if time.Since(owner.started) > streamOwnerTTL {
+			delete(o.owners, id)
+		}
+	}
+	o.owners[sessionID] = streamOwner{userID: userID, started: time.Now()}
+}
+
+// allows reports whether the user may read the session's transcript: the
+// session must be known and, when a user started it, be theirs
+func (o *streamOwners) allows(sessionID string, userID *int) bool {
+	o.mu.Lock()
+	owner, ok := o.owners[sessionID]
+	o.mu.Unlock()
+	if !ok || time.Since(owner.started) > streamOwnerTTL {
+		return false
+	}
+	return owner.userID == nil || userID != nil && *userID == *owner.userID
+}
+
+// newStreamSessionID returns an unguessable session ID
+func newStreamSessionID() string {
+	return "stream_" + newResumeToken()
+}
+
+// asrStreamEvent is a hypothesis from the ASR streaming endpoint
+type asrStreamEvent struct {
+	Type string `json:"type"` // "partial" or "final"
+	Text string `json:"text"`
+}
+
+// streamFinal is a final hypothesis waiting for translation
+type streamFinal struct {
+	id   int
+	text string
+}
+
+// streamProxy bridges one /ws/stream client to the ASR service's /stream
+// endpoint. The client sends 16 kHz int16 PCM and receives the same events
+// as /ws: "partial", "partial_translation", "final" and "translation".
+type streamProxy struct {
+	server    *Server
+	client    *websocket.Conn
+	opts      StreamOptions
+	sessionID string // names the session's full transcript at the ASR service
+
+	writeMu sync.Mutex // gorilla allows one writer at a time on the client
+
+	mu          sync.Mutex
+	upstream    *websocket.Conn // nil while reconnecting
+	backlog     [][]byte        // audio received while upstream is nil
+	backlogSize int
+	lastPartial string
+	nextID      int
+	closed      bool
+
+	finals   chan streamFinal // finals to translate, in order
+	partials chan string      // latest partial to translate; older ones are dropped
+	done     chan struct{}
+}
+
+// HandleStream proxies a /ws/stream client to the ASR streaming endpoint so
+// clients never talk to the ASR service directly. Hypotheses are relayed as
+// they arrive and translated to the target language; when the ASR
+// connection drops, audio is buffered, the connection is re-established
+// with backoff and the buffered audio replayed.
+func (s *Server) HandleStream(client *websocket.Conn, opts StreamOptions) {
+	defer client.Close()
+
+	metrics.WebSocketSessions.Inc("asr_stream")
+	defer metrics.WebSocketSessions.Dec("asr_stream")
+
+	alive := keepalive.Start(client)
+	defer alive.Stop()
+
+	if opts.Language == "" {
+		opts.Language = "auto"
+	}
+	sessionID := newStreamSessionID()
+	s.streams.add(sessionID, opts.UserID)
+
+	p := &streamProxy{
+		server:    s,
+		client:    client,
+		opts:      opts,
+		sessionID: sessionID,
+		finals:    make(chan streamFinal, 16),
+		partials:  make(chan string, 1),
+		done:      make(chan struct{}),
+	}
+	defer p.close()
+
+	conn, err := p.dial()
+	if err != nil {
+		log.Printf("Streaming ASR unavailable for %s: %v", sessionID, err)
+		p.send(wsEvent{Type: "info", Text: "streaming ASR unavailable"})
+		return
+	}
+	p.upstream = conn
+	go p.readUpstream(conn)
+	go p.translate()
+
+	log.Printf("Streaming session %s started (language=%s, target=%s)", sessionID, opts.Language, opts.TargetLang)
+	p.send(wsEvent{Type: "session", SessionID: sessionID})
+
+	for {
+		messageType, data, err := client.ReadMessage()
+		if err != nil {
+			return
+		}
+		if messageType == websocket.BinaryMessage && len(data) > 0 {
+			p.forward(data)
+		}
+	}
+}
+
+// FetchStreamTranscript returns the ASR service's high-quality transcript
+// of a finished streaming session for the user who started it: its status
+// code and JSON body. Sessions of other users are reported as not found.
+func (s *Server) FetchStreamTranscript(sessionID string, userID *int) (int, []byte, error) {
+	if !streamSessionID.MatchString(sessionID) {
+		return http.StatusBadRequest, nil, fmt.Errorf("invalid session ID")
+	}
+	if !s.streams.allows(sessionID, userID) {
+		return http.StatusNotFound, nil, fmt.Errorf("session not found")
+	}
+	resp, err := transcriptClient.Get(strings.TrimRight(s.cfg.ASRBaseURL, "/") + "/transcription/" + url.PathEscape(sessionID))
+	if err != nil {
+		return http.StatusBadGateway, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return http.StatusBadGateway, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
+
+// streamURL is the ASR streaming endpoint for the proxy's session
+func (p *streamProxy) streamURL() (string, error) {
+	u, err := url.Parse(strings.TrimRight(p.server.cfg.ASRBaseURL, "/") + "/stream")
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	u.RawQuery = url.Values{"language": {p.opts.Language}, "session_id": {p.sessionID}}.Encode()
+	return u.String(), nil
+}
+
+func (p *streamProxy) dial() (*websocket.Conn, error) {
+	target, err := p.streamURL()
+	if err != nil {
+		return nil, err
+	}
+	dialer := websocket.Dialer{HandshakeTimeout: streamDialTimeout}
+	conn, _, err := dialer.Dial(target, nil)
+	return conn, err
+}
+
+func (p *streamProxy) send(v any) {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	p.client.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	_ = p.client.WriteJSON(v)
+}
+
+// forward sends client audio to ASR, or holds it while reconnecting
+func (p *streamProxy) forward(data []byte) {
+	p.mu.Lock()
+	conn := p.upstream
+	if conn == nil {
+		p.buffer(data)
+		p.mu.Unlock()
+		return
+	}
+	conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	err := conn.WriteMessage(websocket.BinaryMessage, data)
+	if err != nil {
+		p.buffer(data)
+	}
+	p.mu.Unlock()
+
+	if err != nil {
+		p.upstreamLost(conn, err)
+	}
+}
+
+// buffer holds audio for replay, dropping the oldest past the backlog
+// limit; p.mu must be held
+func (p *streamProxy) buffer(data []byte) {
+	p.backlog = append(p.backlog, data)
+	p.backlogSize += len(data)
+	for p.backlogSize > streamBacklogBytes && len(p.backlog) > 1 {
+		p.backlogSize -= len(p.backlog[0])
+		p.backlog = p.backlog[1:]
+	}
+}
+
+// readUpstream relays one ASR connection's hypotheses until it closes
+func (p *streamProxy) readUpstream(conn *websocket.Conn) {
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			p.upstreamLost(conn, err)
+			return
+		}
+		var event asrStreamEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			continue
+		}
+		switch event.Type {
+		case "partial":
+			p.mu.Lock()
+			p.lastPartial = event.Text
+			p.mu.Unlock()
+			p.partial(event.Text)
+		case "final":
+			p.mu.Lock()
+			p.lastPartial = ""
+			p.mu.Unlock()
+			p.final(event.Text)
+		}
+	}
+}
+
+func (p *streamProxy) partial(text string) {
+	text, ok := hooks.Run(hooks.PostTranscription, hooks.Event{Source: hooks.SourceStream, Text: text, Language: p.sourceLang()})
+	if !ok {
+		return
+	}
+	p.send(wsEvent{Type: "partial", Text: text})
+	if p.opts.TargetLang == "" {
+		return
+	}
+	select {
+	case <-p.partials:
+	default:
+	}
+	select {
+	case p.partials <- text:
+	default:
+	}
+}
+
+func (p *streamProxy) final(text string) {
+	text, ok := hooks.Run(hooks.PostTranscription, hooks.Event{Source: hooks.SourceStream, Text: text, Language: p.sourceLang(), Final: true})
+	if !ok || strings.TrimSpace(text) == "" {
+		return
+	}
+	p.mu.Lock()
+	p.nextID++
+	id := p.nextID
+	p.mu.Unlock()
+
+	p.send(wsEvent{Type: "final", ID: id, Text: text})
+	if p.opts.TargetLang == "" {
+		return
+	}
+	select {
+	case p.finals <- streamFinal{id: id, text: text}:
+	case <-p.done:
+	}
+}
+
+// translate sends translations of finals in order and of the latest
+// partial when no final is waiting
+func (p *streamProxy) translate() {
+	for {
+		select {
+		case <-p.done:
+			return
+		case final := <-p.finals:
+			tr, ok, err := translateHooked(p.server.tr, final.text, p.sourceLang(), p.opts.TargetLang, true)
+			if err != nil {
+				log.Printf("Streaming translation failed (%s): %v", p.sessionID, err)
+				continue
+			}
+			if ok {
+				p.send(wsEvent{Type: "translation", ID: final.id, Text: tr})
+			}
+		case text := <-p.partials:
+			if len(p.finals) > 0 {
+				continue
+			}
+			tr, ok, err := translateHooked(p.server.tr, text, p.sourceLang(), p.opts.TargetLang, false)
+			if err == nil && ok {
+				p.send(wsEvent{Type: "partial_translation", Text: tr})
+			}
+		}
+	}
+}
+
+// sourceLang is the language passed to translation; "" lets it detect
+func (p *streamProxy) sourceLang() string {
+	if p.opts.Language == "auto" {
+		return ""
+	}
+	return p.opts.Language
+}
+
+// upstreamLost handles a failed ASR connection once: the pending partial
+// is finalized, since the new connection starts without it, and a
+// reconnect is started while the client is still there
+func (p *streamProxy) upstreamLost(conn *websocket.Conn, err error) {
+	p.mu.Lock()
+	if p.closed || p.upstream != conn {
+		p.mu.Unlock()
+		return
+	}
+	p.upstream = nil
+	pending := p.lastPartial
+	p.lastPartial = ""
+	p.mu.Unlock()
+	conn.Close()
+
+	log.Printf("Streaming ASR connection lost for %s: %v", p.sessionID, err)
+	if pending != "" {
+		p.final(pending)
+	}
+	p.send(wsEvent{Type: "info", Text: "reconnecting"})
+	go p.reconnect()
+}
+
+// reconnect re-dials ASR with exponential backoff and replays the audio
+// buffered meanwhile. The client is dropped once the retries run out.
+func (p *streamProxy) reconnect() {
+	delay := streamRetryMin
+	for attempt := 1; attempt <= streamMaxRetries; attempt++ {
+		select {
+		case <-p.done:
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, streamRetryMax)
+
+		conn, err := p.dial()
+		if err != nil {
+			log.Printf("Streaming ASR reconnect %d/%d for %s failed: %v", attempt, streamMaxRetries, p.sessionID, err)
+			continue
+		}
+
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			conn.Close()
+			return
+		}
+		replayed := true
+		for _, data := range p.backlog {
+			conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+				replayed = false
+				break
+			}
+		}
+		if !replayed {
+			p.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		p.backlog, p.backlogSize = nil, 0
+		p.upstream = conn
+		p.mu.Unlock()
+
+		log.Printf("Streaming ASR reconnected for %s after %d attempt(s)", p.sessionID, attempt)
+		p.send(wsEvent{Type: "info", Text: "reconnected"})
+		go p.readUpstream(conn)
+		return
+	}
+
+	p.send(wsEvent{Type: "info", Text: "streaming ASR unavailable"})
+	p.client.Close()
+}
+
+// close ends the session. The ASR connection is closed with a close frame
+// so the service runs its high-quality pass over the session's audio.
+func (p *streamProxy) close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	conn := p.upstream
+	p.upstream = nil
+	p.mu.Unlock()
+	close(p.done)
+
+	if conn != nil {
+		conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		conn.Close()
+	}
+	log.Printf("Streaming session %s ended", p.sessionID)
+}
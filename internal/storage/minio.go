@@ -4,19 +4,29 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"mime"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 )
 
+const (
+	defaultPresignExpiry = 15 * time.Minute
+	maxPresignExpiry     = 7 * 24 * time.Hour
+)
+
 type MinioClient struct {
-	client  *minio.Client
-	bucket  string
-	enabled bool
+	client        *minio.Client
+	bucket        string
+	enabled       bool
+	presignExpiry time.Duration
 }
 
 func NewMinioFromEnv() (*MinioClient, error) {
@@ -36,6 +46,15 @@ func NewMinioFromEnv() (*MinioClient, error) {
 
 	useSSL := strings.EqualFold(strings.TrimSpace(os.Getenv("MINIO_USE_SSL")), "true")
 
+	presignExpiry := defaultPresignExpiry
+	if raw := strings.TrimSpace(os.Getenv("MINIO_PRESIGN_EXPIRY_SECONDS")); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("invalid MINIO_PRESIGN_EXPIRY_SECONDS %q", raw)
+		}
+		presignExpiry = time.Duration(seconds) * time.Second
+	}
+
 	client, err := minio.New(endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
 		Secure: useSSL,
@@ -45,9 +64,10 @@ func NewMinioFromEnv() (*MinioClient, error) {
 	}
 
 	return &MinioClient{
-		client:  client,
-		bucket:  bucket,
-		enabled: true,
+		client:        client,
+		bucket:        bucket,
+		enabled:       true,
+		presignExpiry: presignExpiry,
 	}, nil
 }
 
@@ -124,3 +144,64 @@ func SafeObjectKey(parts ...string) string {
 	}
 	return strings.Join(safeParts, "/")
 }
+
+// Open streams an object; the caller closes it
+func (m *MinioClient) Open(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	if !m.Enabled() {
+		return nil, fmt.Errorf("minio disabled")
+	}
+	object, err := m.client.GetObject(ctx, m.bucket, objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject is lazy; Stat surfaces a missing object before the caller reads
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, err
+	}
+	return object, nil
+}
+
+// PresignedDownloadURL returns a time-limited GET URL for an object that
+// browsers save as filename; expiry <= 0 uses the configured default
+func (m *MinioClient) PresignedDownloadURL(ctx context.Context, objectKey, filename string, expiry time.Duration) (string, error) {
+	if !m.Enabled() {
+		return "", fmt.Errorf("minio disabled")
+	}
+	params := url.Values{}
+	if filename != "" {
+		params.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	}
+	u, err := m.client.PresignedGetObject(ctx, m.bucket, objectKey, m.clampExpiry(expiry), params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// PresignExpiry is the default lifetime of presigned URLs
+func (m *MinioClient) PresignExpiry() time.Duration {
+	if m == nil || m.presignExpiry <= 0 {
+		return defaultPresignExpiry
+	}
+	return m.presignExpiry
+}
+
+// clampExpiry applies the default and S3's seven-day limit
+func (m *MinioClient) clampExpiry(expiry time.Duration) time.Duration {
+	if expiry <= 0 {
+		return m.PresignExpiry()
+	}
+	if expiry > maxPresignExpiry {
+		return maxPresignExpiry
+	}
+	return expiry
+}
+
+// Remove deletes an object; removing a missing object is not an error
+func (m *MinioClient) Remove(ctx context.Context, objectKey string) error {
+	if !m.Enabled() {
+		return fmt.Errorf("minio disabled")
+	}
+	return m.client.RemoveObject(ctx, m.bucket, objectKey, minio.RemoveObjectOptions{})
+}
@@ -0,0 +1,92 @@
+package textfilter
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/hooks"
+)
+
+// refreshInterval bounds how stale a cached meeting policy may be
+const refreshInterval = 30 * time.Second
+
+type cachedPolicy struct {
+	policy   *Policy
+	loadedAt time.Time
+}
+
+var (
+	mu       sync.Mutex
+	meetings = make(map[string]cachedPolicy)
+)
+
+// FromRecord converts a stored policy
+func FromRecord(record *database.MeetingTextFilter) *Policy {
+	if record == nil {
+		return nil
+	}
+	return &Policy{
+		Mode:        record.Mode,
+		Profanity:   record.Profanity,
+		Emails:      record.RedactEmails,
+		CardNumbers: record.RedactCardNumbers,
+		Terms:       record.Terms,
+	}
+}
+
+// ForMeeting returns a meeting's policy, or nil when it has none
+func ForMeeting(meetingID string) *Policy {
+	if meetingID == "" || database.DB == nil {
+		return nil
+	}
+
+	mu.Lock()
+	cached, ok := meetings[meetingID]
+	mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < refreshInterval {
+		return cached.policy
+	}
+
+	record, err := database.GetMeetingTextFilter(meetingID)
+	if err != nil {
+		// Keep the previous policy and back off until the next interval
+		log.Printf("[TextFilter] Failed to load policy of meeting %s: %v", meetingID, err)
+	} else {
+		cached.policy = FromRecord(record)
+	}
+	cached.loadedAt = time.Now()
+
+	mu.Lock()
+	meetings[meetingID] = cached
+	mu.Unlock()
+	return cached.policy
+}
+
+// Invalidate forces the next lookup of a meeting's policy to reload it
+func Invalidate(meetingID string) {
+	mu.Lock()
+	delete(meetings, meetingID)
+	mu.Unlock()
+}
+
+// Register adds the meeting redaction as hooks after transcription and
+// translation, so redacted text is what gets broadcast, stored and
+// embedded. Register it before other hooks so they only see redacted
+// text too.
+func Register() error {
+	redact := hooks.HookFunc(func(ctx context.Context, e *hooks.Event) error {
+		if e.Source == hooks.SourceMeeting {
+			e.Text = ForMeeting(e.MeetingID).Apply(e.Text)
+		}
+		return nil
+	})
+	for _, point := range []hooks.Point{hooks.PostTranscription, hooks.PostTranslation} {
+		if err := hooks.Register(point, "textfilter", redact); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Package textfilter redacts profanity and sensitive terms (email addresses,
+// card numbers, names) from captions before they are broadcast, stored or
+// embedded. Each meeting can set its own policy; text of meetings without
+// one passes through unchanged.
+package textfilter
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"realtime-caption-translator/internal/profanity"
+)
+
+// Redaction modes
+const (
+	ModeMask   = "mask"   // replace redacted text with a placeholder
+	ModeRemove = "remove" // drop redacted text
+)
+
+// Placeholders for masked text. Profanity is starred out letter by letter.
+const (
+	EmailPlaceholder = "[email]"
+	CardPlaceholder  = "[card number]"
+	TermPlaceholder  = "[redacted]"
+)
+
+// Policy says what to redact and how
+type Policy struct {
+	Mode        string
+	Profanity   string // a profanity filter level
+	Emails      bool
+	CardNumbers bool
+	Terms       []string // matched as whole words, case-insensitively
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// 13 to 19 digits, optionally grouped with spaces or dashes
+	cardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+// ValidMode reports whether mode is a redaction mode
+func ValidMode(mode string) bool {
+	return mode == ModeMask || mode == ModeRemove
+}
+
+// Enabled reports whether the policy redacts anything
+func (p *Policy) Enabled() bool {
+	return p != nil && (p.Emails || p.CardNumbers || len(p.Terms) > 0 ||
+		(p.Profanity != "" && p.Profanity != profanity.LevelOff))
+}
+
+// span is a stretch of text to redact
+type span struct {
+	start, end  int
+	placeholder string
+}
+
+// Apply returns text with what the policy covers masked or removed
+func (p *Policy) Apply(text string) string {
+	if !p.Enabled() || strings.TrimSpace(text) == "" {
+		return text
+	}
+
+	var spans []span
+	if p.Emails {
+		for _, loc := range emailPattern.FindAllStringIndex(text, -1) {
+			spans = append(spans, span{loc[0], loc[1], EmailPlaceholder})
+		}
+	}
+	if p.CardNumbers {
+		for _, loc := range cardPattern.FindAllStringIndex(text, -1) {
+			if luhnValid(text[loc[0]:loc[1]]) {
+				spans = append(spans, span{loc[0], loc[1], CardPlaceholder})
+			}
+		}
+	}
+
+	words := wordSpans(text)
+	if len(p.Terms) > 0 {
+		spans = append(spans, termSpans(text, words, p.Terms)...)
+	}
+	for _, w := range words {
+		if profanity.Blocked(text[w.start:w.end], p.Profanity) {
+			spans = append(spans, span{w.start, w.end, stars(text[w.start:w.end])})
+		}
+	}
+	if len(spans) == 0 {
+		return text
+	}
+
+	// Earlier spans win; a span inside or across an earlier one is dropped
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var builder strings.Builder
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			continue
+		}
+		builder.WriteString(text[last:s.start])
+		if p.Mode != ModeRemove {
+			builder.WriteString(s.placeholder)
+		}
+		last = s.end
+	}
+	builder.WriteString(text[last:])
+
+	if p.Mode == ModeRemove {
+		return strings.Join(strings.Fields(builder.String()), " ")
+	}
+	return builder.String()
+}
+
+// wordSpans returns the words of text: runs of letters, digits and
+// apostrophes
+func wordSpans(text string) []span {
+	var words []span
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' {
+			if start == -1 {
+				start = i
+			}
+			continue
+		}
+		if start != -1 {
+			words = append(words, span{start: start, end: i})
+			start = -1
+		}
+	}
+	if start != -1 {
+		words = append(words, span{start: start, end: len(text)})
+	}
+	return words
+}
+
+// termSpans finds the terms in text, each matching a run of whole words
+// case-insensitively ("Jane Doe" matches "jane doe" but not "Janet")
+func termSpans(text string, words []span, terms []string) []span {
+	var spans []span
+	for _, term := range terms {
+		termWords := wordSpans(term)
+		if len(termWords) == 0 {
+			continue
+		}
+		for i := 0; i+len(termWords) <= len(words); i++ {
+			matched := true
+			for j, tw := range termWords {
+				w := words[i+j]
+				if !strings.EqualFold(text[w.start:w.end], term[tw.start:tw.end]) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				spans = append(spans, span{words[i].start, words[i+len(termWords)-1].end, TermPlaceholder})
+			}
+		}
+	}
+	return spans
+}
+
+// stars masks a word, keeping its first letter so the sentence still reads
+func stars(word string) string {
+	runes := []rune(word)
+	return string(runes[0]) + strings.Repeat("*", len(runes)-1)
+}
+
+// luhnValid reports whether the digits of s pass the Luhn check card
+// numbers carry, which keeps other long numbers from being redacted
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
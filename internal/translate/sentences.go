@@ -0,0 +1,142 @@
+package translate
+
+import (
+	"strings"
+	"unicode"
+)
+
+// sentenceEnders end a sentence when followed by whitespace or the end of
+// the text: Latin punctuation, the Devanagari danda, and the Arabic/Urdu
+// question mark and full stop
+const sentenceEnders = ".!?…।؟۔"
+
+// fullWidthEnders end a sentence on their own; Chinese and Japanese do not
+// put spaces between sentences
+const fullWidthEnders = "。！？"
+
+// abbreviations are words whose trailing period does not end a sentence,
+// keyed by language; "" applies to every language
+var abbreviations = map[string]map[string]bool{
+	"":   {"e.g.": true, "i.e.": true, "etc.": true, "vs.": true, "no.": true},
+	"en": {"mr.": true, "mrs.": true, "ms.": true, "dr.": true, "prof.": true, "st.": true, "jr.": true, "sr.": true, "approx.": true},
+	"es": {"sr.": true, "sra.": true, "srta.": true, "dr.": true, "dra.": true, "ud.": true, "uds.": true, "pág.": true},
+	"fr": {"m.": true, "mme.": true, "mlle.": true, "dr.": true, "p.": true},
+	"de": {"hr.": true, "fr.": true, "dr.": true, "z.b.": true, "usw.": true, "bzw.": true, "ca.": true, "nr.": true},
+	"it": {"sig.": true, "sig.ra.": true, "dott.": true, "ecc.": true},
+	"pt": {"sr.": true, "sra.": true, "dr.": true, "dra.": true},
+}
+
+// minSentenceWords merges shorter sentences ("Okay.", "Yes.") into the next
+// one so they are not translated and spoken on their own
+const minSentenceWords = 2
+
+// SplitSentences splits a finalized transcript into sentences using the
+// punctuation conventions of lang. Text without sentence breaks is returned
+// as a single sentence.
+func SplitSentences(text, lang string) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	base := baseLanguage(lang)
+	runes := []rune(text)
+
+	var sentences []string
+	start := 0
+	for i, r := range runes {
+		end := false
+		switch {
+		case strings.ContainsRune(fullWidthEnders, r):
+			end = true
+		case strings.ContainsRune(sentenceEnders, r):
+			// Require a following space so decimals, URLs and runs of
+			// punctuation ("?!", "...") stay together
+			end = i+1 == len(runes) || unicode.IsSpace(runes[i+1])
+			if end && r == '.' && isAbbreviation(lastWord(runes[start:i+1]), base) {
+				end = false
+			}
+		}
+		if !end {
+			continue
+		}
+		if sentence := strings.TrimSpace(string(runes[start : i+1])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = i + 1
+	}
+	if rest := strings.TrimSpace(string(runes[start:])); rest != "" {
+		sentences = append(sentences, rest)
+	}
+
+	if usesSpaces(base) {
+		sentences = mergeShortSentences(sentences)
+	}
+	return sentences
+}
+
+// JoinSentences joins translated sentences back into one text, without
+// spaces for languages that write sentences back to back
+func JoinSentences(sentences []string, lang string) string {
+	if usesSpaces(baseLanguage(lang)) {
+		return strings.Join(sentences, " ")
+	}
+	return strings.Join(sentences, "")
+}
+
+func mergeShortSentences(sentences []string) []string {
+	merged := make([]string, 0, len(sentences))
+	pending := ""
+	for _, sentence := range sentences {
+		if pending != "" {
+			sentence = pending + " " + sentence
+			pending = ""
+		}
+		if len(strings.Fields(sentence)) < minSentenceWords {
+			pending = sentence
+			continue
+		}
+		merged = append(merged, sentence)
+	}
+	if pending != "" {
+		if len(merged) == 0 {
+			return []string{pending}
+		}
+		merged[len(merged)-1] += " " + pending
+	}
+	return merged
+}
+
+func isAbbreviation(word, lang string) bool {
+	// Initials ("J. Smith") rarely end a sentence
+	if letters := []rune(strings.TrimSuffix(word, ".")); len(letters) == 1 && unicode.IsUpper(letters[0]) {
+		return true
+	}
+	lower := strings.ToLower(word)
+	return abbreviations[""][lower] || abbreviations[lang][lower]
+}
+
+func lastWord(runes []rune) string {
+	fields := strings.Fields(string(runes))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
+
+// usesSpaces reports whether lang separates sentences with spaces
+func usesSpaces(lang string) bool {
+	switch lang {
+	case "zh", "ja":
+		return false
+	}
+	return true
+}
+
+// baseLanguage reduces a tag such as "zh-CN" or "pt_BR" to its language
+func baseLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
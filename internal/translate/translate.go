@@ -7,6 +7,11 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
+
+	"realtime-caption-translator/internal/httpx"
+	"realtime-caption-translator/internal/metrics"
+	"realtime-caption-translator/internal/ratelimit"
 )
 
 type Translator interface {
@@ -29,6 +34,23 @@ func (s Stub) TranslateWithSource(text, sourceLang, targetLang string) (string,
 type HTTPTranslator struct {
 	BaseURL    string
 	HTTPClient *http.Client
+
+	// Limiter, when set, bounds concurrent requests and rejects calls once
+	// its queue is full; Priority decides how this translator's calls queue
+	Limiter  *ratelimit.Limiter
+	Priority ratelimit.Priority
+
+	// Breaker, when set, retries transient failures and stops calling the
+	// service while it is unhealthy
+	Breaker *httpx.Breaker
+}
+
+// WithPriority returns a copy of the translator that queues at the given
+// priority while sharing the same HTTP client, limiter and breaker
+func (h *HTTPTranslator) WithPriority(priority ratelimit.Priority) *HTTPTranslator {
+	clone := *h
+	clone.Priority = priority
+	return &clone
 }
 
 type translateRequest struct {
@@ -73,7 +95,15 @@ func (h *HTTPTranslator) TranslateWithSource(text, sourceLang, targetLang string
 		client = http.DefaultClient
 	}
 
-	resp, err := client.Do(httpReq)
+	release, err := h.Limiter.TryAcquire(h.Priority)
+	if err != nil {
+		return "", fmt.Errorf("translate: %w", err)
+	}
+	defer release()
+
+	start := time.Now()
+	resp, err := h.Breaker.Do(httpReq, client.Do)
+	metrics.ObserveRequest(metrics.TranslationLatency, metrics.StageTranslation, "translate", start, resp, err)
 	if err != nil {
 		return "", fmt.Errorf("do request: %w", err)
 	}
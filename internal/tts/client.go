@@ -8,12 +8,37 @@ import (
 	"mime/multipart"
 	"net/http"
 	"time"
+
+	"realtime-caption-translator/internal/httpx"
+	"realtime-caption-translator/internal/metrics"
+	"realtime-caption-translator/internal/ratelimit"
+	"realtime-caption-translator/internal/replica"
 )
 
 // Client handles text-to-speech requests
 type Client struct {
 	BaseURL string
 	HTTP    *http.Client
+
+	// Limiter, when set, bounds concurrent requests and rejects calls once
+	// its queue is full; Priority decides how this client's calls queue
+	Limiter  *ratelimit.Limiter
+	Priority ratelimit.Priority
+
+	// Replicas, when set, spreads calls over the service's registered
+	// replicas instead of always using BaseURL
+	Replicas *replica.Pool
+
+	// Breaker, when set, retries transient failures and stops calling the
+	// service while it is unhealthy
+	Breaker *httpx.Breaker
+
+	// Voice is the named speaker to request; empty uses the service default
+	Voice string
+
+	// Policy, when set, filters text and restricts voices and cloning
+	// before any request goes out
+	Policy *Policy
 }
 
 // New creates a new TTS client
@@ -24,10 +49,40 @@ func New(baseURL string) *Client {
 	}
 }
 
+// WithPriority returns a copy of the client that queues at the given priority
+// while sharing the same HTTP client, limiter and breaker
+func (c *Client) WithPriority(priority ratelimit.Priority) *Client {
+	clone := *c
+	clone.Priority = priority
+	return &clone
+}
+
+// do sends a request to the TTS service once the limiter admits it and
+// records its latency. The slot is held until the response body is closed.
+func (c *Client) do(operation string, req *http.Request) (*http.Response, error) {
+	release, err := c.Limiter.TryAcquire(c.Priority)
+	if err != nil {
+		return nil, fmt.Errorf("tts %s: %w", operation, err)
+	}
+
+	start := time.Now()
+	resp, err := c.Breaker.Do(req, func(req *http.Request) (*http.Response, error) {
+		return c.Replicas.Do(c.HTTP, c.BaseURL, req)
+	})
+	metrics.ObserveRequest(metrics.TTSLatency, metrics.StageTTS, operation, start, resp, err)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = ratelimit.ReleaseOnClose(resp.Body, release)
+	return resp, nil
+}
+
 // SynthesizeRequest represents a TTS request
 type SynthesizeRequest struct {
 	Text     string `json:"text"`
 	Language string `json:"language"`
+	Voice    string `json:"voice,omitempty"`
 }
 
 // Synthesize converts text to speech audio (MP3)
@@ -36,9 +91,15 @@ func (c *Client) Synthesize(text, language string) ([]byte, error) {
 		return nil, fmt.Errorf("text cannot be empty")
 	}
 
+	text, voice, err := c.Policy.prepare(text, c.Voice, false)
+	if err != nil {
+		return nil, err
+	}
+
 	reqBody := SynthesizeRequest{
 		Text:     text,
 		Language: language,
+		Voice:    voice,
 	}
 
 	body, err := json.Marshal(reqBody)
@@ -52,7 +113,7 @@ func (c *Client) Synthesize(text, language string) ([]byte, error) {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.do("synthesize", req)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
@@ -80,6 +141,10 @@ func (c *Client) SynthesizeWithVoice(text, language string, referenceAudio []byt
 	if len(referenceAudio) == 0 {
 		return nil, fmt.Errorf("reference audio cannot be empty")
 	}
+	text, _, err := c.Policy.prepare(text, "", true)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create multipart form data
 	body := &bytes.Buffer{}
@@ -115,7 +180,7 @@ func (c *Client) SynthesizeWithVoice(text, language string, referenceAudio []byt
 	}
 	req.Header.Set("Content-Type", contentType)
 
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.do("synthesize_with_voice", req)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
@@ -0,0 +1,70 @@
+package tts
+
+import (
+	"errors"
+	"strings"
+
+	"realtime-caption-translator/internal/profanity"
+)
+
+// Policy errors; callers that clone voices fall back to standard synthesis
+var (
+	ErrCloningNotAllowed = errors.New("voice cloning is disabled by policy")
+	ErrVoiceNotAllowed   = errors.New("voice is not allowed by policy")
+)
+
+// Policy restricts what a client may synthesize, e.g. for an organization
+// that needs kid-safe output
+type Policy struct {
+	AllowedVoices []string // empty allows every voice
+	DefaultVoice  string   // used when no voice, or a disallowed one, is requested
+	AllowCloning  bool
+	Profanity     string // profanity filter level applied to the text
+}
+
+// WithPolicy returns a copy of the client that enforces policy on every
+// request; nil removes any policy
+func (c *Client) WithPolicy(policy *Policy) *Client {
+	clone := *c
+	clone.Policy = policy
+	return &clone
+}
+
+// prepare filters text and resolves the voice before a request goes out
+func (p *Policy) prepare(text, voice string, cloning bool) (string, string, error) {
+	if p == nil {
+		return text, voice, nil
+	}
+	if cloning && !p.AllowCloning {
+		return "", "", ErrCloningNotAllowed
+	}
+
+	text = profanity.Filter(text, p.Profanity)
+	if strings.TrimSpace(text) == "" {
+		return "", "", errors.New("text is empty after profanity filtering")
+	}
+
+	if voice == "" {
+		voice = p.DefaultVoice
+	}
+	if voice != "" && !p.AllowsVoice(voice) {
+		if p.DefaultVoice == "" || !p.AllowsVoice(p.DefaultVoice) {
+			return "", "", ErrVoiceNotAllowed
+		}
+		voice = p.DefaultVoice
+	}
+	return text, voice, nil
+}
+
+// AllowsVoice reports whether the policy permits the named voice
+func (p *Policy) AllowsVoice(voice string) bool {
+	if len(p.AllowedVoices) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedVoices {
+		if strings.EqualFold(allowed, voice) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package video
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"realtime-caption-translator/internal/metrics"
+)
+
+// InUseFunc lists temp files that must survive a sweep however old they are,
+// such as the inputs of queued or running jobs
+type InUseFunc func() ([]string, error)
+
+// SweepTempDir deletes files under TempDir that were last modified more than
+// ttl ago, then the subdirectories left empty. Outputs that failed mid-
+// pipeline or were never downloaded would otherwise stay forever. Files
+// listed by inUse are kept; if inUse fails nothing is deleted. It returns how
+// many files and bytes were reclaimed.
+func (p *Processor) SweepTempDir(ttl time.Duration, inUse InUseFunc) (int, int64, error) {
+	cutoff := time.Now().Add(-ttl)
+	keep := make(map[string]bool)
+	if inUse != nil {
+		paths, err := inUse()
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, path := range paths {
+			if abs, err := filepath.Abs(path); err == nil {
+				keep[abs] = true
+			}
+		}
+	}
+	var files int
+	var reclaimed int64
+	var dirs []string
+
+	err := filepath.WalkDir(p.TempDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// A file removed while walking is not an error
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != p.TempDir {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			return nil
+		}
+		if abs, err := filepath.Abs(path); err == nil && keep[abs] {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			log.Printf("Temp janitor could not remove %s: %v", path, err)
+			return nil
+		}
+		files++
+		reclaimed += info.Size()
+		return nil
+	})
+
+	// Deepest first so nested empty directories go too; Remove fails on
+	// directories that still hold files
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if info, statErr := os.Stat(dirs[i]); statErr == nil && info.ModTime().Before(cutoff) {
+			os.Remove(dirs[i])
+		}
+	}
+
+	metrics.TempFilesReclaimed.Add(float64(files))
+	metrics.TempBytesReclaimed.Add(float64(reclaimed))
+	return files, reclaimed, err
+}
+
+// StartJanitor runs SweepTempDir on an interval until stop is closed
+func (p *Processor) StartJanitor(interval, ttl time.Duration, inUse InUseFunc, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			files, reclaimed, err := p.SweepTempDir(ttl, inUse)
+			if err != nil {
+				log.Printf("Temp janitor sweep failed: %v", err)
+			}
+			if files > 0 {
+				log.Printf("Temp janitor removed %d file(s), %d bytes older than %s", files, reclaimed, ttl)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -2,11 +2,16 @@ package video
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+
+	"realtime-caption-translator/internal/metrics"
 )
 
 // Processor handles video file processing and audio extraction
@@ -50,7 +55,7 @@ func (p *Processor) ExtractAudio(videoPath string) (*ExtractAudioResult, error)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := runFFmpeg("extract_audio", cmd); err != nil {
 		return nil, fmt.Errorf("ffmpeg error: %w, stderr: %s", err, stderr.String())
 	}
 
@@ -99,10 +104,27 @@ func (p *Processor) getVideoDuration(videoPath string) (float64, error) {
 	return duration, nil
 }
 
+// AudioFit controls how dubbed audio is fitted to the video length
+type AudioFit string
+
+const (
+	// AudioFitLoop loops audio that is shorter than the video and trims audio that is longer
+	AudioFitLoop AudioFit = "loop"
+	// AudioFitStretch time-stretches the audio (pitch preserved) to the video length
+	AudioFitStretch AudioFit = "stretch"
+)
+
+// ReplaceOptions controls how dubbed audio is put into a video
+type ReplaceOptions struct {
+	Fit         AudioFit          // loop/trim or time-stretch to the video duration (ReplaceAudio only)
+	MixOriginal bool              // keep the original track underneath, ducked while the dub speaks
+	Metadata    map[string]string // container tags, e.g. provenance labels
+}
+
 // ReplaceAudio replaces the audio track in a video with new audio
 // audioData should be MP3 audio bytes
 // Returns the path to the output video file (caller must delete it)
-func (p *Processor) ReplaceAudio(videoPath string, audioData []byte) (string, error) {
+func (p *Processor) ReplaceAudio(videoPath string, audioData []byte, opts ReplaceOptions) (string, error) {
 	// Save audio data to temp file
 	tempAudio := filepath.Join(p.TempDir, fmt.Sprintf("tts_audio_%d.mp3", os.Getpid()))
 	defer os.Remove(tempAudio)
@@ -132,51 +154,168 @@ func (p *Processor) ReplaceAudio(videoPath string, audioData []byte) (string, er
 	}
 
 	// Use ffmpeg to replace audio
-	// If audio is shorter than video, loop it; if longer, trim it
-	var cmd *exec.Cmd
-	if audioDuration < videoDuration {
-		// Audio is shorter - loop it to match video duration
-		cmd = exec.Command("ffmpeg",
-			"-i", videoPath,
-			"-stream_loop", "-1", // Loop audio indefinitely
-			"-i", tempAudio,
+	// If audio is shorter than video, loop it; if longer, -shortest trims it
+	args := []string{"-i", videoPath}
+	dubFilter := ""
+	if opts.Fit == AudioFitStretch && audioDuration > 0 && videoDuration > 0 {
+		// Speed the audio up or slow it down so it ends with the video;
+		// apad covers rounding so -shortest always cuts at the video's end
+		dubFilter = atempoFilter(audioDuration/videoDuration) + ",apad"
+	} else if audioDuration < videoDuration {
+		args = append(args, "-stream_loop", "-1") // Loop audio indefinitely
+	}
+	args = append(args, "-i", tempAudio)
+
+	if opts.MixOriginal && p.hasAudioStream(videoPath) {
+		if dubFilter == "" {
+			dubFilter = "anull"
+		}
+		args = append(args,
+			"-filter_complex", fmt.Sprintf("[1:a]%s[dub];%s", dubFilter, duckingFilter("dub", "aout")),
 			"-map", "0:v:0", // Use video from first input
-			"-map", "1:a:0", // Use audio from second input
-			"-c:v", "libx264", // Re-encode video to H.264 for MP4
-			"-c:a", "aac", // Encode audio to AAC
-			"-preset", "fast", // Fast encoding preset
-			"-crf", "23", // Quality setting (lower = better quality, 23 is default)
-			"-shortest", // End when shortest stream ends (video)
-			"-y",
-			outputVideo,
+			"-map", "[aout]", // Use original audio ducked under the dub
 		)
 	} else {
-		// Audio is longer or equal - just combine and trim if needed
-		cmd = exec.Command("ffmpeg",
-			"-i", videoPath,
-			"-i", tempAudio,
+		args = append(args,
 			"-map", "0:v:0", // Use video from first input
 			"-map", "1:a:0", // Use audio from second input
-			"-c:v", "libx264", // Re-encode video to H.264 for MP4
-			"-c:a", "aac", // Encode audio to AAC
-			"-preset", "fast", // Fast encoding preset
-			"-crf", "23", // Quality setting
-			"-shortest", // End when video ends
-			"-y",
-			outputVideo,
 		)
+		if dubFilter != "" {
+			args = append(args, "-filter:a", dubFilter)
+		}
 	}
 
+	args = append(args,
+		"-c:v", "libx264", // Re-encode video to H.264 for MP4
+		"-c:a", "aac", // Encode audio to AAC
+		"-preset", "fast", // Fast encoding preset
+		"-crf", "23", // Quality setting (lower = better quality, 23 is default)
+		"-shortest", // End when shortest stream ends (video)
+	)
+	args = append(args, metadataArgs(opts.Metadata)...)
+	args = append(args, "-y", outputVideo)
+
+	cmd := exec.Command("ffmpeg", args...)
+
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := runFFmpeg("replace_audio", cmd); err != nil {
 		return "", fmt.Errorf("ffmpeg error: %w, stderr: %s", err, stderr.String())
 	}
 
 	return outputVideo, nil
 }
 
+// DubSegment is synthesized speech to place at a point in the video
+type DubSegment struct {
+	Start float64 // seconds from the start of the video
+	End   float64 // end of the original speech; bounds how long the clip may run
+	Audio []byte  // MP3 or WAV bytes
+}
+
+// maxSegmentSpeedup limits how much a clip is sped up to fit its slot;
+// beyond this it is allowed to run into the following pause instead
+const maxSegmentSpeedup = 1.5
+
+// DubSegments builds a dubbed video by placing each clip at its original
+// timestamp with adelay and mixing them with amix, so the source's pauses and
+// pacing are kept. A clip longer than the time until the next segment is
+// sped up (up to maxSegmentSpeedup) to avoid talking over it. opts.Fit is
+// ignored; timing comes from the segments.
+// Returns the path to the output video file (caller must delete it)
+func (p *Processor) DubSegments(videoPath string, segments []DubSegment, opts ReplaceOptions) (string, error) {
+	if len(segments) == 0 {
+		return "", fmt.Errorf("no segments to dub")
+	}
+	segments = append([]DubSegment(nil), segments...)
+	sort.SliceStable(segments, func(i, j int) bool { return segments[i].Start < segments[j].Start })
+
+	videoDuration, err := p.getVideoDuration(videoPath)
+	if err != nil {
+		return "", fmt.Errorf("get video duration: %w", err)
+	}
+
+	baseNameWithoutExt := filepath.Base(videoPath)
+	if idx := strings.LastIndex(baseNameWithoutExt, "."); idx != -1 {
+		baseNameWithoutExt = baseNameWithoutExt[:idx]
+	}
+	outputVideo := filepath.Join(p.TempDir, fmt.Sprintf("dubbed_%d_%s.mp4", os.Getpid(), baseNameWithoutExt))
+
+	args := []string{"-i", videoPath}
+	var filters, labels []string
+	for i, segment := range segments {
+		clip, err := os.CreateTemp(p.TempDir, "dub_clip_*.mp3")
+		if err != nil {
+			return "", fmt.Errorf("create clip file: %w", err)
+		}
+		clipPath := clip.Name()
+		defer os.Remove(clipPath)
+		_, err = clip.Write(segment.Audio)
+		clip.Close()
+		if err != nil {
+			return "", fmt.Errorf("write clip file: %w", err)
+		}
+		args = append(args, "-i", clipPath)
+
+		// The clip may use the time until the next segment starts
+		slotEnd := videoDuration
+		if i+1 < len(segments) {
+			slotEnd = segments[i+1].Start
+		}
+		slotEnd = max(slotEnd, segment.End)
+
+		filter := fmt.Sprintf("[%d:a]aresample=44100", i+1)
+		if clipDuration, err := p.getAudioDuration(clipPath); err == nil && slotEnd > segment.Start {
+			if tempo := clipDuration / (slotEnd - segment.Start); tempo > 1.0 {
+				filter += "," + atempoFilter(min(tempo, maxSegmentSpeedup))
+			}
+		}
+		delayMs := int64(segment.Start * 1000)
+		filter += fmt.Sprintf(",adelay=%d|%d[s%d]", delayMs, delayMs, i)
+		filters = append(filters, filter)
+		labels = append(labels, fmt.Sprintf("[s%d]", i))
+	}
+
+	// normalize=0 keeps each clip at full volume (clips rarely overlap);
+	// apad + -shortest make the track exactly as long as the video
+	filters = append(filters, fmt.Sprintf("%samix=inputs=%d:duration=longest:dropout_transition=0:normalize=0,apad[dub]",
+		strings.Join(labels, ""), len(labels)))
+	output := "[dub]"
+	if opts.MixOriginal && p.hasAudioStream(videoPath) {
+		filters = append(filters, duckingFilter("dub", "aout"))
+		output = "[aout]"
+	}
+
+	args = append(args,
+		"-filter_complex", strings.Join(filters, ";"),
+		"-map", "0:v:0", // Use video from first input
+		"-map", output, // Use the mixed dub track
+		"-c:v", "libx264", // Re-encode video to H.264 for MP4
+		"-c:a", "aac", // Encode audio to AAC
+		"-preset", "fast", // Fast encoding preset
+		"-crf", "23", // Quality setting
+		"-shortest", // End when video ends
+	)
+	args = append(args, metadataArgs(opts.Metadata)...)
+	args = append(args, "-y", outputVideo)
+
+	cmd := exec.Command("ffmpeg", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := runFFmpeg("dub_segments", cmd); err != nil {
+		return "", fmt.Errorf("ffmpeg error: %w, stderr: %s", err, stderr.String())
+	}
+
+	return outputVideo, nil
+}
+
+// ProbeDuration returns the duration in seconds of any media file ffprobe can read
+func (p *Processor) ProbeDuration(path string) (float64, error) {
+	return p.getAudioDuration(path)
+}
+
 // getAudioDuration gets the duration of an audio file in seconds
 func (p *Processor) getAudioDuration(audioPath string) (float64, error) {
 	cmd := exec.Command("ffprobe",
@@ -220,7 +359,7 @@ func (p *Processor) ConvertAudioToWAV(audioPath string) (*ExtractAudioResult, er
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := runFFmpeg("convert_audio", cmd); err != nil {
 		return nil, fmt.Errorf("ffmpeg error: %w, stderr: %s", err, stderr.String())
 	}
 
@@ -267,7 +406,7 @@ func (p *Processor) ConvertAudioToWAVWithEnhancement(audioPath string, enhance b
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := runFFmpeg("convert_audio", cmd); err != nil {
 		return nil, fmt.Errorf("ffmpeg error: %w, stderr: %s", err, stderr.String())
 	}
 
@@ -292,6 +431,17 @@ func (p *Processor) ConvertAudioToWAVWithEnhancement(audioPath string, enhance b
 	}, nil
 }
 
+// runFFmpeg runs an ffmpeg command and records how long it took
+func runFFmpeg(operation string, cmd *exec.Cmd) error {
+	start := time.Now()
+	err := cmd.Run()
+	metrics.FFmpegDuration.ObserveSince(start, operation)
+	if err != nil {
+		metrics.StageErrors.Inc(metrics.StageFFmpeg)
+	}
+	return err
+}
+
 // CheckFFmpegInstalled verifies that ffmpeg and ffprobe are available
 func CheckFFmpegInstalled() error {
 	if _, err := exec.LookPath("ffmpeg"); err != nil {
@@ -302,3 +452,109 @@ func CheckFFmpegInstalled() error {
 	}
 	return nil
 }
+
+// backgroundVolume is the level of the original track under a dub before
+// ducking; it dips further (sidechain compression) whenever the dub speaks
+const backgroundVolume = 0.6
+
+// duckingFilter mixes the original audio (input 0) under the dub labelled
+// dubLabel, using the dub as the sidechain so the background dips while it
+// speaks. The result is labelled outLabel and padded so -shortest ends on
+// the video.
+func duckingFilter(dubLabel, outLabel string) string {
+	return fmt.Sprintf("[%[1]s]asplit=2[sc][voice];"+
+		"[0:a]volume=%[3]g[bg];"+
+		"[bg][sc]sidechaincompress=threshold=0.02:ratio=8:attack=20:release=400[ducked];"+
+		"[ducked][voice]amix=inputs=2:duration=longest:dropout_transition=0:normalize=0,apad[%[2]s]",
+		dubLabel, outLabel, backgroundVolume)
+}
+
+// hasAudioStream reports whether a media file has at least one audio stream
+func (p *Processor) hasAudioStream(path string) bool {
+	cmd := exec.Command("ffprobe",
+		"-v", "error",
+		"-select_streams", "a",
+		"-show_entries", "stream=index",
+		"-of", "csv=p=0",
+		path,
+	)
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+
+	if err := cmd.Run(); err != nil {
+		return false
+	}
+	return strings.TrimSpace(out.String()) != ""
+}
+
+// atempoFilter builds an ffmpeg atempo chain for a speed factor (>1 speeds
+// up). A single atempo stage only accepts 0.5-2.0, so larger changes are
+// chained, e.g. 3x becomes atempo=2.0,atempo=1.5.
+func atempoFilter(tempo float64) string {
+	var stages []string
+	for tempo > 2.0 {
+		stages = append(stages, "atempo=2.0")
+		tempo /= 2.0
+	}
+	for tempo < 0.5 {
+		stages = append(stages, "atempo=0.5")
+		tempo /= 0.5
+	}
+	stages = append(stages, fmt.Sprintf("atempo=%.6f", tempo))
+	return strings.Join(stages, ",")
+}
+
+// metadataArgs turns tags into ffmpeg -metadata flags. use_metadata_tags keeps
+// custom keys in MP4, which otherwise only stores a fixed set of atoms.
+func metadataArgs(metadata map[string]string) []string {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := []string{"-movflags", "+use_metadata_tags"}
+	for _, key := range keys {
+		args = append(args, "-metadata", fmt.Sprintf("%s=%s", key, metadata[key]))
+	}
+	return args
+}
+
+// ReadMetadata returns the container-level tags of a media file
+func (p *Processor) ReadMetadata(path string) (map[string]string, error) {
+	cmd := exec.Command("ffprobe",
+		"-v", "error",
+		"-show_entries", "format_tags",
+		"-of", "json",
+		path,
+	)
+
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffprobe error: %w, stderr: %s", err, stderr.String())
+	}
+
+	var probe struct {
+		Format struct {
+			Tags map[string]string `json:"tags"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &probe); err != nil {
+		return nil, fmt.Errorf("parse ffprobe output: %w", err)
+	}
+
+	// Some muxers upper-case keys; normalize so lookups are stable
+	tags := make(map[string]string, len(probe.Format.Tags))
+	for key, value := range probe.Format.Tags {
+		tags[strings.ToLower(key)] = value
+	}
+	return tags, nil
+}
@@ -0,0 +1,151 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// Reference clip selection for per-speaker voice cloning
+const (
+	minReferenceSpan    = 1.0  // seconds; shorter spans are mostly breaths and fillers
+	maxReferenceSeconds = 20.0 // cloning models need only a few seconds of a voice
+)
+
+// SpeakerSpan is a stretch of speech by one diarized speaker (seconds from
+// the start)
+type SpeakerSpan struct {
+	Speaker string
+	Start   float64
+	End     float64
+}
+
+// SpeakerReferences cuts a reference clip for each speaker out of PCM WAV
+// audio, for cloning every speaker's voice separately. A clip joins the
+// speaker's longest spans that no other speaker talks over, up to
+// maxReferenceSeconds; a speaker who is never heard alone gets their longest
+// spans regardless. Speakers with no usable span are left out.
+func SpeakerReferences(wav []byte, spans []SpeakerSpan) (map[string][]byte, error) {
+	format, pcm, err := parseWAV(wav)
+	if err != nil {
+		return nil, err
+	}
+	blockAlign := int(format.blockAlign)
+	bytesPerSecond := float64(format.sampleRate) * float64(blockAlign)
+
+	bySpeaker := make(map[string][]SpeakerSpan)
+	for _, span := range spans {
+		if span.Speaker == "" || span.End-span.Start < minReferenceSpan {
+			continue
+		}
+		bySpeaker[span.Speaker] = append(bySpeaker[span.Speaker], span)
+	}
+
+	references := make(map[string][]byte, len(bySpeaker))
+	for speaker, own := range bySpeaker {
+		var clean []SpeakerSpan
+		for _, span := range own {
+			if !overlapsOtherSpeaker(span, spans) {
+				clean = append(clean, span)
+			}
+		}
+		if len(clean) == 0 {
+			clean = own
+		}
+		sort.SliceStable(clean, func(i, j int) bool {
+			return clean[i].End-clean[i].Start > clean[j].End-clean[j].Start
+		})
+
+		var clip []byte
+		total := 0.0
+		for _, span := range clean {
+			length := min(span.End-span.Start, maxReferenceSeconds-total)
+			start := int(span.Start*bytesPerSecond) / blockAlign * blockAlign
+			end := int((span.Start+length)*bytesPerSecond) / blockAlign * blockAlign
+			if start >= len(pcm) {
+				continue
+			}
+			end = min(end, len(pcm))
+			clip = append(clip, pcm[start:end]...)
+			total += length
+			if total >= maxReferenceSeconds {
+				break
+			}
+		}
+		if len(clip) > 0 {
+			references[speaker] = format.encode(clip)
+		}
+	}
+	return references, nil
+}
+
+// overlapsOtherSpeaker reports whether another speaker talks during span
+func overlapsOtherSpeaker(span SpeakerSpan, spans []SpeakerSpan) bool {
+	for _, other := range spans {
+		if other.Speaker != span.Speaker && other.Start < span.End && other.End > span.Start {
+			return true
+		}
+	}
+	return false
+}
+
+// wavFormat is the fmt chunk of a PCM WAV file
+type wavFormat struct {
+	channels      uint16
+	sampleRate    uint32
+	blockAlign    uint16
+	bitsPerSample uint16
+}
+
+// parseWAV returns the format and sample data of a PCM WAV file
+func parseWAV(data []byte) (wavFormat, []byte, error) {
+	var format wavFormat
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return format, nil, fmt.Errorf("not a WAV file")
+	}
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := data[pos+8:]
+		if size > len(body) {
+			size = len(body)
+		}
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return format, nil, fmt.Errorf("invalid WAV fmt chunk")
+			}
+			format.channels = binary.LittleEndian.Uint16(body[2:4])
+			format.sampleRate = binary.LittleEndian.Uint32(body[4:8])
+			format.blockAlign = binary.LittleEndian.Uint16(body[12:14])
+			format.bitsPerSample = binary.LittleEndian.Uint16(body[14:16])
+		case "data":
+			if format.blockAlign == 0 || format.sampleRate == 0 {
+				return format, nil, fmt.Errorf("WAV data before fmt chunk")
+			}
+			return format, body[:size], nil
+		}
+		pos += 8 + size + size%2
+	}
+	return format, nil, fmt.Errorf("WAV file has no data chunk")
+}
+
+// encode wraps PCM sample data in a WAV header of this format
+func (f wavFormat) encode(pcm []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(36+len(pcm)))
+	b.WriteString("WAVEfmt ")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(1)) // PCM
+	_ = binary.Write(&b, binary.LittleEndian, f.channels)
+	_ = binary.Write(&b, binary.LittleEndian, f.sampleRate)
+	_ = binary.Write(&b, binary.LittleEndian, f.sampleRate*uint32(f.blockAlign))
+	_ = binary.Write(&b, binary.LittleEndian, f.blockAlign)
+	_ = binary.Write(&b, binary.LittleEndian, f.bitsPerSample)
+	b.WriteString("data")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(len(pcm)))
+	b.Write(pcm)
+	return b.Bytes()
+}
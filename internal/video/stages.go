@@ -0,0 +1,131 @@
+package video
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Stages of a video job, in pipeline order
+const (
+	StageExtraction    = "extraction"
+	StageTranscription = "transcription"
+	StageTranslation   = "translation"
+	StageTTS           = "tts"
+)
+
+// Stages lists the stages of a video job in order
+var Stages = []string{StageExtraction, StageTranscription, StageTranslation, StageTTS}
+
+// StageIndex returns the position of a stage in Stages, or -1
+func StageIndex(stage string) int {
+	for i, s := range Stages {
+		if s == stage {
+			return i
+		}
+	}
+	return -1
+}
+
+// StageState is what a video job has produced so far. Completed names the
+// last stage whose output is stored.
+type StageState struct {
+	Completed     string          `json:"completed"`
+	Duration      float64         `json:"duration"`
+	SourceLang    string          `json:"sourceLang,omitempty"`
+	DetectedLang  string          `json:"detectedLang,omitempty"`
+	Transcription string          `json:"transcription,omitempty"`
+	Segments      json.RawMessage `json:"segments,omitempty"`
+	Translation   string          `json:"translation,omitempty"`
+}
+
+// Reached reports whether the output of stage is stored
+func (s *StageState) Reached(stage string) bool {
+	return s != nil && StageIndex(stage) >= 0 && StageIndex(s.Completed) >= StageIndex(stage)
+}
+
+// JobStages stores the per-stage outputs of one video job (the extracted
+// WAV, the transcription and the translation) under the temp dir, keyed by
+// session ID, so a failed job can resume from a later stage instead of
+// starting over
+type JobStages struct {
+	dir string
+}
+
+// JobStages returns the stage store of a session
+func (p *Processor) JobStages(sessionID string) *JobStages {
+	return &JobStages{dir: filepath.Join(p.TempDir, "jobs", filepath.Base(sessionID)+"_stages")}
+}
+
+func (s *JobStages) audioPath() string { return filepath.Join(s.dir, "audio.wav") }
+func (s *JobStages) statePath() string { return filepath.Join(s.dir, "state.json") }
+
+// Files lists the store's files, for keeping them out of temp sweeps while
+// their job is active
+func (s *JobStages) Files() []string {
+	return []string{s.audioPath(), s.statePath()}
+}
+
+// Load returns the stored state and extracted audio, or a nil state when
+// nothing is stored
+func (s *JobStages) Load() (*StageState, []byte, error) {
+	data, err := os.ReadFile(s.statePath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("read stage state: %w", err)
+	}
+	var state StageState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, nil, fmt.Errorf("decode stage state: %w", err)
+	}
+	audio, err := os.ReadFile(s.audioPath())
+	if err != nil {
+		return nil, nil, fmt.Errorf("read extracted audio: %w", err)
+	}
+	return &state, audio, nil
+}
+
+// SaveAudio stores the extracted audio and starts a new state
+func (s *JobStages) SaveAudio(audio []byte, duration float64) (*StageState, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("create stage directory: %w", err)
+	}
+	if err := writeFileAtomic(s.audioPath(), audio); err != nil {
+		return nil, err
+	}
+	state := &StageState{Completed: StageExtraction, Duration: duration}
+	return state, s.Save(state)
+}
+
+// Save stores state, replacing the previous one
+func (s *JobStages) Save(state *StageState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encode stage state: %w", err)
+	}
+	return writeFileAtomic(s.statePath(), data)
+}
+
+// Remove deletes the store once its job has finished
+func (s *JobStages) Remove() error {
+	return os.RemoveAll(s.dir)
+}
+
+// writeFileAtomic writes data to a temp file and renames it into place, so
+// a crash never leaves a half-written artifact
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
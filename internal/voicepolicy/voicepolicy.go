@@ -0,0 +1,80 @@
+// Package voicepolicy resolves the TTS policy that applies to an org. An
+// org's own policy replaces the server-wide default; without either, speech
+// is unrestricted.
+package voicepolicy
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+	"realtime-caption-translator/internal/tts"
+)
+
+// refreshInterval bounds how stale the cached policies may be
+const refreshInterval = 30 * time.Second
+
+var (
+	mu       sync.RWMutex
+	loadedAt time.Time
+	policies map[string]*tts.Policy
+)
+
+// For returns the policy for orgID, the default policy when the org has
+// none, or nil when neither exists
+func For(orgID string) *tts.Policy {
+	refreshIfStale()
+
+	mu.RLock()
+	defer mu.RUnlock()
+	if policy, ok := policies[orgID]; ok && orgID != "" {
+		return policy
+	}
+	return policies[""]
+}
+
+// FromRecord converts a stored policy
+func FromRecord(record database.OrgTTSPolicy) *tts.Policy {
+	return &tts.Policy{
+		AllowedVoices: record.AllowedVoices,
+		DefaultVoice:  record.DefaultVoice,
+		AllowCloning:  record.AllowVoiceCloning,
+		Profanity:     record.ProfanityFilter,
+	}
+}
+
+// Invalidate forces the next lookup to reload policies from the database
+func Invalidate() {
+	mu.Lock()
+	loadedAt = time.Time{}
+	mu.Unlock()
+}
+
+func refreshIfStale() {
+	mu.RLock()
+	fresh := time.Since(loadedAt) < refreshInterval
+	mu.RUnlock()
+	if fresh || database.DB == nil {
+		return
+	}
+
+	records, err := database.ListOrgTTSPolicies()
+	if err != nil {
+		// Keep the previous policies and back off until the next interval
+		log.Printf("[VoicePolicy] Failed to load TTS policies: %v", err)
+		mu.Lock()
+		loadedAt = time.Now()
+		mu.Unlock()
+		return
+	}
+
+	byOrg := make(map[string]*tts.Policy, len(records))
+	for _, record := range records {
+		byOrg[record.OrgID] = FromRecord(record)
+	}
+
+	mu.Lock()
+	policies, loadedAt = byOrg, time.Now()
+	mu.Unlock()
+}
@@ -0,0 +1,181 @@
+// Package webhook delivers signed JSON callbacks to URLs supplied by API
+// clients. Each request carries an HMAC-SHA256 signature over the timestamp
+// and body so receivers can check it came from this server:
+//
+//	X-Webhook-Timestamp: 1760601600
+//	X-Webhook-Signature: sha256=hex(HMAC(secret, timestamp + "." + body))
+//
+// Callback URLs are client input, so private, loopback and link-local
+// addresses are refused unless explicitly allowed, both when the URL is
+// accepted and again when it is dialed.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Request headers
+const (
+	EventHeader     = "X-Webhook-Event"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// ErrBlockedAddress is returned for callback hosts on internal networks
+var ErrBlockedAddress = errors.New("callback address is not publicly routable")
+
+// StatusError is a non-2xx response from the receiver
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("callback returned status %d", e.StatusCode)
+}
+
+// Permanent reports whether retrying a failed delivery cannot help: the
+// address is blocked or the receiver rejected the request outright.
+// Timeouts and rate limiting are worth retrying.
+func Permanent(err error) bool {
+	if errors.Is(err, ErrBlockedAddress) {
+		return true
+	}
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.StatusCode >= 400 && status.StatusCode < 500 &&
+			status.StatusCode != http.StatusRequestTimeout && status.StatusCode != http.StatusTooManyRequests
+	}
+	return false
+}
+
+// Sender signs and posts callbacks
+type Sender struct {
+	secret       []byte
+	allowPrivate bool
+	client       *http.Client
+}
+
+// New creates a sender. Without a secret callbacks are disabled, since
+// receivers would have no way to verify them.
+func New(secret string, allowPrivate bool, timeout time.Duration) *Sender {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	s := &Sender{secret: []byte(secret), allowPrivate: allowPrivate}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		// Checked at dial time too, so DNS cannot point an accepted host inward
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || blocked(ip) {
+				return ErrBlockedAddress
+			}
+			return nil
+		}
+	}
+	s.client = &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext, Proxy: nil},
+		// Redirects could lead anywhere; receivers must answer directly
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return s
+}
+
+// Enabled reports whether a signing secret is configured
+func (s *Sender) Enabled() bool {
+	return s != nil && len(s.secret) > 0
+}
+
+// ValidateURL checks that a callback URL is absolute http(s) and, unless
+// private addresses are allowed, that its host resolves to public addresses
+func (s *Sender) ValidateURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("callbackUrl must be an absolute http or https URL")
+	}
+	if u.User != nil {
+		return errors.New("callbackUrl must not contain credentials")
+	}
+	if s.allowPrivate {
+		return nil
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("callbackUrl host cannot be resolved: %w", err)
+	}
+	for _, ip := range ips {
+		if blocked(ip.IP) {
+			return ErrBlockedAddress
+		}
+	}
+	return nil
+}
+
+// Send posts body to target as event, signed with the current time
+func (s *Sender) Send(ctx context.Context, target, event string, body []byte) error {
+	if !s.Enabled() {
+		return errors.New("webhooks are not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create callback request: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "realtime-caption-translator-webhook")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(s.secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrBlockedAddress) {
+			return ErrBlockedAddress
+		}
+		return fmt.Errorf("failed to deliver callback: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of timestamp + "." + body
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func blocked(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
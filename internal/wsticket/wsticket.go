@@ -0,0 +1,166 @@
+// Package wsticket issues short-lived, single-use tickets that authenticate
+// WebSocket and event-stream connections. Browsers cannot set an
+// Authorization header on those requests, so a signed-in client mints a
+// ticket with a normal REST call and passes it as a query parameter or
+// subprotocol instead of putting its long-lived access token in the URL.
+package wsticket
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"realtime-caption-translator/internal/database"
+)
+
+// Purposes a ticket can be issued for; each is accepted on its own routes
+const (
+	PurposeStream        = "stream"        // /ws, /ws/stream
+	PurposeMeeting       = "meeting"       // /ws/meeting/{roomCode}
+	PurposeRecording     = "recording"     // /ws/recording/{sessionId}
+	PurposeProgress      = "progress"      // /ws/progress/{sessionId}, /progress/{sessionId}/events
+	PurposeNotifications = "notifications" // /ws/notifications
+	PurposeCaptions      = "captions"      // /ws/captions/{roomCode}, /api/meetings/{roomCode}/captions/stream
+)
+
+// Protocol is the WebSocket subprotocol the server selects when a client
+// sends its ticket as a second "ticket.<token>" subprotocol, since browsers
+// fail the connection unless one of their offered protocols is echoed
+const Protocol = "captions.v1"
+
+// QueryParam is the query parameter carrying a ticket
+const QueryParam = "ticket"
+
+const protocolPrefix = "ticket."
+
+// Errors returned by Redeem
+var (
+	ErrInvalid = errors.New("invalid or expired ticket")
+	ErrPurpose = errors.New("ticket was issued for another connection")
+)
+
+// Ticket is an issued, not yet redeemed ticket
+type Ticket struct {
+	User      *database.User
+	Purpose   string
+	Target    string // room code or session ID the ticket is bound to; empty for any
+	ExpiresAt time.Time
+}
+
+// Store holds issued tickets in memory. Tickets are single use and expire
+// after the TTL; connections opened with one stay open after it expires.
+type Store struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	tickets map[string]Ticket
+}
+
+// NewStore creates a store whose tickets live for ttl (default 60s)
+func NewStore(ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	return &Store{ttl: ttl, tickets: make(map[string]Ticket)}
+}
+
+// ValidPurpose reports whether tickets can be issued for purpose
+func ValidPurpose(purpose string) bool {
+	switch purpose {
+	case PurposeStream, PurposeMeeting, PurposeRecording, PurposeProgress, PurposeNotifications, PurposeCaptions:
+		return true
+	}
+	return false
+}
+
+// Issue mints a ticket for user; target optionally binds it to one room or
+// session
+func (s *Store) Issue(user *database.User, purpose, target string) (string, Ticket, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", Ticket{}, err
+	}
+	token := hex.EncodeToString(buf)
+	ticket := Ticket{User: user, Purpose: purpose, Target: target, ExpiresAt: time.Now().Add(s.ttl)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, issued := range s.tickets {
+		if now.After(issued.ExpiresAt) {
+			delete(s.tickets, key)
+		}
+	}
+	s.tickets[token] = ticket
+	return token, ticket, nil
+}
+
+// Redeem consumes a ticket for a connection of the given purpose and
+// target. A ticket is removed on its first redemption, even a rejected
+// one, so a leaked ticket cannot be retried.
+func (s *Store) Redeem(token, purpose, target string) (*Ticket, error) {
+	s.mu.Lock()
+	ticket, ok := s.tickets[token]
+	delete(s.tickets, token)
+	s.mu.Unlock()
+
+	if !ok || time.Now().After(ticket.ExpiresAt) {
+		return nil, ErrInvalid
+	}
+	if ticket.Purpose != purpose || (ticket.Target != "" && ticket.Target != target) {
+		return nil, ErrPurpose
+	}
+	return &ticket, nil
+}
+
+// FromRequest returns the ticket a request carries in the query or as a
+// "ticket.<token>" subprotocol, or "" when it carries none
+func FromRequest(r *http.Request) string {
+	if token := r.URL.Query().Get(QueryParam); token != "" {
+		return token
+	}
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			if token, ok := strings.CutPrefix(strings.TrimSpace(protocol), protocolPrefix); ok {
+				return token
+			}
+		}
+	}
+	return ""
+}
+
+// Route returns the purpose and target of a connection path, or ok=false
+// for paths that do not accept tickets
+func Route(path string) (purpose, target string, ok bool) {
+	switch {
+	case path == "/ws" || path == "/ws/stream":
+		return PurposeStream, "", true
+	case path == "/ws/notifications":
+		return PurposeNotifications, "", true
+	case strings.HasPrefix(path, "/ws/meeting/"):
+		return PurposeMeeting, firstSegment(strings.TrimPrefix(path, "/ws/meeting/")), true
+	case strings.HasPrefix(path, "/ws/recording/"):
+		return PurposeRecording, firstSegment(strings.TrimPrefix(path, "/ws/recording/")), true
+	case strings.HasPrefix(path, "/ws/progress/"):
+		return PurposeProgress, firstSegment(strings.TrimPrefix(path, "/ws/progress/")), true
+	case strings.HasPrefix(path, "/progress/"):
+		return PurposeProgress, firstSegment(strings.TrimPrefix(path, "/progress/")), true
+	case strings.HasPrefix(path, "/ws/captions/"):
+		return PurposeCaptions, firstSegment(strings.TrimPrefix(path, "/ws/captions/")), true
+	case strings.HasPrefix(path, "/api/meetings/") && strings.HasSuffix(path, "/captions/stream"):
+		roomCode := strings.TrimSuffix(strings.TrimPrefix(path, "/api/meetings/"), "/captions/stream")
+		if roomCode == "" || strings.Contains(roomCode, "/") {
+			return "", "", false
+		}
+		return PurposeCaptions, roomCode, true
+	}
+	return "", "", false
+}
+
+func firstSegment(path string) string {
+	segment, _, _ := strings.Cut(path, "/")
+	return segment
+}
@@ -0,0 +1,14 @@
+-- Migration 012: Track per-chunk processing attempts for RAG retries
+
+ALTER TABLE meeting_chunks ADD COLUMN IF NOT EXISTS attempt_count INTEGER DEFAULT 0;
+ALTER TABLE meeting_chunks ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE meeting_chunks ADD COLUMN IF NOT EXISTS next_retry_at TIMESTAMP;
+ALTER TABLE meeting_chunks ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT NOW();
+
+-- Retry queue scan: failed chunks ordered by their next eligible attempt
+CREATE INDEX IF NOT EXISTS idx_chunks_retry_queue ON meeting_chunks(next_retry_at)
+    WHERE processing_status = 'failed';
+
+COMMENT ON COLUMN meeting_chunks.attempt_count IS 'Number of embedding attempts made for this chunk';
+COMMENT ON COLUMN meeting_chunks.last_error IS 'Error message from the most recent failed embedding attempt';
+COMMENT ON COLUMN meeting_chunks.next_retry_at IS 'Earliest time the retry worker may re-attempt a failed chunk';
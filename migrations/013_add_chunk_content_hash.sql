@@ -0,0 +1,5 @@
+-- Migration 013: Content hashes for idempotent RAG re-processing
+
+ALTER TABLE meeting_chunks ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+
+COMMENT ON COLUMN meeting_chunks.content_hash IS 'SHA-256 of chunk text and offsets; unchanged chunks are skipped on re-processing';
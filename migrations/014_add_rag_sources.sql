@@ -0,0 +1,31 @@
+-- Migration 014: Generalize RAG storage beyond meetings
+-- Chunks and chat sessions are keyed by a source ID: the bare meeting ID for
+-- meetings, or "<type>:<session_id>" for uploaded videos and recordings.
+
+ALTER TABLE meeting_chunks DROP CONSTRAINT IF EXISTS meeting_chunks_meeting_id_fkey;
+ALTER TABLE meeting_chunks ALTER COLUMN meeting_id TYPE VARCHAR(150);
+ALTER TABLE meeting_chunks ADD COLUMN IF NOT EXISTS source_type VARCHAR(20) NOT NULL DEFAULT 'meeting';
+
+ALTER TABLE meeting_chat_sessions DROP CONSTRAINT IF EXISTS meeting_chat_sessions_meeting_id_fkey;
+ALTER TABLE meeting_chat_sessions ALTER COLUMN meeting_id TYPE VARCHAR(150);
+ALTER TABLE meeting_chat_sessions ADD COLUMN IF NOT EXISTS source_type VARCHAR(20) NOT NULL DEFAULT 'meeting';
+
+-- Summaries and chapters for non-meeting sources (meetings keep meeting_minutes)
+CREATE TABLE IF NOT EXISTS rag_source_summaries (
+    id SERIAL PRIMARY KEY,
+    source_id VARCHAR(150) NOT NULL,
+    source_type VARCHAR(20) NOT NULL,
+    language VARCHAR(10) NOT NULL DEFAULT 'en',
+    summary TEXT,
+    chapters JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+
+    UNIQUE(source_id, language)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chunks_source_type ON meeting_chunks(source_type);
+
+COMMENT ON COLUMN meeting_chunks.meeting_id IS 'Source ID: meeting ID, or video:/recording: prefixed session ID';
+COMMENT ON COLUMN meeting_chunks.source_type IS 'Source type: meeting, video, recording';
+COMMENT ON TABLE rag_source_summaries IS 'Generated summaries and chapters for uploaded videos and recordings';
@@ -0,0 +1,20 @@
+-- Migration 015: Reference documents for the RAG knowledge base
+-- Documents are pinned to a meeting, or shared globally when meeting_id is NULL.
+-- Their chunks live in meeting_chunks under the source ID "document:<id>".
+
+CREATE TABLE IF NOT EXISTS knowledge_documents (
+    id SERIAL PRIMARY KEY,
+    meeting_id VARCHAR(50) REFERENCES meetings(id) ON DELETE CASCADE,
+    uploaded_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    title VARCHAR(255) NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(100),
+    language VARCHAR(10) NOT NULL DEFAULT 'en',
+    content_text TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_documents_meeting ON knowledge_documents(meeting_id);
+
+COMMENT ON TABLE knowledge_documents IS 'Reference documents (PDF, DOCX, Markdown) blended into RAG answers';
+COMMENT ON COLUMN knowledge_documents.meeting_id IS 'Meeting the document is pinned to; NULL for the global knowledge base';
@@ -0,0 +1,6 @@
+-- Migration 016: Read-only sharing of RAG chat sessions with meeting members
+
+ALTER TABLE meeting_chat_sessions ADD COLUMN IF NOT EXISTS is_shared BOOLEAN DEFAULT false;
+ALTER TABLE meeting_chat_sessions ADD COLUMN IF NOT EXISTS shared_at TIMESTAMP;
+
+COMMENT ON COLUMN meeting_chat_sessions.is_shared IS 'When true, members with viewer access to the meeting can read and export the session';
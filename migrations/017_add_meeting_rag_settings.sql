@@ -0,0 +1,7 @@
+-- Migration 017: Per-meeting retrieval defaults for RAG chat
+
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS rag_top_k INTEGER;
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS rag_min_similarity FLOAT;
+
+COMMENT ON COLUMN meetings.rag_top_k IS 'Default number of chunks retrieved per RAG question (NULL = server default)';
+COMMENT ON COLUMN meetings.rag_min_similarity IS 'Minimum cosine similarity for retrieved chunks (NULL = server default)';
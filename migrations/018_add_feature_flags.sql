@@ -0,0 +1,24 @@
+-- Migration 018: Feature flags with per-organization and per-user overrides
+
+CREATE TABLE IF NOT EXISTS feature_flags (
+    key VARCHAR(100) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    description TEXT,
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS feature_flag_overrides (
+    id SERIAL PRIMARY KEY,
+    flag_key VARCHAR(100) NOT NULL,
+    scope_type VARCHAR(10) NOT NULL CHECK (scope_type IN ('org', 'user')),
+    scope_id VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMP DEFAULT NOW(),
+
+    UNIQUE(flag_key, scope_type, scope_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_flag_overrides_key ON feature_flag_overrides(flag_key);
+
+COMMENT ON TABLE feature_flags IS 'Global on/off state for feature flags (overrides FEATURE_* env defaults)';
+COMMENT ON TABLE feature_flag_overrides IS 'Per-org (email domain) and per-user flag overrides; user beats org beats global';
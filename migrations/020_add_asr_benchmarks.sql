@@ -0,0 +1,35 @@
+-- Migration 020: ASR accuracy benchmark history
+
+CREATE TABLE IF NOT EXISTS asr_benchmark_runs (
+    id SERIAL PRIMARY KEY,
+    backend VARCHAR(100) NOT NULL,
+    asr_url TEXT NOT NULL,
+    window_seconds INTEGER NOT NULL DEFAULT 0,
+    label VARCHAR(255),
+    corpus VARCHAR(500) NOT NULL,
+    sample_count INTEGER NOT NULL,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS asr_benchmark_results (
+    id SERIAL PRIMARY KEY,
+    run_id INTEGER NOT NULL REFERENCES asr_benchmark_runs(id) ON DELETE CASCADE,
+    language VARCHAR(10) NOT NULL,
+    sample_count INTEGER NOT NULL,
+    word_errors INTEGER NOT NULL,
+    reference_words INTEGER NOT NULL,
+    wer DOUBLE PRECISION NOT NULL,
+    char_errors INTEGER NOT NULL,
+    reference_chars INTEGER NOT NULL,
+    cer DOUBLE PRECISION NOT NULL,
+    avg_latency_ms DOUBLE PRECISION NOT NULL,
+
+    UNIQUE(run_id, language)
+);
+
+CREATE INDEX IF NOT EXISTS idx_asr_benchmark_runs_created ON asr_benchmark_runs(created_at DESC);
+
+COMMENT ON TABLE asr_benchmark_runs IS 'One cmd/benchmark run of a labeled corpus against an ASR backend';
+COMMENT ON COLUMN asr_benchmark_runs.window_seconds IS '0 = whole file per request, otherwise audio split into windows of this length';
+COMMENT ON TABLE asr_benchmark_results IS 'Pooled WER/CER per language for a benchmark run';
@@ -0,0 +1,23 @@
+-- Migration 021: Per-session metrics for live pipeline A/B experiments
+
+CREATE TABLE IF NOT EXISTS live_experiment_sessions (
+    id SERIAL PRIMARY KEY,
+    experiment VARCHAR(100) NOT NULL,
+    variant VARCHAR(100) NOT NULL,
+    params JSONB NOT NULL,
+    duration_ms BIGINT NOT NULL,
+    asr_calls INTEGER NOT NULL DEFAULT 0,
+    asr_errors INTEGER NOT NULL DEFAULT 0,
+    asr_latency_ms BIGINT NOT NULL DEFAULT 0,       -- summed over calls
+    silent_windows INTEGER NOT NULL DEFAULT 0,      -- windows skipped by the VAD threshold
+    partial_revisions INTEGER NOT NULL DEFAULT 0,   -- partial text changes
+    finals INTEGER NOT NULL DEFAULT 0,
+    finalize_latency_ms BIGINT NOT NULL DEFAULT 0,  -- summed first-partial-to-final time
+    first_partial_ms BIGINT,                        -- audio start to first partial
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_live_experiment_sessions_variant ON live_experiment_sessions(experiment, variant);
+
+COMMENT ON TABLE live_experiment_sessions IS 'Latency and stability proxies recorded for each live streaming session assigned to an experiment variant';
@@ -0,0 +1,19 @@
+-- Migration 022: Dual-channel interpreter mode (original floor audio + human interpretation)
+
+CREATE TABLE IF NOT EXISTS interpretation_segments (
+    id SERIAL PRIMARY KEY,
+    meeting_id VARCHAR(50) NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('original', 'interpreter')),
+    participant_id INTEGER,
+    speaker_name VARCHAR(255),
+    language VARCHAR(10),
+    text TEXT NOT NULL,
+    translations JSONB,              -- machine translations of original segments
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_interpretation_segments_meeting ON interpretation_segments(meeting_id, channel, started_at);
+
+COMMENT ON TABLE interpretation_segments IS 'Timed transcript segments of both channels in interpreted meetings, aligned on read';
@@ -0,0 +1,23 @@
+-- Migration 023: Persistent job queue for upload processing
+
+CREATE TABLE IF NOT EXISTS processing_jobs (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,                 -- handler name, e.g. 'video_upload'
+    session_id VARCHAR(255),                   -- progress session the job reports to
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    run_at TIMESTAMP NOT NULL DEFAULT NOW(),   -- earliest time the job may (re)start
+    locked_at TIMESTAMP,                       -- when a worker claimed it
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_processing_jobs_ready ON processing_jobs(run_at) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS idx_processing_jobs_session ON processing_jobs(session_id);
+
+COMMENT ON TABLE processing_jobs IS 'Durable queue for background processing; running jobs orphaned by a restart are requeued on startup';
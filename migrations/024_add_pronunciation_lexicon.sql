@@ -0,0 +1,19 @@
+-- Migration 024: Per-language pronunciation lexicon applied before TTS
+
+CREATE TABLE IF NOT EXISTS pronunciation_lexicon (
+    id SERIAL PRIMARY KEY,
+    org_id VARCHAR(255) NOT NULL DEFAULT '',     -- email domain; '' = applies to every org
+    language VARCHAR(10) NOT NULL,               -- TTS language the entry applies to
+    pattern TEXT NOT NULL,                       -- text to find
+    replacement TEXT NOT NULL,                   -- spoken form (phonetic respelling)
+    match_type VARCHAR(10) NOT NULL DEFAULT 'word' CHECK (match_type IN ('word', 'exact', 'regex')),
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+
+    UNIQUE(org_id, language, pattern)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pronunciation_lexicon_org ON pronunciation_lexicon(org_id, language);
+
+COMMENT ON TABLE pronunciation_lexicon IS 'Replacement rules applied to text before synthesis; org entries override global entries with the same pattern';
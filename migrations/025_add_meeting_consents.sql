@@ -0,0 +1,16 @@
+-- Migration 025: Per-participant recording/transcription consent for meetings
+
+CREATE TABLE IF NOT EXISTS meeting_consents (
+    meeting_id VARCHAR(50) NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    participant_id INTEGER NOT NULL REFERENCES meeting_participants(id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    participant_name VARCHAR(255) NOT NULL,
+    consented BOOLEAN NOT NULL,
+    responded_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (meeting_id, participant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_meeting_consents_user ON meeting_consents(meeting_id, user_id);
+
+COMMENT ON TABLE meeting_consents IS 'Participant answers to the recording/transcription consent prompt shown on join';
+COMMENT ON COLUMN meeting_consents.consented IS 'false keeps the participant''s speech live-only: it is never stored in transcripts, snapshots or RAG';
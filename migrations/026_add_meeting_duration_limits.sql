@@ -0,0 +1,12 @@
+-- Migration 026: Maximum meeting duration per meeting and per organization
+
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS max_duration_minutes INTEGER CHECK (max_duration_minutes > 0);
+
+CREATE TABLE IF NOT EXISTS org_meeting_settings (
+    org_id VARCHAR(255) PRIMARY KEY,
+    max_duration_minutes INTEGER NOT NULL CHECK (max_duration_minutes > 0),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+COMMENT ON COLUMN meetings.max_duration_minutes IS 'Meeting is ended automatically this long after creation; NULL means no limit';
+COMMENT ON TABLE org_meeting_settings IS 'Per-organization (email domain) meeting limits, overriding MEETING_MAX_DURATION_MINUTES';
@@ -0,0 +1,16 @@
+-- Migration 027: Link uploaded recordings to the live meeting they duplicate
+-- A linked source is served from the meeting's knowledge entries instead of
+-- being indexed a second time.
+
+CREATE TABLE IF NOT EXISTS rag_source_links (
+    source_id VARCHAR(150) PRIMARY KEY,
+    meeting_id VARCHAR(50) NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    similarity FLOAT,
+    linked_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rag_source_links_meeting ON rag_source_links(meeting_id);
+
+COMMENT ON TABLE rag_source_links IS 'Uploaded videos/recordings merged into the meeting they were detected to duplicate';
+COMMENT ON COLUMN rag_source_links.similarity IS 'Mean transcript embedding similarity when the link was made';
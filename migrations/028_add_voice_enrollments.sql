@@ -0,0 +1,13 @@
+-- Migration 028: Enrolled voice profiles for automatic speaker naming
+-- One voice embedding per account; shared-room diarization matches speakers
+-- against the enrolled voices of a meeting's participants.
+
+CREATE TABLE IF NOT EXISTS voice_enrollments (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    embedding JSONB NOT NULL,
+    sample_seconds FLOAT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+COMMENT ON TABLE voice_enrollments IS 'Speaker embedding of a short voice sample recorded by each enrolled user';
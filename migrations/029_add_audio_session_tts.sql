@@ -0,0 +1,5 @@
+-- Migration 029: Translated speech for audio uploads
+
+ALTER TABLE user_audio_sessions ADD COLUMN IF NOT EXISTS tts_path TEXT;
+
+COMMENT ON COLUMN user_audio_sessions.tts_path IS 'Object key of the synthesized translation audio, when requested';
@@ -0,0 +1,31 @@
+-- Migration 030: Multi-file batch uploads
+-- A batch groups the per-file upload jobs submitted in one request; each item
+-- keeps its file's results so the batch manifest and output zip survive a
+-- restart.
+
+CREATE TABLE IF NOT EXISTS upload_batches (
+    batch_id VARCHAR(255) PRIMARY KEY,         -- also the progress session for aggregate updates
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    source_lang VARCHAR(10),
+    target_lang VARCHAR(10),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS upload_batch_items (
+    batch_id VARCHAR(255) NOT NULL REFERENCES upload_batches(batch_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,                 -- order of the file in the request
+    filename TEXT NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('video', 'audio')),
+    session_id VARCHAR(255) NOT NULL,          -- the file's own progress session
+    job_id INTEGER REFERENCES processing_jobs(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'succeeded', 'failed')),
+    results JSONB,
+    error TEXT,
+    completed_at TIMESTAMP,
+    PRIMARY KEY (batch_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_batches_user ON upload_batches(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_upload_batch_items_session ON upload_batch_items(session_id);
+
+COMMENT ON TABLE upload_batches IS 'Uploads of several files submitted together through POST /upload/batch';
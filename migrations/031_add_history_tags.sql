@@ -0,0 +1,40 @@
+-- Migration 031: User-defined tags and folders for organizing history
+-- Both are labels owned by one user; an item can carry any number of tags
+-- but sits in at most one folder (enforced when assigning).
+
+CREATE TABLE IF NOT EXISTS user_tags (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(10) NOT NULL DEFAULT 'tag' CHECK (kind IN ('tag', 'folder')),
+    color VARCHAR(20),                         -- optional display color, e.g. '#3b82f6'
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_tags_name ON user_tags(user_id, kind, LOWER(name));
+
+CREATE TABLE IF NOT EXISTS session_tags (
+    tag_id INTEGER NOT NULL REFERENCES user_tags(id) ON DELETE CASCADE,
+    session_type VARCHAR(20) NOT NULL CHECK (session_type IN ('video', 'audio', 'streaming')),
+    session_id VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY (tag_id, session_type, session_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_tags_session ON session_tags(session_type, session_id);
+
+CREATE TABLE IF NOT EXISTS meeting_tags (
+    tag_id INTEGER NOT NULL REFERENCES user_tags(id) ON DELETE CASCADE,
+    meeting_id VARCHAR(50) NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY (tag_id, meeting_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_meeting_tags_meeting ON meeting_tags(meeting_id);
+
+COMMENT ON TABLE user_tags IS 'Tags and folders a user files their sessions and meetings under';
+COMMENT ON TABLE session_tags IS 'Video, audio and streaming history sessions by tag or folder';
+COMMENT ON TABLE meeting_tags IS 'Meetings by tag or folder; labels are private to the tag owner';
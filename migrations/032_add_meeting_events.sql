@@ -0,0 +1,20 @@
+-- Migration 032: Append-only log of meeting broadcasts
+-- Every message sent to a meeting's participants (joins, final captions,
+-- language changes, notices, errors) gets a per-meeting sequence number, so
+-- reconnecting clients can catch up and support can replay what was shown.
+-- Captions of speakers without recording consent are stored without text.
+
+CREATE TABLE IF NOT EXISTS meeting_events (
+    id BIGSERIAL PRIMARY KEY,
+    meeting_id VARCHAR(50) NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL,                       -- 1, 2, 3... within the meeting
+    type VARCHAR(50) NOT NULL,
+    participant_id INTEGER,                    -- subject or speaker, when there is one
+    payload JSONB NOT NULL,                    -- the message as broadcast
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_meeting_events_seq ON meeting_events(meeting_id, seq);
+CREATE INDEX IF NOT EXISTS idx_meeting_events_type ON meeting_events(meeting_id, type);
+
+COMMENT ON TABLE meeting_events IS 'Append-only, sequenced log of messages broadcast to meeting participants';
@@ -0,0 +1,16 @@
+-- Migration 033: Per-organization TTS policies
+-- Restricts the voices and voice cloning an org's jobs may use and the
+-- profanity filter applied to text before it is synthesized. The row with
+-- an empty org_id is the server-wide default for orgs without their own.
+
+CREATE TABLE IF NOT EXISTS org_tts_policies (
+    org_id VARCHAR(255) PRIMARY KEY,           -- email domain; '' for the default
+    allowed_voices TEXT[],                     -- NULL or empty allows every voice
+    default_voice VARCHAR(100),
+    allow_voice_cloning BOOLEAN NOT NULL DEFAULT TRUE,
+    profanity_filter VARCHAR(20) NOT NULL DEFAULT 'off'
+        CHECK (profanity_filter IN ('off', 'standard', 'kid_safe')),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+COMMENT ON TABLE org_tts_policies IS 'Voice, cloning and profanity restrictions on synthesized speech per org';
@@ -0,0 +1,26 @@
+-- Migration 034: Archived audio of processed chunks
+-- Each recording or meeting chunk that was sent to ASR can be kept in object
+-- storage so a disputed caption can be checked against what the system
+-- actually heard. Captions reference their chunk by id (audioRef). Rows and
+-- objects are deleted once expires_at passes.
+
+CREATE TABLE IF NOT EXISTS audio_chunks (
+    id BIGSERIAL PRIMARY KEY,
+    session_type VARCHAR(30) NOT NULL,         -- 'recording' or 'meeting'
+    session_id VARCHAR(100) NOT NULL,          -- recording session ID or meeting ID
+    chunk_index INTEGER NOT NULL DEFAULT 0,    -- recording chunk index; 0 for meetings
+    participant_id INTEGER,                    -- meeting speaker, when there is one
+    bucket_name VARCHAR(255) NOT NULL,
+    file_key TEXT NOT NULL,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('wav', 'flac')),
+    duration_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+    file_size_bytes BIGINT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (bucket_name, file_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audio_chunks_session ON audio_chunks(session_type, session_id);
+CREATE INDEX IF NOT EXISTS idx_audio_chunks_expires ON audio_chunks(expires_at);
+
+COMMENT ON TABLE audio_chunks IS 'Archived audio of chunks sent to ASR, for auditing captions; kept until expires_at';
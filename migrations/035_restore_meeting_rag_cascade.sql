@@ -0,0 +1,33 @@
+-- Migration 035: Delete a meeting's RAG data with the meeting again
+-- Migration 014 dropped the meeting foreign keys of meeting_chunks and
+-- meeting_chat_sessions so they could hold other sources, and with them the
+-- ON DELETE CASCADE. A generated column mirrors meeting_id for meeting rows
+-- only (NULL for uploads and recordings) and carries the cascading key.
+-- Chat messages follow their session through its existing cascade.
+
+-- Rows of meetings deleted since 014 would block the new constraints
+DELETE FROM meeting_chunks c
+WHERE c.source_type = 'meeting'
+  AND NOT EXISTS (SELECT 1 FROM meetings m WHERE m.id = c.meeting_id);
+
+DELETE FROM meeting_chat_sessions s
+WHERE s.source_type = 'meeting'
+  AND NOT EXISTS (SELECT 1 FROM meetings m WHERE m.id = s.meeting_id);
+
+ALTER TABLE meeting_chunks ADD COLUMN IF NOT EXISTS meeting_ref VARCHAR(150)
+    GENERATED ALWAYS AS (CASE WHEN source_type = 'meeting' THEN meeting_id END) STORED;
+ALTER TABLE meeting_chunks DROP CONSTRAINT IF EXISTS meeting_chunks_meeting_ref_fkey;
+ALTER TABLE meeting_chunks ADD CONSTRAINT meeting_chunks_meeting_ref_fkey
+    FOREIGN KEY (meeting_ref) REFERENCES meetings(id) ON DELETE CASCADE;
+
+ALTER TABLE meeting_chat_sessions ADD COLUMN IF NOT EXISTS meeting_ref VARCHAR(150)
+    GENERATED ALWAYS AS (CASE WHEN source_type = 'meeting' THEN meeting_id END) STORED;
+ALTER TABLE meeting_chat_sessions DROP CONSTRAINT IF EXISTS meeting_chat_sessions_meeting_ref_fkey;
+ALTER TABLE meeting_chat_sessions ADD CONSTRAINT meeting_chat_sessions_meeting_ref_fkey
+    FOREIGN KEY (meeting_ref) REFERENCES meetings(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_chunks_meeting_ref ON meeting_chunks(meeting_ref);
+CREATE INDEX IF NOT EXISTS idx_chat_sessions_meeting_ref ON meeting_chat_sessions(meeting_ref);
+
+COMMENT ON COLUMN meeting_chunks.meeting_ref IS 'meeting_id of meeting chunks, NULL for other sources; cascades meeting deletes';
+COMMENT ON COLUMN meeting_chat_sessions.meeting_ref IS 'meeting_id of meeting chat sessions, NULL for other sources; cascades meeting deletes';
@@ -0,0 +1,7 @@
+-- Migration 036: Store the outcome of finished jobs
+-- Jobs such as a meeting's knowledge reprocessing report a summary that
+-- stays readable from GET /api/jobs/{id} after the progress stream is gone.
+
+ALTER TABLE processing_jobs ADD COLUMN IF NOT EXISTS result JSONB;
+
+COMMENT ON COLUMN processing_jobs.result IS 'Summary reported by the job handler on success; NULL for kinds that report none';
@@ -0,0 +1,24 @@
+-- Migration 037: Meeting recordings
+-- A meeting owner can record a meeting: the mixed audio of consenting
+-- participants (WAV) and the final captions with offsets into that audio
+-- (JSON) are stored in object storage for replay after the meeting.
+
+CREATE TABLE IF NOT EXISTS meeting_recordings (
+    id SERIAL PRIMARY KEY,
+    meeting_id VARCHAR(50) NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    started_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'recording' CHECK (status IN ('recording', 'processing', 'ready', 'failed')),
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ended_at TIMESTAMP,
+    duration_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+    entry_count INTEGER NOT NULL DEFAULT 0,    -- captions in the transcript
+    bucket_name VARCHAR(255),
+    audio_key TEXT,                            -- mixed audio (WAV)
+    transcript_key TEXT,                       -- captions with offsets (JSON)
+    error TEXT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_meeting_recordings_meeting ON meeting_recordings(meeting_id, started_at);
+
+COMMENT ON TABLE meeting_recordings IS 'Recorded meeting audio and captions stored in object storage for replay';
@@ -0,0 +1,128 @@
+-- Migration 038: Normalize language codes
+-- Pipelines stored languages as "en", "en-US", "EN_us" or "english", so
+-- language filters and joins (snapshots, minutes, chunks) missed rows. New
+-- writes go through internal/langcode; this rewrites existing rows to the
+-- same canonical form: the lowercase ISO 639-1 code without region. The
+-- name mapping below mirrors langcode.names. Pronunciation lexicon entries
+-- keep their region ("pt-br") and are only lowercased.
+
+CREATE OR REPLACE FUNCTION normalize_language_code(value TEXT) RETURNS TEXT AS $$
+DECLARE
+    code TEXT;
+BEGIN
+    IF value IS NULL THEN
+        RETURN NULL;
+    END IF;
+    code := lower(btrim(replace(value, '_', '-')));
+    IF code = '' OR code = 'auto' THEN
+        RETURN code;
+    END IF;
+    code := split_part(code, '-', 1);
+    RETURN CASE
+        WHEN code IN ('english', 'eng') THEN 'en'
+        WHEN code IN ('spanish', 'español', 'espanol', 'castilian', 'spa') THEN 'es'
+        WHEN code IN ('french', 'français', 'francais', 'fra', 'fre') THEN 'fr'
+        WHEN code IN ('german', 'deutsch', 'deu', 'ger') THEN 'de'
+        WHEN code IN ('chinese', 'mandarin', 'zho', 'chi', 'cmn') THEN 'zh'
+        WHEN code IN ('japanese', 'jpn') THEN 'ja'
+        WHEN code IN ('korean', 'kor') THEN 'ko'
+        WHEN code IN ('arabic', 'ara') THEN 'ar'
+        WHEN code IN ('hindi', 'hin') THEN 'hi'
+        WHEN code IN ('urdu', 'urd') THEN 'ur'
+        WHEN code IN ('bengali', 'bangla', 'ben') THEN 'bn'
+        WHEN code IN ('tamil', 'tam') THEN 'ta'
+        WHEN code IN ('telugu', 'tel') THEN 'te'
+        WHEN code IN ('malayalam', 'mal') THEN 'ml'
+        WHEN code IN ('portuguese', 'português', 'portugues', 'por') THEN 'pt'
+        WHEN code IN ('italian', 'italiano', 'ita') THEN 'it'
+        WHEN code IN ('russian', 'rus') THEN 'ru'
+        WHEN code IN ('dutch', 'flemish', 'nld', 'dut') THEN 'nl'
+        WHEN code IN ('turkish', 'tur') THEN 'tr'
+        WHEN code IN ('polish', 'pol') THEN 'pl'
+        WHEN code IN ('vietnamese', 'vie') THEN 'vi'
+        WHEN code IN ('indonesian', 'ind') THEN 'id'
+        WHEN code IN ('thai', 'tha') THEN 'th'
+        WHEN code IN ('persian', 'farsi', 'fas', 'per') THEN 'fa'
+        WHEN code IN ('hebrew', 'heb', 'iw') THEN 'he'
+        WHEN code IN ('ukrainian', 'ukr') THEN 'uk'
+        WHEN code IN ('swedish', 'swe') THEN 'sv'
+        WHEN code IN ('greek', 'ell', 'gre') THEN 'el'
+        ELSE code
+    END;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Tables unique per language: where several codes of one language exist,
+-- keep the rows stored under the canonical code, otherwise those of the
+-- alphabetically first variant
+DELETE FROM meeting_transcript_snapshots t
+WHERE t.language <> normalize_language_code(t.language)
+  AND EXISTS (
+    SELECT 1 FROM meeting_transcript_snapshots o
+    WHERE o.meeting_id = t.meeting_id
+      AND normalize_language_code(o.language) = normalize_language_code(t.language)
+      AND (o.language = normalize_language_code(o.language) OR o.language < t.language)
+  );
+
+DELETE FROM meeting_minutes t
+WHERE t.language <> normalize_language_code(t.language)
+  AND EXISTS (
+    SELECT 1 FROM meeting_minutes o
+    WHERE o.meeting_id = t.meeting_id
+      AND normalize_language_code(o.language) = normalize_language_code(t.language)
+      AND (o.language = normalize_language_code(o.language) OR o.language < t.language)
+  );
+
+DELETE FROM meeting_chunks t
+WHERE t.language <> normalize_language_code(t.language)
+  AND EXISTS (
+    SELECT 1 FROM meeting_chunks o
+    WHERE o.meeting_id = t.meeting_id
+      AND normalize_language_code(o.language) = normalize_language_code(t.language)
+      AND (o.language = normalize_language_code(o.language) OR o.language < t.language)
+  );
+
+DELETE FROM rag_source_summaries t
+WHERE t.language <> normalize_language_code(t.language)
+  AND EXISTS (
+    SELECT 1 FROM rag_source_summaries o
+    WHERE o.source_id = t.source_id
+      AND normalize_language_code(o.language) = normalize_language_code(t.language)
+      AND (o.language = normalize_language_code(o.language) OR o.language < t.language)
+  );
+
+UPDATE meeting_transcript_snapshots SET language = normalize_language_code(language) WHERE language <> normalize_language_code(language);
+UPDATE meeting_minutes SET language = normalize_language_code(language) WHERE language <> normalize_language_code(language);
+UPDATE meeting_chunks SET language = normalize_language_code(language) WHERE language <> normalize_language_code(language);
+UPDATE rag_source_summaries SET language = normalize_language_code(language) WHERE language <> normalize_language_code(language);
+UPDATE meeting_chat_sessions SET language = normalize_language_code(language) WHERE language <> normalize_language_code(language);
+UPDATE knowledge_documents SET language = normalize_language_code(language) WHERE language <> normalize_language_code(language);
+UPDATE interpretation_segments SET language = normalize_language_code(language) WHERE language <> normalize_language_code(language);
+
+UPDATE users SET preferred_language = normalize_language_code(preferred_language) WHERE preferred_language <> normalize_language_code(preferred_language);
+UPDATE meeting_participants SET target_language = normalize_language_code(target_language) WHERE target_language <> normalize_language_code(target_language);
+UPDATE meeting_transcripts SET source_language = normalize_language_code(source_language) WHERE source_language <> normalize_language_code(source_language);
+
+UPDATE user_video_sessions SET source_lang = normalize_language_code(source_lang) WHERE source_lang <> normalize_language_code(source_lang);
+UPDATE user_video_sessions SET target_lang = normalize_language_code(target_lang) WHERE target_lang <> normalize_language_code(target_lang);
+UPDATE user_audio_sessions SET source_lang = normalize_language_code(source_lang) WHERE source_lang <> normalize_language_code(source_lang);
+UPDATE user_audio_sessions SET target_lang = normalize_language_code(target_lang) WHERE target_lang <> normalize_language_code(target_lang);
+UPDATE user_streaming_sessions SET source_lang = normalize_language_code(source_lang) WHERE source_lang <> normalize_language_code(source_lang);
+UPDATE user_streaming_sessions SET target_lang = normalize_language_code(target_lang) WHERE target_lang <> normalize_language_code(target_lang);
+UPDATE upload_batches SET source_lang = normalize_language_code(source_lang) WHERE source_lang <> normalize_language_code(source_lang);
+UPDATE upload_batches SET target_lang = normalize_language_code(target_lang) WHERE target_lang <> normalize_language_code(target_lang);
+
+-- Lexicon entries that differ only in case or separator would collide;
+-- keep the oldest
+DELETE FROM pronunciation_lexicon t
+WHERE t.language <> lower(replace(btrim(t.language), '_', '-'))
+  AND EXISTS (
+    SELECT 1 FROM pronunciation_lexicon o
+    WHERE o.org_id = t.org_id AND o.pattern = t.pattern AND o.id <> t.id
+      AND lower(replace(btrim(o.language), '_', '-')) = lower(replace(btrim(t.language), '_', '-'))
+      AND (o.language = lower(replace(btrim(o.language), '_', '-')) OR o.id < t.id)
+  );
+UPDATE pronunciation_lexicon SET language = lower(replace(btrim(language), '_', '-'))
+WHERE language <> lower(replace(btrim(language), '_', '-'));
+
+DROP FUNCTION normalize_language_code(TEXT);
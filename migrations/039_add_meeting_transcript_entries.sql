@@ -0,0 +1,25 @@
+-- Migration 039: Live meeting transcript entries
+-- Every stored final caption is written as it is broadcast, so a meeting's
+-- transcript survives a server crash and can be paged while the meeting is
+-- live. Offsets are seconds from the meeting's creation.
+
+CREATE TABLE IF NOT EXISTS meeting_transcript_entries (
+    id BIGSERIAL PRIMARY KEY,
+    meeting_id VARCHAR(50) NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    event_seq BIGINT,                            -- meeting_events.seq of the caption
+    participant_id INTEGER REFERENCES meeting_participants(id) ON DELETE SET NULL,
+    speaker_id VARCHAR(100),                     -- diarized speaker, e.g. "P3_SPEAKER_01"
+    speaker_name VARCHAR(255),
+    language VARCHAR(10),                        -- source language
+    text TEXT NOT NULL,
+    translations JSONB,                          -- target language -> text
+    spoken_at TIMESTAMP NOT NULL,
+    start_offset_seconds DOUBLE PRECISION NOT NULL,
+    end_offset_seconds DOUBLE PRECISION NOT NULL,
+    audio_ref BIGINT,                            -- audio_chunks.id when archived
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transcript_entries_meeting ON meeting_transcript_entries(meeting_id, spoken_at, id);
+
+COMMENT ON TABLE meeting_transcript_entries IS 'Final meeting captions persisted as they are broadcast';
@@ -0,0 +1,28 @@
+-- Migration 040: Failure inbox for background processing
+-- Terminal failures of queued jobs (uploads, webhooks, reprocessing), chunk
+-- embeddings and meeting minutes are collected in one table so operators
+-- can triage them by priority and requeue them from /api/admin/failures.
+
+CREATE TABLE IF NOT EXISTS processing_failures (
+    id SERIAL PRIMARY KEY,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('job', 'chunk', 'minutes')),
+    kind VARCHAR(50) NOT NULL,                   -- job kind, or "embedding" / "meeting_minutes"
+    priority INTEGER NOT NULL DEFAULT 2,         -- 1 is triaged first
+    error_code VARCHAR(30) NOT NULL,
+    error TEXT NOT NULL,
+    context JSONB NOT NULL DEFAULT '{}',         -- parameters needed to retry, e.g. the job payload
+    job_id INTEGER REFERENCES processing_jobs(id) ON DELETE SET NULL,
+    chunk_id INTEGER REFERENCES meeting_chunks(id) ON DELETE SET NULL,
+    meeting_id VARCHAR(50) REFERENCES meetings(id) ON DELETE CASCADE,
+    session_id VARCHAR(100),
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'requeued', 'dismissed')),
+    requeued_job_id INTEGER REFERENCES processing_jobs(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_processing_failures_open ON processing_failures(status, priority, created_at DESC);
+
+COMMENT ON TABLE processing_failures IS 'Terminal failures of background processing awaiting triage or requeue';
@@ -0,0 +1,26 @@
+-- Migration 041: Action items as first-class entities
+-- Minutes generation writes each action item it extracts to its own row so
+-- items can be listed, completed and edited per meeting. Items a user has
+-- edited or completed survive regeneration of the minutes.
+
+CREATE TABLE IF NOT EXISTS meeting_action_items (
+    id SERIAL PRIMARY KEY,
+    meeting_id VARCHAR(50) NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    language VARCHAR(10) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,         -- order within the minutes
+    text TEXT NOT NULL,
+    assignee VARCHAR(255),
+    due_date DATE,                               -- only when the meeting mentioned one
+    source_chunk_ids INTEGER[] NOT NULL DEFAULT '{}', -- meeting_chunks the item was found in
+    status VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'done')),
+    edited BOOLEAN NOT NULL DEFAULT FALSE,       -- changed by a user since extraction
+    completed_at TIMESTAMP,
+    completed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_action_items_meeting ON meeting_action_items(meeting_id, language, position);
+
+COMMENT ON TABLE meeting_action_items IS 'Action items extracted from meeting minutes, tracked per meeting';
+COMMENT ON COLUMN meeting_action_items.source_chunk_ids IS 'Chunk IDs at extraction time; chunks rebuilt by reprocessing get new IDs';
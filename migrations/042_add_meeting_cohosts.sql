@@ -0,0 +1,8 @@
+-- Migration 042: Meeting co-hosts
+-- A co-host has the owner's moderator powers (recording, consent,
+-- reprocessing, managing editors and viewers) without owning the meeting.
+-- Ownership itself moves by updating meetings.created_by.
+
+ALTER TABLE meeting_access_control DROP CONSTRAINT IF EXISTS meeting_access_control_role_check;
+ALTER TABLE meeting_access_control ADD CONSTRAINT meeting_access_control_role_check
+    CHECK (role IN ('owner', 'cohost', 'editor', 'viewer'));
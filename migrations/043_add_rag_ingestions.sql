@@ -0,0 +1,16 @@
+-- Migration 043: RAG ingestion status
+-- Tracks the chunking and embedding of each meeting transcript language,
+-- started automatically when a meeting ends or by hand via re-ingest.
+
+CREATE TABLE IF NOT EXISTS meeting_rag_ingestions (
+    meeting_id VARCHAR(50) NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    language VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    error TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (meeting_id, language)
+);
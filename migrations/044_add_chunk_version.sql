@@ -0,0 +1,7 @@
+-- Migration 044: Chunk versions
+-- Records the chunker version (chunking rules, parameters and embedding
+-- model) a chunk was built with, so re-processing re-embeds chunks built by
+-- an older version even when their text is unchanged. Existing chunks get ''
+-- and count as outdated.
+
+ALTER TABLE meeting_chunks ADD COLUMN IF NOT EXISTS chunk_version VARCHAR(100) NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- Migration 045: All speakers of a chunk
+-- speaker_name is only set for single-speaker chunks; speaker_names lists
+-- every speaker of a chunk in order of first appearance.
+
+ALTER TABLE meeting_chunks ADD COLUMN IF NOT EXISTS speaker_names TEXT[] NOT NULL DEFAULT '{}';
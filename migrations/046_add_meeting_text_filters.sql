@@ -0,0 +1,15 @@
+-- Migration 046: Per-meeting caption redaction
+-- Profanity and sensitive terms are masked or removed from transcriptions
+-- and translations before they are broadcast, stored or embedded.
+
+CREATE TABLE IF NOT EXISTS meeting_text_filters (
+    meeting_id VARCHAR(50) PRIMARY KEY REFERENCES meetings(id) ON DELETE CASCADE,
+    mode VARCHAR(10) NOT NULL DEFAULT 'mask'
+        CHECK (mode IN ('mask', 'remove')),
+    profanity VARCHAR(20) NOT NULL DEFAULT 'off',
+    redact_emails BOOLEAN NOT NULL DEFAULT FALSE,
+    redact_card_numbers BOOLEAN NOT NULL DEFAULT FALSE,
+    terms TEXT[] NOT NULL DEFAULT '{}',
+    updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
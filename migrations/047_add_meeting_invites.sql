@@ -0,0 +1,26 @@
+-- Migration 047: Meeting invitations
+-- Owners and co-hosts hand out join links carrying an invite token scoped to
+-- one meeting, a role and an expiry. Only a hash of each token is stored.
+-- An invite-only meeting can be joined with a valid invite or by users who
+-- already have access.
+
+CREATE TABLE IF NOT EXISTS meeting_invites (
+    id SERIAL PRIMARY KEY,
+    meeting_id VARCHAR(50) NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    max_uses INTEGER CHECK (max_uses IS NULL OR max_uses > 0),
+    uses INTEGER NOT NULL DEFAULT 0,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_meeting_invites_meeting ON meeting_invites(meeting_id, created_at DESC);
+
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS invite_only BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The invite an ACL entry was granted through, if any
+ALTER TABLE meeting_access_control ADD COLUMN IF NOT EXISTS invite_id INTEGER
+    REFERENCES meeting_invites(id) ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+-- Migration 048: Meeting waiting rooms
+-- With the waiting room on, participants without an editor role or the host
+-- token wait until an owner, co-host or editor admits or rejects them. Their
+-- audio is not accepted while they wait.
+
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS waiting_room BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE meeting_participants ADD COLUMN IF NOT EXISTS admission VARCHAR(10) NOT NULL DEFAULT 'admitted'
+    CHECK (admission IN ('waiting', 'admitted', 'rejected'));
@@ -0,0 +1,7 @@
+-- Migration 049: Participants removed from live meetings
+-- A moderator who kicks a signed-in participant leaves a note on their ACL
+-- entry. Removed users keep their role for the meeting's history but cannot
+-- rejoin the live room until access is granted to them again.
+
+ALTER TABLE meeting_access_control ADD COLUMN IF NOT EXISTS removed_at TIMESTAMP;
+ALTER TABLE meeting_access_control ADD COLUMN IF NOT EXISTS removal_note TEXT;
@@ -0,0 +1,23 @@
+-- Migration 050: Voice prints in pgvector
+-- Enrolled voices move from JSONB to a vector column so diarized speakers
+-- can be matched in the database. display_name is the name an enrolled
+-- speaker is shown under in every meeting, on any device.
+
+ALTER TABLE voice_enrollments ADD COLUMN IF NOT EXISTS voice_print vector;
+ALTER TABLE voice_enrollments ADD COLUMN IF NOT EXISTS display_name VARCHAR(255);
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'voice_enrollments' AND column_name = 'embedding'
+    ) THEN
+        UPDATE voice_enrollments SET voice_print = (embedding::text)::vector WHERE voice_print IS NULL;
+        ALTER TABLE voice_enrollments DROP COLUMN embedding;
+    END IF;
+END $$;
+
+ALTER TABLE voice_enrollments ALTER COLUMN voice_print SET NOT NULL;
+
+COMMENT ON COLUMN voice_enrollments.voice_print IS 'Speaker embedding from the diarization model; compared by cosine distance';
+COMMENT ON COLUMN voice_enrollments.display_name IS 'Name matched speakers are shown under; empty uses the meeting participant name';
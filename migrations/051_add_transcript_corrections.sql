@@ -0,0 +1,9 @@
+-- Migration 051: Corrections of finalized meeting captions
+-- Entries keep the segment ID clients saw on the caption, so a correction can
+-- be matched to the caption on screen. A corrected entry keeps the text ASR
+-- produced in original_text and records who corrected it and when.
+
+ALTER TABLE meeting_transcript_entries ADD COLUMN IF NOT EXISTS segment_id VARCHAR(100);
+ALTER TABLE meeting_transcript_entries ADD COLUMN IF NOT EXISTS original_text TEXT;
+ALTER TABLE meeting_transcript_entries ADD COLUMN IF NOT EXISTS corrected_at TIMESTAMP;
+ALTER TABLE meeting_transcript_entries ADD COLUMN IF NOT EXISTS corrected_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
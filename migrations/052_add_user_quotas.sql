@@ -0,0 +1,26 @@
+-- Migration 052: Per-user quotas
+-- user_quotas overrides the server-wide defaults for a user; a NULL column
+-- keeps the default and 0 means unlimited. user_audio_usage counts the audio
+-- a user had processed each UTC day (uploads, recordings and meeting speech).
+
+CREATE TABLE IF NOT EXISTS user_quotas (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    audio_minutes_per_day INTEGER CHECK (audio_minutes_per_day >= 0),
+    concurrent_jobs INTEGER CHECK (concurrent_jobs >= 0),
+    storage_bytes BIGINT CHECK (storage_bytes >= 0),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS user_audio_usage (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    usage_date DATE NOT NULL,
+    audio_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (user_id, usage_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_processing_jobs_user_active ON processing_jobs(user_id) WHERE status IN ('queued', 'running');
+
+COMMENT ON TABLE user_quotas IS 'Per-user overrides of the default quotas; NULL keeps the default, 0 is unlimited';
+COMMENT ON TABLE user_audio_usage IS 'Seconds of audio processed per user and UTC day, for the daily audio quota';
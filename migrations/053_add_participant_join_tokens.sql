@@ -0,0 +1,6 @@
+-- Migration 053: Participant join tokens
+-- Joining a meeting returns a secret join token. A meeting WebSocket must
+-- present it with its participant ID, so participant IDs seen in broadcasts
+-- cannot be used to connect as someone else.
+
+ALTER TABLE meeting_participants ADD COLUMN IF NOT EXISTS join_token TEXT;
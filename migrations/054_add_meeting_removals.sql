@@ -0,0 +1,31 @@
+-- Migration 054: Removals that keep kicked participants out
+-- A kick is recorded against the signed-in user or, for guests, the guest
+-- key their browser joined with, so neither can rejoin with a new participant
+-- ID. Removed users lose the role on their ACL entry until access is granted
+-- to them again; a kick no longer creates ACL entries.
+
+ALTER TABLE meeting_participants ADD COLUMN IF NOT EXISTS guest_key TEXT;
+
+CREATE TABLE IF NOT EXISTS meeting_removals (
+    id SERIAL PRIMARY KEY,
+    meeting_id VARCHAR(50) NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    guest_key TEXT,
+    note TEXT,
+    removed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    removed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (user_id IS NOT NULL OR guest_key IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_meeting_removals_user ON meeting_removals(meeting_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_meeting_removals_guest ON meeting_removals(meeting_id, guest_key);
+
+-- Removals noted on ACL entries before this migration keep blocking
+INSERT INTO meeting_removals (meeting_id, user_id, note, removed_at)
+SELECT mac.meeting_id, mac.user_id, mac.removal_note, mac.removed_at
+FROM meeting_access_control mac
+WHERE mac.removed_at IS NOT NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM meeting_removals r
+        WHERE r.meeting_id = mac.meeting_id AND r.user_id = mac.user_id
+    );
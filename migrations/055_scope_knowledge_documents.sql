@@ -0,0 +1,18 @@
+-- Migration 055: Scope the global knowledge base by organization
+-- Global documents (no meeting) are blended only into meetings created by a
+-- member of the document's org (the verified email domain). Existing global
+-- documents have no org and stop being blended until an admin re-uploads
+-- them for an org.
+
+ALTER TABLE knowledge_documents ADD COLUMN IF NOT EXISTS org_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_documents_org ON knowledge_documents(org_id) WHERE meeting_id IS NULL;
+
+COMMENT ON COLUMN knowledge_documents.org_id IS 'Organization a global document belongs to; ignored for documents pinned to a meeting';
+
+-- Chunks embedded after their document was deleted
+DELETE FROM meeting_chunks c
+WHERE c.meeting_id LIKE 'document:%'
+    AND NOT EXISTS (
+        SELECT 1 FROM knowledge_documents d WHERE c.meeting_id = 'document:' || d.id
+    );
@@ -0,0 +1,11 @@
+-- Migration 056: Designated interpreters
+-- Besides hosts and co-hosts, only users designated here may connect as the
+-- interpreter channel of an interpreted meeting.
+
+CREATE TABLE IF NOT EXISTS meeting_interpreters (
+    meeting_id VARCHAR(50) NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    granted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    granted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (meeting_id, user_id)
+);
@@ -112,6 +112,11 @@ SPEAKER_PROFILE_CLEANUP_INTERVAL_SECONDS = int(os.getenv("SPEAKER_PROFILE_CLEANU
 SPEAKER_PROFILE_STORE_URL = os.getenv("SPEAKER_PROFILE_STORE_URL", "").rstrip("/")
 SPEAKER_PROFILE_PERSIST_INTERVAL_SECONDS = int(os.getenv("SPEAKER_PROFILE_PERSIST_INTERVAL_SECONDS", "15"))
 
+# Code-switching: segments at least this long are language-detected on their
+# own, and re-transcribed when confidently in another language than the chunk
+SEGMENT_LANGUAGE_MIN_SECONDS = float(os.getenv("SEGMENT_LANGUAGE_MIN_SECONDS", "1.0"))
+SEGMENT_LANGUAGE_MIN_PROBABILITY = float(os.getenv("SEGMENT_LANGUAGE_MIN_PROBABILITY", "0.6"))
+
 # Thread pool for CPU-bound operations
 executor = ThreadPoolExecutor(max_workers=4)
 
@@ -848,6 +853,8 @@ async def transcribe_audio(request: Request):
         language = request.headers.get("x-language", None)
         if language == "auto" or language == "":
             language = None
+        # Word probabilities let the caller merge overlapping windows
+        word_confidence = request.headers.get("x-word-confidence", "").lower() == "true"
 
         print(f"📝 Transcription request: {len(audio_data)} bytes, language={language}")
 
@@ -870,15 +877,26 @@ async def transcribe_audio(request: Request):
             verbose=False,
             temperature=0.0,
             compression_ratio_threshold=2.4,
-            condition_on_previous_text=True
+            condition_on_previous_text=True,
+            word_timestamps=word_confidence
         )
 
         text = result["text"].strip()
         detected_lang = result.get("language", "unknown")
+        segments = [
+            {"start": seg["start"], "end": seg["end"], "text": seg["text"].strip()}
+            for seg in result.get("segments", []) if seg["text"].strip()
+        ]
 
-        print(f"   ✅ Transcribed: '{text[:100]}...' (lang: {detected_lang})")
+        print(f"   ✅ Transcribed: '{text[:100]}...' (lang: {detected_lang}, {len(segments)} segments)")
 
-        return JSONResponse(content={"text": text, "language": detected_lang})
+        content = {"text": text, "language": detected_lang, "segments": segments}
+        if word_confidence:
+            content["words"] = [
+                {"word": w["word"], "probability": float(w.get("probability", 0.0))}
+                for seg in result.get("segments", []) for w in seg.get("words", [])
+            ]
+        return JSONResponse(content=content)
 
     except Exception as e:
         print(f"❌ Transcription error: {e}")
@@ -889,6 +907,41 @@ async def transcribe_audio(request: Request):
             content={"error": str(e)}
         )
 
+def segment_languages(audio_array, segments, chunk_lang):
+    """Detect the language of each transcribed segment. Segments confidently
+    in another language than the chunk are transcribed again in theirs, since
+    Whisper decodes the whole chunk in the chunk's language."""
+    tagged = []
+    for seg in segments:
+        text = seg["text"].strip()
+        if not text:
+            continue
+        lang = chunk_lang
+        start = int(seg["start"] * SAMPLE_RATE)
+        end = int(seg["end"] * SAMPLE_RATE)
+        clip = audio_array[start:end]
+        if len(clip) >= SEGMENT_LANGUAGE_MIN_SECONDS * SAMPLE_RATE:
+            mel = whisper.log_mel_spectrogram(whisper.pad_or_trim(clip), n_mels=whisper_model.dims.n_mels).to(whisper_model.device)
+            _, probs = whisper_model.detect_language(mel)
+            seg_lang = max(probs, key=probs.get)
+            if seg_lang != chunk_lang and probs[seg_lang] >= SEGMENT_LANGUAGE_MIN_PROBABILITY:
+                retranscribed = whisper_model.transcribe(
+                    clip,
+                    language=seg_lang,
+                    fp16=(DEVICE == "cuda"),
+                    verbose=False,
+                    temperature=0.0
+                )["text"].strip()
+                if retranscribed:
+                    lang, text = seg_lang, retranscribed
+        tagged.append({
+            "start": seg["start"],
+            "end": seg["end"],
+            "text": text,
+            "language": lang
+        })
+    return tagged
+
 @app.post("/detect-language")
 async def detect_language(request: Request):
     """HTTP endpoint for language detection"""
@@ -907,6 +960,22 @@ async def detect_language(request: Request):
             frames = wav.readframes(wav.getnframes())
             audio_array = np.frombuffer(frames, dtype=np.int16).astype(np.float32) / 32768.0
 
+        # Language probabilities from the first 30 seconds
+        clip = whisper.pad_or_trim(audio_array[:SAMPLE_RATE * 30])
+        mel = whisper.log_mel_spectrogram(clip, n_mels=whisper_model.dims.n_mels).to(whisper_model.device)
+        _, probs = whisper_model.detect_language(mel)
+        probable_lang = max(probs, key=probs.get)
+        probability = float(probs[probable_lang])
+
+        # Live sessions only need the language, not a transcript
+        if request.headers.get("x-detect-only", "").lower() == "true":
+            print(f"   ✅ Detected language: {probable_lang} ({probability:.2f})")
+            return JSONResponse(content={
+                "language": probable_lang,
+                "probability": probability,
+                "text": ""
+            })
+
         # Transcribe just to detect language (fast, no full transcription)
         result = whisper_model.transcribe(
             audio_array[:SAMPLE_RATE * 30],  # Use first 30 seconds max
@@ -920,10 +989,19 @@ async def detect_language(request: Request):
 
         print(f"   ✅ Detected language: {detected_lang}, sample: '{text_sample}...'")
 
-        return JSONResponse(content={
+        content = {
             "language": detected_lang,
+            "probability": probability if detected_lang == probable_lang else float(probs.get(detected_lang, 0.0)),
             "text": text_sample
-        })
+        }
+
+        # Bilingual speakers switch language mid-chunk; tag each segment with
+        # its own language so it can be translated from that language
+        if request.headers.get("x-segment-languages", "").lower() == "true":
+            content["text"] = result["text"].strip()
+            content["segments"] = segment_languages(audio_array, result.get("segments", []), detected_lang)
+
+        return JSONResponse(content=content)
 
     except Exception as e:
         print(f"❌ Language detection error: {e}")
@@ -1017,15 +1095,26 @@ async def transcribe_with_diarization(request: Request):
         )
 
         # Count unique speakers
-        unique_speakers = len(set(s.get("speaker", "SPEAKER_00") for s in segments_with_speakers))
+        speaker_ids = set(s.get("speaker", "SPEAKER_00") for s in segments_with_speakers)
+        unique_speakers = len(speaker_ids)
         print(f"   👥 Identified {unique_speakers} unique speaker(s)")
 
-        return JSONResponse(content={
+        content = {
             "text": full_text,
             "language": detected_lang,
             "segments": segments_with_speakers,
             "num_speakers": unique_speakers
-        })
+        }
+
+        # Running voice profile of each speaker, for matching enrolled voices
+        if request.query_params.get("include_embeddings") == "true" and session_id in session_speaker_profiles:
+            content["speaker_embeddings"] = {
+                profile["id"]: profile["embedding"].astype(float).tolist()
+                for profile in session_speaker_profiles[session_id]["profiles"]
+                if profile["id"] in speaker_ids
+            }
+
+        return JSONResponse(content=content)
 
     except Exception as e:
         print(f"❌ Transcription + Diarization error: {e}")
@@ -1036,6 +1125,33 @@ async def transcribe_with_diarization(request: Request):
             content={"error": str(e)}
         )
 
+@app.post("/speaker-embedding")
+async def speaker_embedding(request: Request):
+    """Voice embedding of a whole WAV sample, for speaker enrollment"""
+    if not EMBEDDING_ENABLED or embedding_inference is None:
+        return JSONResponse(status_code=503, content={"error": "Speaker embedding model not loaded"})
+    try:
+        audio_data = await request.body()
+        wav_file = io.BytesIO(audio_data)
+        with wave.open(wav_file, 'rb') as wav:
+            frames = wav.readframes(wav.getnframes())
+            audio_array = np.frombuffer(frames, dtype=np.int16).astype(np.float32) / 32768.0
+
+        duration = len(audio_array) / SAMPLE_RATE
+        emb = _compute_embedding(audio_array, 0.0, duration)
+        if emb is None:
+            return JSONResponse(
+                status_code=400,
+                content={"error": f"Sample too short; need at least {MIN_EMBED_DURATION:.1f}s of audio"}
+            )
+
+        print(f"🎙️ Speaker embedding computed from {duration:.1f}s sample")
+        return {"embedding": emb.astype(float).tolist(), "duration": duration}
+
+    except Exception as e:
+        print(f"❌ Speaker embedding error: {e}")
+        return JSONResponse(status_code=500, content={"error": str(e)})
+
 @app.get("/health")
 async def health():
     return {"status": "ok", "device": DEVICE, "model": MODEL_SIZE}
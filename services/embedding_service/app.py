@@ -7,14 +7,16 @@ the all-MiniLM-L6-v2 model (384 dimensions, fast and lightweight).
 Endpoints:
 - POST /embed: Generate embedding for a single text
 - POST /embed-batch: Generate embeddings for multiple texts (more efficient)
+- POST /rerank: Score passages against a query with a cross-encoder
 - GET /health: Health check endpoint
 """
 
 from fastapi import FastAPI, HTTPException
 from fastapi.middleware.cors import CORSMiddleware
 from pydantic import BaseModel
-from sentence_transformers import SentenceTransformer
+from sentence_transformers import CrossEncoder, SentenceTransformer
 from typing import List
+import os
 import uvicorn
 import logging
 
@@ -45,6 +47,18 @@ model = SentenceTransformer(MODEL_NAME)
 EMBEDDING_DIM = model.get_sentence_embedding_dimension()
 logger.info(f"Model loaded successfully. Embedding dimension: {EMBEDDING_DIM}")
 
+# Cross-encoder for reranking, loaded on first use
+RERANK_MODEL_NAME = os.getenv('RERANK_MODEL', 'cross-encoder/ms-marco-MiniLM-L-6-v2')
+reranker = None
+
+
+def get_reranker():
+    global reranker
+    if reranker is None:
+        logger.info(f"Loading rerank model: {RERANK_MODEL_NAME}")
+        reranker = CrossEncoder(RERANK_MODEL_NAME)
+    return reranker
+
 
 # Request/Response models
 class EmbedRequest(BaseModel):
@@ -83,6 +97,16 @@ class EmbedBatchResponse(BaseModel):
     count: int
 
 
+class RerankRequest(BaseModel):
+    query: str
+    documents: List[str]
+
+
+class RerankResponse(BaseModel):
+    scores: List[float]
+    model: str
+
+
 # Endpoints
 @app.post("/embed", response_model=EmbedResponse)
 async def embed_text(request: EmbedRequest):
@@ -156,6 +180,36 @@ async def embed_batch(request: EmbedBatchRequest):
         raise HTTPException(status_code=500, detail=f"Batch embedding generation failed: {str(e)}")
 
 
+@app.post("/rerank", response_model=RerankResponse)
+async def rerank(request: RerankRequest):
+    """
+    Score each document's relevance to the query with a cross-encoder.
+
+    Args:
+        request: RerankRequest with the query and documents
+
+    Returns:
+        RerankResponse with one score per document, in request order
+    """
+    if not request.query.strip():
+        raise HTTPException(status_code=400, detail="Query cannot be empty")
+    if not request.documents:
+        return RerankResponse(scores=[], model=RERANK_MODEL_NAME)
+
+    try:
+        logger.info(f"Reranking {len(request.documents)} documents")
+        scores = get_reranker().predict(
+            [(request.query, document) for document in request.documents],
+            batch_size=32,
+            show_progress_bar=False
+        )
+        return RerankResponse(scores=[float(score) for score in scores], model=RERANK_MODEL_NAME)
+
+    except Exception as e:
+        logger.error(f"Error reranking documents: {str(e)}")
+        raise HTTPException(status_code=500, detail=f"Reranking failed: {str(e)}")
+
+
 @app.get("/health")
 async def health_check():
     """
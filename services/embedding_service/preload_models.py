@@ -2,8 +2,12 @@
 Preload embedding models during Docker build.
 This ensures the model is cached and ready when the container starts.
 """
-from sentence_transformers import SentenceTransformer
+from sentence_transformers import CrossEncoder, SentenceTransformer
 
 print("Downloading sentence-transformers/all-MiniLM-L6-v2...")
 model = SentenceTransformer('sentence-transformers/all-MiniLM-L6-v2')
 print(f"Model downloaded successfully. Embedding dimension: {model.get_sentence_embedding_dimension()}")
+
+print("Downloading cross-encoder/ms-marco-MiniLM-L-6-v2...")
+CrossEncoder('cross-encoder/ms-marco-MiniLM-L-6-v2')
+print("Rerank model downloaded successfully.")
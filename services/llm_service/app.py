@@ -6,14 +6,17 @@ It handles prompt construction and communication with Ollama for RAG-based Q&A.
 
 Endpoints:
 - POST /generate: Generate response from LLM with context
+- POST /generate/stream: Same, streamed token by token as server-sent events
 - GET /health: Health check endpoint
 """
 
 from fastapi import FastAPI, HTTPException
 from fastapi.middleware.cors import CORSMiddleware
+from fastapi.responses import StreamingResponse
 from pydantic import BaseModel
 from typing import Optional
 import requests
+import json
 import os
 import logging
 
@@ -90,6 +93,29 @@ class GenerateResponse(BaseModel):
     model: str
 
 
+def build_prompt(request: GenerateRequest) -> str:
+    """Build the full prompt with system instructions and context."""
+    language = request.language or "en"
+    language_instruction = LANGUAGE_INSTRUCTIONS.get(language, LANGUAGE_INSTRUCTIONS["en"])
+
+    full_prompt = f"""You are a helpful AI assistant answering questions about a meeting transcript.
+
+Context from the meeting:
+{request.context}
+
+User question: {request.prompt}
+
+{language_instruction}
+- If the user asks for a summary or what was discussed, summarize the key points from the context.
+- If the context is partial, answer with what is available and mention it is based on partial transcript.
+- Only say "I don't have enough information" (in the target language) when the context is empty or unrelated to the question.
+- Base your answer ONLY on the context provided above.
+- Respond entirely in the requested language.
+
+Answer:"""
+    return full_prompt
+
+
 # Endpoints
 @app.post("/generate", response_model=GenerateResponse)
 async def generate(request: GenerateRequest):
@@ -113,25 +139,7 @@ async def generate(request: GenerateRequest):
         logger.info(f"Context length: {len(request.context)} chars")
         logger.info(f"Response language: {request.language}")
 
-        # Build full prompt with system instructions and context
-        language = request.language or "en"
-        language_instruction = LANGUAGE_INSTRUCTIONS.get(language, LANGUAGE_INSTRUCTIONS["en"])
-
-        full_prompt = f"""You are a helpful AI assistant answering questions about a meeting transcript.
-
-Context from the meeting:
-{request.context}
-
-User question: {request.prompt}
-
-{language_instruction}
-- If the user asks for a summary or what was discussed, summarize the key points from the context.
-- If the context is partial, answer with what is available and mention it is based on partial transcript.
-- Only say "I don't have enough information" (in the target language) when the context is empty or unrelated to the question.
-- Base your answer ONLY on the context provided above.
-- Respond entirely in the requested language.
-
-Answer:"""
+        full_prompt = build_prompt(request)
 
         # Call Ollama API
         ollama_url = f"{OLLAMA_BASE_URL}/api/generate"
@@ -198,6 +206,77 @@ Answer:"""
         )
 
 
+def sse(event: str, data: dict) -> str:
+    return f"event: {event}\ndata: {json.dumps(data, ensure_ascii=False)}\n\n"
+
+
+@app.post("/generate/stream")
+async def generate_stream(request: GenerateRequest):
+    """
+    Generate a response like /generate, streamed as server-sent events:
+    "token" events carry {"text": ...} as Ollama produces it, then a "done"
+    event carries {"model": ...}. Failures after the stream started are sent
+    as an "error" event with {"error": ...}.
+    """
+    if not request.prompt or not request.prompt.strip():
+        raise HTTPException(status_code=400, detail="Prompt cannot be empty")
+
+    payload = {
+        "model": DEFAULT_MODEL,
+        "prompt": build_prompt(request),
+        "stream": True,
+        "options": {
+            "temperature": request.temperature,
+            "num_predict": request.max_tokens
+        }
+    }
+
+    try:
+        response = requests.post(
+            f"{OLLAMA_BASE_URL}/api/generate",
+            json=payload,
+            stream=True,
+            timeout=(10, 120)  # connect, then at most 2 minutes between tokens
+        )
+        response.raise_for_status()
+    except requests.exceptions.ConnectionError:
+        raise HTTPException(
+            status_code=503,
+            detail=f"Cannot connect to Ollama at {OLLAMA_BASE_URL}. Is Ollama running?"
+        )
+    except requests.exceptions.HTTPError as e:
+        raise HTTPException(status_code=502, detail=f"Ollama error: {str(e)}")
+
+    def events():
+        produced = 0
+        try:
+            for line in response.iter_lines():
+                if not line:
+                    continue
+                chunk = json.loads(line)
+                if chunk.get("error"):
+                    yield sse("error", {"error": chunk["error"]})
+                    return
+                token = chunk.get("response", "")
+                if token:
+                    produced += len(token)
+                    yield sse("token", {"text": token})
+                if chunk.get("done"):
+                    break
+            if produced == 0:
+                yield sse("error", {"error": "Ollama returned empty response"})
+                return
+            logger.info(f"Streamed response (length: {produced} chars)")
+            yield sse("done", {"model": DEFAULT_MODEL})
+        except Exception as e:
+            logger.error(f"Error streaming response: {str(e)}")
+            yield sse("error", {"error": f"Generation failed: {str(e)}"})
+        finally:
+            response.close()
+
+    return StreamingResponse(events(), media_type="text/event-stream")
+
+
 @app.get("/health")
 async def health_check():
     """
@@ -15,6 +15,7 @@ import logging
 import asyncio
 from threading import Thread
 import re
+from typing import Optional
 from pydub import AudioSegment
 
 logging.basicConfig(level=logging.INFO)
@@ -91,9 +92,12 @@ async def startup_event():
     thread = Thread(target=load_xtts_model, daemon=True)
     thread.start()
 
+DEFAULT_SPEAKER = "Claribel Dervla"
+
 class TTSRequest(BaseModel):
     text: str
     language: str = "en"
+    voice: Optional[str] = None  # XTTS v2 built-in speaker name
 
 @app.post("/synthesize")
 async def synthesize(req: TTSRequest):
@@ -123,7 +127,7 @@ async def synthesize(req: TTSRequest):
                     text=req.text,
                     file_path=output_path,
                     language=req.language,
-                    speaker="Claribel Dervla"
+                    speaker=req.voice or DEFAULT_SPEAKER
                 )
             except Exception as e:
                 logger.warning(f"XTTS v2 failed: {e}, falling back to gTTS")
@@ -3,6 +3,7 @@ const btnStop = document.getElementById("btnStop");
 const statusEl = document.getElementById("status");
 const targetLangEl = document.getElementById("targetLang");
 const sourceLangEl = document.getElementById("sourceLang");
+const speakTranslationsEl = document.getElementById("speakTranslations");
 
 const finalSrc = document.getElementById("finalSrc");
 const partialSrc = document.getElementById("partialSrc");
@@ -18,6 +19,26 @@ let sourceNode = null;
 let workletNode = null;
 
 let lastSampleRate = 48000;
+let resumeToken = "";
+// Translation lines still receiving sentences, by final id
+const translationLines = new Map();
+
+// Spoken translations arrive as audio_start, binary frames, audio_end
+let speechChunks = [];
+let speechType = "";
+let speechQueue = Promise.resolve();
+
+function playSpeech(chunks, type) {
+  const url = URL.createObjectURL(new Blob(chunks, { type }));
+  speechQueue = speechQueue.then(() => new Promise((resolve) => {
+    const audio = new Audio(url);
+    audio.onended = audio.onerror = () => {
+      URL.revokeObjectURL(url);
+      resolve();
+    };
+    audio.play().catch(() => audio.onended());
+  }));
+}
 
 // Very simple linear resampler (good enough for MVP)
 function resampleTo16k(float32, inRate) {
@@ -54,6 +75,7 @@ function addLine(container, text) {
   div.textContent = text;
   container.appendChild(div);
   container.scrollTop = container.scrollHeight;
+  return div;
 }
 
 function setStatus(s) {
@@ -69,6 +91,10 @@ function connectWS() {
     ws.onerror = (e) => reject(e);
 
     ws.onmessage = (evt) => {
+      if (evt.data instanceof ArrayBuffer) {
+        speechChunks.push(evt.data);
+        return;
+      }
       const msg = JSON.parse(evt.data);
       console.log('WebSocket message:', msg);
 
@@ -77,11 +103,41 @@ function connectWS() {
       } else if (msg.type === "final") {
         partialSrc.textContent = "";
         addLine(finalSrc, msg.text);
+      } else if (msg.type === "translation_segment") {
+        // Sentences of a long final arrive one by one under the final's id
+        let line = translationLines.get(msg.id);
+        if (!line) {
+          line = addLine(finalTr, "");
+          translationLines.set(msg.id, line);
+        }
+        line.textContent = line.textContent ? `${line.textContent} ${msg.text}` : msg.text;
+        finalTr.scrollTop = finalTr.scrollHeight;
       } else if (msg.type === "translation") {
-        // match by id if you want; MVP just appends
-        addLine(finalTr, msg.text);
+        const line = translationLines.get(msg.id);
+        if (line) {
+          // Replace the streamed sentences with the joined translation
+          line.textContent = msg.text;
+          translationLines.delete(msg.id);
+        } else {
+          addLine(finalTr, msg.text);
+        }
       } else if (msg.type === "partial_translation") {
         partialTr.textContent = msg.text || "";
+      } else if (msg.type === "audio_start") {
+        speechChunks = [];
+        speechType = msg.text;
+      } else if (msg.type === "audio_end") {
+        playSpeech(speechChunks, speechType);
+        speechChunks = [];
+      } else if (msg.type === "language_switch") {
+        // The server heard a different language and now transcribes in it
+        if (sourceLangEl && [...sourceLangEl.options].some((o) => o.value === msg.language)) {
+          sourceLangEl.value = msg.language;
+        }
+        setStatus(`Source language switched to ${msg.language}`);
+      } else if (msg.type === "session") {
+        // Sent back on the next start so caption IDs continue after a reconnect
+        resumeToken = msg.token;
       } else if (msg.type === "info") {
         setStatus(msg.text);
       }
@@ -123,7 +179,9 @@ async function start() {
       type: "start",
       sampleRate: 16000,
       targetLang: targetLangEl.value,
-      sourceLang: sourceLangEl ? sourceLangEl.value : "auto"
+      sourceLang: sourceLangEl ? sourceLangEl.value : "auto",
+      speakTranslations: speakTranslationsEl ? speakTranslationsEl.checked : false,
+      resumeToken: resumeToken
     };
     console.log('Sending start message:', startMsg);
     ws.send(JSON.stringify(startMsg));
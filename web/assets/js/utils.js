@@ -92,6 +92,58 @@ export function getAccessToken() {
     );
 }
 
+/**
+ * fetch() that sends the signed-in user's token, when there is one
+ */
+export function authFetch(url, options = {}) {
+    const token = getAccessToken();
+    if (!token) {
+        return fetch(url, options);
+    }
+    const headers = new Headers(options.headers || {});
+    if (!headers.has('Authorization')) {
+        headers.set('Authorization', `Bearer ${token}`);
+    }
+    return fetch(url, { ...options, headers });
+}
+
+/**
+ * Add the access token as ?token= to a WebSocket URL (browsers cannot set
+ * headers on WebSocket requests)
+ */
+export function withAuthToken(url) {
+    const token = getAccessToken();
+    if (!token) {
+        return url;
+    }
+    const separator = url.includes('?') ? '&' : '?';
+    return `${url}${separator}token=${encodeURIComponent(token)}`;
+}
+
+/**
+ * Add a single-use ticket as ?ticket= to a WebSocket or event-stream URL.
+ * Browsers cannot set headers on those requests, so the signed-in user
+ * mints a short-lived ticket for this one connection instead of putting the
+ * access token in the URL. purpose is stream, meeting, recording, progress,
+ * notifications or captions; target is the room code or session ID.
+ */
+export async function withSocketTicket(url, purpose, target = '') {
+    if (!getAccessToken()) {
+        return url;
+    }
+    const response = await authFetch('/api/ws-tickets', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ purpose, target })
+    });
+    if (!response.ok) {
+        throw new Error(`Failed to get connection ticket: ${response.status}`);
+    }
+    const { ticket } = await response.json();
+    const separator = url.includes('?') ? '&' : '?';
+    return `${url}${separator}ticket=${encodeURIComponent(ticket)}`;
+}
+
 export async function postJsonWithAuth(url, payload) {
     const token = getAccessToken();
     if (!token) {
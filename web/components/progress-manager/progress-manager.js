@@ -1,8 +1,11 @@
 /**
  * Progress Manager Component
- * Handles WebSocket-based progress tracking for long-running operations.
+ * Handles WebSocket-based progress tracking for long-running operations,
+ * falling back to server-sent events when the WebSocket cannot connect.
  */
 
+import { withSocketTicket } from '../../assets/js/utils.js';
+
 // Stage emoji mappings for visual feedback
 export const STAGE_EMOJIS = {
   'upload': '📤',
@@ -35,30 +38,58 @@ export class ProgressManager {
     this.elements = elements;
     this.stageEmojis = options.stageEmojis || STAGE_EMOJIS;
     this.ws = null;
+    this.eventSource = null;
     this.onCompleteCallback = null;
     this.onErrorCallback = null;
     this.onUpdateCallback = null;
   }
 
   /**
-   * Connect to the progress WebSocket
+   * Connect to the progress WebSocket, or the event stream if that fails
    * @returns {Promise<void>}
    */
   async connect() {
-    return new Promise((resolve, reject) => {
-      const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
-      const wsUrl = `${protocol}//${window.location.host}/ws/progress/${this.sessionId}`;
+    try {
+      await this.connectWebSocket();
+    } catch (error) {
+      console.warn('Progress WebSocket unavailable, using event stream:', error);
+      try {
+        await this.connectEventSource();
+      } catch (streamError) {
+        if (this.onErrorCallback) {
+          this.onErrorCallback(streamError);
+        }
+        throw streamError;
+      }
+    }
+  }
+
+  /**
+   * Connect to the progress WebSocket
+   * @returns {Promise<void>}
+   */
+  async connectWebSocket() {
+    const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+    const wsUrl = await withSocketTicket(
+      `${protocol}//${window.location.host}/ws/progress/${this.sessionId}`, 'progress', this.sessionId);
 
+    return new Promise((resolve, reject) => {
       this.ws = new WebSocket(wsUrl);
+      let opened = false;
 
       this.ws.onopen = () => {
+        opened = true;
         console.log('Progress WebSocket connected');
         resolve();
       };
 
       this.ws.onerror = (error) => {
         console.error('WebSocket error:', error);
-        reject(error);
+        if (!opened) {
+          this.ws = null;
+          reject(error);
+          return;
+        }
         if (this.onErrorCallback) {
           this.onErrorCallback(error);
         }
@@ -77,7 +108,41 @@ export class ProgressManager {
   }
 
   /**
-   * Handle progress update from WebSocket
+   * Connect to the progress event stream (server-sent events)
+   * @returns {Promise<void>}
+   */
+  async connectEventSource() {
+    const url = await withSocketTicket(
+      `${window.location.origin}/progress/${this.sessionId}/events`, 'progress', this.sessionId);
+
+    return new Promise((resolve, reject) => {
+      this.eventSource = new EventSource(url);
+      let opened = false;
+
+      this.eventSource.addEventListener('ready', () => {
+        opened = true;
+        console.log('Progress event stream connected');
+        resolve();
+      });
+
+      this.eventSource.addEventListener('progress', (event) => {
+        const update = JSON.parse(event.data);
+        console.log('Progress update:', update);
+        this.handleUpdate(update);
+      });
+
+      // EventSource reconnects by itself; only a failed first connection is fatal
+      this.eventSource.onerror = () => {
+        if (!opened) {
+          this.cleanup();
+          reject(new Error('Progress event stream failed to connect'));
+        }
+      };
+    });
+  }
+
+  /**
+   * Handle progress update from the WebSocket or event stream
    * @param {object} update - Progress update object
    */
   handleUpdate(update) {
@@ -169,13 +234,17 @@ export class ProgressManager {
   }
 
   /**
-   * Clean up and close WebSocket connection
+   * Clean up and close the WebSocket or event stream
    */
   cleanup() {
     if (this.ws) {
       this.ws.close();
       this.ws = null;
     }
+    if (this.eventSource) {
+      this.eventSource.close();
+      this.eventSource = null;
+    }
   }
 
   /**
@@ -92,6 +92,31 @@ function addChatMessage(role, text, language) {
 
     chatMessages.appendChild(message);
     chatMessages.scrollTop = chatMessages.scrollHeight;
+    return message;
+}
+
+// Reads server-sent events from a fetch response, calling onEvent(event, data)
+async function readEventStream(response, onEvent) {
+    const reader = response.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = '';
+    while (true) {
+        const { value, done } = await reader.read();
+        if (done) break;
+        buffer += decoder.decode(value, { stream: true });
+        let boundary;
+        while ((boundary = buffer.indexOf('\n\n')) >= 0) {
+            const block = buffer.slice(0, boundary);
+            buffer = buffer.slice(boundary + 2);
+            let event = 'message';
+            const data = [];
+            block.split('\n').forEach((line) => {
+                if (line.startsWith('event:')) event = line.slice(6).trim();
+                else if (line.startsWith('data:')) data.push(line.slice(5).trimStart());
+            });
+            if (data.length) onEvent(event, JSON.parse(data.join('\n')));
+        }
+    }
 }
 
 function resetChat() {
@@ -143,6 +168,7 @@ async function sendChatMessage() {
     addChatMessage('user', question);
     chatInput.value = '';
 
+    let answerMessage = null;
     try {
         if (!chatSessionId) {
             chatSessionId = await createChatSession(token);
@@ -152,7 +178,7 @@ async function sendChatMessage() {
             throw new Error('Unable to create chat session');
         }
 
-        const response = await fetch('/api/chat/query', {
+        const response = await fetch('/api/chat/query/stream', {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
@@ -172,10 +198,30 @@ async function sendChatMessage() {
             throw new Error(`Query failed (${response.status})`);
         }
 
-        const data = await response.json();
-        addChatMessage('assistant', data.answer || 'No answer returned.', chatResponseLanguage.value);
+        // The answer streams in token by token; the final answer replaces it
+        // since grounding checks may have removed unsupported sentences
+        let streamError = null;
+        let answered = false;
+        answerMessage = addChatMessage('assistant', '', chatResponseLanguage.value);
+        await readEventStream(response, (event, data) => {
+            if (event === 'token') {
+                answerMessage.textContent += data.text || '';
+                chatMessages.scrollTop = chatMessages.scrollHeight;
+            } else if (event === 'done') {
+                answered = true;
+                answerMessage.textContent = data.answer || 'No answer returned.';
+            } else if (event === 'error') {
+                streamError = new Error(data.error || 'Query failed');
+            }
+        });
+        if (streamError || !answered) {
+            throw streamError || new Error('Answer stream ended early');
+        }
     } catch (error) {
         console.error('Chat query failed:', error);
+        if (answerMessage) {
+            answerMessage.remove();
+        }
         const isNetworkError = error && (error.name === 'TypeError' || `${error}`.includes('NetworkError'));
         const fallbackMessage = isNetworkError
             ? 'The chat service is warming up. Please retry in a few seconds.'
@@ -224,11 +270,19 @@ function renderSnapshots(snapshots) {
         <tr>
             <td>${escapeHtml(getLanguageName(snapshot.language))}</td>
             <td>${formatDateTime(snapshot.createdAt)}</td>
-            <td><button class="btn-secondary" data-lang="${escapeHtml(snapshot.language)}">Download</button></td>
+            <td>
+                <button class="btn-secondary" data-lang="${escapeHtml(snapshot.language)}">Download</button>
+                <button class="btn-secondary" data-lang="${escapeHtml(snapshot.language)}" data-format="docx">DOCX</button>
+                <button class="btn-secondary" data-lang="${escapeHtml(snapshot.language)}" data-format="pdf">PDF</button>
+            </td>
         </tr>
     `).join('');
 
-    snapshotBody.querySelectorAll('button').forEach((button) => {
+    snapshotBody.querySelectorAll('button[data-format]').forEach((button) => {
+        button.addEventListener('click', () => exportTranscript(button.dataset.lang, button.dataset.format));
+    });
+
+    snapshotBody.querySelectorAll('button:not([data-format])').forEach((button) => {
         button.addEventListener('click', async () => {
             const language = button.dataset.lang;
             if (!meetingRoomCode || !language) return;
@@ -247,6 +301,28 @@ function renderSnapshots(snapshots) {
     });
 }
 
+// Downloads the transcript with minutes as a formatted document
+async function exportTranscript(language, format) {
+    if (!meetingRoomCode || !language) return;
+    try {
+        const tz = Intl.DateTimeFormat().resolvedOptions().timeZone;
+        const url = `/api/meetings/${encodeURIComponent(meetingRoomCode)}/export?format=${format}&lang=${encodeURIComponent(language)}&tz=${encodeURIComponent(tz)}`;
+        const token = getAccessToken();
+        const response = await fetch(url, {
+            headers: token ? { 'Authorization': `Bearer ${token}` } : {}
+        });
+        if (!response.ok) {
+            const data = await response.json().catch(() => ({}));
+            throw new Error(data.error || `Export failed (${response.status})`);
+        }
+        const blob = await response.blob();
+        downloadBlob(blob, `meeting-${meetingRoomCode}-${language}-transcript.${format}`);
+    } catch (error) {
+        console.error('Transcript export failed:', error);
+        alert(`Failed to export transcript: ${error.message}`);
+    }
+}
+
 function renderListSection(title, items) {
     if (!items || items.length === 0) {
         return `<div class=\"placeholder-text\">No ${title.toLowerCase()} recorded.</div>`;
@@ -291,6 +367,8 @@ function getRoleBadgeClass(role) {
     switch (role) {
         case 'owner':
             return 'role-badge role-owner';
+        case 'cohost':
+            return 'role-badge role-cohost';
         case 'editor':
             return 'role-badge role-editor';
         case 'viewer':
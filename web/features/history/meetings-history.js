@@ -76,6 +76,8 @@ function getRoleBadgeClass(role) {
     switch (role) {
         case 'owner':
             return 'role-badge role-owner';
+        case 'cohost':
+            return 'role-badge role-cohost';
         case 'editor':
             return 'role-badge role-editor';
         case 'viewer':
@@ -0,0 +1,312 @@
+import { initAuth, login } from '/assets/js/auth.js';
+import { getAccessToken, getLanguageName, escapeHtml } from '/assets/js/utils.js';
+
+const authOverlay = document.getElementById('authOverlay');
+const signInBtn = document.getElementById('signInBtn');
+const mainContent = document.getElementById('mainContent');
+const loadingState = document.getElementById('loadingState');
+const sessionsGrid = document.getElementById('sessionsGrid');
+const emptyState = document.getElementById('emptyState');
+const pagination = document.getElementById('pagination');
+const prevBtn = document.getElementById('prevBtn');
+const nextBtn = document.getElementById('nextBtn');
+const pageInfo = document.getElementById('pageInfo');
+const typeFilter = document.getElementById('typeFilter');
+const languageFilter = document.getElementById('languageFilter');
+const fromFilter = document.getElementById('fromFilter');
+const toFilter = document.getElementById('toFilter');
+
+const PAGE_LIMIT = 12;
+const TYPE_LABELS = {
+    video: 'Video',
+    audio: 'Audio',
+    streaming: 'Stream'
+};
+let currentOffset = 0;
+let totalSessions = 0;
+
+function showAuthRequired() {
+    authOverlay.style.display = 'flex';
+    mainContent.style.display = 'none';
+}
+
+function showMainContent() {
+    authOverlay.style.display = 'none';
+    mainContent.style.display = 'block';
+}
+
+function setLoading(isLoading) {
+    loadingState.style.display = isLoading ? 'block' : 'none';
+    sessionsGrid.style.display = 'none';
+    emptyState.style.display = 'none';
+    pagination.style.display = 'none';
+}
+
+function showEmptyState(title, message) {
+    emptyState.querySelector('h3').textContent = title;
+    emptyState.querySelector('p').textContent = message;
+
+    loadingState.style.display = 'none';
+    sessionsGrid.style.display = 'none';
+    emptyState.style.display = 'block';
+    pagination.style.display = 'none';
+}
+
+function formatDateTime(isoString) {
+    if (!isoString) return 'Unknown';
+    const date = new Date(isoString);
+    if (Number.isNaN(date.getTime())) return 'Unknown';
+    return date.toLocaleString();
+}
+
+function formatDuration(seconds) {
+    if (!seconds) return '';
+    const minutes = Math.floor(seconds / 60);
+    return minutes > 0 ? `${minutes}m ${seconds % 60}s` : `${seconds}s`;
+}
+
+function formatBytes(bytes) {
+    if (!bytes) return '';
+    const units = ['B', 'KB', 'MB', 'GB'];
+    let value = bytes;
+    let unit = 0;
+    while (value >= 1024 && unit < units.length - 1) {
+        value /= 1024;
+        unit++;
+    }
+    return `${value.toFixed(unit === 0 ? 0 : 1)} ${units[unit]}`;
+}
+
+function authHeaders() {
+    return { 'Authorization': `Bearer ${getAccessToken()}` };
+}
+
+function sessionPath(card) {
+    return `/api/history/${encodeURIComponent(card.dataset.type)}/${encodeURIComponent(card.dataset.id)}`;
+}
+
+function renderSessions(sessions) {
+    if (!sessions || sessions.length === 0) {
+        showEmptyState('No sessions found', 'Your uploads, recordings and streams will appear here.');
+        return;
+    }
+
+    sessionsGrid.innerHTML = sessions.map((session) => {
+        const languages = [session.sourceLang, session.targetLang]
+            .filter(Boolean)
+            .map((lang) => `<span class="lang-badge">${escapeHtml(getLanguageName(lang))}</span>`)
+            .join(' → ');
+        const duration = formatDuration(session.durationSeconds);
+
+        return `
+            <div class="meeting-card" data-type="${escapeHtml(session.type)}" data-id="${escapeHtml(session.sessionId)}">
+                <div class="meeting-card-header">
+                    <div class="session-filename">${escapeHtml(session.filename || session.sessionId)}</div>
+                    <span class="meeting-status ended">${escapeHtml(TYPE_LABELS[session.type] || session.type)}</span>
+                </div>
+                <div class="meeting-meta">
+                    <div class="meeting-meta-item">Date: ${formatDateTime(session.createdAt)}</div>
+                    ${duration ? `<div class="meeting-meta-item">Duration: ${duration}</div>` : ''}
+                </div>
+                ${languages ? `<div class="meeting-languages">${languages}</div>` : ''}
+            </div>
+        `;
+    }).join('');
+
+    sessionsGrid.querySelectorAll('.meeting-card').forEach((card) => {
+        card.addEventListener('click', (event) => {
+            if (event.target.closest('.session-detail')) {
+                return;
+            }
+            toggleDetail(card);
+        });
+    });
+
+    sessionsGrid.style.display = 'grid';
+    emptyState.style.display = 'none';
+    loadingState.style.display = 'none';
+}
+
+async function toggleDetail(card) {
+    const open = card.querySelector('.session-detail');
+    if (open) {
+        open.remove();
+        return;
+    }
+
+    const detail = document.createElement('div');
+    detail.className = 'session-detail';
+    detail.textContent = 'Loading...';
+    card.appendChild(detail);
+
+    try {
+        const response = await fetch(sessionPath(card), { headers: authHeaders() });
+        if (!response.ok) {
+            throw new Error(`Failed to load session (${response.status})`);
+        }
+        const data = await response.json();
+        const session = data.session || {};
+        const transcript = session.transcription || session.finalTranscript || '';
+        const translation = session.translation || session.finalTranslation || '';
+        const files = (data.files || []).map((file) => `
+            <li>
+                <a href="#" data-file-id="${file.id}">${escapeHtml(file.fileKey.split('/').pop())}</a>
+                ${file.fileSizeBytes ? `(${formatBytes(file.fileSizeBytes)})` : ''}
+            </li>
+        `).join('');
+
+        detail.innerHTML = `
+            ${transcript ? `<h4>Transcript</h4><div class="session-text">${escapeHtml(transcript)}</div>` : ''}
+            ${translation ? `<h4>Translation</h4><div class="session-text">${escapeHtml(translation)}</div>` : ''}
+            ${files ? `<h4>Files</h4><ul class="session-files">${files}</ul>` : ''}
+            <div class="session-actions">
+                <button class="btn-secondary btn-delete" type="button">Delete</button>
+            </div>
+        `;
+
+        detail.querySelectorAll('[data-file-id]').forEach((link) => {
+            link.addEventListener('click', (event) => {
+                event.preventDefault();
+                downloadFile(link.dataset.fileId);
+            });
+        });
+        detail.querySelector('.btn-delete').addEventListener('click', () => deleteSession(card));
+    } catch (error) {
+        console.error('Failed to load session:', error);
+        detail.textContent = 'Unable to load this session.';
+    }
+}
+
+async function downloadFile(fileId) {
+    try {
+        const response = await fetch(`/api/files/${encodeURIComponent(fileId)}/url`, { headers: authHeaders() });
+        if (!response.ok) {
+            throw new Error(`Failed to get download link (${response.status})`);
+        }
+        const data = await response.json();
+        window.open(data.url, '_blank', 'noopener');
+    } catch (error) {
+        console.error('Failed to download file:', error);
+        alert('Unable to download this file.');
+    }
+}
+
+async function deleteSession(card) {
+    if (!confirm('Delete this session and its stored files? This cannot be undone.')) {
+        return;
+    }
+
+    try {
+        const response = await fetch(sessionPath(card), {
+            method: 'DELETE',
+            headers: authHeaders()
+        });
+        if (!response.ok) {
+            throw new Error(`Failed to delete session (${response.status})`);
+        }
+        const data = await response.json();
+        if (data.filesFailed > 0) {
+            alert(`The session was deleted, but ${data.filesFailed} stored file(s) could not be removed.`);
+        }
+        await loadSessions();
+    } catch (error) {
+        console.error('Failed to delete session:', error);
+        alert('Unable to delete this session.');
+    }
+}
+
+function updatePagination() {
+    if (totalSessions <= PAGE_LIMIT) {
+        pagination.style.display = 'none';
+        return;
+    }
+
+    const start = currentOffset + 1;
+    const end = Math.min(currentOffset + PAGE_LIMIT, totalSessions);
+    pageInfo.textContent = `Showing ${start}-${end} of ${totalSessions}`;
+
+    prevBtn.disabled = currentOffset === 0;
+    nextBtn.disabled = currentOffset + PAGE_LIMIT >= totalSessions;
+
+    pagination.style.display = 'flex';
+}
+
+async function loadSessions() {
+    setLoading(true);
+
+    const token = getAccessToken();
+    if (!token) {
+        showAuthRequired();
+        return;
+    }
+
+    try {
+        const url = new URL('/api/history', window.location.origin);
+        url.searchParams.set('limit', PAGE_LIMIT.toString());
+        url.searchParams.set('offset', currentOffset.toString());
+        if (typeFilter.value) {
+            url.searchParams.set('type', typeFilter.value);
+        }
+        if (languageFilter.value.trim()) {
+            url.searchParams.set('language', languageFilter.value.trim());
+        }
+        if (fromFilter.value) {
+            url.searchParams.set('from', fromFilter.value);
+        }
+        if (toFilter.value) {
+            url.searchParams.set('to', toFilter.value);
+        }
+
+        const response = await fetch(url.toString(), { headers: authHeaders() });
+
+        if (response.status === 401 || response.status === 403) {
+            showAuthRequired();
+            return;
+        }
+
+        if (!response.ok) {
+            throw new Error(`Failed to load sessions (${response.status})`);
+        }
+
+        const data = await response.json();
+        totalSessions = data.total || 0;
+        renderSessions(data.sessions || []);
+        updatePagination();
+    } catch (error) {
+        console.error('Failed to load sessions:', error);
+        showEmptyState('Unable to load sessions', 'Please try again in a moment.');
+    }
+}
+
+async function init() {
+    signInBtn.addEventListener('click', () => login());
+
+    const profile = await initAuth();
+    const token = getAccessToken();
+    if (!profile || !token) {
+        showAuthRequired();
+        return;
+    }
+
+    showMainContent();
+    await loadSessions();
+}
+
+[typeFilter, languageFilter, fromFilter, toFilter].forEach((input) => {
+    input.addEventListener('change', () => {
+        currentOffset = 0;
+        loadSessions();
+    });
+});
+
+prevBtn.addEventListener('click', () => {
+    currentOffset = Math.max(0, currentOffset - PAGE_LIMIT);
+    loadSessions();
+});
+
+nextBtn.addEventListener('click', () => {
+    currentOffset = currentOffset + PAGE_LIMIT;
+    loadSessions();
+});
+
+init();
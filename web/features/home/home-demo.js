@@ -9,7 +9,6 @@ let demoMediaStream = null;
 let demoAudioContext = null;
 let demoProcessor = null;
 let isDemoRunning = false;
-let demoSessionId = null;
 
 function setDemoStatus(status, liveText) {
   demoStatusText.textContent = status;
@@ -65,7 +64,6 @@ function resample(audioBuffer, inputSampleRate, targetSampleRate) {
 
 async function startDemo() {
   if (isDemoRunning) return;
-  demoSessionId = `home_demo_${Date.now()}`;
   demoLines.innerHTML = '';
   setDemoRunning(true);
   setDemoStatus('Connecting...', 'Connecting to ASR...');
@@ -99,9 +97,10 @@ async function startDemo() {
     source.connect(demoProcessor);
     demoProcessor.connect(demoAudioContext.destination);
 
+    // A classic script, so the ticket helper is loaded on demand
+    const { withSocketTicket } = await import('/assets/js/utils.js');
     const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
-    const asrHost = window.location.hostname || 'localhost';
-    demoWs = new WebSocket(`${protocol}//${asrHost}:8003/stream?language=auto&session_id=${demoSessionId}`);
+    demoWs = new WebSocket(await withSocketTicket(`${protocol}//${window.location.host}/ws/stream?language=auto`, 'stream'));
 
     demoWs.onopen = () => {
       setDemoStatus('Listening...', 'Listening for speech...');
@@ -4,8 +4,8 @@
  */
 
 // Import shared utilities
-import { convertToPCM16, getAudioLevel, resampleAudio } from '/assets/js/audio-processor.js';
-import { getLanguageName, escapeHtml, getAccessToken } from '/assets/js/utils.js';
+import { convertToPCM16, getAudioLevel } from '/assets/js/audio-processor.js';
+import { getLanguageName, escapeHtml, getAccessToken, authFetch, withSocketTicket } from '/assets/js/utils.js';
 
 // Meeting WebSocket Client
 let meetingWs = null;
@@ -17,6 +17,7 @@ let isConnected = false;
 
 // Session data
 let myParticipantId = null;
+let myParticipantToken = null; // proves the participant ID is ours on /ws/meeting
 let myParticipantName = null;
 let myTargetLanguage = null;
 let meetingId = null;
@@ -24,6 +25,19 @@ let roomCode = null;
 let meetingMode = null;
 let hostToken = null;
 let isEndingMeeting = false;
+// Meeting event seqs seen; on reconnect the server replays everything after
+// replayFromSeq, the end of the unbroken run received so far
+let eventSeqs = new Set();
+// Captions of long utterances still receiving sentences, by utteranceId
+const segmentCaptions = new Map();
+// Partial captions of unfinished speech, by speakerParticipantId
+const partialCaptions = new Map();
+let replayFromSeq = 0;
+// Reclaims this participant's seat after a dropped connection; cleared when
+// the seat is gone (kicked, rejected, meeting ended)
+let resumeToken = null;
+let resumeAttempts = 0;
+const maxResumeAttempts = 5;
 
 // Track speaking participants
 const speakingParticipants = new Set();
@@ -38,10 +52,19 @@ const diarizationPresets = {
     strict: { minSpeakers: 2, maxSpeakers: 0, strictness: 0.8 }
 };
 
+// Caption accessibility options; the server applies simplification and echoes
+// the style on each caption, so they are re-sent on every (re)connect
+let accessibilitySettings = {
+    simplify: false,
+    speak: false,
+    style: { fontSize: 'normal', highContrast: false }
+};
+
 // Initialize on page load
 document.addEventListener('DOMContentLoaded', async function() {
     // Get session data
     myParticipantId = sessionStorage.getItem('participantId');
+    myParticipantToken = sessionStorage.getItem('participantToken');
     myParticipantName = sessionStorage.getItem('participantName');
     myTargetLanguage = sessionStorage.getItem('targetLanguage');
     meetingId = sessionStorage.getItem('meetingId');
@@ -69,7 +92,7 @@ document.addEventListener('DOMContentLoaded', async function() {
 
     // Fetch meeting info to get mode
     try {
-        const response = await fetch(`/api/meetings/${roomCode || meetingId}`);
+        const response = await authFetch(`/api/meetings/${roomCode || meetingId}`);
         const data = await response.json();
         if (data.success) {
             meetingMode = data.mode;
@@ -82,6 +105,7 @@ document.addEventListener('DOMContentLoaded', async function() {
     }
 
     initializeDiarizationControls();
+    initializeAccessibilityControls();
 
     // Set language selector
     document.getElementById('languageChange').value = myTargetLanguage;
@@ -144,6 +168,11 @@ function setupEventListeners() {
         }
     });
 
+    // Caption accessibility
+    ['captionFontSize', 'captionHighContrast', 'captionSimplify', 'captionSpeak'].forEach(id => {
+        document.getElementById(id).addEventListener('change', updateAccessibility);
+    });
+
     // Reconnect button
     document.getElementById('reconnectButton').addEventListener('click', function() {
         document.getElementById('connectionStatus').style.display = 'none';
@@ -258,6 +287,76 @@ function initializeDiarizationControls() {
     strictnessValue.textContent = parseFloat(strictnessInput.value).toFixed(2);
 }
 
+function initializeAccessibilityControls() {
+    const stored = sessionStorage.getItem('captionAccessibility');
+    if (stored) {
+        try {
+            accessibilitySettings = JSON.parse(stored);
+        } catch (error) {
+            sessionStorage.removeItem('captionAccessibility');
+        }
+    }
+    document.getElementById('captionFontSize').value = accessibilitySettings.style.fontSize || 'normal';
+    document.getElementById('captionHighContrast').checked = !!accessibilitySettings.style.highContrast;
+    document.getElementById('captionSimplify').checked = !!accessibilitySettings.simplify;
+    document.getElementById('captionSpeak').checked = !!accessibilitySettings.speak;
+}
+
+function updateAccessibility() {
+    accessibilitySettings = {
+        simplify: document.getElementById('captionSimplify').checked,
+        speak: document.getElementById('captionSpeak').checked,
+        style: {
+            fontSize: document.getElementById('captionFontSize').value,
+            highContrast: document.getElementById('captionHighContrast').checked
+        }
+    };
+    sessionStorage.setItem('captionAccessibility', JSON.stringify(accessibilitySettings));
+    sendAccessibilitySettings();
+}
+
+function sendAccessibilitySettings() {
+    if (meetingWs && meetingWs.readyState === WebSocket.OPEN) {
+        meetingWs.send(JSON.stringify({
+            type: 'update_accessibility',
+            accessibility: accessibilitySettings
+        }));
+    }
+}
+
+// Spoken captions arrive as binary frames: a 4-byte big-endian header
+// length, a JSON header naming the caption's segmentId, then the audio.
+// Clips are played one after another in arrival order.
+let speechPlayback = Promise.resolve();
+
+function playSpeechFrame(buffer) {
+    if (!accessibilitySettings.speak || buffer.byteLength < 4) {
+        return;
+    }
+    const headerLength = new DataView(buffer).getUint32(0);
+    let header;
+    try {
+        header = JSON.parse(new TextDecoder().decode(new Uint8Array(buffer, 4, headerLength)));
+    } catch (error) {
+        console.error('Invalid speech frame:', error);
+        return;
+    }
+    const url = URL.createObjectURL(new Blob([buffer.slice(4 + headerLength)]));
+    speechPlayback = speechPlayback.then(() => new Promise((resolve) => {
+        const player = new Audio(url);
+        const done = () => {
+            URL.revokeObjectURL(url);
+            resolve();
+        };
+        player.onended = done;
+        player.onerror = done;
+        player.play().catch((error) => {
+            console.warn(`Could not play speech for segment ${header.segmentId}:`, error);
+            done();
+        });
+    }));
+}
+
 function getDiarizationQueryParams() {
     if (meetingMode !== 'shared') {
         return '';
@@ -311,12 +410,22 @@ async function connectToMeeting() {
 
         // Connect WebSocket
         const diarizationParams = getDiarizationQueryParams();
-        const baseParams = `participantId=${myParticipantId}&participantName=${encodeURIComponent(myParticipantName)}&targetLang=${myTargetLanguage}`;
+        let baseParams = `participantId=${myParticipantId}&participantToken=${encodeURIComponent(myParticipantToken || '')}&participantName=${encodeURIComponent(myParticipantName)}&targetLang=${myTargetLanguage}`;
+        if (replayFromSeq > 0) {
+            baseParams += `&since=${replayFromSeq}`;
+        }
+        if (hostToken) {
+            baseParams += `&hostToken=${encodeURIComponent(hostToken)}`;
+        }
+        if (resumeToken) {
+            baseParams += `&resumeToken=${encodeURIComponent(resumeToken)}`;
+        }
         const wsUrl = diarizationParams
             ? `ws://${window.location.host}/ws/meeting/${meetingId}?${baseParams}&${diarizationParams}`
             : `ws://${window.location.host}/ws/meeting/${meetingId}?${baseParams}`;
 
-        meetingWs = new WebSocket(wsUrl);
+        meetingWs = new WebSocket(await withSocketTicket(wsUrl, 'meeting', meetingId));
+        meetingWs.binaryType = 'arraybuffer';
 
         meetingWs.onopen = () => {
             console.log('Connected to meeting');
@@ -324,9 +433,14 @@ async function connectToMeeting() {
             hideStatus();
             setupAudioStreaming(stream);
             refreshSnapshotLanguages();
+            sendAccessibilitySettings();
         };
 
         meetingWs.onmessage = (event) => {
+            if (event.data instanceof ArrayBuffer) {
+                playSpeechFrame(event.data);
+                return;
+            }
             const message = JSON.parse(event.data);
             handleMeetingMessage(message);
         };
@@ -339,8 +453,15 @@ async function connectToMeeting() {
         meetingWs.onclose = () => {
             console.log('Disconnected from meeting');
             isConnected = false;
-            showStatus('Disconnected from meeting', true);
             cleanupAudio();
+            // Reclaim the seat while the server holds it
+            if (resumeToken && !isEndingMeeting && resumeAttempts < maxResumeAttempts) {
+                resumeAttempts++;
+                showStatus('Connection lost. Reconnecting...');
+                setTimeout(connectToMeeting, 1000 * resumeAttempts);
+                return;
+            }
+            showStatus('Disconnected from meeting', true);
         };
 
     } catch (error) {
@@ -362,7 +483,7 @@ async function refreshSnapshotLanguages() {
 
     const meetingKey = roomCode || meetingId;
     try {
-        const response = await fetch(`/api/meetings/${meetingKey}/transcript-snapshots`);
+        const response = await authFetch(`/api/meetings/${meetingKey}/transcript-snapshots`);
         if (!response.ok) {
             select.innerHTML = '<option value="">No snapshots</option>';
             downloadBtn.disabled = true;
@@ -398,6 +519,9 @@ function setupAudioStreaming(stream) {
         const nativeSampleRate = audioContext.sampleRate;
         console.log(`Native sample rate: ${nativeSampleRate}`);
 
+        // Audio is sent at the native rate; the server resamples it to 16kHz
+        meetingWs.send(JSON.stringify({ type: 'audio_format', sampleRate: nativeSampleRate }));
+
         audioSource = audioContext.createMediaStreamSource(stream);
         audioProcessor = audioContext.createScriptProcessor(4096, 1, 1);
 
@@ -411,9 +535,7 @@ function setupAudioStreaming(stream) {
 
             const inputData = e.inputBuffer.getChannelData(0);
 
-            // Resample to 16kHz before sending
-            const resampled = resampleAudio(inputData, nativeSampleRate, 16000);
-            const pcm16 = convertToPCM16(resampled);
+            const pcm16 = convertToPCM16(inputData);
 
             // Send binary audio data
             meetingWs.send(pcm16);
@@ -447,39 +569,172 @@ function cleanupAudio() {
 function handleMeetingMessage(message) {
     console.log('Received message:', message.type);
 
+    // Replayed and live messages can overlap after a reconnect
+    if (message.type === 'replay_complete') {
+        return;
+    }
+    if (message.seq) {
+        if (message.seq <= replayFromSeq || eventSeqs.has(message.seq)) {
+            return;
+        }
+        if (replayFromSeq === 0) {
+            replayFromSeq = message.seq - 1;
+        }
+        eventSeqs.add(message.seq);
+        while (eventSeqs.has(replayFromSeq + 1)) {
+            replayFromSeq++;
+            eventSeqs.delete(replayFromSeq);
+        }
+    }
+
     switch (message.type) {
         case 'participant_joined':
             addParticipantToUI(message);
-            showSystemMessage(`${message.participantName} joined the meeting`);
+            showSystemMessage(message.text || `${message.participantName} joined the meeting`);
             break;
 
         case 'participant_left':
             removeParticipantFromUI(message.participantId);
-            showSystemMessage(`${message.participantName} left the meeting`);
+            showSystemMessage(message.text || `${message.participantName} left the meeting`);
             break;
 
         case 'participant_language_updated':
             updateParticipantLanguageInUI(message.participantId, message.targetLanguage);
             if (message.participantId !== parseInt(myParticipantId)) {
-                showSystemMessage(`Participant updated language to ${getLanguageName(message.targetLanguage)}`);
+                showSystemMessage(message.text || `Participant updated language to ${getLanguageName(message.targetLanguage)}`);
+            }
+            break;
+
+        case 'translation_segment': {
+            // Sentences of a long utterance arrive before its final caption
+            const sentence = message.translations[myTargetLanguage] || message.originalText;
+            const preview = partialCaptions.get(message.speakerParticipantId);
+            if (preview) {
+                partialCaptions.delete(message.speakerParticipantId);
+                preview.remove();
+            }
+            const pending = segmentCaptions.get(message.utteranceId);
+            if (pending) {
+                const textEl = pending.querySelector('.caption-text');
+                textEl.textContent = `${textEl.textContent} ${sentence}`;
+            } else {
+                segmentCaptions.set(message.utteranceId, displayCaption(
+                    message.speakerName,
+                    sentence,
+                    message.speakerParticipantId === parseInt(myParticipantId),
+                    message.speakerParticipantId,
+                    message.speakerId,
+                    message.speakerLowConfidence,
+                    message.speakerOverlap,
+                    '',
+                    message.style
+                ));
             }
             break;
+        }
 
         case 'transcription':
             // Show translation in MY language
-            const myTranslation = message.translations[myTargetLanguage] || message.originalText;
+            const myTranslation = (message.translations && message.translations[myTargetLanguage]) || message.originalText;
             const isMe = message.speakerParticipantId === parseInt(myParticipantId);
-            displayCaption(
+            const partial = partialCaptions.get(message.speakerParticipantId);
+            if (!message.isFinal) {
+                // Preview of speech still in progress; updated in place
+                if (partial && partial.isConnected) {
+                    partial.querySelector('.caption-text').textContent = myTranslation;
+                } else {
+                    const preview = displayCaption(
+                        message.speakerName,
+                        myTranslation,
+                        isMe,
+                        message.speakerParticipantId,
+                        message.speakerId,
+                        false,
+                        false,
+                        '',
+                        message.style
+                    );
+                    preview.classList.add('caption-partial');
+                    partialCaptions.set(message.speakerParticipantId, preview);
+                }
+                break;
+            }
+            if (partial) {
+                // The final caption takes the place of the preview
+                partialCaptions.delete(message.speakerParticipantId);
+                partial.remove();
+            }
+            const caption = displayCaption(
                 message.speakerName,
                 myTranslation,
                 isMe,
                 message.speakerParticipantId,
                 message.speakerId,
                 message.speakerLowConfidence,
-                message.speakerOverlap
+                message.speakerOverlap,
+                message.simplified,
+                message.style
             );
+            // Corrections name the caption by entryId or segmentId
+            if (message.entryId) {
+                caption.dataset.entryId = message.entryId;
+            }
+            if (message.segmentId) {
+                caption.dataset.segmentId = message.segmentId;
+            }
+            const streamed = message.utteranceId && segmentCaptions.get(message.utteranceId);
+            if (streamed) {
+                // The complete caption takes the place of the streamed sentences
+                segmentCaptions.delete(message.utteranceId);
+                if (streamed.isConnected) {
+                    streamed.replaceWith(caption);
+                }
+            }
+            break;
+
+        case 'accessibility_updated':
+            if (accessibilitySettings.simplify && !message.accessibility.simplify) {
+                document.getElementById('captionSimplify').checked = false;
+                showSystemMessage('Simplified captions are not available on this server');
+            }
+            if (accessibilitySettings.speak && !message.accessibility.speak) {
+                document.getElementById('captionSpeak').checked = false;
+                showSystemMessage('Spoken translation is not available on this server');
+            }
+            accessibilitySettings = message.accessibility;
+            sessionStorage.setItem('captionAccessibility', JSON.stringify(accessibilitySettings));
+            break;
+
+        case 'consent_request':
+            respondToConsent(message.announcement);
+            break;
+
+        case 'consent_status':
+            showSystemMessage(message.text || (message.consented
+                ? 'You agreed to recording earlier; your speech is saved to the transcript.'
+                : 'You declined recording earlier; your speech is captioned live only.'));
+            break;
+
+        case 'participant_consent_updated':
+            if (message.participantId !== parseInt(myParticipantId)) {
+                showSystemMessage(message.text || `${message.participantName} ${message.consented ? 'agreed to' : 'declined'} recording`);
+            }
+            break;
+
+        case 'caption_updated': {
+            // An editor corrected a finalized caption
+            const corrected = (message.translations && message.translations[myTargetLanguage]) || message.originalText;
+            document.querySelectorAll('#captionsContainer .caption-item').forEach(item => {
+                if ((message.entryId && item.dataset.entryId === String(message.entryId)) ||
+                    (message.segmentId && item.dataset.segmentId === message.segmentId)) {
+                    item.querySelector('.caption-text').textContent = corrected;
+                    item.classList.add('caption-corrected');
+                }
+            });
             break;
+        }
 
+        case 'speaker_renamed':
         case 'speaker_name_updated':
             updateSpeakerNameInUI(message.speakerId, message.speakerName);
             showSystemMessage(`Speaker renamed to: ${message.speakerName}`);
@@ -488,8 +743,94 @@ function handleMeetingMessage(message) {
         case 'error':
             console.error('Server error:', message.error);
             break;
+        case 'speaking_started':
+        case 'speaking_stopped':
+            setSpeakingIndicator(message.participantId, message.type === 'speaking_started');
+            break;
+        case 'participant_muted':
+        case 'participant_unmuted':
+            setParticipantMuted(message.participantId, message.type === 'participant_muted');
+            showSystemMessage(message.text);
+            break;
+        case 'participant_kicked':
+            removeParticipantFromUI(message.participantId);
+            showSystemMessage(message.text);
+            break;
+        case 'resume_token':
+            resumeToken = message.resumeToken;
+            resumeAttempts = 0;
+            break;
+        case 'resumed':
+            resumeToken = message.resumeToken || resumeToken;
+            resumeAttempts = 0;
+            showSystemMessage('Reconnected to the meeting');
+            break;
+        case 'kicked':
+            resumeToken = null;
+            showStatus(message.text || 'The host removed you from the meeting', true);
+            break;
+        case 'room_locked':
+        case 'room_unlocked':
+            showSystemMessage(message.text);
+            break;
+        case 'waiting':
+            showStatus(message.text || 'Waiting for the host to let you in');
+            break;
+        case 'admitted':
+            hideStatus();
+            showSystemMessage(message.text || 'The host let you in');
+            break;
+        case 'join_rejected':
+            resumeToken = null;
+            showStatus(message.text || 'The host declined your request to join', true);
+            break;
+        case 'join_request':
+            showJoinRequest(message);
+            break;
+        case 'join_request_resolved':
+        case 'join_request_withdrawn': {
+            const request = document.getElementById(`join-request-${message.participantId}`);
+            if (request) {
+                request.remove();
+            }
+            if (message.type === 'join_request_withdrawn') {
+                showSystemMessage(message.text || `${message.participantName} stopped waiting to join`);
+            }
+            break;
+        }
+        case 'audio_format':
+            console.log(`Server resamples audio from ${message.sampleRate} Hz`);
+            break;
+        case 'audio_level':
+            break;
+        case 'meeting_ending_soon':
+            showSystemMessage(message.text || `This meeting will end automatically in ${message.minutesRemaining} minute(s)`);
+            break;
+
+        case 'room_suspended':
+            showSystemMessage(message.text || 'No one has spoken for a while; captioning is paused until someone speaks');
+            break;
+
+        case 'room_resumed':
+            showSystemMessage(message.text || 'Captioning resumed');
+            break;
+
+        case 'quota_exceeded':
+            showSystemMessage(message.text || 'The daily audio quota is used up; speech is not captioned until it resets');
+            break;
+
+        case 'caption_throttle':
+            // The server thins live captions while this connection falls behind
+            if (message.mode === 'finals_only') {
+                showSystemMessage('Slow connection: showing finished captions only');
+            } else if (message.mode === 'off') {
+                showSystemMessage('Connection recovered: live captions resumed');
+            }
+            break;
+
         case 'meeting_ended':
-            showStatus('Meeting ended by host.', false);
+            resumeToken = null;
+            showStatus(message.text || 'Meeting ended by host.', false);
             cleanupAudio();
             refreshSnapshotLanguages();
             setTimeout(hideStatus, 1500);
@@ -500,6 +841,16 @@ function handleMeetingMessage(message) {
     }
 }
 
+function respondToConsent(announcement) {
+    const granted = confirm(`${announcement}\n\nDo you consent to your speech being recorded?`);
+    if (meetingWs && meetingWs.readyState === WebSocket.OPEN) {
+        meetingWs.send(JSON.stringify({ type: 'consent', granted }));
+    }
+    showSystemMessage(granted
+        ? 'Your speech will be saved to the meeting transcript.'
+        : 'Your speech will be captioned live only and not saved.');
+}
+
 function addParticipantToUI(participant) {
     const list = document.getElementById('participantsList');
     const isMe = participant.participantId === parseInt(myParticipantId);
@@ -526,10 +877,50 @@ function addParticipantToUI(participant) {
         </div>
     `;
 
+    // The host can mute and remove other participants
+    if (hostToken && !isMe) {
+        const controls = document.createElement('div');
+        controls.className = 'participant-controls';
+        const mute = document.createElement('button');
+        mute.className = 'mute-button';
+        mute.textContent = 'Mute';
+        mute.addEventListener('click', () => {
+            const muted = !div.classList.contains('muted');
+            sendModeration({ type: 'mute', participantId: participant.participantId, muted });
+        });
+        const kick = document.createElement('button');
+        kick.textContent = 'Remove';
+        kick.addEventListener('click', () => {
+            if (confirm(`Remove ${participant.participantName} from the meeting?`)) {
+                sendModeration({ type: 'kick', participantId: participant.participantId });
+            }
+        });
+        controls.append(mute, kick);
+        div.appendChild(controls);
+    }
+
     list.appendChild(div);
     updateParticipantCount();
 }
 
+function sendModeration(action) {
+    if (meetingWs && meetingWs.readyState === WebSocket.OPEN) {
+        meetingWs.send(JSON.stringify(action));
+    }
+}
+
+function setParticipantMuted(participantId, muted) {
+    const element = document.getElementById(`participant-${participantId}`);
+    if (!element) {
+        return;
+    }
+    element.classList.toggle('muted', muted);
+    const button = element.querySelector('.mute-button');
+    if (button) {
+        button.textContent = muted ? 'Unmute' : 'Mute';
+    }
+}
+
 function updateParticipantLanguageInUI(participantId, targetLanguage) {
     const element = document.getElementById(`participant-${participantId}`);
     if (!element) {
@@ -584,7 +975,7 @@ function formatSpeakerLabelTitle(speakerLowConfidence, speakerOverlap) {
     return '';
 }
 
-function displayCaption(speakerName, text, isMe, speakerParticipantId, speakerId, speakerLowConfidence, speakerOverlap) {
+function displayCaption(speakerName, text, isMe, speakerParticipantId, speakerId, speakerLowConfidence, speakerOverlap, simplified, style) {
     const container = document.getElementById('captionsContainer');
 
     // Remove empty state if exists
@@ -595,6 +986,12 @@ function displayCaption(speakerName, text, isMe, speakerParticipantId, speakerId
 
     const caption = document.createElement('div');
     caption.className = isMe ? 'caption-item caption-me' : 'caption-item';
+    if (style && style.fontSize && style.fontSize !== 'normal') {
+        caption.classList.add(`caption-${style.fontSize}`);
+    }
+    if (style && style.highContrast) {
+        caption.classList.add('caption-high-contrast');
+    }
 
     // Add speaker label styling for shared room mode
     // Store speaker data in data attributes for event delegation (NO inline onclick!)
@@ -624,20 +1021,17 @@ function displayCaption(speakerName, text, isMe, speakerParticipantId, speakerId
     caption.innerHTML = `
         ${speakerLabel}
         <div class="caption-text">${escapeHtml(text)}</div>
+        ${simplified ? `<div class="caption-simplified">${escapeHtml(simplified)}</div>` : ''}
     `;
 
     container.appendChild(caption);
     container.scrollTop = container.scrollHeight; // Auto-scroll to bottom
 
-    // Show speaking indicator (only for individual mode with participant ID)
-    if (speakerParticipantId) {
-        showSpeakingIndicator(speakerParticipantId);
-    }
-
     // Keep only last 50 captions for performance
     while (container.children.length > 50) {
         container.removeChild(container.firstChild);
     }
+    return caption;
 }
 
 function showSystemMessage(text) {
@@ -651,17 +1045,41 @@ function showSystemMessage(text) {
     container.scrollTop = container.scrollHeight;
 }
 
-function showSpeakingIndicator(participantId) {
+function showJoinRequest(message) {
+    if (document.getElementById(`join-request-${message.participantId}`)) {
+        return;
+    }
+    const container = document.getElementById('captionsContainer');
+
+    const request = document.createElement('div');
+    request.className = 'system-message join-request';
+    request.id = `join-request-${message.participantId}`;
+    const text = document.createElement('span');
+    text.textContent = message.text || `${message.participantName} is waiting to join`;
+    request.appendChild(text);
+
+    for (const [label, admit] of [['Admit', true], ['Reject', false]]) {
+        const button = document.createElement('button');
+        button.textContent = label;
+        button.addEventListener('click', () => {
+            if (meetingWs && meetingWs.readyState === WebSocket.OPEN) {
+                meetingWs.send(JSON.stringify({ type: 'admission', participantId: message.participantId, admit }));
+            }
+            request.remove();
+        });
+        request.appendChild(button);
+    }
+
+    container.appendChild(request);
+    container.scrollTop = container.scrollHeight;
+}
+
+// Speaking indicators follow the server's voice detection
+function setSpeakingIndicator(participantId, speaking) {
     const element = document.getElementById(`participant-${participantId}`);
     if (element) {
-        element.classList.add('speaking');
-        element.querySelector('.participant-icon').textContent = '🔊';
-
-        // Remove indicator after 2 seconds
-        setTimeout(() => {
-            element.classList.remove('speaking');
-            element.querySelector('.participant-icon').textContent = '🔇';
-        }, 2000);
+        element.classList.toggle('speaking', speaking);
+        element.querySelector('.participant-icon').textContent = speaking ? '🔊' : '🔇';
     }
 }
 
@@ -719,8 +1137,9 @@ function promptRenameSpeaker(speakerId, currentName) {
 
 async function renameSpeaker(speakerId, newName) {
     try {
-        const response = await fetch(`/api/meetings/${roomCode || meetingId}/speakers/${speakerId}`, {
-            method: 'POST',
+        const hostParam = hostToken ? `?hostToken=${encodeURIComponent(hostToken)}` : '';
+        const response = await authFetch(`/api/meetings/${roomCode || meetingId}/speakers/${encodeURIComponent(speakerId)}${hostParam}`, {
+            method: 'PUT',
             headers: {
                 'Content-Type': 'application/json'
             },
@@ -741,7 +1160,7 @@ async function renameSpeaker(speakerId, newName) {
 }
 
 async function downloadTranscriptFile(url, defaultFilename) {
-    const response = await fetch(url);
+    const response = await authFetch(url);
     if (!response.ok) {
         return { ok: false, errorText: await response.text() };
     }
@@ -775,7 +1194,8 @@ async function downloadTranscript() {
     try {
         const lang = myTargetLanguage || 'en';
         const meetingKey = roomCode || meetingId;
-        const liveUrl = `/api/meetings/${meetingKey}/transcript?lang=${encodeURIComponent(lang)}`;
+        const timeZone = Intl.DateTimeFormat().resolvedOptions().timeZone;
+        const liveUrl = `/api/meetings/${meetingKey}/transcript?lang=${encodeURIComponent(lang)}&tz=${encodeURIComponent(timeZone)}`;
         const snapshotUrl = `/api/meetings/${meetingKey}/transcript-snapshot?lang=${encodeURIComponent(lang)}`;
 
         const liveResult = await downloadTranscriptFile(liveUrl, `meeting_${meetingKey}_${lang}.txt`);
@@ -845,7 +1265,7 @@ async function endMeeting() {
         const controller = new AbortController();
         const timeoutId = setTimeout(() => controller.abort(), 10000);
 
-        const response = await fetch(`/api/meetings/${meetingKey}/end`, {
+        const response = await authFetch(`/api/meetings/${meetingKey}/end`, {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({ hostToken }),
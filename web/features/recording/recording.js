@@ -1,5 +1,5 @@
 import { formatDuration } from '../../assets/js/audio-processor.js';
-import { escapeHtml, downloadBlob, getAccessToken, postJsonWithAuth } from '../../assets/js/utils.js';
+import { escapeHtml, downloadBlob, getAccessToken, postJsonWithAuth, withAuthToken } from '../../assets/js/utils.js';
 import { getSpeakerStyle, formatSpeakerLabelText, getSpeakerLabelClasses } from '../../components/speaker-display/speaker-display.js';
 import { ProgressManager } from '../../components/progress-manager/progress-manager.js';
 
@@ -25,6 +25,10 @@ const downloadTxtBtn = document.getElementById('downloadTxtBtn');
 const downloadJsonBtn = document.getElementById('downloadJsonBtn');
 const enableDiarization = document.getElementById('enableDiarization');
 const enhanceAudio = document.getElementById('enhanceAudio');
+const generateTTS = document.getElementById('generateTTS');
+const cloneVoice = document.getElementById('cloneVoice');
+const ttsSection = document.getElementById('ttsSection');
+const ttsAudio = document.getElementById('ttsAudio');
 
 let selectedFile = null;
 let progressWS = null;
@@ -169,6 +173,8 @@ async function startUpload(forceProcessing) {
         formData.append('targetLang', targetLang.value);
         formData.append('enableDiarization', enableDiarization.checked ? 'true' : 'false');
         formData.append('enhanceAudio', enhanceAudio.checked ? 'true' : 'false');
+        formData.append('generateTTS', generateTTS.checked ? 'true' : 'false');
+        formData.append('cloneVoice', generateTTS.checked && cloneVoice.checked ? 'true' : 'false');
         if (forceProcessing) {
             formData.append('force', 'true');
         }
@@ -180,7 +186,7 @@ async function startUpload(forceProcessing) {
             headers.Authorization = `Bearer ${token}`;
         }
 
-        const response = await fetch('/upload-audio', {
+        const response = await fetch('/upload/audio', {
             method: 'POST',
             headers,
             body: formData
@@ -237,6 +243,15 @@ async function startUpload(forceProcessing) {
                     numSpeakers: update.results.num_speakers || 0
                 };
 
+                // Translated speech is served from the temp dir like dubbed videos
+                if (update.results.ttsPath) {
+                    ttsAudio.src = withAuthToken(`/download/${encodeURIComponent(update.results.ttsPath)}`);
+                    ttsSection.style.display = 'block';
+                } else {
+                    ttsAudio.removeAttribute('src');
+                    ttsSection.style.display = 'none';
+                }
+
                 // Display results
                 if (resultData.segments && resultData.segments.length > 0 && resultData.numSpeakers > 1) {
                     // Display with speaker bubbles
@@ -255,18 +270,23 @@ async function startUpload(forceProcessing) {
                     console.log(`👥 Detected ${update.results.num_speakers} speaker(s)`);
                 }
 
-                await postJsonWithAuth('/api/history/audio', {
-                    sessionId: currentSessionId,
-                    filename: selectedFile ? selectedFile.name : 'upload',
-                    transcription: update.results.transcription || '',
-                    translation: update.results.translation || '',
-                    audioPath: update.results.minioAudioKey || '',
-                    sourceLang: sourceLang.value,
-                    targetLang: targetLang.value,
-                    hasDiarization: enableDiarization.checked || (update.results.segments && update.results.segments.length > 0),
-                    numSpeakers: update.results.num_speakers || 0,
-                    segments: update.results.segments || []
-                });
+                // Signed-in uploads are saved to history by the server; post only
+                // if that did not happen
+                if (!update.results.historyId) {
+                    await postJsonWithAuth('/api/history/audio', {
+                        sessionId: currentSessionId,
+                        filename: selectedFile ? selectedFile.name : 'upload',
+                        transcription: update.results.transcription || '',
+                        translation: update.results.translation || '',
+                        audioPath: update.results.minioAudioKey || '',
+                        ttsPath: update.results.minioTtsKey || '',
+                        sourceLang: sourceLang.value,
+                        targetLang: targetLang.value,
+                        hasDiarization: enableDiarization.checked || (update.results.segments && update.results.segments.length > 0),
+                        numSpeakers: update.results.num_speakers || 0,
+                        segments: update.results.segments || []
+                    });
+                }
             }
 
             // Wait a bit then show results
@@ -319,6 +339,8 @@ clearBtn.addEventListener('click', () => {
     errorMessage.classList.remove('show');
     progressFill.style.width = '0%';
     progressStage.textContent = '';
+    ttsAudio.removeAttribute('src');
+    ttsSection.style.display = 'none';
 });
 
 // Download as text
@@ -1,5 +1,5 @@
 import { convertToPCM16, resampleAudio } from '../../assets/js/audio-processor.js';
-import { escapeHtml, downloadBlob, postJsonWithAuth } from '../../assets/js/utils.js';
+import { escapeHtml, downloadBlob, postJsonWithAuth, withSocketTicket, authFetch } from '../../assets/js/utils.js';
 import { getConfig } from '../../assets/js/config.js';
 import { getSpeakerStyle, formatSpeakerLabelText, getSpeakerLabelClasses } from '../../components/speaker-display/speaker-display.js';
 
@@ -44,9 +44,8 @@ btnStop.addEventListener('click', stopStreaming);
 
 async function startStreaming() {
   try {
-    const config = await loadConfig();
-    const asrBaseUrl = config.services?.asrBaseUrl || 'http://localhost:8003';
-    sessionId = 'stream_' + Date.now() + '_' + Math.random().toString(36).substr(2, 9);
+    // The server names the session in its first "session" event
+    sessionId = null;
 
     // Reset UI for new session
     translationsContainer.innerHTML = '';
@@ -99,10 +98,12 @@ async function startStreaming() {
     source.connect(processor);
     processor.connect(audioContext.destination);
     
-    // Connect to streaming WebSocket (port 8003 for ASR streaming service)
+    // Connect to the server's streaming proxy, which relays audio to ASR
+    // and translates partial and final hypotheses
     const lang = sourceLang.value === 'auto' ? 'auto' : sourceLang.value;
-    const wsBase = toWebSocketUrl(asrBaseUrl, '/stream');
-    ws = new WebSocket(`${wsBase}?language=${lang}&session_id=${sessionId}`);
+    const wsBase = toWebSocketUrl(window.location.origin, '/ws/stream');
+    const wsUrl = `${wsBase}?language=${lang}&targetLang=${encodeURIComponent(targetLang.value)}`;
+    ws = new WebSocket(await withSocketTicket(wsUrl, 'stream'));
     
     // Wait for WebSocket to open before starting streaming
     await new Promise((resolve, reject) => {
@@ -193,7 +194,9 @@ async function stopStreaming() {
 }
 
 function handleStreamingMessage(data) {
-  if (data.type === 'partial') {
+  if (data.type === 'session') {
+    sessionId = data.sessionId;
+  } else if (data.type === 'partial') {
     // Update live caption with partial text
     currentPartialText = data.text;
     liveCaption.innerHTML = `<span class="partial-text">${escapeHtml(data.text)}</span>`;
@@ -203,8 +206,9 @@ function handleStreamingMessage(data) {
     const finalText = data.text;
     liveCaption.innerHTML = `<span class="final-text">${escapeHtml(finalText)}</span>`;
     
-    // Add to finalized segments
+    // Add to finalized segments; the server sends its translation next
     finalizedSegments.push({
+      id: data.id,
       index: ++segmentCount,
       original: finalText,
       timestamp: new Date().toISOString()
@@ -213,13 +217,21 @@ function handleStreamingMessage(data) {
     // Update segment count
     document.getElementById('segmentCount').textContent = segmentCount;
     
-    // Translate this segment
-    translateSegment(finalText, segmentCount - 1);
-    
     // Clear after a moment
     setTimeout(() => {
       liveCaption.innerHTML = '<span style="opacity: 0.5;">Listening...</span>';
     }, 2000);
+  } else if (data.type === 'translation') {
+    const segment = finalizedSegments.find(s => s.id === data.id);
+    if (!segment) return;
+    segment.translation = data.text;
+    addTranslationToUI(segment);
+    translationCount++;
+    document.getElementById('translationCount').textContent = translationCount;
+  } else if (data.type === 'info' && data.text === 'reconnecting') {
+    liveCaption.innerHTML = '<span style="opacity: 0.7;">⏳ Reconnecting to speech recognition...</span>';
+  } else if (data.type === 'info' && data.text === 'reconnected') {
+    liveCaption.innerHTML = '<span style="opacity: 0.5;">Listening...</span>';
   }
 }
 
@@ -288,6 +300,7 @@ function addTranslationToUI(segment) {
 }
 
 async function triggerFinalProcessing() {
+  if (!sessionId) return;
   console.log('🔄 Fetching high-quality final transcription...');
   liveCaption.innerHTML = '<span style="opacity: 0.7;">⏳ Processing final high-quality transcription...</span>';
 
@@ -297,12 +310,10 @@ async function triggerFinalProcessing() {
   
   for (let attempt = 1; attempt <= maxAttempts; attempt++) {
     try {
-      const config = await loadConfig();
-      const asrBaseUrl = config.services?.asrBaseUrl || 'http://localhost:8003';
       console.log(`Polling attempt ${attempt}/${maxAttempts}...`);
       liveCaption.innerHTML = `<span style="opacity: 0.7;">⏳ Processing... (${attempt * 2}s)</span>`;
       
-      const response = await fetch(`${asrBaseUrl}/transcription/${sessionId}`);
+      const response = await authFetch(`/api/stream/transcription/${sessionId}`);
 
       if (response.ok) {
         const result = await response.json();
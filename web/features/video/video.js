@@ -1,5 +1,5 @@
 import { formatDuration } from '../../assets/js/audio-processor.js';
-import { getAccessToken, postJsonWithAuth } from '../../assets/js/utils.js';
+import { getAccessToken, postJsonWithAuth, withAuthToken } from '../../assets/js/utils.js';
 import { ProgressManager } from '../../components/progress-manager/progress-manager.js';
 
 // Video upload and processing script
@@ -23,6 +23,10 @@ const targetLang = document.getElementById('targetLang');
 const errorMessage = document.getElementById('errorMessage');
 const generateTTS = document.getElementById('generateTTS');
 const cloneVoice = document.getElementById('cloneVoice');
+const matchDuration = document.getElementById('matchDuration');
+const segmentDubbing = document.getElementById('segmentDubbing');
+const mixOriginal = document.getElementById('mixOriginal');
+const enableDiarization = document.getElementById('enableDiarization');
 const downloadBtn = document.getElementById('downloadBtn');
 const voiceCloneWarning = document.getElementById('voiceCloneWarning');
 
@@ -149,6 +153,10 @@ async function startUpload(forceProcessing) {
         formData.append('targetLang', targetLang.value);
         formData.append('generateTTS', generateTTS.checked ? 'true' : 'false');
         formData.append('cloneVoice', cloneVoice.checked ? 'true' : 'false');
+        formData.append('matchDuration', matchDuration.checked ? 'true' : 'false');
+        formData.append('segmentDubbing', segmentDubbing.checked ? 'true' : 'false');
+        formData.append('mixOriginal', mixOriginal.checked ? 'true' : 'false');
+        formData.append('enableDiarization', enableDiarization.checked ? 'true' : 'false');
         if (forceProcessing) {
             formData.append('force', 'true');
         }
@@ -294,7 +302,7 @@ clearBtn.addEventListener('click', () => {
 // Download button
 downloadBtn.addEventListener('click', () => {
     if (videoPath) {
-        window.location.href = `/download/${videoPath}`;
+        window.location.href = withAuthToken(`/download/${videoPath}`);
     }
 });
 